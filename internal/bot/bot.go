@@ -1,6 +1,8 @@
 package bot
 
 import (
+	"context"
+	"database/sql"
 	"log"
 	"time"
 
@@ -15,8 +17,10 @@ import (
 	"evo-bot-go/internal/handlers/grouphandlers"
 	"evo-bot-go/internal/handlers/privatehandlers"
 	"evo-bot-go/internal/handlers/privatehandlers/topicshandlers"
+	"evo-bot-go/internal/observability"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/tasks"
+	"evo-bot-go/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -25,29 +29,52 @@ import (
 // HandlerDependencies contains all dependencies needed by handlers
 type HandlerDependencies struct {
 	OpenAiClient                      *clients.OpenAiClient
+	TgUserClient                      clients.TgUserClient
+	TgUserClientLogin                 clients.TgUserClientLogin
 	AppConfig                         *config.Config
 	ProfileService                    *services.ProfileService
 	SummarizationService              *services.SummarizationService
 	RandomCoffeeService               *services.RandomCoffeeService
+	EventRecapService                 *services.EventRecapService
+	EventRegistrationService          *services.EventRegistrationService
+	ScoreService                      *services.ScoreService
+	ThanksService                     *services.ThanksService
+	ModerationRulesService            *services.ModerationRulesService
+	AntiSpamService                   *services.AntiSpamService
+	ModerationActionsService          *services.ModerationActionsService
+	MembershipCacheService            *services.MembershipCacheService
+	AuditLogService                   *services.AuditLogService
+	BroadcastService                  *services.BroadcastService
+	LLMUsageService                   *services.LLMUsageService
+	ForumTopicService                 *services.ForumTopicService
 	MessageSenderService              *services.MessageSenderService
 	PermissionsService                *services.PermissionsService
-	EventRepository                   *repositories.EventRepository
+	EventRepository                   repositories.EventRepository
 	TopicRepository                   *repositories.TopicRepository
 	PromptingTemplateRepository       *repositories.PromptingTemplateRepository
-	UserRepository                    *repositories.UserRepository
-	ProfileRepository                 *repositories.ProfileRepository
-	RandomCoffeePollRepository        *repositories.RandomCoffeePollRepository
-	RandomCoffeeParticipantRepository *repositories.RandomCoffeeParticipantRepository
-	RandomCoffeePairRepository        *repositories.RandomCoffeePairRepository
+	UserRepository                    repositories.UserRepository
+	ProfileRepository                 repositories.ProfileRepository
+	RandomCoffeePollRepository        repositories.RandomCoffeePollRepository
+	RandomCoffeeParticipantRepository repositories.RandomCoffeeParticipantRepository
+	RandomCoffeePairRepository        repositories.RandomCoffeePairRepository
+	EventRecapRepository              *repositories.EventRecapRepository
+	ScoreRepository                   repositories.ScoreRepository
+	ThanksRepository                  *repositories.ThanksRepository
+	ModerationRuleRepository          *repositories.ModerationRuleRepository
+	ModerationActionRepository        *repositories.ModerationActionRepository
+	AuditLogRepository                *repositories.AuditLogRepository
+	UserRoleRepository                repositories.UserRoleRepository
 }
 
 // TgBotClient represents a Telegram bot client with all required dependencies
 type TgBotClient struct {
-	bot        *gotgbot.Bot
-	dispatcher *ext.Dispatcher
-	updater    *ext.Updater
-	db         *database.DB
-	tasks      []tasks.Task
+	bot          *gotgbot.Bot
+	dispatcher   *ext.Dispatcher
+	updater      *ext.Updater
+	db           *database.DB
+	tgUserClient *clients.TelegramClient
+	tasks        []tasks.Task
+	server       *observability.Server
 }
 
 // NewTgBotClient creates and initializes a new Telegram bot client
@@ -62,7 +89,7 @@ func NewTgBotClient(openaiClient *clients.OpenAiClient, appConfig *config.Config
 	// Setup dispatcher with error handling
 	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
 		Error: func(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
-			log.Println(err.Error())
+			observability.Logger(ctx).Error("Update handling failed", "error", err)
 			return ext.DispatcherActionNoop
 		},
 		MaxRoutines: ext.DefaultMaxRoutines,
@@ -72,98 +99,288 @@ func NewTgBotClient(openaiClient *clients.OpenAiClient, appConfig *config.Config
 	updater := ext.NewUpdater(dispatcher, nil)
 
 	// Setup database
-	db, err := setupDatabase(appConfig.DBConnection)
+	db, err := setupDatabase(appConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize repositories
-	eventRepository := repositories.NewEventRepository(db.DB)
-	topicRepository := repositories.NewTopicRepository(db.DB)
-	promptingTemplateRepository := repositories.NewPromptingTemplateRepository(db.DB)
-	userRepository := repositories.NewUserRepository(db.DB)
-	profileRepository := repositories.NewProfileRepository(db.DB)
-	randomCoffeePollRepository := repositories.NewRandomCoffeePollRepository(db.DB)
-	randomCoffeeParticipantRepository := repositories.NewRandomCoffeeParticipantRepository(db.DB)
-	randomCoffeePairRepository := repositories.NewRandomCoffeePairRepository(db.DB)
-
-	// Initialize services
-	messageSenderService := services.NewMessageSenderService(bot)
-	profileService := services.NewProfileService(bot)
+	// Initialize the Telegram user client shared by all consumers, it connects on Start
+	tgSessionStorage, err := clients.NewTgSessionStorage(appConfig, db)
+	if err != nil {
+		return nil, err
+	}
+	tgUserClient := clients.NewTelegramClient(appConfig, tgSessionStorage)
+
+	// Initialize repositories, services and the dependencies of the handlers
+	deps := newHandlerDependencies(appConfig, bot, openaiClient, tgUserClient,
+		services.NewTelegramRateLimiter(), newBotRepositories(db.DB))
+
+	// Record every OpenAI call for usage accounting
+	openaiClient.SetCallRecorder(deps.LLMUsageService.RecordCall)
+
+	// Re-arm the captchas that were not answered before the restart
+	if err := deps.AntiSpamService.RestorePendingCaptchas(); err != nil {
+		log.Printf("Bot Runner: Failed to restore pending captchas: %v", err)
+	}
+
+	// Initialize scheduled tasks
+	scheduledTasks := []tasks.Task{
+		tasks.NewSessionKeepAliveTask(tgUserClient, 30*time.Minute),
+		tasks.NewForumTopicsSyncTask(deps.ForumTopicService),
+		tasks.NewDailySummarizationTask(appConfig, deps.SummarizationService),
+		tasks.NewRandomCoffeePollTask(appConfig, deps.RandomCoffeeService),
+		tasks.NewRandomCoffeePairsTask(appConfig, deps.RandomCoffeeService),
+		tasks.NewScoreDecayTask(appConfig, deps.ScoreService),
+		tasks.NewMembershipCacheTask(appConfig, deps.MembershipCacheService),
+		tasks.NewMessageQueueTask(deps.MessageSenderService),
+		tasks.NewBroadcastReportTask(deps.BroadcastService),
+	}
+
+	// Create bot client
+	client := &TgBotClient{
+		bot:          bot,
+		dispatcher:   dispatcher,
+		updater:      updater,
+		db:           db,
+		tgUserClient: tgUserClient,
+		tasks:        scheduledTasks,
+	}
+
+	// Expose metrics and health endpoints when an address is configured
+	if appConfig.ObservabilityAddr != "" {
+		observability.NewGaugeFunc("membership_cache_hits", "Membership cache hits since start", func() float64 {
+			return float64(deps.MembershipCacheService.GetStats().Hits)
+		})
+		observability.NewGaugeFunc("membership_cache_misses", "Membership cache misses since start", func() float64 {
+			return float64(deps.MembershipCacheService.GetStats().Misses)
+		})
+		observability.NewGaugeFunc("membership_cache_size", "Number of cached chat members", func() float64 {
+			return float64(deps.MembershipCacheService.GetStats().Size)
+		})
+		observability.NewGaugeFunc("forum_topics", "Number of topics in the forum topic registry", func() float64 {
+			return float64(len(deps.ForumTopicService.GetTopics()))
+		})
+
+		client.server = observability.NewServer(appConfig.ObservabilityAddr,
+			observability.ReadinessCheck{Name: "database", Check: db.PingContext},
+			observability.ReadinessCheck{Name: "bot_api", Check: func(ctx context.Context) error {
+				_, err := bot.GetMeWithContext(ctx, nil)
+				return err
+			}},
+			observability.ReadinessCheck{Name: "user_client_session", Check: tgUserClient.CheckSession},
+		)
+	}
+
+	// Register all handlers
+	client.registerHandlers(deps)
+
+	return client, nil
+}
+
+// botRepositories contains the repositories used by the services and the handlers
+type botRepositories struct {
+	Event                   repositories.EventRepository
+	Topic                   *repositories.TopicRepository
+	PromptingTemplate       *repositories.PromptingTemplateRepository
+	User                    repositories.UserRepository
+	Profile                 repositories.ProfileRepository
+	RandomCoffeePoll        repositories.RandomCoffeePollRepository
+	RandomCoffeeParticipant repositories.RandomCoffeeParticipantRepository
+	RandomCoffeePair        repositories.RandomCoffeePairRepository
+	EventRecap              *repositories.EventRecapRepository
+	Score                   repositories.ScoreRepository
+	Thanks                  *repositories.ThanksRepository
+	ModerationRule          *repositories.ModerationRuleRepository
+	ModerationAction        *repositories.ModerationActionRepository
+	AuditLog                *repositories.AuditLogRepository
+	UserRole                repositories.UserRoleRepository
+	OutgoingMessage         *repositories.OutgoingMessageRepository
+	Broadcast               *repositories.BroadcastRepository
+	LLMUsage                *repositories.LLMUsageRepository
+	ForumTopic              repositories.ForumTopicRepository
+	PendingCaptcha          *repositories.PendingCaptchaRepository
+}
+
+// newBotRepositories creates the repositories over the database
+func newBotRepositories(db *sql.DB) *botRepositories {
+	return &botRepositories{
+		Event:                   repositories.NewEventRepository(db),
+		Topic:                   repositories.NewTopicRepository(db),
+		PromptingTemplate:       repositories.NewPromptingTemplateRepository(db),
+		User:                    repositories.NewUserRepository(db),
+		Profile:                 repositories.NewProfileRepository(db),
+		RandomCoffeePoll:        repositories.NewRandomCoffeePollRepository(db),
+		RandomCoffeeParticipant: repositories.NewRandomCoffeeParticipantRepository(db),
+		RandomCoffeePair:        repositories.NewRandomCoffeePairRepository(db),
+		EventRecap:              repositories.NewEventRecapRepository(db),
+		Score:                   repositories.NewScoreRepository(db),
+		Thanks:                  repositories.NewThanksRepository(db),
+		ModerationRule:          repositories.NewModerationRuleRepository(db),
+		ModerationAction:        repositories.NewModerationActionRepository(db),
+		AuditLog:                repositories.NewAuditLogRepository(db),
+		UserRole:                repositories.NewUserRoleRepository(db),
+		OutgoingMessage:         repositories.NewOutgoingMessageRepository(db),
+		Broadcast:               repositories.NewBroadcastRepository(db),
+		LLMUsage:                repositories.NewLLMUsageRepository(db),
+		ForumTopic:              repositories.NewForumTopicRepository(db),
+		PendingCaptcha:          repositories.NewPendingCaptchaRepository(db),
+	}
+}
+
+// newHandlerDependencies creates the services over the repositories and collects the dependencies of the handlers
+func newHandlerDependencies(
+	appConfig *config.Config,
+	bot *gotgbot.Bot,
+	openaiClient *clients.OpenAiClient,
+	tgUserClient *clients.TelegramClient,
+	rateLimiter utils.MessageRateLimiter,
+	repos *botRepositories,
+) *HandlerDependencies {
+	messageSenderService := services.NewMessageSenderService(bot, repos.User, repos.OutgoingMessage, rateLimiter)
+	profileService := services.NewProfileService(bot, appConfig, messageSenderService, repos.Profile)
 	pollSenderService := services.NewPollSenderService(bot)
+	membershipCacheService := services.NewMembershipCacheService(appConfig, bot)
 	permissionsService := services.NewPermissionsService(
 		appConfig,
 		bot,
 		messageSenderService,
+		membershipCacheService,
+		repos.UserRole,
 	)
+	forumTopicService := services.NewForumTopicService(appConfig, tgUserClient, repos.ForumTopic)
+	llmUsageService := services.NewLLMUsageService(appConfig, repos.LLMUsage)
 	summarizationService := services.NewSummarizationService(
 		appConfig,
 		openaiClient,
+		tgUserClient,
+		forumTopicService,
 		messageSenderService,
-		promptingTemplateRepository,
+		repos.PromptingTemplate,
+		llmUsageService,
 	)
 	randomCoffeeService := services.NewRandomCoffeeService(
 		bot,
 		appConfig,
 		pollSenderService,
 		messageSenderService,
-		randomCoffeePollRepository,
-		randomCoffeeParticipantRepository,
-		profileRepository,
-		randomCoffeePairRepository,
-		userRepository,
+		repos.RandomCoffeePoll,
+		repos.RandomCoffeeParticipant,
+		repos.Profile,
+		repos.RandomCoffeePair,
+		repos.User,
 	)
+	eventRecapService := services.NewEventRecapService(
+		bot,
+		appConfig,
+		openaiClient,
+		messageSenderService,
+		repos.PromptingTemplate,
+		repos.Topic,
+		repos.EventRecap,
+		llmUsageService,
+	)
+	eventRegistrationService := services.NewEventRegistrationService(
+		appConfig,
+		messageSenderService,
+		repos.Event,
+		repos.User,
+	)
+	scoreService := services.NewScoreService(
+		appConfig,
+		repos.Score,
+		repos.User,
+	)
+	thanksService := services.NewThanksService(
+		appConfig,
+		repos.Thanks,
+		repos.User,
+		scoreService,
+	)
+	moderationRulesService := services.NewModerationRulesService(
+		appConfig,
+		bot,
+		messageSenderService,
+		membershipCacheService,
+		repos.ModerationRule,
+		repos.User,
+	)
+	adminLogService := services.NewAdminLogService(appConfig, messageSenderService)
+	antiSpamService := services.NewAntiSpamService(
+		appConfig,
+		bot,
+		openaiClient,
+		messageSenderService,
+		adminLogService,
+		repos.PromptingTemplate,
+		repos.PendingCaptcha,
+		repos.ModerationAction,
+		repos.User,
+		llmUsageService,
+	)
+	moderationActionsService := services.NewModerationActionsService(
+		appConfig,
+		bot,
+		messageSenderService,
+		adminLogService,
+		repos.ModerationAction,
+		repos.User,
+	)
+	auditLogService := services.NewAuditLogService(repos.AuditLog)
+	broadcastService := services.NewBroadcastService(messageSenderService, repos.Broadcast, repos.OutgoingMessage)
 
-	// Initialize scheduled tasks
-	scheduledTasks := []tasks.Task{
-		tasks.NewSessionKeepAliveTask(30 * time.Minute),
-		tasks.NewDailySummarizationTask(appConfig, summarizationService),
-		tasks.NewRandomCoffeePollTask(appConfig, randomCoffeeService),
-		tasks.NewRandomCoffeePairsTask(appConfig, randomCoffeeService),
-	}
-
-	// Create bot client
-	client := &TgBotClient{
-		bot:        bot,
-		dispatcher: dispatcher,
-		updater:    updater,
-		db:         db,
-		tasks:      scheduledTasks,
-	}
-
-	// Create dependencies container
-	deps := &HandlerDependencies{
+	return &HandlerDependencies{
 		OpenAiClient:                      openaiClient,
+		TgUserClient:                      tgUserClient,
+		TgUserClientLogin:                 tgUserClient,
 		AppConfig:                         appConfig,
 		ProfileService:                    profileService,
 		SummarizationService:              summarizationService,
 		RandomCoffeeService:               randomCoffeeService,
+		EventRecapService:                 eventRecapService,
+		EventRegistrationService:          eventRegistrationService,
+		ScoreService:                      scoreService,
+		ThanksService:                     thanksService,
+		ModerationRulesService:            moderationRulesService,
+		AntiSpamService:                   antiSpamService,
+		ModerationActionsService:          moderationActionsService,
+		MembershipCacheService:            membershipCacheService,
+		AuditLogService:                   auditLogService,
+		BroadcastService:                  broadcastService,
+		LLMUsageService:                   llmUsageService,
+		ForumTopicService:                 forumTopicService,
 		MessageSenderService:              messageSenderService,
 		PermissionsService:                permissionsService,
-		EventRepository:                   eventRepository,
-		TopicRepository:                   topicRepository,
-		PromptingTemplateRepository:       promptingTemplateRepository,
-		UserRepository:                    userRepository,
-		ProfileRepository:                 profileRepository,
-		RandomCoffeePollRepository:        randomCoffeePollRepository,
-		RandomCoffeeParticipantRepository: randomCoffeeParticipantRepository,
-		RandomCoffeePairRepository:        randomCoffeePairRepository,
+		EventRepository:                   repos.Event,
+		TopicRepository:                   repos.Topic,
+		PromptingTemplateRepository:       repos.PromptingTemplate,
+		UserRepository:                    repos.User,
+		ProfileRepository:                 repos.Profile,
+		RandomCoffeePollRepository:        repos.RandomCoffeePoll,
+		RandomCoffeeParticipantRepository: repos.RandomCoffeeParticipant,
+		RandomCoffeePairRepository:        repos.RandomCoffeePair,
+		EventRecapRepository:              repos.EventRecap,
+		ScoreRepository:                   repos.Score,
+		ThanksRepository:                  repos.Thanks,
+		ModerationRuleRepository:          repos.ModerationRule,
+		ModerationActionRepository:        repos.ModerationAction,
+		AuditLogRepository:                repos.AuditLog,
+		UserRoleRepository:                repos.UserRole,
 	}
-
-	// Register all handlers
-	client.registerHandlers(deps)
-
-	return client, nil
 }
 
 // setupDatabase initializes the database connection and schema
-func setupDatabase(connectionString string) (*database.DB, error) {
-	db, err := database.NewDB(connectionString)
+func setupDatabase(appConfig *config.Config) (*database.DB, error) {
+	sessionCipher, err := database.NewSessionCipher(appConfig.TGUserClientSessionKey, appConfig.TGUserClientSessionOldKeys)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := db.InitWithMigrations(); err != nil {
+	db, err := database.NewDB(appConfig.DBConnection)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.InitWithMigrations(sessionCipher); err != nil {
 		return nil, err
 	}
 
@@ -173,37 +390,60 @@ func setupDatabase(connectionString string) (*database.DB, error) {
 // registerHandlers registers all bot handlers
 func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 	// Register start handler, that avaliable for all users
-	b.dispatcher.AddHandler(handlers.NewStartHandler(deps.AppConfig, deps.MessageSenderService, deps.PermissionsService))
+	b.dispatcher.AddHandler(observability.InstrumentHandler(handlers.NewStartHandler(deps.AppConfig, deps.MessageSenderService, deps.PermissionsService)))
 
 	// Register admin chat handlers
 	adminHandlers := []ext.Handler{
 		eventhandlers.NewEventDeleteHandler(
 			deps.AppConfig,
 			deps.EventRepository,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
 		eventhandlers.NewEventEditHandler(
 			deps.AppConfig,
 			deps.EventRepository,
+			deps.EventRegistrationService,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
 		eventhandlers.NewEventSetupHandler(
 			deps.AppConfig,
 			deps.EventRepository,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
 		eventhandlers.NewEventStartHandler(
 			deps.AppConfig,
 			deps.EventRepository,
+			deps.ScoreService,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		eventhandlers.NewEventRecapHandler(
+			deps.AppConfig,
+			deps.EventRepository,
+			deps.EventRecapRepository,
+			deps.EventRecapService,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		eventhandlers.NewEventAttendeesHandler(
+			deps.AppConfig,
+			deps.EventRepository,
+			deps.EventRegistrationService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
 
 		testhandlers.NewTryCreateCoffeePoolHandler(
 			deps.AppConfig,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 			deps.RandomCoffeeService,
@@ -211,6 +451,7 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 		testhandlers.NewTryGenerateCoffeePairsHandler(
 			deps.AppConfig,
 			deps.PermissionsService,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.RandomCoffeePollRepository,
 			deps.RandomCoffeeParticipantRepository,
@@ -224,23 +465,86 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 			deps.PermissionsService,
 		),
 
-		adminhandlers.NewCodeHandler(
+		adminhandlers.NewUserClientHandler(
 			deps.AppConfig,
+			deps.TgUserClientLogin,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
 		adminhandlers.NewAdminProfilesHandler(
 			deps.AppConfig,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 			deps.ProfileService,
+			deps.ScoreService,
+			deps.ThanksService,
+			deps.ModerationActionsService,
 			deps.UserRepository,
 			deps.ProfileRepository,
 		),
+		adminhandlers.NewScoreAdjustHandler(
+			deps.AppConfig,
+			deps.UserRepository,
+			deps.ScoreService,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewModerationRulesHandler(
+			deps.AppConfig,
+			deps.ModerationRulesService,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewModerationCommandsHandler(
+			deps.AppConfig,
+			deps.ModerationActionsService,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewModLogHandler(
+			deps.AppConfig,
+			deps.ModerationActionsService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewAuditHandler(
+			deps.AppConfig,
+			deps.AuditLogService,
+			deps.UserRepository,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewRolesHandler(
+			deps.AppConfig,
+			deps.UserRepository,
+			deps.UserRoleRepository,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewBroadcastHandler(
+			deps.AppConfig,
+			deps.EventRepository,
+			deps.BroadcastService,
+			deps.AuditLogService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		adminhandlers.NewUsageHandler(
+			deps.AppConfig,
+			deps.LLMUsageService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
 		adminhandlers.NewShowTopicsHandler(
 			deps.AppConfig,
 			deps.TopicRepository,
 			deps.EventRepository,
+			deps.AuditLogService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
@@ -248,10 +552,6 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 
 	// Register group chat handlers
 	groupHandlers := []ext.Handler{
-		grouphandlers.NewCleanClosedThreadsHandler(
-			deps.AppConfig,
-			deps.MessageSenderService,
-		),
 		grouphandlers.NewDeleteJoinLeftMessagesHandler(),
 		grouphandlers.NewJoinLeftHandler(deps.UserRepository),
 		grouphandlers.NewRandomCoffeePollAnswerHandler(
@@ -263,8 +563,61 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 		),
 		grouphandlers.NewRepliesFromClosedThreadsHandler(
 			deps.AppConfig,
+			deps.ForumTopicService,
 			deps.MessageSenderService,
 		),
+		grouphandlers.NewRandomCoffeeMetHandler(
+			deps.UserRepository,
+			deps.RandomCoffeePairRepository,
+			deps.ScoreService,
+		),
+		grouphandlers.NewCaptchaAnswerHandler(deps.AntiSpamService),
+	}
+
+	// Register group chat handlers that must check messages before all other handlers
+	antiSpamGroupHandlers := []ext.Handler{
+		grouphandlers.NewAntiSpamHandler(
+			deps.AppConfig,
+			deps.AntiSpamService,
+		),
+	}
+
+	// Register group chat handlers that check topic rules before the group chat handlers above, which still see
+	// every message the rules let through
+	moderationGroupHandlers := []ext.Handler{
+		grouphandlers.NewModerationHandler(
+			deps.AppConfig,
+			deps.ModerationRulesService,
+		),
+	}
+
+	// Register group chat handlers that must see every group update alongside the handlers above
+	passiveGroupHandlers := []ext.Handler{
+		grouphandlers.NewThanksHandler(
+			deps.AppConfig,
+			deps.ThanksService,
+		),
+		grouphandlers.NewThanksReactionHandler(
+			deps.AppConfig,
+			deps.ThanksService,
+		),
+		grouphandlers.NewCaptchaJoinHandler(
+			deps.AppConfig,
+			deps.AntiSpamService,
+			deps.MembershipCacheService,
+		),
+	}
+
+	// Register handlers that keep the membership cache and the forum topic registry up to date
+	membershipGroupHandlers := []ext.Handler{
+		grouphandlers.NewMembershipCacheHandler(
+			deps.AppConfig,
+			deps.MembershipCacheService,
+		),
+		grouphandlers.NewForumTopicHandler(
+			deps.AppConfig,
+			deps.ForumTopicService,
+		),
 	}
 
 	// Register private chat handlers
@@ -273,6 +626,8 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 			deps.AppConfig,
 			deps.TopicRepository,
 			deps.EventRepository,
+			deps.UserRepository,
+			deps.ScoreService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
@@ -280,12 +635,15 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 			deps.AppConfig,
 			deps.TopicRepository,
 			deps.EventRepository,
+			deps.UserRepository,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
 		privatehandlers.NewContentHandler(
 			deps.AppConfig,
 			deps.OpenAiClient,
+			deps.LLMUsageService,
+			deps.TgUserClient,
 			deps.MessageSenderService,
 			deps.PromptingTemplateRepository,
 			deps.PermissionsService,
@@ -293,6 +651,15 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 		privatehandlers.NewEventsHandler(
 			deps.AppConfig,
 			deps.EventRepository,
+			deps.UserRepository,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
+		privatehandlers.NewEventRsvpHandler(
+			deps.AppConfig,
+			deps.EventRepository,
+			deps.UserRepository,
+			deps.EventRegistrationService,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 		),
@@ -304,23 +671,45 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 		privatehandlers.NewIntroHandler(
 			deps.AppConfig,
 			deps.OpenAiClient,
+			deps.LLMUsageService,
+			deps.TgUserClient,
 			deps.MessageSenderService,
 			deps.PromptingTemplateRepository,
 			deps.PermissionsService,
 		),
+		privatehandlers.NewDirectoryHandler(
+			deps.AppConfig,
+			deps.ProfileRepository,
+			deps.PromptingTemplateRepository,
+			deps.OpenAiClient,
+			deps.LLMUsageService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
+		),
 		privatehandlers.NewProfileHandler(
 			deps.AppConfig,
 			deps.MessageSenderService,
 			deps.PermissionsService,
 			deps.ProfileService,
+			deps.ScoreService,
+			deps.ThanksService,
 			deps.UserRepository,
 			deps.ProfileRepository,
 			deps.PromptingTemplateRepository,
 			deps.OpenAiClient,
+			deps.LLMUsageService,
+		),
+		privatehandlers.NewTopHandler(
+			deps.AppConfig,
+			deps.ScoreService,
+			deps.MessageSenderService,
+			deps.PermissionsService,
 		),
 		privatehandlers.NewToolsHandler(
 			deps.AppConfig,
 			deps.OpenAiClient,
+			deps.LLMUsageService,
+			deps.TgUserClient,
 			deps.MessageSenderService,
 			deps.PromptingTemplateRepository,
 			deps.PermissionsService,
@@ -330,17 +719,44 @@ func (b *TgBotClient) registerHandlers(deps *HandlerDependencies) {
 	// Combine all handlers
 	allHandlers := append(append(adminHandlers, groupHandlers...), privateHandlers...)
 	for _, handler := range allHandlers {
-		b.dispatcher.AddHandler(handler)
+		b.dispatcher.AddHandler(observability.InstrumentHandler(handler))
+	}
+
+	// Each dispatcher group runs its first matching handler, so passive handlers get their own group
+	for _, handler := range passiveGroupHandlers {
+		b.dispatcher.AddHandlerToGroup(observability.InstrumentHandler(handler), 1)
+	}
+
+	// Moderation handlers run before the group chat handlers and stop the processing of a removed message
+	for _, handler := range moderationGroupHandlers {
+		b.dispatcher.AddHandlerToGroup(observability.InstrumentHandler(handler), -1)
+	}
+
+	// Anti-spam handlers run before everything else and may stop the processing of a spam message
+	for _, handler := range antiSpamGroupHandlers {
+		b.dispatcher.AddHandlerToGroup(observability.InstrumentHandler(handler), -2)
+	}
+
+	// Membership cache handlers run first, so every other handler sees the fresh membership
+	for _, handler := range membershipGroupHandlers {
+		b.dispatcher.AddHandlerToGroup(observability.InstrumentHandler(handler), -3)
 	}
 }
 
 // Start begins the bot polling and starts scheduled tasks
 func (b *TgBotClient) Start() {
+	// Connect the user client before the tasks that use it
+	b.tgUserClient.Start()
+
 	// Start scheduled tasks
 	for _, task := range b.tasks {
 		task.Start()
 	}
 
+	if b.server != nil {
+		b.server.Start()
+	}
+
 	// Configure and start polling
 	pollingOpts := &ext.PollingOpts{
 		DropPendingUpdates: true,
@@ -355,6 +771,7 @@ func (b *TgBotClient) Start() {
 				"callback_query",
 				"poll_answer",
 				"my_chat_member",
+				"message_reaction",
 			},
 		},
 	}
@@ -375,6 +792,17 @@ func (b *TgBotClient) Close() error {
 		task.Stop()
 	}
 
+	if b.server != nil {
+		if err := b.server.Close(); err != nil {
+			log.Printf("Bot Runner: Failed to close observability server: %v", err)
+		}
+	}
+
+	// Disconnect the user client
+	if err := b.tgUserClient.Close(); err != nil {
+		log.Printf("Bot Runner: Failed to close user client: %v", err)
+	}
+
 	// Close database connection
 	return b.db.Close()
 }
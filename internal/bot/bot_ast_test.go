@@ -49,28 +49,49 @@ var expectedConstructors = []string{
 	"NewEventEditHandler",
 	"NewEventSetupHandler",
 	"NewEventStartHandler",
+	"NewEventRecapHandler",
+	"NewEventAttendeesHandler",
 	"NewTryCreateCoffeePoolHandler",
 	"NewTryGenerateCoffeePairsHandler",
 	"NewTrySummarizeHandler",
-	"NewCodeHandler",
+	"NewUserClientHandler",
 	"NewAdminProfilesHandler",
+	"NewScoreAdjustHandler",
+	"NewModerationRulesHandler",
+	"NewModerationCommandsHandler",
+	"NewModLogHandler",
+	"NewAuditHandler",
+	"NewRolesHandler",
+	"NewBroadcastHandler",
+	"NewUsageHandler",
 	"NewShowTopicsHandler",
 
 	// Group
-	"NewCleanClosedThreadsHandler",
+	"NewModerationHandler",
 	"NewDeleteJoinLeftMessagesHandler",
 	"NewJoinLeftHandler",
 	"NewRandomCoffeePollAnswerHandler",
 	"NewRepliesFromClosedThreadsHandler",
+	"NewRandomCoffeeMetHandler",
+	"NewThanksHandler",
+	"NewThanksReactionHandler",
+	"NewCaptchaAnswerHandler",
+	"NewCaptchaJoinHandler",
+	"NewMembershipCacheHandler",
+	"NewForumTopicHandler",
+	"NewAntiSpamHandler",
 
 	// Private
 	"NewTopicAddHandler",
 	"NewTopicsHandler",
 	"NewContentHandler",
 	"NewEventsHandler",
+	"NewEventRsvpHandler",
 	"NewHelpHandler",
 	"NewIntroHandler",
+	"NewDirectoryHandler",
 	"NewProfileHandler",
+	"NewTopHandler",
 	"NewToolsHandler",
 }
 
@@ -0,0 +1,1088 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/memory"
+	"evo-bot-go/internal/telegramtest"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/gotd/td/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSuperGroupChatID    = 1234567890
+	testAdminUserID         = 1001
+	testIntroTopicID        = 10
+	testRandomCoffeeTopicID = 20
+)
+
+var (
+	testAdmin  = gotgbot.User{Id: testAdminUserID, FirstName: "Admin", Username: "admin"}
+	testMember = gotgbot.User{Id: 2001, FirstName: "Ivan", LastName: "Petrov", Username: "ivan"}
+)
+
+// testBot runs the handlers of the bot against the fake Bot API server and the in-memory repositories.
+// Repositories without an in-memory implementation fail every query, like an unavailable database.
+type testBot struct {
+	t          *testing.T
+	server     *telegramtest.Server
+	store      *memory.Store
+	config     *config.Config
+	deps       *HandlerDependencies
+	dispatcher *ext.Dispatcher
+}
+
+// noRateLimit lets every message through, the fake server has no limits
+type noRateLimit struct{}
+
+func (noRateLimit) Wait(chatID int64, priority int) {}
+
+// newTestBot creates the bot with the handlers of the real bot, the options replace its dependencies
+func newTestBot(t *testing.T, options ...func(deps *HandlerDependencies)) *testBot {
+	t.Helper()
+
+	server := telegramtest.NewServer(t)
+	store := memory.NewStore()
+	appConfig := &config.Config{
+		SuperGroupChatID:    testSuperGroupChatID,
+		AdminUserID:         testAdminUserID,
+		ClubTimezone:        time.UTC,
+		IntroTopicID:        testIntroTopicID,
+		RandomCoffeeTopicID: testRandomCoffeeTopicID,
+		MembershipCacheTTL:  time.Minute,
+	}
+
+	repos := newBotRepositories(dbtest.Unavailable())
+	repos.User = store.Users()
+	repos.Profile = store.Profiles()
+	repos.Event = store.Events()
+	repos.RandomCoffeePoll = store.RandomCoffeePolls()
+	repos.RandomCoffeeParticipant = store.RandomCoffeeParticipants()
+	repos.RandomCoffeePair = store.RandomCoffeePairs()
+	repos.UserRole = store.UserRoles()
+	repos.Score = store.Scores()
+
+	// The user client is not configured, so it never connects
+	tgUserClient := clients.NewTelegramClient(appConfig, new(session.StorageMemory))
+
+	tb := &testBot{
+		t:      t,
+		server: server,
+		store:  store,
+		config: appConfig,
+		deps:   newHandlerDependencies(appConfig, server.Bot, nil, tgUserClient, noRateLimit{}, repos),
+	}
+	tb.dispatcher = ext.NewDispatcher(&ext.DispatcherOpts{
+		Error: func(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
+			t.Errorf("update %d handling failed: %v", ctx.UpdateId, err)
+			return ext.DispatcherActionNoop
+		},
+		Panic: func(b *gotgbot.Bot, ctx *ext.Context, r interface{}) {
+			t.Errorf("update %d handling panicked: %v", ctx.UpdateId, r)
+		},
+	})
+
+	for _, option := range options {
+		option(tb.deps)
+	}
+
+	client := &TgBotClient{bot: server.Bot, dispatcher: tb.dispatcher}
+	client.registerHandlers(tb.deps)
+	return tb
+}
+
+// process handles the update synchronously, like the updater does for a polled update
+func (tb *testBot) process(update *gotgbot.Update) {
+	tb.t.Helper()
+	require.NoError(tb.t, tb.dispatcher.ProcessUpdate(tb.server.Bot, update, nil))
+}
+
+// send sends the text to the bot in the private chat of the user
+func (tb *testBot) send(user gotgbot.User, text string) {
+	tb.t.Helper()
+	tb.process(tb.server.PrivateMessage(user, text))
+}
+
+// click clicks the button of the latest bot message in the private chat of the user
+func (tb *testBot) click(user gotgbot.User, buttonText string) {
+	tb.t.Helper()
+
+	msg := tb.server.LastBotMessage(user.Id)
+	require.NotNil(tb.t, msg, "no bot message to click %q on", buttonText)
+	data, ok := telegramtest.Button(msg, buttonText)
+	require.True(tb.t, ok, "no button %q under the message %q", buttonText, msg.Text)
+	tb.process(tb.server.CallbackQuery(user, msg, data))
+}
+
+// lastReply returns the text of the latest bot message in the private chat of the user
+func (tb *testBot) lastReply(user gotgbot.User) string {
+	tb.t.Helper()
+
+	msg := tb.server.LastBotMessage(user.Id)
+	require.NotNil(tb.t, msg, "the bot has not answered")
+	return msg.Text
+}
+
+func TestScenario_ProfileEditAndPublish(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	tb.send(testMember, "/"+constants.ProfileCommand)
+	assert.Contains(t, tb.lastReply(testMember), "Меню \"Профиль\"")
+
+	tb.click(testMember, "Редактировать")
+	assert.Contains(t, tb.lastReply(testMember), "Профиль → Редактирование")
+
+	tb.click(testMember, "О себе")
+	assert.Contains(t, tb.lastReply(testMember), "Введи обновлённую биографию")
+
+	tb.send(testMember, "Go developer, <3 Berlin")
+	assert.Contains(t, tb.lastReply(testMember), "Биография сохранена")
+
+	// The menus and the answer of the user are cleaned up, only the latest bot message stays
+	messages := tb.server.Messages(testMember.Id)
+	require.Len(t, messages, 2, "the /profile command and the latest bot message")
+	assert.Equal(t, "/"+constants.ProfileCommand, messages[0].Text)
+
+	dbUser, err := tb.store.Users().GetByTelegramID(testMember.Id)
+	require.NoError(t, err)
+	profile, err := tb.store.Profiles().GetOrCreate(dbUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Go developer, <3 Berlin", profile.Bio)
+
+	tb.click(testMember, "Опубликовать")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+
+	introChatID := utils.ChatIdToFullChatId(testSuperGroupChatID)
+	intro := tb.server.Messages(introChatID)
+	require.Len(t, intro, 1)
+	assert.Equal(t, int64(testIntroTopicID), intro[0].MessageThreadId)
+	assert.Contains(t, intro[0].Text, "Ivan Petrov")
+	assert.Contains(t, intro[0].Text, "(@ivan)")
+	assert.Contains(t, intro[0].Text, "Go developer, &lt;3 Berlin")
+
+	profile, err = tb.store.Profiles().GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, intro[0].MessageId, profile.PublishedMessageID.Int64)
+
+	// Publishing the unchanged profile again edits the published message instead of sending a new one
+	tb.server.ClearRequests()
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Опублик. (+ превью)")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+	assert.Len(t, tb.server.Requests("editMessageText"), 1)
+	assert.Len(t, tb.server.Messages(introChatID), 1)
+
+	tb.click(testMember, "Отмена")
+	assert.Equal(t, "Сессия работы с профилями завершена.", tb.lastReply(testMember))
+}
+
+func TestScenario_ProfileOptionalFields(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	tb.send(testMember, "/"+constants.ProfileCommand)
+	tb.click(testMember, "Редактировать")
+	tb.click(testMember, "Навыки")
+	assert.Contains(t, tb.lastReply(testMember), "Профиль → Редактирование → Навыки")
+	assert.Contains(t, tb.lastReply(testMember), "Текущее значение: отсутствует")
+
+	tb.send(testMember, "#Go, PostgreSQL, go")
+	assert.Contains(t, tb.lastReply(testMember), "Сохранено")
+
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Город")
+	tb.send(testMember, "Berlin, Germany")
+	assert.Contains(t, tb.lastReply(testMember), "Сохранено")
+
+	// Invalid links are asked again, nothing is saved
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Ссылки")
+	tb.send(testMember, "github.com/ivan/repo")
+	assert.Contains(t, tb.lastReply(testMember), "Не получилось разобрать ответ")
+
+	tb.send(testMember, "github.com/ivan @ivan_petrov")
+	assert.Contains(t, tb.lastReply(testMember), "Сохранено")
+
+	dbUser, err := tb.store.Users().GetByTelegramID(testMember.Id)
+	require.NoError(t, err)
+	profile, err := tb.store.Profiles().GetOrCreate(dbUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.ProfileTags{"Go", "PostgreSQL"}, profile.Skills)
+	assert.Equal(t, "Berlin", profile.City)
+	assert.Equal(t, "Germany", profile.Country)
+	assert.Equal(t, repositories.ProfileLinks{
+		"github":   "https://github.com/ivan",
+		"telegram": "https://t.me/ivan_petrov",
+	}, profile.Links)
+
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "О себе")
+	tb.send(testMember, "Go developer")
+	tb.click(testMember, "Опубликовать")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+
+	intro := tb.server.Messages(utils.ChatIdToFullChatId(testSuperGroupChatID))
+	require.Len(t, intro, 1)
+	assert.Contains(t, intro[0].Text, "📍 Berlin, Germany")
+	assert.Contains(t, intro[0].Text, "🛠 <b>Навыки:</b> Go, PostgreSQL")
+	assert.Contains(t, intro[0].Text,
+		`🔗 <a href="https://t.me/ivan_petrov">Telegram</a> · <a href="https://github.com/ivan">GitHub</a>`)
+}
+
+func TestScenario_ProfilePhoto(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+	introChatID := utils.ChatIdToFullChatId(testSuperGroupChatID)
+
+	tb.send(testMember, "/"+constants.ProfileCommand)
+	tb.click(testMember, "Редактировать")
+	tb.click(testMember, "О себе")
+	tb.send(testMember, "Go developer")
+
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Фото")
+	assert.Contains(t, tb.lastReply(testMember), "Профиль → Редактирование → Фото")
+	assert.Contains(t, tb.lastReply(testMember), "Текущее фото: отсутствует")
+
+	tb.send(testMember, "my photo")
+	assert.Contains(t, tb.lastReply(testMember), "Это не фото")
+
+	// The largest size of the uploaded photo is saved
+	tb.process(tb.server.PrivatePhoto(testMember, "ivan-photo"))
+	assert.Contains(t, tb.lastReply(testMember), "Фото сохранено")
+
+	dbUser, err := tb.store.Users().GetByTelegramID(testMember.Id)
+	require.NoError(t, err)
+	profile, err := tb.store.Profiles().GetOrCreate(dbUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ivan-photo", profile.PhotoFileID)
+
+	// The profile with a photo is published as the photo with the caption
+	tb.click(testMember, "Опубликовать")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+
+	intro := tb.server.Messages(introChatID)
+	require.Len(t, intro, 1)
+	require.NotEmpty(t, intro[0].Photo)
+	assert.Equal(t, "ivan-photo", intro[0].Photo[0].FileId)
+	assert.Equal(t, int64(testIntroTopicID), intro[0].MessageThreadId)
+	assert.Contains(t, intro[0].Caption, "Ivan Petrov")
+	assert.Contains(t, intro[0].Caption, "Go developer")
+
+	// A long bio does not fit into the caption and is published as the follow-up message,
+	// the published photo is edited in place
+	longBio := strings.Repeat("Пишу бэкенд на Go. ", 60)
+	require.NoError(t, tb.store.Profiles().Update(profile.ID, map[string]interface{}{"bio": longBio}))
+	tb.server.ClearRequests()
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Опублик. (+ превью)")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+	assert.Len(t, tb.server.Requests("editMessageMedia"), 1)
+
+	intro = tb.server.Messages(introChatID)
+	require.Len(t, intro, 2)
+	assert.Contains(t, intro[0].Caption, "Ivan Petrov")
+	assert.NotContains(t, intro[0].Caption, "Пишу бэкенд")
+	assert.Contains(t, intro[1].Text, "Пишу бэкенд на Go.")
+
+	profile, err = tb.store.Profiles().GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, intro[0].MessageId, profile.PublishedMessageID.Int64)
+	assert.True(t, profile.PublishedWithPhoto)
+	assert.Equal(t, intro[1].MessageId, profile.PublishedExtraMessageID.Int64)
+
+	// The Telegram avatar is copied only on the click of the user
+	tb.server.Handle("getUserProfilePhotos", func(r telegramtest.Request) (interface{}, error) {
+		return gotgbot.UserProfilePhotos{TotalCount: 1, Photos: [][]gotgbot.PhotoSize{{
+			{FileId: "avatar-small", FileUniqueId: "avatar-small"},
+			{FileId: "avatar", FileUniqueId: "avatar"},
+		}}}, nil
+	})
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Редактировать")
+	tb.click(testMember, "Фото")
+	assert.Contains(t, tb.server.LastBotMessage(testMember.Id).Caption, "Текущее фото — выше")
+	tb.click(testMember, "Взять аватарку из Telegram")
+	assert.Contains(t, tb.lastReply(testMember), "Аватарка из Telegram сохранена")
+
+	tb.server.ClearRequests()
+	tb.click(testMember, "Опубликовать")
+	assert.Len(t, tb.server.Requests("editMessageMedia"), 1)
+	assert.Len(t, tb.server.Requests("editMessageText"), 1, "the follow-up message is edited too")
+	intro = tb.server.Messages(introChatID)
+	require.Len(t, intro, 2)
+	assert.Equal(t, "avatar", intro[0].Photo[0].FileId)
+
+	// Without the photo the profile is published as text again, the photo and the follow-up are replaced
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Редактировать")
+	tb.click(testMember, "Фото")
+	tb.click(testMember, "Удалить фото")
+	assert.Contains(t, tb.lastReply(testMember), "Фото удалено")
+	tb.click(testMember, "Опубликовать")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+
+	intro = tb.server.Messages(introChatID)
+	require.Len(t, intro, 1)
+	assert.Empty(t, intro[0].Photo)
+	assert.Contains(t, intro[0].Text, "Пишу бэкенд на Go.")
+
+	profile, err = tb.store.Profiles().GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Empty(t, profile.PhotoFileID)
+	assert.Equal(t, intro[0].MessageId, profile.PublishedMessageID.Int64)
+	assert.False(t, profile.PublishedWithPhoto)
+	assert.False(t, profile.PublishedExtraMessageID.Valid)
+}
+
+func TestScenario_ProfilePhotoWithLongFacts(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+	introChatID := utils.ChatIdToFullChatId(testSuperGroupChatID)
+
+	tb.send(testMember, "/"+constants.ProfileCommand)
+	tb.click(testMember, "Редактировать")
+	tb.click(testMember, "О себе")
+	tb.send(testMember, "Go developer")
+
+	// The facts alone do not fit into the caption, so the caption keeps only the name
+	dbUser, err := tb.store.Users().GetByTelegramID(testMember.Id)
+	require.NoError(t, err)
+	profile, err := tb.store.Profiles().GetOrCreate(dbUser.ID)
+	require.NoError(t, err)
+	languages := make(repositories.ProfileTags, 0, constants.ProfileTagsLimit)
+	for i := 0; i < constants.ProfileTagsLimit; i++ {
+		languages = append(languages, strings.Repeat("я", constants.ProfileTagLengthLimit-2)+strconv.Itoa(10+i))
+	}
+	longText := strings.Repeat("ж", constants.ProfileTextLengthLimit)
+	require.NoError(t, tb.store.Profiles().Update(profile.ID, map[string]interface{}{
+		"job_title":     longText,
+		"company":       longText,
+		"city":          longText,
+		"country":       longText,
+		"languages":     languages,
+		"photo_file_id": "ivan-photo",
+	}))
+
+	// The follow-up message fails, the user is told and the published photo is kept
+	tb.server.Handle("sendMessage", func(r telegramtest.Request) (interface{}, error) {
+		if r.Int64("chat_id") == introChatID {
+			return nil, &telegramtest.APIError{Code: 400, Description: "Bad Request: message is too long"}
+		}
+		return tb.server.Default(r)
+	})
+	tb.click(testMember, "Опубликовать")
+	assert.Contains(t, tb.lastReply(testMember), "продолжение отправить не удалось")
+
+	intro := tb.server.Messages(introChatID)
+	require.Len(t, intro, 1)
+	profile, err = tb.store.Profiles().GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, intro[0].MessageId, profile.PublishedMessageID.Int64)
+	assert.False(t, profile.PublishedExtraMessageID.Valid)
+
+	// Publishing again edits the photo and sends the follow-up
+	tb.server.Handle("sendMessage", tb.server.Default)
+	tb.click(testMember, "Назад")
+	tb.click(testMember, "Опублик. (+ превью)")
+	assert.Contains(t, tb.lastReply(testMember), "успешно опубликован")
+
+	intro = tb.server.Messages(introChatID)
+	require.Len(t, intro, 2)
+	assert.LessOrEqual(t, utils.Utf16CodeUnitCount(intro[0].Caption), constants.ProfileCaptionLimit)
+	assert.Contains(t, intro[0].Caption, "Ivan Petrov")
+	assert.NotContains(t, intro[0].Caption, "💼")
+	assert.Contains(t, intro[1].Text, "💼 "+longText)
+	assert.Contains(t, intro[1].Text, "Go developer")
+}
+
+func TestScenario_ProfileTooLongToPublish(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	tb.send(testMember, "/"+constants.ProfileCommand)
+	tb.click(testMember, "Редактировать")
+	tb.click(testMember, "О себе")
+	tb.send(testMember, "Go developer")
+
+	// The bio within its limit and the full list of skills do not fit into one message together
+	dbUser, err := tb.store.Users().GetByTelegramID(testMember.Id)
+	require.NoError(t, err)
+	profile, err := tb.store.Profiles().GetOrCreate(dbUser.ID)
+	require.NoError(t, err)
+	skills := make(repositories.ProfileTags, 0, constants.ProfileTagsLimit)
+	for i := 0; i < constants.ProfileTagsLimit; i++ {
+		skills = append(skills, strings.Repeat("s", constants.ProfileTagLengthLimit-2)+strconv.Itoa(10+i))
+	}
+	require.NoError(t, tb.store.Profiles().Update(profile.ID, map[string]interface{}{
+		"bio":    strings.Repeat("б", constants.ProfileBioLengthLimit),
+		"skills": skills,
+	}))
+
+	tb.click(testMember, "Опубликовать")
+	assert.Contains(t, tb.lastReply(testMember), "не помещается в одно сообщение")
+	assert.Regexp(t, `на \d+ символов`, tb.lastReply(testMember))
+	assert.Empty(t, tb.server.Messages(utils.ChatIdToFullChatId(testSuperGroupChatID)))
+}
+
+func TestScenario_Directory(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	// Seven Go developers in Berlin, so the results take two pages, and one designer
+	for i := 1; i <= 7; i++ {
+		userID, err := tb.store.Users().Create(int64(3000+i), "Dev"+strconv.Itoa(i), "Backend", "")
+		require.NoError(t, err)
+		profile, err := tb.store.Profiles().GetOrCreate(userID)
+		require.NoError(t, err)
+		require.NoError(t, tb.store.Profiles().Update(profile.ID, map[string]interface{}{
+			"bio":    "Backend на Go",
+			"city":   "Berlin",
+			"skills": repositories.ProfileTags{"Go"},
+		}))
+		require.NoError(t, tb.store.Profiles().UpdatePublishedMessageID(profile.ID, int64(100+i)))
+	}
+	designerID, err := tb.store.Users().Create(4001, "Anna", "Smirnova", "anna")
+	require.NoError(t, err)
+	designer, err := tb.store.Profiles().GetOrCreate(designerID)
+	require.NoError(t, err)
+	require.NoError(t, tb.store.Profiles().Update(designer.ID, map[string]interface{}{
+		"bio":       "Дизайнер интерфейсов",
+		"job_title": "Product designer",
+	}))
+
+	tb.send(testMember, "/"+constants.DirectoryCommand)
+	assert.Contains(t, tb.lastReply(testMember), "Каталог участников")
+	assert.Contains(t, tb.lastReply(testMember), "город:Берлин")
+
+	tb.send(testMember, "backend #go город:berlin")
+	reply := tb.lastReply(testMember)
+	assert.Contains(t, reply, "Найдено: <b>7</b> · страница 1 из 2")
+	assert.Contains(t, reply, "📍 berlin")
+	assert.Contains(t, reply, "Интро")
+	assert.NotContains(t, reply, "Anna")
+
+	// Pages are shown in the same message
+	results := tb.server.LastBotMessage(testMember.Id)
+	tb.click(testMember, "Вперёд")
+	assert.Equal(t, results.MessageId, tb.server.LastBotMessage(testMember.Id).MessageId)
+	assert.Contains(t, tb.lastReply(testMember), "страница 2 из 2")
+	assert.Contains(t, tb.lastReply(testMember), "6. ")
+
+	// Former members are shown only with the status filter
+	require.NoError(t, tb.store.Users().SetClubMemberStatus(designerID, false))
+	tb.send(testMember, "/"+constants.DirectoryCommand+" Smirnva")
+	assert.Contains(t, tb.lastReply(testMember), "Никого не нашёл")
+	tb.click(testMember, "Только участники клуба")
+	assert.Contains(t, tb.lastReply(testMember), "Anna Smirnova</a></b> (@anna)")
+	assert.Contains(t, tb.lastReply(testMember), "Product designer")
+
+	tb.click(testMember, "Закрыть")
+	assert.Equal(t, "Поиск по каталогу участников завершён.", tb.lastReply(testMember))
+}
+
+func TestScenario_EventSetup(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	// Members without the capability are refused
+	tb.send(testMember, "/"+constants.EventSetupCommand)
+	assert.Equal(t, "Эта команда недоступна для твоей роли.", tb.lastReply(testMember))
+
+	tb.send(testAdmin, "/"+constants.EventSetupCommand)
+	assert.Contains(t, tb.lastReply(testAdmin), "введи название")
+
+	tb.send(testAdmin, "Go meetup")
+	assert.Contains(t, tb.lastReply(testAdmin), "Выбери тип мероприятия")
+
+	// The cancel button of the previous question is removed once it is answered
+	typeQuestion := tb.server.LastBotMessage(testAdmin.Id)
+	require.NotNil(t, typeQuestion.ReplyMarkup)
+
+	tb.send(testAdmin, "42")
+	assert.Contains(t, tb.lastReply(testAdmin), "Неверный выбор")
+
+	meetupIndex := slices.Index(constants.AllEventTypes, constants.EventTypeMeetup) + 1
+	tb.send(testAdmin, strconv.Itoa(meetupIndex))
+	assert.Contains(t, tb.lastReply(testAdmin), "Когда стартует мероприятие?")
+	for _, msg := range tb.server.Messages(testAdmin.Id) {
+		if msg.MessageId == typeQuestion.MessageId {
+			assert.Nil(t, msg.ReplyMarkup)
+		}
+	}
+
+	tb.send(testAdmin, "25.12.2025 19:00")
+	assert.Contains(t, tb.lastReply(testAdmin), "Сколько мест на мероприятии?")
+
+	tb.send(testAdmin, "30")
+	assert.Contains(t, tb.lastReply(testAdmin), "Запись о мероприятии '*Go meetup*' успешно создана")
+
+	events, err := tb.store.Events().GetLastEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Go meetup", events[0].Name)
+	assert.Equal(t, string(constants.EventTypeMeetup), events[0].Type)
+	require.NotNil(t, events[0].StartedAt)
+	assert.True(t, time.Date(2025, time.December, 25, 19, 0, 0, 0, time.UTC).Equal(*events[0].StartedAt))
+	require.NotNil(t, events[0].Capacity)
+	assert.Equal(t, 30, *events[0].Capacity)
+}
+
+func TestScenario_RandomCoffeePollAnswer(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	require.NoError(t, tb.deps.RandomCoffeeService.SendPoll(context.Background()))
+
+	groupChatID := utils.ChatIdToFullChatId(testSuperGroupChatID)
+	polls := tb.server.Requests("sendPoll")
+	require.Len(t, polls, 1)
+	assert.Equal(t, strconv.Itoa(testRandomCoffeeTopicID), polls[0].Params["message_thread_id"])
+	pinned := tb.server.Requests("pinChatMessage")
+	require.Len(t, pinned, 1)
+
+	var pollMsg *gotgbot.Message
+	for _, msg := range tb.server.Messages(groupChatID) {
+		if msg.Poll != nil {
+			pollMsg = &msg
+		}
+	}
+	require.NotNil(t, pollMsg)
+	assert.Equal(t, strconv.FormatInt(pollMsg.MessageId, 10), pinned[0].Params["message_id"])
+
+	poll, err := tb.store.RandomCoffeePolls().GetLatestPoll()
+	require.NoError(t, err)
+	require.NotNil(t, poll)
+	assert.Equal(t, pollMsg.Poll.Id, poll.TelegramPollID)
+
+	// "Yes" makes the user a participant, retracting the vote removes them
+	tb.process(tb.server.PollAnswer(testMember, poll.TelegramPollID, 0))
+	participants, err := tb.store.RandomCoffeeParticipants().GetParticipatingUsers(poll.ID)
+	require.NoError(t, err)
+	require.Len(t, participants, 1)
+	assert.Equal(t, testMember.Id, participants[0].TgID)
+
+	tb.process(tb.server.PollAnswer(testMember, poll.TelegramPollID))
+	participants, err = tb.store.RandomCoffeeParticipants().GetParticipatingUsers(poll.ID)
+	require.NoError(t, err)
+	assert.Empty(t, participants)
+
+	// Banned users are told to retract the vote and are not registered
+	banned := gotgbot.User{Id: 2002, FirstName: "Petr"}
+	dbUser, err := tb.store.Users().GetOrCreate(&banned)
+	require.NoError(t, err)
+	require.NoError(t, tb.store.Users().SetCoffeeBan(dbUser.ID, true))
+
+	tb.process(tb.server.PollAnswer(banned, poll.TelegramPollID, 0))
+	assert.True(t, strings.HasPrefix(tb.lastReply(banned), "🚫 К сожалению, участие в опросе Random Coffee для тебя недоступно"))
+	participant, err := tb.store.RandomCoffeeParticipants().GetParticipant(poll.ID, int64(dbUser.ID))
+	require.NoError(t, err)
+	assert.Nil(t, participant)
+}
+
+func TestScenario_RandomCoffeeMetCreditsConfirmingMember(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	partner := gotgbot.User{Id: 2002, FirstName: "Petr", Username: "petr"}
+	memberID, err := tb.store.Users().Create(testMember.Id, testMember.FirstName, testMember.LastName, testMember.Username)
+	require.NoError(t, err)
+	partnerID, err := tb.store.Users().Create(partner.Id, partner.FirstName, partner.LastName, partner.Username)
+	require.NoError(t, err)
+	pollID, err := tb.store.RandomCoffeePolls().CreatePoll(repositories.RandomCoffeePoll{
+		MessageID: 1, WeekStartDate: time.Now(), TelegramPollID: "poll",
+	})
+	require.NoError(t, err)
+	require.NoError(t, tb.store.RandomCoffeePairs().CreatePair(int(pollID), memberID, partnerID))
+
+	pairsMsg := &gotgbot.Message{
+		MessageId: 100,
+		Chat:      gotgbot.Chat{Id: utils.ChatIdToFullChatId(testSuperGroupChatID), Type: "supergroup", IsForum: true},
+	}
+	metData := constants.RandomCoffeeMetCallbackPrefix + strconv.FormatInt(pollID, 10)
+	points := constants.ScoreRules[constants.ScoreReasonRandomCoffeeCompleted]
+	credited := "Тебе начислено +" + strconv.Itoa(points) + " к карме"
+	score := func(userID int) int {
+		user, err := tb.store.Users().GetByID(userID)
+		require.NoError(t, err)
+		return user.Score
+	}
+	lastAnswer := func() string {
+		answers := tb.server.Requests("answerCallbackQuery")
+		require.NotEmpty(t, answers)
+		return answers[len(answers)-1].Params["text"]
+	}
+
+	// Only the member who pressed the button is credited
+	tb.process(tb.server.CallbackQuery(testMember, pairsMsg, metData))
+	assert.Contains(t, lastAnswer(), credited)
+	assert.Equal(t, points, score(memberID))
+	assert.Zero(t, score(partnerID))
+
+	tb.process(tb.server.CallbackQuery(testMember, pairsMsg, metData))
+	assert.Equal(t, "Ты уже отметил(а) эту встречу. Спасибо!", lastAnswer())
+	assert.Equal(t, points, score(memberID))
+
+	tb.process(tb.server.CallbackQuery(partner, pairsMsg, metData))
+	assert.Contains(t, lastAnswer(), credited)
+	assert.Equal(t, points, score(partnerID))
+
+	// Members outside the pair get nothing
+	tb.process(tb.server.CallbackQuery(testAdmin, pairsMsg, metData))
+	assert.Equal(t, "Эта кнопка только для участников пар этой недели.", lastAnswer())
+}
+
+func TestScenario_LostCaptchaSendsNewCaptcha(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+	tb.config.CaptchaTimeout = time.Minute
+
+	// The captcha was sent before a restart and is not pending anymore
+	groupChatID := utils.ChatIdToFullChatId(testSuperGroupChatID)
+	groupChat := gotgbot.Chat{Id: groupChatID, Type: "supergroup"}
+	lostCaptcha := func(user gotgbot.User, option int) {
+		captcha := tb.server.Message(groupChat, telegramtest.BotUser, "captcha").Message
+		data := constants.CaptchaAnswerCallbackPrefix + strconv.FormatInt(user.Id, 10) + "_" + strconv.Itoa(option)
+		tb.process(tb.server.CallbackQuery(user, captcha, data))
+	}
+	lastAnswer := func() string {
+		answers := tb.server.Requests("answerCallbackQuery")
+		require.NotEmpty(t, answers)
+		return answers[len(answers)-1].Params["text"]
+	}
+	memberStatus := func(status string, untilDate int64) {
+		tb.server.Handle("getChatMember", func(r telegramtest.Request) (interface{}, error) {
+			return map[string]interface{}{
+				"status":     status,
+				"user":       gotgbot.User{Id: r.Int64("user_id"), FirstName: "User"},
+				"until_date": untilDate,
+			}, nil
+		})
+	}
+
+	// A member who is not restricted anymore is told the check is over
+	lostCaptcha(testMember, 0)
+	assert.Empty(t, tb.server.Requests("restrictChatMember"))
+	assert.Equal(t, "Проверка уже завершена.", lastAnswer())
+
+	// A muted member can not lift the mute with a leftover captcha button
+	memberStatus("restricted", time.Now().Add(time.Hour).Unix())
+	tb.server.ClearRequests()
+	lostCaptcha(testMember, 0)
+	assert.Empty(t, tb.server.Requests("restrictChatMember"))
+	assert.Empty(t, tb.server.Requests("sendMessage"))
+	assert.Equal(t, "Проверка уже завершена.", lastAnswer())
+
+	// A member restricted by the lost captcha is not let in by any button, a new captcha is sent instead
+	memberStatus("restricted", 0)
+	tb.server.ClearRequests()
+	lostCaptcha(testMember, 3)
+	assert.Empty(t, tb.server.Requests("restrictChatMember"))
+	assert.Len(t, tb.server.Requests("deleteMessage"), 1, "the stale captcha is removed")
+	assert.Contains(t, lastAnswer(), "отправил новую")
+
+	newCaptcha := tb.server.LastBotMessage(groupChatID)
+	require.NotNil(t, newCaptcha)
+	wrong, _ := captchaOptions(t, newCaptcha)
+
+	// The wrong answer to the new captcha removes the member without lifting the restrictions
+	tb.server.ClearRequests()
+	tb.process(tb.server.CallbackQuery(testMember, newCaptcha, wrong))
+	assert.Empty(t, tb.server.Requests("restrictChatMember"))
+	assert.Len(t, tb.server.Requests("banChatMember"), 1)
+	assert.Contains(t, lastAnswer(), "Неверный ответ")
+
+	// The right answer to the new captcha lets the member in
+	other := gotgbot.User{Id: 2003, FirstName: "Olga"}
+	lostCaptcha(other, 0)
+	newCaptcha = tb.server.LastBotMessage(groupChatID)
+	require.NotNil(t, newCaptcha)
+	_, right := captchaOptions(t, newCaptcha)
+
+	tb.server.ClearRequests()
+	tb.process(tb.server.CallbackQuery(other, newCaptcha, right))
+	restricted := tb.server.Requests("restrictChatMember")
+	require.Len(t, restricted, 1)
+	assert.Equal(t, strconv.FormatInt(other.Id, 10), restricted[0].Params["user_id"])
+	assert.Contains(t, restricted[0].Params["permissions"], `"can_send_messages":true`)
+	assert.Equal(t, "Спасибо! Добро пожаловать в клуб 🎉", lastAnswer())
+}
+
+// captchaOptions returns the callback data of a wrong and of the right option of the captcha message
+func captchaOptions(t *testing.T, captcha *gotgbot.Message) (string, string) {
+	t.Helper()
+
+	require.NotNil(t, captcha.ReplyMarkup, "the captcha has no buttons")
+	var wrong, right string
+	for _, button := range captcha.ReplyMarkup.InlineKeyboard[0] {
+		if strings.Contains(captcha.Text, "<b>"+constants.CaptchaOptions[button.Text]+"</b>") {
+			right = button.CallbackData
+		} else {
+			wrong = button.CallbackData
+		}
+	}
+	require.NotEmpty(t, right)
+	require.NotEmpty(t, wrong)
+	return wrong, right
+}
+
+func TestScenario_RolesOwnerAndAdminAreChangedByOwnerOnly(t *testing.T) {
+	t.Parallel()
+	tb := newTestBot(t)
+
+	// An admin of the club chat manages roles, but is not the owner
+	chatAdmin := gotgbot.User{Id: 3001, FirstName: "Chat", LastName: "Admin", Username: "chatadmin"}
+	tb.server.Handle("getChatMember", func(r telegramtest.Request) (interface{}, error) {
+		if r.Int64("user_id") != chatAdmin.Id {
+			return tb.server.Default(r)
+		}
+		return map[string]interface{}{"status": "administrator", "user": chatAdmin}, nil
+	})
+
+	_, err := tb.store.Users().Create(testMember.Id, testMember.FirstName, testMember.LastName, testMember.Username)
+	require.NoError(t, err)
+	_, err = tb.store.Users().Create(testAdmin.Id, testAdmin.FirstName, testAdmin.LastName, testAdmin.Username)
+	require.NoError(t, err)
+
+	tb.send(testMember, "/"+constants.RolesCommand+" grant @ivan moderator")
+	assert.Equal(t, "Эта команда недоступна для твоей роли.", tb.lastReply(testMember))
+
+	for _, command := range []string{
+		"grant @ivan admin",
+		"grant @ivan owner",
+		"revoke @admin owner",
+		"revoke " + strconv.FormatInt(testAdmin.Id, 10) + " admin",
+	} {
+		tb.send(chatAdmin, "/"+constants.RolesCommand+" "+command)
+		assert.Equal(t, "Роли владельца и администратора может менять только владелец.", tb.lastReply(chatAdmin), command)
+	}
+	roles, err := tb.store.UserRoles().GetAll()
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	// Other roles are managed by admins
+	tb.send(chatAdmin, "/"+constants.RolesCommand+" grant @ivan moderator")
+	assert.Contains(t, tb.lastReply(chatAdmin), "Готово")
+	assert.True(t, tb.deps.PermissionsService.HasCapability(testMember.Id, constants.CapabilityModerate))
+
+	// The owner changes the admin roles
+	tb.send(testAdmin, "/"+constants.RolesCommand+" grant @ivan admin")
+	assert.Contains(t, tb.lastReply(testAdmin), "Готово")
+	roles, err = tb.store.UserRoles().GetAll()
+	require.NoError(t, err)
+	assert.Len(t, roles, 2)
+}
+
+// fakeTgUserClientLogin logs in like the user client: the code is 12345 and the 2FA password is "secret"
+type fakeTgUserClientLogin struct {
+	clients.TgUserClientLogin
+
+	mu           sync.Mutex
+	step         clients.LoginStep
+	resendErr    error      // returned when a new code is requested
+	codeErr      error      // returned for the right code
+	qrResult     chan error // the result of the QR login, sent once the QR code is shown
+	cancelQR     context.CancelFunc
+	loggedOut    bool
+	passwordUsed bool
+}
+
+func newFakeTgUserClientLogin() *fakeTgUserClientLogin {
+	return &fakeTgUserClientLogin{qrResult: make(chan error, 1)}
+}
+
+func (c *fakeTgUserClientLogin) setStep(step clients.LoginStep) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.step = step
+}
+
+func (c *fakeTgUserClientLogin) GetLoginStep() clients.LoginStep {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.step
+}
+
+func (c *fakeTgUserClientLogin) SendLoginCode(ctx context.Context) (*clients.LoginCode, error) {
+	c.setStep(clients.LoginStepCode)
+	return &clients.LoginCode{Type: "app", ExpiresAt: time.Now().Add(5 * time.Minute), ResendAfter: time.Now()}, nil
+}
+
+func (c *fakeTgUserClientLogin) ResendLoginCode(ctx context.Context) (*clients.LoginCode, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resendErr != nil {
+		return nil, c.resendErr
+	}
+	return &clients.LoginCode{Type: "sms", ExpiresAt: time.Now().Add(5 * time.Minute), ResendAfter: time.Now()}, nil
+}
+
+func (c *fakeTgUserClientLogin) SignInWithCode(ctx context.Context, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.step != clients.LoginStepCode {
+		return clients.ErrLoginNotStarted
+	}
+	if code != "12345" {
+		return clients.ErrLoginCodeInvalid
+	}
+
+	switch {
+	case errors.Is(c.codeErr, clients.ErrLoginPasswordNeeded):
+		c.step = clients.LoginStepPassword
+	default:
+		c.step = clients.LoginStepNone
+	}
+	return c.codeErr
+}
+
+func (c *fakeTgUserClientLogin) LoginWithQR(ctx context.Context, show func(ctx context.Context, qrCode *clients.LoginQRCode) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.mu.Lock()
+	c.step = clients.LoginStepQR
+	c.cancelQR = cancel
+	c.mu.Unlock()
+
+	if err := show(ctx, &clients.LoginQRCode{PNG: []byte("png"), ExpiresAt: time.Now().Add(30 * time.Second)}); err != nil {
+		return err
+	}
+
+	var err error
+	select {
+	case err = <-c.qrResult:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	switch {
+	case errors.Is(err, clients.ErrLoginPasswordNeeded):
+		c.setStep(clients.LoginStepPassword)
+	default:
+		c.setStep(clients.LoginStepNone)
+	}
+	return err
+}
+
+func (c *fakeTgUserClientLogin) SignInWithPassword(ctx context.Context, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.step != clients.LoginStepPassword {
+		return clients.ErrLoginNotStarted
+	}
+	if password != "secret" {
+		return clients.ErrLoginPasswordInvalid
+	}
+	c.step = clients.LoginStepNone
+	c.passwordUsed = true
+	return nil
+}
+
+func (c *fakeTgUserClientLogin) CancelLogin() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.step = clients.LoginStepNone
+	if c.cancelQR != nil {
+		c.cancelQR()
+	}
+}
+
+func (c *fakeTgUserClientLogin) Logout(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loggedOut = true
+	return nil
+}
+
+func (c *fakeTgUserClientLogin) set(update func(c *fakeTgUserClientLogin)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	update(c)
+}
+
+// newTestBotWithLogin creates the bot with the fake user client login
+func newTestBotWithLogin(t *testing.T) (*testBot, *fakeTgUserClientLogin) {
+	t.Helper()
+
+	login := newFakeTgUserClientLogin()
+	tb := newTestBot(t, func(deps *HandlerDependencies) {
+		deps.TgUserClientLogin = login
+	})
+	return tb, login
+}
+
+// waitForReply waits for the bot message sent in the background that contains the text
+func (tb *testBot) waitForReply(user gotgbot.User, text string) {
+	tb.t.Helper()
+
+	require.Eventually(tb.t, func() bool {
+		msg := tb.server.LastBotMessage(user.Id)
+		return msg != nil && strings.Contains(msg.Text, text)
+	}, 5*time.Second, 10*time.Millisecond, "the bot has not answered %q", text)
+}
+
+func TestScenario_UserClientLoginWithCode(t *testing.T) {
+	t.Parallel()
+	tb, login := newTestBotWithLogin(t)
+
+	// Only the owner manages the user client
+	tb.send(testMember, "/"+constants.UserClientCommand+" "+constants.UserClientLoginSubcommand)
+	assert.NotContains(t, tb.lastReply(testMember), "Как войти")
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLoginSubcommand)
+	assert.Contains(t, tb.lastReply(testAdmin), "Как войти в аккаунт TG-клиента?")
+
+	tb.click(testAdmin, "🔢 Код из Telegram")
+	assert.Contains(t, tb.lastReply(testAdmin), "Код отправлен")
+	assert.Equal(t, clients.LoginStepCode, login.GetLoginStep())
+
+	// A new code can not be requested before the timeout, the admin is told so in an alert
+	login.set(func(c *fakeTgUserClientLogin) { c.resendErr = clients.ErrLoginResendTooEarly })
+	tb.server.ClearRequests()
+	tb.click(testAdmin, "🔁 Отправить код повторно")
+	answers := tb.server.Requests("answerCallbackQuery")
+	require.Len(t, answers, 1)
+	assert.Equal(t, "true", answers[0].Params["show_alert"])
+	assert.Equal(t, "Новый код пока нельзя запросить, подожди немного.", answers[0].Params["text"])
+	assert.Empty(t, tb.server.Requests("sendMessage"))
+
+	login.set(func(c *fakeTgUserClientLogin) { c.resendErr = nil })
+	tb.click(testAdmin, "🔁 Отправить код повторно")
+	assert.Contains(t, tb.lastReply(testAdmin), "Код отправлен по SMS")
+
+	// The code is entered reversed
+	tb.send(testAdmin, "12345")
+	assert.Contains(t, tb.lastReply(testAdmin), "Неверный код")
+
+	login.set(func(c *fakeTgUserClientLogin) { c.codeErr = clients.ErrLoginPasswordNeeded })
+	tb.send(testAdmin, "54321")
+	assert.Contains(t, tb.lastReply(testAdmin), "🔐 У аккаунта включена двухэтапная аутентификация")
+
+	// The password messages are deleted
+	tb.server.ClearRequests()
+	tb.send(testAdmin, "wrong")
+	assert.Equal(t, "Неверный пароль, попробуй ещё раз.", tb.lastReply(testAdmin))
+	tb.send(testAdmin, "secret")
+	assert.Equal(t, "✅ Вход выполнен, TG-клиент снова работает.", tb.lastReply(testAdmin))
+	assert.Len(t, tb.server.Requests("deleteMessage"), 2)
+	assert.Equal(t, clients.LoginStepNone, login.GetLoginStep())
+
+	// The conversation is over
+	tb.send(testAdmin, "secret")
+	assert.Equal(t, "✅ Вход выполнен, TG-клиент снова работает.", tb.lastReply(testAdmin))
+}
+
+func TestScenario_UserClientLoginCodeExpired(t *testing.T) {
+	t.Parallel()
+	tb, login := newTestBotWithLogin(t)
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLoginSubcommand)
+	tb.click(testAdmin, "🔢 Код из Telegram")
+
+	login.set(func(c *fakeTgUserClientLogin) { c.codeErr = clients.ErrLoginExpired })
+	tb.send(testAdmin, "54321")
+	assert.Contains(t, tb.lastReply(testAdmin), "⌛ Время на вход истекло")
+
+	// The conversation is over, so the next message is not taken for a code
+	tb.send(testAdmin, "54321")
+	assert.Contains(t, tb.lastReply(testAdmin), "⌛ Время на вход истекло")
+}
+
+func TestScenario_UserClientLoginWithQR(t *testing.T) {
+	t.Parallel()
+	tb, login := newTestBotWithLogin(t)
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLoginSubcommand)
+	tb.click(testAdmin, "📷 QR-код")
+	require.Eventually(t, func() bool {
+		return len(tb.server.Requests("sendPhoto")) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the QR code is not sent")
+
+	// The password is not asked until the QR code is scanned
+	tb.send(testAdmin, "secret")
+	assert.Equal(t, "Сначала отсканируй QR-код в приложении Telegram.", tb.lastReply(testAdmin))
+
+	login.qrResult <- clients.ErrLoginPasswordNeeded
+	tb.waitForReply(testAdmin, "🔐 У аккаунта включена двухэтапная аутентификация")
+	for _, msg := range tb.server.Messages(testAdmin.Id) {
+		assert.Nil(t, msg.Photo, "the scanned QR code is deleted")
+	}
+
+	tb.send(testAdmin, "secret")
+	assert.Equal(t, "✅ Вход выполнен, TG-клиент снова работает.", tb.lastReply(testAdmin))
+	login.set(func(c *fakeTgUserClientLogin) { assert.True(t, c.passwordUsed) })
+}
+
+func TestScenario_UserClientLoginWithQRTimeout(t *testing.T) {
+	t.Parallel()
+	tb, login := newTestBotWithLogin(t)
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLoginSubcommand)
+	tb.click(testAdmin, "📷 QR-код")
+
+	login.qrResult <- context.DeadlineExceeded
+	tb.waitForReply(testAdmin, "⌛ QR-код так и не отсканировали")
+	assert.Equal(t, clients.LoginStepNone, login.GetLoginStep())
+}
+
+func TestScenario_UserClientLoginWithQRCancelled(t *testing.T) {
+	t.Parallel()
+	tb, login := newTestBotWithLogin(t)
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLoginSubcommand)
+	tb.click(testAdmin, "📷 QR-код")
+	require.Eventually(t, func() bool {
+		return len(tb.server.Requests("sendPhoto")) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the QR code is not sent")
+
+	tb.send(testAdmin, "/"+constants.CancelCommand)
+	assert.Equal(t, "Операция отменена.", tb.lastReply(testAdmin))
+
+	// The QR login stops quietly and deletes the QR code
+	require.Eventually(t, func() bool {
+		return len(tb.server.Requests("deleteMessage")) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the QR code is not deleted")
+	assert.Equal(t, "Операция отменена.", tb.lastReply(testAdmin))
+	assert.Equal(t, clients.LoginStepNone, login.GetLoginStep())
+}
+
+func TestScenario_UserClientLogout(t *testing.T) {
+	t.Parallel()
+	tb, login := newTestBotWithLogin(t)
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLogoutSubcommand)
+	tb.click(testAdmin, "❌ Отмена")
+	assert.Equal(t, "Операция отменена.", tb.lastReply(testAdmin))
+
+	tb.send(testAdmin, "/"+constants.UserClientCommand+" "+constants.UserClientLogoutSubcommand)
+	tb.click(testAdmin, "✅ Подтвердить")
+	assert.Contains(t, tb.lastReply(testAdmin), "✅ Сессия завершена")
+	login.set(func(c *fakeTgUserClientLogin) { assert.True(t, c.loggedOut) })
+}
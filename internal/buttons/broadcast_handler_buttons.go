@@ -0,0 +1,63 @@
+package buttons
+
+import (
+	"evo-bot-go/internal/constants"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func BroadcastAudienceButtons() gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{
+					Text:         "👥 Все участники клуба",
+					CallbackData: constants.BroadcastAudienceAllMembersCallback,
+				},
+			},
+			{
+				{
+					Text:         "☕️ Участники Random Coffee",
+					CallbackData: constants.BroadcastAudienceCoffeeCallback,
+				},
+			},
+			{
+				{
+					Text:         "📝 Участники без профиля",
+					CallbackData: constants.BroadcastAudienceNoProfileCallback,
+				},
+			},
+			{
+				{
+					Text:         "📅 Записавшиеся на мероприятие",
+					CallbackData: constants.BroadcastAudienceEventCallback,
+				},
+			},
+			{
+				{
+					Text:         "❌ Отмена",
+					CallbackData: constants.BroadcastCancelCallback,
+				},
+			},
+		},
+	}
+}
+
+func BroadcastSendNowButtons() gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{
+					Text:         "🚀 Отправить сейчас",
+					CallbackData: constants.BroadcastSendNowCallback,
+				},
+			},
+			{
+				{
+					Text:         "❌ Отмена",
+					CallbackData: constants.BroadcastCancelCallback,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package buttons
+
+import (
+	"evo-bot-go/internal/constants"
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+var directoryStatusButtonTitles = map[constants.DirectoryStatus]string{
+	constants.DirectoryStatusMembers: "👥 Только участники клуба",
+	constants.DirectoryStatusAll:     "👥 Все, включая бывших",
+	constants.DirectoryStatusFormer:  "👥 Только бывшие участники",
+}
+
+// DirectoryResultsButtons returns the pagination, the membership filter and the search buttons.
+// The rephrase button is shown only when the query can be rephrased by the LLM.
+func DirectoryResultsButtons(page, pages int, status constants.DirectoryStatus, canRephrase bool) gotgbot.InlineKeyboardMarkup {
+	var rows [][]gotgbot.InlineKeyboardButton
+
+	var pagination []gotgbot.InlineKeyboardButton
+	if page > 0 {
+		pagination = append(pagination, gotgbot.InlineKeyboardButton{
+			Text:         "◀️ Назад",
+			CallbackData: fmt.Sprintf("%s%d", constants.DirectoryPagePrefix, page-1),
+		})
+	}
+	if page < pages-1 {
+		pagination = append(pagination, gotgbot.InlineKeyboardButton{
+			Text:         "Вперёд ▶️",
+			CallbackData: fmt.Sprintf("%s%d", constants.DirectoryPagePrefix, page+1),
+		})
+	}
+	if len(pagination) > 0 {
+		rows = append(rows, pagination)
+	}
+
+	rows = append(rows, []gotgbot.InlineKeyboardButton{
+		{
+			Text:         directoryStatusButtonTitles[status],
+			CallbackData: constants.DirectoryStatusCallback,
+		},
+	})
+
+	if canRephrase {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         "🧠 Расширить запрос с ИИ",
+				CallbackData: constants.DirectoryRephraseCallback,
+			},
+		})
+	}
+
+	rows = append(rows, []gotgbot.InlineKeyboardButton{
+		{
+			Text:         "🔎 Новый поиск",
+			CallbackData: constants.DirectoryNewSearchCallback,
+		},
+		{
+			Text:         "❌ Закрыть",
+			CallbackData: constants.DirectoryCancelCallback,
+		},
+	})
+
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -0,0 +1,32 @@
+package buttons
+
+import (
+	"evo-bot-go/internal/constants"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func ModerationRulesMainMenuButtons() gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{
+					Text:         "➕ Добавить или изменить правило",
+					CallbackData: constants.ModerationRulesSaveCallback,
+				},
+			},
+			{
+				{
+					Text:         "🗑 Удалить правило",
+					CallbackData: constants.ModerationRulesDeleteCallback,
+				},
+			},
+			{
+				{
+					Text:         "❌ Отмена",
+					CallbackData: constants.ModerationRulesCancelCallback,
+				},
+			},
+		},
+	}
+}
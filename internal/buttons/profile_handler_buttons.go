@@ -87,6 +87,40 @@ func ProfileBackCancelButtons(backCallbackData string) gotgbot.InlineKeyboardMar
 	}
 }
 
+// ProfilePhotoButtons returns the buttons of the profile photo menu, the delete button is shown only if there is a photo
+func ProfilePhotoButtons(hasPhoto bool) gotgbot.InlineKeyboardMarkup {
+	buttons := [][]gotgbot.InlineKeyboardButton{
+		{
+			{
+				Text:         "👤 Взять аватарку из Telegram",
+				CallbackData: constants.ProfilePhotoFromTelegramCallback,
+			},
+		},
+	}
+	if hasPhoto {
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         "🗑 Удалить фото",
+				CallbackData: constants.ProfilePhotoDeleteCallback,
+			},
+		})
+	}
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+		{
+			Text:         "◀️ Назад",
+			CallbackData: constants.ProfileEditMyProfileCallback,
+		},
+		{
+			Text:         "❌ Отмена",
+			CallbackData: constants.ProfileFullCancel,
+		},
+	})
+
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: buttons,
+	}
+}
+
 func ProfileBackPublishCancelButtons(backCallbackData string) gotgbot.InlineKeyboardMarkup {
 	return gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
@@ -124,7 +158,24 @@ func ProfileEditButtons(backCallbackData string) gotgbot.InlineKeyboardMarkup {
 				CallbackData: constants.ProfileEditBioCallback,
 			},
 		},
-		{
+	}
+	buttons = append(buttons, ProfileFieldsButtons(constants.ProfileEditFieldPrefix)...)
+	buttons = append(buttons,
+		[]gotgbot.InlineKeyboardButton{
+			{
+				Text:         "🕒 Часовой пояс",
+				CallbackData: constants.ProfileEditTimezoneCallback,
+			},
+			{
+				Text:         "🔗 Ссылки",
+				CallbackData: constants.ProfileEditFieldPrefix + string(constants.ProfileFieldLinks),
+			},
+			{
+				Text:         "🖼 Фото",
+				CallbackData: constants.ProfileEditPhotoCallback,
+			},
+		},
+		[]gotgbot.InlineKeyboardButton{
 			{
 				Text:         "◀️ Назад",
 				CallbackData: backCallbackData,
@@ -134,10 +185,45 @@ func ProfileEditButtons(backCallbackData string) gotgbot.InlineKeyboardMarkup {
 				CallbackData: constants.ProfileFullCancel,
 			},
 		},
-	}
+	)
 
 	return gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: buttons,
 	}
 }
 
+// ProfileFieldsButtons returns the rows of buttons editing the optional profile fields except the links,
+// the callback data is the prefix followed by the field
+func ProfileFieldsButtons(callbackPrefix string) [][]gotgbot.InlineKeyboardButton {
+	return [][]gotgbot.InlineKeyboardButton{
+		{
+			{
+				Text:         "💼 Работа",
+				CallbackData: callbackPrefix + string(constants.ProfileFieldJob),
+			},
+			{
+				Text:         "📍 Город",
+				CallbackData: callbackPrefix + string(constants.ProfileFieldLocation),
+			},
+			{
+				Text:         "🗣 Языки",
+				CallbackData: callbackPrefix + string(constants.ProfileFieldLanguages),
+			},
+		},
+		{
+			{
+				Text:         "🛠 Навыки",
+				CallbackData: callbackPrefix + string(constants.ProfileFieldSkills),
+			},
+			{
+				Text:         "🤝 Могу помочь",
+				CallbackData: callbackPrefix + string(constants.ProfileFieldCanHelpWith),
+			},
+			{
+				Text:         "🔍 Ищу",
+				CallbackData: callbackPrefix + string(constants.ProfileFieldLookingFor),
+			},
+		},
+	}
+}
+
@@ -76,54 +76,63 @@ func ProfilesCoffeeBanButtons(backCallbackData string, hasCoffeeBan bool) gotgbo
 }
 
 func ProfilesEditMenuButtons(backCallbackData string) gotgbot.InlineKeyboardMarkup {
-	return gotgbot.InlineKeyboardMarkup{
-		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+	buttons := [][]gotgbot.InlineKeyboardButton{
+		{
 			{
-				{
-					Text:         "👤 Имя",
-					CallbackData: constants.AdminProfilesEditFirstnameCallback,
-				},
-				{
-					Text:         "👤 Фамилия",
-					CallbackData: constants.AdminProfilesEditLastnameCallback,
-				},
-				{
-					Text:         "👤 Username",
-					CallbackData: constants.AdminProfilesEditUsernameCallback,
-				},
+				Text:         "👤 Имя",
+				CallbackData: constants.AdminProfilesEditFirstnameCallback,
 			},
 			{
-				{
-					Text:         "📝 О себе",
-					CallbackData: constants.AdminProfilesEditBioCallback,
-				},
-				{
-					Text:         "☕️ Кофе?",
-					CallbackData: constants.AdminProfilesEditCoffeeBanCallback,
-				},
+				Text:         "👤 Фамилия",
+				CallbackData: constants.AdminProfilesEditLastnameCallback,
 			},
 			{
-				{
-					Text:         "📢 Го! (+ превью)",
-					CallbackData: constants.AdminProfilesPublishCallback,
-				},
-				{
-					Text:         "📢 Го! (- превью)",
-					CallbackData: constants.AdminProfilesPublishNoPreviewCallback,
-				},
+				Text:         "👤 Username",
+				CallbackData: constants.AdminProfilesEditUsernameCallback,
 			},
+		},
+		{
 			{
-				{
-					Text:         "◀️ Назад",
-					CallbackData: backCallbackData,
-				},
-				{
-					Text:         "❌ Отмена",
-					CallbackData: constants.AdminProfilesCancelCallback,
-				},
+				Text:         "📝 О себе",
+				CallbackData: constants.AdminProfilesEditBioCallback,
+			},
+			{
+				Text:         "🔗 Ссылки",
+				CallbackData: constants.AdminProfilesEditFieldPrefix + string(constants.ProfileFieldLinks),
+			},
+			{
+				Text:         "☕️ Кофе?",
+				CallbackData: constants.AdminProfilesEditCoffeeBanCallback,
 			},
 		},
 	}
+	buttons = append(buttons, ProfileFieldsButtons(constants.AdminProfilesEditFieldPrefix)...)
+	buttons = append(buttons,
+		[]gotgbot.InlineKeyboardButton{
+			{
+				Text:         "📢 Го! (+ превью)",
+				CallbackData: constants.AdminProfilesPublishCallback,
+			},
+			{
+				Text:         "📢 Го! (- превью)",
+				CallbackData: constants.AdminProfilesPublishNoPreviewCallback,
+			},
+		},
+		[]gotgbot.InlineKeyboardButton{
+			{
+				Text:         "◀️ Назад",
+				CallbackData: backCallbackData,
+			},
+			{
+				Text:         "❌ Отмена",
+				CallbackData: constants.AdminProfilesCancelCallback,
+			},
+		},
+	)
+
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: buttons,
+	}
 }
 
 func ProfilesMainMenuButtons() gotgbot.InlineKeyboardMarkup {
@@ -0,0 +1,49 @@
+package buttons
+
+import (
+	"evo-bot-go/internal/constants"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func UserClientLoginMethodButtons() gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{
+					Text:         "📷 QR-код",
+					CallbackData: constants.UserClientLoginQRCallback,
+				},
+				{
+					Text:         "🔢 Код из Telegram",
+					CallbackData: constants.UserClientLoginCodeCallback,
+				},
+			},
+			{
+				{
+					Text:         "❌ Отмена",
+					CallbackData: constants.UserClientCancelCallback,
+				},
+			},
+		},
+	}
+}
+
+func UserClientResendCodeButtons() gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{
+					Text:         "🔁 Отправить код повторно",
+					CallbackData: constants.UserClientResendCodeCallback,
+				},
+			},
+			{
+				{
+					Text:         "❌ Отмена",
+					CallbackData: constants.UserClientCancelCallback,
+				},
+			},
+		},
+	}
+}
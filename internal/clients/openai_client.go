@@ -3,17 +3,32 @@ package clients
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/observability"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 )
 
 type OpenAiClient struct {
-	client *openai.Client
+	client   *openai.Client
+	recorder LLMCallRecorder
 }
 
+// LLMCall describes a finished call to OpenAI
+type LLMCall struct {
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	Latency          time.Duration
+	Err              error
+}
+
+// LLMCallRecorder is called after every call to OpenAI with the context of the call
+type LLMCallRecorder func(ctx context.Context, call LLMCall)
+
 func NewOpenAiClient() (*OpenAiClient, error) {
 	// Load configuration
 	appConfig, err := config.LoadConfig()
@@ -32,8 +47,14 @@ func NewOpenAiClient() (*OpenAiClient, error) {
 	}, nil
 }
 
+// SetCallRecorder sets the function that records every call, e.g. for usage accounting
+func (c *OpenAiClient) SetCallRecorder(recorder LLMCallRecorder) {
+	c.recorder = recorder
+}
+
 // GetCompletion sends a message to OpenAI and returns the response
 func (c *OpenAiClient) GetCompletion(ctx context.Context, message string) (string, error) {
+	start := time.Now()
 	completion, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(message),
@@ -43,14 +64,17 @@ func (c *OpenAiClient) GetCompletion(ctx context.Context, message string) (strin
 		//Model: "gpt-4.1-mini",
 	})
 	if err != nil {
+		c.observe(ctx, "completion", openai.ChatModelO3Mini, start, err, 0, 0)
 		return "", fmt.Errorf("failed to get completion: %w", err)
 	}
+	c.observe(ctx, "completion", completion.Model, start, nil, completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
 
 	return completion.Choices[0].Message.Content, nil
 }
 
 // GetEmbedding generates an embedding vector for the given text using the text-embedding-ada-002 model
 func (c *OpenAiClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	start := time.Now()
 	embedding, err := c.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
 		Input: openai.EmbeddingNewParamsInputUnion{
 			OfArrayOfStrings: []string{text},
@@ -58,8 +82,10 @@ func (c *OpenAiClient) GetEmbedding(ctx context.Context, text string) ([]float64
 		Model: openai.EmbeddingModelTextEmbeddingAda002,
 	})
 	if err != nil {
+		c.observe(ctx, "embedding", openai.EmbeddingModelTextEmbeddingAda002, start, err, 0, 0)
 		return nil, fmt.Errorf("failed to get embedding: %w", err)
 	}
+	c.observe(ctx, "embedding", embedding.Model, start, nil, embedding.Usage.PromptTokens, 0)
 
 	if len(embedding.Data) == 0 {
 		return nil, fmt.Errorf("no embedding data returned")
@@ -74,6 +100,7 @@ func (c *OpenAiClient) GetBatchEmbeddings(ctx context.Context, texts []string) (
 		return [][]float64{}, nil
 	}
 
+	start := time.Now()
 	embedding, err := c.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
 		Input: openai.EmbeddingNewParamsInputUnion{
 			OfArrayOfStrings: texts,
@@ -81,8 +108,10 @@ func (c *OpenAiClient) GetBatchEmbeddings(ctx context.Context, texts []string) (
 		Model: openai.EmbeddingModelTextEmbeddingAda002,
 	})
 	if err != nil {
+		c.observe(ctx, "batch_embedding", openai.EmbeddingModelTextEmbeddingAda002, start, err, 0, 0)
 		return nil, fmt.Errorf("failed to get batch embeddings: %w", err)
 	}
+	c.observe(ctx, "batch_embedding", embedding.Model, start, nil, embedding.Usage.PromptTokens, 0)
 
 	if len(embedding.Data) == 0 {
 		return nil, fmt.Errorf("no embedding data returned")
@@ -95,3 +124,29 @@ func (c *OpenAiClient) GetBatchEmbeddings(ctx context.Context, texts []string) (
 
 	return result, nil
 }
+
+// observe records the metrics of the call and passes it to the call recorder
+func (c *OpenAiClient) observe(
+	ctx context.Context,
+	operation string,
+	model string,
+	start time.Time,
+	err error,
+	promptTokens int64,
+	completionTokens int64,
+) {
+	observability.ObserveDuration(observability.LLMRequestDuration.WithLabelValues(operation), start)
+	observability.LLMRequestsTotal.WithLabelValues(operation, observability.StatusLabel(err)).Inc()
+	observability.LLMTokensTotal.WithLabelValues(operation, "prompt").Add(float64(promptTokens))
+	observability.LLMTokensTotal.WithLabelValues(operation, "completion").Add(float64(completionTokens))
+
+	if c.recorder != nil {
+		c.recorder(ctx, LLMCall{
+			Model:            model,
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			Latency:          time.Since(start),
+			Err:              err,
+		})
+	}
+}
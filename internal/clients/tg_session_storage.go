@@ -0,0 +1,98 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/gotd/td/session"
+)
+
+// NewTgSessionStorage creates the session storage of the type set in the configuration.
+// File and database sessions are encrypted with the session key.
+func NewTgSessionStorage(appConfig *config.Config, db *database.DB) (session.Storage, error) {
+	var storage session.Storage
+	switch appConfig.TGUserClientSessionType {
+	case constants.TGUserClientSessionTypeFile:
+		log.Printf("TG User Client: Using encrypted file session storage (%s)", constants.TGUserClientDefaultSessionFile)
+		storage = &session.FileStorage{Path: constants.TGUserClientDefaultSessionFile}
+	case constants.TGUserClientSessionTypeDatabase:
+		log.Print("TG User Client: Using encrypted database session storage")
+		storage = repositories.NewTgSessionRepository(db)
+	case constants.TGUserClientSessionTypeMemory:
+		log.Print("TG User Client: Using in-memory session storage")
+		return new(session.StorageMemory), nil
+	default:
+		return nil, fmt.Errorf("TG User Client: unknown session type %q", appConfig.TGUserClientSessionType)
+	}
+
+	if appConfig.TGUserClientSessionKey == "" {
+		return nil, fmt.Errorf("TG User Client: session key is required for %s session storage", appConfig.TGUserClientSessionType)
+	}
+	cipher, err := utils.NewSessionCipher(appConfig.TGUserClientSessionKey, appConfig.TGUserClientSessionOldKeys)
+	if err != nil {
+		return nil, fmt.Errorf("TG User Client: %w", err)
+	}
+
+	return &encryptedSessionStorage{storage: storage, cipher: cipher}, nil
+}
+
+// encryptedSessionStorage encrypts the session before passing it to the underlying storage
+type encryptedSessionStorage struct {
+	storage session.Storage
+	cipher  *utils.SessionCipher
+}
+
+// Ensure encryptedSessionStorage implements session.Storage interface
+var _ session.Storage = (*encryptedSessionStorage)(nil)
+
+// LoadSession decrypts the stored session. A plaintext session or a session encrypted
+// with an old key is stored again with the current key.
+func (s *encryptedSessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	data, err := s.storage.LoadSession(ctx)
+	if err != nil || len(data) == 0 {
+		return data, err
+	}
+
+	if !utils.IsEncryptedSession(data) {
+		log.Print("TG User Client: Found a plaintext session, encrypting it")
+		if err := s.StoreSession(ctx, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	plaintext, err := s.cipher.Decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("TG User Client: failed to decrypt session: %w", err)
+	}
+
+	if !s.cipher.IsCurrentKey(data) {
+		log.Print("TG User Client: Session is encrypted with an old key, re-encrypting it with the current key")
+		if err := s.StoreSession(ctx, plaintext); err != nil {
+			return nil, err
+		}
+	}
+
+	return plaintext, nil
+}
+
+// StoreSession encrypts the session and saves it, an empty session (after logout) is saved as is
+func (s *encryptedSessionStorage) StoreSession(ctx context.Context, data []byte) error {
+	if len(data) == 0 {
+		return s.storage.StoreSession(ctx, data)
+	}
+
+	encrypted, err := s.cipher.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("TG User Client: failed to encrypt session: %w", err)
+	}
+
+	return s.storage.StoreSession(ctx, encrypted)
+}
@@ -2,102 +2,54 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 
 	"evo-bot-go/internal/config"
 	"evo-bot-go/internal/constants"
-	"evo-bot-go/internal/database"
-	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/observability"
 
+	"github.com/gotd/contrib/middleware/floodwait"
+	"github.com/gotd/contrib/middleware/ratelimit"
+	"github.com/gotd/td/bin"
 	"github.com/gotd/td/session"
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/telegram/auth/qrlogin"
 	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"golang.org/x/time/rate"
 )
 
-var (
-	verificationCode string
-	codeMutex        sync.RWMutex
-	sessionStorage   session.Storage
-)
-
-func init() {
-	// Initialize session storage based on configuration
-	// Load configuration
-	appConfig, err := config.LoadConfig()
-	if err != nil {
-		log.Printf("TG User Client: Failed to load configuration, using in-memory session storage: %v", err)
-		sessionStorage = new(session.StorageMemory)
-		return
-	}
-
-	sessionType := strings.ToLower(appConfig.TGUserClientSessionType)
-
-	switch sessionType {
-	case constants.TGUserClientSessionTypeFile:
-		sessionStorage = &session.FileStorage{Path: constants.TGUserClientDefaultSessionFile}
-		log.Printf("TG User Client: Using file session storage (%s)", constants.TGUserClientDefaultSessionFile)
-	case constants.TGUserClientSessionTypeDatabase:
-		// Initialize database storage
-		dbSessionStorage, err := initDatabaseStorage(appConfig.DBConnection)
-		if err != nil {
-			log.Printf("TG User Client: Failed to initialize database session storage: %v, falling back to in-memory storage", err)
-			sessionStorage = new(session.StorageMemory)
-		} else {
-			sessionStorage = dbSessionStorage
-			log.Print("TG User Client: Using database session storage")
-		}
-	default:
-		sessionStorage = new(session.StorageMemory)
-		log.Print("TG User Client: Using in-memory session storage")
-	}
+// TgUserClient reads chat history and forum topics through the Telegram user (MTProto) API
+type TgUserClient interface {
+	GetChatMessages(ctx context.Context, chatID int64, topicID int) ([]tg.Message, error)
+	GetLastTopicMessagesByTime(ctx context.Context, chatID int64, topicID int, hours int) ([]tg.Message, error)
+	GetForumTopics(ctx context.Context, chatID int64) ([]tg.ForumTopic, error)
+	KeepSessionAlive(ctx context.Context) error
+	CheckSession(ctx context.Context) error
 }
 
-// initDatabaseStorage initializes a database-backed session storage
-func initDatabaseStorage(connectionString string) (session.Storage, error) {
-	if connectionString == "" {
-		return nil, fmt.Errorf("TG User Client: database connection string is empty")
-	}
-
-	// Create database connection
-	db, err := database.NewDB(connectionString)
-	if err != nil {
-		return nil, fmt.Errorf("TG User Client: failed to connect to database: %w", err)
-	}
-
-	// Create session repository
-	sessionRepository := repositories.NewTgSessionRepository(db)
-	// No error handling needed as the constructor doesn't return an error anymore
-
-	return sessionRepository, nil
-}
+// Ensure TelegramClient implements TgUserClient interface
+var _ TgUserClient = (*TelegramClient)(nil)
 
 // TelegramConfig holds the configuration for Telegram client
 type TelegramConfig struct {
 	AppID       int
 	AppHash     string
-	PhoneNumber string
-	Password    string
+	PhoneNumber string // only needed to log in with a code
 }
 
 // NewTelegramConfig creates a new TelegramConfig from config values
-func NewTelegramConfig() (*TelegramConfig, error) {
-	// Load configuration
-	appConfig, err := config.LoadConfig()
-	if err != nil {
-		return nil, fmt.Errorf("TG User Client: failed to load configuration: %w", err)
-	}
-
+func NewTelegramConfig(appConfig *config.Config) (*TelegramConfig, error) {
 	appID := appConfig.TGUserClientAppID
 	appHash := appConfig.TGUserClientAppHash
 	phoneNumber := appConfig.TGUserClientPhoneNumber
-	password := appConfig.TGUserClient2FAPass
 
-	if appID == 0 || appHash == "" || phoneNumber == "" || password == "" {
+	if appID == 0 || appHash == "" {
 		return nil, fmt.Errorf("TG User Client: missing required telegram client configuration")
 	}
 
@@ -105,153 +57,272 @@ func NewTelegramConfig() (*TelegramConfig, error) {
 		AppID:       appID,
 		AppHash:     appHash,
 		PhoneNumber: phoneNumber,
-		Password:    password,
 	}, nil
 }
 
-// TelegramClient wraps the Telegram client functionality
+// TelegramClient is a long-running Telegram user client shared by all consumers.
+// It keeps one connection open, reconnects when it is lost, waits out FLOOD_WAITs and rate limits its calls.
 type TelegramClient struct {
-	client *telegram.Client
-	config *TelegramConfig
+	config         *TelegramConfig
+	configErr      error
+	sessionStorage session.Storage
+
+	mu         sync.RWMutex
+	client     *telegram.Client   // nil while disconnected
+	connected  chan struct{}      // closed once the client is connected
+	disconnect context.CancelFunc // drops the current connection, the client reconnects with the stored session
+	authorized bool
+	peers      map[int64]tg.InputPeerClass
+	lastCallAt time.Time
+	lastErr    error
+	lastErrAt  time.Time
+
+	loginMu    sync.Mutex
+	login      loginState
+	updates    tg.UpdateDispatcher
+	qrLoggedIn qrlogin.LoggedIn
+
+	// The middlewares are shared by the clients of all connections, so a reconnect does not reset the rate limit
+	floodWaiter *floodwait.SimpleWaiter
+	rateLimiter *ratelimit.RateLimiter
+
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-// NewTelegramClient creates a new TelegramClient
-func NewTelegramClient() (*TelegramClient, error) {
-	config, err := NewTelegramConfig()
-	if err != nil {
-		return nil, fmt.Errorf("TG User Client: failed to get telegram config: %w", err)
+// NewTelegramClient creates a new TelegramClient. If the configuration is incomplete,
+// the client does not connect and every call returns the configuration error.
+func NewTelegramClient(appConfig *config.Config, sessionStorage session.Storage) *TelegramClient {
+	telegramConfig, err := NewTelegramConfig(appConfig)
+
+	t := &TelegramClient{
+		config:         telegramConfig,
+		configErr:      err,
+		sessionStorage: sessionStorage,
+		connected:      make(chan struct{}),
+		peers:          make(map[int64]tg.InputPeerClass),
+		updates:        tg.NewUpdateDispatcher(),
+		floodWaiter: floodwait.NewSimpleWaiter().
+			WithMaxRetries(constants.TGUserClientFloodWaitMaxRetries).
+			WithMaxWait(constants.TGUserClientFloodWaitMaxDelay),
+		rateLimiter: ratelimit.New(rate.Every(constants.TGUserClientRateLimitInterval), constants.TGUserClientRateLimitBurst),
 	}
+	// Telegram sends updateLoginToken once a QR code is scanned
+	t.qrLoggedIn = qrlogin.OnLoginToken(t.updates)
 
-	client := telegram.NewClient(config.AppID, config.AppHash, telegram.Options{
-		SessionStorage: sessionStorage,
-	})
-
-	return &TelegramClient{
-		client: client,
-		config: config,
-	}, nil
+	return t
 }
 
-// TgSetVerificationCode sets the verification code for authentication
-func TgSetVerificationCode(code string) {
-	codeMutex.Lock()
-	defer codeMutex.Unlock()
-	verificationCode = code
+// Start connects the client in the background
+func (t *TelegramClient) Start() {
+	if t.configErr != nil {
+		log.Printf("TG User Client: Not started: %v", t.configErr)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	go t.run(ctx)
 }
 
-// TgGetChatMessageById retrieves a specific message by its ID from a chat
-// For forum topics, it returns a synthetic message with the topic name
-func TgGetChatMessageById(chatID int64, messageID int) (*tg.Message, error) {
-	tgClient, err := NewTelegramClient()
-	if err != nil {
-		return nil, err
+// Close disconnects the client and waits until it is stopped
+func (t *TelegramClient) Close() error {
+	if t.cancel == nil {
+		return nil
 	}
 
-	var message *tg.Message
-	err = tgClient.client.Run(context.Background(), func(ctx context.Context) error {
-		if err := tgClient.ensureAuthorized(ctx); err != nil {
-			return err
-		}
+	t.cancel()
+	<-t.done
+	log.Print("TG User Client: Stopped")
+	return nil
+}
 
-		api := tgClient.client.API()
-		inputPeer, err := tgClient.getPeerInfoByChatID(ctx, chatID)
-		if err != nil {
-			return fmt.Errorf("TG User Client: failed to get peer info: %w", err)
+// run keeps the client connected until it is closed. gotd reconnects dropped connections itself,
+// run starts a new client with a growing delay when the current one fails for good.
+func (t *TelegramClient) run(ctx context.Context) {
+	defer close(t.done)
+
+	reconnectLoop(ctx, t.connect, constants.TGUserClientReconnectMinDelay, constants.TGUserClientReconnectMaxDelay)
+}
+
+// reconnectLoop connects until the context is cancelled, doubling the delay after every failed connection
+// up to maxDelay. A connection that lived longer than maxDelay resets the delay.
+func reconnectLoop(ctx context.Context, connect func(ctx context.Context) error, minDelay, maxDelay time.Duration) {
+	delay := minDelay
+	for {
+		startedAt := time.Now()
+		err := connect(ctx)
+		if ctx.Err() != nil {
+			return
 		}
 
-		// For channels, try to get forum topics directly
-		if channel, ok := inputPeer.(*tg.InputPeerChannel); ok {
-			inputChannel := &tg.InputChannel{
-				ChannelID:  channel.ChannelID,
-				AccessHash: channel.AccessHash,
-			}
+		if time.Since(startedAt) > maxDelay {
+			delay = minDelay
+		}
+		log.Printf("TG User Client: Connection failed: %v, reconnecting in %v", err, delay)
 
-			// Try to get forum topics
-			forumTopics, err := api.ChannelsGetForumTopics(ctx, &tg.ChannelsGetForumTopicsRequest{
-				Channel: inputChannel,
-				Limit:   constants.TGUserClientDefaultLimit,
-			})
-			if err == nil {
-				for _, topic := range forumTopics.Topics {
-					if forumTopic, ok := topic.(*tg.ForumTopic); ok {
-						if forumTopic.ID == messageID {
-							// Create a synthetic message with the topic name
-							message = &tg.Message{
-								ID:      messageID,
-								Message: forumTopic.Title,
-							}
-							return nil
-						}
-					}
-				}
-			}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
 		}
 
-		return fmt.Errorf("TG User Client: message not found or unexpected response type")
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// connect runs a client until the context is cancelled or the client fails
+func (t *TelegramClient) connect(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := telegram.NewClient(t.config.AppID, t.config.AppHash, telegram.Options{
+		SessionStorage: t.sessionStorage,
+		UpdateHandler:  t.updates,
+		// The first middleware is the outermost one: a FLOOD_WAIT retry goes through the rate limiter again
+		Middlewares: []telegram.Middleware{
+			t.floodWaiter,
+			t.rateLimiter,
+			telegram.MiddlewareFunc(mtprotoMetricsMiddleware),
+		},
 	})
 
-	if err != nil {
-		return nil, fmt.Errorf("TG User Client: failed to get message: %w", err)
+	return client.Run(ctx, func(ctx context.Context) error {
+		t.setClient(client, cancel)
+		defer t.setClient(nil, nil)
+
+		log.Print("TG User Client: Connected")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+}
+
+// setClient publishes the connected client, or marks the client as disconnected if it is nil
+func (t *TelegramClient) setClient(client *telegram.Client, disconnect context.CancelFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.client = client
+	t.disconnect = disconnect
+	if client != nil {
+		close(t.connected)
+	} else {
+		t.connected = make(chan struct{})
+		t.authorized = false
 	}
+}
 
-	if message == nil {
-		return nil, fmt.Errorf("TG User Client: message not found")
+// waitClient returns the connected client, waiting for the connection if needed
+func (t *TelegramClient) waitClient(ctx context.Context) (*telegram.Client, error) {
+	if t.configErr != nil {
+		return nil, t.configErr
 	}
 
-	return message, nil
-}
+	ctx, cancel := context.WithTimeout(ctx, constants.TGUserClientConnectTimeout)
+	defer cancel()
 
-// GetTopicName retrieves the topic name from the topic ID using the Telegram API
-func TgGetTopicName(topicId int) (string, error) {
-	// hack for 0 topic (1 in links)
-	if topicId == 0 {
-		return "Оффтопчик", nil
+	for {
+		t.mu.RLock()
+		client, connected := t.client, t.connected
+		t.mu.RUnlock()
+
+		if client != nil {
+			return client, nil
+		}
+
+		select {
+		case <-connected:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("TG User Client: not connected: %w", ctx.Err())
+		}
 	}
-	// Convert topicId to int since GetChatMessageById expects an int
-	topicIdInt := int(topicId)
+}
 
-	// Load configuration
-	appConfig, err := config.LoadConfig()
+// call runs the function with the API of the connected and authorized client
+func (t *TelegramClient) call(ctx context.Context, f func(ctx context.Context, api *tg.Client) error) error {
+	client, err := t.waitClient(ctx)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return err
 	}
-	chatId := appConfig.SuperGroupChatID
 
-	// Get the topic message by ID
-	message, err := TgGetChatMessageById(chatId, topicIdInt)
-	if err != nil {
-		return "Topic", fmt.Errorf("failed to get thread message: %w", err)
+	if err := t.ensureAuthorized(ctx, client); err != nil {
+		return err
 	}
 
-	// Extract and truncate the topic name if needed
-	topicName := message.Message
-	if topicName == "" {
-		topicName = "Topic"
+	err = f(ctx, client.API())
+
+	t.mu.Lock()
+	if err == nil {
+		t.lastCallAt = time.Now()
+	} else {
+		t.lastErr = err
+		t.lastErrAt = time.Now()
+		if auth.IsUnauthorized(err) || auth.IsKeyUnregistered(err) {
+			t.authorized = false
+		}
 	}
+	t.mu.Unlock()
 
-	return topicName, nil
+	return err
 }
 
-// GetChatMessages retrieves messages from a chat topic
-func GetChatMessages(chatID int64, topicID int) ([]tg.Message, error) {
-	tgClient, err := NewTelegramClient()
-	if err != nil {
-		return nil, err
+// mtprotoMetricsMiddleware records the duration and outcome of every MTProto call, including FLOOD_WAITs
+func mtprotoMetricsMiddleware(next tg.Invoker) telegram.InvokeFunc {
+	return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+		method := "unknown"
+		if typed, ok := input.(interface{ TypeName() string }); ok {
+			method = typed.TypeName()
+		}
+
+		start := time.Now()
+		err := next.Invoke(ctx, input, output)
+		observability.ObserveDuration(observability.MTProtoCallDuration.WithLabelValues(method), start)
+		observability.MTProtoCallsTotal.WithLabelValues(method, observability.StatusLabel(err)).Inc()
+		if _, ok := tgerr.AsFloodWait(err); ok {
+			observability.MTProtoFloodWaitsTotal.WithLabelValues(method).Inc()
+		}
+		return err
 	}
+}
 
-	var allMessages []tg.Message
-	err = tgClient.client.Run(context.Background(), func(ctx context.Context) error {
-		if err := tgClient.ensureAuthorized(ctx); err != nil {
-			return err
+// GetForumTopics retrieves all topics of a forum chat with pagination, deleted topics are skipped
+func (t *TelegramClient) GetForumTopics(ctx context.Context, chatID int64) ([]tg.ForumTopic, error) {
+	var allTopics []tg.ForumTopic
+	err := t.call(ctx, func(ctx context.Context, api *tg.Client) error {
+		inputPeer, err := t.getPeerInfoByChatID(ctx, api, chatID)
+		if err != nil {
+			return fmt.Errorf("TG User Client: failed to get peer info: %w", err)
+		}
+
+		channel, ok := inputPeer.(*tg.InputPeerChannel)
+		if !ok {
+			return fmt.Errorf("TG User Client: chat %d is not a forum", chatID)
 		}
 
-		api := tgClient.client.API()
-		inputPeer, err := tgClient.getPeerInfoByChatID(ctx, chatID)
+		return fetchForumTopics(ctx, api, &tg.InputChannel{
+			ChannelID:  channel.ChannelID,
+			AccessHash: channel.AccessHash,
+		}, &allTopics)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("TG User Client: failed to get forum topics: %w", err)
+	}
+
+	return allTopics, nil
+}
+
+// GetChatMessages retrieves messages from a chat topic
+func (t *TelegramClient) GetChatMessages(ctx context.Context, chatID int64, topicID int) ([]tg.Message, error) {
+	var allMessages []tg.Message
+	err := t.call(ctx, func(ctx context.Context, api *tg.Client) error {
+		inputPeer, err := t.getPeerInfoByChatID(ctx, api, chatID)
 		if err != nil {
 			return fmt.Errorf("TG User Client: failed to get peer info: %w", err)
 		}
 
-		return tgClient.fetchMessages(ctx, api, inputPeer, topicID, &allMessages)
+		return fetchMessages(ctx, api, inputPeer, topicID, &allMessages)
 	})
 
 	if err != nil {
@@ -263,12 +334,7 @@ func GetChatMessages(chatID int64, topicID int) ([]tg.Message, error) {
 
 // GetLastTopicMessagesByTime retrieves messages from a chat topic within the last specified hours
 // Filtering is applied directly when fetching messages
-func GetLastTopicMessagesByTime(chatID int64, topicID int, hours int) ([]tg.Message, error) {
-	tgClient, err := NewTelegramClient()
-	if err != nil {
-		return nil, err
-	}
-
+func (t *TelegramClient) GetLastTopicMessagesByTime(ctx context.Context, chatID int64, topicID int, hours int) ([]tg.Message, error) {
 	if topicID == 0 {
 		topicID = 1 // Root topic fix
 	}
@@ -279,13 +345,8 @@ func GetLastTopicMessagesByTime(chatID int64, topicID int, hours int) ([]tg.Mess
 	cutoffDate := int(cutoffTime.Unix())
 
 	var allMessages []tg.Message
-	err = tgClient.client.Run(context.Background(), func(ctx context.Context) error {
-		if err := tgClient.ensureAuthorized(ctx); err != nil {
-			return err
-		}
-
-		api := tgClient.client.API()
-		inputPeer, err := tgClient.getPeerInfoByChatID(ctx, chatID)
+	err := t.call(ctx, func(ctx context.Context, api *tg.Client) error {
+		inputPeer, err := t.getPeerInfoByChatID(ctx, api, chatID)
 		if err != nil {
 			return fmt.Errorf("TG User Client: failed to get peer info: %w", err)
 		}
@@ -302,7 +363,7 @@ func GetLastTopicMessagesByTime(chatID int64, topicID int, hours int) ([]tg.Mess
 }
 
 // fetchMessages retrieves messages with pagination
-func (t *TelegramClient) fetchMessages(ctx context.Context, api *tg.Client, inputPeer tg.InputPeerClass, topicID int, allMessages *[]tg.Message) error {
+func fetchMessages(ctx context.Context, api *tg.Client, inputPeer tg.InputPeerClass, topicID int, allMessages *[]tg.Message) error {
 	offset := 0
 	limit := constants.TGUserClientDefaultLimit
 
@@ -391,6 +452,52 @@ func fetchMessagesWithDateFilter(ctx context.Context, api *tg.Client, inputPeer
 	return nil
 }
 
+// fetchForumTopics retrieves forum topics with pagination. Topics are ordered by the date of
+// their last message, so the next page starts after the last topic and its top message.
+func fetchForumTopics(ctx context.Context, api *tg.Client, channel tg.InputChannelClass, allTopics *[]tg.ForumTopic) error {
+	request := &tg.ChannelsGetForumTopicsRequest{
+		Channel: channel,
+		Limit:   constants.TGUserClientDefaultLimit,
+	}
+
+	for {
+		resp, err := api.ChannelsGetForumTopics(ctx, request)
+		if err != nil {
+			return fmt.Errorf("TG User Client: failed to get forum topics: %w", err)
+		}
+
+		var last *tg.ForumTopic
+		for _, topicClass := range resp.Topics {
+			if topic, ok := topicClass.(*tg.ForumTopic); ok {
+				*allTopics = append(*allTopics, *topic)
+				last = topic
+			}
+		}
+
+		if last == nil || len(resp.Topics) < request.Limit || len(*allTopics) >= resp.Count {
+			return nil
+		}
+
+		offsetDate := 0
+		for _, msgClass := range resp.Messages {
+			switch msg := msgClass.(type) {
+			case *tg.Message:
+				if msg.ID == last.TopMessage {
+					offsetDate = msg.Date
+				}
+			case *tg.MessageService:
+				if msg.ID == last.TopMessage {
+					offsetDate = msg.Date
+				}
+			}
+		}
+
+		request.OffsetDate = offsetDate
+		request.OffsetID = last.TopMessage
+		request.OffsetTopic = last.ID
+	}
+}
+
 // extractMessages extracts messages from the API response
 func extractMessages(resp tg.MessagesMessagesClass) ([]tg.Message, error) {
 	var messages []tg.Message
@@ -409,24 +516,12 @@ func extractMessages(resp tg.MessagesMessagesClass) ([]tg.Message, error) {
 	return messages, nil
 }
 
-// TgKeepSessionAlive keeps the Telegram session alive
-func TgKeepSessionAlive() error {
-	tgClient, err := NewTelegramClient()
-	if err != nil {
-		return err
-	}
-
-	err = tgClient.client.Run(context.Background(), func(ctx context.Context) error {
-		if err := tgClient.ensureAuthorized(ctx); err != nil {
-			return err
-		}
-
-		// Make a simple request to keep session alive
-		_, err := tgClient.client.API().HelpGetConfig(ctx)
-		if err != nil {
+// KeepSessionAlive makes a simple request to keep the Telegram session alive
+func (t *TelegramClient) KeepSessionAlive(ctx context.Context) error {
+	err := t.call(ctx, func(ctx context.Context, api *tg.Client) error {
+		if _, err := api.HelpGetConfig(ctx); err != nil {
 			return fmt.Errorf("TG User Client: failed to get config: %w", err)
 		}
-
 		return nil
 	})
 
@@ -437,9 +532,26 @@ func TgKeepSessionAlive() error {
 	return nil
 }
 
-// getPeerInfoByChatID retrieves peer information by chat ID
-func (t *TelegramClient) getPeerInfoByChatID(ctx context.Context, chatID int64) (tg.InputPeerClass, error) {
-	api := t.client.API()
+// CheckSession checks that a user client session has been stored
+func (t *TelegramClient) CheckSession(ctx context.Context) error {
+	data, err := t.sessionStorage.LoadSession(ctx)
+	if errors.Is(err, session.ErrNotFound) || (err == nil && len(data) == 0) {
+		return fmt.Errorf("TG User Client: no session stored")
+	}
+	if err != nil {
+		return fmt.Errorf("TG User Client: failed to load session: %w", err)
+	}
+	return nil
+}
+
+// getPeerInfoByChatID retrieves peer information by chat ID, peers found once are cached
+func (t *TelegramClient) getPeerInfoByChatID(ctx context.Context, api *tg.Client, chatID int64) (tg.InputPeerClass, error) {
+	t.mu.RLock()
+	peer, ok := t.peers[chatID]
+	t.mu.RUnlock()
+	if ok {
+		return peer, nil
+	}
 
 	// Fetch dialogs to find the chat and get its AccessHash if needed
 	dialogs, err := api.MessagesGetDialogs(ctx, &tg.MessagesGetDialogsRequest{
@@ -456,14 +568,14 @@ func (t *TelegramClient) getPeerInfoByChatID(ctx context.Context, chatID int64)
 			switch c := chat.(type) {
 			case *tg.Chat:
 				if c.ID == chatID {
-					return &tg.InputPeerChat{ChatID: c.ID}, nil
+					peer = &tg.InputPeerChat{ChatID: c.ID}
 				}
 			case *tg.Channel:
 				if c.ID == chatID {
-					return &tg.InputPeerChannel{
+					peer = &tg.InputPeerChannel{
 						ChannelID:  c.ID,
 						AccessHash: c.AccessHash,
-					}, nil
+					}
 				}
 			}
 		}
@@ -471,48 +583,37 @@ func (t *TelegramClient) getPeerInfoByChatID(ctx context.Context, chatID int64)
 		return nil, fmt.Errorf("TG User Client: unexpected response type: %T", dialogs)
 	}
 
-	return nil, fmt.Errorf("TG User Client: chat with ID %d not found", chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("TG User Client: chat with ID %d not found", chatID)
+	}
+
+	t.mu.Lock()
+	t.peers[chatID] = peer
+	t.mu.Unlock()
+
+	return peer, nil
 }
 
-// ensureAuthorized ensures the client is authorized
-func (t *TelegramClient) ensureAuthorized(ctx context.Context) error {
-	authCli := t.client.Auth()
+// ensureAuthorized checks that the session is logged in, the login itself is done through the login methods
+func (t *TelegramClient) ensureAuthorized(ctx context.Context, client *telegram.Client) error {
+	t.mu.RLock()
+	authorized := t.authorized
+	t.mu.RUnlock()
+	if authorized {
+		return nil
+	}
 
-	status, err := authCli.Status(ctx)
+	status, err := client.Auth().Status(ctx)
 	if err != nil {
 		return fmt.Errorf("TG User Client: failed to get auth status: %w", err)
 	}
-
 	if !status.Authorized {
-		code, err := authCli.SendCode(ctx, t.config.PhoneNumber, auth.SendCodeOptions{
-			AllowAppHash: true,
-		})
-		if err != nil {
-			return fmt.Errorf("TG User Client: failed to send code: %w", err)
-		}
-		sentCode := code.(*tg.AuthSentCode)
-
-		codeMutex.RLock()
-		receivedCode := verificationCode
-		codeMutex.RUnlock()
-
-		if receivedCode == "" {
-			return fmt.Errorf("TG User Client: verification code not set - use /code command to set it")
-		}
-		log.Print("TG User Client: Code applied")
-
-		_, err = authCli.SignIn(ctx, t.config.PhoneNumber, receivedCode, sentCode.PhoneCodeHash)
-		if err != nil {
-			if strings.Contains(err.Error(), "2FA required") {
-				_, err = authCli.Password(ctx, t.config.Password)
-				if err != nil {
-					return fmt.Errorf("TG User Client: failed to send 2FA password: %w", err)
-				}
-			} else {
-				return fmt.Errorf("TG User Client: sign in error: %w", err)
-			}
-		}
+		return ErrTgUserClientNotAuthorized
 	}
 
+	t.mu.Lock()
+	t.authorized = true
+	t.mu.Unlock()
+
 	return nil
 }
@@ -0,0 +1,431 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+	"log"
+	"time"
+
+	"evo-bot-go/internal/constants"
+
+	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"rsc.io/qr"
+)
+
+var (
+	ErrTgUserClientNotAuthorized = errors.New("TG User Client: not logged in - use /userclient login")
+	ErrLoginAlreadyAuthorized    = errors.New("TG User Client: already logged in")
+	ErrLoginNotStarted           = errors.New("TG User Client: no login in progress")
+	ErrLoginInProgress           = errors.New("TG User Client: another login is in progress")
+	ErrLoginExpired              = errors.New("TG User Client: login code expired")
+	ErrLoginResendTooEarly       = errors.New("TG User Client: a new code cannot be requested yet")
+	ErrLoginCodeInvalid          = errors.New("TG User Client: invalid login code")
+	ErrLoginPasswordNeeded       = errors.New("TG User Client: 2FA password needed")
+	ErrLoginPasswordInvalid      = errors.New("TG User Client: invalid 2FA password")
+	ErrLoginPhoneNotConfigured   = errors.New("TG User Client: phone number is not configured")
+)
+
+// LoginStep is the step of the user client login in progress
+type LoginStep string
+
+const (
+	LoginStepNone     LoginStep = ""
+	LoginStepCode     LoginStep = "code"     // waiting for the code sent to the account
+	LoginStepQR       LoginStep = "qr"       // waiting for the QR code to be scanned
+	LoginStepPassword LoginStep = "password" // waiting for the 2FA password
+)
+
+// TgUserClientLogin logs the user client in and out and reports the session health
+type TgUserClientLogin interface {
+	GetStatus(ctx context.Context) *TgUserClientStatus
+	GetLoginStep() LoginStep
+	SendLoginCode(ctx context.Context) (*LoginCode, error)
+	ResendLoginCode(ctx context.Context) (*LoginCode, error)
+	SignInWithCode(ctx context.Context, code string) error
+	LoginWithQR(ctx context.Context, show func(ctx context.Context, qrCode *LoginQRCode) error) error
+	SignInWithPassword(ctx context.Context, password string) error
+	CancelLogin()
+	Logout(ctx context.Context) error
+}
+
+// Ensure TelegramClient implements TgUserClientLogin interface
+var _ TgUserClientLogin = (*TelegramClient)(nil)
+
+// TgUserClientStatus describes the health of the user client session
+type TgUserClientStatus struct {
+	ConfigErr     error
+	Connected     bool
+	Authorized    bool
+	SessionStored bool
+	User          *tg.User // the logged in account, nil if it could not be requested
+	LoginStep     LoginStep
+	LastCallAt    time.Time
+	LastErr       error
+	LastErrAt     time.Time
+}
+
+// LoginCode describes the code sent to log in
+type LoginCode struct {
+	Type        string    // where the code was sent: app, sms, call or other
+	ExpiresAt   time.Time // the code is not accepted after this time
+	ResendAfter time.Time // a new code can be requested after this time
+}
+
+// LoginQRCode is a QR code to scan in the Telegram app to log in
+type LoginQRCode struct {
+	URL       string
+	PNG       []byte
+	ExpiresAt time.Time // a new QR code is shown after this time
+}
+
+// loginState holds the login in progress
+type loginState struct {
+	step          LoginStep
+	phoneCodeHash string
+	expiresAt     time.Time
+	resendAfter   time.Time
+	cancelQR      context.CancelFunc
+}
+
+// GetStatus reports whether the client is connected and logged in, and the result of the last calls
+func (t *TelegramClient) GetStatus(ctx context.Context) *TgUserClientStatus {
+	t.mu.RLock()
+	status := &TgUserClientStatus{
+		ConfigErr:  t.configErr,
+		Connected:  t.client != nil,
+		LastCallAt: t.lastCallAt,
+		LastErr:    t.lastErr,
+		LastErrAt:  t.lastErrAt,
+	}
+	client := t.client
+	t.mu.RUnlock()
+
+	status.SessionStored = t.CheckSession(ctx) == nil
+	status.LoginStep = t.GetLoginStep()
+
+	if client != nil {
+		authStatus, err := client.Auth().Status(ctx)
+		if err != nil {
+			status.LastErr = err
+			status.LastErrAt = time.Now()
+		} else {
+			status.Authorized = authStatus.Authorized
+			status.User = authStatus.User
+		}
+	}
+
+	return status
+}
+
+// GetLoginStep returns the step of the login in progress
+func (t *TelegramClient) GetLoginStep() LoginStep {
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+
+	t.expireLogin()
+	return t.login.step
+}
+
+// SendLoginCode starts a login with a code sent to the configured phone number
+func (t *TelegramClient) SendLoginCode(ctx context.Context) (*LoginCode, error) {
+	if t.config != nil && t.config.PhoneNumber == "" {
+		return nil, ErrLoginPhoneNotConfigured
+	}
+
+	authCli, err := t.startLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sentCode, err := authCli.SendCode(ctx, t.config.PhoneNumber, auth.SendCodeOptions{AllowAppHash: true})
+	if err != nil {
+		t.CancelLogin()
+		return nil, fmt.Errorf("TG User Client: failed to send code: %w", err)
+	}
+
+	return t.setLoginCode(sentCode)
+}
+
+// ResendLoginCode requests a new code for the login in progress, Telegram may send it another way
+func (t *TelegramClient) ResendLoginCode(ctx context.Context) (*LoginCode, error) {
+	t.loginMu.Lock()
+	t.expireLogin()
+	login := t.login
+	t.loginMu.Unlock()
+
+	if login.step != LoginStepCode || login.phoneCodeHash == "" {
+		return nil, ErrLoginNotStarted
+	}
+	if time.Now().Before(login.resendAfter) {
+		return nil, ErrLoginResendTooEarly
+	}
+
+	client, err := t.waitClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sentCode, err := client.API().AuthResendCode(ctx, &tg.AuthResendCodeRequest{
+		PhoneNumber:   t.config.PhoneNumber,
+		PhoneCodeHash: login.phoneCodeHash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TG User Client: failed to resend code: %w", err)
+	}
+
+	return t.setLoginCode(sentCode)
+}
+
+// SignInWithCode finishes the code login. It returns ErrLoginPasswordNeeded if the account has 2FA enabled.
+func (t *TelegramClient) SignInWithCode(ctx context.Context, code string) error {
+	t.loginMu.Lock()
+	expired := t.expireLogin()
+	login := t.login
+	t.loginMu.Unlock()
+
+	if expired {
+		return ErrLoginExpired
+	}
+	if login.step != LoginStepCode || login.phoneCodeHash == "" {
+		return ErrLoginNotStarted
+	}
+
+	client, err := t.waitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Auth().SignIn(ctx, t.config.PhoneNumber, code, login.phoneCodeHash)
+	switch {
+	case errors.Is(err, auth.ErrPasswordAuthNeeded):
+		t.setLoginStep(LoginStepPassword)
+		return ErrLoginPasswordNeeded
+	case tgerr.Is(err, "PHONE_CODE_INVALID", "PHONE_CODE_EMPTY"):
+		return ErrLoginCodeInvalid
+	case tgerr.Is(err, "PHONE_CODE_EXPIRED"):
+		t.CancelLogin()
+		return ErrLoginExpired
+	case err != nil:
+		return fmt.Errorf("TG User Client: sign in error: %w", err)
+	}
+
+	t.finishLogin()
+	return nil
+}
+
+// LoginWithQR logs in by a QR code scanned in the Telegram app of the account. It calls show for every new
+// QR code and returns once the code is scanned, or ErrLoginPasswordNeeded if the account has 2FA enabled.
+func (t *TelegramClient) LoginWithQR(ctx context.Context, show func(ctx context.Context, qrCode *LoginQRCode) error) error {
+	if _, err := t.startLogin(ctx); err != nil {
+		return err
+	}
+
+	client, err := t.waitClient(ctx)
+	if err != nil {
+		t.CancelLogin()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, constants.TGUserClientQRLoginTimeout)
+	defer cancel()
+
+	t.loginMu.Lock()
+	t.login.step = LoginStepQR
+	t.login.expiresAt = time.Now().Add(constants.TGUserClientQRLoginTimeout)
+	t.login.cancelQR = cancel
+	t.loginMu.Unlock()
+
+	_, err = client.QR().Auth(ctx, t.qrLoggedIn, func(ctx context.Context, token qrlogin.Token) error {
+		img, err := token.Image(qr.M)
+		if err != nil {
+			return fmt.Errorf("TG User Client: failed to render QR code: %w", err)
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return fmt.Errorf("TG User Client: failed to encode QR code: %w", err)
+		}
+
+		return show(ctx, &LoginQRCode{URL: token.URL(), PNG: buf.Bytes(), ExpiresAt: token.Expires()})
+	})
+	switch {
+	case tgerr.Is(err, "SESSION_PASSWORD_NEEDED"):
+		t.setLoginStep(LoginStepPassword)
+		return ErrLoginPasswordNeeded
+	case err != nil:
+		t.CancelLogin()
+		return fmt.Errorf("TG User Client: QR login error: %w", err)
+	}
+
+	t.finishLogin()
+	return nil
+}
+
+// SignInWithPassword finishes a code or QR login of an account with 2FA enabled
+func (t *TelegramClient) SignInWithPassword(ctx context.Context, password string) error {
+	if step := t.GetLoginStep(); step != LoginStepPassword {
+		return ErrLoginNotStarted
+	}
+
+	client, err := t.waitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Auth().Password(ctx, password)
+	switch {
+	case errors.Is(err, auth.ErrPasswordInvalid):
+		return ErrLoginPasswordInvalid
+	case err != nil:
+		return fmt.Errorf("TG User Client: failed to send 2FA password: %w", err)
+	}
+
+	t.finishLogin()
+	return nil
+}
+
+// CancelLogin drops the login in progress
+func (t *TelegramClient) CancelLogin() {
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+
+	if t.login.cancelQR != nil {
+		t.login.cancelQR()
+	}
+	t.login = loginState{}
+}
+
+// Logout terminates the session on the Telegram side, deletes the stored session and reconnects
+// with a new one, so the client can be logged in again with another method or account
+func (t *TelegramClient) Logout(ctx context.Context) error {
+	client, err := t.waitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.CancelLogin()
+
+	if _, err := client.API().AuthLogOut(ctx); err != nil && !auth.IsUnauthorized(err) {
+		return fmt.Errorf("TG User Client: failed to log out: %w", err)
+	}
+
+	t.mu.Lock()
+	t.authorized = false
+	t.peers = make(map[int64]tg.InputPeerClass)
+	disconnect := t.disconnect
+	t.mu.Unlock()
+
+	if disconnect != nil {
+		disconnect()
+	}
+
+	if err := t.sessionStorage.StoreSession(ctx, nil); err != nil {
+		return fmt.Errorf("TG User Client: failed to delete session: %w", err)
+	}
+
+	log.Print("TG User Client: Logged out")
+	return nil
+}
+
+// startLogin checks that the client is connected and not logged in, and that no other login is in progress
+func (t *TelegramClient) startLogin(ctx context.Context) (*auth.Client, error) {
+	client, err := t.waitClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.Auth().Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TG User Client: failed to get auth status: %w", err)
+	}
+	if status.Authorized {
+		return nil, ErrLoginAlreadyAuthorized
+	}
+
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+
+	t.expireLogin()
+	if t.login.step != LoginStepNone {
+		return nil, ErrLoginInProgress
+	}
+	t.login = loginState{step: LoginStepCode, expiresAt: time.Now().Add(constants.TGUserClientLoginCodeTimeout)}
+
+	return client.Auth(), nil
+}
+
+// setLoginCode stores the hash of the sent code, the code is entered with it
+func (t *TelegramClient) setLoginCode(sentCodeClass tg.AuthSentCodeClass) (*LoginCode, error) {
+	sentCode, ok := sentCodeClass.(*tg.AuthSentCode)
+	if !ok {
+		t.CancelLogin()
+		return nil, fmt.Errorf("TG User Client: unexpected sent code type: %T", sentCodeClass)
+	}
+
+	now := time.Now()
+	code := &LoginCode{
+		Type:        "other",
+		ExpiresAt:   now.Add(constants.TGUserClientLoginCodeTimeout),
+		ResendAfter: now,
+	}
+	if timeout, ok := sentCode.GetTimeout(); ok {
+		code.ResendAfter = now.Add(time.Duration(timeout) * time.Second)
+	}
+	switch sentCode.Type.(type) {
+	case *tg.AuthSentCodeTypeApp:
+		code.Type = "app"
+	case *tg.AuthSentCodeTypeSMS:
+		code.Type = "sms"
+	case *tg.AuthSentCodeTypeCall:
+		code.Type = "call"
+	}
+
+	t.loginMu.Lock()
+	t.login = loginState{
+		step:          LoginStepCode,
+		phoneCodeHash: sentCode.PhoneCodeHash,
+		expiresAt:     code.ExpiresAt,
+		resendAfter:   code.ResendAfter,
+	}
+	t.loginMu.Unlock()
+
+	return code, nil
+}
+
+func (t *TelegramClient) setLoginStep(step LoginStep) {
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+
+	t.login.step = step
+	t.login.expiresAt = time.Now().Add(constants.TGUserClientLoginCodeTimeout)
+}
+
+// finishLogin marks the client as authorized, calls waiting for the login go through from now on
+func (t *TelegramClient) finishLogin() {
+	t.CancelLogin()
+
+	t.mu.Lock()
+	t.authorized = true
+	t.mu.Unlock()
+
+	log.Print("TG User Client: Logged in")
+}
+
+// expireLogin drops the login in progress when it has timed out, the caller must hold loginMu
+func (t *TelegramClient) expireLogin() bool {
+	if t.login.step == LoginStepNone || time.Now().Before(t.login.expiresAt) {
+		return false
+	}
+
+	if t.login.cancelQR != nil {
+		t.login.cancelQR()
+	}
+	t.login = loginState{}
+	return true
+}
@@ -0,0 +1,167 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTelegramClient creates a configured client that is never started, so only the login state is used
+func newTestTelegramClient(phoneNumber string) *TelegramClient {
+	return NewTelegramClient(&config.Config{
+		TGUserClientAppID:       1,
+		TGUserClientAppHash:     "hash",
+		TGUserClientPhoneNumber: phoneNumber,
+	}, new(session.StorageMemory))
+}
+
+func TestTelegramClient_SetLoginCode(t *testing.T) {
+	withTimeout := &tg.AuthSentCode{Type: &tg.AuthSentCodeTypeApp{}, PhoneCodeHash: "hash"}
+	withTimeout.SetTimeout(60)
+
+	tests := []struct {
+		name       string
+		sentCode   tg.AuthSentCodeClass
+		wantType   string
+		wantResend time.Duration
+		wantErr    bool
+	}{
+		{
+			name:     "code in the app with a resend timeout",
+			sentCode: withTimeout,
+			wantType: "app", wantResend: time.Minute,
+		},
+		{
+			name:     "code by SMS without a timeout",
+			sentCode: &tg.AuthSentCode{Type: &tg.AuthSentCodeTypeSMS{}, PhoneCodeHash: "hash"},
+			wantType: "sms",
+		},
+		{
+			name:     "code by call",
+			sentCode: &tg.AuthSentCode{Type: &tg.AuthSentCodeTypeCall{}, PhoneCodeHash: "hash"},
+			wantType: "call",
+		},
+		{
+			name:     "code sent another way",
+			sentCode: &tg.AuthSentCode{Type: &tg.AuthSentCodeTypeFlashCall{}, PhoneCodeHash: "hash"},
+			wantType: "other",
+		},
+		{
+			name:     "the account is logged in already",
+			sentCode: &tg.AuthSentCodeSuccess{},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestTelegramClient("+10000000000")
+			client.login = loginState{step: LoginStepCode, expiresAt: time.Now().Add(time.Minute)}
+
+			before := time.Now()
+			code, err := client.setLoginCode(tt.sentCode)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, LoginStepNone, client.GetLoginStep(), "the login is cancelled")
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantType, code.Type)
+			assert.WithinDuration(t, before.Add(tt.wantResend), code.ResendAfter, time.Second)
+			assert.WithinDuration(t, before.Add(constants.TGUserClientLoginCodeTimeout), code.ExpiresAt, time.Second)
+			assert.Equal(t, LoginStepCode, client.GetLoginStep())
+			assert.Equal(t, "hash", client.login.phoneCodeHash)
+		})
+	}
+}
+
+func TestTelegramClient_LoginTimeout(t *testing.T) {
+	client := newTestTelegramClient("+10000000000")
+	client.login = loginState{step: LoginStepCode, phoneCodeHash: "hash", expiresAt: time.Now().Add(-time.Second)}
+
+	assert.ErrorIs(t, client.SignInWithCode(context.Background(), "12345"), ErrLoginExpired)
+	assert.Equal(t, LoginStepNone, client.GetLoginStep())
+
+	// The code of an expired login can not be resent
+	client.login = loginState{step: LoginStepCode, phoneCodeHash: "hash", expiresAt: time.Now().Add(-time.Second)}
+	_, err := client.ResendLoginCode(context.Background())
+	assert.ErrorIs(t, err, ErrLoginNotStarted)
+
+	// The 2FA password prompt expires too
+	client.login = loginState{step: LoginStepPassword, expiresAt: time.Now().Add(-time.Second)}
+	assert.ErrorIs(t, client.SignInWithPassword(context.Background(), "secret"), ErrLoginNotStarted)
+	assert.Equal(t, LoginStepNone, client.GetLoginStep())
+}
+
+func TestTelegramClient_ResendLoginCode(t *testing.T) {
+	client := newTestTelegramClient("+10000000000")
+
+	_, err := client.ResendLoginCode(context.Background())
+	assert.ErrorIs(t, err, ErrLoginNotStarted)
+
+	client.login = loginState{
+		step:          LoginStepCode,
+		phoneCodeHash: "hash",
+		expiresAt:     time.Now().Add(time.Minute),
+		resendAfter:   time.Now().Add(30 * time.Second),
+	}
+	_, err = client.ResendLoginCode(context.Background())
+	assert.ErrorIs(t, err, ErrLoginResendTooEarly)
+	assert.Equal(t, LoginStepCode, client.GetLoginStep(), "the login goes on")
+
+	// The QR login has no code to resend
+	client.login = loginState{step: LoginStepQR, expiresAt: time.Now().Add(time.Minute)}
+	_, err = client.ResendLoginCode(context.Background())
+	assert.ErrorIs(t, err, ErrLoginNotStarted)
+}
+
+func TestTelegramClient_LoginStepTransitions(t *testing.T) {
+	client := newTestTelegramClient("+10000000000")
+
+	// The code and the password are checked only at their steps
+	assert.ErrorIs(t, client.SignInWithCode(context.Background(), "12345"), ErrLoginNotStarted)
+	assert.ErrorIs(t, client.SignInWithPassword(context.Background(), "secret"), ErrLoginNotStarted)
+
+	client.login = loginState{step: LoginStepCode, phoneCodeHash: "hash", expiresAt: time.Now().Add(time.Second)}
+	assert.ErrorIs(t, client.SignInWithPassword(context.Background(), "secret"), ErrLoginNotStarted)
+
+	// The password step gets the full timeout once the code is accepted
+	client.setLoginStep(LoginStepPassword)
+	assert.Equal(t, LoginStepPassword, client.GetLoginStep())
+	assert.WithinDuration(t, time.Now().Add(constants.TGUserClientLoginCodeTimeout), client.login.expiresAt, time.Second)
+	assert.ErrorIs(t, client.SignInWithCode(context.Background(), "12345"), ErrLoginNotStarted)
+
+	// Cancelling stops the QR login in progress
+	qrCtx, cancelQR := context.WithCancel(context.Background())
+	client.login = loginState{step: LoginStepQR, expiresAt: time.Now().Add(time.Minute), cancelQR: cancelQR}
+	client.CancelLogin()
+	assert.Equal(t, LoginStepNone, client.GetLoginStep())
+	assert.ErrorIs(t, qrCtx.Err(), context.Canceled)
+
+	// A login that succeeded lets the calls through
+	client.login = loginState{step: LoginStepPassword, expiresAt: time.Now().Add(time.Minute)}
+	client.finishLogin()
+	assert.Equal(t, LoginStepNone, client.GetLoginStep())
+	assert.True(t, client.authorized)
+}
+
+func TestTelegramClient_LoginNotConfigured(t *testing.T) {
+	_, err := newTestTelegramClient("").SendLoginCode(context.Background())
+	assert.ErrorIs(t, err, ErrLoginPhoneNotConfigured)
+
+	client := NewTelegramClient(&config.Config{}, new(session.StorageMemory))
+	_, err = client.SendLoginCode(context.Background())
+	assert.ErrorIs(t, err, client.configErr)
+	assert.ErrorIs(t, client.LoginWithQR(context.Background(), nil), client.configErr)
+	assert.ErrorIs(t, client.Logout(context.Background()), client.configErr)
+	assert.Equal(t, LoginStepNone, client.GetLoginStep())
+}
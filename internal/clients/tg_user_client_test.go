@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnectLoop(t *testing.T) {
+	const (
+		minDelay = 20 * time.Millisecond
+		maxDelay = 160 * time.Millisecond
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var startedAt, failedAt []time.Time
+	connect := func(ctx context.Context) error {
+		startedAt = append(startedAt, time.Now())
+		defer func() { failedAt = append(failedAt, time.Now()) }()
+
+		switch len(startedAt) {
+		case 6:
+			// The connection lived long enough, the next delay is reset
+			time.Sleep(maxDelay + 10*time.Millisecond)
+		case 7:
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return errors.New("connection refused")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reconnectLoop(ctx, connect, minDelay, maxDelay)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the loop did not stop after the context was cancelled")
+	}
+
+	require.Len(t, startedAt, 7, "no reconnect after the context is cancelled")
+	delays := []time.Duration{minDelay, 2 * minDelay, 4 * minDelay, maxDelay, maxDelay}
+	for i, delay := range delays {
+		assert.GreaterOrEqual(t, startedAt[i+1].Sub(failedAt[i]), delay, "delay before the connection %d", i+2)
+	}
+	assert.Less(t, startedAt[6].Sub(failedAt[5]), maxDelay, "the delay is reset")
+}
+
+func TestReconnectLoopStopsWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	done := make(chan struct{})
+	go func() {
+		reconnectLoop(ctx, func(ctx context.Context) error {
+			attempts++
+			return errors.New("connection refused")
+		}, time.Hour, time.Hour)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the loop did not stop while waiting to reconnect")
+	}
+	assert.Equal(t, 1, attempts)
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"evo-bot-go/internal/constants"
 )
 
 // Config holds the application configuration
@@ -15,6 +17,7 @@ type Config struct {
 	SuperGroupChatID int64
 	OpenAIAPIKey     string
 	AdminUserID      int64
+	ClubTimezone     *time.Location
 
 	// Topics Management
 	ClosedTopicsIDs     []int
@@ -23,13 +26,16 @@ type Config struct {
 	ContentTopicID      int
 	AnnouncementTopicID int
 	IntroTopicID        int
+	RecapTopicID        int
 
 	// Telegram User Client
 	TGUserClientAppID       int
 	TGUserClientAppHash     string
 	TGUserClientPhoneNumber string
-	TGUserClient2FAPass     string
 	TGUserClientSessionType string
+	// Keys encrypting the stored session, the old ones only decrypt sessions stored before a rotation
+	TGUserClientSessionKey     string
+	TGUserClientSessionOldKeys []string
 
 	// Daily Summarization Feature
 	DBConnection             string
@@ -48,6 +54,33 @@ type Config struct {
 	RandomCoffeePairsTaskEnabled bool
 	RandomCoffeePairsTime        time.Time
 	RandomCoffeePairsDay         time.Weekday
+
+	// Score Feature
+	ScoreDecayTaskEnabled bool
+	ScoreDecayPercent     int
+
+	// Thanks Feature
+	ThanksDailyLimit   int
+	ThanksPairCooldown time.Duration
+
+	// Anti-Spam Feature
+	AdminLogChatID     int64
+	CaptchaEnabled     bool
+	CaptchaTimeout     time.Duration
+	AntiSpamEnabled    bool
+	AntiSpamLlmEnabled bool
+
+	// Membership Cache
+	MembershipCacheTTL time.Duration
+
+	// LLM Usage
+	LLMUserDailyQuota   int
+	LLMMonthlyBudgetUSD float64
+
+	// Observability
+	LogFormat         string
+	LogLevel          string
+	ObservabilityAddr string
 }
 
 // LoadConfig loads the configuration from environment variables
@@ -98,6 +131,17 @@ func LoadConfig() (*Config, error) {
 		config.AdminUserID = adminUserID
 	}
 
+	// Club timezone is used to parse event dates entered by admins (default: UTC)
+	clubTimezoneStr := os.Getenv("TG_EVO_BOT_CLUB_TIMEZONE")
+	if clubTimezoneStr == "" {
+		clubTimezoneStr = "UTC"
+	}
+	clubTimezone, err := time.LoadLocation(clubTimezoneStr)
+	if err != nil || clubTimezone == time.Local {
+		return nil, fmt.Errorf("invalid club timezone: %s", clubTimezoneStr)
+	}
+	config.ClubTimezone = clubTimezone
+
 	forwardingTopicIDStr := os.Getenv("TG_EVO_BOT_FORWARDING_TOPIC_ID")
 	if forwardingTopicIDStr != "" {
 		forwardingTopicID, err := strconv.Atoi(forwardingTopicIDStr)
@@ -143,6 +187,18 @@ func LoadConfig() (*Config, error) {
 		config.IntroTopicID = introTopicID
 	}
 
+	recapTopicIDStr := os.Getenv("TG_EVO_BOT_RECAP_TOPIC_ID")
+	if recapTopicIDStr == "" {
+		// Default to announcement topic if not specified
+		config.RecapTopicID = config.AnnouncementTopicID
+	} else {
+		recapTopicID, err := strconv.Atoi(recapTopicIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recap topic ID: %s", recapTopicIDStr)
+		}
+		config.RecapTopicID = recapTopicID
+	}
+
 	// Telegram User Client
 	tgUserClientAppIDStr := os.Getenv("TG_EVO_BOT_TGUSERCLIENT_APPID")
 	if tgUserClientAppIDStr != "" {
@@ -155,13 +211,30 @@ func LoadConfig() (*Config, error) {
 
 	config.TGUserClientAppHash = os.Getenv("TG_EVO_BOT_TGUSERCLIENT_APPHASH")
 	config.TGUserClientPhoneNumber = os.Getenv("TG_EVO_BOT_TGUSERCLIENT_PHONENUMBER")
-	config.TGUserClient2FAPass = os.Getenv("TG_EVO_BOT_TGUSERCLIENT_2FAPASS")
-	config.TGUserClientSessionType = os.Getenv("TG_EVO_BOT_TGUSERCLIENT_SESSION_TYPE")
+	config.TGUserClientSessionType = strings.ToLower(os.Getenv("TG_EVO_BOT_TGUSERCLIENT_SESSION_TYPE"))
+	switch config.TGUserClientSessionType {
+	case "", constants.TGUserClientSessionTypeMemory:
+		config.TGUserClientSessionType = constants.TGUserClientSessionTypeMemory
+	case constants.TGUserClientSessionTypeFile, constants.TGUserClientSessionTypeDatabase:
+	default:
+		return nil, fmt.Errorf("invalid TG_EVO_BOT_TGUSERCLIENT_SESSION_TYPE: %s", config.TGUserClientSessionType)
+	}
+
+	config.TGUserClientSessionKey, config.TGUserClientSessionOldKeys, err = LoadTGUserClientSessionKeys()
+	if err != nil {
+		return nil, err
+	}
+	if config.TGUserClientSessionKey == "" && config.TGUserClientSessionType != constants.TGUserClientSessionTypeMemory {
+		return nil, fmt.Errorf(
+			"TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY or TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE must be set to store the session in %s storage",
+			config.TGUserClientSessionType,
+		)
+	}
 
 	// Daily Summarization Feature
-	config.DBConnection = os.Getenv("TG_EVO_BOT_DB_CONNECTION")
-	if config.DBConnection == "" {
-		return nil, fmt.Errorf("TG_EVO_BOT_DB_CONNECTION environment variable is not set")
+	config.DBConnection, err = LoadDBConnection()
+	if err != nil {
+		return nil, err
 	}
 
 	// Monitored topic IDs
@@ -336,5 +409,204 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	// Score decay task enabled/disabled
+	scoreDecayTaskEnabledStr := os.Getenv("TG_EVO_BOT_SCORE_DECAY_TASK_ENABLED")
+	if scoreDecayTaskEnabledStr == "" {
+		// Default to enabled if not specified
+		config.ScoreDecayTaskEnabled = true
+	} else {
+		scoreDecayTaskEnabled, err := strconv.ParseBool(scoreDecayTaskEnabledStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid score decay task enabled value: %s", scoreDecayTaskEnabledStr)
+		}
+		config.ScoreDecayTaskEnabled = scoreDecayTaskEnabled
+	}
+
+	// Score decay percent, applied monthly (default: 10)
+	scoreDecayPercentStr := os.Getenv("TG_EVO_BOT_SCORE_DECAY_PERCENT")
+	if scoreDecayPercentStr == "" {
+		config.ScoreDecayPercent = 10
+	} else {
+		scoreDecayPercent, err := strconv.Atoi(scoreDecayPercentStr)
+		if err != nil || scoreDecayPercent < 0 || scoreDecayPercent > 100 {
+			return nil, fmt.Errorf("invalid score decay percent: %s", scoreDecayPercentStr)
+		}
+		config.ScoreDecayPercent = scoreDecayPercent
+	}
+
+	// Max number of thanks a member can give per day (default: 5)
+	thanksDailyLimitStr := os.Getenv("TG_EVO_BOT_THANKS_DAILY_LIMIT")
+	if thanksDailyLimitStr == "" {
+		config.ThanksDailyLimit = 5
+	} else {
+		thanksDailyLimit, err := strconv.Atoi(thanksDailyLimitStr)
+		if err != nil || thanksDailyLimit < 1 {
+			return nil, fmt.Errorf("invalid thanks daily limit: %s", thanksDailyLimitStr)
+		}
+		config.ThanksDailyLimit = thanksDailyLimit
+	}
+
+	// Hours before a member can thank the same member again (default: 24)
+	thanksPairCooldownStr := os.Getenv("TG_EVO_BOT_THANKS_PAIR_COOLDOWN_HOURS")
+	if thanksPairCooldownStr == "" {
+		config.ThanksPairCooldown = 24 * time.Hour
+	} else {
+		thanksPairCooldownHours, err := strconv.Atoi(thanksPairCooldownStr)
+		if err != nil || thanksPairCooldownHours < 0 {
+			return nil, fmt.Errorf("invalid thanks pair cooldown hours: %s", thanksPairCooldownStr)
+		}
+		config.ThanksPairCooldown = time.Duration(thanksPairCooldownHours) * time.Hour
+	}
+
+	// Chat for the admin log of anti-spam actions (default: admin user)
+	adminLogChatIDStr := os.Getenv("TG_EVO_BOT_ADMIN_LOG_CHAT_ID")
+	if adminLogChatIDStr == "" {
+		config.AdminLogChatID = config.AdminUserID
+	} else {
+		adminLogChatID, err := strconv.ParseInt(adminLogChatIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin log chat ID: %s", adminLogChatIDStr)
+		}
+		config.AdminLogChatID = adminLogChatID
+	}
+
+	// Captcha for new members (default: false)
+	captchaEnabledStr := os.Getenv("TG_EVO_BOT_CAPTCHA_ENABLED")
+	if captchaEnabledStr != "" {
+		captchaEnabled, err := strconv.ParseBool(captchaEnabledStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid captcha enabled value: %s", captchaEnabledStr)
+		}
+		config.CaptchaEnabled = captchaEnabled
+	}
+
+	// Minutes a new member has to pass the captcha (default: 5)
+	captchaTimeoutStr := os.Getenv("TG_EVO_BOT_CAPTCHA_TIMEOUT_MINUTES")
+	if captchaTimeoutStr == "" {
+		config.CaptchaTimeout = 5 * time.Minute
+	} else {
+		captchaTimeoutMinutes, err := strconv.Atoi(captchaTimeoutStr)
+		if err != nil || captchaTimeoutMinutes < 1 {
+			return nil, fmt.Errorf("invalid captcha timeout minutes: %s", captchaTimeoutStr)
+		}
+		config.CaptchaTimeout = time.Duration(captchaTimeoutMinutes) * time.Minute
+	}
+
+	// Spam checks of the first messages of new members (default: false)
+	antiSpamEnabledStr := os.Getenv("TG_EVO_BOT_ANTISPAM_ENABLED")
+	if antiSpamEnabledStr != "" {
+		antiSpamEnabled, err := strconv.ParseBool(antiSpamEnabledStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anti-spam enabled value: %s", antiSpamEnabledStr)
+		}
+		config.AntiSpamEnabled = antiSpamEnabled
+	}
+
+	// LLM classification of the first messages of new members (default: false)
+	antiSpamLlmEnabledStr := os.Getenv("TG_EVO_BOT_ANTISPAM_LLM_ENABLED")
+	if antiSpamLlmEnabledStr != "" {
+		antiSpamLlmEnabled, err := strconv.ParseBool(antiSpamLlmEnabledStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anti-spam LLM enabled value: %s", antiSpamLlmEnabledStr)
+		}
+		config.AntiSpamLlmEnabled = antiSpamLlmEnabled
+	}
+
+	// Minutes chat membership lookups stay cached (default: 10)
+	membershipCacheTTLStr := os.Getenv("TG_EVO_BOT_MEMBERSHIP_CACHE_TTL_MINUTES")
+	if membershipCacheTTLStr == "" {
+		config.MembershipCacheTTL = 10 * time.Minute
+	} else {
+		membershipCacheTTLMinutes, err := strconv.Atoi(membershipCacheTTLStr)
+		if err != nil || membershipCacheTTLMinutes < 1 {
+			return nil, fmt.Errorf("invalid membership cache TTL minutes: %s", membershipCacheTTLStr)
+		}
+		config.MembershipCacheTTL = time.Duration(membershipCacheTTLMinutes) * time.Minute
+	}
+
+	// LLM Usage
+	// LLM requests a user can make per day, 0 means no limit (default: 20)
+	llmUserDailyQuotaStr := os.Getenv("TG_EVO_BOT_LLM_USER_DAILY_QUOTA")
+	if llmUserDailyQuotaStr == "" {
+		config.LLMUserDailyQuota = 20
+	} else {
+		llmUserDailyQuota, err := strconv.Atoi(llmUserDailyQuotaStr)
+		if err != nil || llmUserDailyQuota < 0 {
+			return nil, fmt.Errorf("invalid LLM user daily quota: %s", llmUserDailyQuotaStr)
+		}
+		config.LLMUserDailyQuota = llmUserDailyQuota
+	}
+
+	// Estimated LLM spending per calendar month in US dollars, 0 means no limit (default: 0)
+	llmMonthlyBudgetStr := os.Getenv("TG_EVO_BOT_LLM_MONTHLY_BUDGET_USD")
+	if llmMonthlyBudgetStr != "" {
+		llmMonthlyBudget, err := strconv.ParseFloat(llmMonthlyBudgetStr, 64)
+		if err != nil || llmMonthlyBudget < 0 {
+			return nil, fmt.Errorf("invalid LLM monthly budget: %s", llmMonthlyBudgetStr)
+		}
+		config.LLMMonthlyBudgetUSD = llmMonthlyBudget
+	}
+
+	// Observability
+	// Log format: text or json (default: text)
+	config.LogFormat = strings.ToLower(os.Getenv("TG_EVO_BOT_LOG_FORMAT"))
+	if config.LogFormat == "" {
+		config.LogFormat = "text"
+	}
+	if config.LogFormat != "text" && config.LogFormat != "json" {
+		return nil, fmt.Errorf("invalid log format: %s", config.LogFormat)
+	}
+
+	// Log level: debug, info, warn or error (default: info)
+	config.LogLevel = os.Getenv("TG_EVO_BOT_LOG_LEVEL")
+	if config.LogLevel == "" {
+		config.LogLevel = "info"
+	}
+
+	// Address of the /metrics, /healthz and /readyz endpoints, e.g. ":9090" (the endpoints are disabled if not set)
+	config.ObservabilityAddr = os.Getenv("TG_EVO_BOT_OBSERVABILITY_ADDR")
+
 	return config, nil
 }
+
+// LoadTGUserClientSessionKeys reads the keys encrypting the Telegram user client session:
+// the current key from TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY or the file in TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE,
+// and the comma-separated old keys from TG_EVO_BOT_TGUSERCLIENT_SESSION_OLD_KEYS
+func LoadTGUserClientSessionKeys() (string, []string, error) {
+	key := strings.TrimSpace(os.Getenv("TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY"))
+	keyFile := os.Getenv("TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE")
+	if key != "" && keyFile != "" {
+		return "", nil, fmt.Errorf("only one of TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY and TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE can be set")
+	}
+	if keyFile != "" {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE: %w", err)
+		}
+		key = strings.TrimSpace(string(content))
+		if key == "" {
+			return "", nil, fmt.Errorf("TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE is empty: %s", keyFile)
+		}
+	}
+
+	var oldKeys []string
+	for _, oldKey := range strings.Split(os.Getenv("TG_EVO_BOT_TGUSERCLIENT_SESSION_OLD_KEYS"), ",") {
+		if oldKey = strings.TrimSpace(oldKey); oldKey != "" {
+			oldKeys = append(oldKeys, oldKey)
+		}
+	}
+	if len(oldKeys) > 0 && key == "" {
+		return "", nil, fmt.Errorf("TG_EVO_BOT_TGUSERCLIENT_SESSION_OLD_KEYS is set without the current session key")
+	}
+
+	return key, oldKeys, nil
+}
+
+// LoadDBConnection reads the PostgreSQL connection string, it is the only setting the migrate subcommand needs
+func LoadDBConnection() (string, error) {
+	dbConnection := os.Getenv("TG_EVO_BOT_DB_CONNECTION")
+	if dbConnection == "" {
+		return "", fmt.Errorf("TG_EVO_BOT_DB_CONNECTION environment variable is not set")
+	}
+	return dbConnection, nil
+}
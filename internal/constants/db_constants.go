@@ -33,3 +33,179 @@ var AllEventStatuses = []EventStatus{
 	EventStatusFinished,
 	EventStatusActual,
 }
+
+// EventTypesWithCapacity lists offline event types that can have a limited number of places
+var EventTypesWithCapacity = []EventType{
+	EventTypeMeetup,
+	EventTypeWorkshop,
+	EventTypeConference,
+}
+
+// EventRegistrationStatus represents the status of a user's registration for an event
+type EventRegistrationStatus string
+
+const (
+	EventRegistrationStatusGoing    EventRegistrationStatus = "going"
+	EventRegistrationStatusWaitlist EventRegistrationStatus = "waitlist"
+)
+
+// ScoreReason represents the reason of a score ledger entry
+type ScoreReason string
+
+const (
+	ScoreReasonTopicProposed         ScoreReason = "topic_proposed"
+	ScoreReasonEventRegistered       ScoreReason = "event_registered"
+	ScoreReasonRandomCoffeeCompleted ScoreReason = "random_coffee_completed"
+	ScoreReasonThanksReceived        ScoreReason = "thanks_received"
+	ScoreReasonProfilePublished      ScoreReason = "profile_published"
+	ScoreReasonAdminAdjustment       ScoreReason = "admin_adjustment"
+	ScoreReasonDecay                 ScoreReason = "decay"
+)
+
+// ThanksSource represents how a member expressed gratitude
+type ThanksSource string
+
+const (
+	ThanksSourceReply    ThanksSource = "reply"
+	ThanksSourceReaction ThanksSource = "reaction"
+)
+
+// ModerationPosters represents who may post in a topic under a moderation rule
+type ModerationPosters string
+
+const (
+	ModerationPostersAdmins   ModerationPosters = "admins"
+	ModerationPostersMembers  ModerationPosters = "members"
+	ModerationPostersEveryone ModerationPosters = "everyone"
+)
+
+// ModerationAction represents what happens to a message that breaks a moderation rule
+type ModerationAction string
+
+const (
+	ModerationActionDelete  ModerationAction = "delete"
+	ModerationActionWarn    ModerationAction = "warn"
+	ModerationActionMute    ModerationAction = "mute"
+	ModerationActionForward ModerationAction = "forward"
+)
+
+// MemberSanction represents a moderation action applied to a member by the moderation commands
+type MemberSanction string
+
+const (
+	MemberSanctionWarn   MemberSanction = "warn"
+	MemberSanctionMute   MemberSanction = "mute"
+	MemberSanctionUnmute MemberSanction = "unmute"
+	MemberSanctionBan    MemberSanction = "ban"
+	MemberSanctionUnban  MemberSanction = "unban"
+)
+
+// AuditEntity represents the type of an entity changed by an admin
+type AuditEntity string
+
+const (
+	AuditEntityEvent             AuditEntity = "event"
+	AuditEntityEventRecap        AuditEntity = "event_recap"
+	AuditEntityTopic             AuditEntity = "topic"
+	AuditEntityUser              AuditEntity = "user"
+	AuditEntityProfile           AuditEntity = "profile"
+	AuditEntityScore             AuditEntity = "score"
+	AuditEntityModerationRule    AuditEntity = "moderation_rule"
+	AuditEntityRandomCoffeePoll  AuditEntity = "random_coffee_poll"
+	AuditEntityRandomCoffeePairs AuditEntity = "random_coffee_pairs"
+	AuditEntityBroadcast         AuditEntity = "broadcast"
+)
+
+// AllAuditEntities is a slice containing all possible AuditEntity values
+var AllAuditEntities = []AuditEntity{
+	AuditEntityEvent,
+	AuditEntityEventRecap,
+	AuditEntityTopic,
+	AuditEntityUser,
+	AuditEntityProfile,
+	AuditEntityScore,
+	AuditEntityModerationRule,
+	AuditEntityRandomCoffeePoll,
+	AuditEntityRandomCoffeePairs,
+	AuditEntityBroadcast,
+}
+
+// AuditAction represents what an admin did with an entity
+type AuditAction string
+
+const (
+	AuditActionCreate   AuditAction = "create"
+	AuditActionUpdate   AuditAction = "update"
+	AuditActionDelete   AuditAction = "delete"
+	AuditActionPublish  AuditAction = "publish"
+	AuditActionStart    AuditAction = "start"
+	AuditActionModerate AuditAction = "moderate"
+	AuditActionGrant    AuditAction = "grant"
+	AuditActionRevoke   AuditAction = "revoke"
+)
+
+// Role represents a role of a user in the club
+type Role string
+
+const (
+	RoleOwner          Role = "owner"
+	RoleAdmin          Role = "admin"
+	RoleEventOrganizer Role = "event_organizer"
+	RoleModerator      Role = "moderator"
+	RoleCoffeeManager  Role = "coffee_manager"
+	RoleMember         Role = "member"
+)
+
+// AllRoles is a slice containing all possible Role values, ordered from the most powerful
+var AllRoles = []Role{
+	RoleOwner,
+	RoleAdmin,
+	RoleEventOrganizer,
+	RoleModerator,
+	RoleCoffeeManager,
+	RoleMember,
+}
+
+// OutgoingMessageStatus represents the delivery status of a queued message
+type OutgoingMessageStatus string
+
+const (
+	OutgoingMessageStatusPending OutgoingMessageStatus = "pending"
+	OutgoingMessageStatusSent    OutgoingMessageStatus = "sent"
+	OutgoingMessageStatusFailed  OutgoingMessageStatus = "failed"
+	OutgoingMessageStatusBlocked OutgoingMessageStatus = "blocked"
+)
+
+// MessagePriority defines the order of outgoing messages, lower values are sent first
+type MessagePriority int
+
+const (
+	MessagePriorityInteractive  MessagePriority = 0 // replies to users, sent right away
+	MessagePriorityNotification MessagePriority = 1 // personal notifications, e.g. waitlist promotions
+	MessagePriorityBulk         MessagePriority = 2 // mass mailings
+)
+
+// BroadcastAudience represents who receives a broadcast
+type BroadcastAudience string
+
+const (
+	BroadcastAudienceAllMembers         BroadcastAudience = "all_members"
+	BroadcastAudienceCoffeeParticipants BroadcastAudience = "coffee_participants"
+	BroadcastAudienceWithoutProfile     BroadcastAudience = "without_profile"
+	BroadcastAudienceEventRegistrations BroadcastAudience = "event_registrations"
+)
+
+// LLMFeature represents the bot feature an LLM call was made for
+type LLMFeature string
+
+const (
+	LLMFeatureTools         LLMFeature = "tools"
+	LLMFeatureContent       LLMFeature = "content"
+	LLMFeatureIntro         LLMFeature = "intro"
+	LLMFeatureProfileSearch LLMFeature = "profile_search"
+	LLMFeatureSummarization LLMFeature = "summarization"
+	LLMFeatureEventRecap    LLMFeature = "event_recap"
+	LLMFeatureAntiSpam      LLMFeature = "anti_spam"
+	LLMFeatureDirectory     LLMFeature = "directory"
+	LLMFeatureUnknown       LLMFeature = "unknown"
+)
@@ -1,9 +1,180 @@
 package constants
 
+import "time"
+
 const PrivateChatType = "private"
 const CancelCommand = "cancel"
 
 // Profile fields
 const (
-	ProfileBioLengthLimit = 3900 //max Telegram message length
+	ProfileBioLengthLimit  = 3900 // leaves room for the name and the facts within the message limit
+	ProfileTextLengthLimit = 100  // city, country, job title and company
+	ProfileTagsLimit       = 15   // skills, "can help with", "looking for" and languages
+	ProfileTagLengthLimit  = 40
+	ProfileCaptionLimit    = 1024 // max Telegram caption length, the published photo keeps the rest for a follow-up message
+	ProfileMessageLimit    = 4096 // max Telegram message length
+)
+
+// ProfileField represents an optional field of a profile that is edited with a single message
+type ProfileField string
+
+const (
+	ProfileFieldLocation    ProfileField = "location"
+	ProfileFieldJob         ProfileField = "job"
+	ProfileFieldSkills      ProfileField = "skills"
+	ProfileFieldCanHelpWith ProfileField = "can_help_with"
+	ProfileFieldLookingFor  ProfileField = "looking_for"
+	ProfileFieldLanguages   ProfileField = "languages"
+	ProfileFieldLinks       ProfileField = "links"
+)
+
+// AllProfileFields is a slice containing all possible ProfileField values, in the order they are shown
+var AllProfileFields = []ProfileField{
+	ProfileFieldLocation,
+	ProfileFieldJob,
+	ProfileFieldSkills,
+	ProfileFieldCanHelpWith,
+	ProfileFieldLookingFor,
+	ProfileFieldLanguages,
+	ProfileFieldLinks,
+}
+
+// ProfileLinkType represents the type of a personal link in a profile
+type ProfileLinkType string
+
+const (
+	ProfileLinkTelegram ProfileLinkType = "telegram"
+	ProfileLinkGitHub   ProfileLinkType = "github"
+	ProfileLinkLinkedIn ProfileLinkType = "linkedin"
+	ProfileLinkWebsite  ProfileLinkType = "website"
+)
+
+// AllProfileLinkTypes is a slice containing all possible ProfileLinkType values, in the order they are shown
+var AllProfileLinkTypes = []ProfileLinkType{
+	ProfileLinkTelegram,
+	ProfileLinkGitHub,
+	ProfileLinkLinkedIn,
+	ProfileLinkWebsite,
+}
+
+// Score rules: points awarded for member activity
+var ScoreRules = map[ScoreReason]int{
+	ScoreReasonTopicProposed:         2,
+	ScoreReasonEventRegistered:       5,
+	ScoreReasonRandomCoffeeCompleted: 5,
+	ScoreReasonThanksReceived:        1,
+	ScoreReasonProfilePublished:      3,
+}
+
+// Score fields
+const (
+	ScoreLeaderboardLimit = 10
+	ScoreHistoryLimit     = 10
+)
+
+// Thanks fields
+const (
+	ThanksMessageAuthorsTTL = 72 * time.Hour // how long authors of group messages are remembered for reactions
+)
+
+// Message reactions that count as thanks
+var ThanksReactionEmojis = []string{"👍", "❤", "🔥", "🙏", "👏", "🤝", "💯", "🏆"}
+
+// Moderation actions fields
+const (
+	ModerationWarningsBeforeMute   = 3              // warnings since the last mute or ban that trigger an automatic mute
+	ModerationWarningsMuteDuration = 24 * time.Hour // duration of the automatic mute
+	ModerationLogLimit             = 20
+	ModerationHistoryLimit         = 5
 )
+
+// Capability represents an action a role allows
+type Capability string
+
+const (
+	CapabilityManageEvents          Capability = "manage_events"
+	CapabilityManageProfiles        Capability = "manage_profiles"
+	CapabilityManageScore           Capability = "manage_score"
+	CapabilityModerate              Capability = "moderate"
+	CapabilityManageModerationRules Capability = "manage_moderation_rules"
+	CapabilityManageRandomCoffee    Capability = "manage_random_coffee"
+	CapabilityViewAudit             Capability = "view_audit"
+	CapabilityManageRoles           Capability = "manage_roles"
+	CapabilityManageBot             Capability = "manage_bot"
+	CapabilityBroadcast             Capability = "broadcast"
+	CapabilityViewUsage             Capability = "view_usage"
+)
+
+// AllCapabilities is a slice containing all possible Capability values
+var AllCapabilities = []Capability{
+	CapabilityManageEvents,
+	CapabilityManageProfiles,
+	CapabilityManageScore,
+	CapabilityModerate,
+	CapabilityManageModerationRules,
+	CapabilityManageRandomCoffee,
+	CapabilityViewAudit,
+	CapabilityManageRoles,
+	CapabilityManageBot,
+	CapabilityBroadcast,
+	CapabilityViewUsage,
+}
+
+// RoleCapabilities lists what every role allows. Members of the club have no management capabilities.
+var RoleCapabilities = map[Role][]Capability{
+	RoleOwner:          AllCapabilities,
+	RoleAdmin:          AllCapabilities,
+	RoleEventOrganizer: {CapabilityManageEvents},
+	RoleModerator:      {CapabilityModerate},
+	RoleCoffeeManager:  {CapabilityManageRandomCoffee},
+	RoleMember:         {},
+}
+
+// Audit log fields
+const (
+	AuditLogLimit          = 20
+	AuditLogValueMaxLength = 200 // max length of before and after values shown by /audit
+)
+
+// Outgoing messages fields: Telegram rate limits and the queue of messages sent in the background
+const (
+	RateLimitGlobalPerSecond      = 30 // messages per second to all chats together
+	RateLimitPrivateChatPerSecond = 1  // messages per second to a single private chat
+	RateLimitPrivateChatBurst     = 3  // messages that can be sent to a private chat at once
+	RateLimitGroupChatPerMinute   = 20 // messages per minute to a single group
+	RateLimitMaxRetries           = 3  // retries of a send that got 429 Too Many Requests
+
+	OutgoingQueuePollInterval = 2 * time.Second
+	OutgoingQueueBatchSize    = 100
+	OutgoingQueueMaxAttempts  = 5
+	OutgoingQueueRetryDelay   = time.Minute         // multiplied by the number of attempts
+	OutgoingQueueRetention    = 30 * 24 * time.Hour // how long sent and failed messages are kept
+	OutgoingQueueClaimTimeout = 15 * time.Minute    // how long claimed messages are hidden from other instances
+)
+
+// Broadcast fields
+const (
+	BroadcastCoffeeMaxWeeks      = 52
+	BroadcastEventsListLimit     = 10
+	BroadcastReportCheckInterval = time.Minute
+)
+
+// LLM usage fields
+const (
+	LLMUsageTopUsersLimit = 10
+	LLMUsageMaxDays       = 365 // max period of the /usage report
+)
+
+// LLMModelPrice is the price of a model in US dollars per million tokens
+type LLMModelPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// LLMModelPrices is used to estimate the cost of LLM calls, models missing here are counted as free
+var LLMModelPrices = map[string]LLMModelPrice{
+	"o3-mini":                {PromptPerMillion: 1.10, CompletionPerMillion: 4.40},
+	"o4-mini":                {PromptPerMillion: 1.10, CompletionPerMillion: 4.40},
+	"gpt-4.1-mini":           {PromptPerMillion: 0.40, CompletionPerMillion: 1.60},
+	"text-embedding-ada-002": {PromptPerMillion: 0.10},
+}
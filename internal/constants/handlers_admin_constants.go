@@ -1,6 +1,5 @@
 package constants
 
-const CodeCommand = "code"
 const TrySummarizeCommand = "trySummarize"
 const SummarizeDmFlag = "-dm"
 
@@ -10,10 +9,57 @@ const EventEditGetLastLimit = 10
 const EventSetupCommand = "eventSetup"
 const EventDeleteCommand = "eventDelete"
 const EventStartCommand = "eventStart"
+const EventRecapCommand = "eventRecap"
+const EventAttendeesCommand = "eventAttendees"
+const EventRecapTranscriptMaxFileSize = 5 * 1024 * 1024
+const EventRecapTranscriptChunkSize = 12000
+const EventRecapItemLengthLimit = 500 // max length of a point of the recap, the model can ignore the prompt
+
+// EventRecapTopicStatus tells how a proposed topic was covered at the event
+type EventRecapTopicStatus string
+
+const (
+	EventRecapTopicCovered    EventRecapTopicStatus = "covered"
+	EventRecapTopicPartial    EventRecapTopicStatus = "partial"
+	EventRecapTopicNotCovered EventRecapTopicStatus = "not_covered"
+)
 
 // Topics Handlers
 const ShowTopicsCommand = "showTopics"
 
+// Score Handlers
+const ScoreAdjustCommand = "scoreAdjust"
+
+// Moderation Handlers
+const ModerationRulesCommand = "moderationRules"
+
+// Moderation commands, used as replies in the group or with a user reference in DM
+const (
+	WarnCommand   = "warn"
+	MuteCommand   = "mute"
+	UnmuteCommand = "unmute"
+	BanCommand    = "ban"
+	UnbanCommand  = "unban"
+	ModLogCommand = "modlog"
+)
+
+// Audit Handler
+const AuditCommand = "audit"
+
+// Roles Handler
+const RolesCommand = "roles"
+
+// Usage Handler
+const UsageCommand = "usage"
+
+// Callback data constants for admin "/moderationRules" handler
+const (
+	ModerationRulesPrefix         = "moderation_rules_"
+	ModerationRulesSaveCallback   = ModerationRulesPrefix + "save"
+	ModerationRulesDeleteCallback = ModerationRulesPrefix + "delete"
+	ModerationRulesCancelCallback = ModerationRulesPrefix + "cancel"
+)
+
 // Profiles Handler
 const AdminProfilesCommand = "profilesManager"
 
@@ -31,6 +77,7 @@ const (
 	AdminProfilesEditFirstnameCallback    = AdminProfilesPrefix + "edit_firstname"
 	AdminProfilesEditLastnameCallback     = AdminProfilesPrefix + "edit_lastname"
 	AdminProfilesEditUsernameCallback     = AdminProfilesPrefix + "edit_username"
+	AdminProfilesEditFieldPrefix          = AdminProfilesPrefix + "edit_field_" // followed by the ProfileField
 	AdminProfilesEditCoffeeBanCallback    = AdminProfilesPrefix + "edit_coffee_ban"
 	AdminProfilesToggleCoffeeBanCallback  = AdminProfilesPrefix + "toggle_coffee_ban"
 	AdminProfilesPublishCallback          = AdminProfilesPrefix + "publish"
@@ -56,3 +103,38 @@ const (
 	TryGenerateCoffeePairsBackCallback    = TryGenerateCoffeePairsPrefix + "back"
 	TryGenerateCoffeePairsCancelCallback  = TryGenerateCoffeePairsPrefix + "cancel"
 )
+
+// Broadcast Handler
+const BroadcastCommand = "broadcast"
+
+// Callback data constants for admin "/broadcast" handler
+const (
+	BroadcastPrefix                     = "broadcast_"
+	BroadcastAudienceAllMembersCallback = BroadcastPrefix + "audience_all_members"
+	BroadcastAudienceCoffeeCallback     = BroadcastPrefix + "audience_coffee"
+	BroadcastAudienceNoProfileCallback  = BroadcastPrefix + "audience_no_profile"
+	BroadcastAudienceEventCallback      = BroadcastPrefix + "audience_event"
+	BroadcastSendNowCallback            = BroadcastPrefix + "send_now"
+	BroadcastConfirmCallback            = BroadcastPrefix + "confirm"
+	BroadcastCancelCallback             = BroadcastPrefix + "cancel"
+)
+
+// User Client Handler
+const UserClientCommand = "userclient"
+
+// Subcommands of the admin "/userclient" handler
+const (
+	UserClientStatusSubcommand = "status"
+	UserClientLoginSubcommand  = "login"
+	UserClientLogoutSubcommand = "logout"
+)
+
+// Callback data constants for admin "/userclient" handler
+const (
+	UserClientPrefix                = "userclient_"
+	UserClientLoginQRCallback       = UserClientPrefix + "login_qr"
+	UserClientLoginCodeCallback     = UserClientPrefix + "login_code"
+	UserClientResendCodeCallback    = UserClientPrefix + "resend_code"
+	UserClientConfirmLogoutCallback = UserClientPrefix + "confirm_logout"
+	UserClientCancelCallback        = UserClientPrefix + "cancel"
+)
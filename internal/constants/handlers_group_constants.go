@@ -0,0 +1,74 @@
+package constants
+
+// Callback data constants for the random coffee pairs message
+const (
+	RandomCoffeePrefix            = "random_coffee_"
+	RandomCoffeeMetCallbackPrefix = RandomCoffeePrefix + "met_"
+)
+
+// Callback data constants for the new member captcha message
+const (
+	CaptchaPrefix               = "captcha_"
+	CaptchaAnswerCallbackPrefix = CaptchaPrefix + "answer_"
+)
+
+// Anti-spam fields
+const (
+	// Number of answer buttons in the captcha message
+	CaptchaOptionsCount = 4
+
+	// Number of first messages of a new member that are checked for spam
+	AntiSpamFirstMessagesCount = 3
+
+	// Max length of the message text quoted in the admin log
+	AntiSpamLogTextMaxLength = 300
+)
+
+// CaptchaOptions maps captcha emojis to their names used in the question
+var CaptchaOptions = map[string]string{
+	"🍎": "яблоко",
+	"🚗": "машину",
+	"🐶": "собаку",
+	"⚽": "мяч",
+	"🎸": "гитару",
+	"🌵": "кактус",
+	"🚀": "ракету",
+	"☕": "чашку кофе",
+}
+
+// AntiSpamPhrases contains phrases typical for spam in the first messages of new members
+var AntiSpamPhrases = []string{
+	"заработок от",
+	"заработок в интернете",
+	"пассивный доход",
+	"пишите в лс",
+	"пишите в личку",
+	"пиши в лс",
+	"набираю команду",
+	"ищу людей для удаленной работы",
+	"без вложений",
+	"заработок на криптовалюте",
+	"казино",
+	"ставки на спорт",
+	"18+",
+}
+
+// Moderation rules fields
+const (
+	ModerationDefaultMuteMinutes = 60
+	ModerationWarnCleanupSeconds = 30
+
+	// Placeholders available in DM templates of moderation rules
+	ModerationTemplateTopicUrl = "{topic_url}"
+	ModerationTemplateReason   = "{reason}"
+
+	// DM template of read-only topics from TG_EVO_BOT_CLOSED_TOPICS_IDS
+	ModerationClosedTopicDmTemplate = "*Приношу свои извинения* 🧐\n\n" +
+		"Твоё сообщение в канале " + ModerationTemplateTopicUrl + " было удалено, поскольку этот канал предназначен только для чтения. " +
+		"Однако ты можешь присоединиться к обсуждению, используя функцию *Reply* (ответ) на интересующий тебя пост. " +
+		"Твой ответ автоматически появится в чате \"_Оффтопчик_\" 👌\n\n" +
+		"⬇️ _Копия твоего сообщения_ ⬇️"
+
+	// Warning posted in the topic when no DM template is set for a warn rule
+	ModerationWarnDefaultText = "⚠️ Сообщение нарушает правила этого канала: " + ModerationTemplateReason
+)
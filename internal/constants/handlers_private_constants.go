@@ -5,10 +5,13 @@ const ContentCommand = "content"
 const EventsCommand = "events"
 const TopicsCommand = "topics"
 const TopicAddCommand = "topicAdd"
+const EventRsvpCommand = "rsvp"
+const TopCommand = "top"
 const HelpCommand = "help"
 const StartCommand = "start"
 const IntroCommand = "intro"
 const ProfileCommand = "profile"
+const DirectoryCommand = "directory"
 
 // Callback data constants for profile handler
 const (
@@ -20,10 +23,43 @@ const (
 
 	ProfileEditBioCallback               = ProfilePrefix + "edit_bio"
 	ProfileEditFirstnameCallback         = ProfilePrefix + "edit_firstname"
+	ProfileEditTimezoneCallback          = ProfilePrefix + "edit_timezone"
 	ProfileEditLastnameCallback          = ProfilePrefix + "edit_lastname"
+	ProfileEditFieldPrefix               = ProfilePrefix + "edit_field_" // followed by the ProfileField
+	ProfileEditPhotoCallback             = ProfilePrefix + "edit_photo"
+	ProfilePhotoFromTelegramCallback     = ProfilePrefix + "photo_from_telegram"
+	ProfilePhotoDeleteCallback           = ProfilePrefix + "photo_delete"
 	ProfilePublishCallback               = ProfilePrefix + "publish"
 	ProfilePublishWithoutPreviewCallback = ProfilePrefix + "publish_without_preview"
 
 	ProfileStartCallback = ProfilePrefix + "start"
 	ProfileFullCancel    = "full_cancel" + ProfilePrefix
 )
+
+// Callback data constants for directory handler
+const (
+	DirectoryPrefix            = "directory_"
+	DirectoryPagePrefix        = DirectoryPrefix + "page_" // followed by the page number, starting from 0
+	DirectoryStatusCallback    = DirectoryPrefix + "status"
+	DirectoryRephraseCallback  = DirectoryPrefix + "rephrase"
+	DirectoryNewSearchCallback = DirectoryPrefix + "new_search"
+	DirectoryCancelCallback    = DirectoryPrefix + "cancel"
+)
+
+// DirectoryPageSize is the number of profiles on a page of the directory
+const DirectoryPageSize = 5
+
+// A page of the directory is one message, so the entries show the short version of the longest fields
+const (
+	DirectoryTextLengthLimit = 60 // the search query, the job and the location
+	DirectorySkillsLimit     = 5  // the rest of the skills are counted
+)
+
+// DirectoryStatus filters the directory by the club membership
+type DirectoryStatus string
+
+const (
+	DirectoryStatusMembers DirectoryStatus = "members"
+	DirectoryStatusAll     DirectoryStatus = "all"
+	DirectoryStatusFormer  DirectoryStatus = "former"
+)
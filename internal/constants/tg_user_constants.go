@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 const (
 	TGUserClientDefaultSessionFile = "session.json"
 	TGUserClientDefaultLimit       = 100
@@ -9,3 +11,30 @@ const (
 	TGUserClientSessionTypeMemory   = "memory"
 	TGUserClientSessionTypeDatabase = "database"
 )
+
+// Connection of the long-running user client
+const (
+	TGUserClientConnectTimeout    = 30 * time.Second // how long a call waits for the client to connect
+	TGUserClientReconnectMinDelay = 5 * time.Second
+	TGUserClientReconnectMaxDelay = 5 * time.Minute
+
+	TGUserClientRateLimitBurst    = 5                      // MTProto calls that can be made at once
+	TGUserClientRateLimitInterval = 200 * time.Millisecond // one more call is allowed every interval
+
+	TGUserClientFloodWaitMaxRetries = 3
+	TGUserClientFloodWaitMaxDelay   = 2 * time.Minute // longer FLOOD_WAITs are returned to the caller
+)
+
+// Login of the user client
+const (
+	TGUserClientLoginCodeTimeout = 5 * time.Minute // how long a sent code or the 2FA password prompt is valid
+	TGUserClientQRLoginTimeout   = 2 * time.Minute // how long QR codes are shown before the login is cancelled
+)
+
+// Forum topic registry
+const (
+	ForumGeneralTopicID          = 1           // the General topic has ID 1 in MTProto and no thread ID in the Bot API
+	ForumGeneralTopicDefaultName = "Оффтопчик" // used until the General topic is synced
+	ForumTopicDefaultName        = "Topic"
+	ForumTopicsSyncInterval      = time.Hour
+)
@@ -3,9 +3,11 @@ package database
 import (
 	"database/sql"
 	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/utils"
 	"fmt"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 )
 
 // DB represents a database connection
@@ -15,10 +17,11 @@ type DB struct {
 
 // NewDB creates a new database connection
 func NewDB(connectionString string) (*DB, error) {
-	db, err := sql.Open("postgres", connectionString)
+	connector, err := pq.NewConnector(connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
+	db := observability.NewInstrumentedDB(connector)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
@@ -28,10 +31,19 @@ func NewDB(connectionString string) (*DB, error) {
 	return &DB{db}, nil
 }
 
+// NewSessionCipher creates the cipher the migrations encrypt the stored user client sessions with,
+// it returns nil if no session key is configured
+func NewSessionCipher(sessionKey string, oldSessionKeys []string) (*utils.SessionCipher, error) {
+	if sessionKey == "" {
+		return nil, nil
+	}
+	return utils.NewSessionCipher(sessionKey, oldSessionKeys)
+}
+
 // InitWithMigrations initializes the database and runs any pending migrations
-func (db *DB) InitWithMigrations() error {
+func (db *DB) InitWithMigrations(sessionCipher *utils.SessionCipher) error {
 	// Run pending migrations
-	if err := migrations.RunMigrations(db.DB); err != nil {
+	if err := migrations.RunMigrations(db.DB, sessionCipher); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
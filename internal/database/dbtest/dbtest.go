@@ -0,0 +1,59 @@
+// Package dbtest connects integration tests to a local PostgreSQL set in TG_EVO_BOT_TEST_DB_CONNECTION
+package dbtest
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ConnectionEnv is the environment variable with the connection string of the test database
+const ConnectionEnv = "TG_EVO_BOT_TEST_DB_CONNECTION"
+
+// OpenSchema opens a connection whose search_path is a new empty schema, dropped when the test ends.
+// The test is skipped if the test database is not configured.
+func OpenSchema(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connection := os.Getenv(ConnectionEnv)
+	if connection == "" {
+		t.Skipf("%s is not set", ConnectionEnv)
+	}
+
+	admin, err := sql.Open("postgres", connection)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	_, err = admin.Exec("CREATE SCHEMA " + pq.QuoteIdentifier(schema))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schema) + " CASCADE")
+		assert.NoError(t, err)
+	})
+
+	db, err := sql.Open("postgres", withSearchPath(connection, schema))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// withSearchPath adds the search_path runtime parameter to a URL or key=value connection string
+func withSearchPath(connection, schema string) string {
+	if strings.HasPrefix(connection, "postgres://") || strings.HasPrefix(connection, "postgresql://") {
+		separator := "?"
+		if strings.Contains(connection, "?") {
+			separator = "&"
+		}
+		return connection + separator + "search_path=" + schema
+	}
+	return connection + " search_path=" + schema
+}
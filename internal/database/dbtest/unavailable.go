@@ -0,0 +1,29 @@
+package dbtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// ErrUnavailable is returned by every query to the database opened by Unavailable
+var ErrUnavailable = errors.New("dbtest: database is not available")
+
+const unavailableDriverName = "dbtest-unavailable"
+
+func init() {
+	sql.Register(unavailableDriverName, unavailableDriver{})
+}
+
+type unavailableDriver struct{}
+
+func (unavailableDriver) Open(string) (driver.Conn, error) {
+	return nil, ErrUnavailable
+}
+
+// Unavailable returns a database every query to which fails with ErrUnavailable. It backs the repositories
+// that a test needs to construct a service, but that have no in-memory implementation.
+func Unavailable() *sql.DB {
+	db, _ := sql.Open(unavailableDriverName, "")
+	return db
+}
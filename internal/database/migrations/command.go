@@ -0,0 +1,115 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"evo-bot-go/internal/utils"
+)
+
+// CommandUsage describes the migrate subcommand
+const CommandUsage = `Usage: evo-bot-go migrate <command>
+
+Commands:
+  status          show applied and pending migrations
+  up [--to NAME]  apply pending migrations, up to and including NAME if set
+  down [N]        roll back the last N applied migrations (default 1)
+  redo            roll back the last applied migration and apply it again`
+
+// RunCommand runs the migrate subcommand with the given arguments and writes the result to out
+func RunCommand(ctx context.Context, db *sql.DB, sessionCipher *utils.SessionCipher, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n\n%s", CommandUsage)
+	}
+
+	migrator := NewMigrator(db, sessionCipher)
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "status":
+		if len(args) > 0 {
+			return fmt.Errorf("status takes no arguments\n\n%s", CommandUsage)
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return writeStatus(out, statuses)
+
+	case "up":
+		flags := flag.NewFlagSet("up", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		target := flags.String("to", "", "name of the last migration to apply")
+		if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+			return fmt.Errorf("invalid up arguments\n\n%s", CommandUsage)
+		}
+		applied, err := migrator.Up(ctx, *target)
+		writeNames(out, "Applied", applied)
+		return err
+
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			return fmt.Errorf("down takes at most one argument\n\n%s", CommandUsage)
+		}
+		if len(args) == 1 {
+			parsed, err := strconv.Atoi(args[0])
+			if err != nil || parsed < 1 {
+				return fmt.Errorf("invalid number of migrations: %s\n\n%s", args[0], CommandUsage)
+			}
+			n = parsed
+		}
+		rolledBack, err := migrator.Down(ctx, n)
+		writeNames(out, "Rolled back", rolledBack)
+		return err
+
+	case "redo":
+		if len(args) > 0 {
+			return fmt.Errorf("redo takes no arguments\n\n%s", CommandUsage)
+		}
+		redone, err := migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Redone: %s\n", redone)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command: %s\n\n%s", command, CommandUsage)
+	}
+}
+
+func writeStatus(out io.Writer, statuses []MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIMESTAMP\tNAME\tAPPLIED AT")
+	pending := 0
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Timestamp, status.Name, appliedAt)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%d migrations, %d pending\n", len(statuses), pending)
+	return nil
+}
+
+func writeNames(out io.Writer, action string, names []string) {
+	if len(names) == 0 {
+		fmt.Fprintf(out, "%s: nothing\n", action)
+		return
+	}
+	for _, name := range names {
+		fmt.Fprintf(out, "%s: %s\n", action, name)
+	}
+}
@@ -90,95 +90,95 @@ func NewInitialMigration() *InitialMigration {
 	}
 }
 
-func (m *InitialMigration) Apply(db *sql.DB) error {
-	if _, err := db.Exec(createSessionsTableSQL); err != nil {
+func (m *InitialMigration) Apply(tx *sql.Tx) error {
+	if _, err := tx.Exec(createSessionsTableSQL); err != nil {
 		return fmt.Errorf("failed to create tg_sessions table: %w", err)
 	}
 
-	if _, err := db.Exec(createPromptingTemplatesTableSQL); err != nil {
+	if _, err := tx.Exec(createPromptingTemplatesTableSQL); err != nil {
 		return fmt.Errorf("failed to create prompting_templates table: %w", err)
 	}
 
-	if err := insertDefaultPrompts(db); err != nil {
+	if err := insertDefaultPrompts(tx); err != nil {
 		return err
 	}
 
-	if _, err := db.Exec(createContentsTableSQL); err != nil {
+	if _, err := tx.Exec(createContentsTableSQL); err != nil {
 		return fmt.Errorf("failed to create contents table: %w", err)
 	}
 
-	if _, err := db.Exec(createTopicsTableSQL); err != nil {
+	if _, err := tx.Exec(createTopicsTableSQL); err != nil {
 		return fmt.Errorf("failed to create topics table: %w", err)
 	}
 
-	if _, err := db.Exec(createUsersTableSQL); err != nil {
+	if _, err := tx.Exec(createUsersTableSQL); err != nil {
 		return fmt.Errorf("failed to create users table: %w", err)
 	}
 
-	if _, err := db.Exec(createProfilesTableSQL); err != nil {
+	if _, err := tx.Exec(createProfilesTableSQL); err != nil {
 		return fmt.Errorf("failed to create profiles table: %w", err)
 	}
 
-	if _, err := db.Exec(createProfilesIndexSQL); err != nil {
+	if _, err := tx.Exec(createProfilesIndexSQL); err != nil {
 		return fmt.Errorf("failed to create profiles index: %w", err)
 	}
 
 	return nil
 }
 
-func (m *InitialMigration) Rollback(db *sql.DB) error {
-	if _, err := db.Exec(`DROP INDEX IF EXISTS profiles_user_id_idx`); err != nil {
+func (m *InitialMigration) Rollback(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP INDEX IF EXISTS profiles_user_id_idx`); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`DROP TABLE IF EXISTS profiles`); err != nil {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS profiles`); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`DROP TABLE IF EXISTS topics`); err != nil {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS topics`); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`DROP TABLE IF EXISTS users`); err != nil {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS users`); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`DROP TABLE IF EXISTS contents`); err != nil {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS contents`); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`DROP TABLE IF EXISTS prompting_templates`); err != nil {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS prompting_templates`); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`DROP TABLE IF EXISTS tg_sessions`); err != nil {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS tg_sessions`); err != nil {
 		return err
 	}
 	return nil
 }
 
-func insertDefaultPrompts(db *sql.DB) error {
-	if err := insertDefaultPromptIfNotExists(db, prompts.GetContentPromptTemplateDbKey, prompts.GetContentPromptDefaultTemplate); err != nil {
+func insertDefaultPrompts(tx *sql.Tx) error {
+	if err := insertDefaultPromptIfNotExists(tx, prompts.GetContentPromptTemplateDbKey, prompts.GetContentPromptDefaultTemplate); err != nil {
 		return fmt.Errorf("failed to insert content prompt: %w", err)
 	}
 
-	if err := insertDefaultPromptIfNotExists(db, prompts.DailySummarizationPromptTemplateDbKey, prompts.DailySummarizationPromptDefaultTemplate); err != nil {
+	if err := insertDefaultPromptIfNotExists(tx, prompts.DailySummarizationPromptTemplateDbKey, prompts.DailySummarizationPromptDefaultTemplate); err != nil {
 		return fmt.Errorf("failed to insert summarization prompt: %w", err)
 	}
 
-	if err := insertDefaultPromptIfNotExists(db, prompts.GetToolPromptTemplateDbKey, prompts.GetToolPromptDefaultTemplate); err != nil {
+	if err := insertDefaultPromptIfNotExists(tx, prompts.GetToolPromptTemplateDbKey, prompts.GetToolPromptDefaultTemplate); err != nil {
 		return fmt.Errorf("failed to insert tool prompt: %w", err)
 	}
 
-	if err := insertDefaultPromptIfNotExists(db, prompts.GetIntroPromptTemplateDbKey, prompts.GetIntroPromptDefaultTemplate); err != nil {
+	if err := insertDefaultPromptIfNotExists(tx, prompts.GetIntroPromptTemplateDbKey, prompts.GetIntroPromptDefaultTemplate); err != nil {
 		return fmt.Errorf("failed to insert intro prompt: %w", err)
 	}
 	return nil
 }
 
-func insertDefaultPromptIfNotExists(db *sql.DB, key, text string) error {
+func insertDefaultPromptIfNotExists(tx *sql.Tx, key, text string) error {
 	var exists bool
-	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM prompting_templates WHERE template_key = $1)", key).Scan(&exists)
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM prompting_templates WHERE template_key = $1)", key).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check if prompt exists: %w", err)
 	}
 
 	if !exists {
-		_, err = db.Exec("INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)", key, text)
+		_, err = tx.Exec("INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)", key, text)
 		if err != nil {
 			return fmt.Errorf("failed to insert prompt: %w", err)
 		}
@@ -21,19 +21,19 @@ func NewChangeUserIdToNullableString() *ChangeUserIdToNullableString {
 }
 
 // Apply executes the migration
-func (m *ChangeUserIdToNullableString) Apply(db *sql.DB) error {
+func (m *ChangeUserIdToNullableString) Apply(tx *sql.Tx) error {
 	sql := `ALTER TABLE topics 
 			ALTER COLUMN user_id TYPE TEXT USING user_id::TEXT,
 			ALTER COLUMN user_id DROP NOT NULL`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
 // Rollback reverts the migration
-func (m *ChangeUserIdToNullableString) Rollback(db *sql.DB) error {
+func (m *ChangeUserIdToNullableString) Rollback(tx *sql.Tx) error {
 	sql := `ALTER TABLE topics 
 			ALTER COLUMN user_id TYPE INTEGER USING user_id::INTEGER,
 			ALTER COLUMN user_id SET NOT NULL`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
@@ -20,15 +20,15 @@ func NewRenameUserIdToUserNickname() *RenameUserIdToUserNickname {
 }
 
 // Apply executes the migration
-func (m *RenameUserIdToUserNickname) Apply(db *sql.DB) error {
+func (m *RenameUserIdToUserNickname) Apply(tx *sql.Tx) error {
 	sql := `ALTER TABLE topics RENAME COLUMN user_id TO user_nickname`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
 // Rollback reverts the migration
-func (m *RenameUserIdToUserNickname) Rollback(db *sql.DB) error {
+func (m *RenameUserIdToUserNickname) Rollback(tx *sql.Tx) error {
 	sql := `ALTER TABLE topics RENAME COLUMN user_nickname TO user_id`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
@@ -22,21 +22,9 @@ func NewRenameContentsToEvents() *RenameContentsToEvents {
 }
 
 // Apply renames the contents table to events and updates the related foreign key
-func (m *RenameContentsToEvents) Apply(db *sql.DB) error {
-	// Start a transaction to ensure all changes are applied atomically
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *RenameContentsToEvents) Apply(tx *sql.Tx) error {
 	// Drop the foreign key constraint first
-	_, err = tx.Exec("ALTER TABLE topics DROP CONSTRAINT IF EXISTS topics_content_id_fkey")
+	_, err := tx.Exec("ALTER TABLE topics DROP CONSTRAINT IF EXISTS topics_content_id_fkey")
 	if err != nil {
 		return fmt.Errorf("failed to drop foreign key constraint: %w", err)
 	}
@@ -59,30 +47,13 @@ func (m *RenameContentsToEvents) Apply(db *sql.DB) error {
 		return fmt.Errorf("failed to add new foreign key constraint: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 // Rollback reverts the changes made by the Apply method
-func (m *RenameContentsToEvents) Rollback(db *sql.DB) error {
-	// Start a transaction to ensure all changes are reverted atomically
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *RenameContentsToEvents) Rollback(tx *sql.Tx) error {
 	// Drop the foreign key constraint first
-	_, err = tx.Exec("ALTER TABLE topics DROP CONSTRAINT IF EXISTS topics_event_id_fkey")
+	_, err := tx.Exec("ALTER TABLE topics DROP CONSTRAINT IF EXISTS topics_event_id_fkey")
 	if err != nil {
 		return fmt.Errorf("failed to drop foreign key constraint: %w", err)
 	}
@@ -105,10 +76,5 @@ func (m *RenameContentsToEvents) Rollback(db *sql.DB) error {
 		return fmt.Errorf("failed to add original foreign key constraint: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback transaction: %w", err)
-	}
-
 	return nil
 }
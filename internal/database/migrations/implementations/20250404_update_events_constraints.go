@@ -22,21 +22,9 @@ func NewUpdateEventsConstraints() *UpdateEventsConstraints {
 }
 
 // Apply drops and renames the constraints
-func (m *UpdateEventsConstraints) Apply(db *sql.DB) error {
-	// Start a transaction to ensure all changes are applied atomically
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *UpdateEventsConstraints) Apply(tx *sql.Tx) error {
 	// Drop the contents_type_check constraint
-	_, err = tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS contents_type_check")
+	_, err := tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS contents_type_check")
 	if err != nil {
 		return fmt.Errorf("failed to drop contents_type_check constraint: %w", err)
 	}
@@ -54,30 +42,13 @@ func (m *UpdateEventsConstraints) Apply(db *sql.DB) error {
 		return fmt.Errorf("failed to add events_status_check constraint: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 // Rollback reverts the changes made by the Apply method
-func (m *UpdateEventsConstraints) Rollback(db *sql.DB) error {
-	// Start a transaction to ensure all changes are reverted atomically
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *UpdateEventsConstraints) Rollback(tx *sql.Tx) error {
 	// Drop the events_status_check constraint
-	_, err = tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check")
+	_, err := tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check")
 	if err != nil {
 		return fmt.Errorf("failed to drop events_status_check constraint: %w", err)
 	}
@@ -97,10 +68,5 @@ func (m *UpdateEventsConstraints) Rollback(db *sql.DB) error {
 		return fmt.Errorf("failed to add contents_type_check constraint: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback transaction: %w", err)
-	}
-
 	return nil
 }
@@ -21,21 +21,9 @@ func NewAddNewEventTypes() *AddNewEventTypes {
 }
 
 // Apply updates the check constraint on the events table to include the new event types
-func (m *AddNewEventTypes) Apply(db *sql.DB) error {
-	// Start a transaction
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *AddNewEventTypes) Apply(tx *sql.Tx) error {
 	// Drop the existing check constraint
-	_, err = tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check")
+	_, err := tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check")
 	if err != nil {
 		return fmt.Errorf("failed to drop type check constraint: %w", err)
 	}
@@ -46,30 +34,13 @@ func (m *AddNewEventTypes) Apply(db *sql.DB) error {
 		return fmt.Errorf("failed to add new type check constraint: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 // Rollback reverts the changes made by the Apply method
-func (m *AddNewEventTypes) Rollback(db *sql.DB) error {
-	// Start a transaction
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *AddNewEventTypes) Rollback(tx *sql.Tx) error {
 	// Drop the updated check constraint
-	_, err = tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check")
+	_, err := tx.Exec("ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check")
 	if err != nil {
 		return fmt.Errorf("failed to drop type check constraint: %w", err)
 	}
@@ -80,10 +51,5 @@ func (m *AddNewEventTypes) Rollback(db *sql.DB) error {
 		return fmt.Errorf("failed to restore original type check constraint: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback transaction: %w", err)
-	}
-
 	return nil
 }
@@ -21,21 +21,9 @@ func NewAddUsersAndProfilesTables() *AddUsersAndProfilesTables {
 }
 
 // Apply creates the users and profiles tables
-func (m *AddUsersAndProfilesTables) Apply(db *sql.DB) error {
-	// Start a transaction
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *AddUsersAndProfilesTables) Apply(tx *sql.Tx) error {
 	// Create users table
-	_, err = tx.Exec(`
+	_, err := tx.Exec(`
 		CREATE TABLE IF NOT EXISTS users (
 			id SERIAL PRIMARY KEY,
 			tg_id BIGINT NOT NULL UNIQUE,
@@ -77,30 +65,13 @@ func (m *AddUsersAndProfilesTables) Apply(db *sql.DB) error {
 		return fmt.Errorf("failed to create index on profiles.user_id: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 // Rollback drops the profiles and users tables
-func (m *AddUsersAndProfilesTables) Rollback(db *sql.DB) error {
-	// Start a transaction
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *AddUsersAndProfilesTables) Rollback(tx *sql.Tx) error {
 	// Drop the profiles table first (because it references users)
-	_, err = tx.Exec("DROP TABLE IF EXISTS profiles")
+	_, err := tx.Exec("DROP TABLE IF EXISTS profiles")
 	if err != nil {
 		return fmt.Errorf("failed to drop profiles table: %w", err)
 	}
@@ -111,10 +82,5 @@ func (m *AddUsersAndProfilesTables) Rollback(db *sql.DB) error {
 		return fmt.Errorf("failed to drop users table: %w", err)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback transaction: %w", err)
-	}
-
 	return nil
 }
\ No newline at end of file
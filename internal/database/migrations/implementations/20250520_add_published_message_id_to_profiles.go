@@ -21,21 +21,9 @@ func NewAddPublishedMessageIDToProfiles() *AddPublishedMessageIDToProfiles {
 }
 
 // Apply creates the published_message_id column in the profiles table
-func (m *AddPublishedMessageIDToProfiles) Apply(db *sql.DB) error {
-	// Start a transaction
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *AddPublishedMessageIDToProfiles) Apply(tx *sql.Tx) error {
 	// Add the published_message_id column to the profiles table
-	_, err = tx.Exec(`
+	_, err := tx.Exec(`
 		ALTER TABLE profiles
 		ADD COLUMN published_message_id BIGINT DEFAULT NULL
 	`)
@@ -43,30 +31,13 @@ func (m *AddPublishedMessageIDToProfiles) Apply(db *sql.DB) error {
 		return fmt.Errorf("failed to add published_message_id column to profiles table: %w", err)
 	}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 // Rollback removes the published_message_id column from the profiles table
-func (m *AddPublishedMessageIDToProfiles) Rollback(db *sql.DB) error {
-	// Start a transaction
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
+func (m *AddPublishedMessageIDToProfiles) Rollback(tx *sql.Tx) error {
 	// Remove the published_message_id column from the profiles table
-	_, err = tx.Exec(`
+	_, err := tx.Exec(`
 		ALTER TABLE profiles
 		DROP COLUMN IF EXISTS published_message_id
 	`)
@@ -74,10 +45,5 @@ func (m *AddPublishedMessageIDToProfiles) Rollback(db *sql.DB) error {
 		return fmt.Errorf("failed to drop published_message_id column from profiles table: %w", err)
 	}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
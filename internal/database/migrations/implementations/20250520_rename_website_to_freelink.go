@@ -17,14 +17,14 @@ func NewRenameWebsiteToFreelink() *RenameWebsiteToFreelink {
 	}
 }
 
-func (m *RenameWebsiteToFreelink) Apply(db *sql.DB) error {
+func (m *RenameWebsiteToFreelink) Apply(tx *sql.Tx) error {
 	sql := `ALTER TABLE profiles RENAME COLUMN website TO freelink`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
-func (m *RenameWebsiteToFreelink) Rollback(db *sql.DB) error {
+func (m *RenameWebsiteToFreelink) Rollback(tx *sql.Tx) error {
 	sql := `ALTER TABLE profiles RENAME COLUMN freelink TO website`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
@@ -17,20 +17,20 @@ func NewRemoveSocialLinksFromProfiles() *RemoveSocialLinksFromProfiles {
 	}
 }
 
-func (m *RemoveSocialLinksFromProfiles) Apply(db *sql.DB) error {
+func (m *RemoveSocialLinksFromProfiles) Apply(tx *sql.Tx) error {
 	sql := `ALTER TABLE profiles 
 			DROP COLUMN IF EXISTS linkedin,
 			DROP COLUMN IF EXISTS github,
 			DROP COLUMN IF EXISTS freelink`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
-func (m *RemoveSocialLinksFromProfiles) Rollback(db *sql.DB) error {
+func (m *RemoveSocialLinksFromProfiles) Rollback(tx *sql.Tx) error {
 	sql := `ALTER TABLE profiles 
 			ADD COLUMN IF NOT EXISTS linkedin TEXT DEFAULT '',
 			ADD COLUMN IF NOT EXISTS github TEXT DEFAULT '',
 			ADD COLUMN IF NOT EXISTS freelink TEXT DEFAULT ''`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
@@ -17,7 +17,7 @@ func NewAddRandomCoffeePollTables() *AddRandomCoffeePollTables {
 	}
 }
 
-func (m *AddRandomCoffeePollTables) Apply(db *sql.DB) error {
+func (m *AddRandomCoffeePollTables) Apply(tx *sql.Tx) error {
 	// Create random_coffee_polls table
 	sql1 := `CREATE TABLE IF NOT EXISTS random_coffee_polls (
 		id SERIAL PRIMARY KEY,
@@ -27,7 +27,7 @@ func (m *AddRandomCoffeePollTables) Apply(db *sql.DB) error {
 		created_at TIMESTAMPTZ DEFAULT NOW()
 	);`
 
-	if _, err := db.Exec(sql1); err != nil {
+	if _, err := tx.Exec(sql1); err != nil {
 		return err
 	}
 
@@ -42,7 +42,7 @@ func (m *AddRandomCoffeePollTables) Apply(db *sql.DB) error {
 		UNIQUE (poll_id, user_id)
 	);`
 
-	if _, err := db.Exec(sql2); err != nil {
+	if _, err := tx.Exec(sql2); err != nil {
 		return err
 	}
 
@@ -60,7 +60,7 @@ func (m *AddRandomCoffeePollTables) Apply(db *sql.DB) error {
 		FOR EACH ROW
 		EXECUTE FUNCTION update_updated_at_column();`
 
-	if _, err := db.Exec(sql3); err != nil {
+	if _, err := tx.Exec(sql3); err != nil {
 		return err
 	}
 
@@ -73,19 +73,19 @@ func (m *AddRandomCoffeePollTables) Apply(db *sql.DB) error {
 
 	COMMENT ON COLUMN random_coffee_polls.telegram_poll_id IS 'Unique poll ID string from Telegram (Poll.Id).';`
 
-	if _, err := db.Exec(sql4); err != nil {
+	if _, err := tx.Exec(sql4); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (m *AddRandomCoffeePollTables) Rollback(db *sql.DB) error {
+func (m *AddRandomCoffeePollTables) Rollback(tx *sql.Tx) error {
 	// Remove telegram_poll_id column and constraint
 	sql1 := `ALTER TABLE random_coffee_polls DROP CONSTRAINT IF EXISTS random_coffee_polls_telegram_poll_id_unique;
 	ALTER TABLE random_coffee_polls DROP COLUMN IF EXISTS telegram_poll_id;`
 
-	if _, err := db.Exec(sql1); err != nil {
+	if _, err := tx.Exec(sql1); err != nil {
 		return err
 	}
 
@@ -93,7 +93,7 @@ func (m *AddRandomCoffeePollTables) Rollback(db *sql.DB) error {
 	sql2 := `DROP TRIGGER IF EXISTS update_random_coffee_participants_updated_at ON random_coffee_participants;
 	DROP FUNCTION IF EXISTS update_updated_at_column();`
 
-	if _, err := db.Exec(sql2); err != nil {
+	if _, err := tx.Exec(sql2); err != nil {
 		return err
 	}
 
@@ -101,7 +101,7 @@ func (m *AddRandomCoffeePollTables) Rollback(db *sql.DB) error {
 	sql3 := `DROP TABLE IF EXISTS random_coffee_participants;
 	DROP TABLE IF EXISTS random_coffee_polls;`
 
-	if _, err := db.Exec(sql3); err != nil {
+	if _, err := tx.Exec(sql3); err != nil {
 		return err
 	}
 
@@ -17,14 +17,14 @@ func NewRemoveChatIdFromRandomCoffeePolls() *RemoveChatIdFromRandomCoffeePolls {
 	}
 }
 
-func (m *RemoveChatIdFromRandomCoffeePolls) Apply(db *sql.DB) error {
+func (m *RemoveChatIdFromRandomCoffeePolls) Apply(tx *sql.Tx) error {
 	sql := `ALTER TABLE random_coffee_polls DROP COLUMN IF EXISTS chat_id`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
-func (m *RemoveChatIdFromRandomCoffeePolls) Rollback(db *sql.DB) error {
+func (m *RemoveChatIdFromRandomCoffeePolls) Rollback(tx *sql.Tx) error {
 	sql := `ALTER TABLE random_coffee_polls ADD COLUMN IF NOT EXISTS chat_id BIGINT NOT NULL DEFAULT 0`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
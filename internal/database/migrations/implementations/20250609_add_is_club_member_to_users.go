@@ -17,14 +17,14 @@ func NewAddIsClubMemberToUsers() *AddIsClubMemberToUsers {
 	}
 }
 
-func (m *AddIsClubMemberToUsers) Apply(db *sql.DB) error {
+func (m *AddIsClubMemberToUsers) Apply(tx *sql.Tx) error {
 	sql := `ALTER TABLE users ADD COLUMN is_club_member BOOLEAN DEFAULT TRUE`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
-func (m *AddIsClubMemberToUsers) Rollback(db *sql.DB) error {
+func (m *AddIsClubMemberToUsers) Rollback(tx *sql.Tx) error {
 	sql := `ALTER TABLE users DROP COLUMN IF EXISTS is_club_member`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
@@ -17,7 +17,7 @@ func NewAddRandomCoffeePairsTable() *AddRandomCoffeePairsTable {
 	}
 }
 
-func (m *AddRandomCoffeePairsTable) Apply(db *sql.DB) error {
+func (m *AddRandomCoffeePairsTable) Apply(tx *sql.Tx) error {
 	sql := `
 	CREATE TABLE IF NOT EXISTS random_coffee_pairs (
 		id SERIAL PRIMARY KEY,
@@ -28,12 +28,12 @@ func (m *AddRandomCoffeePairsTable) Apply(db *sql.DB) error {
 		UNIQUE(poll_id, user1_id, user2_id)
 	);
 	`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
 
-func (m *AddRandomCoffeePairsTable) Rollback(db *sql.DB) error {
+func (m *AddRandomCoffeePairsTable) Rollback(tx *sql.Tx) error {
 	sql := `DROP TABLE IF EXISTS random_coffee_pairs;`
-	_, err := db.Exec(sql)
+	_, err := tx.Exec(sql)
 	return err
 }
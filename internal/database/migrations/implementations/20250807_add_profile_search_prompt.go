@@ -20,8 +20,8 @@ func NewAddProfileSearchPromptMigration() *AddProfileSearchPromptMigration {
 	}
 }
 
-func (m *AddProfileSearchPromptMigration) Apply(db *sql.DB) error {
-	if err := m.insertPromptIfNotExists(db, prompts.GetProfilePromptTemplateDbKey, prompts.GetProfilePromptDefaultTemplate); err != nil {
+func (m *AddProfileSearchPromptMigration) Apply(tx *sql.Tx) error {
+	if err := m.insertPromptIfNotExists(tx, prompts.GetProfilePromptTemplateDbKey, prompts.GetProfilePromptDefaultTemplate); err != nil {
 		return fmt.Errorf("failed to insert profile search prompt: %w", err)
 	}
 
@@ -29,8 +29,8 @@ func (m *AddProfileSearchPromptMigration) Apply(db *sql.DB) error {
 	return nil
 }
 
-func (m *AddProfileSearchPromptMigration) Rollback(db *sql.DB) error {
-	_, err := db.Exec("DELETE FROM prompting_templates WHERE template_key = $1", prompts.GetProfilePromptTemplateDbKey)
+func (m *AddProfileSearchPromptMigration) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM prompting_templates WHERE template_key = $1", prompts.GetProfilePromptTemplateDbKey)
 	if err != nil {
 		return fmt.Errorf("failed to remove profile search prompt: %w", err)
 	}
@@ -39,15 +39,15 @@ func (m *AddProfileSearchPromptMigration) Rollback(db *sql.DB) error {
 	return nil
 }
 
-func (m *AddProfileSearchPromptMigration) insertPromptIfNotExists(db *sql.DB, key, text string) error {
+func (m *AddProfileSearchPromptMigration) insertPromptIfNotExists(tx *sql.Tx, key, text string) error {
 	var exists bool
-	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM prompting_templates WHERE template_key = $1)", key).Scan(&exists)
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM prompting_templates WHERE template_key = $1)", key).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check if prompt exists: %w", err)
 	}
 
 	if !exists {
-		_, err = db.Exec("INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)", key, text)
+		_, err = tx.Exec("INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)", key, text)
 		if err != nil {
 			return fmt.Errorf("failed to insert prompt: %w", err)
 		}
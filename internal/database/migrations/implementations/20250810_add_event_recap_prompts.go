@@ -0,0 +1,68 @@
+package implementations
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/database/prompts"
+	"fmt"
+	"log"
+)
+
+type AddEventRecapPromptsMigration struct {
+	BaseMigration
+}
+
+func NewAddEventRecapPromptsMigration() *AddEventRecapPromptsMigration {
+	return &AddEventRecapPromptsMigration{
+		BaseMigration: BaseMigration{
+			name:      "add_event_recap_prompts",
+			timestamp: "20250810",
+		},
+	}
+}
+
+func (m *AddEventRecapPromptsMigration) Apply(tx *sql.Tx) error {
+	if err := m.insertPromptIfNotExists(tx, prompts.EventRecapChunkPromptTemplateDbKey, prompts.EventRecapChunkPromptDefaultTemplate); err != nil {
+		return fmt.Errorf("failed to insert event recap chunk prompt: %w", err)
+	}
+
+	if err := m.insertPromptIfNotExists(tx, prompts.EventRecapPromptTemplateDbKey, prompts.EventRecapPromptDefaultTemplate); err != nil {
+		return fmt.Errorf("failed to insert event recap prompt: %w", err)
+	}
+
+	log.Printf("Migration %s applied successfully", m.name)
+	return nil
+}
+
+func (m *AddEventRecapPromptsMigration) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"DELETE FROM prompting_templates WHERE template_key IN ($1, $2)",
+		prompts.EventRecapChunkPromptTemplateDbKey,
+		prompts.EventRecapPromptTemplateDbKey,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove event recap prompts: %w", err)
+	}
+
+	log.Printf("Migration %s rolled back successfully", m.name)
+	return nil
+}
+
+func (m *AddEventRecapPromptsMigration) insertPromptIfNotExists(tx *sql.Tx, key, text string) error {
+	var exists bool
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM prompting_templates WHERE template_key = $1)", key).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if prompt exists: %w", err)
+	}
+
+	if !exists {
+		_, err = tx.Exec("INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)", key, text)
+		if err != nil {
+			return fmt.Errorf("failed to insert prompt: %w", err)
+		}
+		log.Printf("Inserted event recap prompt: %s", key)
+	} else {
+		log.Printf("Event recap prompt already exists: %s", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package implementations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// AddEventRecapsTable migration adds event_recaps table for storing AI-generated event recaps
+type AddEventRecapsTable struct {
+	BaseMigration
+}
+
+// NewAddEventRecapsTable creates a new migration instance
+func NewAddEventRecapsTable() *AddEventRecapsTable {
+	return &AddEventRecapsTable{
+		BaseMigration: BaseMigration{
+			name:      "add_event_recaps_table",
+			timestamp: "20250810",
+		},
+	}
+}
+
+// Apply creates the event_recaps table
+func (m *AddEventRecapsTable) Apply(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS event_recaps (
+			id SERIAL PRIMARY KEY,
+			event_id INTEGER NOT NULL UNIQUE REFERENCES events(id) ON DELETE CASCADE,
+			recap TEXT NOT NULL,
+			published_message_id BIGINT DEFAULT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create event_recaps table: %w", err)
+	}
+
+	return nil
+}
+
+// Rollback drops the event_recaps table
+func (m *AddEventRecapsTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS event_recaps`)
+	if err != nil {
+		return fmt.Errorf("failed to drop event_recaps table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddTimezones struct {
+	BaseMigration
+}
+
+func NewAddTimezones() *AddTimezones {
+	return &AddTimezones{
+		BaseMigration: BaseMigration{
+			name:      "add_timezones",
+			timestamp: "20250811",
+		},
+	}
+}
+
+func (m *AddTimezones) Apply(tx *sql.Tx) error {
+	// Events keep the IANA zone they were scheduled in, existing rows were entered in UTC
+	if _, err := tx.Exec(`ALTER TABLE events ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC'`); err != nil {
+		return err
+	}
+
+	// Empty user timezone means the club timezone is used
+	if _, err := tx.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT ''`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddTimezones) Rollback(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE users DROP COLUMN IF EXISTS timezone`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE events DROP COLUMN IF EXISTS timezone`); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddEventCapacityAndRegistrations struct {
+	BaseMigration
+}
+
+func NewAddEventCapacityAndRegistrations() *AddEventCapacityAndRegistrations {
+	return &AddEventCapacityAndRegistrations{
+		BaseMigration: BaseMigration{
+			name:      "add_event_capacity_and_registrations",
+			timestamp: "20250812",
+		},
+	}
+}
+
+func (m *AddEventCapacityAndRegistrations) Apply(tx *sql.Tx) error {
+	// NULL capacity means the event has no limit of places
+	if _, err := tx.Exec(`ALTER TABLE events ADD COLUMN IF NOT EXISTS capacity INTEGER CHECK (capacity > 0)`); err != nil {
+		return err
+	}
+
+	createRegistrationsTable := `
+		CREATE TABLE IF NOT EXISTS event_registrations (
+			id SERIAL PRIMARY KEY,
+			event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			status TEXT NOT NULL CHECK (status IN ('going', 'waitlist')),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (event_id, user_id)
+		)
+	`
+	if _, err := tx.Exec(createRegistrationsTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_event_registrations_event_status ON event_registrations(event_id, status, created_at)`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddEventCapacityAndRegistrations) Rollback(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS event_registrations`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE events DROP COLUMN IF EXISTS capacity`); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddScoreLedgerTable struct {
+	BaseMigration
+}
+
+func NewAddScoreLedgerTable() *AddScoreLedgerTable {
+	return &AddScoreLedgerTable{
+		BaseMigration: BaseMigration{
+			name:      "add_score_ledger_table",
+			timestamp: "20250813",
+		},
+	}
+}
+
+func (m *AddScoreLedgerTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS score_ledger (
+			id SERIAL PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			points INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			reference TEXT NOT NULL DEFAULT '',
+			comment TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	// The same activity (e.g. a topic or an event) is rewarded only once per user
+	if _, err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_score_ledger_unique_reference ON score_ledger(user_id, reason, reference) WHERE reference <> ''`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_score_ledger_user_created ON score_ledger(user_id, created_at DESC)`); err != nil {
+		return err
+	}
+
+	// Existing scores become the opening balance of the ledger
+	openingBalance := `
+		INSERT INTO score_ledger (user_id, points, reason, reference, comment)
+		SELECT id, score, 'admin_adjustment', 'opening_balance', 'Начальный баланс'
+		FROM users
+		WHERE score <> 0
+	`
+	if _, err := tx.Exec(openingBalance); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddScoreLedgerTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS score_ledger`)
+	return err
+}
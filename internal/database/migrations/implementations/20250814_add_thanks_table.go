@@ -0,0 +1,53 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddThanksTable struct {
+	BaseMigration
+}
+
+func NewAddThanksTable() *AddThanksTable {
+	return &AddThanksTable{
+		BaseMigration: BaseMigration{
+			name:      "add_thanks_table",
+			timestamp: "20250814",
+		},
+	}
+}
+
+func (m *AddThanksTable) Apply(tx *sql.Tx) error {
+	// A member can thank for the same message only once, whether by reply or by reaction
+	createTable := `
+		CREATE TABLE IF NOT EXISTS thanks (
+			id SERIAL PRIMARY KEY,
+			giver_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			receiver_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			chat_id BIGINT NOT NULL,
+			message_id BIGINT NOT NULL,
+			source TEXT NOT NULL CHECK (source IN ('reply', 'reaction')),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (giver_user_id, chat_id, message_id),
+			CHECK (giver_user_id <> receiver_user_id)
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_thanks_giver_created ON thanks(giver_user_id, created_at DESC)`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_thanks_receiver ON thanks(receiver_user_id)`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddThanksTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS thanks`)
+	return err
+}
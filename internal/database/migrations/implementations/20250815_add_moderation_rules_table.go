@@ -0,0 +1,45 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddModerationRulesTable struct {
+	BaseMigration
+}
+
+func NewAddModerationRulesTable() *AddModerationRulesTable {
+	return &AddModerationRulesTable{
+		BaseMigration: BaseMigration{
+			name:      "add_moderation_rules_table",
+			timestamp: "20250815",
+		},
+	}
+}
+
+func (m *AddModerationRulesTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS moderation_rules (
+			id SERIAL PRIMARY KEY,
+			topic_id INTEGER NOT NULL UNIQUE,
+			allowed_posters TEXT NOT NULL DEFAULT 'everyone' CHECK (allowed_posters IN ('admins', 'members', 'everyone')),
+			min_score INTEGER NOT NULL DEFAULT 0,
+			allowed_user_ids TEXT NOT NULL DEFAULT '',
+			allow_links BOOLEAN NOT NULL DEFAULT TRUE,
+			allow_media BOOLEAN NOT NULL DEFAULT TRUE,
+			allow_forwards BOOLEAN NOT NULL DEFAULT TRUE,
+			action TEXT NOT NULL DEFAULT 'delete' CHECK (action IN ('delete', 'warn', 'mute', 'forward')),
+			mute_minutes INTEGER NOT NULL DEFAULT 60 CHECK (mute_minutes > 0),
+			dm_template TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	_, err := tx.Exec(createTable)
+	return err
+}
+
+func (m *AddModerationRulesTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS moderation_rules`)
+	return err
+}
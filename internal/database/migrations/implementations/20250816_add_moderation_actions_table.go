@@ -0,0 +1,50 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddModerationActionsTable struct {
+	BaseMigration
+}
+
+func NewAddModerationActionsTable() *AddModerationActionsTable {
+	return &AddModerationActionsTable{
+		BaseMigration: BaseMigration{
+			name:      "add_moderation_actions_table",
+			timestamp: "20250816",
+		},
+	}
+}
+
+func (m *AddModerationActionsTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS moderation_actions (
+			id SERIAL PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			admin_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+			action TEXT NOT NULL CHECK (action IN ('warn', 'mute', 'unmute', 'ban', 'unban')),
+			reason TEXT NOT NULL DEFAULT '',
+			until_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	createIndexes := `
+		CREATE INDEX IF NOT EXISTS idx_moderation_actions_user_id ON moderation_actions(user_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_moderation_actions_created_at ON moderation_actions(created_at DESC);
+	`
+	if _, err := tx.Exec(createIndexes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddModerationActionsTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS moderation_actions`)
+	return err
+}
@@ -0,0 +1,53 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddAuditLogTable struct {
+	BaseMigration
+}
+
+func NewAddAuditLogTable() *AddAuditLogTable {
+	return &AddAuditLogTable{
+		BaseMigration: BaseMigration{
+			name:      "add_audit_log_table",
+			timestamp: "20250817",
+		},
+	}
+}
+
+func (m *AddAuditLogTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id SERIAL PRIMARY KEY,
+			actor_tg_id BIGINT NOT NULL,
+			actor_name TEXT NOT NULL DEFAULT '',
+			action TEXT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id TEXT NOT NULL DEFAULT '',
+			before_value JSONB,
+			after_value JSONB,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	createIndexes := `
+		CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_tg_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+	`
+	if _, err := tx.Exec(createIndexes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddAuditLogTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS audit_log`)
+	return err
+}
@@ -0,0 +1,46 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddUserRolesTable struct {
+	BaseMigration
+}
+
+func NewAddUserRolesTable() *AddUserRolesTable {
+	return &AddUserRolesTable{
+		BaseMigration: BaseMigration{
+			name:      "add_user_roles_table",
+			timestamp: "20250818",
+		},
+	}
+}
+
+func (m *AddUserRolesTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS user_roles (
+			id SERIAL PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'event_organizer', 'moderator', 'coffee_manager', 'member')),
+			granted_by_tg_id BIGINT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (user_id, role)
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	createIndex := `CREATE INDEX IF NOT EXISTS idx_user_roles_user_id ON user_roles(user_id)`
+	if _, err := tx.Exec(createIndex); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddUserRolesTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS user_roles`)
+	return err
+}
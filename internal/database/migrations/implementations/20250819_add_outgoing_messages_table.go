@@ -0,0 +1,71 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddOutgoingMessagesTable struct {
+	BaseMigration
+}
+
+func NewAddOutgoingMessagesTable() *AddOutgoingMessagesTable {
+	return &AddOutgoingMessagesTable{
+		BaseMigration: BaseMigration{
+			name:      "add_outgoing_messages_table",
+			timestamp: "20250819",
+		},
+	}
+}
+
+func (m *AddOutgoingMessagesTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS outgoing_messages (
+			id SERIAL PRIMARY KEY,
+			chat_id BIGINT NOT NULL,
+			message_thread_id BIGINT NOT NULL DEFAULT 0,
+			text TEXT NOT NULL,
+			parse_mode TEXT NOT NULL DEFAULT '',
+			priority INTEGER NOT NULL DEFAULT 1,
+			batch TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed', 'blocked')),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			send_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			sent_message_id BIGINT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	createIndexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_outgoing_messages_pending ON outgoing_messages(priority, id) WHERE status = 'pending'`,
+		`CREATE INDEX IF NOT EXISTS idx_outgoing_messages_batch ON outgoing_messages(batch) WHERE batch != ''`,
+	}
+	for _, createIndex := range createIndexes {
+		if _, err := tx.Exec(createIndex); err != nil {
+			return err
+		}
+	}
+
+	addBlockedColumn := `ALTER TABLE users ADD COLUMN IF NOT EXISTS bot_blocked_at TIMESTAMP WITH TIME ZONE`
+	if _, err := tx.Exec(addBlockedColumn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddOutgoingMessagesTable) Rollback(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS outgoing_messages`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE users DROP COLUMN IF EXISTS bot_blocked_at`); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddBroadcastsTable struct {
+	BaseMigration
+}
+
+func NewAddBroadcastsTable() *AddBroadcastsTable {
+	return &AddBroadcastsTable{
+		BaseMigration: BaseMigration{
+			name:      "add_broadcasts_table",
+			timestamp: "20250820",
+		},
+	}
+}
+
+func (m *AddBroadcastsTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS broadcasts (
+			id SERIAL PRIMARY KEY,
+			created_by_tg_id BIGINT NOT NULL,
+			audience TEXT NOT NULL CHECK (audience IN ('all_members', 'coffee_participants', 'without_profile', 'event_registrations')),
+			audience_param INTEGER NOT NULL DEFAULT 0,
+			from_chat_id BIGINT NOT NULL,
+			from_message_id BIGINT NOT NULL,
+			recipients_count INTEGER NOT NULL DEFAULT 0,
+			scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			reported_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	// Queued messages can be copies of a message instead of a text
+	alterOutgoingMessages := []string{
+		`ALTER TABLE outgoing_messages ADD COLUMN IF NOT EXISTS copy_from_chat_id BIGINT`,
+		`ALTER TABLE outgoing_messages ADD COLUMN IF NOT EXISTS copy_from_message_id BIGINT`,
+		`ALTER TABLE outgoing_messages ALTER COLUMN text SET DEFAULT ''`,
+	}
+	for _, alter := range alterOutgoingMessages {
+		if _, err := tx.Exec(alter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AddBroadcastsTable) Rollback(tx *sql.Tx) error {
+	rollbacks := []string{
+		`DROP TABLE IF EXISTS broadcasts`,
+		`ALTER TABLE outgoing_messages DROP COLUMN IF EXISTS copy_from_chat_id`,
+		`ALTER TABLE outgoing_messages DROP COLUMN IF EXISTS copy_from_message_id`,
+		`ALTER TABLE outgoing_messages ALTER COLUMN text DROP DEFAULT`,
+	}
+	for _, rollback := range rollbacks {
+		if _, err := tx.Exec(rollback); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddLLMUsageTable struct {
+	BaseMigration
+}
+
+func NewAddLLMUsageTable() *AddLLMUsageTable {
+	return &AddLLMUsageTable{
+		BaseMigration: BaseMigration{
+			name:      "add_llm_usage_table",
+			timestamp: "20250821",
+		},
+	}
+}
+
+func (m *AddLLMUsageTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS llm_usage (
+			id SERIAL PRIMARY KEY,
+			user_tg_id BIGINT,
+			feature TEXT NOT NULL,
+			model TEXT NOT NULL,
+			prompt_tokens INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			latency_ms INTEGER NOT NULL DEFAULT 0,
+			cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+			success BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_llm_usage_created_at ON llm_usage (created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_llm_usage_user_tg_id_created_at ON llm_usage (user_tg_id, created_at)`,
+	}
+	for _, index := range indexes {
+		if _, err := tx.Exec(index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AddLLMUsageTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS llm_usage`)
+	return err
+}
@@ -0,0 +1,44 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddForumTopicsTable struct {
+	BaseMigration
+}
+
+func NewAddForumTopicsTable() *AddForumTopicsTable {
+	return &AddForumTopicsTable{
+		BaseMigration: BaseMigration{
+			name:      "add_forum_topics_table",
+			timestamp: "20250822",
+		},
+	}
+}
+
+func (m *AddForumTopicsTable) Apply(tx *sql.Tx) error {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS forum_topics (
+			topic_id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL,
+			icon_color INTEGER NOT NULL DEFAULT 0,
+			icon_emoji_id TEXT NOT NULL DEFAULT '',
+			is_closed BOOLEAN NOT NULL DEFAULT FALSE,
+			is_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+			topic_created_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddForumTopicsTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS forum_topics`)
+	return err
+}
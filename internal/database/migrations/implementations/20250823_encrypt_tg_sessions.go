@@ -0,0 +1,102 @@
+package implementations
+
+import (
+	"database/sql"
+	"fmt"
+
+	"evo-bot-go/internal/utils"
+)
+
+type EncryptTgSessions struct {
+	BaseMigration
+	cipher *utils.SessionCipher
+}
+
+// NewEncryptTgSessions creates the migration with the cipher of the configured session keys,
+// the cipher is nil if no key is configured
+func NewEncryptTgSessions(cipher *utils.SessionCipher) *EncryptTgSessions {
+	return &EncryptTgSessions{
+		BaseMigration: BaseMigration{
+			name:      "encrypt_tg_sessions",
+			timestamp: "20250823",
+		},
+		cipher: cipher,
+	}
+}
+
+// Apply encrypts the stored plaintext sessions and re-encrypts the sessions stored with an old key
+func (m *EncryptTgSessions) Apply(tx *sql.Tx) error {
+	return m.transformSessions(tx, func(cipher *utils.SessionCipher, data []byte) ([]byte, error) {
+		if !utils.IsEncryptedSession(data) {
+			return cipher.Encrypt(data)
+		}
+		if cipher.IsCurrentKey(data) {
+			return nil, nil
+		}
+		plaintext, err := cipher.Decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.Encrypt(plaintext)
+	})
+}
+
+// Rollback decrypts the sessions back to plaintext
+func (m *EncryptTgSessions) Rollback(tx *sql.Tx) error {
+	return m.transformSessions(tx, func(cipher *utils.SessionCipher, data []byte) ([]byte, error) {
+		if !utils.IsEncryptedSession(data) {
+			return nil, nil
+		}
+		return cipher.Decrypt(data)
+	})
+}
+
+// transformSessions updates every non-empty session with the result of transform, nil means no change
+func (m *EncryptTgSessions) transformSessions(
+	tx *sql.Tx,
+	transform func(cipher *utils.SessionCipher, data []byte) ([]byte, error),
+) error {
+	rows, err := tx.Query(`SELECT id, data FROM tg_sessions WHERE data IS NOT NULL AND LENGTH(data) > 0 FOR UPDATE`)
+	if err != nil {
+		return fmt.Errorf("failed to select tg_sessions: %w", err)
+	}
+
+	sessions := make(map[string][]byte)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan tg_sessions row: %w", err)
+		}
+		sessions[id] = data
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over tg_sessions: %w", err)
+	}
+
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	if m.cipher == nil {
+		return fmt.Errorf("tg_sessions has %d stored session(s), set TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY "+
+			"or TG_EVO_BOT_TGUSERCLIENT_SESSION_KEY_FILE to encrypt them, or delete them to log in again", len(sessions))
+	}
+
+	for id, data := range sessions {
+		transformed, err := transform(m.cipher, data)
+		if err != nil {
+			return fmt.Errorf("failed to transform session %s: %w", id, err)
+		}
+		if transformed == nil {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE tg_sessions SET data = $1, updated_at = NOW() WHERE id = $2`, transformed, id); err != nil {
+			return fmt.Errorf("failed to update session %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddProfileFields struct {
+	BaseMigration
+}
+
+func NewAddProfileFields() *AddProfileFields {
+	return &AddProfileFields{
+		BaseMigration: BaseMigration{
+			name:      "add_profile_fields",
+			timestamp: "20250824",
+		},
+	}
+}
+
+func (m *AddProfileFields) Apply(tx *sql.Tx) error {
+	sql := `ALTER TABLE profiles
+			ADD COLUMN IF NOT EXISTS city TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS job_title TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS company TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS skills TEXT[] NOT NULL DEFAULT '{}',
+			ADD COLUMN IF NOT EXISTS can_help_with TEXT[] NOT NULL DEFAULT '{}',
+			ADD COLUMN IF NOT EXISTS looking_for TEXT[] NOT NULL DEFAULT '{}',
+			ADD COLUMN IF NOT EXISTS languages TEXT[] NOT NULL DEFAULT '{}',
+			ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '{}'`
+	_, err := tx.Exec(sql)
+	return err
+}
+
+func (m *AddProfileFields) Rollback(tx *sql.Tx) error {
+	sql := `ALTER TABLE profiles
+			DROP COLUMN IF EXISTS city,
+			DROP COLUMN IF EXISTS country,
+			DROP COLUMN IF EXISTS job_title,
+			DROP COLUMN IF EXISTS company,
+			DROP COLUMN IF EXISTS skills,
+			DROP COLUMN IF EXISTS can_help_with,
+			DROP COLUMN IF EXISTS looking_for,
+			DROP COLUMN IF EXISTS languages,
+			DROP COLUMN IF EXISTS links`
+	_, err := tx.Exec(sql)
+	return err
+}
@@ -0,0 +1,74 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddProfileSearch struct {
+	BaseMigration
+}
+
+func NewAddProfileSearch() *AddProfileSearch {
+	return &AddProfileSearch{
+		BaseMigration: BaseMigration{
+			name:      "add_profile_search",
+			timestamp: "20250825",
+		},
+	}
+}
+
+func (m *AddProfileSearch) Apply(tx *sql.Tx) error {
+	// Fuzzy matching of the names
+	if _, err := tx.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE profiles ADD COLUMN IF NOT EXISTS search_vector TSVECTOR`); err != nil {
+		return err
+	}
+
+	// The vector is built with the Russian and the English configs, so both languages are stemmed.
+	// The role, the company and the skills weigh the most, then the other fields, then the bio.
+	createTrigger := `CREATE OR REPLACE FUNCTION update_profiles_search_vector()
+	RETURNS TRIGGER AS $$
+	DECLARE
+		headline TEXT := concat_ws(' ', NEW.job_title, NEW.company, array_to_string(NEW.skills, ' '));
+		details TEXT := concat_ws(' ', NEW.city, NEW.country, array_to_string(NEW.can_help_with, ' '),
+			array_to_string(NEW.looking_for, ' '), array_to_string(NEW.languages, ' '));
+		bio TEXT := coalesce(NEW.bio, '');
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('russian', headline), 'A') || setweight(to_tsvector('english', headline), 'A') ||
+			setweight(to_tsvector('russian', details), 'B') || setweight(to_tsvector('english', details), 'B') ||
+			setweight(to_tsvector('russian', bio), 'C') || setweight(to_tsvector('english', bio), 'C');
+		RETURN NEW;
+	END;
+	$$ language 'plpgsql';
+
+	DROP TRIGGER IF EXISTS update_profiles_search_vector ON profiles;
+	CREATE TRIGGER update_profiles_search_vector
+		BEFORE INSERT OR UPDATE ON profiles
+		FOR EACH ROW
+		EXECUTE FUNCTION update_profiles_search_vector();`
+	if _, err := tx.Exec(createTrigger); err != nil {
+		return err
+	}
+
+	// Build the vector of the existing profiles
+	if _, err := tx.Exec(`UPDATE profiles SET search_vector = NULL`); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_profiles_search_vector ON profiles USING GIN (search_vector)`)
+	return err
+}
+
+func (m *AddProfileSearch) Rollback(tx *sql.Tx) error {
+	// The pg_trgm extension is kept, it may be used outside of the bot
+	sql := `DROP TRIGGER IF EXISTS update_profiles_search_vector ON profiles;
+	DROP FUNCTION IF EXISTS update_profiles_search_vector();
+	DROP INDEX IF EXISTS idx_profiles_search_vector;
+	ALTER TABLE profiles DROP COLUMN IF EXISTS search_vector;`
+	_, err := tx.Exec(sql)
+	return err
+}
@@ -0,0 +1,50 @@
+package implementations
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/database/prompts"
+	"fmt"
+	"log"
+)
+
+type AddDirectoryQueryPromptMigration struct {
+	BaseMigration
+}
+
+func NewAddDirectoryQueryPromptMigration() *AddDirectoryQueryPromptMigration {
+	return &AddDirectoryQueryPromptMigration{
+		BaseMigration: BaseMigration{
+			name:      "add_directory_query_prompt",
+			timestamp: "20250826",
+		},
+	}
+}
+
+func (m *AddDirectoryQueryPromptMigration) Apply(tx *sql.Tx) error {
+	var exists bool
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM prompting_templates WHERE template_key = $1)", prompts.DirectoryQueryPromptTemplateDbKey).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if directory query prompt exists: %w", err)
+	}
+
+	if !exists {
+		_, err = tx.Exec("INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)",
+			prompts.DirectoryQueryPromptTemplateDbKey, prompts.DirectoryQueryPromptDefaultTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to insert directory query prompt: %w", err)
+		}
+	}
+
+	log.Printf("Migration %s applied successfully", m.name)
+	return nil
+}
+
+func (m *AddDirectoryQueryPromptMigration) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM prompting_templates WHERE template_key = $1", prompts.DirectoryQueryPromptTemplateDbKey)
+	if err != nil {
+		return fmt.Errorf("failed to remove directory query prompt: %w", err)
+	}
+
+	log.Printf("Migration %s rolled back successfully", m.name)
+	return nil
+}
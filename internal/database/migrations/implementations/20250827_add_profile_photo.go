@@ -0,0 +1,38 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddProfilePhoto struct {
+	BaseMigration
+}
+
+func NewAddProfilePhoto() *AddProfilePhoto {
+	return &AddProfilePhoto{
+		BaseMigration: BaseMigration{
+			name:      "add_profile_photo",
+			timestamp: "20250827",
+		},
+	}
+}
+
+func (m *AddProfilePhoto) Apply(tx *sql.Tx) error {
+	// published_with_photo tells how to edit the published message,
+	// published_extra_message_id is the follow-up message with the text that did not fit into the caption
+	sql := `ALTER TABLE profiles
+			ADD COLUMN IF NOT EXISTS photo_file_id TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS published_with_photo BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN IF NOT EXISTS published_extra_message_id BIGINT`
+	_, err := tx.Exec(sql)
+	return err
+}
+
+func (m *AddProfilePhoto) Rollback(tx *sql.Tx) error {
+	sql := `ALTER TABLE profiles
+			DROP COLUMN IF EXISTS photo_file_id,
+			DROP COLUMN IF EXISTS published_with_photo,
+			DROP COLUMN IF EXISTS published_extra_message_id`
+	_, err := tx.Exec(sql)
+	return err
+}
@@ -0,0 +1,29 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type RenameEventAttendedScoreReason struct {
+	BaseMigration
+}
+
+func NewRenameEventAttendedScoreReason() *RenameEventAttendedScoreReason {
+	return &RenameEventAttendedScoreReason{
+		BaseMigration: BaseMigration{
+			name:      "rename_event_attended_score_reason",
+			timestamp: "20250828",
+		},
+	}
+}
+
+// Apply renames the reason of the karma for events, it has always been paid for the registration, not for the attendance
+func (m *RenameEventAttendedScoreReason) Apply(tx *sql.Tx) error {
+	_, err := tx.Exec(`UPDATE score_ledger SET reason = 'event_registered' WHERE reason = 'event_attended'`)
+	return err
+}
+
+func (m *RenameEventAttendedScoreReason) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`UPDATE score_ledger SET reason = 'event_attended' WHERE reason = 'event_registered'`)
+	return err
+}
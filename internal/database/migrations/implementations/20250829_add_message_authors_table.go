@@ -0,0 +1,48 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddMessageAuthorsTable struct {
+	BaseMigration
+}
+
+func NewAddMessageAuthorsTable() *AddMessageAuthorsTable {
+	return &AddMessageAuthorsTable{
+		BaseMigration: BaseMigration{
+			name:      "add_message_authors_table",
+			timestamp: "20250829",
+		},
+	}
+}
+
+func (m *AddMessageAuthorsTable) Apply(tx *sql.Tx) error {
+	// Reaction updates don't contain the message author, so the authors of recent group messages are kept here
+	createTable := `
+		CREATE TABLE IF NOT EXISTS message_authors (
+			chat_id BIGINT NOT NULL,
+			message_id BIGINT NOT NULL,
+			author_tg_id BIGINT NOT NULL,
+			author_first_name TEXT NOT NULL DEFAULT '',
+			author_last_name TEXT NOT NULL DEFAULT '',
+			author_username TEXT NOT NULL DEFAULT '',
+			posted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (chat_id, message_id)
+		)
+	`
+	if _, err := tx.Exec(createTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_message_authors_posted_at ON message_authors(posted_at)`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AddMessageAuthorsTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS message_authors`)
+	return err
+}
@@ -0,0 +1,42 @@
+package implementations
+
+import (
+	"database/sql"
+)
+
+type AddPendingCaptchasTable struct {
+	BaseMigration
+}
+
+func NewAddPendingCaptchasTable() *AddPendingCaptchasTable {
+	return &AddPendingCaptchasTable{
+		BaseMigration: BaseMigration{
+			name:      "add_pending_captchas_table",
+			timestamp: "20250830",
+		},
+	}
+}
+
+func (m *AddPendingCaptchasTable) Apply(tx *sql.Tx) error {
+	// Unanswered captchas are kept here, so their deadlines survive a restart of the bot
+	createTable := `
+		CREATE TABLE IF NOT EXISTS pending_captchas (
+			user_tg_id BIGINT PRIMARY KEY,
+			chat_id BIGINT NOT NULL,
+			message_id BIGINT NOT NULL,
+			answer_option INTEGER NOT NULL,
+			user_first_name TEXT NOT NULL DEFAULT '',
+			user_last_name TEXT NOT NULL DEFAULT '',
+			user_username TEXT NOT NULL DEFAULT '',
+			deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := tx.Exec(createTable)
+	return err
+}
+
+func (m *AddPendingCaptchasTable) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS pending_captchas`)
+	return err
+}
@@ -0,0 +1,63 @@
+package implementations
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/database/prompts"
+	"fmt"
+	"log"
+)
+
+// AddEventRecapContent stores recaps as structured JSON instead of the HTML written by the model,
+// the old HTML prompt is replaced with the prompt asking for JSON
+type AddEventRecapContent struct {
+	BaseMigration
+}
+
+func NewAddEventRecapContent() *AddEventRecapContent {
+	return &AddEventRecapContent{
+		BaseMigration: BaseMigration{
+			name:      "add_event_recap_content",
+			timestamp: "20250831",
+		},
+	}
+}
+
+// eventRecapHtmlPromptTemplateDbKey is the key of the prompt that asked the model for HTML
+const eventRecapHtmlPromptTemplateDbKey = "event_recap_prompt"
+
+func (m *AddEventRecapContent) Apply(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE event_recaps ADD COLUMN IF NOT EXISTS content JSONB NOT NULL DEFAULT '{}'`)
+	if err != nil {
+		return fmt.Errorf("failed to add content column to event_recaps: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM prompting_templates WHERE template_key = $1", eventRecapHtmlPromptTemplateDbKey)
+	if err != nil {
+		return fmt.Errorf("failed to remove event recap HTML prompt: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO prompting_templates (template_key, template_text) VALUES ($1, $2)
+		ON CONFLICT (template_key) DO NOTHING`,
+		prompts.EventRecapPromptTemplateDbKey, prompts.EventRecapPromptDefaultTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to insert event recap prompt: %w", err)
+	}
+
+	log.Printf("Migration %s applied successfully", m.name)
+	return nil
+}
+
+func (m *AddEventRecapContent) Rollback(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM prompting_templates WHERE template_key = $1", prompts.EventRecapPromptTemplateDbKey)
+	if err != nil {
+		return fmt.Errorf("failed to remove event recap prompt: %w", err)
+	}
+
+	_, err = tx.Exec(`ALTER TABLE event_recaps DROP COLUMN IF EXISTS content`)
+	if err != nil {
+		return fmt.Errorf("failed to drop content column from event_recaps: %w", err)
+	}
+
+	log.Printf("Migration %s rolled back successfully", m.name)
+	return nil
+}
@@ -20,6 +20,6 @@ func (m *BaseMigration) Timestamp() string {
 type Migration interface {
 	Name() string
 	Timestamp() string
-	Apply(db *sql.DB) error
-	Rollback(db *sql.DB) error
+	Apply(tx *sql.Tx) error
+	Rollback(tx *sql.Tx) error
 }
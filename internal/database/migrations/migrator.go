@@ -1,12 +1,19 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
 	"evo-bot-go/internal/database/migrations/implementations"
+	"evo-bot-go/internal/utils"
 	"fmt"
 	"log"
+	"time"
 )
 
+// migrationsLockID is the key of the advisory lock held while migrations run,
+// so instances started at the same time do not apply the same migration twice
+const migrationsLockID int64 = 7_245_870_215
+
 const (
 	createMigrationsTableSQL = `
 		CREATE TABLE IF NOT EXISTS migrations (
@@ -19,16 +26,17 @@ const (
 )
 
 // initMigrationsSchema initializes the migrations table schema
-func initMigrationsSchema(db *sql.DB) error {
-	if _, err := db.Exec(createMigrationsTableSQL); err != nil {
+func initMigrationsSchema(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, createMigrationsTableSQL); err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
 	return nil
 }
 
-// Registry returns all available migrations in order
-func Registry() []implementations.Migration {
+// Registry returns all available migrations in order. The session cipher encrypts the stored
+// user client sessions, it is nil if no session key is configured.
+func Registry(sessionCipher *utils.SessionCipher) []implementations.Migration {
 	return []implementations.Migration{
 		implementations.NewInitialMigration(),
 		implementations.NewChangeUserIdToNullableString(),
@@ -45,86 +53,255 @@ func Registry() []implementations.Migration {
 		implementations.NewAddIsClubMemberToUsers(),
 		implementations.NewAddRandomCoffeePairsTable(),
 		implementations.NewAddProfileSearchPromptMigration(),
+		implementations.NewAddEventRecapsTable(),
+		implementations.NewAddEventRecapPromptsMigration(),
+		implementations.NewAddTimezones(),
+		implementations.NewAddEventCapacityAndRegistrations(),
+		implementations.NewAddScoreLedgerTable(),
+		implementations.NewAddThanksTable(),
+		implementations.NewAddModerationRulesTable(),
+		implementations.NewAddModerationActionsTable(),
+		implementations.NewAddAuditLogTable(),
+		implementations.NewAddUserRolesTable(),
+		implementations.NewAddOutgoingMessagesTable(),
+		implementations.NewAddBroadcastsTable(),
+		implementations.NewAddLLMUsageTable(),
+		implementations.NewAddForumTopicsTable(),
+		implementations.NewEncryptTgSessions(sessionCipher),
+		implementations.NewAddProfileFields(),
+		implementations.NewAddProfileSearch(),
+		implementations.NewAddDirectoryQueryPromptMigration(),
+		implementations.NewAddProfilePhoto(),
+		implementations.NewRenameEventAttendedScoreReason(),
+		implementations.NewAddMessageAuthorsTable(),
+		implementations.NewAddPendingCaptchasTable(),
+		implementations.NewAddEventRecapContent(),
 		// Add new migrations here
 	}
 }
 
-// RunMigrations checks and runs all pending migrations
-func RunMigrations(db *sql.DB) error {
-	// Initialize the migrations table
-	if err := initMigrationsSchema(db); err != nil {
-		return fmt.Errorf("failed to initialize migrations schema: %w", err)
-	}
+// MigrationStatus represents a registered migration and when it was applied
+type MigrationStatus struct {
+	Name      string
+	Timestamp string
+	AppliedAt *time.Time // nil if the migration is pending
+}
 
-	// Get all registered migrations
-	allMigrations := Registry()
+// Migrator applies and rolls back the registered migrations. Every migration runs in its own
+// transaction together with its record in the migrations table, under an advisory lock.
+type Migrator struct {
+	db         *sql.DB
+	migrations []implementations.Migration
+}
 
-	// If no migrations, return early
-	if len(allMigrations) == 0 {
-		log.Println("No migrations to apply")
+// NewMigrator creates a migrator for the migrations in Registry
+func NewMigrator(db *sql.DB, sessionCipher *utils.SessionCipher) *Migrator {
+	return &Migrator{db: db, migrations: Registry(sessionCipher)}
+}
+
+// RunMigrations checks and runs all pending migrations, the first failed migration stops the run with an error
+func RunMigrations(db *sql.DB, sessionCipher *utils.SessionCipher) error {
+	_, err := NewMigrator(db, sessionCipher).Up(context.Background(), "")
+	return err
+}
+
+// Status returns the registered migrations in order with the time they were applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Name: migration.Name(), Timestamp: migration.Timestamp()}
+			if appliedAt, exists := applied[migration.Name()]; exists {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
 		return nil
+	})
+	return statuses, err
+}
+
+// Up applies the pending migrations in order up to and including the target one, or all of them if the target is empty.
+// It returns the names of the applied migrations.
+func (m *Migrator) Up(ctx context.Context, target string) ([]string, error) {
+	last := len(m.migrations) - 1
+	if target != "" {
+		last = m.indexOf(target)
+		if last < 0 {
+			return nil, fmt.Errorf("unknown migration: %s", target)
+		}
 	}
 
-	// Get already applied migrations
-	appliedMigrations, err := getAppliedMigrations(db)
-	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+	var appliedNow []string
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations[:last+1] {
+			if _, exists := applied[migration.Name()]; exists {
+				continue
+			}
+			if err := applyMigration(ctx, conn, migration); err != nil {
+				return err
+			}
+			appliedNow = append(appliedNow, migration.Name())
+		}
+		return nil
+	})
+	return appliedNow, err
+}
+
+// Down rolls back the last n applied migrations in reverse order and returns their names
+func (m *Migrator) Down(ctx context.Context, n int) ([]string, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
 	}
 
-	// Run each pending migration
-	for _, migration := range allMigrations {
-		migrationName := migration.Name()
+	var rolledBack []string
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < n; i-- {
+			migration := m.migrations[i]
+			if _, exists := applied[migration.Name()]; !exists {
+				continue
+			}
+			if err := rollbackMigration(ctx, conn, migration); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration.Name())
+		}
+		return nil
+	})
+	return rolledBack, err
+}
 
-		// Skip already applied migrations
-		if _, exists := appliedMigrations[migrationName]; exists {
-			continue
+// Redo rolls back the last applied migration and applies it again, it returns the name of the migration
+func (m *Migrator) Redo(ctx context.Context) (string, error) {
+	var redone string
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
 		}
 
-		// Apply the migration
-		log.Printf("Applying migration: %s", migrationName)
-		if err := migration.Apply(db); err != nil {
-			// If migration fails, roll it back
-			log.Printf("Migration %s failed, rolling back: %v", migrationName, err)
-			rollbackErr := migration.Rollback(db)
-			if rollbackErr != nil {
-				log.Printf("Warning: Rollback of migration %s also failed: %v", migrationName, rollbackErr)
-			} else {
-				// Remove the migration from the database if rollback succeeds
-				if err := removeMigrationRecord(db, migrationName); err != nil {
-					log.Printf("Warning: Failed to remove migration record for %s: %v", migrationName, err)
-				}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, exists := applied[migration.Name()]; !exists {
+				continue
 			}
-			log.Printf("Failed to apply migration %s: %v", migrationName, err)
-			break // Stop the loop after the first failed migration
+			if err := rollbackMigration(ctx, conn, migration); err != nil {
+				return err
+			}
+			if err := applyMigration(ctx, conn, migration); err != nil {
+				return err
+			}
+			redone = migration.Name()
+			return nil
 		}
+		return fmt.Errorf("no applied migrations to redo")
+	})
+	return redone, err
+}
 
-		// Record the migration to the database
-		if err := recordMigration(db, migrationName, migration.Timestamp()); err != nil {
-			return fmt.Errorf("failed to record migration %s: %w", migrationName, err)
+func (m *Migrator) indexOf(name string) int {
+	for i, migration := range m.migrations {
+		if migration.Name() == name {
+			return i
 		}
+	}
+	return -1
+}
 
-		log.Printf("Successfully applied migration: %s", migrationName)
+// withLock runs fn on a dedicated connection holding the migrations advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
 	}
+	defer conn.Close()
 
-	return nil
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockID); err != nil {
+		return fmt.Errorf("failed to acquire migrations lock: %w", err)
+	}
+	defer func() {
+		// The lock is released with the session anyway, so a failed unlock is only logged
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockID); err != nil {
+			log.Printf("Warning: Failed to release migrations lock: %v", err)
+		}
+	}()
+
+	if err := initMigrationsSchema(ctx, conn); err != nil {
+		return fmt.Errorf("failed to initialize migrations schema: %w", err)
+	}
+
+	return fn(conn)
 }
 
-func recordMigration(db *sql.DB, name string, timestamp string) error {
-	insertSQL := `INSERT INTO migrations (name, timestamp) VALUES ($1, $2)`
-	_, err := db.Exec(insertSQL, name, timestamp)
-	return err
+// applyMigration applies the migration and records it in one transaction
+func applyMigration(ctx context.Context, conn *sql.Conn, migration implementations.Migration) error {
+	log.Printf("Applying migration: %s", migration.Name())
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for migration %s: %w", migration.Name(), err)
+	}
+	defer tx.Rollback()
+
+	if err := migration.Apply(tx); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.Name(), err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO migrations (name, timestamp) VALUES ($1, $2)`,
+		migration.Name(), migration.Timestamp()); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.Name(), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", migration.Name(), err)
+	}
+
+	log.Printf("Successfully applied migration: %s", migration.Name())
+	return nil
 }
 
-func removeMigrationRecord(db *sql.DB, name string) error {
-	deleteSQL := `DELETE FROM migrations WHERE name = $1`
-	_, err := db.Exec(deleteSQL, name)
-	return err
+// rollbackMigration rolls back the migration and removes its record in one transaction
+func rollbackMigration(ctx context.Context, conn *sql.Conn, migration implementations.Migration) error {
+	log.Printf("Rolling back migration: %s", migration.Name())
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for migration %s: %w", migration.Name(), err)
+	}
+	defer tx.Rollback()
+
+	if err := migration.Rollback(tx); err != nil {
+		return fmt.Errorf("failed to roll back migration %s: %w", migration.Name(), err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM migrations WHERE name = $1`, migration.Name()); err != nil {
+		return fmt.Errorf("failed to remove migration record %s: %w", migration.Name(), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollback of migration %s: %w", migration.Name(), err)
+	}
+
+	log.Printf("Successfully rolled back migration: %s", migration.Name())
+	return nil
 }
 
-func getAppliedMigrations(db *sql.DB) (map[string]bool, error) {
-	appliedMigrations := make(map[string]bool)
+// getAppliedMigrations returns the applied migrations with the time they were applied
+func getAppliedMigrations(ctx context.Context, conn *sql.Conn) (map[string]time.Time, error) {
+	appliedMigrations := make(map[string]time.Time)
 
-	rows, err := db.Query("SELECT name FROM migrations")
+	rows, err := conn.QueryContext(ctx, "SELECT name, created_at FROM migrations")
 	if err != nil {
 		return nil, fmt.Errorf("failed to query migrations: %w", err)
 	}
@@ -132,10 +309,11 @@ func getAppliedMigrations(db *sql.DB) (map[string]bool, error) {
 
 	for rows.Next() {
 		var name string
-		if err := rows.Scan(&name); err != nil {
-			return nil, fmt.Errorf("failed to scan migration name: %w", err)
+		var createdAt time.Time
+		if err := rows.Scan(&name, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
 		}
-		appliedMigrations[name] = true
+		appliedMigrations[name] = createdAt
 	}
 
 	if err := rows.Err(); err != nil {
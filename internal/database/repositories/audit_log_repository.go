@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/utils"
+)
+
+// AuditLogEntry represents a row in the audit_log table
+type AuditLogEntry struct {
+	ID          int
+	ActorTgID   int64
+	ActorName   string
+	Action      string
+	EntityType  string
+	EntityID    string
+	BeforeValue sql.NullString
+	AfterValue  sql.NullString
+	CreatedAt   time.Time
+}
+
+// AuditLogFilter narrows the audit log search, empty fields are not applied
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   string
+	ActorTgID  int64
+	Limit      int
+}
+
+// AuditLogRepository handles database operations for the audit log
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create inserts a new audit log entry, before and after values must be JSON or NULL
+func (r *AuditLogRepository) Create(
+	actorTgID int64,
+	actorName string,
+	action string,
+	entityType string,
+	entityID string,
+	beforeValue sql.NullString,
+	afterValue sql.NullString,
+) (int, error) {
+	var id int
+	query := `
+		INSERT INTO audit_log (actor_tg_id, actor_name, action, entity_type, entity_id, before_value, after_value)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+	err := r.db.QueryRow(query, actorTgID, actorName, action, entityType, entityID, beforeValue, afterValue).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to insert audit log entry: %w", utils.GetCurrentTypeName(), err)
+	}
+	return id, nil
+}
+
+// Find retrieves the latest audit log entries matching the filter
+func (r *AuditLogRepository) Find(filter AuditLogFilter) ([]AuditLogEntry, error) {
+	query := `
+		SELECT id, actor_tg_id, actor_name, action, entity_type, entity_id,
+			before_value::TEXT, after_value::TEXT, created_at
+		FROM audit_log
+		WHERE ($1 = '' OR entity_type = $1)
+			AND ($2 = '' OR entity_id = $2)
+			AND ($3::BIGINT = 0 OR actor_tg_id = $3::BIGINT)
+		ORDER BY id DESC
+		LIMIT $4`
+
+	rows, err := r.db.Query(query, filter.EntityType, filter.EntityID, filter.ActorTgID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query audit log: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var entries []AuditLogEntry
+	for rows.Next() {
+		var entry AuditLogEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ActorTgID,
+			&entry.ActorName,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.BeforeValue,
+			&entry.AfterValue,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan audit log entry: %w", utils.GetCurrentTypeName(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over audit log entries: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,38 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogRepository_Find(t *testing.T) {
+	db := dbtest.OpenSchema(t)
+	require.NoError(t, migrations.RunMigrations(db, nil))
+	repository := repositories.NewAuditLogRepository(db)
+
+	// Telegram IDs don't fit into INTEGER
+	const actorTgID = int64(7_000_000_001)
+	_, err := repository.Create(actorTgID, "Admin", "update", "event", "1", sql.NullString{}, sql.NullString{String: `{"name":"Meetup"}`, Valid: true})
+	require.NoError(t, err)
+	_, err = repository.Create(1001, "Other", "delete", "event", "2", sql.NullString{}, sql.NullString{})
+	require.NoError(t, err)
+
+	entries, err := repository.Find(repositories.AuditLogFilter{ActorTgID: actorTgID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "1", entries[0].EntityID)
+	assert.False(t, entries[0].BeforeValue.Valid)
+
+	entries, err = repository.Find(repositories.AuditLogFilter{EntityType: "event", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+// Broadcast represents a row in the broadcasts table
+type Broadcast struct {
+	ID              int
+	CreatedByTgID   int64
+	Audience        constants.BroadcastAudience
+	AudienceParam   int // number of weeks for coffee participants, event ID for event registrations
+	FromChatID      int64
+	FromMessageID   int64
+	RecipientsCount int
+	ScheduledAt     time.Time
+	ReportedAt      sql.NullTime
+	CreatedAt       time.Time
+}
+
+// BroadcastRepository handles database operations for broadcasts and their audiences
+type BroadcastRepository struct {
+	db *sql.DB
+}
+
+// NewBroadcastRepository creates a new BroadcastRepository
+func NewBroadcastRepository(db *sql.DB) *BroadcastRepository {
+	return &BroadcastRepository{db: db}
+}
+
+// CreateQueued inserts the broadcast and queues a copy of its message for every recipient in one transaction,
+// so either the whole audience gets the broadcast or nobody does. The copies are sent after the scheduled time.
+func (r *BroadcastRepository) CreateQueued(broadcast *Broadcast, recipientTgIDs []int64) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to begin transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := `
+		INSERT INTO broadcasts (created_by_tg_id, audience, audience_param, from_chat_id, from_message_id, recipients_count, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+	err = tx.QueryRow(
+		query,
+		broadcast.CreatedByTgID,
+		string(broadcast.Audience),
+		broadcast.AudienceParam,
+		broadcast.FromChatID,
+		broadcast.FromMessageID,
+		len(recipientTgIDs),
+		broadcast.ScheduledAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to insert broadcast: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	queueQuery := `
+		INSERT INTO outgoing_messages (chat_id, copy_from_chat_id, copy_from_message_id, priority, batch, send_after)
+		SELECT recipient, $2, $3, $4, $5, $6
+		FROM UNNEST($1::BIGINT[]) AS recipient`
+	_, err = tx.Exec(
+		queueQuery,
+		pq.Array(recipientTgIDs),
+		broadcast.FromChatID,
+		broadcast.FromMessageID,
+		int(constants.MessagePriorityBulk),
+		BroadcastBatch(id),
+		broadcast.ScheduledAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to queue broadcast %d: %w", utils.GetCurrentTypeName(), id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	broadcast.RecipientsCount = len(recipientTgIDs)
+	return id, nil
+}
+
+// BroadcastBatch returns the batch of the queued copies of the broadcast
+func BroadcastBatch(broadcastID int) string {
+	return fmt.Sprintf("broadcast:%d", broadcastID)
+}
+
+// GetUnreported retrieves broadcasts whose time has come and whose report has not been sent yet
+func (r *BroadcastRepository) GetUnreported() ([]Broadcast, error) {
+	query := `
+		SELECT id, created_by_tg_id, audience, audience_param, from_chat_id, from_message_id, recipients_count, scheduled_at, reported_at, created_at
+		FROM broadcasts
+		WHERE reported_at IS NULL AND scheduled_at <= NOW()
+		ORDER BY id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query unreported broadcasts: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var broadcasts []Broadcast
+	for rows.Next() {
+		var broadcast Broadcast
+		var audience string
+		if err := rows.Scan(
+			&broadcast.ID,
+			&broadcast.CreatedByTgID,
+			&audience,
+			&broadcast.AudienceParam,
+			&broadcast.FromChatID,
+			&broadcast.FromMessageID,
+			&broadcast.RecipientsCount,
+			&broadcast.ScheduledAt,
+			&broadcast.ReportedAt,
+			&broadcast.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan broadcast: %w", utils.GetCurrentTypeName(), err)
+		}
+		broadcast.Audience = constants.BroadcastAudience(audience)
+		broadcasts = append(broadcasts, broadcast)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over broadcasts: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return broadcasts, nil
+}
+
+// MarkReported records that the delivery report of the broadcast was sent
+func (r *BroadcastRepository) MarkReported(id int) error {
+	query := `UPDATE broadcasts SET reported_at = NOW() WHERE id = $1`
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("%s: failed to mark broadcast %d as reported: %w", utils.GetCurrentTypeName(), id, err)
+	}
+	return nil
+}
+
+// GetAudienceTelegramIDs retrieves the Telegram IDs of the users in the broadcast audience
+func (r *BroadcastRepository) GetAudienceTelegramIDs(audience constants.BroadcastAudience, param int) ([]int64, error) {
+	var query string
+	var args []interface{}
+
+	switch audience {
+	case constants.BroadcastAudienceAllMembers:
+		query = `SELECT tg_id FROM users WHERE is_club_member = TRUE ORDER BY id`
+	case constants.BroadcastAudienceCoffeeParticipants:
+		query = `
+			SELECT DISTINCT u.tg_id
+			FROM users u
+			INNER JOIN random_coffee_participants rcp ON rcp.user_id = u.id
+			INNER JOIN random_coffee_polls rcpoll ON rcpoll.id = rcp.poll_id
+			WHERE u.is_club_member = TRUE
+				AND rcp.is_participating = TRUE
+				AND rcpoll.week_start_date >= CURRENT_DATE - make_interval(weeks => $1)`
+		args = append(args, param)
+	case constants.BroadcastAudienceWithoutProfile:
+		query = `
+			SELECT u.tg_id
+			FROM users u
+			LEFT JOIN profiles p ON p.user_id = u.id
+			WHERE u.is_club_member = TRUE AND (p.id IS NULL OR p.bio IS NULL OR p.bio = '')
+			ORDER BY u.id`
+	case constants.BroadcastAudienceEventRegistrations:
+		query = `
+			SELECT u.tg_id
+			FROM event_registrations er
+			INNER JOIN users u ON er.user_id = u.id
+			WHERE er.event_id = $1 AND u.is_club_member = TRUE
+			ORDER BY er.created_at, er.id`
+		args = append(args, param)
+	default:
+		return nil, fmt.Errorf("%s: unknown broadcast audience %s", utils.GetCurrentTypeName(), audience)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query audience %s: %w", utils.GetCurrentTypeName(), audience, err)
+	}
+	defer rows.Close()
+
+	var tgIDs []int64
+	for rows.Next() {
+		var tgID int64
+		if err := rows.Scan(&tgID); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan audience member: %w", utils.GetCurrentTypeName(), err)
+		}
+		tgIDs = append(tgIDs, tgID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over audience: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return tgIDs, nil
+}
@@ -0,0 +1,91 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastRepository_CreateQueued(t *testing.T) {
+	db := dbtest.OpenSchema(t)
+	require.NoError(t, migrations.RunMigrations(db, nil))
+	repository := repositories.NewBroadcastRepository(db)
+	outgoingMessages := repositories.NewOutgoingMessageRepository(db)
+
+	broadcast := &repositories.Broadcast{
+		CreatedByTgID: 1001,
+		Audience:      constants.BroadcastAudienceAllMembers,
+		FromChatID:    1001,
+		FromMessageID: 5,
+		ScheduledAt:   time.Now().Add(time.Hour),
+	}
+	id, err := repository.CreateQueued(broadcast, []int64{2001, 2002, 2003})
+	require.NoError(t, err)
+	assert.Equal(t, 3, broadcast.RecipientsCount)
+
+	stats, err := outgoingMessages.GetBatchStats(repositories.BroadcastBatch(id))
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats[constants.OutgoingMessageStatusPending])
+
+	// The copies wait for the scheduled time
+	due, err := outgoingMessages.ClaimDue(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func TestBroadcastRepository_GetAudienceTelegramIDsSkipsFormerMembers(t *testing.T) {
+	db := dbtest.OpenSchema(t)
+	require.NoError(t, migrations.RunMigrations(db, nil))
+	repository := repositories.NewBroadcastRepository(db)
+	users := repositories.NewUserRepository(db)
+	events := repositories.NewEventRepository(db)
+
+	memberID, err := users.Create(2001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+	formerMemberID, err := users.Create(2002, "Petr", "Sidorov", "petr")
+	require.NoError(t, err)
+	require.NoError(t, users.SetClubMemberStatus(formerMemberID, false))
+
+	pollID, err := repositories.NewRandomCoffeePollRepository(db).CreatePoll(repositories.RandomCoffeePoll{
+		MessageID:      1,
+		WeekStartDate:  time.Now(),
+		TelegramPollID: "poll",
+	})
+	require.NoError(t, err)
+	eventID, err := events.CreateEvent("Meetup", constants.EventTypeMeetup)
+	require.NoError(t, err)
+
+	for _, userID := range []int{memberID, formerMemberID} {
+		require.NoError(t, repositories.NewRandomCoffeeParticipantRepository(db).UpsertParticipant(repositories.RandomCoffeeParticipant{
+			PollID:          pollID,
+			UserID:          int64(userID),
+			IsParticipating: true,
+		}))
+		_, err = events.RegisterForEvent(eventID, userID)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		audience constants.BroadcastAudience
+		param    int
+	}{
+		{audience: constants.BroadcastAudienceAllMembers},
+		{audience: constants.BroadcastAudienceCoffeeParticipants, param: 4},
+		{audience: constants.BroadcastAudienceWithoutProfile},
+		{audience: constants.BroadcastAudienceEventRegistrations, param: eventID},
+	}
+	for _, tt := range tests {
+		tgIDs, err := repository.GetAudienceTelegramIDs(tt.audience, tt.param)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2001}, tgIDs, "those who left the club get no broadcasts: %s", tt.audience)
+	}
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"log"
+	"time"
+)
+
+// EventRecap represents a row in the event_recaps table
+type EventRecap struct {
+	ID      int
+	EventID int
+	// Recap is the free text of the recaps generated before the structured content, it is empty for new recaps
+	Recap              string
+	Content            EventRecapContent
+	PublishedMessageID sql.NullInt64
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// EventRecapContent is the structured recap of an event, it is stored as a JSONB column
+type EventRecapContent struct {
+	KeyPoints []string          `json:"key_points"`
+	Decisions []string          `json:"decisions"`
+	Links     []string          `json:"links"`
+	Topics    []EventRecapTopic `json:"topics"`
+}
+
+// EventRecapTopic tells whether a topic proposed to the event was covered
+type EventRecapTopic struct {
+	Topic  string                          `json:"topic"`
+	Status constants.EventRecapTopicStatus `json:"status"`
+}
+
+// IsEmpty checks if the recap has nothing to show
+func (c EventRecapContent) IsEmpty() bool {
+	return len(c.KeyPoints) == 0 && len(c.Decisions) == 0 && len(c.Links) == 0 && len(c.Topics) == 0
+}
+
+// Value implements driver.Valuer
+func (c EventRecapContent) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (c *EventRecapContent) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = EventRecapContent{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EventRecapContent", src)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// EventRecapRepository handles database operations for event recaps
+type EventRecapRepository struct {
+	db *sql.DB
+}
+
+// NewEventRecapRepository creates a new EventRecapRepository
+func NewEventRecapRepository(db *sql.DB) *EventRecapRepository {
+	return &EventRecapRepository{db: db}
+}
+
+// Upsert creates a recap for the event or replaces the content of the existing one
+func (r *EventRecapRepository) Upsert(eventID int, content EventRecapContent) (*EventRecap, error) {
+	query := `
+		INSERT INTO event_recaps (event_id, recap, content)
+		VALUES ($1, '', $2)
+		ON CONFLICT (event_id) DO UPDATE SET recap = '', content = EXCLUDED.content, updated_at = NOW()
+		RETURNING id, event_id, recap, content, published_message_id, created_at, updated_at`
+
+	var eventRecap EventRecap
+	err := r.db.QueryRow(query, eventID, content).Scan(
+		&eventRecap.ID,
+		&eventRecap.EventID,
+		&eventRecap.Recap,
+		&eventRecap.Content,
+		&eventRecap.PublishedMessageID,
+		&eventRecap.CreatedAt,
+		&eventRecap.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to upsert recap for event ID %d: %w", utils.GetCurrentTypeName(), eventID, err)
+	}
+
+	return &eventRecap, nil
+}
+
+// GetByEventID retrieves the recap of an event, returns sql.ErrNoRows if there is no recap yet
+func (r *EventRecapRepository) GetByEventID(eventID int) (*EventRecap, error) {
+	query := `
+		SELECT id, event_id, recap, content, published_message_id, created_at, updated_at
+		FROM event_recaps
+		WHERE event_id = $1`
+
+	var eventRecap EventRecap
+	err := r.db.QueryRow(query, eventID).Scan(
+		&eventRecap.ID,
+		&eventRecap.EventID,
+		&eventRecap.Recap,
+		&eventRecap.Content,
+		&eventRecap.PublishedMessageID,
+		&eventRecap.CreatedAt,
+		&eventRecap.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: failed to get recap for event ID %d: %w", utils.GetCurrentTypeName(), eventID, err)
+	}
+
+	return &eventRecap, nil
+}
+
+// UpdatePublishedMessageID updates the published_message_id field for a recap
+func (r *EventRecapRepository) UpdatePublishedMessageID(recapID int, messageID int64) error {
+	query := `UPDATE event_recaps SET published_message_id = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.Exec(query, messageID, recapID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update published_message_id for recap with ID %d: %w", utils.GetCurrentTypeName(), recapID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("%s: Could not get rows affected after update: %v", utils.GetCurrentTypeName(), err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("%s: no recap found with ID %d to update published_message_id", utils.GetCurrentTypeName(), recapID)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"testing"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRecapRepository_UpsertStoresContent(t *testing.T) {
+	db := dbtest.OpenSchema(t)
+	require.NoError(t, migrations.RunMigrations(db, nil))
+	repository := repositories.NewEventRecapRepository(db)
+
+	eventID, err := repositories.NewEventRepository(db).CreateEvent("Go meetup", constants.EventTypeMeetup)
+	require.NoError(t, err)
+
+	// A recap generated before the structured content
+	_, err = db.Exec(`INSERT INTO event_recaps (event_id, recap) VALUES ($1, '<b>old</b>')`, eventID)
+	require.NoError(t, err)
+	recap, err := repository.GetByEventID(eventID)
+	require.NoError(t, err)
+	assert.Equal(t, "<b>old</b>", recap.Recap)
+	assert.True(t, recap.Content.IsEmpty())
+
+	content := repositories.EventRecapContent{
+		KeyPoints: []string{"Generics <T>"},
+		Topics:    []repositories.EventRecapTopic{{Topic: "Errors", Status: constants.EventRecapTopicPartial}},
+	}
+	recap, err = repository.Upsert(eventID, content)
+	require.NoError(t, err)
+	assert.Empty(t, recap.Recap, "the old text is replaced")
+	assert.Equal(t, content, recap.Content)
+
+	recap, err = repository.GetByEventID(eventID)
+	require.NoError(t, err)
+	assert.Equal(t, content, recap.Content)
+}
@@ -16,26 +16,68 @@ type Event struct {
 	Type      string
 	Status    string
 	StartedAt *time.Time
+	Timezone  string
+	Capacity  *int
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-// EventRepository handles database operations for events
-type EventRepository struct {
+// EventRegistration represents a row in the event_registrations table
+type EventRegistration struct {
+	ID        int
+	EventID   int
+	UserID    int
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// EventRegistrationWithUser represents a registration with the registered user and their published profile
+type EventRegistrationWithUser struct {
+	Registration              *EventRegistration
+	User                      *User
+	ProfilePublishedMessageID sql.NullInt64
+}
+
+// EventRepository stores the club events and registrations for them
+type EventRepository interface {
+	CreateEvent(name string, eventType constants.EventType) (int, error)
+	CreateEventWithStartedAt(name string, eventType constants.EventType, startedAt time.Time) (int, error)
+	GetLastActualEvents(limit int) ([]Event, error)
+	GetLastEvents(limit int) ([]Event, error)
+	UpdateEventName(id int, newName string) error
+	UpdateEventStatus(id int, status constants.EventStatus) error
+	UpdateEventStartedAt(id int, startedAt time.Time) error
+	UpdateEventCapacity(id int, capacity *int) ([]EventRegistration, error)
+	UpdateEventType(id int, eventType constants.EventType) error
+	DeleteEvent(id int) error
+	GetEventByID(id int) (*Event, error)
+	GetEventRegistration(eventID int, userID int) (*EventRegistration, error)
+	CountEventRegistrations(eventID int) (going int, waitlist int, err error)
+	RegisterForEvent(eventID int, userID int) (*EventRegistration, error)
+	CancelEventRegistration(eventID int, userID int) ([]EventRegistration, error)
+	GetEventRegistrationsWithUsers(eventID int) ([]EventRegistrationWithUser, error)
+}
+
+// Ensure PostgresEventRepository implements EventRepository interface
+var _ EventRepository = (*PostgresEventRepository)(nil)
+
+// PostgresEventRepository handles database operations for events
+type PostgresEventRepository struct {
 	db              *sql.DB
 	topicRepository *TopicRepository
 }
 
-// NewEventRepository creates a new EventRepository
-func NewEventRepository(db *sql.DB) *EventRepository {
-	return &EventRepository{
+// NewEventRepository creates a new PostgresEventRepository
+func NewEventRepository(db *sql.DB) *PostgresEventRepository {
+	return &PostgresEventRepository{
 		db:              db,
 		topicRepository: NewTopicRepository(db),
 	}
 }
 
 // CreateEvent inserts a new event record into the database
-func (r *EventRepository) CreateEvent(name string, eventType constants.EventType) (int, error) {
+func (r *PostgresEventRepository) CreateEvent(name string, eventType constants.EventType) (int, error) {
 	var id int
 	query := `INSERT INTO events (name, type, status) VALUES ($1, $2, $3) RETURNING id`
 	err := r.db.QueryRow(query, name, eventType, constants.EventStatusActual).Scan(&id)
@@ -45,11 +87,12 @@ func (r *EventRepository) CreateEvent(name string, eventType constants.EventType
 	return id, nil
 }
 
-// CreateEventWithStartedAt inserts a new event record with a started_at value into the database
-func (r *EventRepository) CreateEventWithStartedAt(name string, eventType constants.EventType, startedAt time.Time) (int, error) {
+// CreateEventWithStartedAt inserts a new event record with a started_at value into the database.
+// The timezone of the event is taken from the location of startedAt.
+func (r *PostgresEventRepository) CreateEventWithStartedAt(name string, eventType constants.EventType, startedAt time.Time) (int, error) {
 	var id int
-	query := `INSERT INTO events (name, type, status, started_at) VALUES ($1, $2, $3, $4) RETURNING id`
-	err := r.db.QueryRow(query, name, eventType, constants.EventStatusActual, startedAt).Scan(&id)
+	query := `INSERT INTO events (name, type, status, started_at, timezone) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := r.db.QueryRow(query, name, eventType, constants.EventStatusActual, startedAt, startedAt.Location().String()).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("%s: failed to insert event with started_at: %w", utils.GetCurrentTypeName(), err)
 	}
@@ -57,9 +100,9 @@ func (r *EventRepository) CreateEventWithStartedAt(name string, eventType consta
 }
 
 // GetLastActualEvents retrieves the last N actual event records
-func (r *EventRepository) GetLastActualEvents(limit int) ([]Event, error) {
+func (r *PostgresEventRepository) GetLastActualEvents(limit int) ([]Event, error) {
 	query := `
-		SELECT id, name, type, status, started_at, created_at, updated_at
+		SELECT id, name, type, status, started_at, timezone, capacity, created_at, updated_at
 		FROM events
 		WHERE status = $1
 		ORDER BY started_at ASC NULLS LAST
@@ -74,7 +117,7 @@ func (r *EventRepository) GetLastActualEvents(limit int) ([]Event, error) {
 	var events []Event
 	for rows.Next() {
 		var e Event
-		if err := rows.Scan(&e.ID, &e.Name, &e.Type, &e.Status, &e.StartedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Name, &e.Type, &e.Status, &e.StartedAt, &e.Timezone, &e.Capacity, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("%s: failed to scan event row: %w", utils.GetCurrentTypeName(), err)
 		}
 		events = append(events, e)
@@ -88,9 +131,9 @@ func (r *EventRepository) GetLastActualEvents(limit int) ([]Event, error) {
 }
 
 // GetLastEvents retrieves the last N event records
-func (r *EventRepository) GetLastEvents(limit int) ([]Event, error) {
+func (r *PostgresEventRepository) GetLastEvents(limit int) ([]Event, error) {
 	query := `
-		SELECT id, name, type, status, started_at, created_at, updated_at
+		SELECT id, name, type, status, started_at, timezone, capacity, created_at, updated_at
 		FROM events
 		ORDER BY started_at DESC NULLS LAST
 		LIMIT $1`
@@ -104,7 +147,7 @@ func (r *EventRepository) GetLastEvents(limit int) ([]Event, error) {
 	var events []Event
 	for rows.Next() {
 		var e Event
-		if err := rows.Scan(&e.ID, &e.Name, &e.Type, &e.Status, &e.StartedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Name, &e.Type, &e.Status, &e.StartedAt, &e.Timezone, &e.Capacity, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("%s: failed to scan event row: %w", utils.GetCurrentTypeName(), err)
 		}
 		events = append(events, e)
@@ -118,7 +161,7 @@ func (r *EventRepository) GetLastEvents(limit int) ([]Event, error) {
 }
 
 // UpdateEventName updates the name of an event record by its ID
-func (r *EventRepository) UpdateEventName(id int, newName string) error {
+func (r *PostgresEventRepository) UpdateEventName(id int, newName string) error {
 	query := `UPDATE events SET name = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, newName, id)
 	if err != nil {
@@ -137,7 +180,7 @@ func (r *EventRepository) UpdateEventName(id int, newName string) error {
 }
 
 // UpdateEventStatus updates the status of an event record by its ID
-func (r *EventRepository) UpdateEventStatus(id int, status constants.EventStatus) error {
+func (r *PostgresEventRepository) UpdateEventStatus(id int, status constants.EventStatus) error {
 	query := `UPDATE events SET status = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, status, id)
 	if err != nil {
@@ -154,10 +197,11 @@ func (r *EventRepository) UpdateEventStatus(id int, status constants.EventStatus
 	return nil
 }
 
-// UpdateEventStartedAt updates the started_at field of an event record by its ID
-func (r *EventRepository) UpdateEventStartedAt(id int, startedAt time.Time) error {
-	query := `UPDATE events SET started_at = $1, updated_at = NOW() WHERE id = $2`
-	result, err := r.db.Exec(query, startedAt, id)
+// UpdateEventStartedAt updates the started_at and timezone fields of an event record by its ID.
+// The timezone of the event is taken from the location of startedAt.
+func (r *PostgresEventRepository) UpdateEventStartedAt(id int, startedAt time.Time) error {
+	query := `UPDATE events SET started_at = $1, timezone = $2, updated_at = NOW() WHERE id = $3`
+	result, err := r.db.Exec(query, startedAt, startedAt.Location().String(), id)
 	if err != nil {
 		return fmt.Errorf("%s: failed to update event started_at for ID %d: %w", utils.GetCurrentTypeName(), id, err)
 	}
@@ -172,8 +216,41 @@ func (r *EventRepository) UpdateEventStartedAt(id int, startedAt time.Time) erro
 	return nil
 }
 
+// UpdateEventCapacity updates the capacity of an event record by its ID, nil removes the limit.
+// Returns registrations promoted from the waitlist if the capacity was increased.
+func (r *PostgresEventRepository) UpdateEventCapacity(id int, capacity *int) ([]EventRegistration, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to begin transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE events SET capacity = $1, updated_at = NOW() WHERE id = $2`, capacity, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to update event capacity for ID %d: %w", utils.GetCurrentTypeName(), id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("%s: Could not get rows affected after update: %v", utils.GetCurrentTypeName(), err)
+	} else if rowsAffected == 0 {
+		return nil, fmt.Errorf("%s: no event found with ID %d to update capacity", utils.GetCurrentTypeName(), id)
+	}
+
+	promoted, err := r.promoteFromWaitlist(tx, id, capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: failed to commit transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return promoted, nil
+}
+
 // UpdateEventType updates the type of an event record by its ID
-func (r *EventRepository) UpdateEventType(id int, eventType constants.EventType) error {
+func (r *PostgresEventRepository) UpdateEventType(id int, eventType constants.EventType) error {
 	query := `UPDATE events SET type = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, string(eventType), id)
 	if err != nil {
@@ -191,7 +268,7 @@ func (r *EventRepository) UpdateEventType(id int, eventType constants.EventType)
 }
 
 // DeleteEvent removes an event record from the database by its ID
-func (r *EventRepository) DeleteEvent(id int) error {
+func (r *PostgresEventRepository) DeleteEvent(id int) error {
 	// First, get all topics related to this event
 	topics, err := r.topicRepository.GetTopicsByEventID(id)
 	if err != nil {
@@ -223,9 +300,9 @@ func (r *EventRepository) DeleteEvent(id int) error {
 }
 
 // GetEventByID retrieves a single event record by its ID
-func (r *EventRepository) GetEventByID(id int) (*Event, error) {
+func (r *PostgresEventRepository) GetEventByID(id int) (*Event, error) {
 	query := `
-		SELECT id, name, type, status, started_at, created_at, updated_at
+		SELECT id, name, type, status, started_at, timezone, capacity, created_at, updated_at
 		FROM events
 		WHERE id = $1`
 
@@ -236,6 +313,8 @@ func (r *EventRepository) GetEventByID(id int) (*Event, error) {
 		&event.Type,
 		&event.Status,
 		&event.StartedAt,
+		&event.Timezone,
+		&event.Capacity,
 		&event.CreatedAt,
 		&event.UpdatedAt,
 	)
@@ -250,3 +329,282 @@ func (r *EventRepository) GetEventByID(id int) (*Event, error) {
 
 	return &event, nil
 }
+
+// GetEventRegistration retrieves the registration of a user for an event
+func (r *PostgresEventRepository) GetEventRegistration(eventID int, userID int) (*EventRegistration, error) {
+	query := `
+		SELECT id, event_id, user_id, status, created_at, updated_at
+		FROM event_registrations
+		WHERE event_id = $1 AND user_id = $2`
+
+	registration, err := scanEventRegistration(r.db.QueryRow(query, eventID, userID))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get registration of user %d for event %d: %w", utils.GetCurrentTypeName(), userID, eventID, err)
+	}
+
+	return registration, nil
+}
+
+// CountEventRegistrations returns the number of going and waitlisted registrations for an event
+func (r *PostgresEventRepository) CountEventRegistrations(eventID int) (going int, waitlist int, err error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = $2),
+			COUNT(*) FILTER (WHERE status = $3)
+		FROM event_registrations
+		WHERE event_id = $1`
+
+	err = r.db.QueryRow(query, eventID, constants.EventRegistrationStatusGoing, constants.EventRegistrationStatusWaitlist).Scan(&going, &waitlist)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: failed to count registrations for event %d: %w", utils.GetCurrentTypeName(), eventID, err)
+	}
+
+	return going, waitlist, nil
+}
+
+// RegisterForEvent registers a user for an event, putting them on the waitlist when the event is full.
+// If the user is already registered, the existing registration is returned.
+func (r *PostgresEventRepository) RegisterForEvent(eventID int, userID int) (*EventRegistration, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to begin transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer tx.Rollback()
+
+	// Lock the event row so concurrent registrations can't exceed the capacity
+	capacity, err := r.lockEventCapacity(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := scanEventRegistration(tx.QueryRow(`
+		SELECT id, event_id, user_id, status, created_at, updated_at
+		FROM event_registrations
+		WHERE event_id = $1 AND user_id = $2`, eventID, userID))
+	if err == nil {
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: failed to check existing registration: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	status := constants.EventRegistrationStatusGoing
+	if capacity != nil {
+		var going int
+		err := tx.QueryRow(
+			`SELECT COUNT(*) FROM event_registrations WHERE event_id = $1 AND status = $2`,
+			eventID, constants.EventRegistrationStatusGoing,
+		).Scan(&going)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to count going registrations: %w", utils.GetCurrentTypeName(), err)
+		}
+		if going >= *capacity {
+			status = constants.EventRegistrationStatusWaitlist
+		}
+	}
+
+	registration, err := scanEventRegistration(tx.QueryRow(`
+		INSERT INTO event_registrations (event_id, user_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, event_id, user_id, status, created_at, updated_at`, eventID, userID, status))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to insert registration: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: failed to commit transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return registration, nil
+}
+
+// CancelEventRegistration removes a user's registration for an event.
+// Returns registrations promoted from the waitlist to the freed places, or sql.ErrNoRows if the user wasn't registered.
+func (r *PostgresEventRepository) CancelEventRegistration(eventID int, userID int) ([]EventRegistration, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to begin transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer tx.Rollback()
+
+	capacity, err := r.lockEventCapacity(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status string
+	err = tx.QueryRow(
+		`DELETE FROM event_registrations WHERE event_id = $1 AND user_id = $2 RETURNING status`,
+		eventID, userID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to delete registration of user %d for event %d: %w", utils.GetCurrentTypeName(), userID, eventID, err)
+	}
+
+	var promoted []EventRegistration
+	if status == string(constants.EventRegistrationStatusGoing) {
+		promoted, err = r.promoteFromWaitlist(tx, eventID, capacity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: failed to commit transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return promoted, nil
+}
+
+// GetEventRegistrationsWithUsers retrieves all registrations for an event with user information,
+// going registrations first, each group in registration order
+func (r *PostgresEventRepository) GetEventRegistrationsWithUsers(eventID int) ([]EventRegistrationWithUser, error) {
+	query := `
+		SELECT
+			er.id, er.event_id, er.user_id, er.status, er.created_at, er.updated_at,
+			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username, u.score, u.has_coffee_ban, u.is_club_member, u.timezone, u.created_at, u.updated_at,
+			p.published_message_id
+		FROM event_registrations er
+		INNER JOIN users u ON er.user_id = u.id
+		LEFT JOIN profiles p ON p.user_id = u.id
+		WHERE er.event_id = $1
+		ORDER BY CASE WHEN er.status = $2 THEN 0 ELSE 1 END, er.created_at ASC, er.id ASC`
+
+	rows, err := r.db.Query(query, eventID, constants.EventRegistrationStatusGoing)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query registrations for event %d: %w", utils.GetCurrentTypeName(), eventID, err)
+	}
+	defer rows.Close()
+
+	var registrations []EventRegistrationWithUser
+	for rows.Next() {
+		var registration EventRegistration
+		var user User
+		var publishedMessageID sql.NullInt64
+
+		err := rows.Scan(
+			&registration.ID,
+			&registration.EventID,
+			&registration.UserID,
+			&registration.Status,
+			&registration.CreatedAt,
+			&registration.UpdatedAt,
+			&user.ID,
+			&user.TgID,
+			&user.Firstname,
+			&user.Lastname,
+			&user.TgUsername,
+			&user.Score,
+			&user.HasCoffeeBan,
+			&user.IsClubMember,
+			&user.Timezone,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&publishedMessageID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan registration with user: %w", utils.GetCurrentTypeName(), err)
+		}
+
+		registrations = append(registrations, EventRegistrationWithUser{
+			Registration:              &registration,
+			User:                      &user,
+			ProfilePublishedMessageID: publishedMessageID,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over registrations: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return registrations, nil
+}
+
+// lockEventCapacity locks the event row for the rest of the transaction and returns its capacity
+func (r *PostgresEventRepository) lockEventCapacity(tx *sql.Tx, eventID int) (*int, error) {
+	var capacity *int
+	err := tx.QueryRow(`SELECT capacity FROM events WHERE id = $1 FOR UPDATE`, eventID).Scan(&capacity)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: no event found with ID %d", utils.GetCurrentTypeName(), eventID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to lock event with ID %d: %w", utils.GetCurrentTypeName(), eventID, err)
+	}
+	return capacity, nil
+}
+
+// promoteFromWaitlist moves the earliest waitlisted registrations to going while there are free places
+func (r *PostgresEventRepository) promoteFromWaitlist(tx *sql.Tx, eventID int, capacity *int) ([]EventRegistration, error) {
+	var going int
+	err := tx.QueryRow(
+		`SELECT COUNT(*) FROM event_registrations WHERE event_id = $1 AND status = $2`,
+		eventID, constants.EventRegistrationStatusGoing,
+	).Scan(&going)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to count going registrations: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// Without capacity everybody from the waitlist gets a place
+	freePlaces := -1
+	if capacity != nil {
+		freePlaces = *capacity - going
+		if freePlaces <= 0 {
+			return nil, nil
+		}
+	}
+
+	query := `
+		UPDATE event_registrations SET status = $1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM event_registrations
+			WHERE event_id = $2 AND status = $3
+			ORDER BY created_at ASC, id ASC
+			LIMIT NULLIF($4, -1)
+		)
+		RETURNING id, event_id, user_id, status, created_at, updated_at`
+
+	rows, err := tx.Query(query, constants.EventRegistrationStatusGoing, eventID, constants.EventRegistrationStatusWaitlist, freePlaces)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to promote registrations from waitlist: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var promoted []EventRegistration
+	for rows.Next() {
+		registration, err := scanEventRegistration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan promoted registration: %w", utils.GetCurrentTypeName(), err)
+		}
+		promoted = append(promoted, *registration)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over promoted registrations: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return promoted, nil
+}
+
+// scanEventRegistration scans a single event_registrations row
+func scanEventRegistration(row interface{ Scan(dest ...any) error }) (*EventRegistration, error) {
+	var registration EventRegistration
+	err := row.Scan(
+		&registration.ID,
+		&registration.EventID,
+		&registration.UserID,
+		&registration.Status,
+		&registration.CreatedAt,
+		&registration.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+// ForumTopic represents a row in the forum_topics table, a topic of the club chat
+type ForumTopic struct {
+	TopicID        int
+	Title          string
+	IconColor      int
+	IconEmojiID    string
+	IsClosed       bool
+	IsHidden       bool
+	TopicCreatedAt sql.NullTime // empty for topics known only from service messages
+	UpdatedAt      time.Time
+}
+
+// ForumTopicRepository stores the topics of the club chat
+type ForumTopicRepository interface {
+	GetAll() ([]ForumTopic, error)
+	Upsert(topic *ForumTopic) error
+	DeleteAllExcept(topicIDs []int) (int64, error)
+}
+
+// Ensure PostgresForumTopicRepository implements ForumTopicRepository interface
+var _ ForumTopicRepository = (*PostgresForumTopicRepository)(nil)
+
+// PostgresForumTopicRepository handles database operations for forum topics
+type PostgresForumTopicRepository struct {
+	db *sql.DB
+}
+
+// NewForumTopicRepository creates a new PostgresForumTopicRepository
+func NewForumTopicRepository(db *sql.DB) *PostgresForumTopicRepository {
+	return &PostgresForumTopicRepository{db: db}
+}
+
+// GetAll retrieves all stored forum topics ordered by ID
+func (r *PostgresForumTopicRepository) GetAll() ([]ForumTopic, error) {
+	query := `
+		SELECT topic_id, title, icon_color, icon_emoji_id, is_closed, is_hidden, topic_created_at, updated_at
+		FROM forum_topics
+		ORDER BY topic_id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query forum topics: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var topics []ForumTopic
+	for rows.Next() {
+		var topic ForumTopic
+		if err := rows.Scan(
+			&topic.TopicID,
+			&topic.Title,
+			&topic.IconColor,
+			&topic.IconEmojiID,
+			&topic.IsClosed,
+			&topic.IsHidden,
+			&topic.TopicCreatedAt,
+			&topic.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan forum topic: %w", utils.GetCurrentTypeName(), err)
+		}
+		topics = append(topics, topic)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over forum topics: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return topics, nil
+}
+
+// Upsert inserts the forum topic or updates the stored one with the same ID.
+// A known creation date is kept when the new one is empty.
+func (r *PostgresForumTopicRepository) Upsert(topic *ForumTopic) error {
+	query := `
+		INSERT INTO forum_topics (topic_id, title, icon_color, icon_emoji_id, is_closed, is_hidden, topic_created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (topic_id) DO UPDATE SET
+			title = EXCLUDED.title,
+			icon_color = EXCLUDED.icon_color,
+			icon_emoji_id = EXCLUDED.icon_emoji_id,
+			is_closed = EXCLUDED.is_closed,
+			is_hidden = EXCLUDED.is_hidden,
+			topic_created_at = COALESCE(EXCLUDED.topic_created_at, forum_topics.topic_created_at),
+			updated_at = NOW()`
+	_, err := r.db.Exec(
+		query,
+		topic.TopicID,
+		topic.Title,
+		topic.IconColor,
+		topic.IconEmojiID,
+		topic.IsClosed,
+		topic.IsHidden,
+		topic.TopicCreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: failed to upsert forum topic %d: %w", utils.GetCurrentTypeName(), topic.TopicID, err)
+	}
+	return nil
+}
+
+// DeleteAllExcept deletes the forum topics whose IDs are not in the list, used to drop deleted topics after a sync
+func (r *PostgresForumTopicRepository) DeleteAllExcept(topicIDs []int) (int64, error) {
+	ids := make([]int64, len(topicIDs))
+	for i, id := range topicIDs {
+		ids[i] = int64(id)
+	}
+
+	result, err := r.db.Exec(`DELETE FROM forum_topics WHERE NOT (topic_id = ANY($1))`, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to delete forum topics: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: could not get rows affected after delete: %w", utils.GetCurrentTypeName(), err)
+	}
+	return rowsAffected, nil
+}
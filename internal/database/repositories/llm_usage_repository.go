@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+)
+
+// LLMUsage represents a row in the llm_usage table
+type LLMUsage struct {
+	ID               int
+	UserTgID         sql.NullInt64 // empty for calls made by scheduled tasks
+	Feature          constants.LLMFeature
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	LatencyMs        int64
+	CostUSD          float64
+	Success          bool
+	CreatedAt        time.Time
+}
+
+// LLMUsageStats represents the usage aggregated by feature or user
+type LLMUsageStats struct {
+	Key              string // feature name or user Telegram ID
+	Username         string // Telegram username, only for stats by user
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+	CostUSD          float64
+}
+
+// LLMUsageReport represents the LLM usage for a period with the limits in effect
+type LLMUsageReport struct {
+	Since            time.Time
+	Total            LLMUsageStats
+	ByFeature        []LLMUsageStats
+	ByUser           []LLMUsageStats
+	MonthCostUSD     float64
+	MonthlyBudgetUSD float64
+	UserDailyQuota   int
+}
+
+// LLMUsageRepository handles database operations for LLM usage records
+type LLMUsageRepository struct {
+	db *sql.DB
+}
+
+// NewLLMUsageRepository creates a new LLMUsageRepository
+func NewLLMUsageRepository(db *sql.DB) *LLMUsageRepository {
+	return &LLMUsageRepository{db: db}
+}
+
+// Create inserts a new LLM usage record into the database
+func (r *LLMUsageRepository) Create(usage *LLMUsage) error {
+	query := `
+		INSERT INTO llm_usage (user_tg_id, feature, model, prompt_tokens, completion_tokens, latency_ms, cost_usd, success)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.Exec(
+		query,
+		usage.UserTgID,
+		string(usage.Feature),
+		usage.Model,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		usage.LatencyMs,
+		usage.CostUSD,
+		usage.Success,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: failed to insert LLM usage: %w", utils.GetCurrentTypeName(), err)
+	}
+	return nil
+}
+
+// CountUserRequestsSince counts the LLM calls made for the user since the given time
+func (r *LLMUsageRepository) CountUserRequestsSince(userTgID int64, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM llm_usage WHERE user_tg_id = $1 AND created_at >= $2`
+	if err := r.db.QueryRow(query, userTgID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: failed to count LLM requests of user %d: %w", utils.GetCurrentTypeName(), userTgID, err)
+	}
+	return count, nil
+}
+
+// GetTotalCostSince sums the estimated cost of all LLM calls since the given time
+func (r *LLMUsageRepository) GetTotalCostSince(since time.Time) (float64, error) {
+	var cost float64
+	query := `SELECT COALESCE(SUM(cost_usd), 0) FROM llm_usage WHERE created_at >= $1`
+	if err := r.db.QueryRow(query, since).Scan(&cost); err != nil {
+		return 0, fmt.Errorf("%s: failed to sum LLM cost: %w", utils.GetCurrentTypeName(), err)
+	}
+	return cost, nil
+}
+
+// GetStatsByFeatureSince aggregates the LLM calls since the given time by feature, the most expensive first
+func (r *LLMUsageRepository) GetStatsByFeatureSince(since time.Time) ([]LLMUsageStats, error) {
+	query := `
+		SELECT feature, '', COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0), COALESCE(SUM(cost_usd), 0)
+		FROM llm_usage
+		WHERE created_at >= $1
+		GROUP BY feature
+		ORDER BY SUM(cost_usd) DESC, COUNT(*) DESC`
+	return r.queryStats(query, since)
+}
+
+// GetStatsByUserSince aggregates the LLM calls made for users since the given time, the most expensive first
+func (r *LLMUsageRepository) GetStatsByUserSince(since time.Time, limit int) ([]LLMUsageStats, error) {
+	query := `
+		SELECT lu.user_tg_id::TEXT, COALESCE(MAX(u.tg_username), ''), COUNT(*),
+			COALESCE(SUM(lu.prompt_tokens), 0), COALESCE(SUM(lu.completion_tokens), 0), COALESCE(SUM(lu.cost_usd), 0)
+		FROM llm_usage lu
+		LEFT JOIN users u ON u.tg_id = lu.user_tg_id
+		WHERE lu.created_at >= $1 AND lu.user_tg_id IS NOT NULL
+		GROUP BY lu.user_tg_id
+		ORDER BY SUM(lu.cost_usd) DESC, COUNT(*) DESC
+		LIMIT $2`
+	return r.queryStats(query, since, limit)
+}
+
+func (r *LLMUsageRepository) queryStats(query string, args ...interface{}) ([]LLMUsageStats, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query LLM usage stats: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var stats []LLMUsageStats
+	for rows.Next() {
+		var s LLMUsageStats
+		if err := rows.Scan(&s.Key, &s.Username, &s.Requests, &s.PromptTokens, &s.CompletionTokens, &s.CostUSD); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan LLM usage stats: %w", utils.GetCurrentTypeName(), err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over LLM usage stats: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,369 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+)
+
+// EventRepository is the in-memory implementation of repositories.EventRepository.
+// Topics are not kept in memory, so DeleteEvent only removes the event and its registrations.
+type EventRepository struct {
+	store *Store
+}
+
+// Ensure EventRepository implements repositories.EventRepository interface
+var _ repositories.EventRepository = (*EventRepository)(nil)
+
+func (r *EventRepository) CreateEvent(name string, eventType constants.EventType) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(name, eventType, nil, "UTC"), nil
+}
+
+func (r *EventRepository) CreateEventWithStartedAt(name string, eventType constants.EventType, startedAt time.Time) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(name, eventType, &startedAt, startedAt.Location().String()), nil
+}
+
+// GetLastActualEvents returns the actual events, the earliest first and the ones without start time last
+func (r *EventRepository) GetLastActualEvents(limit int) ([]repositories.Event, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.list(limit, func(e *repositories.Event) bool { return e.Status == string(constants.EventStatusActual) }, false), nil
+}
+
+// GetLastEvents returns the events, the latest first and the ones without start time last
+func (r *EventRepository) GetLastEvents(limit int) ([]repositories.Event, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.list(limit, func(e *repositories.Event) bool { return true }, true), nil
+}
+
+func (r *EventRepository) UpdateEventName(id int, newName string) error {
+	return r.updateEvent(id, "", func(e *repositories.Event) { e.Name = newName })
+}
+
+func (r *EventRepository) UpdateEventStatus(id int, status constants.EventStatus) error {
+	return r.updateEvent(id, " status", func(e *repositories.Event) { e.Status = string(status) })
+}
+
+func (r *EventRepository) UpdateEventStartedAt(id int, startedAt time.Time) error {
+	return r.updateEvent(id, " started_at", func(e *repositories.Event) {
+		e.StartedAt = &startedAt
+		e.Timezone = startedAt.Location().String()
+	})
+}
+
+func (r *EventRepository) UpdateEventCapacity(id int, capacity *int) ([]repositories.EventRegistration, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.events[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: no event found with ID %d to update capacity", utils.GetCurrentTypeName(), id)
+	}
+	if capacity != nil {
+		value := *capacity
+		capacity = &value
+	}
+	event.Capacity = capacity
+	event.UpdatedAt = r.store.now()
+
+	return r.promoteFromWaitlist(id, capacity), nil
+}
+
+func (r *EventRepository) UpdateEventType(id int, eventType constants.EventType) error {
+	return r.updateEvent(id, " type", func(e *repositories.Event) { e.Type = string(eventType) })
+}
+
+func (r *EventRepository) DeleteEvent(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.events[id]; !ok {
+		return fmt.Errorf("%s: no event found with ID %d to delete", utils.GetCurrentTypeName(), id)
+	}
+	delete(r.store.events, id)
+	for registrationID, registration := range r.store.registrations {
+		if registration.EventID == id {
+			delete(r.store.registrations, registrationID)
+		}
+	}
+
+	return nil
+}
+
+func (r *EventRepository) GetEventByID(id int) (*repositories.Event, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.events[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: no event found with ID %d", utils.GetCurrentTypeName(), id)
+	}
+	return copyEvent(event), nil
+}
+
+func (r *EventRepository) GetEventRegistration(eventID int, userID int) (*repositories.EventRegistration, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	registration := r.findRegistration(eventID, userID)
+	if registration == nil {
+		return nil, sql.ErrNoRows
+	}
+	found := *registration
+	return &found, nil
+}
+
+func (r *EventRepository) CountEventRegistrations(eventID int) (going int, waitlist int, err error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, registration := range r.store.registrations {
+		if registration.EventID != eventID {
+			continue
+		}
+		switch registration.Status {
+		case string(constants.EventRegistrationStatusGoing):
+			going++
+		case string(constants.EventRegistrationStatusWaitlist):
+			waitlist++
+		}
+	}
+	return going, waitlist, nil
+}
+
+func (r *EventRepository) RegisterForEvent(eventID int, userID int) (*repositories.EventRegistration, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.events[eventID]
+	if !ok {
+		return nil, fmt.Errorf("%s: no event found with ID %d", utils.GetCurrentTypeName(), eventID)
+	}
+
+	if existing := r.findRegistration(eventID, userID); existing != nil {
+		found := *existing
+		return &found, nil
+	}
+
+	if _, ok := r.store.users[userID]; !ok {
+		return nil, fmt.Errorf("%s: failed to insert registration: %w", utils.GetCurrentTypeName(), errForeignKeyViolation)
+	}
+
+	status := constants.EventRegistrationStatusGoing
+	if event.Capacity != nil && r.countGoing(eventID) >= *event.Capacity {
+		status = constants.EventRegistrationStatusWaitlist
+	}
+
+	now := r.store.now()
+	registration := &repositories.EventRegistration{
+		ID:        r.store.nextID(),
+		EventID:   eventID,
+		UserID:    userID,
+		Status:    string(status),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.store.registrations[registration.ID] = registration
+
+	created := *registration
+	return &created, nil
+}
+
+func (r *EventRepository) CancelEventRegistration(eventID int, userID int) ([]repositories.EventRegistration, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.events[eventID]
+	if !ok {
+		return nil, fmt.Errorf("%s: no event found with ID %d", utils.GetCurrentTypeName(), eventID)
+	}
+
+	registration := r.findRegistration(eventID, userID)
+	if registration == nil {
+		return nil, sql.ErrNoRows
+	}
+	delete(r.store.registrations, registration.ID)
+
+	if registration.Status != string(constants.EventRegistrationStatusGoing) {
+		return nil, nil
+	}
+	return r.promoteFromWaitlist(eventID, event.Capacity), nil
+}
+
+// GetEventRegistrationsWithUsers returns the registrations, going first, each group in registration order
+func (r *EventRepository) GetEventRegistrationsWithUsers(eventID int) ([]repositories.EventRegistrationWithUser, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var registrations []repositories.EventRegistrationWithUser
+	for _, registration := range r.sortedRegistrations(eventID) {
+		user := *r.store.users[registration.UserID]
+
+		var publishedMessageID sql.NullInt64
+		for _, id := range sortedKeys(r.store.profiles) {
+			if profile := r.store.profiles[id]; profile.UserID == user.ID {
+				publishedMessageID = profile.PublishedMessageID
+				break
+			}
+		}
+
+		found := *registration
+		registrations = append(registrations, repositories.EventRegistrationWithUser{
+			Registration:              &found,
+			User:                      &user,
+			ProfilePublishedMessageID: publishedMessageID,
+		})
+	}
+
+	sort.SliceStable(registrations, func(i, j int) bool {
+		return registrations[i].Registration.Status == string(constants.EventRegistrationStatusGoing) &&
+			registrations[j].Registration.Status != string(constants.EventRegistrationStatusGoing)
+	})
+
+	return registrations, nil
+}
+
+func (r *EventRepository) create(name string, eventType constants.EventType, startedAt *time.Time, timezone string) int {
+	now := r.store.now()
+	event := &repositories.Event{
+		ID:        r.store.nextID(),
+		Name:      name,
+		Type:      string(eventType),
+		Status:    string(constants.EventStatusActual),
+		StartedAt: startedAt,
+		Timezone:  timezone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.store.events[event.ID] = event
+	return event.ID
+}
+
+// list returns up to limit events matching the filter sorted by start time, events without start time go last
+func (r *EventRepository) list(limit int, match func(e *repositories.Event) bool, latestFirst bool) []repositories.Event {
+	var events []repositories.Event
+	for _, id := range sortedKeys(r.store.events) {
+		if event := r.store.events[id]; match(event) {
+			events = append(events, *copyEvent(event))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].StartedAt, events[j].StartedAt
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if latestFirst {
+			return a.After(*b)
+		}
+		return a.Before(*b)
+	})
+
+	if limit >= 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
+
+// updateEvent applies the change to the event with the ID, what names the updated field in the error
+func (r *EventRepository) updateEvent(id int, what string, change func(e *repositories.Event)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.events[id]
+	if !ok {
+		return fmt.Errorf("%s: no event found with ID %d to update%s", utils.GetCurrentTypeName(), id, what)
+	}
+	change(event)
+	event.UpdatedAt = r.store.now()
+
+	return nil
+}
+
+func (r *EventRepository) findRegistration(eventID int, userID int) *repositories.EventRegistration {
+	for _, registration := range r.store.registrations {
+		if registration.EventID == eventID && registration.UserID == userID {
+			return registration
+		}
+	}
+	return nil
+}
+
+func (r *EventRepository) countGoing(eventID int) int {
+	going := 0
+	for _, registration := range r.store.registrations {
+		if registration.EventID == eventID && registration.Status == string(constants.EventRegistrationStatusGoing) {
+			going++
+		}
+	}
+	return going
+}
+
+// sortedRegistrations returns the registrations for the event in registration order
+func (r *EventRepository) sortedRegistrations(eventID int) []*repositories.EventRegistration {
+	var registrations []*repositories.EventRegistration
+	for _, id := range sortedKeys(r.store.registrations) {
+		if registration := r.store.registrations[id]; registration.EventID == eventID {
+			registrations = append(registrations, registration)
+		}
+	}
+	sort.SliceStable(registrations, func(i, j int) bool {
+		return registrations[i].CreatedAt.Before(registrations[j].CreatedAt)
+	})
+	return registrations
+}
+
+// promoteFromWaitlist moves the earliest waitlisted registrations to going while there are free places
+func (r *EventRepository) promoteFromWaitlist(eventID int, capacity *int) []repositories.EventRegistration {
+	// Without capacity everybody from the waitlist gets a place
+	freePlaces := -1
+	if capacity != nil {
+		freePlaces = *capacity - r.countGoing(eventID)
+		if freePlaces <= 0 {
+			return nil
+		}
+	}
+
+	var promoted []repositories.EventRegistration
+	for _, registration := range r.sortedRegistrations(eventID) {
+		if freePlaces == 0 {
+			break
+		}
+		if registration.Status != string(constants.EventRegistrationStatusWaitlist) {
+			continue
+		}
+		registration.Status = string(constants.EventRegistrationStatusGoing)
+		registration.UpdatedAt = r.store.now()
+		promoted = append(promoted, *registration)
+		freePlaces--
+	}
+	return promoted
+}
+
+// copyEvent copies the event with its pointer fields
+func copyEvent(event *repositories.Event) *repositories.Event {
+	found := *event
+	if event.StartedAt != nil {
+		startedAt := *event.StartedAt
+		found.StartedAt = &startedAt
+	}
+	if event.Capacity != nil {
+		capacity := *event.Capacity
+		found.Capacity = &capacity
+	}
+	return &found
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"slices"
+
+	"evo-bot-go/internal/database/repositories"
+)
+
+// ForumTopicRepository is the in-memory implementation of repositories.ForumTopicRepository
+type ForumTopicRepository struct {
+	store *Store
+}
+
+// Ensure ForumTopicRepository implements repositories.ForumTopicRepository interface
+var _ repositories.ForumTopicRepository = (*ForumTopicRepository)(nil)
+
+func (r *ForumTopicRepository) GetAll() ([]repositories.ForumTopic, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var topics []repositories.ForumTopic
+	for _, id := range sortedKeys(r.store.forumTopics) {
+		topics = append(topics, *r.store.forumTopics[id])
+	}
+	return topics, nil
+}
+
+func (r *ForumTopicRepository) Upsert(topic *repositories.ForumTopic) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored := *topic
+	if existing, ok := r.store.forumTopics[topic.TopicID]; ok && !stored.TopicCreatedAt.Valid {
+		stored.TopicCreatedAt = existing.TopicCreatedAt
+	}
+	stored.UpdatedAt = r.store.now()
+	r.store.forumTopics[topic.TopicID] = &stored
+	return nil
+}
+
+func (r *ForumTopicRepository) DeleteAllExcept(topicIDs []int) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for id := range r.store.forumTopics {
+		if !slices.Contains(topicIDs, id) {
+			delete(r.store.forumTopics, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// ProfileRepository is the in-memory implementation of repositories.ProfileRepository
+type ProfileRepository struct {
+	store *Store
+}
+
+// Ensure ProfileRepository implements repositories.ProfileRepository interface
+var _ repositories.ProfileRepository = (*ProfileRepository)(nil)
+
+func (r *ProfileRepository) GetByID(id int) (*repositories.Profile, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	profile, ok := r.store.profiles[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return copyProfile(profile), nil
+}
+
+func (r *ProfileRepository) Create(userID int, bio string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(userID, bio)
+}
+
+func (r *ProfileRepository) Update(id int, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: no fields to update for profile with ID %d", utils.GetCurrentTypeName(), id)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	profile, ok := r.store.profiles[id]
+	if !ok {
+		return fmt.Errorf("%s: no profile found with ID %d to update", utils.GetCurrentTypeName(), id)
+	}
+
+	// Apply to a copy, so a failed update changes nothing like a failed statement
+	updated := *profile
+	for key, value := range fields {
+		if err := setProfileField(&updated, key, value); err != nil {
+			return fmt.Errorf("%s: failed to update profile with ID %d: %w", utils.GetCurrentTypeName(), id, err)
+		}
+	}
+	updated.UpdatedAt = r.store.now()
+	*profile = updated
+
+	return nil
+}
+
+func (r *ProfileRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.profiles[id]; !ok {
+		return fmt.Errorf("%s: no profile found with ID %d to delete", utils.GetCurrentTypeName(), id)
+	}
+	delete(r.store.profiles, id)
+
+	return nil
+}
+
+func (r *ProfileRepository) UpdatePublishedMessageID(profileID int, messageID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	profile, ok := r.store.profiles[profileID]
+	if !ok {
+		return fmt.Errorf("%s: no profile found with ID %d to update published_message_id", utils.GetCurrentTypeName(), profileID)
+	}
+	profile.PublishedMessageID = sql.NullInt64{Int64: messageID, Valid: true}
+	profile.UpdatedAt = r.store.now()
+
+	return nil
+}
+
+func (r *ProfileRepository) GetOrCreateWithBio(userID int, bio string) (*repositories.Profile, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.getOrCreateWithBio(userID, bio)
+}
+
+func (r *ProfileRepository) GetOrCreate(userID int) (*repositories.Profile, error) {
+	return r.GetOrCreateWithBio(userID, "")
+}
+
+func (r *ProfileRepository) GetOrFullCreate(user *gotgbot.User) (*repositories.Profile, error) {
+	_, profile, err := (&UserRepository{store: r.store}).GetOrFullCreate(user)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get user in GetOrFullCreate: %w", utils.GetCurrentTypeName(), err)
+	}
+	return profile, nil
+}
+
+// GetAllWithUsers returns the profiles with a bio, the most recently updated first
+func (r *ProfileRepository) GetAllWithUsers() ([]repositories.ProfileWithUser, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var profiles []repositories.ProfileWithUser
+	for _, profile := range r.store.profiles {
+		if profile.Bio == "" {
+			continue
+		}
+		user := *r.store.users[profile.UserID]
+		profiles = append(profiles, repositories.ProfileWithUser{Profile: copyProfile(profile), User: &user})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		if !profiles[i].Profile.UpdatedAt.Equal(profiles[j].Profile.UpdatedAt) {
+			return profiles[i].Profile.UpdatedAt.After(profiles[j].Profile.UpdatedAt)
+		}
+		return profiles[i].Profile.ID > profiles[j].Profile.ID
+	})
+
+	return profiles, nil
+}
+
+func (r *ProfileRepository) create(userID int, bio string) (int, error) {
+	if _, ok := r.store.users[userID]; !ok {
+		return 0, fmt.Errorf("%s: failed to insert profile: %w", utils.GetCurrentTypeName(), errForeignKeyViolation)
+	}
+
+	now := r.store.now()
+	profile := &repositories.Profile{
+		ID:          r.store.nextID(),
+		UserID:      userID,
+		Bio:         bio,
+		Skills:      repositories.ProfileTags{},
+		CanHelpWith: repositories.ProfileTags{},
+		LookingFor:  repositories.ProfileTags{},
+		Languages:   repositories.ProfileTags{},
+		Links:       repositories.ProfileLinks{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.store.profiles[profile.ID] = profile
+
+	return profile.ID, nil
+}
+
+func (r *ProfileRepository) getOrCreateWithBio(userID int, bio string) (*repositories.Profile, error) {
+	for _, id := range sortedKeys(r.store.profiles) {
+		if profile := r.store.profiles[id]; profile.UserID == userID {
+			return copyProfile(profile), nil
+		}
+	}
+
+	if _, ok := r.store.users[userID]; !ok {
+		return nil, fmt.Errorf("%s: user with ID %d not found, cannot create profile", utils.GetCurrentTypeName(), userID)
+	}
+
+	id, err := r.create(userID, bio)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create profile in GetOrCreateWithBio: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return copyProfile(r.store.profiles[id]), nil
+}
+
+// copyProfile copies the profile with its tags and links, so callers cannot change the stored one
+func copyProfile(profile *repositories.Profile) *repositories.Profile {
+	found := *profile
+	found.Skills = slices.Clone(profile.Skills)
+	found.CanHelpWith = slices.Clone(profile.CanHelpWith)
+	found.LookingFor = slices.Clone(profile.LookingFor)
+	found.Languages = slices.Clone(profile.Languages)
+	found.Links = maps.Clone(profile.Links)
+	return &found
+}
+
+// setProfileField sets the field by its column name, like the dynamic UPDATE of the database repository
+func setProfileField(profile *repositories.Profile, column string, value interface{}) error {
+	switch column {
+	case "bio":
+		bio, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value %v for column %q", value, column)
+		}
+		profile.Bio = bio
+	case "city", "country", "job_title", "company", "photo_file_id":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value %v for column %q", value, column)
+		}
+		switch column {
+		case "city":
+			profile.City = text
+		case "country":
+			profile.Country = text
+		case "job_title":
+			profile.JobTitle = text
+		case "company":
+			profile.Company = text
+		case "photo_file_id":
+			profile.PhotoFileID = text
+		}
+	case "skills", "can_help_with", "looking_for", "languages":
+		var tags repositories.ProfileTags
+		switch v := value.(type) {
+		case repositories.ProfileTags:
+			tags = v
+		case []string:
+			tags = v
+		default:
+			return fmt.Errorf("invalid value %v for column %q", value, column)
+		}
+		// NULL is not allowed and an empty array is read back as empty, not nil
+		tags = append(repositories.ProfileTags{}, tags...)
+		switch column {
+		case "skills":
+			profile.Skills = tags
+		case "can_help_with":
+			profile.CanHelpWith = tags
+		case "looking_for":
+			profile.LookingFor = tags
+		case "languages":
+			profile.Languages = tags
+		}
+	case "links":
+		var links repositories.ProfileLinks
+		switch v := value.(type) {
+		case repositories.ProfileLinks:
+			links = v
+		case map[string]string:
+			links = v
+		default:
+			return fmt.Errorf("invalid value %v for column %q", value, column)
+		}
+		profile.Links = maps.Clone(links)
+		if profile.Links == nil {
+			profile.Links = repositories.ProfileLinks{}
+		}
+	case "published_with_photo":
+		withPhoto, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("invalid value %v for column %q", value, column)
+		}
+		profile.PublishedWithPhoto = withPhoto
+	case "published_message_id", "published_extra_message_id":
+		var messageID sql.NullInt64
+		switch v := value.(type) {
+		case nil:
+		case int64:
+			messageID = sql.NullInt64{Int64: v, Valid: true}
+		case int:
+			messageID = sql.NullInt64{Int64: int64(v), Valid: true}
+		case sql.NullInt64:
+			messageID = v
+		default:
+			return fmt.Errorf("invalid value %v for column %q", value, column)
+		}
+		if column == "published_message_id" {
+			profile.PublishedMessageID = messageID
+		} else {
+			profile.PublishedExtraMessageID = messageID
+		}
+	default:
+		return fmt.Errorf("column %q of relation \"profiles\" does not exist", column)
+	}
+	return nil
+}
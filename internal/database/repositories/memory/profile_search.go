@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"evo-bot-go/internal/database/repositories"
+)
+
+// Search approximates the full-text search of the database repository: the query words are matched
+// by their prefixes instead of the stems and the names by the trigram word similarity like pg_trgm does
+func (r *ProfileRepository) Search(filter repositories.ProfileSearchFilter, limit, offset int) ([]repositories.ProfileWithUser, int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	type found struct {
+		repositories.ProfileWithUser
+		rank float64
+	}
+
+	query := parseSearchQuery(filter.Query)
+	var matched []found
+	for _, profile := range r.store.profiles {
+		user := r.store.users[profile.UserID]
+		if profile.Bio == "" ||
+			(filter.Tag != "" && !hasProfileTag(profile, filter.Tag)) ||
+			(filter.City != "" && !strings.EqualFold(profile.City, filter.City)) ||
+			(filter.IsClubMember != nil && user.IsClubMember != *filter.IsClubMember) {
+			continue
+		}
+
+		rank := 0.0
+		if filter.Query != "" {
+			nameSimilarity := 0.0
+			if len([]rune(filter.Query)) >= repositories.ProfileNameMinQueryLength {
+				nameSimilarity = wordSimilarity(filter.Query, strings.Join([]string{user.Firstname, user.Lastname, user.TgUsername}, " "))
+			}
+			textRank, ok := query.rank(profile)
+			if !ok && nameSimilarity < repositories.ProfileNameSimilarityThreshold {
+				continue
+			}
+			rank = textRank + nameSimilarity
+		}
+
+		userCopy := *user
+		matched = append(matched, found{
+			ProfileWithUser: repositories.ProfileWithUser{Profile: copyProfile(profile), User: &userCopy},
+			rank:            rank,
+		})
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].rank != matched[j].rank {
+			return matched[i].rank > matched[j].rank
+		}
+		if !matched[i].Profile.UpdatedAt.Equal(matched[j].Profile.UpdatedAt) {
+			return matched[i].Profile.UpdatedAt.After(matched[j].Profile.UpdatedAt)
+		}
+		return matched[i].Profile.ID > matched[j].Profile.ID
+	})
+
+	var profiles []repositories.ProfileWithUser
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		profiles = append(profiles, matched[i].ProfileWithUser)
+	}
+	return profiles, len(matched), nil
+}
+
+func hasProfileTag(profile *repositories.Profile, tag string) bool {
+	for _, tags := range []repositories.ProfileTags{profile.Skills, profile.CanHelpWith, profile.LookingFor, profile.Languages} {
+		for _, t := range tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchQuery is a websearch_to_tsquery query: the groups are joined by "or", the words of a group by "and"
+type searchQuery [][]searchTerm
+
+type searchTerm struct {
+	prefix  string
+	exclude bool
+}
+
+func parseSearchQuery(query string) searchQuery {
+	var groups searchQuery
+	var group []searchTerm
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if field == "or" {
+			if len(group) > 0 {
+				groups = append(groups, group)
+			}
+			group = nil
+			continue
+		}
+		exclude := strings.HasPrefix(field, "-")
+		for _, word := range searchWords(field) {
+			group = append(group, searchTerm{prefix: searchStem(word), exclude: exclude})
+		}
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// rank returns the rank of the profile for the query and whether the profile matches it,
+// the fields are weighted like the search_vector of the profiles
+func (q searchQuery) rank(profile *repositories.Profile) (float64, bool) {
+	weighted := []struct {
+		weight float64
+		words  []string
+	}{
+		{1.0, searchWords(strings.Join(slices.Concat([]string{profile.JobTitle, profile.Company}, profile.Skills), " "))},
+		{0.4, searchWords(strings.Join(slices.Concat([]string{profile.City, profile.Country},
+			profile.CanHelpWith, profile.LookingFor, profile.Languages), " "))},
+		{0.2, searchWords(profile.Bio)},
+	}
+
+	rank := 0.0
+	matches := false
+	for _, group := range q {
+		groupRank := 0.0
+		groupMatches := true
+		for _, term := range group {
+			termRank := 0.0
+			for _, field := range weighted {
+				for _, word := range field.words {
+					if strings.HasPrefix(word, term.prefix) {
+						termRank = max(termRank, field.weight)
+					}
+				}
+			}
+			if (termRank > 0) == term.exclude {
+				groupMatches = false
+				break
+			}
+			groupRank += termRank
+		}
+		if groupMatches {
+			matches = true
+			rank = max(rank, groupRank)
+		}
+	}
+	return rank, matches
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchStem cuts the ending of the word, so the word matches its other forms like a stem does
+func searchStem(word string) string {
+	runes := []rune(word)
+	switch {
+	case len(runes) > 5:
+		return string(runes[:len(runes)-2])
+	case len(runes) > 3:
+		return string(runes[:len(runes)-1])
+	}
+	return word
+}
+
+// wordSimilarity is the word_similarity of pg_trgm: the share of the trigrams of the query found in the text
+func wordSimilarity(query, text string) float64 {
+	queryTrigrams := trigrams(query)
+	if len(queryTrigrams) == 0 {
+		return 0
+	}
+	textTrigrams := trigrams(text)
+	shared := 0
+	for trigram := range queryTrigrams {
+		if textTrigrams[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(queryTrigrams))
+}
+
+// trigrams returns the trigrams of the words padded with two spaces in front and one after, like pg_trgm
+func trigrams(text string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range searchWords(text) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+)
+
+// RandomCoffeePollRepository is the in-memory implementation of repositories.RandomCoffeePollRepository
+type RandomCoffeePollRepository struct {
+	store *Store
+}
+
+// Ensure RandomCoffeePollRepository implements repositories.RandomCoffeePollRepository interface
+var _ repositories.RandomCoffeePollRepository = (*RandomCoffeePollRepository)(nil)
+
+func (r *RandomCoffeePollRepository) CreatePoll(poll repositories.RandomCoffeePoll) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.polls {
+		if existing.TelegramPollID == poll.TelegramPollID {
+			return 0, fmt.Errorf("%s: failed to create poll: %w", utils.GetCurrentTypeName(), errUniqueViolation)
+		}
+	}
+
+	if poll.CreatedAt.IsZero() {
+		poll.CreatedAt = r.store.now()
+	}
+	poll.ID = int64(r.store.nextID())
+	r.store.polls[poll.ID] = &poll
+
+	return poll.ID, nil
+}
+
+func (r *RandomCoffeePollRepository) GetPollByTelegramPollID(telegramPollID string) (*repositories.RandomCoffeePoll, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, poll := range r.store.polls {
+		if poll.TelegramPollID == telegramPollID {
+			found := *poll
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *RandomCoffeePollRepository) GetLatestPoll() (*repositories.RandomCoffeePoll, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	polls := r.store.latestPolls()
+	if len(polls) == 0 {
+		return nil, nil
+	}
+	found := *polls[0]
+	return &found, nil
+}
+
+// latestPolls returns the polls by week start date, the latest first
+func (s *Store) latestPolls() []*repositories.RandomCoffeePoll {
+	var polls []*repositories.RandomCoffeePoll
+	for _, id := range sortedKeys(s.polls) {
+		polls = append(polls, s.polls[id])
+	}
+	sort.SliceStable(polls, func(i, j int) bool {
+		if !polls[i].WeekStartDate.Equal(polls[j].WeekStartDate) {
+			return polls[i].WeekStartDate.After(polls[j].WeekStartDate)
+		}
+		return polls[i].ID > polls[j].ID
+	})
+	return polls
+}
+
+// RandomCoffeeParticipantRepository is the in-memory implementation of repositories.RandomCoffeeParticipantRepository
+type RandomCoffeeParticipantRepository struct {
+	store *Store
+}
+
+// Ensure RandomCoffeeParticipantRepository implements repositories.RandomCoffeeParticipantRepository interface
+var _ repositories.RandomCoffeeParticipantRepository = (*RandomCoffeeParticipantRepository)(nil)
+
+func (r *RandomCoffeeParticipantRepository) UpsertParticipant(participant repositories.RandomCoffeeParticipant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing := r.find(participant.PollID, participant.UserID); existing != nil {
+		existing.IsParticipating = participant.IsParticipating
+		existing.UpdatedAt = r.store.now()
+		return nil
+	}
+
+	if _, ok := r.store.polls[participant.PollID]; !ok {
+		return errForeignKeyViolation
+	}
+	if _, ok := r.store.users[int(participant.UserID)]; !ok {
+		return errForeignKeyViolation
+	}
+
+	now := r.store.now()
+	participant.ID = int64(r.store.nextID())
+	participant.CreatedAt = now
+	participant.UpdatedAt = now
+	r.store.participants[participant.ID] = &participant
+
+	return nil
+}
+
+func (r *RandomCoffeeParticipantRepository) RemoveParticipant(pollID int64, userID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing := r.find(pollID, userID); existing != nil {
+		delete(r.store.participants, existing.ID)
+	}
+	return nil
+}
+
+func (r *RandomCoffeeParticipantRepository) GetParticipant(pollID int64, userID int64) (*repositories.RandomCoffeeParticipant, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing := r.find(pollID, userID)
+	if existing == nil {
+		return nil, nil
+	}
+	found := *existing
+	return &found, nil
+}
+
+// GetParticipatingUsers returns the participating users with their ID, Telegram ID and names only
+func (r *RandomCoffeeParticipantRepository) GetParticipatingUsers(pollID int64) ([]repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var users []repositories.User
+	for _, id := range sortedKeys(r.store.participants) {
+		participant := r.store.participants[id]
+		if participant.PollID != pollID || !participant.IsParticipating {
+			continue
+		}
+		user := r.store.users[int(participant.UserID)]
+		users = append(users, repositories.User{
+			ID:         user.ID,
+			TgID:       user.TgID,
+			Firstname:  user.Firstname,
+			Lastname:   user.Lastname,
+			TgUsername: user.TgUsername,
+		})
+	}
+	return users, nil
+}
+
+func (r *RandomCoffeeParticipantRepository) find(pollID int64, userID int64) *repositories.RandomCoffeeParticipant {
+	for _, participant := range r.store.participants {
+		if participant.PollID == pollID && participant.UserID == userID {
+			return participant
+		}
+	}
+	return nil
+}
+
+// RandomCoffeePairRepository is the in-memory implementation of repositories.RandomCoffeePairRepository
+type RandomCoffeePairRepository struct {
+	store *Store
+}
+
+// Ensure RandomCoffeePairRepository implements repositories.RandomCoffeePairRepository interface
+var _ repositories.RandomCoffeePairRepository = (*RandomCoffeePairRepository)(nil)
+
+func (r *RandomCoffeePairRepository) CreatePair(pollID int, user1ID, user2ID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.polls[int64(pollID)]; !ok {
+		return fmt.Errorf("error creating random coffee pair: %w", errForeignKeyViolation)
+	}
+	for _, userID := range []int{user1ID, user2ID} {
+		if _, ok := r.store.users[userID]; !ok {
+			return fmt.Errorf("error creating random coffee pair: %w", errForeignKeyViolation)
+		}
+	}
+	for _, pair := range r.store.pairs {
+		if pair.PollID == pollID && pair.User1ID == int64(user1ID) && pair.User2ID == int64(user2ID) {
+			return fmt.Errorf("error creating random coffee pair: %w", errUniqueViolation)
+		}
+	}
+
+	pair := &repositories.RandomCoffeePair{
+		ID:        r.store.nextID(),
+		PollID:    pollID,
+		User1ID:   int64(user1ID),
+		User2ID:   int64(user2ID),
+		CreatedAt: r.store.now(),
+	}
+	r.store.pairs[pair.ID] = pair
+
+	return nil
+}
+
+// GetPairsHistoryForUsers returns the poll IDs of the pairs with the users, keyed by "user1-user2" in both directions.
+// Like the query of the database repository, it takes the latest pairs with the users, as many as all pairs of the last N polls.
+func (r *RandomCoffeePairRepository) GetPairsHistoryForUsers(userIDs []int, lastNPolls int) (map[string][]int, error) {
+	pairHistory := make(map[string][]int)
+	if len(userIDs) == 0 {
+		return pairHistory, nil
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	requested := make(map[int64]bool, len(userIDs))
+	for _, userID := range userIDs {
+		requested[int64(userID)] = true
+	}
+
+	limit := 0
+	for _, poll := range r.lastPolls(lastNPolls) {
+		for _, pair := range r.store.pairs {
+			if pair.PollID == int(poll.ID) {
+				limit++
+			}
+		}
+	}
+
+	for _, pair := range r.latestPairs() {
+		if limit == 0 {
+			break
+		}
+		if !requested[pair.User1ID] && !requested[pair.User2ID] {
+			continue
+		}
+		key1 := fmt.Sprintf("%d-%d", pair.User1ID, pair.User2ID)
+		key2 := fmt.Sprintf("%d-%d", pair.User2ID, pair.User1ID)
+		pairHistory[key1] = append(pairHistory[key1], pair.PollID)
+		pairHistory[key2] = append(pairHistory[key2], pair.PollID)
+		limit--
+	}
+
+	return pairHistory, nil
+}
+
+// GetMostRecentPairPoll returns the latest of the last N polls where the users were paired, or 0 if never paired
+func (r *RandomCoffeePairRepository) GetMostRecentPairPoll(user1ID, user2ID int, lastNPolls int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	inLastPolls := make(map[int]bool)
+	for _, poll := range r.lastPolls(lastNPolls) {
+		inLastPolls[int(poll.ID)] = true
+	}
+
+	for _, pair := range r.latestPairs() {
+		samePair := (pair.User1ID == int64(user1ID) && pair.User2ID == int64(user2ID)) ||
+			(pair.User1ID == int64(user2ID) && pair.User2ID == int64(user1ID))
+		if samePair && inLastPolls[pair.PollID] {
+			return pair.PollID, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *RandomCoffeePairRepository) GetPairByPollAndUser(pollID int, userID int) (*repositories.RandomCoffeePair, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range sortedKeys(r.store.pairs) {
+		pair := r.store.pairs[id]
+		if pair.PollID == pollID && (pair.User1ID == int64(userID) || pair.User2ID == int64(userID)) {
+			found := *pair
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// lastPolls returns the last N polls by week start date
+func (r *RandomCoffeePairRepository) lastPolls(n int) []*repositories.RandomCoffeePoll {
+	polls := r.store.latestPolls()
+	if len(polls) > n {
+		polls = polls[:n]
+	}
+	return polls
+}
+
+// latestPairs returns the pairs by the week start date of their poll, the latest first
+func (r *RandomCoffeePairRepository) latestPairs() []*repositories.RandomCoffeePair {
+	var pairs []*repositories.RandomCoffeePair
+	for _, id := range sortedKeys(r.store.pairs) {
+		pairs = append(pairs, r.store.pairs[id])
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return r.store.polls[int64(pairs[i].PollID)].WeekStartDate.After(r.store.polls[int64(pairs[j].PollID)].WeekStartDate)
+	})
+	return pairs
+}
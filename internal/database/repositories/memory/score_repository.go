@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+)
+
+// ScoreRepository is the in-memory implementation of repositories.ScoreRepository
+type ScoreRepository struct {
+	store *Store
+}
+
+// Ensure ScoreRepository implements repositories.ScoreRepository interface
+var _ repositories.ScoreRepository = (*ScoreRepository)(nil)
+
+func (r *ScoreRepository) AddEntry(userID int, points int, reason constants.ScoreReason, reference string, comment string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return false, fmt.Errorf("%s: failed to insert score ledger entry for user %d: %w", utils.GetCurrentTypeName(), userID, errForeignKeyViolation)
+	}
+	if r.hasEntry(userID, reason, reference) {
+		return false, nil
+	}
+
+	r.addEntry(user, points, reason, reference, comment)
+	return true, nil
+}
+
+func (r *ScoreRepository) GetHistory(userID int, limit int) ([]repositories.ScoreLedgerEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var entries []repositories.ScoreLedgerEntry
+	for _, id := range sortedKeys(r.store.scoreLedger) {
+		if entry := r.store.scoreLedger[id]; entry.UserID == userID {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (r *ScoreRepository) GetLeaderboard(limit int) ([]repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var users []repositories.User
+	for _, id := range sortedKeys(r.store.users) {
+		if user := r.store.users[id]; user.Score > 0 && user.IsClubMember {
+			users = append(users, *user)
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].Score > users[j].Score })
+
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *ScoreRepository) GetRank(userID int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Like the comparison with NULL in the database, an unknown user is first
+	rank := 1
+	user, ok := r.store.users[userID]
+	if !ok {
+		return rank, nil
+	}
+	for _, other := range r.store.users {
+		if other.IsClubMember && other.Score > user.Score {
+			rank++
+		}
+	}
+	return rank, nil
+}
+
+func (r *ScoreRepository) ApplyDecay(percent int, reference string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	affected := 0
+	for _, id := range sortedKeys(r.store.users) {
+		user := r.store.users[id]
+		decay := int(math.Floor(float64(user.Score) * float64(percent) / 100))
+		if decay < 1 || r.hasEntry(user.ID, constants.ScoreReasonDecay, reference) {
+			continue
+		}
+
+		r.addEntry(user, -decay, constants.ScoreReasonDecay, reference, fmt.Sprintf("Снижение на %d%%", percent))
+		affected++
+	}
+	return affected, nil
+}
+
+func (r *ScoreRepository) GetLastDecayReference() (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	last := ""
+	for _, entry := range r.store.scoreLedger {
+		if entry.Reason == string(constants.ScoreReasonDecay) && entry.Reference > last {
+			last = entry.Reference
+		}
+	}
+	return last, nil
+}
+
+// hasEntry reports whether the activity is in the ledger already, entries without a reference are never unique
+func (r *ScoreRepository) hasEntry(userID int, reason constants.ScoreReason, reference string) bool {
+	if reference == "" {
+		return false
+	}
+	for _, entry := range r.store.scoreLedger {
+		if entry.UserID == userID && entry.Reason == string(reason) && entry.Reference == reference {
+			return true
+		}
+	}
+	return false
+}
+
+// addEntry adds the ledger entry and updates the score of the user
+func (r *ScoreRepository) addEntry(user *repositories.User, points int, reason constants.ScoreReason, reference string, comment string) {
+	now := r.store.now()
+	id := r.store.nextID()
+	r.store.scoreLedger[id] = &repositories.ScoreLedgerEntry{
+		ID:        id,
+		UserID:    user.ID,
+		Points:    points,
+		Reason:    string(reason),
+		Reference: reference,
+		Comment:   comment,
+		CreatedAt: now,
+	}
+	user.Score += points
+	user.UpdatedAt = now
+}
@@ -0,0 +1,166 @@
+// Package memory implements the repositories in memory with the same behavior as the PostgreSQL ones,
+// including not found errors, unique constraints and cascading deletes. It is meant for tests.
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"evo-bot-go/internal/database/repositories"
+)
+
+var (
+	errUniqueViolation     = errors.New("duplicate key value violates unique constraint")
+	errForeignKeyViolation = errors.New("insert or update violates foreign key constraint")
+)
+
+// Store holds the tables shared by the in-memory repositories, so joins between them work like in the database
+type Store struct {
+	mu sync.Mutex
+
+	users        map[int]*repositories.User
+	botBlockedAt map[int]time.Time // by user ID
+	profiles     map[int]*repositories.Profile
+
+	events        map[int]*repositories.Event
+	registrations map[int]*repositories.EventRegistration
+
+	polls        map[int64]*repositories.RandomCoffeePoll
+	participants map[int64]*repositories.RandomCoffeeParticipant
+	pairs        map[int]*repositories.RandomCoffeePair
+
+	roles map[int]*repositories.UserRole
+
+	forumTopics map[int]*repositories.ForumTopic
+
+	scoreLedger map[int]*repositories.ScoreLedgerEntry
+
+	lastID   int
+	lastTime time.Time
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		users:         make(map[int]*repositories.User),
+		botBlockedAt:  make(map[int]time.Time),
+		profiles:      make(map[int]*repositories.Profile),
+		events:        make(map[int]*repositories.Event),
+		registrations: make(map[int]*repositories.EventRegistration),
+		polls:         make(map[int64]*repositories.RandomCoffeePoll),
+		participants:  make(map[int64]*repositories.RandomCoffeeParticipant),
+		pairs:         make(map[int]*repositories.RandomCoffeePair),
+		roles:         make(map[int]*repositories.UserRole),
+		forumTopics:   make(map[int]*repositories.ForumTopic),
+		scoreLedger:   make(map[int]*repositories.ScoreLedgerEntry),
+	}
+}
+
+// Users returns the user repository backed by the store
+func (s *Store) Users() repositories.UserRepository {
+	return &UserRepository{store: s}
+}
+
+// Profiles returns the profile repository backed by the store
+func (s *Store) Profiles() repositories.ProfileRepository {
+	return &ProfileRepository{store: s}
+}
+
+// Events returns the event repository backed by the store
+func (s *Store) Events() repositories.EventRepository {
+	return &EventRepository{store: s}
+}
+
+// RandomCoffeePolls returns the random coffee poll repository backed by the store
+func (s *Store) RandomCoffeePolls() repositories.RandomCoffeePollRepository {
+	return &RandomCoffeePollRepository{store: s}
+}
+
+// RandomCoffeeParticipants returns the random coffee participant repository backed by the store
+func (s *Store) RandomCoffeeParticipants() repositories.RandomCoffeeParticipantRepository {
+	return &RandomCoffeeParticipantRepository{store: s}
+}
+
+// RandomCoffeePairs returns the random coffee pair repository backed by the store
+func (s *Store) RandomCoffeePairs() repositories.RandomCoffeePairRepository {
+	return &RandomCoffeePairRepository{store: s}
+}
+
+// UserRoles returns the user role repository backed by the store
+func (s *Store) UserRoles() repositories.UserRoleRepository {
+	return &UserRoleRepository{store: s}
+}
+
+// ForumTopics returns the forum topic repository backed by the store
+func (s *Store) ForumTopics() repositories.ForumTopicRepository {
+	return &ForumTopicRepository{store: s}
+}
+
+// Scores returns the score repository backed by the store
+func (s *Store) Scores() repositories.ScoreRepository {
+	return &ScoreRepository{store: s}
+}
+
+// nextID returns a new ID, unique across all tables which makes mixed up IDs fail in tests
+func (s *Store) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+// now returns the current time, strictly increasing so the creation order is kept in the timestamps
+func (s *Store) now() time.Time {
+	now := time.Now()
+	if !now.After(s.lastTime) {
+		now = s.lastTime.Add(time.Microsecond)
+	}
+	s.lastTime = now
+	return now
+}
+
+// sortedKeys returns the map keys in ascending order
+func sortedKeys[K int | int64, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// deleteUserCascade removes the user with the rows referencing it, like ON DELETE CASCADE does
+func (s *Store) deleteUserCascade(userID int) {
+	delete(s.users, userID)
+	delete(s.botBlockedAt, userID)
+	for id, profile := range s.profiles {
+		if profile.UserID == userID {
+			delete(s.profiles, id)
+		}
+	}
+	for id, registration := range s.registrations {
+		if registration.UserID == userID {
+			delete(s.registrations, id)
+		}
+	}
+	for id, participant := range s.participants {
+		if participant.UserID == int64(userID) {
+			delete(s.participants, id)
+		}
+	}
+	for id, pair := range s.pairs {
+		if pair.User1ID == int64(userID) || pair.User2ID == int64(userID) {
+			delete(s.pairs, id)
+		}
+	}
+	for id, role := range s.roles {
+		if role.UserID == userID {
+			delete(s.roles, id)
+		}
+	}
+	for id, entry := range s.scoreLedger {
+		if entry.UserID == userID {
+			delete(s.scoreLedger, id)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package memory
+
+import (
+	"testing"
+
+	"evo-bot-go/internal/database/repositories/repositorytest"
+)
+
+func TestStoreContract(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		store := NewStore()
+		return repositorytest.Repositories{
+			Users:                    store.Users(),
+			Profiles:                 store.Profiles(),
+			Events:                   store.Events(),
+			RandomCoffeePolls:        store.RandomCoffeePolls(),
+			RandomCoffeeParticipants: store.RandomCoffeeParticipants(),
+			RandomCoffeePairs:        store.RandomCoffeePairs(),
+			UserRoles:                store.UserRoles(),
+			ForumTopics:              store.ForumTopics(),
+			Scores:                   store.Scores(),
+		}
+	})
+}
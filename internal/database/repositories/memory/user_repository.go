@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// UserRepository is the in-memory implementation of repositories.UserRepository
+type UserRepository struct {
+	store *Store
+}
+
+// Ensure UserRepository implements repositories.UserRepository interface
+var _ repositories.UserRepository = (*UserRepository)(nil)
+
+func (r *UserRepository) GetByID(id int) (*repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.findUser(func(u *repositories.User) bool { return u.ID == id })
+}
+
+func (r *UserRepository) GetByTelegramID(tgID int64) (*repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.findUser(func(u *repositories.User) bool { return u.TgID == tgID })
+}
+
+func (r *UserRepository) GetByTelegramUsername(tgUsername string) (*repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.findUser(func(u *repositories.User) bool { return u.TgUsername == tgUsername })
+}
+
+func (r *UserRepository) Create(tgID int64, firstname string, lastname string, username string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(tgID, firstname, lastname, username)
+}
+
+func (r *UserRepository) Update(id int, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: no fields to update for user with ID %d", utils.GetCurrentTypeName(), id)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return fmt.Errorf("%s: no user found with ID %d to update", utils.GetCurrentTypeName(), id)
+	}
+
+	// Apply to a copy, so a failed update changes nothing like a failed statement
+	updated := *user
+	for key, value := range fields {
+		if err := setUserField(&updated, key, value); err != nil {
+			return fmt.Errorf("%s: failed to update user with ID %d: %w", utils.GetCurrentTypeName(), id, err)
+		}
+	}
+	updated.UpdatedAt = r.store.now()
+	*user = updated
+
+	return nil
+}
+
+func (r *UserRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return fmt.Errorf("%s: no user found with ID %d to delete", utils.GetCurrentTypeName(), id)
+	}
+	r.store.deleteUserCascade(id)
+
+	return nil
+}
+
+func (r *UserRepository) UpdateScore(id int, score int) error {
+	return r.updateUser(id, "score", func(u *repositories.User) { u.Score = score })
+}
+
+func (r *UserRepository) SetCoffeeBan(id int, banned bool) error {
+	return r.updateUser(id, "coffee ban status", func(u *repositories.User) { u.HasCoffeeBan = banned })
+}
+
+func (r *UserRepository) SetClubMemberStatus(id int, isMember bool) error {
+	return r.updateUser(id, "club member status", func(u *repositories.User) { u.IsClubMember = isMember })
+}
+
+func (r *UserRepository) SetBotBlocked(tgID int64, blocked bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if user.TgID != tgID {
+			continue
+		}
+		if blocked {
+			r.store.botBlockedAt[user.ID] = r.store.now()
+		} else {
+			delete(r.store.botBlockedAt, user.ID)
+		}
+		user.UpdatedAt = r.store.now()
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetBotBlockedTelegramIDs() ([]int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var tgIDs []int64
+	for _, id := range sortedKeys(r.store.botBlockedAt) {
+		tgIDs = append(tgIDs, r.store.users[id].TgID)
+	}
+
+	return tgIDs, nil
+}
+
+func (r *UserRepository) UpdateTelegramUsername(id int, username string) error {
+	return r.updateUser(id, "telegram username", func(u *repositories.User) { u.TgUsername = username })
+}
+
+func (r *UserRepository) UpdateFirstname(id int, firstname string) error {
+	return r.updateUser(id, "firstname", func(u *repositories.User) { u.Firstname = firstname })
+}
+
+func (r *UserRepository) UpdateLastname(id int, lastname string) error {
+	return r.updateUser(id, "lastname", func(u *repositories.User) { u.Lastname = lastname })
+}
+
+func (r *UserRepository) UpdateTimezone(id int, timezone string) error {
+	return r.updateUser(id, "timezone", func(u *repositories.User) { u.Timezone = timezone })
+}
+
+// GetOrCreate gets the user by Telegram ID, updating the changed names, or creates it
+func (r *UserRepository) GetOrCreate(tgUser *gotgbot.User) (*repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.getOrCreate(tgUser)
+}
+
+// GetOrFullCreate gets or creates the user and their profile
+func (r *UserRepository) GetOrFullCreate(tgUser *gotgbot.User) (*repositories.User, *repositories.Profile, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, err := r.getOrCreate(tgUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile, err := (&ProfileRepository{store: r.store}).getOrCreateWithBio(user.ID, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to get profile in getOrCreateWithProfile: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return user, profile, nil
+}
+
+func (r *UserRepository) SearchByName(firstname, lastname string) (*repositories.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.findUser(func(u *repositories.User) bool {
+		return strings.EqualFold(u.Firstname, firstname) && strings.EqualFold(u.Lastname, lastname)
+	})
+}
+
+// findUser returns a copy of the user with the lowest ID matching the predicate, or sql.ErrNoRows
+func (r *UserRepository) findUser(match func(u *repositories.User) bool) (*repositories.User, error) {
+	for _, id := range sortedKeys(r.store.users) {
+		if user := r.store.users[id]; match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *UserRepository) create(tgID int64, firstname string, lastname string, username string) (int, error) {
+	for _, user := range r.store.users {
+		if user.TgID == tgID {
+			return 0, fmt.Errorf("%s: failed to insert user: %w", utils.GetCurrentTypeName(), errUniqueViolation)
+		}
+	}
+
+	now := r.store.now()
+	user := &repositories.User{
+		ID:           r.store.nextID(),
+		TgID:         tgID,
+		Firstname:    firstname,
+		Lastname:     lastname,
+		TgUsername:   username,
+		IsClubMember: true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	r.store.users[user.ID] = user
+
+	return user.ID, nil
+}
+
+func (r *UserRepository) getOrCreate(tgUser *gotgbot.User) (*repositories.User, error) {
+	user, err := r.findUser(func(u *repositories.User) bool { return u.TgID == tgUser.Id })
+	if err == nil {
+		stored := r.store.users[user.ID]
+		changed := false
+		if stored.Firstname != tgUser.FirstName && tgUser.FirstName != "" {
+			stored.Firstname = tgUser.FirstName
+			changed = true
+		}
+		if stored.Lastname != tgUser.LastName && tgUser.LastName != "" {
+			stored.Lastname = tgUser.LastName
+			changed = true
+		}
+		if stored.TgUsername != tgUser.Username && tgUser.Username != "" {
+			stored.TgUsername = tgUser.Username
+			changed = true
+		}
+		if changed {
+			stored.UpdatedAt = r.store.now()
+		}
+
+		found := *stored
+		return &found, nil
+	}
+
+	id, err := r.create(tgUser.Id, tgUser.FirstName, tgUser.LastName, tgUser.Username)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create user in getOrCreateUser: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	created := *r.store.users[id]
+	return &created, nil
+}
+
+// updateUser applies the change to the user with the ID, what names the updated field in the error
+func (r *UserRepository) updateUser(id int, what string, change func(u *repositories.User)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return fmt.Errorf("%s: no user found with ID %d to update %s", utils.GetCurrentTypeName(), id, what)
+	}
+	change(user)
+	user.UpdatedAt = r.store.now()
+
+	return nil
+}
+
+// setUserField sets the field by its column name, like the dynamic UPDATE of the database repository
+func setUserField(user *repositories.User, column string, value interface{}) error {
+	var ok bool
+	switch column {
+	case "firstname":
+		user.Firstname, ok = value.(string)
+	case "lastname":
+		user.Lastname, ok = value.(string)
+	case "tg_username":
+		user.TgUsername, ok = value.(string)
+	case "timezone":
+		user.Timezone, ok = value.(string)
+	case "score":
+		user.Score, ok = value.(int)
+	case "has_coffee_ban":
+		user.HasCoffeeBan, ok = value.(bool)
+	case "is_club_member":
+		user.IsClubMember, ok = value.(bool)
+	default:
+		return fmt.Errorf("column %q of relation \"users\" does not exist", column)
+	}
+	if !ok {
+		return fmt.Errorf("invalid value %v for column %q", value, column)
+	}
+	return nil
+}
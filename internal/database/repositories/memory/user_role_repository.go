@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+)
+
+// UserRoleRepository is the in-memory implementation of repositories.UserRoleRepository
+type UserRoleRepository struct {
+	store *Store
+}
+
+// Ensure UserRoleRepository implements repositories.UserRoleRepository interface
+var _ repositories.UserRoleRepository = (*UserRoleRepository)(nil)
+
+func (r *UserRoleRepository) GetRolesByTelegramID(tgID int64) ([]constants.Role, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var roles []constants.Role
+	for _, id := range sortedKeys(r.store.roles) {
+		role := r.store.roles[id]
+		if user, ok := r.store.users[role.UserID]; ok && user.TgID == tgID {
+			roles = append(roles, constants.Role(role.Role))
+		}
+	}
+	return roles, nil
+}
+
+func (r *UserRoleRepository) Grant(userID int, role constants.Role, grantedByTgID int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return false, fmt.Errorf("%s: failed to grant role %s to user %d: %w", utils.GetCurrentTypeName(), role, userID, errForeignKeyViolation)
+	}
+	for _, existing := range r.store.roles {
+		if existing.UserID == userID && existing.Role == string(role) {
+			return false, nil
+		}
+	}
+
+	id := r.store.nextID()
+	r.store.roles[id] = &repositories.UserRole{
+		ID:            id,
+		UserID:        userID,
+		Role:          string(role),
+		GrantedByTgID: sql.NullInt64{Int64: grantedByTgID, Valid: true},
+		CreatedAt:     r.store.now(),
+	}
+	return true, nil
+}
+
+func (r *UserRoleRepository) Revoke(userID int, role constants.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, existing := range r.store.roles {
+		if existing.UserID == userID && existing.Role == string(role) {
+			delete(r.store.roles, id)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *UserRoleRepository) GetAll() ([]repositories.UserRoleWithUser, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var roles []repositories.UserRoleWithUser
+	for _, role := range r.store.roles {
+		user := r.store.users[role.UserID]
+		roles = append(roles, repositories.UserRoleWithUser{
+			Role: *role,
+			User: repositories.User{
+				ID:         user.ID,
+				TgID:       user.TgID,
+				Firstname:  user.Firstname,
+				Lastname:   user.Lastname,
+				TgUsername: user.TgUsername,
+			},
+		})
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		a, b := roles[i], roles[j]
+		if a.Role.Role != b.Role.Role {
+			return a.Role.Role < b.Role.Role
+		}
+		if a.User.Firstname != b.User.Firstname {
+			return a.User.Firstname < b.User.Firstname
+		}
+		return a.User.Lastname < b.User.Lastname
+	})
+	return roles, nil
+}
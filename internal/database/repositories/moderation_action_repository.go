@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+)
+
+// ModerationActionEntry represents a row in the moderation_actions table
+type ModerationActionEntry struct {
+	ID          int
+	UserID      int
+	AdminUserID sql.NullInt64 // NULL for automatic actions
+	Action      string
+	Reason      string
+	UntilAt     sql.NullTime
+	CreatedAt   time.Time
+}
+
+// ModerationActionWithUsers represents a moderation action with the affected member and the admin name
+type ModerationActionWithUsers struct {
+	Entry     ModerationActionEntry
+	User      User
+	AdminName sql.NullString
+}
+
+// ModerationActionRepository handles database operations for moderation actions
+type ModerationActionRepository struct {
+	db *sql.DB
+}
+
+// NewModerationActionRepository creates a new ModerationActionRepository
+func NewModerationActionRepository(db *sql.DB) *ModerationActionRepository {
+	return &ModerationActionRepository{db: db}
+}
+
+// Create inserts a new moderation action and returns its ID
+func (r *ModerationActionRepository) Create(
+	userID int,
+	adminUserID sql.NullInt64,
+	action constants.MemberSanction,
+	reason string,
+	untilAt sql.NullTime,
+) (int, error) {
+	var id int
+	query := `
+		INSERT INTO moderation_actions (user_id, admin_user_id, action, reason, until_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+	err := r.db.QueryRow(query, userID, adminUserID, string(action), reason, untilAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to insert moderation action for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+	return id, nil
+}
+
+// CountWarningsSinceLastPenalty counts warnings of the user given after the latest mute or ban
+func (r *ModerationActionRepository) CountWarningsSinceLastPenalty(userID int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM moderation_actions
+		WHERE user_id = $1
+			AND action = $2
+			AND id > COALESCE((
+				SELECT MAX(id) FROM moderation_actions
+				WHERE user_id = $1 AND action IN ($3, $4)
+			), 0)`
+
+	var count int
+	err := r.db.QueryRow(query, userID,
+		string(constants.MemberSanctionWarn),
+		string(constants.MemberSanctionMute),
+		string(constants.MemberSanctionBan),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to count warnings for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+	return count, nil
+}
+
+// HasActiveMute reports whether the latest mute of the user is not over and was not lifted by an unmute
+func (r *ModerationActionRepository) HasActiveMute(userID int) (bool, error) {
+	query := `
+		SELECT COALESCE((
+			SELECT action = $2 AND until_at > NOW()
+			FROM moderation_actions
+			WHERE user_id = $1 AND action IN ($2, $3)
+			ORDER BY id DESC
+			LIMIT 1
+		), FALSE)`
+
+	var active bool
+	err := r.db.QueryRow(query, userID,
+		string(constants.MemberSanctionMute),
+		string(constants.MemberSanctionUnmute),
+	).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to check active mute for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+	return active, nil
+}
+
+// GetRecent retrieves the latest moderation actions
+func (r *ModerationActionRepository) GetRecent(limit int) ([]ModerationActionWithUsers, error) {
+	return r.query(`
+		SELECT ma.id, ma.user_id, ma.admin_user_id, ma.action, ma.reason, ma.until_at, ma.created_at,
+			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username,
+			NULLIF(TRIM(CONCAT(a.firstname, ' ', a.lastname)), '')
+		FROM moderation_actions ma
+		INNER JOIN users u ON ma.user_id = u.id
+		LEFT JOIN users a ON ma.admin_user_id = a.id
+		ORDER BY ma.id DESC
+		LIMIT $1`, limit)
+}
+
+// GetByUserID retrieves the latest moderation actions of the user
+func (r *ModerationActionRepository) GetByUserID(userID int, limit int) ([]ModerationActionWithUsers, error) {
+	return r.query(`
+		SELECT ma.id, ma.user_id, ma.admin_user_id, ma.action, ma.reason, ma.until_at, ma.created_at,
+			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username,
+			NULLIF(TRIM(CONCAT(a.firstname, ' ', a.lastname)), '')
+		FROM moderation_actions ma
+		INNER JOIN users u ON ma.user_id = u.id
+		LEFT JOIN users a ON ma.admin_user_id = a.id
+		WHERE ma.user_id = $2
+		ORDER BY ma.id DESC
+		LIMIT $1`, limit, userID)
+}
+
+func (r *ModerationActionRepository) query(query string, args ...interface{}) ([]ModerationActionWithUsers, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query moderation actions: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var actions []ModerationActionWithUsers
+	for rows.Next() {
+		var action ModerationActionWithUsers
+		if err := rows.Scan(
+			&action.Entry.ID,
+			&action.Entry.UserID,
+			&action.Entry.AdminUserID,
+			&action.Entry.Action,
+			&action.Entry.Reason,
+			&action.Entry.UntilAt,
+			&action.Entry.CreatedAt,
+			&action.User.ID,
+			&action.User.TgID,
+			&action.User.Firstname,
+			&action.User.Lastname,
+			&action.User.TgUsername,
+			&action.AdminName,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan moderation action: %w", utils.GetCurrentTypeName(), err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over moderation actions: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return actions, nil
+}
@@ -0,0 +1,52 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationActionRepository_HasActiveMute(t *testing.T) {
+	db := dbtest.OpenSchema(t)
+	require.NoError(t, migrations.RunMigrations(db, nil))
+	repository := repositories.NewModerationActionRepository(db)
+
+	userID, err := repositories.NewUserRepository(db).Create(2001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+
+	active, err := repository.HasActiveMute(userID)
+	require.NoError(t, err)
+	assert.False(t, active, "no mutes")
+
+	_, err = repository.Create(userID, sql.NullInt64{}, constants.MemberSanctionMute, "",
+		sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true})
+	require.NoError(t, err)
+	active, err = repository.HasActiveMute(userID)
+	require.NoError(t, err)
+	assert.False(t, active, "the mute is over")
+
+	_, err = repository.Create(userID, sql.NullInt64{}, constants.MemberSanctionMute, "",
+		sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true})
+	require.NoError(t, err)
+	_, err = repository.Create(userID, sql.NullInt64{}, constants.MemberSanctionWarn, "", sql.NullTime{})
+	require.NoError(t, err)
+	active, err = repository.HasActiveMute(userID)
+	require.NoError(t, err)
+	assert.True(t, active, "a later warning does not lift the mute")
+
+	_, err = repository.Create(userID, sql.NullInt64{}, constants.MemberSanctionUnmute, "", sql.NullTime{})
+	require.NoError(t, err)
+	active, err = repository.HasActiveMute(userID)
+	require.NoError(t, err)
+	assert.False(t, active, "the mute is lifted")
+}
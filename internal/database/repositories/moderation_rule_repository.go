@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ModerationRule represents a row in the moderation_rules table
+type ModerationRule struct {
+	ID             int
+	TopicID        int
+	AllowedPosters string
+	MinScore       int
+	AllowedUserIDs []int64
+	AllowLinks     bool
+	AllowMedia     bool
+	AllowForwards  bool
+	Action         string
+	MuteMinutes    int
+	DmTemplate     string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ModerationRuleRepository handles database operations for moderation rules
+type ModerationRuleRepository struct {
+	db *sql.DB
+}
+
+// NewModerationRuleRepository creates a new ModerationRuleRepository
+func NewModerationRuleRepository(db *sql.DB) *ModerationRuleRepository {
+	return &ModerationRuleRepository{db: db}
+}
+
+// GetAll retrieves all moderation rules ordered by topic
+func (r *ModerationRuleRepository) GetAll() ([]ModerationRule, error) {
+	query := `
+		SELECT id, topic_id, allowed_posters, min_score, allowed_user_ids, allow_links, allow_media, allow_forwards,
+			action, mute_minutes, dm_template, created_at, updated_at
+		FROM moderation_rules
+		ORDER BY topic_id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query moderation rules: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var rules []ModerationRule
+	for rows.Next() {
+		var rule ModerationRule
+		var allowedUserIDs string
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.TopicID,
+			&rule.AllowedPosters,
+			&rule.MinScore,
+			&allowedUserIDs,
+			&rule.AllowLinks,
+			&rule.AllowMedia,
+			&rule.AllowForwards,
+			&rule.Action,
+			&rule.MuteMinutes,
+			&rule.DmTemplate,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan moderation rule: %w", utils.GetCurrentTypeName(), err)
+		}
+		rule.AllowedUserIDs = utils.ParseInt64List(allowedUserIDs)
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over moderation rules: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return rules, nil
+}
+
+// Upsert creates the rule for its topic or replaces the existing one
+func (r *ModerationRuleRepository) Upsert(rule *ModerationRule) error {
+	query := `
+		INSERT INTO moderation_rules (topic_id, allowed_posters, min_score, allowed_user_ids, allow_links, allow_media,
+			allow_forwards, action, mute_minutes, dm_template)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (topic_id) DO UPDATE SET
+			allowed_posters = EXCLUDED.allowed_posters,
+			min_score = EXCLUDED.min_score,
+			allowed_user_ids = EXCLUDED.allowed_user_ids,
+			allow_links = EXCLUDED.allow_links,
+			allow_media = EXCLUDED.allow_media,
+			allow_forwards = EXCLUDED.allow_forwards,
+			action = EXCLUDED.action,
+			mute_minutes = EXCLUDED.mute_minutes,
+			dm_template = EXCLUDED.dm_template,
+			updated_at = NOW()`
+
+	_, err := r.db.Exec(query,
+		rule.TopicID,
+		rule.AllowedPosters,
+		rule.MinScore,
+		utils.FormatInt64List(rule.AllowedUserIDs),
+		rule.AllowLinks,
+		rule.AllowMedia,
+		rule.AllowForwards,
+		rule.Action,
+		rule.MuteMinutes,
+		rule.DmTemplate,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: failed to save moderation rule for topic %d: %w", utils.GetCurrentTypeName(), rule.TopicID, err)
+	}
+
+	return nil
+}
+
+// DeleteByTopicID removes the rule of the topic
+func (r *ModerationRuleRepository) DeleteByTopicID(topicID int) error {
+	result, err := r.db.Exec(`DELETE FROM moderation_rules WHERE topic_id = $1`, topicID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete moderation rule for topic %d: %w", utils.GetCurrentTypeName(), topicID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("%s: Could not get rows affected after delete: %v", utils.GetCurrentTypeName(), err)
+	} else if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+)
+
+// OutgoingMessage represents a row in the outgoing_messages table
+type OutgoingMessage struct {
+	ID                int
+	ChatID            int64
+	MessageThreadID   int64
+	Text              string
+	ParseMode         string
+	CopyFromChatID    sql.NullInt64 // set when the message is a copy of another message
+	CopyFromMessageID sql.NullInt64
+	Priority          constants.MessagePriority
+	Batch             string
+	Status            constants.OutgoingMessageStatus
+	Attempts          int
+	LastError         sql.NullString
+	SendAfter         time.Time
+	SentMessageID     sql.NullInt64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// OutgoingMessageRepository handles database operations for the outgoing message queue
+type OutgoingMessageRepository struct {
+	db *sql.DB
+}
+
+// NewOutgoingMessageRepository creates a new OutgoingMessageRepository
+func NewOutgoingMessageRepository(db *sql.DB) *OutgoingMessageRepository {
+	return &OutgoingMessageRepository{db: db}
+}
+
+// Create puts a pending message into the queue
+func (r *OutgoingMessageRepository) Create(message *OutgoingMessage) (int, error) {
+	sendAfter := message.SendAfter
+	if sendAfter.IsZero() {
+		sendAfter = time.Now()
+	}
+
+	var id int
+	query := `
+		INSERT INTO outgoing_messages (chat_id, message_thread_id, text, parse_mode, copy_from_chat_id, copy_from_message_id, priority, batch, send_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	err := r.db.QueryRow(
+		query,
+		message.ChatID,
+		message.MessageThreadID,
+		message.Text,
+		message.ParseMode,
+		message.CopyFromChatID,
+		message.CopyFromMessageID,
+		int(message.Priority),
+		message.Batch,
+		sendAfter,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to queue message to chat %d: %w", utils.GetCurrentTypeName(), message.ChatID, err)
+	}
+
+	return id, nil
+}
+
+// ClaimDue retrieves pending messages whose time has come, the most urgent first. The messages are postponed
+// by claimFor in the same statement, so other instances skip them while they are being sent, and they are
+// picked up again if this instance stops before marking them.
+func (r *OutgoingMessageRepository) ClaimDue(limit int, claimFor time.Duration) ([]OutgoingMessage, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outgoing_messages
+			SET send_after = NOW() + $3 * INTERVAL '1 second', updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM outgoing_messages
+				WHERE status = $1 AND send_after <= NOW()
+				ORDER BY priority, id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, chat_id, message_thread_id, text, parse_mode, copy_from_chat_id, copy_from_message_id, priority, batch, status, attempts, last_error, send_after, sent_message_id, created_at, updated_at
+		)
+		SELECT id, chat_id, message_thread_id, text, parse_mode, copy_from_chat_id, copy_from_message_id, priority, batch, status, attempts, last_error, send_after, sent_message_id, created_at, updated_at
+		FROM claimed
+		ORDER BY priority, id`
+
+	rows, err := r.db.Query(query, string(constants.OutgoingMessageStatusPending), limit, int(claimFor.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to claim due messages: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var messages []OutgoingMessage
+	for rows.Next() {
+		var message OutgoingMessage
+		var priority int
+		var status string
+		if err := rows.Scan(
+			&message.ID,
+			&message.ChatID,
+			&message.MessageThreadID,
+			&message.Text,
+			&message.ParseMode,
+			&message.CopyFromChatID,
+			&message.CopyFromMessageID,
+			&priority,
+			&message.Batch,
+			&status,
+			&message.Attempts,
+			&message.LastError,
+			&message.SendAfter,
+			&message.SentMessageID,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan outgoing message: %w", utils.GetCurrentTypeName(), err)
+		}
+		message.Priority = constants.MessagePriority(priority)
+		message.Status = constants.OutgoingMessageStatus(status)
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over outgoing messages: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return messages, nil
+}
+
+// MarkSent marks the message as delivered
+func (r *OutgoingMessageRepository) MarkSent(id int, sentMessageID int64) error {
+	query := `
+		UPDATE outgoing_messages
+		SET status = $1, attempts = attempts + 1, sent_message_id = $2, updated_at = NOW()
+		WHERE id = $3`
+	if _, err := r.db.Exec(query, string(constants.OutgoingMessageStatusSent), sentMessageID, id); err != nil {
+		return fmt.Errorf("%s: failed to mark outgoing message %d as sent: %w", utils.GetCurrentTypeName(), id, err)
+	}
+	return nil
+}
+
+// MarkUndelivered finishes the message with the failed or blocked status
+func (r *OutgoingMessageRepository) MarkUndelivered(id int, status constants.OutgoingMessageStatus, lastError string) error {
+	query := `
+		UPDATE outgoing_messages
+		SET status = $1, attempts = attempts + 1, last_error = $2, updated_at = NOW()
+		WHERE id = $3`
+	if _, err := r.db.Exec(query, string(status), lastError, id); err != nil {
+		return fmt.Errorf("%s: failed to mark outgoing message %d as %s: %w", utils.GetCurrentTypeName(), id, status, err)
+	}
+	return nil
+}
+
+// Reschedule keeps the message pending and moves its next attempt to sendAfter
+func (r *OutgoingMessageRepository) Reschedule(id int, sendAfter time.Time, lastError string) error {
+	query := `
+		UPDATE outgoing_messages
+		SET attempts = attempts + 1, send_after = $1, last_error = $2, updated_at = NOW()
+		WHERE id = $3`
+	if _, err := r.db.Exec(query, sendAfter, lastError, id); err != nil {
+		return fmt.Errorf("%s: failed to reschedule outgoing message %d: %w", utils.GetCurrentTypeName(), id, err)
+	}
+	return nil
+}
+
+// GetBatchStats counts the messages of the batch by status
+func (r *OutgoingMessageRepository) GetBatchStats(batch string) (map[constants.OutgoingMessageStatus]int, error) {
+	query := `SELECT status, COUNT(*) FROM outgoing_messages WHERE batch = $1 GROUP BY status`
+	rows, err := r.db.Query(query, batch)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query stats of batch %s: %w", utils.GetCurrentTypeName(), batch, err)
+	}
+	defer rows.Close()
+
+	stats := make(map[constants.OutgoingMessageStatus]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan batch stats: %w", utils.GetCurrentTypeName(), err)
+		}
+		stats[constants.OutgoingMessageStatus(status)] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over batch stats: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return stats, nil
+}
+
+// DeleteFinishedBefore removes delivered and undeliverable messages last updated before the given time
+func (r *OutgoingMessageRepository) DeleteFinishedBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM outgoing_messages WHERE status != $1 AND updated_at < $2`
+	result, err := r.db.Exec(query, string(constants.OutgoingMessageStatusPending), before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to delete finished outgoing messages: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: could not get rows affected after delete: %w", utils.GetCurrentTypeName(), err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,45 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutgoingMessageRepository_ClaimDue(t *testing.T) {
+	db := dbtest.OpenSchema(t)
+	require.NoError(t, migrations.RunMigrations(db, nil))
+	repository := repositories.NewOutgoingMessageRepository(db)
+
+	bulkID, err := repository.Create(&repositories.OutgoingMessage{ChatID: 1, Text: "bulk", Priority: constants.MessagePriorityBulk})
+	require.NoError(t, err)
+	interactiveID, err := repository.Create(&repositories.OutgoingMessage{ChatID: 2, Text: "reply", Priority: constants.MessagePriorityInteractive})
+	require.NoError(t, err)
+
+	claimed, err := repository.ClaimDue(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, interactiveID, claimed[0].ID, "the most urgent first")
+	assert.Equal(t, bulkID, claimed[1].ID)
+
+	// Another instance does not get the claimed messages
+	claimed, err = repository.ClaimDue(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// A rescheduled message is due again
+	require.NoError(t, repository.Reschedule(bulkID, time.Now().Add(-time.Second), "retry"))
+	claimed, err = repository.ClaimDue(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, bulkID, claimed[0].ID)
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// PendingCaptcha represents a row in the pending_captchas table
+type PendingCaptcha struct {
+	User         gotgbot.User
+	ChatID       int64
+	MessageID    int64
+	AnswerOption int
+	Deadline     time.Time
+}
+
+// PendingCaptchaRepository handles database operations for the captchas that new members have not answered yet
+type PendingCaptchaRepository struct {
+	db *sql.DB
+}
+
+// NewPendingCaptchaRepository creates a new PendingCaptchaRepository
+func NewPendingCaptchaRepository(db *sql.DB) *PendingCaptchaRepository {
+	return &PendingCaptchaRepository{db: db}
+}
+
+// Save stores the captcha of the user, replacing the previous one
+func (r *PendingCaptchaRepository) Save(captcha *PendingCaptcha) error {
+	query := `
+		INSERT INTO pending_captchas (user_tg_id, chat_id, message_id, answer_option, user_first_name, user_last_name, user_username, deadline)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_tg_id) DO UPDATE SET
+			chat_id = EXCLUDED.chat_id,
+			message_id = EXCLUDED.message_id,
+			answer_option = EXCLUDED.answer_option,
+			user_first_name = EXCLUDED.user_first_name,
+			user_last_name = EXCLUDED.user_last_name,
+			user_username = EXCLUDED.user_username,
+			deadline = EXCLUDED.deadline,
+			created_at = CURRENT_TIMESTAMP`
+
+	_, err := r.db.Exec(query, captcha.User.Id, captcha.ChatID, captcha.MessageID, captcha.AnswerOption,
+		captcha.User.FirstName, captcha.User.LastName, captcha.User.Username, captcha.Deadline)
+	if err != nil {
+		return fmt.Errorf("%s: failed to save captcha of user %d: %w", utils.GetCurrentTypeName(), captcha.User.Id, err)
+	}
+
+	return nil
+}
+
+// Delete removes the captcha of the user
+func (r *PendingCaptchaRepository) Delete(userTgID int64) error {
+	if _, err := r.db.Exec(`DELETE FROM pending_captchas WHERE user_tg_id = $1`, userTgID); err != nil {
+		return fmt.Errorf("%s: failed to delete captcha of user %d: %w", utils.GetCurrentTypeName(), userTgID, err)
+	}
+
+	return nil
+}
+
+// GetAll returns all pending captchas ordered by deadline
+func (r *PendingCaptchaRepository) GetAll() ([]PendingCaptcha, error) {
+	query := `
+		SELECT user_tg_id, user_first_name, user_last_name, user_username, chat_id, message_id, answer_option, deadline
+		FROM pending_captchas
+		ORDER BY deadline`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query pending captchas: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var captchas []PendingCaptcha
+	for rows.Next() {
+		var captcha PendingCaptcha
+		if err := rows.Scan(&captcha.User.Id, &captcha.User.FirstName, &captcha.User.LastName, &captcha.User.Username,
+			&captcha.ChatID, &captcha.MessageID, &captcha.AnswerOption, &captcha.Deadline); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan pending captcha: %w", utils.GetCurrentTypeName(), err)
+		}
+		captchas = append(captchas, captcha)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over pending captchas: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return captchas, nil
+}
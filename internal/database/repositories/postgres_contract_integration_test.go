@@ -0,0 +1,33 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"testing"
+
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/repositorytest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresContract(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		db := dbtest.OpenSchema(t)
+		require.NoError(t, migrations.RunMigrations(db, nil))
+
+		return repositorytest.Repositories{
+			Users:                    repositories.NewUserRepository(db),
+			Profiles:                 repositories.NewProfileRepository(db),
+			Events:                   repositories.NewEventRepository(db),
+			RandomCoffeePolls:        repositories.NewRandomCoffeePollRepository(db),
+			RandomCoffeeParticipants: repositories.NewRandomCoffeeParticipantRepository(db),
+			RandomCoffeePairs:        repositories.NewRandomCoffeePairRepository(db),
+			UserRoles:                repositories.NewUserRoleRepository(db),
+			ForumTopics:              repositories.NewForumTopicRepository(db),
+			Scores:                   repositories.NewScoreRepository(db),
+		}
+	})
+}
@@ -2,12 +2,15 @@ package repositories
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"evo-bot-go/internal/utils"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/lib/pq"
 )
 
 // Profile represents a row in the profiles table
@@ -15,37 +18,162 @@ type Profile struct {
 	ID                 int
 	UserID             int
 	Bio                string
+	City               string
+	Country            string
+	JobTitle           string
+	Company            string
+	Skills             ProfileTags
+	CanHelpWith        ProfileTags
+	LookingFor         ProfileTags
+	Languages          ProfileTags
+	Links              ProfileLinks
+	PhotoFileID        string
 	PublishedMessageID sql.NullInt64
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	// PublishedWithPhoto is true when the published message is a photo with a caption
+	PublishedWithPhoto bool
+	// PublishedExtraMessageID is the follow-up message with the text that did not fit into the caption
+	PublishedExtraMessageID sql.NullInt64
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
 }
 
-// ProfileRepository handles database operations for profiles
-type ProfileRepository struct {
-	db *sql.DB
+// ProfileTags is a list of tags stored as a TEXT[] column
+type ProfileTags []string
+
+// Value implements driver.Valuer
+func (t ProfileTags) Value() (driver.Value, error) {
+	if t == nil {
+		return pq.StringArray{}.Value()
+	}
+	return pq.StringArray(t).Value()
 }
 
-// NewProfileRepository creates a new ProfileRepository
-func NewProfileRepository(db *sql.DB) *ProfileRepository {
-	return &ProfileRepository{db: db}
+// Scan implements sql.Scanner
+func (t *ProfileTags) Scan(src interface{}) error {
+	var tags pq.StringArray
+	if err := tags.Scan(src); err != nil {
+		return err
+	}
+	*t = ProfileTags(tags)
+	return nil
 }
 
-// GetByID retrieves a profile by ID
-func (r *ProfileRepository) GetByID(id int) (*Profile, error) {
-	query := `
-		SELECT id, user_id, bio, published_message_id, created_at, updated_at
-		FROM profiles
-		WHERE id = $1`
+// ProfileLinks maps a constants.ProfileLinkType to the link, it is stored as a JSONB column
+type ProfileLinks map[string]string
 
-	var profile Profile
-	err := r.db.QueryRow(query, id).Scan(
+// Value implements driver.Valuer
+func (l ProfileLinks) Value() (driver.Value, error) {
+	if l == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *ProfileLinks) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ProfileLinks", src)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// profileScanDest returns the destinations for the columns of profileColumns in their order
+func profileScanDest(profile *Profile) []interface{} {
+	return []interface{}{
 		&profile.ID,
 		&profile.UserID,
 		&profile.Bio,
+		&profile.City,
+		&profile.Country,
+		&profile.JobTitle,
+		&profile.Company,
+		&profile.Skills,
+		&profile.CanHelpWith,
+		&profile.LookingFor,
+		&profile.Languages,
+		&profile.Links,
+		&profile.PhotoFileID,
 		&profile.PublishedMessageID,
+		&profile.PublishedWithPhoto,
+		&profile.PublishedExtraMessageID,
 		&profile.CreatedAt,
 		&profile.UpdatedAt,
-	)
+	}
+}
+
+// profileColumns lists the columns of a profile, every column is prefixed with "p."
+const profileColumns = `p.id, p.user_id, p.bio, p.city, p.country, p.job_title, p.company,
+			p.skills, p.can_help_with, p.looking_for, p.languages, p.links, p.photo_file_id,
+			p.published_message_id, p.published_with_photo, p.published_extra_message_id, p.created_at, p.updated_at`
+
+// ProfileRepository stores the user profiles
+type ProfileRepository interface {
+	GetByID(id int) (*Profile, error)
+	Create(userID int, bio string) (int, error)
+	Update(id int, fields map[string]interface{}) error
+	Delete(id int) error
+	UpdatePublishedMessageID(profileID int, messageID int64) error
+	GetOrCreateWithBio(userID int, bio string) (*Profile, error)
+	GetOrCreate(userID int) (*Profile, error)
+	GetOrFullCreate(user *gotgbot.User) (*Profile, error)
+	GetAllWithUsers() ([]ProfileWithUser, error)
+	Search(filter ProfileSearchFilter, limit, offset int) ([]ProfileWithUser, int, error)
+}
+
+// ProfileSearchFilter narrows the member directory search, empty fields are not applied
+type ProfileSearchFilter struct {
+	// Query is a websearch_to_tsquery query: words, "quoted phrases", "or" and "-excluded" words.
+	// It is matched against the profile fields and, with typos allowed, against the name and the username.
+	Query string
+	// Tag matches one of the skills, "can help with", "looking for" and languages, ignoring the case
+	Tag string
+	// City matches the city, ignoring the case
+	City         string
+	IsClubMember *bool
+}
+
+// ProfileNameSimilarityThreshold is the minimal trigram word similarity of the query to the name of the user
+const ProfileNameSimilarityThreshold = 0.5
+
+// ProfileNameMinQueryLength is the minimal length of the query to be matched against the names,
+// shorter queries are similar to too many names
+const ProfileNameMinQueryLength = 3
+
+// Ensure PostgresProfileRepository implements ProfileRepository interface
+var _ ProfileRepository = (*PostgresProfileRepository)(nil)
+
+// PostgresProfileRepository handles database operations for profiles
+type PostgresProfileRepository struct {
+	db *sql.DB
+}
+
+// NewProfileRepository creates a new PostgresProfileRepository
+func NewProfileRepository(db *sql.DB) *PostgresProfileRepository {
+	return &PostgresProfileRepository{db: db}
+}
+
+// GetByID retrieves a profile by ID
+func (r *PostgresProfileRepository) GetByID(id int) (*Profile, error) {
+	query := `
+		SELECT ` + profileColumns + `
+		FROM profiles p
+		WHERE p.id = $1`
+
+	var profile Profile
+	err := r.db.QueryRow(query, id).Scan(profileScanDest(&profile)...)
 
 	if err == sql.ErrNoRows {
 		return nil, sql.ErrNoRows
@@ -59,7 +187,7 @@ func (r *ProfileRepository) GetByID(id int) (*Profile, error) {
 }
 
 // Create inserts a new profile record into the database
-func (r *ProfileRepository) Create(userID int, bio string) (int, error) {
+func (r *PostgresProfileRepository) Create(userID int, bio string) (int, error) {
 	var id int
 	query := `INSERT INTO profiles (user_id, bio) 
 			VALUES ($1, $2) RETURNING id`
@@ -71,7 +199,7 @@ func (r *ProfileRepository) Create(userID int, bio string) (int, error) {
 }
 
 // Update updates profile fields
-func (r *ProfileRepository) Update(id int, fields map[string]interface{}) error {
+func (r *PostgresProfileRepository) Update(id int, fields map[string]interface{}) error {
 	if len(fields) == 0 {
 		return fmt.Errorf("%s: no fields to update for profile with ID %d", utils.GetCurrentTypeName(), id)
 	}
@@ -106,7 +234,7 @@ func (r *ProfileRepository) Update(id int, fields map[string]interface{}) error
 }
 
 // Delete removes a profile record from the database by its ID
-func (r *ProfileRepository) Delete(id int) error {
+func (r *PostgresProfileRepository) Delete(id int) error {
 	query := `DELETE FROM profiles WHERE id = $1`
 	result, err := r.db.Exec(query, id)
 	if err != nil {
@@ -124,7 +252,7 @@ func (r *ProfileRepository) Delete(id int) error {
 }
 
 // UpdatePublishedMessageID updates the published_message_id field for a profile
-func (r *ProfileRepository) UpdatePublishedMessageID(profileID int, messageID int64) error {
+func (r *PostgresProfileRepository) UpdatePublishedMessageID(profileID int, messageID int64) error {
 	query := `UPDATE profiles SET published_message_id = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, messageID, profileID)
 	if err != nil {
@@ -141,7 +269,7 @@ func (r *ProfileRepository) UpdatePublishedMessageID(profileID int, messageID in
 	return nil
 }
 
-func (r *ProfileRepository) GetOrCreateWithBio(userID int, bio string) (*Profile, error) {
+func (r *PostgresProfileRepository) GetOrCreateWithBio(userID int, bio string) (*Profile, error) {
 	// Try to get profile
 	profile, err := r.getByUserID(userID)
 	if err != nil && err != sql.ErrNoRows {
@@ -178,12 +306,12 @@ func (r *ProfileRepository) GetOrCreateWithBio(userID int, bio string) (*Profile
 	return newProfile, nil
 }
 
-func (r *ProfileRepository) GetOrCreate(userID int) (*Profile, error) {
+func (r *PostgresProfileRepository) GetOrCreate(userID int) (*Profile, error) {
 	return r.GetOrCreateWithBio(userID, "")
 }
 
 // GetOrFullCreate gets or creates a user with default profile
-func (r *ProfileRepository) GetOrFullCreate(user *gotgbot.User) (*Profile, error) {
+func (r *PostgresProfileRepository) GetOrFullCreate(user *gotgbot.User) (*Profile, error) {
 	// Get or create user
 	userRepo := NewUserRepository(r.db)
 	_, profile, err := userRepo.GetOrFullCreate(user)
@@ -194,21 +322,14 @@ func (r *ProfileRepository) GetOrFullCreate(user *gotgbot.User) (*Profile, error
 }
 
 // GetByUserID retrieves a profile by user ID
-func (r *ProfileRepository) getByUserID(userID int) (*Profile, error) {
+func (r *PostgresProfileRepository) getByUserID(userID int) (*Profile, error) {
 	query := `
-		SELECT id, user_id, bio, published_message_id, created_at, updated_at
-		FROM profiles
-		WHERE user_id = $1`
+		SELECT ` + profileColumns + `
+		FROM profiles p
+		WHERE p.user_id = $1`
 
 	var profile Profile
-	err := r.db.QueryRow(query, userID).Scan(
-		&profile.ID,
-		&profile.UserID,
-		&profile.Bio,
-		&profile.PublishedMessageID,
-		&profile.CreatedAt,
-		&profile.UpdatedAt,
-	)
+	err := r.db.QueryRow(query, userID).Scan(profileScanDest(&profile)...)
 
 	if err == sql.ErrNoRows {
 		return nil, sql.ErrNoRows
@@ -228,11 +349,10 @@ type ProfileWithUser struct {
 }
 
 // GetAllWithUsers retrieves all profiles with their associated user information
-func (r *ProfileRepository) GetAllWithUsers() ([]ProfileWithUser, error) {
+func (r *PostgresProfileRepository) GetAllWithUsers() ([]ProfileWithUser, error) {
 	query := `
-		SELECT 
-			p.id, p.user_id, p.bio, p.published_message_id, p.created_at, p.updated_at,
-			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username, u.score, u.has_coffee_ban, u.is_club_member, u.created_at, u.updated_at
+		SELECT ` + profileColumns + `,
+			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username, u.score, u.has_coffee_ban, u.is_club_member, u.timezone, u.created_at, u.updated_at
 		FROM profiles p
 		INNER JOIN users u ON p.user_id = u.id
 		WHERE p.bio != '' AND p.bio IS NOT NULL
@@ -249,13 +369,7 @@ func (r *ProfileRepository) GetAllWithUsers() ([]ProfileWithUser, error) {
 		var profile Profile
 		var user User
 
-		err := rows.Scan(
-			&profile.ID,
-			&profile.UserID,
-			&profile.Bio,
-			&profile.PublishedMessageID,
-			&profile.CreatedAt,
-			&profile.UpdatedAt,
+		err := rows.Scan(append(profileScanDest(&profile),
 			&user.ID,
 			&user.TgID,
 			&user.Firstname,
@@ -264,9 +378,10 @@ func (r *ProfileRepository) GetAllWithUsers() ([]ProfileWithUser, error) {
 			&user.Score,
 			&user.HasCoffeeBan,
 			&user.IsClubMember,
+			&user.Timezone,
 			&user.CreatedAt,
 			&user.UpdatedAt,
-		)
+		)...)
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to scan profile with user: %w", utils.GetCurrentTypeName(), err)
 		}
@@ -283,3 +398,90 @@ func (r *ProfileRepository) GetAllWithUsers() ([]ProfileWithUser, error) {
 
 	return profiles, nil
 }
+
+// Search finds the profiles with a bio matching the filter, the most relevant first, and the total number of them.
+// The profiles are matched by the search_vector built with the Russian and the English configs and by the trigram
+// similarity of the name, the relevance is their sum. Without a query the most recently updated go first.
+func (r *PostgresProfileRepository) Search(filter ProfileSearchFilter, limit, offset int) ([]ProfileWithUser, int, error) {
+	query := `
+		WITH q AS (
+			SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS ts
+		)
+		SELECT ` + profileColumns + `,
+			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username, u.score, u.has_coffee_ban, u.is_club_member, u.timezone, u.created_at, u.updated_at,
+			COUNT(*) OVER() AS total
+		FROM profiles p
+		INNER JOIN users u ON p.user_id = u.id
+		CROSS JOIN q
+		CROSS JOIN LATERAL (
+			SELECT CASE WHEN char_length($1) >= $7
+				THEN word_similarity($1, concat_ws(' ', u.firstname, u.lastname, u.tg_username))
+				ELSE 0 END AS name_similarity
+		) n
+		WHERE p.bio != '' AND p.bio IS NOT NULL
+			AND ($1 = '' OR p.search_vector @@ q.ts OR n.name_similarity >= $8)
+			AND ($2 = '' OR EXISTS (
+				SELECT 1 FROM unnest(p.skills || p.can_help_with || p.looking_for || p.languages) AS tag
+				WHERE lower(tag) = lower($2)
+			))
+			AND ($3 = '' OR lower(p.city) = lower($3))
+			AND ($4::boolean IS NULL OR u.is_club_member = $4)
+		ORDER BY
+			CASE WHEN $1 = '' THEN 0 ELSE ts_rank(p.search_vector, q.ts) + n.name_similarity END DESC,
+			p.updated_at DESC, p.id DESC
+		LIMIT $5 OFFSET $6`
+
+	var isClubMember sql.NullBool
+	if filter.IsClubMember != nil {
+		isClubMember = sql.NullBool{Bool: *filter.IsClubMember, Valid: true}
+	}
+
+	rows, err := r.db.Query(query, filter.Query, filter.Tag, filter.City, isClubMember, limit, offset,
+		ProfileNameMinQueryLength, ProfileNameSimilarityThreshold)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: failed to search profiles: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var profiles []ProfileWithUser
+	total := 0
+	for rows.Next() {
+		var profile Profile
+		var user User
+
+		err := rows.Scan(append(profileScanDest(&profile),
+			&user.ID,
+			&user.TgID,
+			&user.Firstname,
+			&user.Lastname,
+			&user.TgUsername,
+			&user.Score,
+			&user.HasCoffeeBan,
+			&user.IsClubMember,
+			&user.Timezone,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&total,
+		)...)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: failed to scan found profile: %w", utils.GetCurrentTypeName(), err)
+		}
+
+		profiles = append(profiles, ProfileWithUser{
+			Profile: &profile,
+			User:    &user,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: error iterating over found profiles: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// The total comes with the rows, so a page past the end has to count them separately
+	if len(profiles) == 0 && offset > 0 {
+		_, total, err := r.Search(filter, 1, 0)
+		return nil, total, err
+	}
+
+	return profiles, total, nil
+}
@@ -14,15 +14,27 @@ type RandomCoffeePair struct {
 	CreatedAt time.Time
 }
 
-type RandomCoffeePairRepository struct {
+// RandomCoffeePairRepository stores the generated random coffee pairs
+type RandomCoffeePairRepository interface {
+	CreatePair(pollID int, user1ID, user2ID int) error
+	GetPairsHistoryForUsers(userIDs []int, lastNPolls int) (map[string][]int, error)
+	GetMostRecentPairPoll(user1ID, user2ID int, lastNPolls int) (int, error)
+	GetPairByPollAndUser(pollID int, userID int) (*RandomCoffeePair, error)
+}
+
+// Ensure PostgresRandomCoffeePairRepository implements RandomCoffeePairRepository interface
+var _ RandomCoffeePairRepository = (*PostgresRandomCoffeePairRepository)(nil)
+
+// PostgresRandomCoffeePairRepository handles database operations for random coffee pairs
+type PostgresRandomCoffeePairRepository struct {
 	db *sql.DB
 }
 
-func NewRandomCoffeePairRepository(db *sql.DB) *RandomCoffeePairRepository {
-	return &RandomCoffeePairRepository{db: db}
+func NewRandomCoffeePairRepository(db *sql.DB) *PostgresRandomCoffeePairRepository {
+	return &PostgresRandomCoffeePairRepository{db: db}
 }
 
-func (r *RandomCoffeePairRepository) CreatePair(pollID int, user1ID, user2ID int) error {
+func (r *PostgresRandomCoffeePairRepository) CreatePair(pollID int, user1ID, user2ID int) error {
 	query := `
 		INSERT INTO random_coffee_pairs (poll_id, user1_id, user2_id)
 		VALUES ($1, $2, $3)
@@ -35,7 +47,7 @@ func (r *RandomCoffeePairRepository) CreatePair(pollID int, user1ID, user2ID int
 }
 
 // GetPairsHistoryForUsers returns historical pairs for specified users from last N polls
-func (r *RandomCoffeePairRepository) GetPairsHistoryForUsers(userIDs []int, lastNPolls int) (map[string][]int, error) {
+func (r *PostgresRandomCoffeePairRepository) GetPairsHistoryForUsers(userIDs []int, lastNPolls int) (map[string][]int, error) {
 	if len(userIDs) == 0 {
 		return make(map[string][]int), nil
 	}
@@ -97,7 +109,7 @@ func (r *RandomCoffeePairRepository) GetPairsHistoryForUsers(userIDs []int, last
 }
 
 // GetMostRecentPairPoll returns the most recent poll ID where two users were paired, or 0 if never paired
-func (r *RandomCoffeePairRepository) GetMostRecentPairPoll(user1ID, user2ID int, lastNPolls int) (int, error) {
+func (r *PostgresRandomCoffeePairRepository) GetMostRecentPairPoll(user1ID, user2ID int, lastNPolls int) (int, error) {
 	query := `
 		SELECT poll.id
 		FROM random_coffee_pairs p
@@ -123,3 +135,24 @@ func (r *RandomCoffeePairRepository) GetMostRecentPairPoll(user1ID, user2ID int,
 
 	return pollID, nil
 }
+
+// GetPairByPollAndUser returns the pair of the given poll that contains the user
+func (r *PostgresRandomCoffeePairRepository) GetPairByPollAndUser(pollID int, userID int) (*RandomCoffeePair, error) {
+	query := `
+		SELECT id, poll_id, user1_id, user2_id, created_at
+		FROM random_coffee_pairs
+		WHERE poll_id = $1 AND (user1_id = $2 OR user2_id = $2)
+		LIMIT 1
+	`
+
+	var pair RandomCoffeePair
+	err := r.db.QueryRow(query, pollID, userID).Scan(&pair.ID, &pair.PollID, &pair.User1ID, &pair.User2ID, &pair.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting random coffee pair: %w", err)
+	}
+
+	return &pair, nil
+}
@@ -16,15 +16,27 @@ type RandomCoffeeParticipant struct {
 	UpdatedAt       time.Time `db:"updated_at"`
 }
 
-type RandomCoffeeParticipantRepository struct {
+// RandomCoffeeParticipantRepository stores the answers to random coffee polls
+type RandomCoffeeParticipantRepository interface {
+	UpsertParticipant(participant RandomCoffeeParticipant) error
+	RemoveParticipant(pollID int64, userID int64) error
+	GetParticipant(pollID int64, userID int64) (*RandomCoffeeParticipant, error)
+	GetParticipatingUsers(pollID int64) ([]User, error)
+}
+
+// Ensure PostgresRandomCoffeeParticipantRepository implements RandomCoffeeParticipantRepository interface
+var _ RandomCoffeeParticipantRepository = (*PostgresRandomCoffeeParticipantRepository)(nil)
+
+// PostgresRandomCoffeeParticipantRepository handles database operations for random coffee participants
+type PostgresRandomCoffeeParticipantRepository struct {
 	db *sql.DB
 }
 
-func NewRandomCoffeeParticipantRepository(db *sql.DB) *RandomCoffeeParticipantRepository {
-	return &RandomCoffeeParticipantRepository{db: db}
+func NewRandomCoffeeParticipantRepository(db *sql.DB) *PostgresRandomCoffeeParticipantRepository {
+	return &PostgresRandomCoffeeParticipantRepository{db: db}
 }
 
-func (r *RandomCoffeeParticipantRepository) UpsertParticipant(participant RandomCoffeeParticipant) error {
+func (r *PostgresRandomCoffeeParticipantRepository) UpsertParticipant(participant RandomCoffeeParticipant) error {
 	query := `
 		INSERT INTO random_coffee_participants (poll_id, user_id, is_participating, created_at, updated_at)
 		VALUES ($1, $2, $3, NOW(), NOW())
@@ -36,13 +48,13 @@ func (r *RandomCoffeeParticipantRepository) UpsertParticipant(participant Random
 	return err
 }
 
-func (r *RandomCoffeeParticipantRepository) RemoveParticipant(pollID int64, userID int64) error {
+func (r *PostgresRandomCoffeeParticipantRepository) RemoveParticipant(pollID int64, userID int64) error {
 	query := "DELETE FROM random_coffee_participants WHERE poll_id = $1 AND user_id = $2"
 	_, err := r.db.Exec(query, pollID, userID)
 	return err
 }
 
-func (r *RandomCoffeeParticipantRepository) GetParticipant(pollID int64, userID int64) (*RandomCoffeeParticipant, error) {
+func (r *PostgresRandomCoffeeParticipantRepository) GetParticipant(pollID int64, userID int64) (*RandomCoffeeParticipant, error) {
 	query := "SELECT id, poll_id, user_id, is_participating, created_at, updated_at FROM random_coffee_participants WHERE poll_id = $1 AND user_id = $2"
 	row := r.db.QueryRow(query, pollID, userID)
 	p := &RandomCoffeeParticipant{}
@@ -57,7 +69,7 @@ func (r *RandomCoffeeParticipantRepository) GetParticipant(pollID int64, userID
 }
 
 // GetParticipatingUsers retrieves all users who are participating in a given poll
-func (r *RandomCoffeeParticipantRepository) GetParticipatingUsers(pollID int64) ([]User, error) {
+func (r *PostgresRandomCoffeeParticipantRepository) GetParticipatingUsers(pollID int64) ([]User, error) {
 	query := `
 		SELECT u.id, u.tg_id, u.firstname, u.lastname, u.tg_username 
 		FROM users u
@@ -15,15 +15,26 @@ type RandomCoffeePoll struct {
 	CreatedAt      time.Time `db:"created_at"`
 }
 
-type RandomCoffeePollRepository struct {
+// RandomCoffeePollRepository stores the weekly random coffee polls
+type RandomCoffeePollRepository interface {
+	CreatePoll(poll RandomCoffeePoll) (int64, error)
+	GetPollByTelegramPollID(telegramPollID string) (*RandomCoffeePoll, error)
+	GetLatestPoll() (*RandomCoffeePoll, error)
+}
+
+// Ensure PostgresRandomCoffeePollRepository implements RandomCoffeePollRepository interface
+var _ RandomCoffeePollRepository = (*PostgresRandomCoffeePollRepository)(nil)
+
+// PostgresRandomCoffeePollRepository handles database operations for random coffee polls
+type PostgresRandomCoffeePollRepository struct {
 	db *sql.DB
 }
 
-func NewRandomCoffeePollRepository(db *sql.DB) *RandomCoffeePollRepository {
-	return &RandomCoffeePollRepository{db: db}
+func NewRandomCoffeePollRepository(db *sql.DB) *PostgresRandomCoffeePollRepository {
+	return &PostgresRandomCoffeePollRepository{db: db}
 }
 
-func (r *RandomCoffeePollRepository) CreatePoll(poll RandomCoffeePoll) (int64, error) {
+func (r *PostgresRandomCoffeePollRepository) CreatePoll(poll RandomCoffeePoll) (int64, error) {
 	query := `
 		INSERT INTO random_coffee_polls (message_id, week_start_date, telegram_poll_id, created_at)
 		VALUES ($1, $2, $3, $4)
@@ -47,7 +58,7 @@ func (r *RandomCoffeePollRepository) CreatePoll(poll RandomCoffeePoll) (int64, e
 	return id, nil
 }
 
-func (r *RandomCoffeePollRepository) GetPollByTelegramPollID(telegramPollID string) (*RandomCoffeePoll, error) {
+func (r *PostgresRandomCoffeePollRepository) GetPollByTelegramPollID(telegramPollID string) (*RandomCoffeePoll, error) {
 	query := `
 		SELECT id, message_id, week_start_date, telegram_poll_id, created_at
 		FROM random_coffee_polls
@@ -71,7 +82,7 @@ func (r *RandomCoffeePollRepository) GetPollByTelegramPollID(telegramPollID stri
 }
 
 // GetLatestPoll retrieves the latest poll globally
-func (r *RandomCoffeePollRepository) GetLatestPoll() (*RandomCoffeePoll, error) {
+func (r *PostgresRandomCoffeePollRepository) GetLatestPoll() (*RandomCoffeePoll, error) {
 	query := `
 		SELECT id, message_id, week_start_date, telegram_poll_id, created_at
 		FROM random_coffee_polls
@@ -0,0 +1,947 @@
+// Package repositorytest is the contract test suite of the repositories. It is run against
+// the in-memory implementation and against PostgreSQL, so both behave the same.
+package repositorytest
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Repositories is a set of repositories sharing the same storage
+type Repositories struct {
+	Users                    repositories.UserRepository
+	Profiles                 repositories.ProfileRepository
+	Events                   repositories.EventRepository
+	RandomCoffeePolls        repositories.RandomCoffeePollRepository
+	RandomCoffeeParticipants repositories.RandomCoffeeParticipantRepository
+	RandomCoffeePairs        repositories.RandomCoffeePairRepository
+	UserRoles                repositories.UserRoleRepository
+	ForumTopics              repositories.ForumTopicRepository
+	Scores                   repositories.ScoreRepository
+}
+
+// Run runs the contract tests, newRepositories must return repositories over empty storage
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := map[string]func(t *testing.T, r Repositories){
+		"UserCreateAndGet":            testUserCreateAndGet,
+		"UserUpdate":                  testUserUpdate,
+		"UserBotBlocked":              testUserBotBlocked,
+		"UserGetOrCreate":             testUserGetOrCreate,
+		"UserDeleteCascades":          testUserDeleteCascades,
+		"ProfileCreateAndUpdate":      testProfileCreateAndUpdate,
+		"ProfileGetAllWithUsers":      testProfileGetAllWithUsers,
+		"ProfileFields":               testProfileFields,
+		"ProfileSearch":               testProfileSearch,
+		"EventCreateAndList":          testEventCreateAndList,
+		"EventUpdateAndDelete":        testEventUpdateAndDelete,
+		"EventRegistrationWaitlist":   testEventRegistrationWaitlist,
+		"EventRegistrationsWithUsers": testEventRegistrationsWithUsers,
+		"RandomCoffeePolls":           testRandomCoffeePolls,
+		"RandomCoffeeParticipants":    testRandomCoffeeParticipants,
+		"RandomCoffeePairs":           testRandomCoffeePairs,
+		"RandomCoffeePairsHistory":    testRandomCoffeePairsHistory,
+		"UserRoles":                   testUserRoles,
+		"ForumTopics":                 testForumTopics,
+		"ScoreLedger":                 testScoreLedger,
+		"ScoreDecay":                  testScoreDecay,
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepositories(t))
+		})
+	}
+}
+
+func createUser(t *testing.T, r Repositories, tgID int64, firstname string) *repositories.User {
+	t.Helper()
+
+	id, err := r.Users.Create(tgID, firstname, "Lastname", "user"+firstname)
+	require.NoError(t, err)
+	user, err := r.Users.GetByID(id)
+	require.NoError(t, err)
+	return user
+}
+
+func createPoll(t *testing.T, r Repositories, telegramPollID string, weekStartDate time.Time) int64 {
+	t.Helper()
+
+	id, err := r.RandomCoffeePolls.CreatePoll(repositories.RandomCoffeePoll{
+		MessageID:      100,
+		WeekStartDate:  weekStartDate,
+		TelegramPollID: telegramPollID,
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func week(n int) time.Time {
+	return time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 7*n)
+}
+
+func testUserCreateAndGet(t *testing.T, r Repositories) {
+	id, err := r.Users.Create(1001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+
+	user, err := r.Users.GetByID(id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1001), user.TgID)
+	assert.Equal(t, "Ivan", user.Firstname)
+	assert.Equal(t, "Petrov", user.Lastname)
+	assert.Equal(t, "ivan", user.TgUsername)
+	assert.Zero(t, user.Score)
+	assert.False(t, user.HasCoffeeBan)
+	assert.True(t, user.IsClubMember)
+	assert.Empty(t, user.Timezone)
+
+	byTgID, err := r.Users.GetByTelegramID(1001)
+	require.NoError(t, err)
+	assert.Equal(t, id, byTgID.ID)
+
+	byUsername, err := r.Users.GetByTelegramUsername("ivan")
+	require.NoError(t, err)
+	assert.Equal(t, id, byUsername.ID)
+
+	byName, err := r.Users.SearchByName("IVAN", "petrov")
+	require.NoError(t, err)
+	assert.Equal(t, id, byName.ID)
+
+	_, err = r.Users.GetByID(id + 1000)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = r.Users.GetByTelegramID(9999)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = r.Users.GetByTelegramUsername("nobody")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = r.Users.SearchByName("Nobody", "Petrov")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = r.Users.Create(1001, "Other", "", "")
+	assert.Error(t, err, "Telegram ID must be unique")
+}
+
+func testUserUpdate(t *testing.T, r Repositories) {
+	user := createUser(t, r, 1001, "Ivan")
+
+	require.NoError(t, r.Users.Update(user.ID, map[string]interface{}{"firstname": "Ivan2", "tg_username": "ivan2"}))
+	require.NoError(t, r.Users.UpdateScore(user.ID, 42))
+	require.NoError(t, r.Users.SetCoffeeBan(user.ID, true))
+	require.NoError(t, r.Users.SetClubMemberStatus(user.ID, false))
+	require.NoError(t, r.Users.UpdateLastname(user.ID, "Sidorov"))
+	require.NoError(t, r.Users.UpdateTimezone(user.ID, "Europe/Berlin"))
+
+	updated, err := r.Users.GetByID(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ivan2", updated.Firstname)
+	assert.Equal(t, "ivan2", updated.TgUsername)
+	assert.Equal(t, "Sidorov", updated.Lastname)
+	assert.Equal(t, 42, updated.Score)
+	assert.True(t, updated.HasCoffeeBan)
+	assert.False(t, updated.IsClubMember)
+	assert.Equal(t, "Europe/Berlin", updated.Timezone)
+
+	missingID := user.ID + 1000
+	assert.Error(t, r.Users.Update(user.ID, map[string]interface{}{}))
+	assert.Error(t, r.Users.Update(missingID, map[string]interface{}{"firstname": "X"}))
+	assert.Error(t, r.Users.UpdateScore(missingID, 1))
+	assert.Error(t, r.Users.SetCoffeeBan(missingID, true))
+	assert.Error(t, r.Users.SetClubMemberStatus(missingID, true))
+	assert.Error(t, r.Users.UpdateTelegramUsername(missingID, "x"))
+	assert.Error(t, r.Users.UpdateFirstname(missingID, "x"))
+	assert.Error(t, r.Users.UpdateLastname(missingID, "x"))
+	assert.Error(t, r.Users.UpdateTimezone(missingID, "UTC"))
+	assert.Error(t, r.Users.Delete(missingID))
+}
+
+func testUserBotBlocked(t *testing.T, r Repositories) {
+	createUser(t, r, 1001, "Ivan")
+	createUser(t, r, 1002, "Petr")
+
+	blocked, err := r.Users.GetBotBlockedTelegramIDs()
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	require.NoError(t, r.Users.SetBotBlocked(1001, true))
+	require.NoError(t, r.Users.SetBotBlocked(1002, true))
+	// Users not in the database are ignored
+	require.NoError(t, r.Users.SetBotBlocked(9999, true))
+	require.NoError(t, r.Users.SetBotBlocked(1002, false))
+
+	blocked, err = r.Users.GetBotBlockedTelegramIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1001}, blocked)
+}
+
+func testUserGetOrCreate(t *testing.T, r Repositories) {
+	tgUser := &gotgbot.User{Id: 1001, FirstName: "Ivan", LastName: "Petrov", Username: "ivan"}
+
+	created, err := r.Users.GetOrCreate(tgUser)
+	require.NoError(t, err)
+	assert.Equal(t, "Ivan", created.Firstname)
+
+	// Changed names are updated, empty ones are kept
+	changed := &gotgbot.User{Id: 1001, FirstName: "Ivan2", Username: "ivan2"}
+	updated, err := r.Users.GetOrCreate(changed)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, "Ivan2", updated.Firstname)
+	assert.Equal(t, "Petrov", updated.Lastname)
+	assert.Equal(t, "ivan2", updated.TgUsername)
+
+	stored, err := r.Users.GetByID(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ivan2", stored.Firstname)
+
+	user, profile, err := r.Users.GetOrFullCreate(&gotgbot.User{Id: 1002, FirstName: "Petr"})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, profile.UserID)
+	assert.Empty(t, profile.Bio)
+
+	again, err := r.Profiles.GetOrFullCreate(&gotgbot.User{Id: 1002, FirstName: "Petr"})
+	require.NoError(t, err)
+	assert.Equal(t, profile.ID, again.ID)
+}
+
+func testUserDeleteCascades(t *testing.T, r Repositories) {
+	user := createUser(t, r, 1001, "Ivan")
+	other := createUser(t, r, 1002, "Petr")
+	profile, err := r.Profiles.GetOrCreateWithBio(user.ID, "bio")
+	require.NoError(t, err)
+	eventID, err := r.Events.CreateEvent("Meetup", constants.EventTypeMeetup)
+	require.NoError(t, err)
+	_, err = r.Events.RegisterForEvent(eventID, user.ID)
+	require.NoError(t, err)
+	pollID := createPoll(t, r, "poll-1", week(0))
+	require.NoError(t, r.RandomCoffeeParticipants.UpsertParticipant(repositories.RandomCoffeeParticipant{
+		PollID: pollID, UserID: int64(user.ID), IsParticipating: true,
+	}))
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(int(pollID), user.ID, other.ID))
+	_, err = r.UserRoles.Grant(user.ID, constants.RoleModerator, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Users.Delete(user.ID))
+
+	_, err = r.Users.GetByID(user.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = r.Profiles.GetByID(profile.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	going, waitlist, err := r.Events.CountEventRegistrations(eventID)
+	require.NoError(t, err)
+	assert.Zero(t, going+waitlist)
+	participant, err := r.RandomCoffeeParticipants.GetParticipant(pollID, int64(user.ID))
+	require.NoError(t, err)
+	assert.Nil(t, participant)
+	_, err = r.RandomCoffeePairs.GetPairByPollAndUser(int(pollID), other.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	roles, err := r.UserRoles.GetAll()
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func testUserRoles(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	anna := createUser(t, r, 1002, "Anna")
+
+	_, err := r.UserRoles.Grant(ivan.ID+1000, constants.RoleModerator, 1)
+	assert.Error(t, err, "role needs an existing user")
+
+	granted, err := r.UserRoles.Grant(ivan.ID, constants.RoleModerator, 1)
+	require.NoError(t, err)
+	assert.True(t, granted)
+	granted, err = r.UserRoles.Grant(ivan.ID, constants.RoleModerator, 1)
+	require.NoError(t, err)
+	assert.False(t, granted, "the role is granted once")
+	_, err = r.UserRoles.Grant(ivan.ID, constants.RoleEventOrganizer, 1)
+	require.NoError(t, err)
+	_, err = r.UserRoles.Grant(anna.ID, constants.RoleModerator, 1)
+	require.NoError(t, err)
+
+	roles, err := r.UserRoles.GetRolesByTelegramID(ivan.TgID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []constants.Role{constants.RoleModerator, constants.RoleEventOrganizer}, roles)
+
+	all, err := r.UserRoles.GetAll()
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, string(constants.RoleEventOrganizer), all[0].Role.Role)
+	assert.Equal(t, "Anna", all[1].User.Firstname, "ordered by role, then by name")
+	assert.Equal(t, "Ivan", all[2].User.Firstname)
+	assert.Equal(t, int64(1), all[2].Role.GrantedByTgID.Int64)
+
+	require.NoError(t, r.UserRoles.Revoke(ivan.ID, constants.RoleModerator))
+	assert.ErrorIs(t, r.UserRoles.Revoke(ivan.ID, constants.RoleModerator), sql.ErrNoRows)
+	roles, err = r.UserRoles.GetRolesByTelegramID(ivan.TgID)
+	require.NoError(t, err)
+	assert.Equal(t, []constants.Role{constants.RoleEventOrganizer}, roles)
+}
+
+func testForumTopics(t *testing.T, r Repositories) {
+	createdAt := time.Date(2025, time.August, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, r.ForumTopics.Upsert(&repositories.ForumTopic{
+		TopicID: 42, Title: "Go", TopicCreatedAt: sql.NullTime{Time: createdAt, Valid: true},
+	}))
+	require.NoError(t, r.ForumTopics.Upsert(&repositories.ForumTopic{TopicID: 1, Title: "General"}))
+
+	// A service message has no creation date, the known one is kept
+	require.NoError(t, r.ForumTopics.Upsert(&repositories.ForumTopic{TopicID: 42, Title: "Golang", IsClosed: true}))
+
+	topics, err := r.ForumTopics.GetAll()
+	require.NoError(t, err)
+	require.Len(t, topics, 2)
+	assert.Equal(t, 1, topics[0].TopicID, "ordered by ID")
+	assert.False(t, topics[0].TopicCreatedAt.Valid)
+	assert.Equal(t, "Golang", topics[1].Title)
+	assert.True(t, topics[1].IsClosed)
+	assert.True(t, createdAt.Equal(topics[1].TopicCreatedAt.Time))
+	assert.False(t, topics[1].UpdatedAt.IsZero())
+
+	deleted, err := r.ForumTopics.DeleteAllExcept([]int{42, 100})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	topics, err = r.ForumTopics.GetAll()
+	require.NoError(t, err)
+	require.Len(t, topics, 1)
+	assert.Equal(t, 42, topics[0].TopicID)
+}
+
+func testScoreLedger(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	petr := createUser(t, r, 1002, "Petr")
+	anna := createUser(t, r, 1003, "Anna")
+	require.NoError(t, r.Users.SetClubMemberStatus(anna.ID, false))
+
+	_, err := r.Scores.AddEntry(ivan.ID+1000, 5, constants.ScoreReasonTopicProposed, "topic:1", "")
+	assert.Error(t, err, "the entry needs an existing user")
+
+	// An activity is rewarded once, entries without a reference are always added
+	for _, entry := range []struct {
+		userID    int
+		points    int
+		reason    constants.ScoreReason
+		reference string
+		want      bool
+	}{
+		{userID: ivan.ID, points: 5, reason: constants.ScoreReasonEventRegistered, reference: "event:1", want: true},
+		{userID: ivan.ID, points: 5, reason: constants.ScoreReasonEventRegistered, reference: "event:1", want: false},
+		{userID: ivan.ID, points: 2, reason: constants.ScoreReasonTopicProposed, reference: "event:1", want: true},
+		{userID: petr.ID, points: 5, reason: constants.ScoreReasonEventRegistered, reference: "event:1", want: true},
+		{userID: petr.ID, points: -1, reason: constants.ScoreReasonAdminAdjustment, want: true},
+		{userID: petr.ID, points: -1, reason: constants.ScoreReasonAdminAdjustment, want: true},
+		{userID: anna.ID, points: 50, reason: constants.ScoreReasonAdminAdjustment, want: true},
+	} {
+		added, err := r.Scores.AddEntry(entry.userID, entry.points, entry.reason, entry.reference, "")
+		require.NoError(t, err)
+		assert.Equal(t, entry.want, added, "%s %s", entry.reason, entry.reference)
+	}
+
+	ivan, err = r.Users.GetByID(ivan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 7, ivan.Score)
+
+	history, err := r.Scores.GetHistory(petr.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, string(constants.ScoreReasonAdminAdjustment), history[0].Reason, "the latest entry first")
+	assert.Greater(t, history[0].ID, history[1].ID)
+
+	// Former club members are not ranked
+	leaderboard, err := r.Scores.GetLeaderboard(10)
+	require.NoError(t, err)
+	require.Len(t, leaderboard, 2)
+	assert.Equal(t, ivan.ID, leaderboard[0].ID)
+	assert.Equal(t, petr.ID, leaderboard[1].ID)
+	assert.Equal(t, 3, leaderboard[1].Score)
+
+	rank, err := r.Scores.GetRank(petr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rank)
+}
+
+func testScoreDecay(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	petr := createUser(t, r, 1002, "Petr")
+	_, err := r.Scores.AddEntry(ivan.ID, 25, constants.ScoreReasonAdminAdjustment, "", "")
+	require.NoError(t, err)
+	_, err = r.Scores.AddEntry(petr.ID, 9, constants.ScoreReasonAdminAdjustment, "", "")
+	require.NoError(t, err)
+
+	last, err := r.Scores.GetLastDecayReference()
+	require.NoError(t, err)
+	assert.Empty(t, last)
+
+	// Scores with less than one point to take are left as is
+	affected, err := r.Scores.ApplyDecay(10, "decay:2025-08")
+	require.NoError(t, err)
+	assert.Equal(t, 1, affected)
+	affected, err = r.Scores.ApplyDecay(10, "decay:2025-08")
+	require.NoError(t, err)
+	assert.Zero(t, affected, "the decay of a month is applied once")
+
+	ivan, err = r.Users.GetByID(ivan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 23, ivan.Score)
+	petr, err = r.Users.GetByID(petr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 9, petr.Score)
+
+	_, err = r.Scores.ApplyDecay(10, "decay:2025-09")
+	require.NoError(t, err)
+	last, err = r.Scores.GetLastDecayReference()
+	require.NoError(t, err)
+	assert.Equal(t, "decay:2025-09", last)
+}
+
+func testProfileCreateAndUpdate(t *testing.T, r Repositories) {
+	user := createUser(t, r, 1001, "Ivan")
+
+	_, err := r.Profiles.Create(user.ID+1000, "bio")
+	assert.Error(t, err, "profile needs an existing user")
+	_, err = r.Profiles.GetOrCreate(user.ID + 1000)
+	assert.Error(t, err)
+
+	profile, err := r.Profiles.GetOrCreateWithBio(user.ID, "first bio")
+	require.NoError(t, err)
+	assert.Equal(t, "first bio", profile.Bio)
+	assert.False(t, profile.PublishedMessageID.Valid)
+
+	// An existing profile is returned as is
+	existing, err := r.Profiles.GetOrCreateWithBio(user.ID, "second bio")
+	require.NoError(t, err)
+	assert.Equal(t, profile.ID, existing.ID)
+	assert.Equal(t, "first bio", existing.Bio)
+
+	require.NoError(t, r.Profiles.Update(profile.ID, map[string]interface{}{"bio": "new bio"}))
+	require.NoError(t, r.Profiles.UpdatePublishedMessageID(profile.ID, 555))
+
+	updated, err := r.Profiles.GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new bio", updated.Bio)
+	assert.Equal(t, sql.NullInt64{Int64: 555, Valid: true}, updated.PublishedMessageID)
+
+	missingID := profile.ID + 1000
+	assert.Error(t, r.Profiles.Update(profile.ID, map[string]interface{}{}))
+	assert.Error(t, r.Profiles.Update(missingID, map[string]interface{}{"bio": "x"}))
+	assert.Error(t, r.Profiles.UpdatePublishedMessageID(missingID, 1))
+	_, err = r.Profiles.GetByID(missingID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, r.Profiles.Delete(profile.ID))
+	assert.Error(t, r.Profiles.Delete(profile.ID))
+}
+
+func testProfileGetAllWithUsers(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	petr := createUser(t, r, 1002, "Petr")
+	anna := createUser(t, r, 1003, "Anna")
+
+	ivanProfile, err := r.Profiles.GetOrCreateWithBio(ivan.ID, "Ivan bio")
+	require.NoError(t, err)
+	_, err = r.Profiles.GetOrCreateWithBio(petr.ID, "Petr bio")
+	require.NoError(t, err)
+	_, err = r.Profiles.GetOrCreate(anna.ID)
+	require.NoError(t, err)
+
+	// The most recently updated first, profiles without bio are skipped
+	require.NoError(t, r.Profiles.Update(ivanProfile.ID, map[string]interface{}{"bio": "Ivan new bio"}))
+
+	profiles, err := r.Profiles.GetAllWithUsers()
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, ivan.ID, profiles[0].User.ID)
+	assert.Equal(t, "Ivan new bio", profiles[0].Profile.Bio)
+	assert.Equal(t, "Ivan", profiles[0].User.Firstname)
+	assert.Equal(t, petr.ID, profiles[1].User.ID)
+}
+
+func testProfileFields(t *testing.T, r Repositories) {
+	user := createUser(t, r, 1001, "Ivan")
+	profile, err := r.Profiles.GetOrCreate(user.ID)
+	require.NoError(t, err)
+
+	// New profiles have empty optional fields
+	assert.Empty(t, profile.City)
+	assert.Empty(t, profile.JobTitle)
+	assert.Equal(t, repositories.ProfileTags{}, profile.Skills)
+	assert.Equal(t, repositories.ProfileLinks{}, profile.Links)
+	assert.Empty(t, profile.PhotoFileID)
+	assert.False(t, profile.PublishedWithPhoto)
+	assert.False(t, profile.PublishedExtraMessageID.Valid)
+
+	require.NoError(t, r.Profiles.Update(profile.ID, map[string]interface{}{
+		"city":          "Berlin",
+		"country":       "Germany",
+		"job_title":     "Backend developer",
+		"company":       "Acme",
+		"skills":        repositories.ProfileTags{"Go", "PostgreSQL"},
+		"can_help_with": repositories.ProfileTags{"code review"},
+		"looking_for":   repositories.ProfileTags{"co-founder"},
+		"languages":     repositories.ProfileTags{"русский", "English"},
+		"links":         repositories.ProfileLinks{"github": "https://github.com/ivan"},
+		"photo_file_id": "photo-file-id",
+	}))
+
+	updated, err := r.Profiles.GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin", updated.City)
+	assert.Equal(t, "Germany", updated.Country)
+	assert.Equal(t, "Backend developer", updated.JobTitle)
+	assert.Equal(t, "Acme", updated.Company)
+	assert.Equal(t, repositories.ProfileTags{"Go", "PostgreSQL"}, updated.Skills)
+	assert.Equal(t, repositories.ProfileTags{"code review"}, updated.CanHelpWith)
+	assert.Equal(t, repositories.ProfileTags{"co-founder"}, updated.LookingFor)
+	assert.Equal(t, repositories.ProfileTags{"русский", "English"}, updated.Languages)
+	assert.Equal(t, repositories.ProfileLinks{"github": "https://github.com/ivan"}, updated.Links)
+	assert.Equal(t, "photo-file-id", updated.PhotoFileID)
+
+	// The published photo message with the follow-up message, then the text message without it
+	require.NoError(t, r.Profiles.Update(profile.ID, map[string]interface{}{
+		"published_message_id":       int64(10),
+		"published_with_photo":       true,
+		"published_extra_message_id": int64(11),
+	}))
+	updated, err = r.Profiles.GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sql.NullInt64{Int64: 10, Valid: true}, updated.PublishedMessageID)
+	assert.True(t, updated.PublishedWithPhoto)
+	assert.Equal(t, sql.NullInt64{Int64: 11, Valid: true}, updated.PublishedExtraMessageID)
+
+	require.NoError(t, r.Profiles.Update(profile.ID, map[string]interface{}{
+		"published_with_photo":       false,
+		"published_extra_message_id": nil,
+	}))
+	updated, err = r.Profiles.GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.False(t, updated.PublishedWithPhoto)
+	assert.False(t, updated.PublishedExtraMessageID.Valid)
+
+	// Cleared tags are stored as empty, not as NULL
+	require.NoError(t, r.Profiles.Update(profile.ID, map[string]interface{}{
+		"skills": repositories.ProfileTags(nil),
+		"links":  repositories.ProfileLinks{},
+	}))
+	updated, err = r.Profiles.GetByID(profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.ProfileTags{}, updated.Skills)
+	assert.Equal(t, repositories.ProfileLinks{}, updated.Links)
+	assert.Equal(t, repositories.ProfileTags{"co-founder"}, updated.LookingFor)
+}
+
+func testProfileSearch(t *testing.T, r Repositories) {
+	createProfile := func(tgID int64, firstname, lastname string, fields map[string]interface{}) int {
+		t.Helper()
+		userID, err := r.Users.Create(tgID, firstname, lastname, "")
+		require.NoError(t, err)
+		profile, err := r.Profiles.GetOrCreate(userID)
+		require.NoError(t, err)
+		require.NoError(t, r.Profiles.Update(profile.ID, fields))
+		return userID
+	}
+
+	ivan := createProfile(1001, "Ivan", "Petrov", map[string]interface{}{
+		"bio":       "Пишу бэкенд, люблю Kubernetes",
+		"job_title": "Backend developer",
+		"city":      "Berlin",
+		"skills":    repositories.ProfileTags{"Go", "PostgreSQL"},
+	})
+	anna := createProfile(1002, "Anna", "Smirnova", map[string]interface{}{
+		"bio":           "Дизайнер интерфейсов",
+		"city":          "Berlin",
+		"can_help_with": repositories.ProfileTags{"Figma"},
+	})
+	oleg := createProfile(1003, "Oleg", "Sidorov", map[string]interface{}{
+		"bio":    "Frontend на React",
+		"city":   "Москва",
+		"skills": repositories.ProfileTags{"React", "go"},
+	})
+	require.NoError(t, r.Users.SetClubMemberStatus(oleg, false))
+	// Profiles without a bio are not in the directory
+	createProfile(1004, "Dmitry", "Kubernetes", map[string]interface{}{"city": "Berlin"})
+
+	search := func(filter repositories.ProfileSearchFilter, limit, offset int) ([]int, int) {
+		t.Helper()
+		profiles, total, err := r.Profiles.Search(filter, limit, offset)
+		require.NoError(t, err)
+		var userIDs []int
+		for _, p := range profiles {
+			userIDs = append(userIDs, p.User.ID)
+		}
+		return userIDs, total
+	}
+
+	found, total := search(repositories.ProfileSearchFilter{Query: "kubernetes"}, 10, 0)
+	assert.Equal(t, []int{ivan}, found)
+	assert.Equal(t, 1, total)
+
+	// A typo in the name
+	found, _ = search(repositories.ProfileSearchFilter{Query: "Petrv"}, 10, 0)
+	assert.Equal(t, []int{ivan}, found)
+
+	found, _ = search(repositories.ProfileSearchFilter{Query: "дизайнер or react"}, 10, 0)
+	assert.ElementsMatch(t, []int{anna, oleg}, found)
+
+	found, _ = search(repositories.ProfileSearchFilter{Tag: "GO"}, 10, 0)
+	assert.ElementsMatch(t, []int{ivan, oleg}, found)
+
+	isMember := true
+	found, _ = search(repositories.ProfileSearchFilter{Tag: "go", IsClubMember: &isMember}, 10, 0)
+	assert.Equal(t, []int{ivan}, found)
+
+	found, _ = search(repositories.ProfileSearchFilter{City: "berlin"}, 10, 0)
+	assert.ElementsMatch(t, []int{ivan, anna}, found)
+
+	found, total = search(repositories.ProfileSearchFilter{Query: "blockchain"}, 10, 0)
+	assert.Empty(t, found)
+	assert.Zero(t, total)
+
+	// Pages do not overlap and the total is the same on every page, even past the end
+	first, total := search(repositories.ProfileSearchFilter{}, 2, 0)
+	assert.Len(t, first, 2)
+	assert.Equal(t, 3, total)
+	second, total := search(repositories.ProfileSearchFilter{}, 2, 2)
+	assert.Len(t, second, 1)
+	assert.Equal(t, 3, total)
+	assert.ElementsMatch(t, []int{ivan, anna, oleg}, append(first, second...))
+	found, total = search(repositories.ProfileSearchFilter{}, 2, 4)
+	assert.Empty(t, found)
+	assert.Equal(t, 3, total)
+}
+
+func testEventCreateAndList(t *testing.T, r Repositories) {
+	zone := time.FixedZone("MSK", 3*60*60)
+	early := time.Date(2025, time.September, 1, 19, 0, 0, 0, zone)
+	late := time.Date(2025, time.September, 10, 19, 0, 0, 0, zone)
+
+	noDateID, err := r.Events.CreateEvent("No date", constants.EventTypeClubCall)
+	require.NoError(t, err)
+	lateID, err := r.Events.CreateEventWithStartedAt("Late", constants.EventTypeMeetup, late)
+	require.NoError(t, err)
+	earlyID, err := r.Events.CreateEventWithStartedAt("Early", constants.EventTypeWorkshop, early)
+	require.NoError(t, err)
+	finishedID, err := r.Events.CreateEventWithStartedAt("Finished", constants.EventTypeMeetup, early.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	require.NoError(t, r.Events.UpdateEventStatus(finishedID, constants.EventStatusFinished))
+
+	noDate, err := r.Events.GetEventByID(noDateID)
+	require.NoError(t, err)
+	assert.Equal(t, "No date", noDate.Name)
+	assert.Equal(t, string(constants.EventTypeClubCall), noDate.Type)
+	assert.Equal(t, string(constants.EventStatusActual), noDate.Status)
+	assert.Equal(t, "UTC", noDate.Timezone)
+	assert.Nil(t, noDate.StartedAt)
+	assert.Nil(t, noDate.Capacity)
+
+	lateEvent, err := r.Events.GetEventByID(lateID)
+	require.NoError(t, err)
+	require.NotNil(t, lateEvent.StartedAt)
+	assert.True(t, late.Equal(*lateEvent.StartedAt))
+	assert.Equal(t, "MSK", lateEvent.Timezone)
+
+	// Actual events: the earliest first, without date last
+	actual, err := r.Events.GetLastActualEvents(10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{earlyID, lateID, noDateID}, eventIDs(actual))
+
+	limited, err := r.Events.GetLastActualEvents(1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{earlyID}, eventIDs(limited))
+
+	// All events: the latest first, without date last
+	all, err := r.Events.GetLastEvents(10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{lateID, earlyID, finishedID, noDateID}, eventIDs(all))
+
+	_, err = r.Events.GetEventByID(noDateID + 1000)
+	assert.Error(t, err)
+}
+
+func eventIDs(events []repositories.Event) []int {
+	ids := make([]int, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func testEventUpdateAndDelete(t *testing.T, r Repositories) {
+	id, err := r.Events.CreateEvent("Call", constants.EventTypeClubCall)
+	require.NoError(t, err)
+
+	startedAt := time.Date(2025, time.October, 1, 18, 30, 0, 0, time.UTC)
+	require.NoError(t, r.Events.UpdateEventName(id, "Club call"))
+	require.NoError(t, r.Events.UpdateEventType(id, constants.EventTypeMeetup))
+	require.NoError(t, r.Events.UpdateEventStartedAt(id, startedAt))
+
+	event, err := r.Events.GetEventByID(id)
+	require.NoError(t, err)
+	assert.Equal(t, "Club call", event.Name)
+	assert.Equal(t, string(constants.EventTypeMeetup), event.Type)
+	require.NotNil(t, event.StartedAt)
+	assert.True(t, startedAt.Equal(*event.StartedAt))
+
+	missingID := id + 1000
+	assert.Error(t, r.Events.UpdateEventName(missingID, "x"))
+	assert.Error(t, r.Events.UpdateEventStatus(missingID, constants.EventStatusFinished))
+	assert.Error(t, r.Events.UpdateEventType(missingID, constants.EventTypeMeetup))
+	assert.Error(t, r.Events.UpdateEventStartedAt(missingID, startedAt))
+	_, err = r.Events.UpdateEventCapacity(missingID, nil)
+	assert.Error(t, err)
+
+	require.NoError(t, r.Events.DeleteEvent(id))
+	_, err = r.Events.GetEventByID(id)
+	assert.Error(t, err)
+	assert.Error(t, r.Events.DeleteEvent(id))
+}
+
+func testEventRegistrationWaitlist(t *testing.T, r Repositories) {
+	first := createUser(t, r, 1001, "Ivan")
+	second := createUser(t, r, 1002, "Petr")
+	third := createUser(t, r, 1003, "Anna")
+
+	eventID, err := r.Events.CreateEvent("Meetup", constants.EventTypeMeetup)
+	require.NoError(t, err)
+	capacity := 1
+	promoted, err := r.Events.UpdateEventCapacity(eventID, &capacity)
+	require.NoError(t, err)
+	assert.Empty(t, promoted)
+
+	firstRegistration, err := r.Events.RegisterForEvent(eventID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(constants.EventRegistrationStatusGoing), firstRegistration.Status)
+
+	secondRegistration, err := r.Events.RegisterForEvent(eventID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(constants.EventRegistrationStatusWaitlist), secondRegistration.Status)
+
+	_, err = r.Events.RegisterForEvent(eventID, third.ID)
+	require.NoError(t, err)
+
+	// Registering again returns the existing registration
+	again, err := r.Events.RegisterForEvent(eventID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, secondRegistration.ID, again.ID)
+
+	going, waitlist, err := r.Events.CountEventRegistrations(eventID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, going)
+	assert.Equal(t, 2, waitlist)
+
+	// The freed place goes to the earliest waitlisted registration
+	promoted, err = r.Events.CancelEventRegistration(eventID, first.ID)
+	require.NoError(t, err)
+	require.Len(t, promoted, 1)
+	assert.Equal(t, second.ID, promoted[0].UserID)
+	assert.Equal(t, string(constants.EventRegistrationStatusGoing), promoted[0].Status)
+
+	registration, err := r.Events.GetEventRegistration(eventID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(constants.EventRegistrationStatusGoing), registration.Status)
+	_, err = r.Events.GetEventRegistration(eventID, first.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = r.Events.CancelEventRegistration(eventID, first.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Removing the limit gives everybody a place
+	promoted, err = r.Events.UpdateEventCapacity(eventID, nil)
+	require.NoError(t, err)
+	require.Len(t, promoted, 1)
+	assert.Equal(t, third.ID, promoted[0].UserID)
+
+	going, waitlist, err = r.Events.CountEventRegistrations(eventID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, going)
+	assert.Zero(t, waitlist)
+
+	_, err = r.Events.RegisterForEvent(eventID+1000, first.ID)
+	assert.Error(t, err)
+
+	// Deleting the event deletes its registrations
+	require.NoError(t, r.Events.DeleteEvent(eventID))
+	going, waitlist, err = r.Events.CountEventRegistrations(eventID)
+	require.NoError(t, err)
+	assert.Zero(t, going+waitlist)
+}
+
+func testEventRegistrationsWithUsers(t *testing.T, r Repositories) {
+	first := createUser(t, r, 1001, "Ivan")
+	second := createUser(t, r, 1002, "Petr")
+	third := createUser(t, r, 1003, "Anna")
+	profile, err := r.Profiles.GetOrCreateWithBio(third.ID, "bio")
+	require.NoError(t, err)
+	require.NoError(t, r.Profiles.UpdatePublishedMessageID(profile.ID, 777))
+
+	eventID, err := r.Events.CreateEvent("Workshop", constants.EventTypeWorkshop)
+	require.NoError(t, err)
+	capacity := 1
+	_, err = r.Events.UpdateEventCapacity(eventID, &capacity)
+	require.NoError(t, err)
+
+	for _, user := range []*repositories.User{first, second, third} {
+		_, err := r.Events.RegisterForEvent(eventID, user.ID)
+		require.NoError(t, err)
+	}
+	_, err = r.Events.CancelEventRegistration(eventID, first.ID)
+	require.NoError(t, err)
+	_, err = r.Events.RegisterForEvent(eventID, first.ID)
+	require.NoError(t, err)
+
+	// Going first, then the waitlist in registration order
+	registrations, err := r.Events.GetEventRegistrationsWithUsers(eventID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 3)
+	assert.Equal(t, second.ID, registrations[0].User.ID)
+	assert.Equal(t, string(constants.EventRegistrationStatusGoing), registrations[0].Registration.Status)
+	assert.Equal(t, third.ID, registrations[1].User.ID)
+	assert.Equal(t, sql.NullInt64{Int64: 777, Valid: true}, registrations[1].ProfilePublishedMessageID)
+	assert.Equal(t, first.ID, registrations[2].User.ID)
+	assert.False(t, registrations[2].ProfilePublishedMessageID.Valid)
+	assert.Equal(t, "Ivan", registrations[2].User.Firstname)
+}
+
+func testRandomCoffeePolls(t *testing.T, r Repositories) {
+	latest, err := r.RandomCoffeePolls.GetLatestPoll()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	olderID := createPoll(t, r, "poll-1", week(0))
+	newerID := createPoll(t, r, "poll-2", week(1))
+
+	latest, err = r.RandomCoffeePolls.GetLatestPoll()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, newerID, latest.ID)
+	assert.True(t, week(1).Equal(latest.WeekStartDate))
+
+	poll, err := r.RandomCoffeePolls.GetPollByTelegramPollID("poll-1")
+	require.NoError(t, err)
+	require.NotNil(t, poll)
+	assert.Equal(t, olderID, poll.ID)
+	assert.Equal(t, int64(100), poll.MessageID)
+	assert.False(t, poll.CreatedAt.IsZero())
+
+	missing, err := r.RandomCoffeePolls.GetPollByTelegramPollID("missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = r.RandomCoffeePolls.CreatePoll(repositories.RandomCoffeePoll{MessageID: 1, WeekStartDate: week(2), TelegramPollID: "poll-1"})
+	assert.Error(t, err, "Telegram poll ID must be unique")
+}
+
+func testRandomCoffeeParticipants(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	petr := createUser(t, r, 1002, "Petr")
+	pollID := createPoll(t, r, "poll-1", week(0))
+
+	participant, err := r.RandomCoffeeParticipants.GetParticipant(pollID, int64(ivan.ID))
+	require.NoError(t, err)
+	assert.Nil(t, participant)
+
+	require.NoError(t, r.RandomCoffeeParticipants.UpsertParticipant(repositories.RandomCoffeeParticipant{
+		PollID: pollID, UserID: int64(ivan.ID), IsParticipating: true,
+	}))
+	require.NoError(t, r.RandomCoffeeParticipants.UpsertParticipant(repositories.RandomCoffeeParticipant{
+		PollID: pollID, UserID: int64(petr.ID), IsParticipating: true,
+	}))
+	// Answering again updates the participant
+	require.NoError(t, r.RandomCoffeeParticipants.UpsertParticipant(repositories.RandomCoffeeParticipant{
+		PollID: pollID, UserID: int64(petr.ID), IsParticipating: false,
+	}))
+
+	participant, err = r.RandomCoffeeParticipants.GetParticipant(pollID, int64(petr.ID))
+	require.NoError(t, err)
+	require.NotNil(t, participant)
+	assert.False(t, participant.IsParticipating)
+
+	users, err := r.RandomCoffeeParticipants.GetParticipatingUsers(pollID)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, ivan.ID, users[0].ID)
+	assert.Equal(t, ivan.TgID, users[0].TgID)
+	assert.Equal(t, ivan.TgUsername, users[0].TgUsername)
+
+	require.NoError(t, r.RandomCoffeeParticipants.RemoveParticipant(pollID, int64(ivan.ID)))
+	users, err = r.RandomCoffeeParticipants.GetParticipatingUsers(pollID)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	err = r.RandomCoffeeParticipants.UpsertParticipant(repositories.RandomCoffeeParticipant{
+		PollID: pollID + 1000, UserID: int64(ivan.ID), IsParticipating: true,
+	})
+	assert.Error(t, err, "participant needs an existing poll")
+}
+
+func testRandomCoffeePairs(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	petr := createUser(t, r, 1002, "Petr")
+	anna := createUser(t, r, 1003, "Anna")
+	firstPollID := int(createPoll(t, r, "poll-1", week(0)))
+	secondPollID := int(createPoll(t, r, "poll-2", week(1)))
+
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(firstPollID, ivan.ID, petr.ID))
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(secondPollID, ivan.ID, petr.ID))
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(secondPollID, anna.ID, anna.ID))
+	assert.Error(t, r.RandomCoffeePairs.CreatePair(secondPollID, ivan.ID, petr.ID), "pair must be unique in a poll")
+
+	pair, err := r.RandomCoffeePairs.GetPairByPollAndUser(firstPollID, petr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, firstPollID, pair.PollID)
+	assert.Equal(t, int64(ivan.ID), pair.User1ID)
+	assert.Equal(t, int64(petr.ID), pair.User2ID)
+
+	_, err = r.RandomCoffeePairs.GetPairByPollAndUser(firstPollID, anna.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The most recent poll in either order of the users
+	pollID, err := r.RandomCoffeePairs.GetMostRecentPairPoll(petr.ID, ivan.ID, 4)
+	require.NoError(t, err)
+	assert.Equal(t, secondPollID, pollID)
+
+	pollID, err = r.RandomCoffeePairs.GetMostRecentPairPoll(ivan.ID, anna.ID, 4)
+	require.NoError(t, err)
+	assert.Zero(t, pollID, "never paired")
+}
+
+func testRandomCoffeePairsHistory(t *testing.T, r Repositories) {
+	ivan := createUser(t, r, 1001, "Ivan")
+	petr := createUser(t, r, 1002, "Petr")
+	anna := createUser(t, r, 1003, "Anna")
+	olga := createUser(t, r, 1004, "Olga")
+	oldPollID := int(createPoll(t, r, "poll-1", week(0)))
+	midPollID := int(createPoll(t, r, "poll-2", week(1)))
+	newPollID := int(createPoll(t, r, "poll-3", week(2)))
+
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(oldPollID, ivan.ID, anna.ID))
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(midPollID, ivan.ID, petr.ID))
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(newPollID, petr.ID, anna.ID))
+	require.NoError(t, r.RandomCoffeePairs.CreatePair(newPollID, ivan.ID, olga.ID))
+
+	history, err := r.RandomCoffeePairs.GetPairsHistoryForUsers([]int{ivan.ID, petr.ID, anna.ID, olga.ID}, 2)
+	require.NoError(t, err)
+
+	// Only the pairs of the last 2 polls, keyed in both directions
+	key := func(a, b *repositories.User) string {
+		return fmt.Sprintf("%d-%d", a.ID, b.ID)
+	}
+	assert.Len(t, history, 6)
+	assert.Equal(t, []int{midPollID}, history[key(ivan, petr)])
+	assert.Equal(t, []int{midPollID}, history[key(petr, ivan)])
+	assert.Equal(t, []int{newPollID}, history[key(anna, petr)])
+	assert.Equal(t, []int{newPollID}, history[key(olga, ivan)])
+	assert.NotContains(t, history, key(ivan, anna))
+
+	pollID, err := r.RandomCoffeePairs.GetMostRecentPairPoll(ivan.ID, anna.ID, 2)
+	require.NoError(t, err)
+	assert.Zero(t, pollID, "paired before the last 2 polls")
+
+	empty, err := r.RandomCoffeePairs.GetPairsHistoryForUsers(nil, 2)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"time"
+)
+
+// ScoreLedgerEntry represents a row in the score_ledger table
+type ScoreLedgerEntry struct {
+	ID        int
+	UserID    int
+	Points    int
+	Reason    string
+	Reference string
+	Comment   string
+	CreatedAt time.Time
+}
+
+// ScoreRepository stores the score ledger and keeps the user scores in sync with it
+type ScoreRepository interface {
+	AddEntry(userID int, points int, reason constants.ScoreReason, reference string, comment string) (bool, error)
+	GetHistory(userID int, limit int) ([]ScoreLedgerEntry, error)
+	GetLeaderboard(limit int) ([]User, error)
+	GetRank(userID int) (int, error)
+	ApplyDecay(percent int, reference string) (int, error)
+	GetLastDecayReference() (string, error)
+}
+
+// Ensure PostgresScoreRepository implements ScoreRepository interface
+var _ ScoreRepository = (*PostgresScoreRepository)(nil)
+
+// PostgresScoreRepository handles database operations for the score ledger
+type PostgresScoreRepository struct {
+	db *sql.DB
+}
+
+// NewScoreRepository creates a new PostgresScoreRepository
+func NewScoreRepository(db *sql.DB) *PostgresScoreRepository {
+	return &PostgresScoreRepository{db: db}
+}
+
+// AddEntry adds a ledger entry and updates the user's score in one transaction.
+// Entries with a non-empty reference are added only once per user and reason,
+// the returned flag is false when such an entry already exists.
+func (r *PostgresScoreRepository) AddEntry(userID int, points int, reason constants.ScoreReason, reference string, comment string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to begin transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO score_ledger (user_id, points, reason, reference, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING`
+	result, err := tx.Exec(query, userID, points, reason, reference, comment)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to insert score ledger entry for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: could not get rows affected after insert: %w", utils.GetCurrentTypeName(), err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	result, err = tx.Exec(`UPDATE users SET score = score + $1, updated_at = NOW() WHERE id = $2`, points, userID)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to update score for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: could not get rows affected after update: %w", utils.GetCurrentTypeName(), err)
+	} else if rowsAffected == 0 {
+		return false, fmt.Errorf("%s: no user found with ID %d to update score", utils.GetCurrentTypeName(), userID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("%s: failed to commit transaction: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return true, nil
+}
+
+// GetHistory retrieves the last ledger entries of a user
+func (r *PostgresScoreRepository) GetHistory(userID int, limit int) ([]ScoreLedgerEntry, error) {
+	query := `
+		SELECT id, user_id, points, reason, reference, comment, created_at
+		FROM score_ledger
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query score history for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+	defer rows.Close()
+
+	var entries []ScoreLedgerEntry
+	for rows.Next() {
+		var entry ScoreLedgerEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Points,
+			&entry.Reason,
+			&entry.Reference,
+			&entry.Comment,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan score ledger entry: %w", utils.GetCurrentTypeName(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over score ledger entries: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return entries, nil
+}
+
+// GetLeaderboard retrieves club members with the highest positive score
+func (r *PostgresScoreRepository) GetLeaderboard(limit int) ([]User, error) {
+	query := `
+		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, timezone, created_at, updated_at
+		FROM users
+		WHERE score > 0 AND is_club_member = TRUE
+		ORDER BY score DESC, id ASC
+		LIMIT $1`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query leaderboard: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(
+			&user.ID,
+			&user.TgID,
+			&user.Firstname,
+			&user.Lastname,
+			&user.TgUsername,
+			&user.Score,
+			&user.HasCoffeeBan,
+			&user.IsClubMember,
+			&user.Timezone,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan leaderboard user: %w", utils.GetCurrentTypeName(), err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over leaderboard users: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return users, nil
+}
+
+// GetRank returns the 1-based position of a user in the leaderboard
+func (r *PostgresScoreRepository) GetRank(userID int) (int, error) {
+	query := `
+		SELECT COUNT(*) + 1
+		FROM users
+		WHERE is_club_member = TRUE AND score > (SELECT score FROM users WHERE id = $1)`
+
+	var rank int
+	if err := r.db.QueryRow(query, userID).Scan(&rank); err != nil {
+		return 0, fmt.Errorf("%s: failed to get rank for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	return rank, nil
+}
+
+// ApplyDecay takes the given percent of positive scores away, once per reference (e.g. month).
+// Returns the number of affected users.
+func (r *PostgresScoreRepository) ApplyDecay(percent int, reference string) (int, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO score_ledger (user_id, points, reason, reference, comment)
+			SELECT id, -FLOOR(score * $1 / 100.0)::INTEGER, $2, $3, $4
+			FROM users
+			WHERE FLOOR(score * $1 / 100.0) >= 1
+			ON CONFLICT DO NOTHING
+			RETURNING user_id, points
+		)
+		UPDATE users u
+		SET score = u.score + i.points, updated_at = NOW()
+		FROM inserted i
+		WHERE u.id = i.user_id`
+
+	result, err := r.db.Exec(query, percent, constants.ScoreReasonDecay, reference, fmt.Sprintf("Снижение на %d%%", percent))
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to apply score decay: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: could not get rows affected after decay: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// GetLastDecayReference returns the reference of the latest applied decay, or an empty string if there was none
+func (r *PostgresScoreRepository) GetLastDecayReference() (string, error) {
+	var reference string
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(reference), '') FROM score_ledger WHERE reason = $1`, constants.ScoreReasonDecay).Scan(&reference)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get last decay reference: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return reference, nil
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"database/sql"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// Thanks represents a row in the thanks table
+type Thanks struct {
+	ID             int
+	GiverUserID    int
+	ReceiverUserID int
+	ChatID         int64
+	MessageID      int64
+	Source         string
+	CreatedAt      time.Time
+}
+
+// ThanksStats contains counters of thanks of a user
+type ThanksStats struct {
+	Received int
+	Given    int
+}
+
+// ThanksRepository handles database operations for thanks between members
+type ThanksRepository struct {
+	db *sql.DB
+}
+
+// NewThanksRepository creates a new ThanksRepository
+func NewThanksRepository(db *sql.DB) *ThanksRepository {
+	return &ThanksRepository{db: db}
+}
+
+// Create stores a thanks. Returns 0 if the giver has already thanked for this message.
+func (r *ThanksRepository) Create(giverUserID int, receiverUserID int, chatID int64, messageID int64, source constants.ThanksSource) (int, error) {
+	query := `
+		INSERT INTO thanks (giver_user_id, receiver_user_id, chat_id, message_id, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (giver_user_id, chat_id, message_id) DO NOTHING
+		RETURNING id`
+
+	var id int
+	err := r.db.QueryRow(query, giverUserID, receiverUserID, chatID, messageID, source).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to insert thanks: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return id, nil
+}
+
+// CountGivenSince counts thanks given by the user since the given time
+func (r *ThanksRepository) CountGivenSince(giverUserID int, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM thanks WHERE giver_user_id = $1 AND created_at >= $2`
+
+	var count int
+	if err := r.db.QueryRow(query, giverUserID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: failed to count thanks given by user %d: %w", utils.GetCurrentTypeName(), giverUserID, err)
+	}
+
+	return count, nil
+}
+
+// GetLastBetween returns the time of the last thanks from giver to receiver, or nil if there was none
+func (r *ThanksRepository) GetLastBetween(giverUserID int, receiverUserID int) (*time.Time, error) {
+	query := `SELECT MAX(created_at) FROM thanks WHERE giver_user_id = $1 AND receiver_user_id = $2`
+
+	var lastAt sql.NullTime
+	if err := r.db.QueryRow(query, giverUserID, receiverUserID).Scan(&lastAt); err != nil {
+		return nil, fmt.Errorf("%s: failed to get last thanks between users %d and %d: %w", utils.GetCurrentTypeName(), giverUserID, receiverUserID, err)
+	}
+
+	if !lastAt.Valid {
+		return nil, nil
+	}
+
+	return &lastAt.Time, nil
+}
+
+// GetStats returns the number of thanks received and given by the user
+func (r *ThanksRepository) GetStats(userID int) (*ThanksStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE receiver_user_id = $1),
+			COUNT(*) FILTER (WHERE giver_user_id = $1)
+		FROM thanks
+		WHERE receiver_user_id = $1 OR giver_user_id = $1`
+
+	var stats ThanksStats
+	if err := r.db.QueryRow(query, userID).Scan(&stats.Received, &stats.Given); err != nil {
+		return nil, fmt.Errorf("%s: failed to get thanks stats for user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	return &stats, nil
+}
+
+// SaveMessageAuthor remembers the author of a group message, so reactions to it can be credited
+func (r *ThanksRepository) SaveMessageAuthor(chatID int64, messageID int64, author *gotgbot.User, postedAt time.Time) error {
+	query := `
+		INSERT INTO message_authors (chat_id, message_id, author_tg_id, author_first_name, author_last_name, author_username, posted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (chat_id, message_id) DO NOTHING`
+
+	_, err := r.db.Exec(query, chatID, messageID, author.Id, author.FirstName, author.LastName, author.Username, postedAt)
+	if err != nil {
+		return fmt.Errorf("%s: failed to save author of message %d: %w", utils.GetCurrentTypeName(), messageID, err)
+	}
+
+	return nil
+}
+
+// GetMessageAuthor returns the remembered author of a group message, or nil if it is unknown
+func (r *ThanksRepository) GetMessageAuthor(chatID int64, messageID int64) (*gotgbot.User, error) {
+	query := `
+		SELECT author_tg_id, author_first_name, author_last_name, author_username
+		FROM message_authors
+		WHERE chat_id = $1 AND message_id = $2`
+
+	var author gotgbot.User
+	err := r.db.QueryRow(query, chatID, messageID).Scan(&author.Id, &author.FirstName, &author.LastName, &author.Username)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get author of message %d: %w", utils.GetCurrentTypeName(), messageID, err)
+	}
+
+	return &author, nil
+}
+
+// DeleteMessageAuthorsBefore forgets the authors of messages posted before the given time
+func (r *ThanksRepository) DeleteMessageAuthorsBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM message_authors WHERE posted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to delete old message authors: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return result.RowsAffected()
+}
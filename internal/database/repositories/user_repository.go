@@ -20,29 +20,55 @@ type User struct {
 	Score        int
 	HasCoffeeBan bool
 	IsClubMember bool
+	Timezone     string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
-// UserRepository handles database operations for users
-type UserRepository struct {
+// UserRepository stores the club users
+type UserRepository interface {
+	GetByID(id int) (*User, error)
+	GetByTelegramID(tgID int64) (*User, error)
+	GetByTelegramUsername(tgUsername string) (*User, error)
+	Create(tgID int64, firstname string, lastname string, username string) (int, error)
+	Update(id int, fields map[string]interface{}) error
+	Delete(id int) error
+	UpdateScore(id int, score int) error
+	SetCoffeeBan(id int, banned bool) error
+	SetClubMemberStatus(id int, isMember bool) error
+	SetBotBlocked(tgID int64, blocked bool) error
+	GetBotBlockedTelegramIDs() ([]int64, error)
+	UpdateTelegramUsername(id int, username string) error
+	UpdateFirstname(id int, firstname string) error
+	UpdateLastname(id int, lastname string) error
+	UpdateTimezone(id int, timezone string) error
+	GetOrCreate(tgUser *gotgbot.User) (*User, error)
+	GetOrFullCreate(user *gotgbot.User) (*User, *Profile, error)
+	SearchByName(firstname, lastname string) (*User, error)
+}
+
+// Ensure PostgresUserRepository implements UserRepository interface
+var _ UserRepository = (*PostgresUserRepository)(nil)
+
+// PostgresUserRepository handles database operations for users
+type PostgresUserRepository struct {
 	db *sql.DB
 }
 
-// NewUserRepository creates a new UserRepository
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+// NewUserRepository creates a new PostgresUserRepository
+func NewUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
 }
 
 // GetDB returns the database connection
-func (r *UserRepository) GetDB() *sql.DB {
+func (r *PostgresUserRepository) GetDB() *sql.DB {
 	return r.db
 }
 
 // GetByID retrieves a user by ID
-func (r *UserRepository) GetByID(id int) (*User, error) {
+func (r *PostgresUserRepository) GetByID(id int) (*User, error) {
 	query := `
-		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, created_at, updated_at
+		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, timezone, created_at, updated_at
 		FROM users
 		WHERE id = $1`
 
@@ -56,6 +82,7 @@ func (r *UserRepository) GetByID(id int) (*User, error) {
 		&user.Score,
 		&user.HasCoffeeBan,
 		&user.IsClubMember,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -72,9 +99,9 @@ func (r *UserRepository) GetByID(id int) (*User, error) {
 }
 
 // GetByTelegramID retrieves a user by Telegram ID
-func (r *UserRepository) GetByTelegramID(tgID int64) (*User, error) {
+func (r *PostgresUserRepository) GetByTelegramID(tgID int64) (*User, error) {
 	query := `
-		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, created_at, updated_at
+		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, timezone, created_at, updated_at
 		FROM users
 		WHERE tg_id = $1`
 
@@ -88,6 +115,7 @@ func (r *UserRepository) GetByTelegramID(tgID int64) (*User, error) {
 		&user.Score,
 		&user.HasCoffeeBan,
 		&user.IsClubMember,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -104,9 +132,9 @@ func (r *UserRepository) GetByTelegramID(tgID int64) (*User, error) {
 }
 
 // GetByTelegramUsername retrieves a user by Telegram username
-func (r *UserRepository) GetByTelegramUsername(tgUsername string) (*User, error) {
+func (r *PostgresUserRepository) GetByTelegramUsername(tgUsername string) (*User, error) {
 	query := `
-		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, created_at, updated_at
+		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, timezone, created_at, updated_at
 		FROM users
 		WHERE tg_username = $1`
 
@@ -120,6 +148,7 @@ func (r *UserRepository) GetByTelegramUsername(tgUsername string) (*User, error)
 		&user.Score,
 		&user.HasCoffeeBan,
 		&user.IsClubMember,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -136,7 +165,7 @@ func (r *UserRepository) GetByTelegramUsername(tgUsername string) (*User, error)
 }
 
 // Create inserts a new user record into the database
-func (r *UserRepository) Create(tgID int64, firstname string, lastname string, username string) (int, error) {
+func (r *PostgresUserRepository) Create(tgID int64, firstname string, lastname string, username string) (int, error) {
 	var id int
 	query := `INSERT INTO users (tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member) 
 			VALUES ($1, $2, $3, $4, 0, false, true) RETURNING id`
@@ -148,7 +177,7 @@ func (r *UserRepository) Create(tgID int64, firstname string, lastname string, u
 }
 
 // Update updates user fields
-func (r *UserRepository) Update(id int, fields map[string]interface{}) error {
+func (r *PostgresUserRepository) Update(id int, fields map[string]interface{}) error {
 	if len(fields) == 0 {
 		return fmt.Errorf("%s: no fields to update for user with ID %d", utils.GetCurrentTypeName(), id)
 	}
@@ -183,7 +212,7 @@ func (r *UserRepository) Update(id int, fields map[string]interface{}) error {
 }
 
 // Delete removes a user record from the database by its ID
-func (r *UserRepository) Delete(id int) error {
+func (r *PostgresUserRepository) Delete(id int) error {
 	query := `DELETE FROM users WHERE id = $1`
 	result, err := r.db.Exec(query, id)
 	if err != nil {
@@ -201,7 +230,7 @@ func (r *UserRepository) Delete(id int) error {
 }
 
 // UpdateScore updates a user's score
-func (r *UserRepository) UpdateScore(id int, score int) error {
+func (r *PostgresUserRepository) UpdateScore(id int, score int) error {
 	query := `UPDATE users SET score = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, score, id)
 	if err != nil {
@@ -219,7 +248,7 @@ func (r *UserRepository) UpdateScore(id int, score int) error {
 }
 
 // SetCoffeeBan sets a user's coffee ban status
-func (r *UserRepository) SetCoffeeBan(id int, banned bool) error {
+func (r *PostgresUserRepository) SetCoffeeBan(id int, banned bool) error {
 	query := `UPDATE users SET has_coffee_ban = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, banned, id)
 	if err != nil {
@@ -237,7 +266,7 @@ func (r *UserRepository) SetCoffeeBan(id int, banned bool) error {
 }
 
 // SetClubMemberStatus sets a user's club member status
-func (r *UserRepository) SetClubMemberStatus(id int, isMember bool) error {
+func (r *PostgresUserRepository) SetClubMemberStatus(id int, isMember bool) error {
 	query := `UPDATE users SET is_club_member = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, isMember, id)
 	if err != nil {
@@ -254,8 +283,46 @@ func (r *UserRepository) SetClubMemberStatus(id int, isMember bool) error {
 	return nil
 }
 
+// SetBotBlocked marks or unmarks the user with the Telegram ID as having blocked the bot. Users not in the database are ignored.
+func (r *PostgresUserRepository) SetBotBlocked(tgID int64, blocked bool) error {
+	query := `UPDATE users SET bot_blocked_at = NULL, updated_at = NOW() WHERE tg_id = $1`
+	if blocked {
+		query = `UPDATE users SET bot_blocked_at = NOW(), updated_at = NOW() WHERE tg_id = $1`
+	}
+
+	if _, err := r.db.Exec(query, tgID); err != nil {
+		return fmt.Errorf("%s: failed to update bot blocked status for user %d: %w", utils.GetCurrentTypeName(), tgID, err)
+	}
+
+	return nil
+}
+
+// GetBotBlockedTelegramIDs retrieves the Telegram IDs of users who blocked the bot
+func (r *PostgresUserRepository) GetBotBlockedTelegramIDs() ([]int64, error) {
+	rows, err := r.db.Query(`SELECT tg_id FROM users WHERE bot_blocked_at IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query users who blocked the bot: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var tgIDs []int64
+	for rows.Next() {
+		var tgID int64
+		if err := rows.Scan(&tgID); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan user who blocked the bot: %w", utils.GetCurrentTypeName(), err)
+		}
+		tgIDs = append(tgIDs, tgID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over users who blocked the bot: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return tgIDs, nil
+}
+
 // UpdateTelegramUsername updates a user's telegram username
-func (r *UserRepository) UpdateTelegramUsername(id int, username string) error {
+func (r *PostgresUserRepository) UpdateTelegramUsername(id int, username string) error {
 	query := `UPDATE users SET tg_username = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, username, id)
 	if err != nil {
@@ -273,7 +340,7 @@ func (r *UserRepository) UpdateTelegramUsername(id int, username string) error {
 }
 
 // UpdateFirstname updates a user's first name
-func (r *UserRepository) UpdateFirstname(id int, firstname string) error {
+func (r *PostgresUserRepository) UpdateFirstname(id int, firstname string) error {
 	query := `UPDATE users SET firstname = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, firstname, id)
 	if err != nil {
@@ -291,7 +358,7 @@ func (r *UserRepository) UpdateFirstname(id int, firstname string) error {
 }
 
 // UpdateLastname updates a user's last name
-func (r *UserRepository) UpdateLastname(id int, lastname string) error {
+func (r *PostgresUserRepository) UpdateLastname(id int, lastname string) error {
 	query := `UPDATE users SET lastname = $1, updated_at = NOW() WHERE id = $2`
 	result, err := r.db.Exec(query, lastname, id)
 	if err != nil {
@@ -308,7 +375,25 @@ func (r *UserRepository) UpdateLastname(id int, lastname string) error {
 	return nil
 }
 
-func (h *UserRepository) GetOrCreate(tgUser *gotgbot.User) (*User, error) {
+// UpdateTimezone updates a user's IANA timezone, empty value resets it to the club timezone
+func (r *PostgresUserRepository) UpdateTimezone(id int, timezone string) error {
+	query := `UPDATE users SET timezone = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.Exec(query, timezone, id)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update timezone for user with ID %d: %w", utils.GetCurrentTypeName(), id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("%s: Could not get rows affected after update: %v", utils.GetCurrentTypeName(), err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("%s: no user found with ID %d to update timezone", utils.GetCurrentTypeName(), id)
+	}
+
+	return nil
+}
+
+func (h *PostgresUserRepository) GetOrCreate(tgUser *gotgbot.User) (*User, error) {
 	// Try to get user by Telegram ID
 	dbUser, err := h.GetByTelegramID(int64(tgUser.Id))
 	if err == nil {
@@ -373,7 +458,7 @@ func (h *UserRepository) GetOrCreate(tgUser *gotgbot.User) (*User, error) {
 }
 
 // GetOrFullCreate gets or creates a user with default profile
-func (h *UserRepository) GetOrFullCreate(user *gotgbot.User) (*User, *Profile, error) {
+func (h *PostgresUserRepository) GetOrFullCreate(user *gotgbot.User) (*User, *Profile, error) {
 	dbUser, err := h.GetOrCreate(user)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s: failed to get user in getOrCreateWithProfile: %w", utils.GetCurrentTypeName(), err)
@@ -390,9 +475,9 @@ func (h *UserRepository) GetOrFullCreate(user *gotgbot.User) (*User, *Profile, e
 }
 
 // SearchByName searches for users with matching first and last name
-func (r *UserRepository) SearchByName(firstname, lastname string) (*User, error) {
+func (r *PostgresUserRepository) SearchByName(firstname, lastname string) (*User, error) {
 	query := `
-		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, created_at, updated_at
+		SELECT id, tg_id, firstname, lastname, tg_username, score, has_coffee_ban, is_club_member, timezone, created_at, updated_at
 		FROM users
 		WHERE LOWER(firstname) = LOWER($1) AND LOWER(lastname) = LOWER($2)
 		LIMIT 1`
@@ -407,6 +492,7 @@ func (r *UserRepository) SearchByName(firstname, lastname string) (*User, error)
 		&user.Score,
 		&user.HasCoffeeBan,
 		&user.IsClubMember,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+)
+
+// UserRole represents a row in the user_roles table
+type UserRole struct {
+	ID            int
+	UserID        int
+	Role          string
+	GrantedByTgID sql.NullInt64
+	CreatedAt     time.Time
+}
+
+// UserRoleWithUser represents a granted role with the user it belongs to
+type UserRoleWithUser struct {
+	Role UserRole
+	User User
+}
+
+// UserRoleRepository stores the roles granted through the bot
+type UserRoleRepository interface {
+	GetRolesByTelegramID(tgID int64) ([]constants.Role, error)
+	Grant(userID int, role constants.Role, grantedByTgID int64) (bool, error)
+	Revoke(userID int, role constants.Role) error
+	GetAll() ([]UserRoleWithUser, error)
+}
+
+// Ensure PostgresUserRoleRepository implements UserRoleRepository interface
+var _ UserRoleRepository = (*PostgresUserRoleRepository)(nil)
+
+// PostgresUserRoleRepository handles database operations for user roles
+type PostgresUserRoleRepository struct {
+	db *sql.DB
+}
+
+// NewUserRoleRepository creates a new PostgresUserRoleRepository
+func NewUserRoleRepository(db *sql.DB) *PostgresUserRoleRepository {
+	return &PostgresUserRoleRepository{db: db}
+}
+
+// GetRolesByTelegramID retrieves the roles granted to the user with the Telegram ID
+func (r *PostgresUserRoleRepository) GetRolesByTelegramID(tgID int64) ([]constants.Role, error) {
+	query := `
+		SELECT ur.role
+		FROM user_roles ur
+		INNER JOIN users u ON ur.user_id = u.id
+		WHERE u.tg_id = $1`
+
+	rows, err := r.db.Query(query, tgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query roles of user %d: %w", utils.GetCurrentTypeName(), tgID, err)
+	}
+	defer rows.Close()
+
+	var roles []constants.Role
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan role: %w", utils.GetCurrentTypeName(), err)
+		}
+		roles = append(roles, constants.Role(role))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over roles: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return roles, nil
+}
+
+// Grant gives the role to the user. Returns false if the user already has the role.
+func (r *PostgresUserRoleRepository) Grant(userID int, role constants.Role, grantedByTgID int64) (bool, error) {
+	query := `
+		INSERT INTO user_roles (user_id, role, granted_by_tg_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, role) DO NOTHING`
+	result, err := r.db.Exec(query, userID, string(role), grantedByTgID)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to grant role %s to user %d: %w", utils.GetCurrentTypeName(), role, userID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: could not get rows affected after grant: %w", utils.GetCurrentTypeName(), err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Revoke takes the role away from the user. Returns sql.ErrNoRows if the user does not have the role.
+func (r *PostgresUserRoleRepository) Revoke(userID int, role constants.Role) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role = $2`
+	result, err := r.db.Exec(query, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("%s: failed to revoke role %s from user %d: %w", utils.GetCurrentTypeName(), role, userID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: could not get rows affected after revoke: %w", utils.GetCurrentTypeName(), err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetAll retrieves all granted roles with their users
+func (r *PostgresUserRoleRepository) GetAll() ([]UserRoleWithUser, error) {
+	query := `
+		SELECT ur.id, ur.user_id, ur.role, ur.granted_by_tg_id, ur.created_at,
+			u.id, u.tg_id, u.firstname, u.lastname, u.tg_username
+		FROM user_roles ur
+		INNER JOIN users u ON ur.user_id = u.id
+		ORDER BY ur.role, u.firstname, u.lastname`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query user roles: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer rows.Close()
+
+	var roles []UserRoleWithUser
+	for rows.Next() {
+		var role UserRoleWithUser
+		if err := rows.Scan(
+			&role.Role.ID,
+			&role.Role.UserID,
+			&role.Role.Role,
+			&role.Role.GrantedByTgID,
+			&role.Role.CreatedAt,
+			&role.User.ID,
+			&role.User.TgID,
+			&role.User.Firstname,
+			&role.User.Lastname,
+			&role.User.TgUsername,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan user role: %w", utils.GetCurrentTypeName(), err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating over user roles: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return roles, nil
+}
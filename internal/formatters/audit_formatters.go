@@ -0,0 +1,65 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"fmt"
+	"strings"
+)
+
+// FormatAuditLog formats audit log entries for the /audit command
+func FormatAuditLog(title string, entries []repositories.AuditLogEntry) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📜 <b>%s</b>\n\n", title))
+
+	if len(entries) == 0 {
+		text.WriteString("<i>Записей не найдено.</i>\n")
+		return text.String()
+	}
+
+	for _, entry := range entries {
+		text.WriteString(fmt.Sprintf("<i>%s</i> <b>%s</b> <code>%s</code>",
+			entry.CreatedAt.Format("02.01.2006 15:04"),
+			escapeHtml(entry.ActorName),
+			escapeHtml(entry.Action),
+		))
+
+		entity := entry.EntityType
+		if entry.EntityID != "" {
+			entity += " #" + entry.EntityID
+		}
+		text.WriteString(" → " + escapeHtml(entity) + "\n")
+
+		if entry.BeforeValue.Valid {
+			text.WriteString("   было: <code>" + escapeHtml(truncateAuditValue(entry.BeforeValue.String)) + "</code>\n")
+		}
+		if entry.AfterValue.Valid {
+			text.WriteString("   стало: <code>" + escapeHtml(truncateAuditValue(entry.AfterValue.String)) + "</code>\n")
+		}
+	}
+
+	return text.String()
+}
+
+// FormatAuditUsage formats the help for the /audit command
+func FormatAuditUsage() string {
+	entities := make([]string, 0, len(constants.AllAuditEntities))
+	for _, entity := range constants.AllAuditEntities {
+		entities = append(entities, "<code>"+string(entity)+"</code>")
+	}
+
+	return fmt.Sprintf("Использование:\n"+
+		"└ <code>/%[1]s</code> — последние действия администраторов\n"+
+		"└ <code>/%[1]s event 12</code> — действия с сущностью (ID можно не указывать)\n"+
+		"└ <code>/%[1]s admin @username</code> — действия администратора (или Telegram ID)\n\n"+
+		"Сущности: %[2]s",
+		constants.AuditCommand, strings.Join(entities, ", "))
+}
+
+func truncateAuditValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= constants.AuditLogValueMaxLength {
+		return value
+	}
+	return string(runes[:constants.AuditLogValueMaxLength]) + "..."
+}
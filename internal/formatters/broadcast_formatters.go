@@ -0,0 +1,58 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetBroadcastAudienceLabel returns a human readable description of the broadcast audience
+func GetBroadcastAudienceLabel(audience constants.BroadcastAudience, param int) string {
+	switch audience {
+	case constants.BroadcastAudienceAllMembers:
+		return "все участники клуба"
+	case constants.BroadcastAudienceCoffeeParticipants:
+		return fmt.Sprintf("участники Random Coffee за последние %d %s", param, utils.PluralizeRu(param, "неделю", "недели", "недель"))
+	case constants.BroadcastAudienceWithoutProfile:
+		return "участники клуба без профиля"
+	case constants.BroadcastAudienceEventRegistrations:
+		return fmt.Sprintf("записавшиеся на мероприятие с ID %d", param)
+	default:
+		return string(audience)
+	}
+}
+
+// FormatBroadcastSummary formats the broadcast details shown to the admin before confirmation
+func FormatBroadcastSummary(audienceLabel string, recipientsCount int, scheduledAt *time.Time, loc *time.Location) string {
+	var text strings.Builder
+	text.WriteString("📣 <b>Рассылка</b>\n\n")
+	text.WriteString(fmt.Sprintf("<b>Кому:</b> %s\n", audienceLabel))
+	text.WriteString(fmt.Sprintf("<b>Получателей:</b> %d\n", recipientsCount))
+	if scheduledAt == nil {
+		text.WriteString("<b>Когда:</b> сейчас\n")
+	} else {
+		text.WriteString(fmt.Sprintf("<b>Когда:</b> %s\n", FormatEventStartedAt(*scheduledAt, loc)))
+	}
+	text.WriteString("\nПодтверди отправку.")
+	return text.String()
+}
+
+// FormatBroadcastReport formats the delivery report of a finished broadcast
+func FormatBroadcastReport(broadcast *repositories.Broadcast, stats map[constants.OutgoingMessageStatus]int) string {
+	sent := stats[constants.OutgoingMessageStatusSent]
+	blocked := stats[constants.OutgoingMessageStatusBlocked]
+	// Messages that could not even be queued are counted as failed
+	failed := max(broadcast.RecipientsCount-sent-blocked, 0)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📊 <b>Отчёт о рассылке #%d</b>\n\n", broadcast.ID))
+	text.WriteString(fmt.Sprintf("<b>Кому:</b> %s\n", GetBroadcastAudienceLabel(broadcast.Audience, broadcast.AudienceParam)))
+	text.WriteString(fmt.Sprintf("<b>Получателей:</b> %d\n\n", broadcast.RecipientsCount))
+	text.WriteString(fmt.Sprintf("✅ Доставлено: %d\n", sent))
+	text.WriteString(fmt.Sprintf("🚫 Заблокировали бота: %d\n", blocked))
+	text.WriteString(fmt.Sprintf("❌ Не удалось отправить: %d", failed))
+	return text.String()
+}
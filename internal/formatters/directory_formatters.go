@@ -0,0 +1,100 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"html"
+	"strconv"
+)
+
+var directoryStatusTitles = map[constants.DirectoryStatus]string{
+	constants.DirectoryStatusMembers: "участники клуба",
+	constants.DirectoryStatusAll:     "все, включая бывших участников",
+	constants.DirectoryStatusFormer:  "бывшие участники клуба",
+}
+
+// FormatDirectoryHelp explains the syntax of the directory queries
+func FormatDirectoryHelp() string {
+	return "Введи запрос: роль, навык, технологию, город или имя. Опечатки в именах не страшны.\n\n" +
+		"<b>Подсказки:</b>\n" +
+		"└ <code>frontend or фронтенд</code> - любое из слов\n" +
+		"└ <code>go -php</code> - исключить слово\n" +
+		"└ <code>#system_design</code> - точный тег из навыков, «Могу помочь», «Ищу» или языков\n" +
+		"└ <code>город:Берлин</code> - город, пробелы заменяй на <code>_</code>\n\n" +
+		fmt.Sprintf("Пример: <code>/%s backend #go город:Москва</code>", constants.DirectoryCommand)
+}
+
+// FormatDirectoryResults formats a page of the member directory with links to the published intros
+func FormatDirectoryResults(
+	query utils.DirectoryQuery,
+	status constants.DirectoryStatus,
+	profiles []repositories.ProfileWithUser,
+	total int,
+	page int,
+	config *config.Config,
+) string {
+	text := "<b>📇 Каталог участников</b>\n\n"
+	if query.Text != "" {
+		text += fmt.Sprintf("🔎 <i>%s</i>\n", formatDirectoryText(query.Text))
+	}
+	if query.Tag != "" {
+		text += fmt.Sprintf("🏷 #%s\n", formatDirectoryText(query.Tag))
+	}
+	if query.City != "" {
+		text += fmt.Sprintf("📍 %s\n", formatDirectoryText(query.City))
+	}
+	text += fmt.Sprintf("👥 %s\n", directoryStatusTitles[status])
+
+	if total == 0 {
+		return text + "\nНикого не нашёл 🤷 Попробуй другие слова, убери фильтры или расширь запрос с ИИ."
+	}
+
+	pages := (total + constants.DirectoryPageSize - 1) / constants.DirectoryPageSize
+	text += fmt.Sprintf("\nНайдено: <b>%d</b> · страница %d из %d\n", total, page+1, pages)
+
+	for i, found := range profiles {
+		user, profile := found.User, found.Profile
+
+		fullName := html.EscapeString(joinNotEmpty(" ", user.Firstname, user.Lastname))
+		text += fmt.Sprintf("\n%d. <b><a href=\"tg://user?id=%s\">%s</a></b>",
+			page*constants.DirectoryPageSize+i+1, strconv.FormatInt(user.TgID, 10), fullName)
+		if user.TgUsername != "" {
+			text += fmt.Sprintf(" (@%s)", user.TgUsername)
+		}
+		text += "\n"
+
+		facts := joinNotEmpty(" · ",
+			formatDirectoryText(joinNotEmpty(", ", profile.JobTitle, profile.Company)),
+			formatDirectoryText(joinNotEmpty(", ", profile.City, profile.Country)),
+		)
+		if facts != "" {
+			text += fmt.Sprintf("└ %s\n", facts)
+		}
+		if skills := formatDirectorySkills(profile.Skills); skills != "" {
+			text += fmt.Sprintf("└ 🛠 %s\n", skills)
+		}
+		if profile.PublishedMessageID.Valid {
+			text += fmt.Sprintf("└ 👉 <a href=\"%s\">Интро</a>\n",
+				utils.GetIntroMessageLink(config, profile.PublishedMessageID.Int64))
+		}
+	}
+
+	return text
+}
+
+// formatDirectoryText escapes the text shortened to the directory limit
+func formatDirectoryText(text string) string {
+	return html.EscapeString(utils.TruncateText(text, constants.DirectoryTextLengthLimit))
+}
+
+// formatDirectorySkills lists the first skills and counts the rest
+func formatDirectorySkills(skills repositories.ProfileTags) string {
+	if len(skills) <= constants.DirectorySkillsLimit {
+		return formatProfileTags(skills)
+	}
+	return fmt.Sprintf("%s и ещё %d",
+		formatProfileTags(skills[:constants.DirectorySkillsLimit]), len(skills)-constants.DirectorySkillsLimit)
+}
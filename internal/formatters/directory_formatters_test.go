@@ -0,0 +1,54 @@
+package formatters
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDirectoryResultsFitsIntoMessage(t *testing.T) {
+	longText := strings.Repeat("ж", constants.ProfileTextLengthLimit)
+	skills := make(repositories.ProfileTags, 0, constants.ProfileTagsLimit)
+	for i := 0; i < constants.ProfileTagsLimit; i++ {
+		skills = append(skills, strings.Repeat("&", constants.ProfileTagLengthLimit))
+	}
+
+	profiles := make([]repositories.ProfileWithUser, 0, constants.DirectoryPageSize)
+	for i := 0; i < constants.DirectoryPageSize; i++ {
+		profiles = append(profiles, repositories.ProfileWithUser{
+			User: &repositories.User{
+				TgID:       int64(1000 + i),
+				Firstname:  strings.Repeat("И", 64),
+				Lastname:   strings.Repeat("П", 64),
+				TgUsername: strings.Repeat("u", 32),
+			},
+			Profile: &repositories.Profile{
+				JobTitle:           longText,
+				Company:            longText,
+				City:               longText,
+				Country:            longText,
+				Skills:             skills,
+				PublishedMessageID: sql.NullInt64{Int64: 42, Valid: true},
+			},
+		})
+	}
+
+	query := utils.DirectoryQuery{
+		Text: strings.Repeat("запрос ", 500),
+		Tag:  strings.Repeat("t", 500),
+		City: strings.Repeat("г", 500),
+	}
+	text := FormatDirectoryResults(query, constants.DirectoryStatusAll, profiles, 1000, 199,
+		&config.Config{SuperGroupChatID: 1234567890, IntroTopicID: 10})
+
+	assert.LessOrEqual(t, utils.HtmlTextLength(text), constants.ProfileMessageLimit)
+	assert.Contains(t, text, "и ещё 10")
+	assert.Contains(t, text, "ж…")
+}
@@ -2,13 +2,20 @@ package formatters
 
 import (
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
 	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
 )
 
+// FormatEventStartedAt formats the event start time in the given location with the zone abbreviation
+func FormatEventStartedAt(startedAt time.Time, loc *time.Location) string {
+	return startedAt.In(loc).Format("02.01.2006 в 15:04 MST")
+}
+
 func GetTypeEmoji(eventType constants.EventType) string {
 	switch eventType {
 	case constants.EventTypeClubCall:
@@ -54,7 +61,8 @@ func GetStatusEmoji(status constants.EventStatus) string {
 	}
 }
 
-func FormatEventListForTopicsView(events []repositories.Event, title string) string {
+// FormatEventListForTopicsView renders events in the member's timezone for selection by ID
+func FormatEventListForTopicsView(events []repositories.Event, title string, loc *time.Location) string {
 	var response strings.Builder
 	response.WriteString(fmt.Sprintf("%s:\n", title))
 
@@ -62,7 +70,7 @@ func FormatEventListForTopicsView(events []repositories.Event, title string) str
 		// Handle optional started_at field
 		startedAtStr := "не указано"
 		if event.StartedAt != nil && !event.StartedAt.IsZero() {
-			startedAtStr = event.StartedAt.Format("02.01.2006 в 15:04")
+			startedAtStr = FormatEventStartedAt(*event.StartedAt, loc)
 		}
 
 		typeEmoji := GetTypeEmoji(constants.EventType(event.Type))
@@ -76,7 +84,8 @@ func FormatEventListForTopicsView(events []repositories.Event, title string) str
 	return response.String()
 }
 
-func FormatEventListForEventsView(events []repositories.Event, title string) string {
+// FormatEventListForEventsView renders events in the member's timezone with the time left until start
+func FormatEventListForEventsView(events []repositories.Event, title string, loc *time.Location) string {
 	var response strings.Builder
 	response.WriteString(fmt.Sprintf("%s:\n", title))
 
@@ -84,29 +93,12 @@ func FormatEventListForEventsView(events []repositories.Event, title string) str
 		// Handle optional started_at field
 		startedAtStr := "не указано"
 		if event.StartedAt != nil && !event.StartedAt.IsZero() {
-			startedAtStr = event.StartedAt.Format("02.01.2006 в 15:04 UTC")
+			startedAtStr = FormatEventStartedAt(*event.StartedAt, loc)
 
 			// Add time remaining if event is in the future
-			utcNow := time.Now().UTC()
-			if event.StartedAt.After(utcNow) {
-				timeUntil := event.StartedAt.Sub(utcNow)
-
-				switch {
-				case timeUntil <= 24*time.Hour:
-					// Less than 24 hours
-					hours := int(timeUntil.Hours())
-					mins := int(timeUntil.Minutes()) % 60
-					if hours > 0 {
-						startedAtStr += fmt.Sprintf(" _(через %dч %dмин)_", hours, mins)
-					} else {
-						startedAtStr += fmt.Sprintf(" _(через %dмин)_", mins)
-					}
-				case timeUntil <= 7*24*time.Hour:
-					// Less than 7 days
-					days := int(timeUntil.Hours() / 24)
-					hours := int(timeUntil.Hours()) % 24
-					startedAtStr += fmt.Sprintf(" _(через %dд %dч)_", days, hours)
-				}
+			now := time.Now()
+			if event.StartedAt.After(now) {
+				startedAtStr += fmt.Sprintf(" _(%s)_", utils.FormatTimeUntil(event.StartedAt.Sub(now)))
 			}
 		}
 
@@ -120,6 +112,7 @@ func FormatEventListForEventsView(events []repositories.Event, title string) str
 	return response.String()
 }
 
+// FormatEventListForAdmin renders events in the timezone they were scheduled in
 func FormatEventListForAdmin(events []repositories.Event, title string, cancelCommand string, actionDescription string) string {
 	var response strings.Builder
 	response.WriteString(fmt.Sprintf("*%s*\n", title))
@@ -128,7 +121,7 @@ func FormatEventListForAdmin(events []repositories.Event, title string, cancelCo
 		// Handle optional started_at field
 		startedAtStr := "не указано"
 		if event.StartedAt != nil && !event.StartedAt.IsZero() {
-			startedAtStr = event.StartedAt.Format("02.01.2006 в 15:04 UTC")
+			startedAtStr = FormatEventStartedAt(*event.StartedAt, utils.ResolveLocation(event.Timezone, time.UTC))
 		}
 
 		statusEmoji := GetStatusEmoji(constants.EventStatus(event.Status))
@@ -215,3 +208,79 @@ func FormatHtmlTopicListForAdmin(topics []repositories.Topic, eventName string,
 
 	return response.String()
 }
+
+// FormatEventRecap builds the message of the event recap. Every text of the model is escaped,
+// the points that do not fit into one message are left out.
+func FormatEventRecap(event *repositories.Event, recap *repositories.EventRecap) string {
+	text := fmt.Sprintf(
+		"📝 <b>Итоги мероприятия</b>\n\n%s <b>%s</b>",
+		GetTypeEmoji(constants.EventType(event.Type)),
+		html.EscapeString(utils.TruncateText(event.Name, constants.EventRecapItemLengthLimit)),
+	)
+
+	const omitted = "\n\n…"
+	limit := constants.ProfileMessageLimit - utils.Utf16CodeUnitCount(omitted)
+	fits := func(lines ...string) bool {
+		return utils.HtmlTextLength(text+strings.Join(lines, "")) <= limit
+	}
+
+	// Recaps generated before the structured content are HTML written by the model, only their text is shown
+	if recap.Content.IsEmpty() {
+		text += "\n"
+		for _, line := range strings.Split(utils.HtmlToPlainText(recap.Recap), "\n") {
+			line = "\n" + html.EscapeString(line)
+			if !fits(line) {
+				return text + omitted
+			}
+			text += line
+		}
+		return text
+	}
+
+	topicItems := make([]string, 0, len(recap.Content.Topics))
+	for _, topic := range recap.Content.Topics {
+		topicItems = append(topicItems, getRecapTopicStatusEmoji(topic.Status)+" "+topic.Topic)
+	}
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"🔑 Ключевые мысли", recap.Content.KeyPoints},
+		{"✅ Решения и договорённости", recap.Content.Decisions},
+		{"🔗 Ссылки и ресурсы", recap.Content.Links},
+		{"💬 Предложенные темы", topicItems},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+
+		title := "\n\n<b>" + section.title + "</b>"
+		for i, item := range section.items {
+			line := "\n• " + html.EscapeString(utils.TruncateText(item, constants.EventRecapItemLengthLimit))
+			if i == 0 && fits(title, line) {
+				text += title + line
+			} else if i > 0 && fits(line) {
+				text += line
+			} else {
+				return text + omitted
+			}
+		}
+	}
+
+	return text
+}
+
+func getRecapTopicStatusEmoji(status constants.EventRecapTopicStatus) string {
+	switch status {
+	case constants.EventRecapTopicCovered:
+		return "✅"
+	case constants.EventRecapTopicPartial:
+		return "⚠️"
+	case constants.EventRecapTopicNotCovered:
+		return "❌"
+	default:
+		return "❔"
+	}
+}
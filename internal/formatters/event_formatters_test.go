@@ -0,0 +1,56 @@
+package formatters
+
+import (
+	"strings"
+	"testing"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatEventRecap(t *testing.T) {
+	event := &repositories.Event{Name: "Go <meetup>", Type: string(constants.EventTypeMeetup)}
+
+	t.Run("escapes the text of the model", func(t *testing.T) {
+		text := FormatEventRecap(event, &repositories.EventRecap{Content: repositories.EventRecapContent{
+			KeyPoints: []string{"<script>alert(1)</script>", "Tom & Jerry"},
+			Links:     []string{`<a href="javascript:alert(1)">link</a>`},
+			Topics:    []repositories.EventRecapTopic{{Topic: "Errors", Status: constants.EventRecapTopicPartial}},
+		}})
+
+		assert.Contains(t, text, "<b>Go &lt;meetup&gt;</b>")
+		assert.Contains(t, text, "• &lt;script&gt;alert(1)&lt;/script&gt;")
+		assert.Contains(t, text, "• Tom &amp; Jerry")
+		assert.NotContains(t, text, "<a ")
+		assert.Contains(t, text, "⚠️ Errors")
+		assert.NotContains(t, text, "Решения", "empty sections are skipped")
+	})
+
+	t.Run("fits into one message", func(t *testing.T) {
+		points := make([]string, 30)
+		for i := range points {
+			points[i] = strings.Repeat("<ж>", constants.EventRecapItemLengthLimit)
+		}
+		text := FormatEventRecap(event, &repositories.EventRecap{Content: repositories.EventRecapContent{
+			KeyPoints: points,
+			Decisions: []string{"decision"},
+		}})
+
+		assert.LessOrEqual(t, utils.HtmlTextLength(text), constants.ProfileMessageLimit)
+		assert.True(t, strings.HasSuffix(text, "…"))
+		assert.NotContains(t, text, "decision")
+	})
+
+	t.Run("shows the text of an old recap", func(t *testing.T) {
+		text := FormatEventRecap(event, &repositories.EventRecap{
+			Recap: "<b>🔑 Ключевые мысли</b>\n- a < b\n" + strings.Repeat("long line\n", 1000),
+		})
+
+		assert.Contains(t, text, "\n🔑 Ключевые мысли\n- a &lt; b")
+		assert.LessOrEqual(t, utils.HtmlTextLength(text), constants.ProfileMessageLimit)
+		assert.True(t, strings.HasSuffix(text, "…"))
+	})
+}
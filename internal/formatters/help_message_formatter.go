@@ -14,15 +14,18 @@ func FormatHelpMessage(isAdmin bool, config *config.Config) string {
 		"└ /help - Показать список моих команд\n" +
 		"└ /cancel - Принудительно отменяет любой диалог\n\n" +
 		"<b>👤 Профиль</b>\n" +
-		"└ /profile - Управление своим профилем, поиск профилей клубчан, публикация и обновление информации о себе в канале «Интро»\n\n" +
+		"└ /profile - Управление своим профилем, поиск профилей клубчан, публикация и обновление информации о себе в канале «Интро»\n" +
+		fmt.Sprintf("└ /%s - Рейтинг участников по карме и история твоих начислений\n\n", constants.TopCommand) +
 		"<b>🔍 Поиск</b>\n" +
 		"└ /tools - Найти инструменты из канала «Инструменты»\n" +
 		"└ /content - Найти видео из канала «Видео-контент»\n" +
+		fmt.Sprintf("└ /%s - Каталог участников: поиск по ролям, навыкам, городам и именам с фильтрами\n", constants.DirectoryCommand) +
 		"└ /intro - Найти информацию об участниках клуба из канала «Интро» (умный поиск по профилям клубчан)\n\n" +
 		"<b>📅 Мероприятия</b>\n" +
 		"└ /events - Показать список предстоящих мероприятий\n" +
 		"└ /topics - Просмотреть темы и вопросы к предстоящим мероприятиям\n" +
-		"└ /topicAdd - Предложить тему или вопрос к предстоящему мероприятию"
+		"└ /topicAdd - Предложить тему или вопрос к предстоящему мероприятию\n" +
+		fmt.Sprintf("└ /%s - Записаться на офлайн-мероприятие или отменить запись", constants.EventRsvpCommand)
 
 	featuresDescription := "\n\n<b>☕️ Random Coffee</b>\n" +
 		"Я создаю еженедельные опросы для участия в клубных встречах. " +
@@ -41,9 +44,20 @@ func FormatHelpMessage(isAdmin bool, config *config.Config) string {
 			fmt.Sprintf("└ /%s - Создать новое мероприятие\n", constants.EventSetupCommand) +
 			fmt.Sprintf("└ /%s - Редактировать мероприятие\n", constants.EventEditCommand) +
 			fmt.Sprintf("└ /%s - Удалить мероприятие\n", constants.EventDeleteCommand) +
+			fmt.Sprintf("└ /%s - Подвести итоги мероприятия по расшифровке записи\n", constants.EventRecapCommand) +
+			fmt.Sprintf("└ /%s - Выгрузить список участников офлайн-мероприятия в CSV\n", constants.EventAttendeesCommand) +
 			fmt.Sprintf("└ /%s - Просмотреть темы и вопросы к предстоящим мероприятиям <b>с возможностью удаления</b>\n", constants.ShowTopicsCommand) +
-			fmt.Sprintf("└ /%s - Ввести код для авторизации TG-клиента (задом наперед)\n", constants.CodeCommand) +
-			fmt.Sprintf("└ /%s - Управление профилями клубчан", constants.AdminProfilesCommand)
+			fmt.Sprintf("└ /%s - Состояние сессии TG-клиента, вход по QR-коду или коду и выход\n", constants.UserClientCommand) +
+			fmt.Sprintf("└ /%s - Управление профилями клубчан\n", constants.AdminProfilesCommand) +
+			fmt.Sprintf("└ /%s - Начислить или списать карму участнику\n", constants.ScoreAdjustCommand) +
+			fmt.Sprintf("└ /%s - Настроить правила модерации топиков\n", constants.ModerationRulesCommand) +
+			fmt.Sprintf("└ /%s, /%s, /%s, /%s, /%s - Предупреждение, мьют, снятие мьюта, бан и разбан участника (ответом на сообщение в чате или с @username в ЛС)\n",
+				constants.WarnCommand, constants.MuteCommand, constants.UnmuteCommand, constants.BanCommand, constants.UnbanCommand) +
+			fmt.Sprintf("└ /%s - Журнал модерации\n", constants.ModLogCommand) +
+			fmt.Sprintf("└ /%s - Журнал действий администраторов (фильтр по сущности или администратору)\n", constants.AuditCommand) +
+			fmt.Sprintf("└ /%s - Роли и права: организаторы мероприятий, модераторы, менеджеры Random Coffee\n", constants.RolesCommand) +
+			fmt.Sprintf("└ /%s - Рассылка участникам с выбором аудитории, отложенной отправкой и отчётом о доставке\n", constants.BroadcastCommand) +
+			fmt.Sprintf("└ /%s - Расходы на ИИ по функциям и участникам (за N дней или с начала месяца)", constants.UsageCommand)
 
 		testCommandsHelpText := "\n\n<b>⚙️ Команды для тестирования</b>\n" +
 			fmt.Sprintf("└ /%s - Ручная генерация саммаризации общения в клубе\n", constants.TrySummarizeCommand) +
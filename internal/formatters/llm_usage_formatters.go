@@ -0,0 +1,64 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"fmt"
+	"strings"
+)
+
+// FormatLLMUsageReport formats the LLM usage report for the /usage command
+func FormatLLMUsageReport(report *repositories.LLMUsageReport) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🤖 <b>Использование ИИ с %s</b>\n\n", report.Since.Format("02.01.2006")))
+
+	text.WriteString(fmt.Sprintf("Всего: %s\n", formatLLMUsageStats(report.Total)))
+
+	budget := "без лимита"
+	if report.MonthlyBudgetUSD > 0 {
+		budget = fmt.Sprintf("$%.2f", report.MonthlyBudgetUSD)
+	}
+	text.WriteString(fmt.Sprintf("Расходы за месяц: <b>$%.2f</b> из %s\n", report.MonthCostUSD, budget))
+
+	quota := "без лимита"
+	if report.UserDailyQuota > 0 {
+		quota = fmt.Sprintf("%d запросов", report.UserDailyQuota)
+	}
+	text.WriteString(fmt.Sprintf("Дневной лимит участника: %s\n", quota))
+
+	if len(report.ByFeature) == 0 {
+		text.WriteString("\n<i>Запросов к ИИ не было.</i>\n")
+		return text.String()
+	}
+
+	text.WriteString("\n<b>По функциям:</b>\n")
+	for _, stats := range report.ByFeature {
+		text.WriteString(fmt.Sprintf("└ <code>%s</code>: %s\n", escapeHtml(stats.Key), formatLLMUsageStats(stats)))
+	}
+
+	if len(report.ByUser) > 0 {
+		text.WriteString(fmt.Sprintf("\n<b>Топ-%d участников:</b>\n", constants.LLMUsageTopUsersLimit))
+		for _, stats := range report.ByUser {
+			name := "<code>" + escapeHtml(stats.Key) + "</code>"
+			if stats.Username != "" {
+				name = "@" + escapeHtml(stats.Username)
+			}
+			text.WriteString(fmt.Sprintf("└ %s: %s\n", name, formatLLMUsageStats(stats)))
+		}
+	}
+
+	return text.String()
+}
+
+// FormatLLMUsageUsage formats the help for the /usage command
+func FormatLLMUsageUsage() string {
+	return fmt.Sprintf("Использование:\n"+
+		"└ <code>/%[1]s</code> — расходы на ИИ с начала месяца\n"+
+		"└ <code>/%[1]s 7</code> — расходы за последние N дней (до %[2]d)",
+		constants.UsageCommand, constants.LLMUsageMaxDays)
+}
+
+func formatLLMUsageStats(stats repositories.LLMUsageStats) string {
+	return fmt.Sprintf("%d запр., %d + %d токенов, $%.4f",
+		stats.Requests, stats.PromptTokens, stats.CompletionTokens, stats.CostUSD)
+}
@@ -0,0 +1,148 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"strings"
+)
+
+// GetModerationPostersLabel returns a human readable label for the allowed posters of a rule
+func GetModerationPostersLabel(rule repositories.ModerationRule) string {
+	switch constants.ModerationPosters(rule.AllowedPosters) {
+	case constants.ModerationPostersAdmins:
+		return "только админы"
+	case constants.ModerationPostersMembers:
+		if rule.MinScore > 0 {
+			return fmt.Sprintf("участники с кармой от %d", rule.MinScore)
+		}
+		return "участники"
+	default:
+		return "все"
+	}
+}
+
+// FormatModerationRules formats the list of moderation rules for admins
+func FormatModerationRules(rules []repositories.ModerationRule) string {
+	var text strings.Builder
+	text.WriteString("🛡 <b>Правила модерации топиков</b>\n\n")
+
+	if len(rules) == 0 {
+		text.WriteString("Правил пока нет.\n")
+		return text.String()
+	}
+
+	yesNo := func(allowed bool) string {
+		if allowed {
+			return "✅"
+		}
+		return "🚫"
+	}
+
+	for _, rule := range rules {
+		text.WriteString(fmt.Sprintf("<b>Топик %d</b>", rule.TopicID))
+		if rule.ID == 0 {
+			text.WriteString(" <i>(из конфигурации)</i>")
+		}
+		text.WriteString("\n")
+		text.WriteString(fmt.Sprintf("└ Кто пишет: %s\n", GetModerationPostersLabel(rule)))
+		if len(rule.AllowedUserIDs) > 0 {
+			text.WriteString(fmt.Sprintf("└ Всегда разрешено: <code>%s</code>\n", utils.FormatInt64List(rule.AllowedUserIDs)))
+		}
+		text.WriteString(fmt.Sprintf("└ Ссылки %s медиа %s пересылки %s\n",
+			yesNo(rule.AllowLinks), yesNo(rule.AllowMedia), yesNo(rule.AllowForwards)))
+
+		action := rule.Action
+		if constants.ModerationAction(rule.Action) == constants.ModerationActionMute {
+			action = fmt.Sprintf("%s (%d мин)", rule.Action, rule.MuteMinutes)
+		}
+		text.WriteString(fmt.Sprintf("└ Действие: <code>%s</code>\n", action))
+		if rule.DmTemplate != "" {
+			text.WriteString("└ Сообщение в ЛС: задано\n")
+		}
+		text.WriteString("\n")
+	}
+
+	return text.String()
+}
+
+// GetMemberSanctionLabel returns a human readable label for a moderation action
+func GetMemberSanctionLabel(action constants.MemberSanction) string {
+	switch action {
+	case constants.MemberSanctionWarn:
+		return "⚠️ Предупреждение"
+	case constants.MemberSanctionMute:
+		return "🔇 Мьют"
+	case constants.MemberSanctionUnmute:
+		return "🔊 Снятие мьюта"
+	case constants.MemberSanctionBan:
+		return "🚫 Бан"
+	case constants.MemberSanctionUnban:
+		return "✅ Разбан"
+	default:
+		return string(action)
+	}
+}
+
+// FormatModerationLog formats the latest moderation actions for the /modlog command
+func FormatModerationLog(title string, actions []repositories.ModerationActionWithUsers, withUser bool) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🛡 <b>%s</b>\n\n", title))
+
+	if len(actions) == 0 {
+		text.WriteString("<i>Действий модерации пока нет.</i>\n")
+		return text.String()
+	}
+
+	for _, action := range actions {
+		text.WriteString(formatModerationAction(action, withUser))
+	}
+
+	return text.String()
+}
+
+// FormatModerationHistoryForAdmin formats the latest moderation actions of a member for the admin profile manager
+func FormatModerationHistoryForAdmin(actions []repositories.ModerationActionWithUsers) string {
+	if len(actions) == 0 {
+		return "\n<i>Модерация:</i> нарушений нет"
+	}
+
+	var text strings.Builder
+	text.WriteString("\n<i>Модерация:</i>\n")
+	for _, action := range actions {
+		text.WriteString(formatModerationAction(action, false))
+	}
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+func formatModerationAction(action repositories.ModerationActionWithUsers, withUser bool) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("<i>%s</i> %s",
+		action.Entry.CreatedAt.Format("02.01.2006 15:04"),
+		GetMemberSanctionLabel(constants.MemberSanction(action.Entry.Action)),
+	))
+
+	if withUser {
+		name := strings.TrimSpace(action.User.Firstname + " " + action.User.Lastname)
+		if action.User.TgUsername != "" {
+			name += " (@" + action.User.TgUsername + ")"
+		}
+		text.WriteString(fmt.Sprintf(" → <a href=\"tg://user?id=%d\">%s</a>", action.User.TgID, escapeHtml(name)))
+	}
+
+	if action.Entry.UntilAt.Valid {
+		text.WriteString(fmt.Sprintf(" до %s", action.Entry.UntilAt.Time.Format("02.01.2006 15:04")))
+	}
+	if action.AdminName.Valid {
+		text.WriteString(fmt.Sprintf(" <i>(%s)</i>", escapeHtml(action.AdminName.String)))
+	} else {
+		text.WriteString(" <i>(автоматически)</i>")
+	}
+	if action.Entry.Reason != "" {
+		text.WriteString(" — " + escapeHtml(action.Entry.Reason))
+	}
+	text.WriteString("\n")
+
+	return text.String()
+}
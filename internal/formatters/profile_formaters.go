@@ -2,13 +2,151 @@ package formatters
 
 import (
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/repositories"
 	"evo-bot-go/internal/utils"
 	"fmt"
+	"html"
 	"strconv"
 	"strings"
 )
 
+// ProfileFieldTitles are the names of the optional profile fields
+var ProfileFieldTitles = map[constants.ProfileField]string{
+	constants.ProfileFieldLocation:    "Город и страна",
+	constants.ProfileFieldJob:         "Роль и компания",
+	constants.ProfileFieldSkills:      "Навыки",
+	constants.ProfileFieldCanHelpWith: "Могу помочь",
+	constants.ProfileFieldLookingFor:  "Ищу",
+	constants.ProfileFieldLanguages:   "Языки",
+	constants.ProfileFieldLinks:       "Ссылки",
+}
+
+var profileLinkTitles = map[constants.ProfileLinkType]string{
+	constants.ProfileLinkTelegram: "Telegram",
+	constants.ProfileLinkGitHub:   "GitHub",
+	constants.ProfileLinkLinkedIn: "LinkedIn",
+	constants.ProfileLinkWebsite:  "Сайт",
+}
+
+// Format the description of the format of an optional profile field for the edit prompt
+func FormatProfileFieldPrompt(field constants.ProfileField) string {
+	tagsLimit := fmt.Sprintf(" Не больше %d штук, до %d символов каждая.",
+		constants.ProfileTagsLimit, constants.ProfileTagLengthLimit)
+	prompt := ""
+	switch field {
+	case constants.ProfileFieldLocation:
+		prompt = "Введи город и страну через запятую, например <code>Берлин, Германия</code>."
+	case constants.ProfileFieldJob:
+		prompt = "Введи роль и компанию через запятую, например <code>Backend-разработчик, Acme</code>. " +
+			"Компанию можно не указывать."
+	case constants.ProfileFieldSkills:
+		prompt = "Перечисли навыки через запятую, например <code>Go, PostgreSQL, system design</code>." + tagsLimit
+	case constants.ProfileFieldCanHelpWith:
+		prompt = "Перечисли через запятую, с чем можешь помочь другим участникам, " +
+			"например <code>code review, подготовка к собеседованиям</code>." + tagsLimit
+	case constants.ProfileFieldLookingFor:
+		prompt = "Перечисли через запятую, что или кого ищешь, например <code>ко-фаундер, ментор по ML</code>." + tagsLimit
+	case constants.ProfileFieldLanguages:
+		prompt = "Перечисли языки, на которых с тобой можно общаться, например <code>русский, English</code>." + tagsLimit
+	case constants.ProfileFieldLinks:
+		prompt = "Пришли ссылки через пробел или с новой строки, по одной каждого типа:" +
+			"\n└ Telegram: <code>@username</code> или <code>t.me/username</code>" +
+			"\n└ GitHub: <code>github.com/username</code>" +
+			"\n└ LinkedIn: <code>linkedin.com/in/username</code>" +
+			"\n└ Сайт: любая ссылка на свой сайт"
+	}
+	return prompt + fmt.Sprintf("\n\nОтправь <code>%s</code>, чтобы очистить поле.", utils.ProfileClearValue)
+}
+
+// Format the current value of an optional profile field, it is empty if the field is not filled
+func FormatProfileFieldValue(profile *repositories.Profile, field constants.ProfileField) string {
+	switch field {
+	case constants.ProfileFieldLocation:
+		return joinNotEmpty(", ", html.EscapeString(profile.City), html.EscapeString(profile.Country))
+	case constants.ProfileFieldJob:
+		return joinNotEmpty(", ", html.EscapeString(profile.JobTitle), html.EscapeString(profile.Company))
+	case constants.ProfileFieldSkills:
+		return formatProfileTags(profile.Skills)
+	case constants.ProfileFieldCanHelpWith:
+		return formatProfileTags(profile.CanHelpWith)
+	case constants.ProfileFieldLookingFor:
+		return formatProfileTags(profile.LookingFor)
+	case constants.ProfileFieldLanguages:
+		return formatProfileTags(profile.Languages)
+	case constants.ProfileFieldLinks:
+		links := make([]string, 0, len(profile.Links))
+		for _, linkType := range constants.AllProfileLinkTypes {
+			if link := profile.Links[string(linkType)]; link != "" {
+				links = append(links, html.EscapeString(link))
+			}
+		}
+		return strings.Join(links, "\n")
+	}
+	return ""
+}
+
+// formatProfileFacts formats the role, the location and the languages, a line each
+func formatProfileFacts(profile *repositories.Profile) string {
+	text := ""
+	if job := FormatProfileFieldValue(profile, constants.ProfileFieldJob); job != "" {
+		text += fmt.Sprintf("💼 %s\n", job)
+	}
+	if location := FormatProfileFieldValue(profile, constants.ProfileFieldLocation); location != "" {
+		text += fmt.Sprintf("📍 %s\n", location)
+	}
+	if languages := FormatProfileFieldValue(profile, constants.ProfileFieldLanguages); languages != "" {
+		text += fmt.Sprintf("🗣 %s\n", languages)
+	}
+	return text
+}
+
+// formatProfileTagsAndLinks formats the skills, "can help with" and "looking for" tags and the personal links
+func formatProfileTagsAndLinks(profile *repositories.Profile) string {
+	text := ""
+	if skills := FormatProfileFieldValue(profile, constants.ProfileFieldSkills); skills != "" {
+		text += fmt.Sprintf("\n🛠 <b>Навыки:</b> %s", skills)
+	}
+	if canHelpWith := FormatProfileFieldValue(profile, constants.ProfileFieldCanHelpWith); canHelpWith != "" {
+		text += fmt.Sprintf("\n🤝 <b>Могу помочь:</b> %s", canHelpWith)
+	}
+	if lookingFor := FormatProfileFieldValue(profile, constants.ProfileFieldLookingFor); lookingFor != "" {
+		text += fmt.Sprintf("\n🔍 <b>Ищу:</b> %s", lookingFor)
+	}
+	if text != "" {
+		text += "\n"
+	}
+
+	links := make([]string, 0, len(profile.Links))
+	for _, linkType := range constants.AllProfileLinkTypes {
+		if link := profile.Links[string(linkType)]; link != "" {
+			links = append(links, fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(link), profileLinkTitles[linkType]))
+		}
+	}
+	if len(links) > 0 {
+		text += fmt.Sprintf("\n🔗 %s\n", strings.Join(links, " · "))
+	}
+	return text
+}
+
+func formatProfileTags(tags repositories.ProfileTags) string {
+	escaped := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		escaped = append(escaped, html.EscapeString(tag))
+	}
+	return strings.Join(escaped, ", ")
+}
+
+func joinNotEmpty(sep string, values ...string) string {
+	notEmpty := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			notEmpty = append(notEmpty, value)
+		}
+	}
+	return strings.Join(notEmpty, sep)
+}
+
 // Format a readable view of a user profile
 func FormatProfileView(user *repositories.User, profile *repositories.Profile, showScore bool) string {
 	if profile == nil {
@@ -29,6 +167,7 @@ func FormatProfileView(user *repositories.User, profile *repositories.Profile, s
 
 	// Build profile text
 	text := fmt.Sprintf("🖐 %s %s\n", fullName, username)
+	text += formatProfileFacts(profile)
 
 	if profile.Bio != "" {
 		profile.Bio = strings.ReplaceAll(profile.Bio, "<", "&lt;")
@@ -36,8 +175,14 @@ func FormatProfileView(user *repositories.User, profile *repositories.Profile, s
 		text += fmt.Sprintf("\n<blockquote>О себе</blockquote>\n%s\n", profile.Bio)
 	}
 
-	if showScore && user.Score > 100 {
-		text += fmt.Sprintf("\n<b>%d</b> <i>(что это? хм...)</i>\n", user.Score)
+	text += formatProfileTagsAndLinks(profile)
+
+	if user.Timezone != "" {
+		text += fmt.Sprintf("\n🕒 Часовой пояс: <code>%s</code>\n", user.Timezone)
+	}
+
+	if showScore {
+		text += fmt.Sprintf("\n⭐ Карма: <b>%d</b> <i>(подробнее /%s)</i>\n", user.Score, constants.TopCommand)
 	}
 
 	return text
@@ -67,6 +212,17 @@ func FormatProfileManagerView(user *repositories.User, profile *repositories.Pro
 		profile.Bio = strings.ReplaceAll(profile.Bio, ">", "&gt;")
 		text += fmt.Sprintf("<blockquote expandable>%s</blockquote>", profile.Bio)
 	}
+	for _, field := range constants.AllProfileFields {
+		if value := FormatProfileFieldValue(profile, field); value != "" {
+			text += fmt.Sprintf("\n<i>%s:</i> %s", ProfileFieldTitles[field], strings.ReplaceAll(value, "\n", ", "))
+		}
+	}
+	if user.Timezone != "" {
+		text += fmt.Sprintf("\n<i>Часовой пояс:</i> <code>%s</code>", user.Timezone)
+	}
+	if profile.PhotoFileID != "" {
+		text += "\n<i>Фото:</i> есть"
+	}
 	text += fmt.Sprintf("\n\n<i>Карма:</i> <b>%d</b>", user.Score)
 
 	coffeeBanStatus := "✅ Разрешено"
@@ -83,6 +239,48 @@ func FormatProfileManagerView(user *repositories.User, profile *repositories.Pro
 }
 
 func FormatPublicProfileForMessage(user *repositories.User, profile *repositories.Profile, showScore bool) string {
+	return formatPublicProfileHeader(user, profile) + formatPublicProfileBody(profile)
+}
+
+// PublicProfileExcessLength returns how many characters the published profile exceeds
+// the Telegram limits by, zero if the profile fits. The profile with a photo is checked
+// as the caption and the follow-up message it is published as.
+func PublicProfileExcessLength(user *repositories.User, profile *repositories.Profile) int {
+	if profile.PhotoFileID != "" {
+		caption, followUp := FormatPublicProfileForPhoto(user, profile)
+		return max(0,
+			utils.HtmlTextLength(caption)-constants.ProfileCaptionLimit,
+			utils.HtmlTextLength(followUp)-constants.ProfileMessageLimit,
+		)
+	}
+	text := FormatPublicProfileForMessage(user, profile, false)
+	return max(0, utils.HtmlTextLength(text)-constants.ProfileMessageLimit)
+}
+
+// FormatPublicProfileForPhoto formats the published profile as the caption of the profile photo.
+// If the profile does not fit into the caption, the caption keeps the name and the facts,
+// and the bio with the tags and the links is returned as the follow-up message.
+// If even the facts do not fit, the caption keeps only the name.
+func FormatPublicProfileForPhoto(user *repositories.User, profile *repositories.Profile) (string, string) {
+	title := formatPublicProfileTitle(user)
+	facts := formatPublicProfileFacts(user, profile)
+	body := formatPublicProfileBody(profile)
+	if utils.HtmlTextLength(title+facts+body) <= constants.ProfileCaptionLimit {
+		return title + facts + body, ""
+	}
+	if utils.HtmlTextLength(title+facts) <= constants.ProfileCaptionLimit {
+		return title + facts, strings.TrimPrefix(body, "\n")
+	}
+	return title, facts + body
+}
+
+// formatPublicProfileHeader formats the name, the facts and the timezone of the published profile
+func formatPublicProfileHeader(user *repositories.User, profile *repositories.Profile) string {
+	return formatPublicProfileTitle(user) + formatPublicProfileFacts(user, profile)
+}
+
+// formatPublicProfileTitle formats the name line of the published profile
+func formatPublicProfileTitle(user *repositories.User) string {
 
 	// Format username
 	username := ""
@@ -96,15 +294,28 @@ func FormatPublicProfileForMessage(user *repositories.User, profile *repositorie
 		username = "(@" + user.TgUsername + ")"
 	}
 
-	// Build profile text
-	text := fmt.Sprintf("🖐 %s %s\n", fullName, username)
+	return fmt.Sprintf("🖐 %s %s\n", fullName, username)
+}
 
-	if profile.Bio != "" {
+// formatPublicProfileFacts formats the facts and the timezone of the published profile
+func formatPublicProfileFacts(user *repositories.User, profile *repositories.Profile) string {
+	text := formatProfileFacts(profile)
+	if user.Timezone != "" {
+		text += fmt.Sprintf("🕒 %s\n", user.Timezone)
+	}
+	return text
+}
 
-		profile.Bio = strings.ReplaceAll(profile.Bio, "<", "&lt;")
-		profile.Bio = strings.ReplaceAll(profile.Bio, ">", "&gt;")
-		text += fmt.Sprintf("\n<blockquote>О себе</blockquote>\n%s\n", profile.Bio)
+// formatPublicProfileBody formats the bio, the tags and the links of the published profile
+func formatPublicProfileBody(profile *repositories.Profile) string {
+	text := ""
+	if profile.Bio != "" {
+		bio := strings.ReplaceAll(profile.Bio, "<", "&lt;")
+		bio = strings.ReplaceAll(bio, ">", "&gt;")
+		text += fmt.Sprintf("\n<blockquote>О себе</blockquote>\n%s\n", bio)
 	}
 
+	text += formatProfileTagsAndLinks(profile)
+
 	return text
 }
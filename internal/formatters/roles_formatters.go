@@ -0,0 +1,96 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"fmt"
+	"strings"
+)
+
+// GetRoleLabel returns a human readable label for the role
+func GetRoleLabel(role constants.Role) string {
+	switch role {
+	case constants.RoleOwner:
+		return "👑 Владелец"
+	case constants.RoleAdmin:
+		return "🛡 Администратор"
+	case constants.RoleEventOrganizer:
+		return "📅 Организатор мероприятий"
+	case constants.RoleModerator:
+		return "👮 Модератор"
+	case constants.RoleCoffeeManager:
+		return "☕️ Менеджер Random Coffee"
+	case constants.RoleMember:
+		return "👤 Участник"
+	default:
+		return string(role)
+	}
+}
+
+// FormatRolesList formats the roles granted through the bot
+func FormatRolesList(roles []repositories.UserRoleWithUser) string {
+	var text strings.Builder
+	text.WriteString("🔑 <b>Роли, выданные через бота</b>\n\n")
+
+	if len(roles) == 0 {
+		text.WriteString("<i>Ролей пока нет.</i>\n")
+	}
+
+	for _, role := range roles {
+		name := escapeHtml(strings.TrimSpace(role.User.Firstname + " " + role.User.Lastname))
+		if role.User.TgUsername != "" {
+			name += " (@" + escapeHtml(role.User.TgUsername) + ")"
+		}
+		text.WriteString(fmt.Sprintf("%s — %s <code>%d</code>\n",
+			GetRoleLabel(constants.Role(role.Role.Role)), name, role.User.TgID))
+	}
+
+	text.WriteString("\n<i>Владелец задаётся в TG_EVO_BOT_ADMIN_USER_ID, администраторы чата клуба — администраторы бота, " +
+		"участники чата — участники клуба.</i>")
+	return text.String()
+}
+
+// FormatUserRoles formats the effective roles of a user with their capabilities
+func FormatUserRoles(user *repositories.User, roles []constants.Role) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🔑 <b>Роли %s</b>\n\n", escapeHtml(strings.TrimSpace(user.Firstname+" "+user.Lastname))))
+
+	if len(roles) == 0 {
+		text.WriteString("<i>Ролей нет.</i>\n")
+		return text.String()
+	}
+
+	for _, role := range roles {
+		text.WriteString(GetRoleLabel(role))
+		capabilities := constants.RoleCapabilities[role]
+		if len(capabilities) > 0 && len(capabilities) < len(constants.AllCapabilities) {
+			names := make([]string, 0, len(capabilities))
+			for _, capability := range capabilities {
+				names = append(names, "<code>"+string(capability)+"</code>")
+			}
+			text.WriteString(": " + strings.Join(names, ", "))
+		}
+		text.WriteString("\n")
+	}
+
+	return text.String()
+}
+
+// FormatRolesUsage formats the help for the /roles command
+func FormatRolesUsage() string {
+	roles := make([]string, 0, len(constants.AllRoles))
+	for _, role := range constants.AllRoles {
+		if role == constants.RoleMember {
+			continue
+		}
+		roles = append(roles, "<code>"+string(role)+"</code>")
+	}
+
+	return fmt.Sprintf("Использование:\n"+
+		"└ <code>/%[1]s</code> — роли, выданные через бота\n"+
+		"└ <code>/%[1]s @username</code> — роли участника (или Telegram ID)\n"+
+		"└ <code>/%[1]s grant @username event_organizer</code> — выдать роль\n"+
+		"└ <code>/%[1]s revoke @username event_organizer</code> — забрать роль\n\n"+
+		"Роли: %[2]s. Роли <code>owner</code> и <code>admin</code> выдаёт только владелец.",
+		constants.RolesCommand, strings.Join(roles, ", "))
+}
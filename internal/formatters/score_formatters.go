@@ -0,0 +1,105 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"fmt"
+	"strings"
+)
+
+// GetScoreReasonLabel returns a human readable label for a score ledger reason
+func GetScoreReasonLabel(reason constants.ScoreReason) string {
+	switch reason {
+	case constants.ScoreReasonTopicProposed:
+		return "Тема или вопрос к мероприятию"
+	case constants.ScoreReasonEventRegistered:
+		return "Запись на мероприятие"
+	case constants.ScoreReasonRandomCoffeeCompleted:
+		return "Встреча Random Coffee"
+	case constants.ScoreReasonThanksReceived:
+		return "Благодарность от участника"
+	case constants.ScoreReasonProfilePublished:
+		return "Публикация профиля"
+	case constants.ScoreReasonAdminAdjustment:
+		return "Корректировка администратором"
+	case constants.ScoreReasonDecay:
+		return "Ежемесячное снижение"
+	default:
+		return "Прочее"
+	}
+}
+
+// FormatScoreLeaderboard formats the list of members with the highest karma
+func FormatScoreLeaderboard(users []repositories.User) string {
+	var text strings.Builder
+	text.WriteString("🏆 <b>Топ участников клуба по карме</b>\n\n")
+
+	if len(users) == 0 {
+		text.WriteString("Пока никто не набрал карму. Стань первым!\n")
+		return text.String()
+	}
+
+	medals := []string{"🥇", "🥈", "🥉"}
+	for i, user := range users {
+		place := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) {
+			place = medals[i]
+		}
+
+		name := user.Firstname
+		if user.Lastname != "" {
+			name += " " + user.Lastname
+		}
+
+		text.WriteString(fmt.Sprintf("%s <a href=\"tg://user?id=%d\">%s</a> — <b>%d</b>\n", place, user.TgID, escapeHtml(name), user.Score))
+	}
+
+	return text.String()
+}
+
+// FormatScoreHistory formats the latest score ledger entries of a member
+func FormatScoreHistory(user *repositories.User, rank int, entries []repositories.ScoreLedgerEntry) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("⭐ Карма: <b>%d</b>", user.Score))
+	if user.Score > 0 && rank > 0 {
+		text.WriteString(fmt.Sprintf(" (место в рейтинге: %d)", rank))
+	}
+	text.WriteString("\n")
+
+	if len(entries) == 0 {
+		text.WriteString("\n<i>История начислений пока пуста.</i>\n")
+		return text.String()
+	}
+
+	text.WriteString("\n<blockquote>Последние начисления</blockquote>\n")
+	for _, entry := range entries {
+		text.WriteString(fmt.Sprintf("<code>%+d</code> %s <i>(%s)</i>",
+			entry.Points,
+			GetScoreReasonLabel(constants.ScoreReason(entry.Reason)),
+			entry.CreatedAt.Format("02.01.2006"),
+		))
+		if entry.Comment != "" {
+			text.WriteString(" — " + escapeHtml(entry.Comment))
+		}
+		text.WriteString("\n")
+	}
+
+	return text.String()
+}
+
+// FormatThanksStats formats thanks counters for the member's own profile
+func FormatThanksStats(stats *repositories.ThanksStats) string {
+	return fmt.Sprintf("🙏 Благодарности: получено <b>%d</b>, отправлено <b>%d</b>\n", stats.Received, stats.Given)
+}
+
+// FormatThanksStatsForAdmin formats thanks counters for the admin profile manager
+func FormatThanksStatsForAdmin(stats *repositories.ThanksStats) string {
+	return fmt.Sprintf("\n<i>Благодарности:</i> получено <b>%d</b>, отправлено <b>%d</b>", stats.Received, stats.Given)
+}
+
+func escapeHtml(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+	return text
+}
@@ -0,0 +1,78 @@
+package formatters
+
+import (
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/constants"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatUserClientStatus formats the session health of the user client for the /userclient command
+func FormatUserClientStatus(status *clients.TgUserClientStatus, loc *time.Location) string {
+	var text strings.Builder
+	text.WriteString("👤 <b>TG-клиент</b>\n\n")
+
+	if status.ConfigErr != nil {
+		text.WriteString("❌ Не настроен: " + escapeHtml(status.ConfigErr.Error()) + "\n")
+		return text.String()
+	}
+
+	text.WriteString("Подключение: " + formatUserClientFlag(status.Connected, "есть", "нет") + "\n")
+	text.WriteString("Сессия сохранена: " + formatUserClientFlag(status.SessionStored, "да", "нет") + "\n")
+
+	if status.Connected {
+		text.WriteString("Авторизация: " + formatUserClientFlag(status.Authorized, "выполнена", "не выполнена") + "\n")
+	}
+	if status.User != nil {
+		account := strings.TrimSpace(status.User.FirstName + " " + status.User.LastName)
+		if status.User.Username != "" {
+			account += " @" + status.User.Username
+		}
+		text.WriteString(fmt.Sprintf("Аккаунт: %s (<code>%d</code>)\n", escapeHtml(account), status.User.ID))
+	}
+
+	switch status.LoginStep {
+	case clients.LoginStepCode:
+		text.WriteString("Вход: ожидается код\n")
+	case clients.LoginStepQR:
+		text.WriteString("Вход: ожидается сканирование QR-кода\n")
+	case clients.LoginStepPassword:
+		text.WriteString("Вход: ожидается пароль 2FA\n")
+	}
+
+	if !status.LastCallAt.IsZero() {
+		text.WriteString("Последний успешный запрос: " + status.LastCallAt.In(loc).Format("02.01.2006 15:04:05") + "\n")
+	}
+	if status.LastErr != nil {
+		text.WriteString(fmt.Sprintf("Последняя ошибка (%s): <code>%s</code>\n",
+			status.LastErrAt.In(loc).Format("02.01.2006 15:04:05"),
+			escapeHtml(status.LastErr.Error())))
+	}
+
+	if status.Connected && !status.Authorized && status.LoginStep == clients.LoginStepNone {
+		text.WriteString(fmt.Sprintf("\nЧтобы войти, используй <code>/%s %s</code>", constants.UserClientCommand, constants.UserClientLoginSubcommand))
+	}
+
+	return text.String()
+}
+
+// FormatUserClientUsage formats the usage of the /userclient command
+func FormatUserClientUsage() string {
+	return fmt.Sprintf("<b>Использование:</b>\n"+
+		"└ <code>/%[1]s</code> или <code>/%[1]s %[2]s</code> — состояние сессии TG-клиента\n"+
+		"└ <code>/%[1]s %[3]s</code> — войти по QR-коду или коду из Telegram\n"+
+		"└ <code>/%[1]s %[4]s</code> — завершить сессию, чтобы войти заново",
+		constants.UserClientCommand,
+		constants.UserClientStatusSubcommand,
+		constants.UserClientLoginSubcommand,
+		constants.UserClientLogoutSubcommand,
+	)
+}
+
+func formatUserClientFlag(value bool, yes string, no string) string {
+	if value {
+		return "✅ " + yes
+	}
+	return "❌ " + no
+}
@@ -0,0 +1,111 @@
+package adminhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type auditHandler struct {
+	config               *config.Config
+	auditLogService      *services.AuditLogService
+	userRepository       repositories.UserRepository
+	messageSenderService *services.MessageSenderService
+	permissionsService   *services.PermissionsService
+}
+
+func NewAuditHandler(
+	config *config.Config,
+	auditLogService *services.AuditLogService,
+	userRepository repositories.UserRepository,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &auditHandler{
+		config:               config,
+		auditLogService:      auditLogService,
+		userRepository:       userRepository,
+		messageSenderService: messageSenderService,
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewCommand(constants.AuditCommand, h.handleCommand)
+}
+
+// handleCommand shows the audit log filtered by entity or admin
+func (h *auditHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityViewAudit, constants.AuditCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.AuditCommand,
+		)
+		return nil
+	}
+
+	args := strings.Fields(msg.Text)[1:]
+	filter := repositories.AuditLogFilter{}
+	title := "Журнал действий администраторов"
+
+	switch {
+	case len(args) == 0:
+		// No filter, show the latest entries
+
+	case args[0] == "admin" && len(args) == 2:
+		actorTgID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			user, err := h.userRepository.GetByTelegramUsername(strings.TrimPrefix(args[1], "@"))
+			if err == sql.ErrNoRows {
+				h.messageSenderService.Reply(msg, "Администратор не найден.", nil)
+				return nil
+			}
+			if err != nil {
+				h.messageSenderService.Reply(msg, "Произошла ошибка при поиске администратора.", nil)
+				return fmt.Errorf("%s: failed to get user by username: %w", utils.GetCurrentTypeName(), err)
+			}
+			actorTgID = user.TgID
+		}
+		filter.ActorTgID = actorTgID
+		title += ": " + html.EscapeString(args[1])
+
+	case slices.Contains(constants.AllAuditEntities, constants.AuditEntity(args[0])) && len(args) <= 2:
+		filter.EntityType = args[0]
+		title += ": " + args[0]
+		if len(args) == 2 {
+			filter.EntityID = args[1]
+			title += " #" + html.EscapeString(args[1])
+		}
+
+	default:
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatAuditUsage(), nil)
+		return nil
+	}
+
+	entries, err := h.auditLogService.Find(filter)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Ошибка при получении журнала действий.", nil)
+		return fmt.Errorf("%s: failed to get audit log: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.messageSenderService.ReplyHtml(msg, formatters.FormatAuditLog(title, entries), nil)
+	return nil
+}
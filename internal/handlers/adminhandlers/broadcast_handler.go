@@ -0,0 +1,481 @@
+package adminhandlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	broadcastStateAskMessage  = "broadcast_state_ask_message"
+	broadcastStateAskAudience = "broadcast_state_ask_audience"
+	broadcastStateAskWeeks    = "broadcast_state_ask_weeks"
+	broadcastStateAskEvent    = "broadcast_state_ask_event"
+	broadcastStateAskSchedule = "broadcast_state_ask_schedule"
+	broadcastStateConfirm     = "broadcast_state_confirm"
+
+	// Context data keys
+	broadcastCtxDataKeyFromChatID        = "broadcast_ctx_data_from_chat_id"
+	broadcastCtxDataKeyFromMessageID     = "broadcast_ctx_data_from_message_id"
+	broadcastCtxDataKeyAudience          = "broadcast_ctx_data_audience"
+	broadcastCtxDataKeyAudienceParam     = "broadcast_ctx_data_audience_param"
+	broadcastCtxDataKeyScheduledAt       = "broadcast_ctx_data_scheduled_at"
+	broadcastCtxDataKeyPreviousMessageID = "broadcast_ctx_data_previous_message_id"
+	broadcastCtxDataKeyPreviousChatID    = "broadcast_ctx_data_previous_chat_id"
+)
+
+type broadcastHandler struct {
+	config               *config.Config
+	eventRepository      repositories.EventRepository
+	broadcastService     *services.BroadcastService
+	auditLogService      *services.AuditLogService
+	messageSenderService *services.MessageSenderService
+	userStore            *utils.UserDataStore
+	permissionsService   *services.PermissionsService
+}
+
+func NewBroadcastHandler(
+	config *config.Config,
+	eventRepository repositories.EventRepository,
+	broadcastService *services.BroadcastService,
+	auditLogService *services.AuditLogService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &broadcastHandler{
+		config:               config,
+		eventRepository:      eventRepository,
+		broadcastService:     broadcastService,
+		auditLogService:      auditLogService,
+		messageSenderService: messageSenderService,
+		userStore:            utils.NewUserDataStore(),
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.BroadcastCommand, h.startBroadcast),
+		},
+		map[string][]ext.Handler{
+			broadcastStateAskMessage: {
+				handlers.NewMessage(isBroadcastContent, h.handleMessage),
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastCancelCallback), h.handleCallbackCancel),
+			},
+			broadcastStateAskAudience: {
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastCancelCallback), h.handleCallbackCancel),
+				handlers.NewCallback(callbackquery.Prefix(constants.BroadcastPrefix+"audience_"), h.handleAudience),
+			},
+			broadcastStateAskWeeks: {
+				handlers.NewMessage(message.Text, h.handleWeeks),
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastCancelCallback), h.handleCallbackCancel),
+			},
+			broadcastStateAskEvent: {
+				handlers.NewMessage(message.Text, h.handleEvent),
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastCancelCallback), h.handleCallbackCancel),
+			},
+			broadcastStateAskSchedule: {
+				handlers.NewMessage(message.Text, h.handleScheduledAt),
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastSendNowCallback), h.handleSendNow),
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastCancelCallback), h.handleCallbackCancel),
+			},
+			broadcastStateConfirm: {
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastConfirmCallback), h.handleConfirm),
+				handlers.NewCallback(callbackquery.Equal(constants.BroadcastCancelCallback), h.handleCallbackCancel),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// isBroadcastContent accepts the messages that can be previewed with SendCopy: text, photos, videos and animations
+func isBroadcastContent(msg *gotgbot.Message) bool {
+	return msg.Text != "" || msg.Photo != nil || msg.Video != nil || msg.Animation != nil
+}
+
+// 1. startBroadcast is the entry point handler for the broadcast
+func (h *broadcastHandler) startBroadcast(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityBroadcast, constants.BroadcastCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.BroadcastCommand,
+		)
+		return handlers.EndConversation()
+	}
+
+	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+		msg,
+		fmt.Sprintf("Отправь сообщение для рассылки: текст, фото, видео или GIF с подписью. Форматирование сохранится. Для отмены используй /%s.", constants.CancelCommand),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(constants.BroadcastCancelCallback),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(broadcastStateAskMessage)
+}
+
+// 2. handleMessage shows the preview of the broadcast message and asks for the audience
+func (h *broadcastHandler) handleMessage(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	text, entities := msg.Text, msg.Entities
+	if text == "" {
+		text, entities = msg.Caption, msg.CaptionEntities
+	}
+
+	if _, err := h.messageSenderService.SendCopy(msg.Chat.Id, nil, text, entities, msg); err != nil {
+		h.messageSenderService.Reply(msg, "Не удалось показать предпросмотр сообщения. Попробуй отправить другое сообщение.", nil)
+		log.Printf("%s: Error during broadcast preview: %v", utils.GetCurrentTypeName(), err)
+		return nil // Stay in the same state
+	}
+
+	h.userStore.Set(ctx.EffectiveUser.Id, broadcastCtxDataKeyFromChatID, msg.Chat.Id)
+	h.userStore.Set(ctx.EffectiveUser.Id, broadcastCtxDataKeyFromMessageID, msg.MessageId)
+
+	sentMsg, _ := h.messageSenderService.SendWithReturnMessage(
+		msg.Chat.Id,
+		"👆 Так участники увидят рассылку. Кому её отправить?",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.BroadcastAudienceButtons(),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(broadcastStateAskAudience)
+}
+
+// 3. handleAudience processes the audience selection, asking for its parameter when needed
+func (h *broadcastHandler) handleAudience(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+	msg := ctx.EffectiveMessage
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	switch cb.Data {
+	case constants.BroadcastAudienceAllMembersCallback:
+		return h.askSchedule(ctx, constants.BroadcastAudienceAllMembers, 0)
+	case constants.BroadcastAudienceNoProfileCallback:
+		return h.askSchedule(ctx, constants.BroadcastAudienceWithoutProfile, 0)
+	case constants.BroadcastAudienceCoffeeCallback:
+		sentMsg, _ := h.messageSenderService.SendWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("За сколько последних недель взять участников Random Coffee? Введи число от 1 до %d.", constants.BroadcastCoffeeMaxWeeks),
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.CancelButton(constants.BroadcastCancelCallback),
+			},
+		)
+		h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+		return handlers.NextConversationState(broadcastStateAskWeeks)
+	case constants.BroadcastAudienceEventCallback:
+		events, err := h.eventRepository.GetLastEvents(constants.BroadcastEventsListLimit)
+		if err != nil {
+			h.messageSenderService.Send(msg.Chat.Id, "Произошла ошибка при получении списка мероприятий.", nil)
+			log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+			h.userStore.Clear(ctx.EffectiveUser.Id)
+			return handlers.EndConversation()
+		}
+		if len(events) == 0 {
+			h.messageSenderService.Send(msg.Chat.Id, "Нет мероприятий для рассылки.", nil)
+			h.userStore.Clear(ctx.EffectiveUser.Id)
+			return handlers.EndConversation()
+		}
+
+		title := fmt.Sprintf("Последние %d мероприятия:", len(events))
+		actionDescription := "записавшимся на которое нужно отправить рассылку"
+		sentMsg, _ := h.messageSenderService.SendMarkdownWithReturnMessage(
+			msg.Chat.Id,
+			formatters.FormatEventListForAdmin(events, title, constants.CancelCommand, actionDescription),
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.CancelButton(constants.BroadcastCancelCallback),
+			},
+		)
+		h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+		return handlers.NextConversationState(broadcastStateAskEvent)
+	}
+
+	return nil // Stay in the same state
+}
+
+// 4a. handleWeeks processes the number of weeks for the Random Coffee participants audience
+func (h *broadcastHandler) handleWeeks(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	weeks, err := strconv.Atoi(strings.TrimSpace(msg.Text))
+	if err != nil || weeks < 1 || weeks > constants.BroadcastCoffeeMaxWeeks {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Введи число от 1 до %d или используй кнопку для отмены.", constants.BroadcastCoffeeMaxWeeks), nil)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	return h.askSchedule(ctx, constants.BroadcastAudienceCoffeeParticipants, weeks)
+}
+
+// 4b. handleEvent processes the event whose registrations receive the broadcast
+func (h *broadcastHandler) handleEvent(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	eventID, err := strconv.Atoi(strings.TrimSpace(strings.Replace(msg.Text, "/", "", 1)))
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Неверный ID. Пожалуйста, введи числовой ID или /%s для отмены.", constants.CancelCommand), nil)
+		return nil // Stay in the same state
+	}
+
+	if _, err := h.eventRepository.GetEventByID(eventID); err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Мероприятие с ID %d не найдено. Попробуй ещё раз или используй /%s для отмены.", eventID, constants.CancelCommand), nil)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	return h.askSchedule(ctx, constants.BroadcastAudienceEventRegistrations, eventID)
+}
+
+// askSchedule saves the audience, shows the number of recipients and asks when to send the broadcast
+func (h *broadcastHandler) askSchedule(ctx *ext.Context, audience constants.BroadcastAudience, param int) error {
+	chatID := ctx.EffectiveChat.Id
+
+	recipients, err := h.broadcastService.GetRecipients(audience, param)
+	if err != nil {
+		h.messageSenderService.Send(chatID, "Произошла ошибка при подсчёте получателей.", nil)
+		log.Printf("%s: Error during audience retrieval: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+	if len(recipients) == 0 {
+		h.messageSenderService.Send(chatID, "В этой аудитории нет ни одного получателя. Рассылка отменена.", nil)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	h.userStore.Set(ctx.EffectiveUser.Id, broadcastCtxDataKeyAudience, audience)
+	h.userStore.Set(ctx.EffectiveUser.Id, broadcastCtxDataKeyAudienceParam, param)
+
+	sentMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+		chatID,
+		fmt.Sprintf(
+			"<b>Кому:</b> %s\n<b>Получателей:</b> %d\n\nОтправить рассылку сейчас? Или введи дату и время отправки в формате DD.MM.YYYY HH:MM (%s).",
+			formatters.GetBroadcastAudienceLabel(audience, param),
+			len(recipients),
+			h.config.ClubTimezone,
+		),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.BroadcastSendNowButtons(),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(broadcastStateAskSchedule)
+}
+
+// 5a. handleSendNow processes the choice to send the broadcast right away
+func (h *broadcastHandler) handleSendNow(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.userStore.Set(ctx.EffectiveUser.Id, broadcastCtxDataKeyScheduledAt, time.Time{})
+	return h.askConfirmation(ctx, nil)
+}
+
+// 5b. handleScheduledAt processes the date and time of a scheduled broadcast
+func (h *broadcastHandler) handleScheduledAt(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Parse the date in the club timezone
+	scheduledAt, err := time.ParseInLocation("02.01.2006 15:04", strings.TrimSpace(msg.Text), h.config.ClubTimezone)
+	if err != nil {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf(
+				"Неверный формат даты. Пожалуйста, введи дату и время в формате DD.MM.YYYY HH:MM (%s) или используй кнопки.",
+				h.config.ClubTimezone,
+			),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+	if scheduledAt.Before(time.Now()) {
+		h.messageSenderService.Reply(msg, "Это время уже прошло. Введи время в будущем или отправь рассылку сейчас.", nil)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.userStore.Set(ctx.EffectiveUser.Id, broadcastCtxDataKeyScheduledAt, scheduledAt)
+	return h.askConfirmation(ctx, &scheduledAt)
+}
+
+// askConfirmation shows the broadcast summary with the confirmation buttons
+func (h *broadcastHandler) askConfirmation(ctx *ext.Context, scheduledAt *time.Time) error {
+	chatID := ctx.EffectiveChat.Id
+
+	audience, param, ok := h.getAudience(ctx.EffectiveUser.Id)
+	if !ok {
+		h.messageSenderService.Send(chatID, "Произошла ошибка: аудитория не найдена в контексте. Начни заново.", nil)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	recipients, err := h.broadcastService.GetRecipients(audience, param)
+	if err != nil {
+		h.messageSenderService.Send(chatID, "Произошла ошибка при подсчёте получателей.", nil)
+		log.Printf("%s: Error during audience retrieval: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	sentMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+		chatID,
+		formatters.FormatBroadcastSummary(formatters.GetBroadcastAudienceLabel(audience, param), len(recipients), scheduledAt, h.config.ClubTimezone),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ConfirmAndCancelButton(constants.BroadcastConfirmCallback, constants.BroadcastCancelCallback),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(broadcastStateConfirm)
+}
+
+// 6. handleConfirm queues the broadcast
+func (h *broadcastHandler) handleConfirm(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+	chatID := ctx.EffectiveChat.Id
+	userID := ctx.EffectiveUser.Id
+
+	h.MessageRemoveInlineKeyboard(b, &userID)
+
+	audience, param, audienceOk := h.getAudience(userID)
+	fromChatIDVal, fromChatOk := h.userStore.Get(userID, broadcastCtxDataKeyFromChatID)
+	fromMessageIDVal, fromMessageOk := h.userStore.Get(userID, broadcastCtxDataKeyFromMessageID)
+	scheduledAtVal, scheduledAtOk := h.userStore.Get(userID, broadcastCtxDataKeyScheduledAt)
+	if !audienceOk || !fromChatOk || !fromMessageOk || !scheduledAtOk {
+		h.messageSenderService.Send(chatID, "Произошла ошибка: данные рассылки не найдены в контексте. Начни заново.", nil)
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	scheduledAt := scheduledAtVal.(time.Time)
+	if scheduledAt.IsZero() {
+		scheduledAt = time.Now()
+	}
+
+	broadcast, err := h.broadcastService.Schedule(userID, audience, param, fromChatIDVal.(int64), fromMessageIDVal.(int64), scheduledAt)
+	if err != nil {
+		h.messageSenderService.Send(chatID, "Произошла ошибка при постановке рассылки в очередь.", nil)
+		log.Printf("%s: Error during broadcast scheduling: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionCreate, constants.AuditEntityBroadcast, broadcast.ID,
+		nil,
+		map[string]interface{}{
+			"audience":         broadcast.Audience,
+			"audience_param":   broadcast.AudienceParam,
+			"recipients_count": broadcast.RecipientsCount,
+			"scheduled_at":     broadcast.ScheduledAt,
+		},
+	)
+
+	h.messageSenderService.Send(
+		chatID,
+		fmt.Sprintf("✅ Рассылка #%d поставлена в очередь для %d %s. Когда все сообщения будут обработаны, я пришлю отчёт о доставке.",
+			broadcast.ID,
+			broadcast.RecipientsCount,
+			utils.PluralizeRu(broadcast.RecipientsCount, "получателя", "получателей", "получателей"),
+		),
+		nil,
+	)
+
+	// Clean up user data
+	h.userStore.Clear(userID)
+
+	return handlers.EndConversation()
+}
+
+func (h *broadcastHandler) getAudience(userID int64) (constants.BroadcastAudience, int, bool) {
+	audienceVal, ok := h.userStore.Get(userID, broadcastCtxDataKeyAudience)
+	if !ok {
+		return "", 0, false
+	}
+	paramVal, ok := h.userStore.Get(userID, broadcastCtxDataKeyAudienceParam)
+	if !ok {
+		return "", 0, false
+	}
+	return audienceVal.(constants.BroadcastAudience), paramVal.(int), true
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *broadcastHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// 7. handleCancel handles the /cancel command
+func (h *broadcastHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Send(ctx.EffectiveChat.Id, "Рассылка отменена.", nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+func (h *broadcastHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			broadcastCtxDataKeyPreviousMessageID,
+			broadcastCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *broadcastHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	if sentMsg == nil {
+		return
+	}
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		broadcastCtxDataKeyPreviousMessageID, broadcastCtxDataKeyPreviousChatID)
+}
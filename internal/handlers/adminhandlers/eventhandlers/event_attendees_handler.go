@@ -0,0 +1,234 @@
+package eventhandlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	eventAttendeesStateSelectEvent = "event_attendees_state_select_event"
+
+	// Context data keys
+	eventAttendeesCtxDataKeyPreviousMessageID = "event_attendees_ctx_data_previous_message_id"
+	eventAttendeesCtxDataKeyPreviousChatID    = "event_attendees_ctx_data_previous_chat_id"
+
+	// Callback data
+	eventAttendeesCallbackConfirmCancel = "event_attendees_callback_confirm_cancel"
+)
+
+type eventAttendeesHandler struct {
+	config                   *config.Config
+	eventRepository          repositories.EventRepository
+	eventRegistrationService *services.EventRegistrationService
+	messageSenderService     *services.MessageSenderService
+	userStore                *utils.UserDataStore
+	permissionsService       *services.PermissionsService
+}
+
+func NewEventAttendeesHandler(
+	config *config.Config,
+	eventRepository repositories.EventRepository,
+	eventRegistrationService *services.EventRegistrationService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &eventAttendeesHandler{
+		config:                   config,
+		eventRepository:          eventRepository,
+		eventRegistrationService: eventRegistrationService,
+		messageSenderService:     messageSenderService,
+		userStore:                utils.NewUserDataStore(),
+		permissionsService:       permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.EventAttendeesCommand, h.startAttendees),
+		},
+		map[string][]ext.Handler{
+			eventAttendeesStateSelectEvent: {
+				handlers.NewMessage(message.Text, h.handleSelectEvent),
+				handlers.NewCallback(callbackquery.Equal(eventAttendeesCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// 1. startAttendees is the entry point handler for the attendees export conversation
+func (h *eventAttendeesHandler) startAttendees(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.EventAttendeesCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.EventAttendeesCommand,
+		)
+		return handlers.EndConversation()
+	}
+
+	events, err := h.getEventsWithCapacity()
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при получении списка мероприятий.", nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	if len(events) == 0 {
+		h.messageSenderService.Reply(msg, "Нет офлайн-мероприятий с записью участников.", nil)
+		return handlers.EndConversation()
+	}
+
+	title := fmt.Sprintf("Последние %d офлайн-мероприятия:", len(events))
+	actionDescription := "список участников которого ты хочешь выгрузить"
+	formattedResponse := formatters.FormatEventListForAdmin(events, title, constants.CancelCommand, actionDescription)
+
+	sentMsg, _ := h.messageSenderService.ReplyMarkdownWithReturnMessage(
+		msg,
+		formattedResponse,
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(eventAttendeesCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(eventAttendeesStateSelectEvent)
+}
+
+// 2. handleSelectEvent exports the attendee list of the selected event as CSV
+func (h *eventAttendeesHandler) handleSelectEvent(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	eventIDStr := strings.TrimSpace(strings.Replace(msg.Text, "/", "", 1))
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Некорректный ID. Пожалуйста, введи числовой ID или используй кнопку для отмены.", nil)
+		return nil // Stay in the same state
+	}
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil || !slices.Contains(constants.EventTypesWithCapacity, constants.EventType(event.Type)) {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Офлайн-мероприятие с ID %d не найдено. Пожалуйста, введи корректный ID или используй кнопку для отмены.", eventID),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	csvData, count, err := h.eventRegistrationService.BuildAttendeesCsv(event)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при формировании списка участников.", nil)
+		log.Printf("%s: Error during attendees export: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	_, err = b.SendDocument(
+		msg.Chat.Id,
+		gotgbot.InputFileByReader(fmt.Sprintf("event_%d_attendees.csv", event.ID), bytes.NewReader(csvData)),
+		&gotgbot.SendDocumentOpts{
+			Caption:   fmt.Sprintf("Участники мероприятия <b>%s</b>: %d", event.Name, count),
+			ParseMode: "HTML",
+		},
+	)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при отправке файла со списком участников.", nil)
+		log.Printf("%s: Error during attendees file sending: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *eventAttendeesHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// 3. handleCancel handles the /cancel command
+func (h *eventAttendeesHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, "Операция выгрузки участников отменена.", nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// getEventsWithCapacity returns the last events of types that support registration
+func (h *eventAttendeesHandler) getEventsWithCapacity() ([]repositories.Event, error) {
+	events, err := h.eventRepository.GetLastEvents(constants.EventEditGetLastLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []repositories.Event
+	for _, event := range events {
+		if slices.Contains(constants.EventTypesWithCapacity, constants.EventType(event.Type)) {
+			result = append(result, event)
+		}
+	}
+
+	return result, nil
+}
+
+func (h *eventAttendeesHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			eventAttendeesCtxDataKeyPreviousMessageID,
+			eventAttendeesCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *eventAttendeesHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		eventAttendeesCtxDataKeyPreviousMessageID, eventAttendeesCtxDataKeyPreviousChatID)
+}
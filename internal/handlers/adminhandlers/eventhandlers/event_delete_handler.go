@@ -39,7 +39,8 @@ const (
 
 type eventDeleteHandler struct {
 	config               *config.Config
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	auditLogService      *services.AuditLogService
 	messageSenderService *services.MessageSenderService
 	userStore            *utils.UserDataStore
 	permissionsService   *services.PermissionsService
@@ -47,13 +48,15 @@ type eventDeleteHandler struct {
 
 func NewEventDeleteHandler(
 	config *config.Config,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
 	h := &eventDeleteHandler{
 		config:               config,
 		eventRepository:      eventRepository,
+		auditLogService:      auditLogService,
 		messageSenderService: messageSenderService,
 		userStore:            utils.NewUserDataStore(),
 		permissionsService:   permissionsService,
@@ -84,9 +87,9 @@ func NewEventDeleteHandler(
 func (h *eventDeleteHandler) startDelete(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.ShowTopicsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.ShowTopicsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -242,14 +245,24 @@ func (h *eventDeleteHandler) handleCallbackConfirmYes(b *gotgbot.Bot, ctx *ext.C
 	}
 	eventName, _ := eventNameVal.(string)
 
+	// Keep the event state for the audit log
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(ctx.EffectiveMessage, "Произошла ошибка при получении мероприятия.", nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
 	// Delete the event
-	err := h.eventRepository.DeleteEvent(eventID)
+	err = h.eventRepository.DeleteEvent(eventID)
 	if err != nil {
 		h.messageSenderService.Reply(ctx.EffectiveMessage, "Произошла ошибка при удалении мероприятия.", nil)
 		log.Printf("%s: Error during event deletion: %v", utils.GetCurrentTypeName(), err)
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionDelete, constants.AuditEntityEvent, eventID, event, nil)
+
 	// Confirmation message
 	h.messageSenderService.ReplyMarkdown(
 		ctx.EffectiveMessage,
@@ -29,6 +29,7 @@ const (
 	eventEditStateEditName      = "event_edit_state_edit_name"
 	eventEditStateEditStartedAt = "event_edit_state_edit_started_at"
 	eventEditStateEditType      = "event_edit_state_edit_type"
+	eventEditStateEditCapacity  = "event_edit_state_edit_capacity"
 
 	// Context data keys
 	eventEditCtxDataKeySelectedEventID   = "event_edit_ctx_data_selected_event_id"
@@ -43,28 +44,35 @@ const (
 	eventEditTypeName      = "name"
 	eventEditTypeStartDate = "startDate"
 	eventEditTypeType      = "type"
+	eventEditTypeCapacity  = "capacity"
 )
 
 type eventEditHandler struct {
-	config               *config.Config
-	eventRepository      *repositories.EventRepository
-	messageSenderService *services.MessageSenderService
-	userStore            *utils.UserDataStore
-	permissionsService   *services.PermissionsService
+	config                   *config.Config
+	eventRepository          repositories.EventRepository
+	eventRegistrationService *services.EventRegistrationService
+	auditLogService          *services.AuditLogService
+	messageSenderService     *services.MessageSenderService
+	userStore                *utils.UserDataStore
+	permissionsService       *services.PermissionsService
 }
 
 func NewEventEditHandler(
 	config *config.Config,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	eventRegistrationService *services.EventRegistrationService,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
 	h := &eventEditHandler{
-		config:               config,
-		eventRepository:      eventRepository,
-		messageSenderService: messageSenderService,
-		userStore:            utils.NewUserDataStore(),
-		permissionsService:   permissionsService,
+		config:                   config,
+		eventRepository:          eventRepository,
+		eventRegistrationService: eventRegistrationService,
+		auditLogService:          auditLogService,
+		messageSenderService:     messageSenderService,
+		userStore:                utils.NewUserDataStore(),
+		permissionsService:       permissionsService,
 	}
 
 	return handlers.NewConversation(
@@ -92,6 +100,10 @@ func NewEventEditHandler(
 				handlers.NewMessage(message.Text, h.handleEditType),
 				handlers.NewCallback(callbackquery.Equal(eventEditCallbackConfirmCancel), h.handleCallbackCancel),
 			},
+			eventEditStateEditCapacity: {
+				handlers.NewMessage(message.Text, h.handleEditCapacity),
+				handlers.NewCallback(callbackquery.Equal(eventEditCallbackConfirmCancel), h.handleCallbackCancel),
+			},
 		},
 		&handlers.ConversationOpts{
 			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
@@ -103,9 +115,9 @@ func NewEventEditHandler(
 func (h *eventEditHandler) startEdit(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.ShowTopicsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.ShowTopicsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -175,7 +187,7 @@ func (h *eventEditHandler) handleSelectEvent(b *gotgbot.Bot, ctx *ext.Context) e
 	// Ask what the user wants to edit
 	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
 		msg,
-		fmt.Sprintf("Что ты хочешь отредактировать?\n/1. Название\n/2. Дату начала\n/3. Тип\n\nВведи номер:"),
+		fmt.Sprintf("Что ты хочешь отредактировать?\n/1. Название\n/2. Дату начала\n/3. Тип\n/4. Количество мест\n\nВведи номер:"),
 		&gotgbot.SendMessageOpts{
 			ReplyMarkup: buttons.CancelButton(eventEditCallbackConfirmCancel),
 		},
@@ -219,9 +231,9 @@ func (h *eventEditHandler) handleSelectEditType(b *gotgbot.Bot, ctx *ext.Context
 
 	// Parse the selection
 	selection, err := strconv.Atoi(selectionText)
-	if err != nil || selection < 1 || selection > 3 {
+	if err != nil || selection < 1 || selection > 4 {
 		h.messageSenderService.Reply(msg, fmt.Sprintf(
-			"Неверный выбор. Пожалуйста, введи число от 1 до 3, или используй кнопку для отмены",
+			"Неверный выбор. Пожалуйста, введи число от 1 до 4, или используй кнопку для отмены",
 		), nil)
 		return nil // Stay in the same state
 	}
@@ -242,13 +254,13 @@ func (h *eventEditHandler) handleSelectEditType(b *gotgbot.Bot, ctx *ext.Context
 		nextState = eventEditStateEditStartedAt
 		var currentStartedAt string
 		if event.StartedAt != nil {
-			currentStartedAt = event.StartedAt.Format("02.01.2006 15:04")
+			currentStartedAt = event.StartedAt.In(h.config.ClubTimezone).Format("02.01.2006 15:04")
 		} else {
 			currentStartedAt = "не задана"
 		}
 		message = fmt.Sprintf(
-			"Текущая дата старта: `%s` (UTC)\nВведи новую дату и время в формате DD.MM.YYYY HH:MM (UTC):",
-			currentStartedAt,
+			"Текущая дата старта: `%s` (%s)\nВведи новую дату и время в формате DD.MM.YYYY HH:MM (%s):",
+			currentStartedAt, h.config.ClubTimezone, h.config.ClubTimezone,
 		)
 	case 3:
 		editType = eventEditTypeType
@@ -264,6 +276,20 @@ func (h *eventEditHandler) handleSelectEditType(b *gotgbot.Bot, ctx *ext.Context
 			"Текущий тип: *%s*\n\nДоступные типы:\n%s\nВведи новый тип или его номер:",
 			event.Type, availableTypes,
 		)
+	case 4:
+		editType = eventEditTypeCapacity
+		nextState = eventEditStateEditCapacity
+
+		currentCapacity := "без ограничений"
+		if event.Capacity != nil {
+			currentCapacity = strconv.Itoa(*event.Capacity)
+		}
+
+		message = fmt.Sprintf(
+			"Текущее количество мест: *%s*\n\nВведи новое количество мест (0 — без ограничений). "+
+				"Если мест станет больше, участники из листа ожидания будут записаны автоматически:",
+			currentCapacity,
+		)
 	}
 
 	// Store the edit type
@@ -316,14 +342,26 @@ func (h *eventEditHandler) handleEditName(b *gotgbot.Bot, ctx *ext.Context) erro
 		return handlers.EndConversation()
 	}
 
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Ошибка при получении мероприятия с ID %d", eventID), nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
 	// Update the event name
-	err := h.eventRepository.UpdateEventName(eventID, newName)
+	err = h.eventRepository.UpdateEventName(eventID, newName)
 	if err != nil {
 		h.messageSenderService.Reply(msg, "Произошла ошибка при обновлении названия мероприятия.", nil)
 		log.Printf("%s: Error during event update: %v", utils.GetCurrentTypeName(), err)
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityEvent, eventID,
+		map[string]interface{}{"name": event.Name},
+		map[string]interface{}{"name": newName},
+	)
+
 	// Confirmation message
 	h.messageSenderService.ReplyMarkdown(
 		msg,
@@ -345,11 +383,12 @@ func (h *eventEditHandler) handleEditStartedAt(b *gotgbot.Bot, ctx *ext.Context)
 	msg := ctx.EffectiveMessage
 	dateTimeStr := strings.TrimSpace(msg.Text)
 
-	// Parse the start date
-	startedAt, err := time.Parse("02.01.2006 15:04", dateTimeStr)
+	// Parse the start date in the club timezone
+	startedAt, err := time.ParseInLocation("02.01.2006 15:04", dateTimeStr, h.config.ClubTimezone)
 	if err != nil {
 		h.messageSenderService.ReplyMarkdown(msg, fmt.Sprintf(
-			"Неверный формат даты. Пожалуйста, введи дату и время в формате *DD.MM.YYYY HH:MM* (UTC) или используй кнопку для отмены.",
+			"Неверный формат даты. Пожалуйста, введи дату и время в формате *DD.MM.YYYY HH:MM* (%s) или используй кнопку для отмены.",
+			h.config.ClubTimezone,
 		), nil)
 		return nil // Stay in the same state
 	}
@@ -376,6 +415,13 @@ func (h *eventEditHandler) handleEditStartedAt(b *gotgbot.Bot, ctx *ext.Context)
 		return handlers.EndConversation()
 	}
 
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Ошибка при получении мероприятия с ID %d", eventID), nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
 	// Update the event start date
 	err = h.eventRepository.UpdateEventStartedAt(eventID, startedAt)
 	if err != nil {
@@ -384,10 +430,15 @@ func (h *eventEditHandler) handleEditStartedAt(b *gotgbot.Bot, ctx *ext.Context)
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityEvent, eventID,
+		map[string]interface{}{"started_at": event.StartedAt},
+		map[string]interface{}{"started_at": startedAt},
+	)
+
 	// Confirmation message
 	h.messageSenderService.ReplyMarkdown(msg, fmt.Sprintf(
 		"Дата начала мероприятия с ID %d успешно обновлена на *%s* \n\nДля продолжения редактирования мероприятия используй команду /%s.\nДля просмотра всех команд используй команду /%s",
-		eventID, startedAt.Format("02.01.2006 15:04 UTC"), constants.EventEditCommand, constants.HelpCommand,
+		eventID, formatters.FormatEventStartedAt(startedAt, h.config.ClubTimezone), constants.EventEditCommand, constants.HelpCommand,
 	), nil)
 
 	// Clean up user data
@@ -461,6 +512,13 @@ func (h *eventEditHandler) handleEditType(b *gotgbot.Bot, ctx *ext.Context) erro
 		return handlers.EndConversation()
 	}
 
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Ошибка при получении мероприятия с ID %d", eventID), nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
 	// Update the event type
 	err = h.eventRepository.UpdateEventType(eventID, validEventType)
 	if err != nil {
@@ -469,6 +527,11 @@ func (h *eventEditHandler) handleEditType(b *gotgbot.Bot, ctx *ext.Context) erro
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityEvent, eventID,
+		map[string]interface{}{"type": event.Type},
+		map[string]interface{}{"type": validEventType},
+	)
+
 	// Confirmation message
 	h.messageSenderService.ReplyMarkdown(
 		msg,
@@ -489,6 +552,86 @@ func (h *eventEditHandler) handleEditType(b *gotgbot.Bot, ctx *ext.Context) erro
 	return handlers.EndConversation()
 }
 
+// 4.4. handleEditCapacity processes the new capacity input and updates the event
+func (h *eventEditHandler) handleEditCapacity(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	capacity, err := strconv.Atoi(strings.TrimSpace(msg.Text))
+	if err != nil || capacity < 0 {
+		h.messageSenderService.Reply(msg,
+			"Неверное количество мест. Пожалуйста, введи целое число (0 — без ограничений) или используй кнопку для отмены.",
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	// Get the selected event ID
+	eventIDVal, ok := h.userStore.Get(ctx.EffectiveUser.Id, eventEditCtxDataKeySelectedEventID)
+	if !ok {
+		h.messageSenderService.Reply(msg, fmt.Sprintf(
+			"Произошла ошибка при получении выбранного мероприятия. Пожалуйста, начни заново с /%s",
+			constants.EventEditCommand,
+		), nil)
+		return handlers.EndConversation()
+	}
+
+	eventID, ok := eventIDVal.(int)
+	if !ok {
+		log.Println("Invalid event ID type:", eventIDVal)
+		h.messageSenderService.Reply(msg, fmt.Sprintf(
+			"Произошла внутренняя ошибка (неверный тип ID). Пожалуйста, начни заново с /%s",
+			constants.EventEditCommand,
+		), nil)
+		return handlers.EndConversation()
+	}
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Ошибка при получении мероприятия с ID %d", eventID), nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	previousCapacity := event.Capacity
+
+	var capacityPtr *int
+	capacityStr := "без ограничений"
+	if capacity > 0 {
+		capacityPtr = &capacity
+		capacityStr = strconv.Itoa(capacity)
+	}
+
+	// Update the capacity, promoted members get notified by the service
+	err = h.eventRegistrationService.UpdateCapacity(event, capacityPtr)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при обновлении количества мест.", nil)
+		log.Printf("%s: Error during event update: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityEvent, eventID,
+		map[string]interface{}{"capacity": previousCapacity},
+		map[string]interface{}{"capacity": capacityPtr},
+	)
+
+	// Confirmation message
+	h.messageSenderService.ReplyMarkdown(
+		msg,
+		fmt.Sprintf(
+			"Количество мест мероприятия с ID %d успешно обновлено: *%s* \n\nДля продолжения редактирования мероприятия используй команду /%s.\nДля просмотра всех команд используй команду /%s",
+			eventID, capacityStr, constants.EventEditCommand, constants.HelpCommand,
+		),
+		nil,
+	)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
 // handleCallbackCancel processes the cancel button click
 func (h *eventEditHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
 	// Answer the callback query to remove the loading state on the button
@@ -0,0 +1,481 @@
+package eventhandlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	eventRecapStateSelectEvent     = "event_recap_state_select_event"
+	eventRecapStateAwaitTranscript = "event_recap_state_await_transcript"
+	eventRecapStateConfirmPublish  = "event_recap_state_confirm_publish"
+
+	// Context data keys
+	eventRecapCtxDataKeySelectedEventID   = "event_recap_ctx_data_selected_event_id"
+	eventRecapCtxDataKeyProcessing        = "event_recap_ctx_data_processing"
+	eventRecapCtxDataKeyCancelFunc        = "event_recap_ctx_data_cancel_func"
+	eventRecapCtxDataKeyPreviousMessageID = "event_recap_ctx_data_previous_message_id"
+	eventRecapCtxDataKeyPreviousChatID    = "event_recap_ctx_data_previous_chat_id"
+
+	// Callback data
+	eventRecapCallbackConfirmPublish = "event_recap_callback_confirm_publish"
+	eventRecapCallbackConfirmCancel  = "event_recap_callback_confirm_cancel"
+)
+
+type eventRecapHandler struct {
+	config               *config.Config
+	eventRepository      repositories.EventRepository
+	eventRecapRepository *repositories.EventRecapRepository
+	eventRecapService    *services.EventRecapService
+	auditLogService      *services.AuditLogService
+	messageSenderService *services.MessageSenderService
+	userStore            *utils.UserDataStore
+	permissionsService   *services.PermissionsService
+}
+
+func NewEventRecapHandler(
+	config *config.Config,
+	eventRepository repositories.EventRepository,
+	eventRecapRepository *repositories.EventRecapRepository,
+	eventRecapService *services.EventRecapService,
+	auditLogService *services.AuditLogService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &eventRecapHandler{
+		config:               config,
+		eventRepository:      eventRepository,
+		eventRecapRepository: eventRecapRepository,
+		eventRecapService:    eventRecapService,
+		auditLogService:      auditLogService,
+		messageSenderService: messageSenderService,
+		userStore:            utils.NewUserDataStore(),
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.EventRecapCommand, h.startRecap),
+		},
+		map[string][]ext.Handler{
+			eventRecapStateSelectEvent: {
+				handlers.NewMessage(message.Text, h.handleSelectEvent),
+				handlers.NewCallback(callbackquery.Equal(eventRecapCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+			eventRecapStateAwaitTranscript: {
+				handlers.NewMessage(message.Document, h.handleTranscript),
+				handlers.NewMessage(message.All, h.handleNonDocumentMessage),
+				handlers.NewCallback(callbackquery.Equal(eventRecapCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+			eventRecapStateConfirmPublish: {
+				handlers.NewCallback(callbackquery.Equal(eventRecapCallbackConfirmPublish), h.handleCallbackPublish),
+				handlers.NewCallback(callbackquery.Equal(eventRecapCallbackConfirmCancel), h.handleCallbackCancel),
+				handlers.NewMessage(message.All, h.handleMessageDuringConfirmation),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// 1. startRecap is the entry point handler for the recap conversation
+func (h *eventRecapHandler) startRecap(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.EventRecapCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.EventRecapCommand,
+		)
+		return handlers.EndConversation()
+	}
+
+	// Get a list of the last N events
+	events, err := h.eventRepository.GetLastEvents(constants.EventEditGetLastLimit)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при получении списка мероприятий.", nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	if len(events) == 0 {
+		h.messageSenderService.Reply(msg, "Нет созданных мероприятий для подведения итогов.", nil)
+		return handlers.EndConversation()
+	}
+
+	title := fmt.Sprintf("Последние %d мероприятия:", len(events))
+	actionDescription := "для которого ты хочешь подготовить итоги"
+	formattedResponse := formatters.FormatEventListForAdmin(events, title, constants.CancelCommand, actionDescription)
+
+	sentMsg, _ := h.messageSenderService.ReplyMarkdownWithReturnMessage(
+		msg,
+		formattedResponse,
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(eventRecapCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(eventRecapStateSelectEvent)
+}
+
+// 2. handleSelectEvent processes the user's selection of an event
+func (h *eventRecapHandler) handleSelectEvent(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	eventIDStr := strings.TrimSpace(strings.Replace(msg.Text, "/", "", 1))
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Некорректный ID. Пожалуйста, введи числовой ID или используй кнопку для отмены.", nil)
+		return nil // Stay in the same state
+	}
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Мероприятие с ID %d не найдено. Пожалуйста, введи корректный ID или используй кнопку для отмены.", eventID),
+			nil,
+		)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.userStore.Set(ctx.EffectiveUser.Id, eventRecapCtxDataKeySelectedEventID, eventID)
+
+	sentMsg, _ := h.messageSenderService.ReplyMarkdownWithReturnMessage(
+		msg,
+		fmt.Sprintf(
+			"📄 Отправь мне файл с расшифровкой записи мероприятия '*%s*' (ID: %d).\n\nПоддерживаемые форматы: *.vtt*, *.srt*, *.txt* (не более %d МБ).",
+			event.Name, event.ID, constants.EventRecapTranscriptMaxFileSize/(1024*1024),
+		),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(eventRecapCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(eventRecapStateAwaitTranscript)
+}
+
+// 3. handleTranscript downloads the transcript, generates the recap and shows it for confirmation
+func (h *eventRecapHandler) handleTranscript(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if we're already processing a transcript for this user
+	if isProcessing, ok := h.userStore.Get(ctx.EffectiveUser.Id, eventRecapCtxDataKeyProcessing); ok && isProcessing.(bool) {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Пожалуйста, дождись окончания обработки предыдущего файла, или используй /%s для отмены.", constants.CancelCommand),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	if !utils.IsSupportedTranscriptFile(msg.Document.FileName) {
+		h.messageSenderService.Reply(msg, "Неподдерживаемый формат файла. Пожалуйста, отправь файл в формате .vtt, .srt или .txt.", nil)
+		return nil // Stay in the same state
+	}
+
+	if msg.Document.FileSize > constants.EventRecapTranscriptMaxFileSize {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Файл слишком большой. Максимальный размер — %d МБ.", constants.EventRecapTranscriptMaxFileSize/(1024*1024)),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	eventID, ok := h.getSelectedEventID(ctx)
+	if !ok {
+		return handlers.EndConversation()
+	}
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Ошибка при получении мероприятия с ID %d", eventID), nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	transcript, err := h.eventRecapService.DownloadTranscript(msg.Document)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при загрузке файла с расшифровкой.", nil)
+		log.Printf("%s: Error during transcript download: %v", utils.GetCurrentTypeName(), err)
+		return nil // Stay in the same state
+	}
+
+	if strings.TrimSpace(transcript) == "" {
+		h.messageSenderService.Reply(msg, "Файл не содержит текста расшифровки. Пожалуйста, отправь другой файл.", nil)
+		return nil // Stay in the same state
+	}
+
+	// Mark as processing
+	h.userStore.Set(ctx.EffectiveUser.Id, eventRecapCtxDataKeyProcessing, true)
+
+	// Create a cancellable context for this operation
+	typingCtx, cancelTyping := context.WithCancel(context.Background())
+	h.userStore.Set(ctx.EffectiveUser.Id, eventRecapCtxDataKeyCancelFunc, cancelTyping)
+
+	// Make sure we clean up the processing flag in all exit paths
+	defer func() {
+		h.userStore.Set(ctx.EffectiveUser.Id, eventRecapCtxDataKeyProcessing, false)
+		h.userStore.Set(ctx.EffectiveUser.Id, eventRecapCtxDataKeyCancelFunc, nil)
+	}()
+	defer cancelTyping()
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+		msg,
+		"⏳ Готовлю итоги мероприятия, это может занять несколько минут...",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(eventRecapCallbackConfirmCancel),
+		},
+	)
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+
+	// Send typing action every 5 seconds while waiting for the OpenAI responses
+	h.messageSenderService.SendTypingAction(msg.Chat.Id)
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.messageSenderService.SendTypingAction(msg.Chat.Id)
+			case <-typingCtx.Done():
+				return
+			}
+		}
+	}()
+
+	recap, err := h.eventRecapService.GenerateRecap(typingCtx, event, transcript)
+	// Check if context was cancelled
+	if typingCtx.Err() != nil {
+		log.Printf("%s: Request was cancelled", utils.GetCurrentTypeName())
+		return handlers.EndConversation()
+	}
+
+	if errors.Is(err, services.ErrLLMBudgetExhausted) {
+		h.messageSenderService.Reply(msg, "🙏 Лимит запросов к ИИ на этот месяц исчерпан, итоги мероприятия можно будет подготовить в начале следующего месяца.", nil)
+		return handlers.EndConversation()
+	}
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при подготовке итогов мероприятия.", nil)
+		log.Printf("%s: Error during recap generation: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionCreate, constants.AuditEntityEventRecap, recap.ID,
+		nil,
+		map[string]interface{}{"event_id": event.ID, "recap": recap.Content},
+	)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	recapText := h.eventRecapService.FormatRecapMessage(event, recap)
+	err = h.messageSenderService.ReplyHtml(msg, recapText, nil)
+	if err != nil {
+		log.Printf("%s: Error during recap preview sending, sending plain text: %v", utils.GetCurrentTypeName(), err)
+		if err := h.messageSenderService.Reply(msg, utils.HtmlToPlainText(recapText), nil); err != nil {
+			log.Printf("%s: Error during plain text recap preview sending: %v", utils.GetCurrentTypeName(), err)
+		}
+	}
+
+	sentMsg, _ = h.messageSenderService.ReplyMarkdownWithReturnMessage(
+		msg,
+		"💾 Итоги сохранены. Опубликовать их в чате?\n\nНажми на кнопку ниже для подтверждения или отмены",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ConfirmAndCancelButton(eventRecapCallbackConfirmPublish, eventRecapCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(eventRecapStateConfirmPublish)
+}
+
+// handleNonDocumentMessage handles messages without a file while waiting for the transcript
+func (h *eventRecapHandler) handleNonDocumentMessage(b *gotgbot.Bot, ctx *ext.Context) error {
+	h.messageSenderService.Reply(
+		ctx.EffectiveMessage,
+		fmt.Sprintf("Пожалуйста, отправь файл с расшифровкой (.vtt, .srt или .txt), или используй /%s для отмены.", constants.CancelCommand),
+		nil,
+	)
+	return nil // Stay in the same state
+}
+
+// 4. handleCallbackPublish publishes the generated recap
+func (h *eventRecapHandler) handleCallbackPublish(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	eventID, ok := h.getSelectedEventID(ctx)
+	if !ok {
+		return handlers.EndConversation()
+	}
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(ctx.EffectiveMessage, fmt.Sprintf("Ошибка при получении мероприятия с ID %d", eventID), nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	recap, err := h.eventRecapRepository.GetByEventID(eventID)
+	if err != nil {
+		h.messageSenderService.Reply(ctx.EffectiveMessage, "Произошла ошибка при получении итогов мероприятия.", nil)
+		log.Printf("%s: Error during recap retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	_, err = h.eventRecapService.PublishRecap(event, recap)
+	if err != nil {
+		h.messageSenderService.Reply(ctx.EffectiveMessage, "Произошла ошибка при публикации итогов мероприятия.", nil)
+		log.Printf("%s: Error during recap publishing: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionPublish, constants.AuditEntityEventRecap, recap.ID,
+		nil,
+		map[string]interface{}{"event_id": event.ID, "recap": recap.Content},
+	)
+
+	h.messageSenderService.ReplyMarkdown(
+		ctx.EffectiveMessage,
+		fmt.Sprintf("✅ *Итоги мероприятия опубликованы!*\n\n🎯 *%s* _(ID: %d)_", event.Name, event.ID),
+		nil,
+	)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// handleMessageDuringConfirmation handles messages during the confirmation state
+func (h *eventRecapHandler) handleMessageDuringConfirmation(b *gotgbot.Bot, ctx *ext.Context) error {
+	h.messageSenderService.Reply(
+		ctx.EffectiveMessage,
+		"Пожалуйста, используй кнопки выше для подтверждения или отмены.",
+		nil,
+	)
+	return nil // Stay in the same state
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *eventRecapHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// 5. handleCancel handles the /cancel command
+func (h *eventRecapHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Cancel the recap generation if it is in progress
+	if cancelFunc, ok := h.userStore.Get(ctx.EffectiveUser.Id, eventRecapCtxDataKeyCancelFunc); ok {
+		if cancel, ok := cancelFunc.(context.CancelFunc); ok && cancel != nil {
+			cancel()
+		}
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, "Операция подведения итогов мероприятия отменена.", nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// getSelectedEventID returns the event ID selected on the previous step
+func (h *eventRecapHandler) getSelectedEventID(ctx *ext.Context) (int, bool) {
+	eventIDVal, ok := h.userStore.Get(ctx.EffectiveUser.Id, eventRecapCtxDataKeySelectedEventID)
+	if !ok {
+		h.messageSenderService.Reply(
+			ctx.EffectiveMessage,
+			fmt.Sprintf(
+				"Произошла ошибка при получении выбранного мероприятия. Пожалуйста, начни заново с /%s",
+				constants.EventRecapCommand,
+			),
+			nil,
+		)
+		log.Printf("%s: Error during event retrieval.", utils.GetCurrentTypeName())
+		return 0, false
+	}
+
+	eventID, ok := eventIDVal.(int)
+	if !ok {
+		h.messageSenderService.Reply(
+			ctx.EffectiveMessage,
+			fmt.Sprintf(
+				"Произошла внутренняя ошибка (неверный тип ID). Пожалуйста, начни заново с /%s",
+				constants.EventRecapCommand,
+			),
+			nil,
+		)
+		log.Printf("%s: Invalid event ID type: %v", utils.GetCurrentTypeName(), eventIDVal)
+		return 0, false
+	}
+
+	return eventID, true
+}
+
+func (h *eventRecapHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			eventRecapCtxDataKeyPreviousMessageID,
+			eventRecapCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *eventRecapHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	if sentMsg == nil {
+		return
+	}
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		eventRecapCtxDataKeyPreviousMessageID, eventRecapCtxDataKeyPreviousChatID)
+}
@@ -3,6 +3,7 @@ package eventhandlers
 import (
 	"fmt"
 	"log"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"evo-bot-go/internal/config"
 	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/utils"
 
@@ -26,10 +28,13 @@ const (
 	eventSetupStateAskEventName      = "event_setup_state_ask_event_name"
 	eventSetupStateAskEventType      = "event_setup_state_ask_event_type"
 	eventSetupStateAskEventStartedAt = "event_setup_state_ask_event_started_at"
+	eventSetupStateAskEventCapacity  = "event_setup_state_ask_event_capacity"
 
 	// Context data keys
 	eventSetupCtxDataKeyEventName         = "event_setup_ctx_data_event_name"
 	eventSetupCtxDataKeyEventID           = "event_setup_ctx_data_event_id"
+	eventSetupCtxDataKeyEventType         = "event_setup_ctx_data_event_type"
+	eventSetupCtxDataKeyEventStartedAt    = "event_setup_ctx_data_event_started_at"
 	eventSetupCtxDataKeyPreviousMessageID = "event_setup_ctx_data_previous_message_id"
 	eventSetupCtxDataKeyPreviousChatID    = "event_setup_ctx_data_previous_chat_id"
 
@@ -39,7 +44,8 @@ const (
 
 type eventSetupHandler struct {
 	config               *config.Config
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	auditLogService      *services.AuditLogService
 	messageSenderService *services.MessageSenderService
 	userStore            *utils.UserDataStore
 	permissionsService   *services.PermissionsService
@@ -47,13 +53,15 @@ type eventSetupHandler struct {
 
 func NewEventSetupHandler(
 	config *config.Config,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
 	h := &eventSetupHandler{
 		config:               config,
 		eventRepository:      eventRepository,
+		auditLogService:      auditLogService,
 		messageSenderService: messageSenderService,
 		userStore:            utils.NewUserDataStore(),
 		permissionsService:   permissionsService,
@@ -76,6 +84,10 @@ func NewEventSetupHandler(
 				handlers.NewMessage(message.Text, h.handleEventStartedAt),
 				handlers.NewCallback(callbackquery.Equal(eventSetupCallbackConfirmCancel), h.handleCallbackCancel),
 			},
+			eventSetupStateAskEventCapacity: {
+				handlers.NewMessage(message.Text, h.handleEventCapacity),
+				handlers.NewCallback(callbackquery.Equal(eventSetupCallbackConfirmCancel), h.handleCallbackCancel),
+			},
 		},
 		&handlers.ConversationOpts{
 			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
@@ -87,9 +99,9 @@ func NewEventSetupHandler(
 func (h *eventSetupHandler) startSetup(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.ShowTopicsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.ShowTopicsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -208,13 +220,22 @@ func (h *eventSetupHandler) handleEventType(b *gotgbot.Bot, ctx *ext.Context) er
 		return handlers.EndConversation()
 	}
 
-	// Store the event ID
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionCreate, constants.AuditEntityEvent, id,
+		nil,
+		map[string]interface{}{"name": eventName, "type": eventType},
+	)
+
+	// Store the event ID and type
 	h.userStore.Set(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventID, id)
+	h.userStore.Set(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventType, eventType)
 
 	// Ask for start date
 	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
 		msg,
-		"Когда стартует мероприятие? Введи дату и время в формате DD.MM.YYYY HH:MM:",
+		fmt.Sprintf(
+			"Когда стартует мероприятие? Введи дату и время в формате DD.MM.YYYY HH:MM (%s):",
+			h.config.ClubTimezone,
+		),
 		&gotgbot.SendMessageOpts{
 			ReplyMarkup: buttons.CancelButton(eventSetupCallbackConfirmCancel),
 		},
@@ -229,12 +250,15 @@ func (h *eventSetupHandler) handleEventStartedAt(b *gotgbot.Bot, ctx *ext.Contex
 	msg := ctx.EffectiveMessage
 	dateTimeStr := strings.TrimSpace(msg.Text)
 
-	// Parse the start date
-	startedAt, err := time.Parse("02.01.2006 15:04", dateTimeStr)
+	// Parse the start date in the club timezone
+	startedAt, err := time.ParseInLocation("02.01.2006 15:04", dateTimeStr, h.config.ClubTimezone)
 	if err != nil {
 		h.messageSenderService.Reply(
 			msg,
-			"Неверный формат даты. Пожалуйста, введи дату и время в формате DD.MM.YYYY HH:MM или используй кнопку для отмены.",
+			fmt.Sprintf(
+				"Неверный формат даты. Пожалуйста, введи дату и время в формате DD.MM.YYYY HH:MM (%s) или используй кнопку для отмены.",
+				h.config.ClubTimezone,
+			),
 			nil,
 		)
 		return nil // Stay in the same state
@@ -275,6 +299,11 @@ func (h *eventSetupHandler) handleEventStartedAt(b *gotgbot.Bot, ctx *ext.Contex
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityEvent, eventID,
+		nil,
+		map[string]interface{}{"started_at": startedAt},
+	)
+
 	// Get event name for the success message
 	eventNameVal, ok := h.userStore.Get(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventName)
 	if !ok {
@@ -290,22 +319,109 @@ func (h *eventSetupHandler) handleEventStartedAt(b *gotgbot.Bot, ctx *ext.Contex
 		return handlers.EndConversation()
 	}
 
-	// Success message
+	// Offline events can have a limited number of places, ask for it
+	eventTypeVal, _ := h.userStore.Get(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventType)
+	if eventType, ok := eventTypeVal.(constants.EventType); ok && slices.Contains(constants.EventTypesWithCapacity, eventType) {
+		h.userStore.Set(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventStartedAt, startedAt)
+
+		sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+			msg,
+			"Сколько мест на мероприятии? Введи число (0 — без ограничений). Когда места закончатся, участники попадут в лист ожидания:",
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.CancelButton(eventSetupCallbackConfirmCancel),
+			},
+		)
+
+		h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+		return handlers.NextConversationState(eventSetupStateAskEventCapacity)
+	}
+
+	h.replySetupSuccess(msg, eventName, eventID, startedAt, nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// 5. handleEventCapacity processes the capacity input for offline events
+func (h *eventSetupHandler) handleEventCapacity(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	capacity, err := strconv.Atoi(strings.TrimSpace(msg.Text))
+	if err != nil || capacity < 0 {
+		h.messageSenderService.Reply(
+			msg,
+			"Неверное количество мест. Пожалуйста, введи целое число (0 — без ограничений) или используй кнопку для отмены.",
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	eventIDVal, _ := h.userStore.Get(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventID)
+	eventNameVal, _ := h.userStore.Get(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventName)
+	startedAtVal, _ := h.userStore.Get(ctx.EffectiveUser.Id, eventSetupCtxDataKeyEventStartedAt)
+
+	eventID, okID := eventIDVal.(int)
+	eventName, okName := eventNameVal.(string)
+	startedAt, okStartedAt := startedAtVal.(time.Time)
+	if !okID || !okName || !okStartedAt {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Произошла внутренняя ошибка. Не удалось найти данные мероприятия. Количество мест можно задать через /%s.",
+				constants.EventEditCommand,
+			),
+			nil,
+		)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	var capacityPtr *int
+	if capacity > 0 {
+		capacityPtr = &capacity
+	}
+
+	// No registrations exist yet, so there is nobody to promote from the waitlist
+	if _, err := h.eventRepository.UpdateEventCapacity(eventID, capacityPtr); err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при сохранении количества мест.", nil)
+		log.Printf("%s: Error during event capacity update: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityEvent, eventID,
+		nil,
+		map[string]interface{}{"capacity": capacityPtr},
+	)
+
+	h.replySetupSuccess(msg, eventName, eventID, startedAt, capacityPtr)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// replySetupSuccess sends the final message of the setup conversation
+func (h *eventSetupHandler) replySetupSuccess(msg *gotgbot.Message, eventName string, eventID int, startedAt time.Time, capacity *int) {
+	capacityStr := ""
+	if capacity != nil {
+		capacityStr = fmt.Sprintf(" и количеством мест: *%d*", *capacity)
+	}
+
 	h.messageSenderService.Reply(
 		msg,
 		fmt.Sprintf(
-			"Запись о мероприятии '*%s*' успешно создана с ID: %d и датой старта: *%s*\n\nДля редактирования мероприятия используй команду /%s.\nДля просмотра всех команд используй команду /%s",
-			eventName, eventID, startedAt.Format("02.01.2006 15:04"), constants.EventEditCommand, constants.HelpCommand,
+			"Запись о мероприятии '*%s*' успешно создана с ID: %d и датой старта: *%s*%s\n\nДля редактирования мероприятия используй команду /%s.\nДля просмотра всех команд используй команду /%s",
+			eventName, eventID, formatters.FormatEventStartedAt(startedAt, h.config.ClubTimezone), capacityStr, constants.EventEditCommand, constants.HelpCommand,
 		),
 		&gotgbot.SendMessageOpts{
 			ParseMode: "Markdown",
 		},
 	)
-
-	// Clean up user data
-	h.userStore.Clear(ctx.EffectiveUser.Id)
-
-	return handlers.EndConversation()
 }
 
 // handleCallbackCancel processes the cancel button click
@@ -317,7 +433,7 @@ func (h *eventSetupHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Contex
 	return h.handleCancel(b, ctx)
 }
 
-// 5. handleCancel handles the /cancel command
+// 6. handleCancel handles the /cancel command
 func (h *eventSetupHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
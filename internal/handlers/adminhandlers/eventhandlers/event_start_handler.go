@@ -46,7 +46,9 @@ const (
 
 type eventStartHandler struct {
 	config               *config.Config
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	scoreService         *services.ScoreService
+	auditLogService      *services.AuditLogService
 	messageSenderService *services.MessageSenderService
 	userStore            *utils.UserDataStore
 	permissionsService   *services.PermissionsService
@@ -54,13 +56,17 @@ type eventStartHandler struct {
 
 func NewEventStartHandler(
 	config *config.Config,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	scoreService *services.ScoreService,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
 	h := &eventStartHandler{
 		config:               config,
 		eventRepository:      eventRepository,
+		scoreService:         scoreService,
+		auditLogService:      auditLogService,
 		messageSenderService: messageSenderService,
 		userStore:            utils.NewUserDataStore(),
 		permissionsService:   permissionsService,
@@ -95,9 +101,9 @@ func NewEventStartHandler(
 func (h *eventStartHandler) startEvent(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.ShowTopicsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.ShowTopicsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -295,6 +301,20 @@ func (h *eventStartHandler) handleCallbackYes(b *gotgbot.Bot, ctx *ext.Context)
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionStart, constants.AuditEntityEvent, eventID,
+		map[string]interface{}{"status": event.Status},
+		map[string]interface{}{"status": constants.EventStatusFinished},
+	)
+
+	// Award karma to members registered for the event
+	registrations, err := h.eventRepository.GetEventRegistrationsWithUsers(eventID)
+	if err != nil {
+		log.Printf("%s: Error during event registrations retrieval: %v", utils.GetCurrentTypeName(), err)
+	} else if len(registrations) > 0 {
+		awardedCount := h.scoreService.AwardEventRegistrations(eventID, registrations)
+		log.Printf("%s: Awarded event registration karma to %d members", utils.GetCurrentTypeName(), awardedCount)
+	}
+
 	buttonWithLink := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			{
@@ -0,0 +1,94 @@
+package adminhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type modLogHandler struct {
+	config                   *config.Config
+	moderationActionsService *services.ModerationActionsService
+	messageSenderService     *services.MessageSenderService
+	permissionsService       *services.PermissionsService
+}
+
+func NewModLogHandler(
+	config *config.Config,
+	moderationActionsService *services.ModerationActionsService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &modLogHandler{
+		config:                   config,
+		moderationActionsService: moderationActionsService,
+		messageSenderService:     messageSenderService,
+		permissionsService:       permissionsService,
+	}
+
+	return handlers.NewCommand(constants.ModLogCommand, h.handleCommand)
+}
+
+// handleCommand shows the latest moderation actions, or the history of a member for "/modlog @username"
+func (h *modLogHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityModerate, constants.ModLogCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.ModLogCommand,
+		)
+		return nil
+	}
+
+	reference := ""
+	if fields := strings.Fields(msg.Text); len(fields) > 1 {
+		reference = fields[1]
+	}
+
+	if reference == "" {
+		actions, err := h.moderationActionsService.GetRecent()
+		if err != nil {
+			h.messageSenderService.Reply(msg, "Ошибка при получении журнала модерации.", nil)
+			return fmt.Errorf("%s: failed to get recent moderation actions: %w", utils.GetCurrentTypeName(), err)
+		}
+
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatModerationLog("Журнал модерации", actions, true), nil)
+		return nil
+	}
+
+	user, err := h.moderationActionsService.ResolveUser(reference)
+	if err == sql.ErrNoRows {
+		h.messageSenderService.Reply(msg, "Участник не найден.", nil)
+		return nil
+	}
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при поиске участника.", nil)
+		return fmt.Errorf("%s: failed to resolve user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	actions, err := h.moderationActionsService.GetHistory(user.ID, constants.ModerationLogLimit)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Ошибка при получении журнала модерации.", nil)
+		return fmt.Errorf("%s: failed to get moderation history: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	title := "Журнал модерации: " + html.EscapeString(strings.TrimSpace(user.Firstname+" "+user.Lastname))
+	h.messageSenderService.ReplyHtml(msg, formatters.FormatModerationLog(title, actions, false), nil)
+	return nil
+}
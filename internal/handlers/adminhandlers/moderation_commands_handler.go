@@ -0,0 +1,253 @@
+package adminhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"slices"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+// moderationCommands lists the commands handled by moderationCommandsHandler
+var moderationCommands = []string{
+	constants.WarnCommand,
+	constants.MuteCommand,
+	constants.UnmuteCommand,
+	constants.BanCommand,
+	constants.UnbanCommand,
+}
+
+type moderationCommandsHandler struct {
+	config                   *config.Config
+	moderationActionsService *services.ModerationActionsService
+	auditLogService          *services.AuditLogService
+	messageSenderService     *services.MessageSenderService
+	permissionsService       *services.PermissionsService
+}
+
+// NewModerationCommandsHandler handles /warn, /mute, /unmute, /ban and /unban.
+// In the club group the commands are replies to a message of the member,
+// in DM the member is referenced by @username or Telegram ID.
+func NewModerationCommandsHandler(
+	config *config.Config,
+	moderationActionsService *services.ModerationActionsService,
+	auditLogService *services.AuditLogService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &moderationCommandsHandler{
+		config:                   config,
+		moderationActionsService: moderationActionsService,
+		auditLogService:          auditLogService,
+		messageSenderService:     messageSenderService,
+		permissionsService:       permissionsService,
+	}
+
+	return handlers.NewMessage(h.check, h.handle)
+}
+
+func (h *moderationCommandsHandler) check(msg *gotgbot.Message) bool {
+	if msg == nil || msg.From == nil {
+		return false
+	}
+
+	command, _, _ := h.parseCommand(msg.Text)
+	return slices.Contains(moderationCommands, command)
+}
+
+func (h *moderationCommandsHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	command, botUsername, args := h.parseCommand(msg.Text)
+	if botUsername != "" && !strings.EqualFold(botUsername, b.User.Username) {
+		return ext.ContinueGroups
+	}
+
+	switch {
+	case msg.Chat.Type == constants.PrivateChatType:
+		return h.handlePrivate(msg, command, args)
+	case msg.Chat.Id == utils.ChatIdToFullChatId(h.config.SuperGroupChatID):
+		return h.handleGroup(b, msg, command, args)
+	default:
+		return nil
+	}
+}
+
+// handleGroup applies the command to the author of the replied message
+func (h *moderationCommandsHandler) handleGroup(b *gotgbot.Bot, msg *gotgbot.Message, command string, args []string) error {
+	if !h.permissionsService.HasCapability(msg.From.Id, constants.CapabilityModerate) {
+		log.Printf("%s: User %d tried to use /%s without the capability", utils.GetCurrentTypeName(), msg.From.Id, command)
+		return nil
+	}
+
+	// Every message in a forum topic replies to the topic's first message, so it is not a real reply
+	replyTo := msg.ReplyToMessage
+	if replyTo == nil || replyTo.MessageId == msg.MessageThreadId || replyTo.From == nil || replyTo.From.IsBot {
+		_ = h.messageSenderService.ReplyWithCleanupAfterDelayWithPing(msg,
+			fmt.Sprintf("Используй /%s ответом на сообщение участника.", command), 10, nil)
+		return nil
+	}
+
+	target, err := h.moderationActionsService.GetOrCreateUser(replyTo.From)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get target user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	resultText, err := h.apply(msg.From, target, command, args)
+	if err != nil {
+		_ = h.messageSenderService.ReplyWithCleanupAfterDelayWithPing(msg, "Ошибка: "+err.Error(), 10, nil)
+		return nil
+	}
+
+	// Remove the command and explain the action in the thread
+	if _, err := msg.Delete(b, nil); err != nil {
+		log.Printf("%s: Failed to delete command message: %v", utils.GetCurrentTypeName(), err)
+	}
+	if err := h.messageSenderService.ReplyHtml(replyTo, resultText, nil); err != nil {
+		log.Printf("%s: Failed to send moderation result: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	return nil
+}
+
+// handlePrivate applies the command to the member referenced by the first argument
+func (h *moderationCommandsHandler) handlePrivate(msg *gotgbot.Message, command string, args []string) error {
+	if !h.permissionsService.CheckCapability(msg, constants.CapabilityModerate, command) {
+		return nil
+	}
+
+	if len(args) == 0 {
+		usage := fmt.Sprintf("Использование: <code>/%s @username причина</code> или <code>/%s TelegramID причина</code>", command, command)
+		if command == constants.MuteCommand {
+			usage = fmt.Sprintf("Использование: <code>/%s @username 1h причина</code>. Длительность: <code>30m</code>, <code>2h</code>, <code>1d</code>, <code>1w</code>.", command)
+		}
+		h.messageSenderService.ReplyHtml(msg, usage, nil)
+		return nil
+	}
+
+	target, err := h.moderationActionsService.ResolveUser(args[0])
+	if err == sql.ErrNoRows {
+		h.messageSenderService.Reply(msg, "Участник не найден.", nil)
+		return nil
+	}
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при поиске участника.", nil)
+		return fmt.Errorf("%s: failed to resolve user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	resultText, err := h.apply(msg.From, target, command, args[1:])
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Ошибка: "+err.Error(), nil)
+		return nil
+	}
+
+	h.messageSenderService.ReplyHtml(msg, resultText, nil)
+	return nil
+}
+
+// apply runs the command and returns the HTML text describing the result
+func (h *moderationCommandsHandler) apply(adminTgUser *gotgbot.User, target *repositories.User, command string, args []string) (string, error) {
+	admin, err := h.moderationActionsService.GetOrCreateUser(adminTgUser)
+	if err != nil {
+		log.Printf("%s: Failed to get admin user: %v", utils.GetCurrentTypeName(), err)
+		return "", fmt.Errorf("не удалось определить администратора")
+	}
+
+	name := html.EscapeString(strings.TrimSpace(target.Firstname + " " + target.Lastname))
+	userLink := fmt.Sprintf("<a href=\"tg://user?id=%d\">%s</a>", target.TgID, name)
+
+	var resultText string
+	switch command {
+	case constants.WarnCommand:
+		reason := strings.Join(args, " ")
+		result, err := h.moderationActionsService.Warn(target, admin, reason)
+		if err != nil {
+			log.Printf("%s: Failed to warn user: %v", utils.GetCurrentTypeName(), err)
+			return "", fmt.Errorf("не удалось выдать предупреждение")
+		}
+		resultText = fmt.Sprintf("⚠️ %s получает предупреждение (%d/%d)",
+			userLink, result.Warnings, constants.ModerationWarningsBeforeMute)
+		if result.Muted {
+			resultText += fmt.Sprintf(" и не может писать в чат %s",
+				utils.FormatDurationRu(constants.ModerationWarningsMuteDuration))
+		}
+		resultText += formatReasonHtml(reason)
+
+	case constants.MuteCommand:
+		if len(args) == 0 {
+			return "", fmt.Errorf("укажи длительность, например 30m, 2h, 1d или 1w")
+		}
+		duration, err := utils.ParseShortDuration(args[0])
+		if err != nil {
+			return "", fmt.Errorf("неверная длительность «%s», используй 30m, 2h, 1d или 1w, но не больше 366d", args[0])
+		}
+		reason := strings.Join(args[1:], " ")
+		if err := h.moderationActionsService.Mute(target, admin, duration, reason); err != nil {
+			log.Printf("%s: Failed to mute user: %v", utils.GetCurrentTypeName(), err)
+			return "", fmt.Errorf("не удалось выдать мьют")
+		}
+		resultText = fmt.Sprintf("🔇 %s не может писать в чат %s", userLink, utils.FormatDurationRu(duration)) +
+			formatReasonHtml(reason)
+
+	case constants.UnmuteCommand:
+		reason := strings.Join(args, " ")
+		if err := h.moderationActionsService.Unmute(target, admin, reason); err != nil {
+			log.Printf("%s: Failed to unmute user: %v", utils.GetCurrentTypeName(), err)
+			return "", fmt.Errorf("не удалось снять мьют")
+		}
+		resultText = fmt.Sprintf("🔊 %s снова может писать в чат", userLink)
+
+	case constants.BanCommand:
+		reason := strings.Join(args, " ")
+		if err := h.moderationActionsService.Ban(target, admin, reason); err != nil {
+			log.Printf("%s: Failed to ban user: %v", utils.GetCurrentTypeName(), err)
+			return "", fmt.Errorf("не удалось забанить участника")
+		}
+		resultText = fmt.Sprintf("🚫 %s удаляется из чата без возможности вернуться", userLink) + formatReasonHtml(reason)
+
+	case constants.UnbanCommand:
+		reason := strings.Join(args, " ")
+		if err := h.moderationActionsService.Unban(target, admin, reason); err != nil {
+			log.Printf("%s: Failed to unban user: %v", utils.GetCurrentTypeName(), err)
+			return "", fmt.Errorf("не удалось разбанить участника")
+		}
+		resultText = fmt.Sprintf("✅ %s может снова вступить в чат", userLink)
+	}
+
+	log.Printf("%s: Admin %d used /%s on user %d", utils.GetCurrentTypeName(), adminTgUser.Id, command, target.TgID)
+
+	h.auditLogService.Record(adminTgUser, constants.AuditActionModerate, constants.AuditEntityUser, target.ID,
+		nil,
+		map[string]interface{}{"command": command, "args": strings.Join(args, " ")},
+	)
+	return resultText, nil
+}
+
+// parseCommand splits "/mute@bot 1h reason" into the command, the bot username and the arguments
+func (h *moderationCommandsHandler) parseCommand(text string) (string, string, []string) {
+	if !strings.HasPrefix(text, "/") {
+		return "", "", nil
+	}
+
+	fields := strings.Fields(text)
+	command, botUsername, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	return strings.ToLower(command), botUsername, fields[1:]
+}
+
+func formatReasonHtml(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return "\nПричина: " + html.EscapeString(reason)
+}
@@ -0,0 +1,297 @@
+package adminhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	moderationRulesStateMainMenu      = "moderation_rules_state_main_menu"
+	moderationRulesStateAskRule       = "moderation_rules_state_ask_rule"
+	moderationRulesStateAskDeleteRule = "moderation_rules_state_ask_delete_rule"
+
+	// Context data keys
+	moderationRulesCtxDataKeyPreviousMessageID = "moderation_rules_ctx_data_previous_message_id"
+	moderationRulesCtxDataKeyPreviousChatID    = "moderation_rules_ctx_data_previous_chat_id"
+)
+
+type moderationRulesHandler struct {
+	config                 *config.Config
+	moderationRulesService *services.ModerationRulesService
+	auditLogService        *services.AuditLogService
+	messageSenderService   *services.MessageSenderService
+	userStore              *utils.UserDataStore
+	permissionsService     *services.PermissionsService
+}
+
+func NewModerationRulesHandler(
+	config *config.Config,
+	moderationRulesService *services.ModerationRulesService,
+	auditLogService *services.AuditLogService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &moderationRulesHandler{
+		config:                 config,
+		moderationRulesService: moderationRulesService,
+		auditLogService:        auditLogService,
+		messageSenderService:   messageSenderService,
+		userStore:              utils.NewUserDataStore(),
+		permissionsService:     permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.ModerationRulesCommand, h.startModerationRules),
+		},
+		map[string][]ext.Handler{
+			moderationRulesStateMainMenu: {
+				handlers.NewCallback(callbackquery.Equal(constants.ModerationRulesSaveCallback), h.handleCallbackSave),
+				handlers.NewCallback(callbackquery.Equal(constants.ModerationRulesDeleteCallback), h.handleCallbackDelete),
+				handlers.NewCallback(callbackquery.Equal(constants.ModerationRulesCancelCallback), h.handleCallbackCancel),
+			},
+			moderationRulesStateAskRule: {
+				handlers.NewMessage(message.Text, h.handleRule),
+				handlers.NewCallback(callbackquery.Equal(constants.ModerationRulesCancelCallback), h.handleCallbackCancel),
+			},
+			moderationRulesStateAskDeleteRule: {
+				handlers.NewMessage(message.Text, h.handleDeleteRule),
+				handlers.NewCallback(callbackquery.Equal(constants.ModerationRulesCancelCallback), h.handleCallbackCancel),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// 1. startModerationRules is the entry point handler that shows the current rules
+func (h *moderationRulesHandler) startModerationRules(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageModerationRules, constants.ModerationRulesCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.ModerationRulesCommand,
+		)
+		return handlers.EndConversation()
+	}
+
+	sentMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+		msg.Chat.Id,
+		formatters.FormatModerationRules(h.moderationRulesService.GetRules()),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ModerationRulesMainMenuButtons(),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(moderationRulesStateMainMenu)
+}
+
+// 2.1 handleCallbackSave asks for the rule definition
+func (h *moderationRulesHandler) handleCallbackSave(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	text := "Отправь правило одним сообщением. Первая строка — параметры в формате <code>ключ=значение</code>:\n\n" +
+		"<code>topic=123 posters=members min_score=10 users=111,222 links=no media=yes forwards=no action=delete mute=60</code>\n\n" +
+		"• <code>topic</code> — ID топика (обязательно)\n" +
+		"• <code>posters</code> — кто может писать: <code>admins</code>, <code>members</code>, <code>everyone</code>\n" +
+		"• <code>min_score</code> — минимальная карма для <code>members</code>\n" +
+		"• <code>users</code> — Telegram ID, которым можно писать всегда\n" +
+		"• <code>links</code>, <code>media</code>, <code>forwards</code> — разрешены ли ссылки, медиа и пересылки (<code>yes</code>/<code>no</code>)\n" +
+		"• <code>action</code> — <code>delete</code>, <code>warn</code>, <code>mute</code> или <code>forward</code> (перенос в топик для пересылки)\n" +
+		"• <code>mute</code> — длительность мьюта в минутах\n\n" +
+		fmt.Sprintf("Следующие строки — текст сообщения в ЛС нарушителю (Markdown). Можно использовать <code>%s</code> и <code>%s</code>. ",
+			constants.ModerationTemplateTopicUrl, constants.ModerationTemplateReason) +
+		"Если текст не указан, сообщение не отправляется.\n\n" +
+		"Правило для существующего топика будет заменено."
+
+	sentMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+		ctx.EffectiveChat.Id,
+		text,
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(constants.ModerationRulesCancelCallback),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(moderationRulesStateAskRule)
+}
+
+// 2.2 handleCallbackDelete asks for the topic ID of the rule to delete
+func (h *moderationRulesHandler) handleCallbackDelete(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	sentMsg, _ := h.messageSenderService.SendWithReturnMessage(
+		ctx.EffectiveChat.Id,
+		"Введи ID топика, правило которого нужно удалить.",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(constants.ModerationRulesCancelCallback),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(moderationRulesStateAskDeleteRule)
+}
+
+// 3.1 handleRule parses and saves the rule
+func (h *moderationRulesHandler) handleRule(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	rule, err := h.moderationRulesService.ParseRule(msg.Text)
+	if err != nil {
+		h.messageSenderService.Reply(msg, fmt.Sprintf("Не удалось разобрать правило: %s. Попробуй ещё раз.", err.Error()), nil)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	var previousRule interface{}
+	auditAction := constants.AuditActionCreate
+	if existingRule, ok := h.moderationRulesService.GetRule(rule.TopicID); ok {
+		previousRule = existingRule
+		auditAction = constants.AuditActionUpdate
+	}
+
+	if err := h.moderationRulesService.SaveRule(rule); err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при сохранении правила.", nil)
+		log.Printf("%s: Error during moderation rule saving: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	log.Printf("%s: Admin %d saved moderation rule for topic %d", utils.GetCurrentTypeName(), ctx.EffectiveUser.Id, rule.TopicID)
+
+	h.auditLogService.Record(ctx.EffectiveUser, auditAction, constants.AuditEntityModerationRule, rule.TopicID, previousRule, rule)
+
+	h.messageSenderService.SendHtml(
+		msg.Chat.Id,
+		fmt.Sprintf("✅ Правило для топика %d сохранено.\n\n", rule.TopicID)+
+			formatters.FormatModerationRules(h.moderationRulesService.GetRules()),
+		nil,
+	)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// 3.2 handleDeleteRule deletes the rule of the topic
+func (h *moderationRulesHandler) handleDeleteRule(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	topicID, err := strconv.Atoi(strings.TrimSpace(msg.Text))
+	if err != nil {
+		h.messageSenderService.Reply(msg, "ID топика должен быть числом. Попробуй ещё раз.", nil)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	previousRule, _ := h.moderationRulesService.GetRule(topicID)
+
+	err = h.moderationRulesService.DeleteRule(topicID)
+	if err == sql.ErrNoRows {
+		h.messageSenderService.Reply(msg,
+			fmt.Sprintf("Правило для топика %d не найдено в базе. Правила из конфигурации (TG_EVO_BOT_CLOSED_TOPICS_IDS) удаляются только из неё.", topicID),
+			nil)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при удалении правила.", nil)
+		log.Printf("%s: Error during moderation rule deletion: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	log.Printf("%s: Admin %d deleted moderation rule for topic %d", utils.GetCurrentTypeName(), ctx.EffectiveUser.Id, topicID)
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionDelete, constants.AuditEntityModerationRule, topicID, previousRule, nil)
+
+	h.messageSenderService.Reply(msg, fmt.Sprintf("✅ Правило для топика %d удалено.", topicID), nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *moderationRulesHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// 4. handleCancel handles the /cancel command
+func (h *moderationRulesHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, "Управление правилами модерации отменено.", nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+func (h *moderationRulesHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			moderationRulesCtxDataKeyPreviousMessageID,
+			moderationRulesCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *moderationRulesHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	if sentMsg == nil {
+		return
+	}
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		moderationRulesCtxDataKeyPreviousMessageID, moderationRulesCtxDataKeyPreviousChatID)
+}
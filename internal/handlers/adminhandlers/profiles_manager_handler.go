@@ -2,6 +2,7 @@ package adminhandlers
 
 import (
 	"database/sql"
+	"errors"
 	"evo-bot-go/internal/buttons"
 	"evo-bot-go/internal/config"
 	"evo-bot-go/internal/constants"
@@ -11,6 +12,7 @@ import (
 	"evo-bot-go/internal/utils"
 	"fmt"
 	"log"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +39,7 @@ const (
 	adminProfilesStateAwaitLastname                 = "admin_profiles_state_await_lastname"
 	adminProfilesStateAwaitCoffeeBan                = "admin_profiles_state_await_coffee_ban"
 	adminProfilesStateAwaitUsername                 = "admin_profiles_state_await_username"
+	adminProfilesStateAwaitOptionalField            = "admin_profiles_state_await_optional_field"
 
 	// UserStore keys
 	adminProfilesCtxDataKeyField                   = "admin_profiles_ctx_data_field"
@@ -63,31 +66,43 @@ const (
 )
 
 type adminProfilesHandler struct {
-	config               *config.Config
-	messageSenderService *services.MessageSenderService
-	permissionsService   *services.PermissionsService
-	profileService       *services.ProfileService
-	userRepository       *repositories.UserRepository
-	profileRepository    *repositories.ProfileRepository
-	userStore            *utils.UserDataStore
+	config                   *config.Config
+	auditLogService          *services.AuditLogService
+	messageSenderService     *services.MessageSenderService
+	permissionsService       *services.PermissionsService
+	profileService           *services.ProfileService
+	scoreService             *services.ScoreService
+	thanksService            *services.ThanksService
+	moderationActionsService *services.ModerationActionsService
+	userRepository           repositories.UserRepository
+	profileRepository        repositories.ProfileRepository
+	userStore                *utils.UserDataStore
 }
 
 func NewAdminProfilesHandler(
 	config *config.Config,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 	profileService *services.ProfileService,
-	userRepository *repositories.UserRepository,
-	profileRepository *repositories.ProfileRepository,
+	scoreService *services.ScoreService,
+	thanksService *services.ThanksService,
+	moderationActionsService *services.ModerationActionsService,
+	userRepository repositories.UserRepository,
+	profileRepository repositories.ProfileRepository,
 ) ext.Handler {
 	h := &adminProfilesHandler{
-		config:               config,
-		messageSenderService: messageSenderService,
-		permissionsService:   permissionsService,
-		profileService:       profileService,
-		userRepository:       userRepository,
-		profileRepository:    profileRepository,
-		userStore:            utils.NewUserDataStore(),
+		config:                   config,
+		auditLogService:          auditLogService,
+		messageSenderService:     messageSenderService,
+		permissionsService:       permissionsService,
+		profileService:           profileService,
+		scoreService:             scoreService,
+		thanksService:            thanksService,
+		moderationActionsService: moderationActionsService,
+		userRepository:           userRepository,
+		profileRepository:        profileRepository,
+		userStore:                utils.NewUserDataStore(),
 	}
 
 	return handlers.NewConversation(
@@ -134,6 +149,7 @@ func NewAdminProfilesHandler(
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditLastnameCallback), h.handleEditFieldCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditUsernameCallback), h.handleEditFieldCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditCoffeeBanCallback), h.handleEditFieldCallback),
+				handlers.NewCallback(callbackquery.Prefix(constants.AdminProfilesEditFieldPrefix), h.handleEditFieldCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditMenuCallback), h.handleEditMenuCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesPublishCallback), h.handlePublishCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesPublishNoPreviewCallback), h.handlePublishNoPreviewCallback),
@@ -160,6 +176,11 @@ func NewAdminProfilesHandler(
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditMenuCallback), h.handleEditMenuCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesCancelCallback), h.handleCancelCallback),
 			},
+			adminProfilesStateAwaitOptionalField: {
+				handlers.NewMessage(message.Text, h.handleOptionalFieldInput),
+				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditMenuCallback), h.handleEditMenuCallback),
+				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesCancelCallback), h.handleCancelCallback),
+			},
 			adminProfilesStateAwaitCoffeeBan: {
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesToggleCoffeeBanCallback), h.handleToggleCoffeeBanCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.AdminProfilesEditMenuCallback), h.handleEditMenuCallback),
@@ -183,9 +204,9 @@ func NewAdminProfilesHandler(
 func (h *adminProfilesHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.AdminProfilesCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageProfiles, constants.AdminProfilesCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -371,6 +392,11 @@ func (h *adminProfilesHandler) handleCreateByTelegramIDInput(b *gotgbot.Bot, ctx
 			return fmt.Errorf("%s: failed to create user in handleUserIDInput: %w", utils.GetCurrentTypeName(), err)
 		}
 
+		h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionCreate, constants.AuditEntityUser, userID,
+			nil,
+			map[string]interface{}{"tg_id": telegramID},
+		)
+
 		dbUser, err = h.userRepository.GetByID(userID)
 		if err != nil {
 			return fmt.Errorf("%s: failed to get created user in handleUserIDInput: %w", utils.GetCurrentTypeName(), err)
@@ -703,6 +729,16 @@ func (h *adminProfilesHandler) handleSearchByFullNameInput(b *gotgbot.Bot, ctx *
 // Shows the profile edit menu
 func (h *adminProfilesHandler) showProfileEditMenu(b *gotgbot.Bot, msg *gotgbot.Message, userId int64, user *repositories.User, profile *repositories.Profile) error {
 	profileText := fmt.Sprintf("<b>%s</b>\n\n%s", adminProfilesMenuEditHeader, formatters.FormatProfileManagerView(user, profile, user.HasCoffeeBan, h.config))
+	if thanksStats, err := h.thanksService.GetStats(user.ID); err == nil {
+		profileText += formatters.FormatThanksStatsForAdmin(thanksStats)
+	} else {
+		log.Printf("%s: Error during thanks stats retrieval: %v", utils.GetCurrentTypeName(), err)
+	}
+	if moderationHistory, err := h.moderationActionsService.GetHistory(user.ID, constants.ModerationHistoryLimit); err == nil {
+		profileText += formatters.FormatModerationHistoryForAdmin(moderationHistory)
+	} else {
+		log.Printf("%s: Error during moderation history retrieval: %v", utils.GetCurrentTypeName(), err)
+	}
 
 	editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
 		msg.Chat.Id,
@@ -790,7 +826,18 @@ func (h *adminProfilesHandler) handleEditFieldCallback(b *gotgbot.Bot, ctx *ext.
 			oldField = "Текущее значение: ✅ Разрешено"
 		}
 	default:
-		return fmt.Errorf("%s: unknown callback data: %s", utils.GetCurrentTypeName(), data)
+		field, ok := strings.CutPrefix(data, constants.AdminProfilesEditFieldPrefix)
+		profileField := constants.ProfileField(field)
+		if !ok || !slices.Contains(constants.AllProfileFields, profileField) {
+			return fmt.Errorf("%s: unknown callback data: %s", utils.GetCurrentTypeName(), data)
+		}
+		callToAction = formatters.FormatProfileFieldPrompt(profileField)
+		menuHeader = fmt.Sprintf("%s → %s", adminProfilesMenuEditHeader, formatters.ProfileFieldTitles[profileField])
+		nextState = adminProfilesStateAwaitOptionalField
+		oldField = "Текущее значение: отсутствует"
+		if value := formatters.FormatProfileFieldValue(profile, profileField); value != "" {
+			oldField = "Текущее значение: <pre>" + value + "</pre>"
+		}
 	}
 
 	// Store field being edited for use in input handlers
@@ -905,64 +952,59 @@ func (h *adminProfilesHandler) handlePublishProfile(b *gotgbot.Bot, ctx *ext.Con
 		return nil // Stay in current state
 	}
 
-	// Format profile text for publishing
-	publicMessageText := formatters.FormatPublicProfileForMessage(dbUser, profile, false)
-
-	var publishedMsg *gotgbot.Message
-	// Check if we need to update existing message or create a new one
-	if profile.PublishedMessageID.Valid {
-		// Try to edit existing message
-		_, _, err := b.EditMessageText(
-			publicMessageText,
-			&gotgbot.EditMessageTextOpts{
-				ChatId:    utils.ChatIdToFullChatId(h.config.SuperGroupChatID),
-				MessageId: profile.PublishedMessageID.Int64,
-				ParseMode: "HTML",
-				LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
-					IsDisabled: withoutPreview,
-				},
+	if excess := formatters.PublicProfileExcessLength(dbUser, profile); excess > 0 {
+		h.RemovePreviousMessage(b, &userId)
+		editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s</b>", adminProfilesMenuPublishHeader)+
+				"\n\n⚠️ Профиль пользователя не помещается в одно сообщение Telegram. "+
+				fmt.Sprintf("\n\nСократи биографию, навыки или ссылки на %d символов и опубликуй снова.", excess),
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ProfilesBackCancelButtons(constants.AdminProfilesEditMenuCallback),
 			})
-		// If editing fails, create a new message if the error is not about the message being exactly the same
-		if err != nil && !strings.Contains(err.Error(), "are exactly the same") {
-			publishedMsg, err = h.messageSenderService.SendHtmlWithReturnMessage(
-				utils.ChatIdToFullChatId(h.config.SuperGroupChatID),
-				publicMessageText,
-				&gotgbot.SendMessageOpts{
-					MessageThreadId: int64(h.config.IntroTopicID),
-					LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
-						IsDisabled: withoutPreview,
-					},
-				})
-			if err != nil {
-				return fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
-			}
-		} else {
-			// Message updated successfully, store the message ID for database update
-			messageID := profile.PublishedMessageID.Int64
-			publishedMsg = &gotgbot.Message{
-				MessageId: messageID,
-			}
+
+		if err != nil {
+			return fmt.Errorf("%s: failed to send message in handlePublishProfile: %w", utils.GetCurrentTypeName(), err)
 		}
-	} else {
-		// Create a new message
-		publishedMsg, err = h.messageSenderService.SendHtmlWithReturnMessage(
-			utils.ChatIdToFullChatId(h.config.SuperGroupChatID),
-			publicMessageText,
+
+		h.SavePreviousMessageInfo(userId, editedMsg)
+		return nil // Stay in current state
+	}
+
+	previousMessageID := profile.PublishedMessageID.Int64
+	publishedMessageID, err := h.profileService.PublishProfile(dbUser, profile, withoutPreview)
+	if errors.Is(err, services.ErrProfileFollowUpNotPublished) {
+		log.Printf("%s: %v", utils.GetCurrentTypeName(), err)
+		h.RemovePreviousMessage(b, &userId)
+		editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s</b>", adminProfilesMenuPublishHeader)+
+				fmt.Sprintf("\n\n⚠️ Фото с началом профиля опубликовано в канале \"<a href='%s'>Интро</a>\", но продолжение отправить не удалось. ",
+					utils.GetIntroMessageLink(h.config, publishedMessageID))+
+				"\n\nПопробуй опубликовать профиль ещё раз.",
 			&gotgbot.SendMessageOpts{
-				MessageThreadId: int64(h.config.IntroTopicID),
-				LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
-					IsDisabled: withoutPreview,
-				},
+				ReplyMarkup: buttons.ProfilesBackCancelButtons(constants.AdminProfilesEditMenuCallback),
 			})
+
 		if err != nil {
-			return fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
+			return fmt.Errorf("%s: failed to send message in handlePublishProfile: %w", utils.GetCurrentTypeName(), err)
 		}
-	}
 
-	// Update profile with the published message ID
-	err = h.profileRepository.UpdatePublishedMessageID(profile.ID, publishedMsg.MessageId)
+		h.SavePreviousMessageInfo(userId, editedMsg)
+		return nil // Stay in current state
+	}
 	if err != nil {
-		return fmt.Errorf("%s: failed to update published message ID: %w", utils.GetCurrentTypeName(), err)
+		return fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionPublish, constants.AuditEntityProfile, profile.ID,
+		map[string]interface{}{"published_message_id": previousMessageID},
+		map[string]interface{}{"published_message_id": publishedMessageID},
+	)
+
+	// Award karma for the first profile publication
+	if _, err := h.scoreService.Award(dbUser.ID, constants.ScoreReasonProfilePublished, "profile"); err != nil {
+		log.Printf("%s: Error during karma award for profile: %v", utils.GetCurrentTypeName(), err)
 	}
 
 	// Show success message
@@ -970,7 +1012,7 @@ func (h *adminProfilesHandler) handlePublishProfile(b *gotgbot.Bot, ctx *ext.Con
 	editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
 		msg.Chat.Id,
 		fmt.Sprintf("<b>%s</b>", adminProfilesMenuPublishHeader)+
-			fmt.Sprintf("\n\n✅ Профиль пользователя успешно опубликован в канале \"<a href='%s'>Интро</a>\"!", utils.GetIntroMessageLink(h.config, profile.PublishedMessageID.Int64)),
+			fmt.Sprintf("\n\n✅ Профиль пользователя успешно опубликован в канале \"<a href='%s'>Интро</a>\"!", utils.GetIntroMessageLink(h.config, publishedMessageID)),
 		&gotgbot.SendMessageOpts{
 			ReplyMarkup: buttons.ProfilesBackStartCancelButtons(constants.AdminProfilesEditMenuCallback),
 		})
@@ -1024,14 +1066,78 @@ func (h *adminProfilesHandler) handleBioInput(b *gotgbot.Bot, ctx *ext.Context)
 	}
 	profileID := profileIDVal.(int)
 
+	profile, err := h.profileRepository.GetByID(profileID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get profile before bio update: %w", utils.GetCurrentTypeName(), err)
+	}
+
 	// Save the bio
-	err := h.profileRepository.Update(profileID, map[string]interface{}{
+	err = h.profileRepository.Update(profileID, map[string]interface{}{
 		"bio": bio,
 	})
 	if err != nil {
 		return fmt.Errorf("%s: failed to update bio: %w", utils.GetCurrentTypeName(), err)
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityProfile, profileID,
+		map[string]interface{}{"bio": profile.Bio},
+		map[string]interface{}{"bio": bio},
+	)
+
+	return h.returnToProfileView(b, ctx)
+}
+
+// Handle input of an optional profile field, like the location or the skills
+func (h *adminProfilesHandler) handleOptionalFieldInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	userId := ctx.EffectiveUser.Id
+
+	fieldVal, ok := h.userStore.Get(userId, adminProfilesCtxDataKeyField)
+	if !ok {
+		return fmt.Errorf("%s: edited field not found in user store", utils.GetCurrentTypeName())
+	}
+	field := constants.ProfileField(strings.TrimPrefix(fieldVal.(string), constants.AdminProfilesEditFieldPrefix))
+
+	profileIDVal, ok := h.userStore.Get(userId, adminProfilesCtxDataKeyProfileID)
+	if !ok {
+		return fmt.Errorf("%s: profile ID not found in user store", utils.GetCurrentTypeName())
+	}
+	profileID := profileIDVal.(int)
+
+	profile, err := h.profileRepository.GetByID(profileID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get profile before %s update: %w", utils.GetCurrentTypeName(), field, err)
+	}
+
+	err = h.profileService.UpdateProfileField(profileID, field, msg.Text)
+	if errors.Is(err, services.ErrInvalidProfileFieldValue) {
+		h.RemovePreviousMessage(b, &userId)
+		b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+		errMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s → %s</b>", adminProfilesMenuEditHeader, formatters.ProfileFieldTitles[field])+
+				"\n\n⚠️ Не получилось разобрать ответ, проверь формат и пришли снова."+
+				fmt.Sprintf("\n\n%s", formatters.FormatProfileFieldPrompt(field)),
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ProfilesBackCancelButtons(constants.AdminProfilesEditMenuCallback),
+			})
+
+		h.SavePreviousMessageInfo(userId, errMsg)
+		return nil // Stay in current state
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to update %s: %w", utils.GetCurrentTypeName(), field, err)
+	}
+
+	updated, err := h.profileRepository.GetByID(profileID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get profile after %s update: %w", utils.GetCurrentTypeName(), field, err)
+	}
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityProfile, profileID,
+		map[string]interface{}{string(field): formatters.FormatProfileFieldValue(profile, field)},
+		map[string]interface{}{string(field): formatters.FormatProfileFieldValue(updated, field)},
+	)
+
 	return h.returnToProfileView(b, ctx)
 }
 
@@ -1063,14 +1169,24 @@ func (h *adminProfilesHandler) handleFirstnameInput(b *gotgbot.Bot, ctx *ext.Con
 	}
 	dbUserID := userIDVal.(int)
 
+	dbUser, err := h.userRepository.GetByID(dbUserID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user before firstname update: %w", utils.GetCurrentTypeName(), err)
+	}
+
 	// Save the firstname
-	err := h.userRepository.Update(dbUserID, map[string]interface{}{
+	err = h.userRepository.Update(dbUserID, map[string]interface{}{
 		"firstname": firstname,
 	})
 	if err != nil {
 		return fmt.Errorf("%s: failed to update firstname: %w", utils.GetCurrentTypeName(), err)
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityUser, dbUserID,
+		map[string]interface{}{"firstname": dbUser.Firstname},
+		map[string]interface{}{"firstname": firstname},
+	)
+
 	return h.returnToProfileView(b, ctx)
 }
 
@@ -1102,14 +1218,24 @@ func (h *adminProfilesHandler) handleLastnameInput(b *gotgbot.Bot, ctx *ext.Cont
 	}
 	dbUserID := userIDVal.(int)
 
+	dbUser, err := h.userRepository.GetByID(dbUserID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user before lastname update: %w", utils.GetCurrentTypeName(), err)
+	}
+
 	// Save the lastname
-	err := h.userRepository.Update(dbUserID, map[string]interface{}{
+	err = h.userRepository.Update(dbUserID, map[string]interface{}{
 		"lastname": lastname,
 	})
 	if err != nil {
 		return fmt.Errorf("%s: failed to update lastname: %w", utils.GetCurrentTypeName(), err)
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityUser, dbUserID,
+		map[string]interface{}{"lastname": dbUser.Lastname},
+		map[string]interface{}{"lastname": lastname},
+	)
+
 	return h.returnToProfileView(b, ctx)
 }
 
@@ -1146,14 +1272,24 @@ func (h *adminProfilesHandler) handleUsernameInput(b *gotgbot.Bot, ctx *ext.Cont
 	}
 	dbUserID := userIDVal.(int)
 
+	dbUser, err := h.userRepository.GetByID(dbUserID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user before username update: %w", utils.GetCurrentTypeName(), err)
+	}
+
 	// Save the username
-	err := h.userRepository.Update(dbUserID, map[string]interface{}{
+	err = h.userRepository.Update(dbUserID, map[string]interface{}{
 		"tg_username": username,
 	})
 	if err != nil {
 		return fmt.Errorf("%s: failed to update username: %w", utils.GetCurrentTypeName(), err)
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityUser, dbUserID,
+		map[string]interface{}{"tg_username": dbUser.TgUsername},
+		map[string]interface{}{"tg_username": username},
+	)
+
 	return h.returnToProfileView(b, ctx)
 }
 
@@ -1233,6 +1369,11 @@ func (h *adminProfilesHandler) handleToggleCoffeeBanCallback(b *gotgbot.Bot, ctx
 		return fmt.Errorf("%s: failed to update coffee ban status: %w", utils.GetCurrentTypeName(), err)
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityUser, dbUserID,
+		map[string]interface{}{"has_coffee_ban": dbUser.HasCoffeeBan},
+		map[string]interface{}{"has_coffee_ban": newStatus},
+	)
+
 	// Update the message with new buttons
 	h.RemovePreviousMessage(b, &userId)
 
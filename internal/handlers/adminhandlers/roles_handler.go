@@ -0,0 +1,177 @@
+package adminhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+const (
+	rolesActionGrant  = "grant"
+	rolesActionRevoke = "revoke"
+)
+
+type rolesHandler struct {
+	config               *config.Config
+	userRepository       repositories.UserRepository
+	userRoleRepository   repositories.UserRoleRepository
+	auditLogService      *services.AuditLogService
+	messageSenderService *services.MessageSenderService
+	permissionsService   *services.PermissionsService
+}
+
+func NewRolesHandler(
+	config *config.Config,
+	userRepository repositories.UserRepository,
+	userRoleRepository repositories.UserRoleRepository,
+	auditLogService *services.AuditLogService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &rolesHandler{
+		config:               config,
+		userRepository:       userRepository,
+		userRoleRepository:   userRoleRepository,
+		auditLogService:      auditLogService,
+		messageSenderService: messageSenderService,
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewCommand(constants.RolesCommand, h.handleCommand)
+}
+
+// handleCommand shows, grants and revokes roles
+func (h *rolesHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageRoles, constants.RolesCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.RolesCommand,
+		)
+		return nil
+	}
+
+	args := strings.Fields(msg.Text)[1:]
+	switch {
+	case len(args) == 0:
+		roles, err := h.userRoleRepository.GetAll()
+		if err != nil {
+			h.messageSenderService.Reply(msg, "Ошибка при получении списка ролей.", nil)
+			return fmt.Errorf("%s: failed to get roles: %w", utils.GetCurrentTypeName(), err)
+		}
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatRolesList(roles), nil)
+
+	case len(args) == 1:
+		user, ok := h.resolveUser(msg, args[0])
+		if !ok {
+			return nil
+		}
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatUserRoles(user, h.permissionsService.GetRoles(user.TgID)), nil)
+
+	case len(args) == 3 && (args[0] == rolesActionGrant || args[0] == rolesActionRevoke):
+		return h.changeRole(ctx, args[0], args[1], constants.Role(args[2]))
+
+	default:
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatRolesUsage(), nil)
+	}
+
+	return nil
+}
+
+// changeRole grants or revokes the role of the referenced user
+func (h *rolesHandler) changeRole(ctx *ext.Context, action string, reference string, role constants.Role) error {
+	msg := ctx.EffectiveMessage
+
+	if !slices.Contains(constants.AllRoles, role) {
+		h.messageSenderService.ReplyHtml(msg, "Неизвестная роль.\n\n"+formatters.FormatRolesUsage(), nil)
+		return nil
+	}
+	if role == constants.RoleMember {
+		h.messageSenderService.Reply(msg, "Роль участника выдаётся автоматически всем участникам чата клуба.", nil)
+		return nil
+	}
+	if (role == constants.RoleOwner || role == constants.RoleAdmin) && !h.permissionsService.HasRole(ctx.EffectiveUser.Id, constants.RoleOwner) {
+		h.messageSenderService.Reply(msg, "Роли владельца и администратора может менять только владелец.", nil)
+		return nil
+	}
+
+	user, ok := h.resolveUser(msg, reference)
+	if !ok {
+		return nil
+	}
+
+	if action == rolesActionGrant {
+		granted, err := h.userRoleRepository.Grant(user.ID, role, ctx.EffectiveUser.Id)
+		if err != nil {
+			h.messageSenderService.Reply(msg, "Произошла ошибка при выдаче роли.", nil)
+			return fmt.Errorf("%s: failed to grant role: %w", utils.GetCurrentTypeName(), err)
+		}
+		if !granted {
+			h.messageSenderService.Reply(msg, "У участника уже есть эта роль.", nil)
+			return nil
+		}
+		h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionGrant, constants.AuditEntityUser, user.ID,
+			nil,
+			map[string]interface{}{"role": role},
+		)
+	} else {
+		err := h.userRoleRepository.Revoke(user.ID, role)
+		if err == sql.ErrNoRows {
+			h.messageSenderService.Reply(msg, "У участника нет этой роли, выданной через бота.", nil)
+			return nil
+		}
+		if err != nil {
+			h.messageSenderService.Reply(msg, "Произошла ошибка при снятии роли.", nil)
+			return fmt.Errorf("%s: failed to revoke role: %w", utils.GetCurrentTypeName(), err)
+		}
+		h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionRevoke, constants.AuditEntityUser, user.ID,
+			map[string]interface{}{"role": role},
+			nil,
+		)
+	}
+
+	log.Printf("%s: Admin %d used %s of role %s for user %d", utils.GetCurrentTypeName(), ctx.EffectiveUser.Id, action, role, user.TgID)
+
+	h.messageSenderService.ReplyHtml(msg, "✅ Готово.\n\n"+formatters.FormatUserRoles(user, h.permissionsService.GetRoles(user.TgID)), nil)
+	return nil
+}
+
+// resolveUser finds the user by @username or Telegram ID and replies if it's not found
+func (h *rolesHandler) resolveUser(msg *gotgbot.Message, reference string) (*repositories.User, bool) {
+	var user *repositories.User
+	var err error
+	if tgID, parseErr := strconv.ParseInt(reference, 10, 64); parseErr == nil {
+		user, err = h.userRepository.GetByTelegramID(tgID)
+	} else {
+		user, err = h.userRepository.GetByTelegramUsername(strings.TrimPrefix(reference, "@"))
+	}
+
+	if err == sql.ErrNoRows {
+		h.messageSenderService.Reply(msg, "Участник не найден. Участник должен хотя бы раз написать боту или в чат клуба.", nil)
+		return nil, false
+	}
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при поиске участника.", nil)
+		log.Printf("%s: Failed to resolve user %s: %v", utils.GetCurrentTypeName(), reference, err)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,259 @@
+package adminhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	scoreAdjustStateAskUser       = "score_adjust_state_ask_user"
+	scoreAdjustStateAskAdjustment = "score_adjust_state_ask_adjustment"
+
+	// Context data keys
+	scoreAdjustCtxDataKeyUserID            = "score_adjust_ctx_data_user_id"
+	scoreAdjustCtxDataKeyPreviousMessageID = "score_adjust_ctx_data_previous_message_id"
+	scoreAdjustCtxDataKeyPreviousChatID    = "score_adjust_ctx_data_previous_chat_id"
+
+	// Callback data
+	scoreAdjustCallbackConfirmCancel = "score_adjust_callback_confirm_cancel"
+)
+
+type scoreAdjustHandler struct {
+	config               *config.Config
+	userRepository       repositories.UserRepository
+	scoreService         *services.ScoreService
+	auditLogService      *services.AuditLogService
+	messageSenderService *services.MessageSenderService
+	userStore            *utils.UserDataStore
+	permissionsService   *services.PermissionsService
+}
+
+func NewScoreAdjustHandler(
+	config *config.Config,
+	userRepository repositories.UserRepository,
+	scoreService *services.ScoreService,
+	auditLogService *services.AuditLogService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &scoreAdjustHandler{
+		config:               config,
+		userRepository:       userRepository,
+		scoreService:         scoreService,
+		auditLogService:      auditLogService,
+		messageSenderService: messageSenderService,
+		userStore:            utils.NewUserDataStore(),
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.ScoreAdjustCommand, h.startScoreAdjust),
+		},
+		map[string][]ext.Handler{
+			scoreAdjustStateAskUser: {
+				handlers.NewMessage(message.Text, h.handleUser),
+				handlers.NewCallback(callbackquery.Equal(scoreAdjustCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+			scoreAdjustStateAskAdjustment: {
+				handlers.NewMessage(message.Text, h.handleAdjustment),
+				handlers.NewCallback(callbackquery.Equal(scoreAdjustCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// 1. startScoreAdjust is the entry point handler for the manual karma adjustment
+func (h *scoreAdjustHandler) startScoreAdjust(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageScore, constants.ScoreAdjustCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.ScoreAdjustCommand,
+		)
+		return handlers.EndConversation()
+	}
+
+	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+		msg,
+		fmt.Sprintf("Введи @username или Telegram ID участника, карму которого нужно изменить, либо /%s для отмены.", constants.CancelCommand),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(scoreAdjustCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(scoreAdjustStateAskUser)
+}
+
+// 2. handleUser finds the member and shows the current karma with its history
+func (h *scoreAdjustHandler) handleUser(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	input := strings.TrimSpace(msg.Text)
+
+	var user *repositories.User
+	var err error
+	if tgID, parseErr := strconv.ParseInt(input, 10, 64); parseErr == nil {
+		user, err = h.userRepository.GetByTelegramID(tgID)
+	} else {
+		user, err = h.userRepository.GetByTelegramUsername(strings.TrimPrefix(input, "@"))
+	}
+
+	if err == sql.ErrNoRows {
+		h.messageSenderService.Reply(msg, "Участник не найден. Попробуй ещё раз или используй кнопку для отмены.", nil)
+		return nil // Stay in the same state
+	}
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при поиске участника.", nil)
+		log.Printf("%s: Error during user search: %v", utils.GetCurrentTypeName(), err)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	rank, err := h.scoreService.GetRank(user.ID)
+	if err != nil {
+		log.Printf("%s: Error during rank retrieval: %v", utils.GetCurrentTypeName(), err)
+	}
+	history, err := h.scoreService.GetHistory(user.ID)
+	if err != nil {
+		log.Printf("%s: Error during score history retrieval: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	text := fmt.Sprintf("👤 <b>%s %s</b>\n\n", user.Firstname, user.Lastname) +
+		formatters.FormatScoreHistory(user, rank, history) +
+		"\nВведи изменение кармы и причину, например: <code>+10 помощь с организацией встречи</code> или <code>-5 спам</code>."
+
+	sentMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+		msg.Chat.Id,
+		text,
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(scoreAdjustCallbackConfirmCancel),
+		},
+	)
+
+	h.userStore.Set(ctx.EffectiveUser.Id, scoreAdjustCtxDataKeyUserID, user.ID)
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(scoreAdjustStateAskAdjustment)
+}
+
+// 3. handleAdjustment applies the adjustment entered by the admin
+func (h *scoreAdjustHandler) handleAdjustment(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	parts := strings.SplitN(strings.TrimSpace(msg.Text), " ", 2)
+	points, err := strconv.Atoi(parts[0])
+	if err != nil || points == 0 || len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+		h.messageSenderService.Reply(msg, "Неверный формат. Введи число и причину, например: +10 помощь с организацией встречи", nil)
+		return nil // Stay in the same state
+	}
+	comment := strings.TrimSpace(parts[1])
+
+	userIDVal, ok := h.userStore.Get(ctx.EffectiveUser.Id, scoreAdjustCtxDataKeyUserID)
+	if !ok {
+		h.messageSenderService.Reply(msg, "Произошла ошибка: участник не найден в контексте. Начни заново.", nil)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+	userID := userIDVal.(int)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	if err := h.scoreService.Adjust(userID, points, comment); err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при изменении кармы.", nil)
+		log.Printf("%s: Error during score adjustment: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	log.Printf("%s: Admin %d adjusted score of user %d by %d: %s",
+		utils.GetCurrentTypeName(), ctx.EffectiveUser.Id, userID, points, comment)
+
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionUpdate, constants.AuditEntityScore, userID,
+		nil,
+		map[string]interface{}{"points": points, "comment": comment},
+	)
+
+	h.messageSenderService.Reply(msg, fmt.Sprintf("✅ Карма изменена на %+d.", points), nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *scoreAdjustHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// 4. handleCancel handles the /cancel command
+func (h *scoreAdjustHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, "Изменение кармы отменено.", nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+func (h *scoreAdjustHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			scoreAdjustCtxDataKeyPreviousMessageID,
+			scoreAdjustCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *scoreAdjustHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	if sentMsg == nil {
+		return
+	}
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		scoreAdjustCtxDataKeyPreviousMessageID, scoreAdjustCtxDataKeyPreviousChatID)
+}
@@ -40,7 +40,8 @@ const (
 type showTopicsHandler struct {
 	config               *config.Config
 	topicRepository      *repositories.TopicRepository
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	auditLogService      *services.AuditLogService
 	messageSenderService *services.MessageSenderService
 	userStore            *utils.UserDataStore
 	permissionsService   *services.PermissionsService
@@ -49,7 +50,8 @@ type showTopicsHandler struct {
 func NewShowTopicsHandler(
 	config *config.Config,
 	topicRepository *repositories.TopicRepository,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
@@ -57,6 +59,7 @@ func NewShowTopicsHandler(
 		config:               config,
 		topicRepository:      topicRepository,
 		eventRepository:      eventRepository,
+		auditLogService:      auditLogService,
 		messageSenderService: messageSenderService,
 		userStore:            utils.NewUserDataStore(),
 		permissionsService:   permissionsService,
@@ -86,9 +89,9 @@ func NewShowTopicsHandler(
 func (h *showTopicsHandler) startShowTopics(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.ShowTopicsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageEvents, constants.ShowTopicsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -253,6 +256,8 @@ func (h *showTopicsHandler) handleTopicDeletion(b *gotgbot.Bot, ctx *ext.Context
 		return handlers.EndConversation()
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionDelete, constants.AuditEntityTopic, topicID, topic, nil)
+
 	// Confirmation message
 	h.messageSenderService.Reply(msg, fmt.Sprintf("✅ Тема с ID %d успешно удалена.", topicID), nil)
 
@@ -31,6 +31,7 @@ const (
 
 type tryCreateCoffeePoolHandler struct {
 	config               *config.Config
+	auditLogService      *services.AuditLogService
 	messageSenderService *services.MessageSenderService
 	permissionsService   *services.PermissionsService
 	randomCoffeeService  *services.RandomCoffeeService
@@ -39,12 +40,14 @@ type tryCreateCoffeePoolHandler struct {
 
 func NewTryCreateCoffeePoolHandler(
 	config *config.Config,
+	auditLogService *services.AuditLogService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 	randomCoffeeService *services.RandomCoffeeService,
 ) ext.Handler {
 	h := &tryCreateCoffeePoolHandler{
 		config:               config,
+		auditLogService:      auditLogService,
 		messageSenderService: messageSenderService,
 		permissionsService:   permissionsService,
 		randomCoffeeService:  randomCoffeeService,
@@ -78,9 +81,9 @@ func NewTryCreateCoffeePoolHandler(
 func (h *tryCreateCoffeePoolHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.TryCreateCoffeePoolCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageRandomCoffee, constants.TryCreateCoffeePoolCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -154,6 +157,8 @@ func (h *tryCreateCoffeePoolHandler) handleConfirmCallback(b *gotgbot.Bot, ctx *
 		return fmt.Errorf("%s: failed to create coffee poll: %w", utils.GetCurrentTypeName(), err)
 	}
 
+	h.auditLogService.Record(ctx.EffectiveUser, constants.AuditActionStart, constants.AuditEntityRandomCoffeePoll, "", nil, nil)
+
 	// Update message with success
 	_, _, err = b.EditMessageText(
 		fmt.Sprintf("<b>%s</b>", tryCreateCoffeePoolMenuHeader)+
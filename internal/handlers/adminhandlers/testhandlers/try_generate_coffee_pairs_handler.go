@@ -32,10 +32,11 @@ const (
 type tryGenerateCoffeePairsHandler struct {
 	config              *config.Config
 	permissions         *services.PermissionsService
+	auditLog            *services.AuditLogService
 	sender              *services.MessageSenderService
-	pollRepo            *repositories.RandomCoffeePollRepository
-	participantRepo     *repositories.RandomCoffeeParticipantRepository
-	profileRepo         *repositories.ProfileRepository
+	pollRepo            repositories.RandomCoffeePollRepository
+	participantRepo     repositories.RandomCoffeeParticipantRepository
+	profileRepo         repositories.ProfileRepository
 	randomCoffeeService *services.RandomCoffeeService
 	userStore           *utils.UserDataStore
 }
@@ -43,15 +44,17 @@ type tryGenerateCoffeePairsHandler struct {
 func NewTryGenerateCoffeePairsHandler(
 	config *config.Config,
 	permissions *services.PermissionsService,
+	auditLog *services.AuditLogService,
 	sender *services.MessageSenderService,
-	pollRepo *repositories.RandomCoffeePollRepository,
-	participantRepo *repositories.RandomCoffeeParticipantRepository,
-	profileRepo *repositories.ProfileRepository,
+	pollRepo repositories.RandomCoffeePollRepository,
+	participantRepo repositories.RandomCoffeeParticipantRepository,
+	profileRepo repositories.ProfileRepository,
 	randomCoffeeService *services.RandomCoffeeService,
 ) ext.Handler {
 	h := &tryGenerateCoffeePairsHandler{
 		config:              config,
 		permissions:         permissions,
+		auditLog:            auditLog,
 		sender:              sender,
 		pollRepo:            pollRepo,
 		participantRepo:     participantRepo,
@@ -88,9 +91,9 @@ func NewTryGenerateCoffeePairsHandler(
 func (h *tryGenerateCoffeePairsHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissions.CheckAdminAndPrivateChat(msg, constants.TryGenerateCoffeePairsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissions.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageRandomCoffee, constants.TryGenerateCoffeePairsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -189,6 +192,8 @@ func (h *tryGenerateCoffeePairsHandler) handleConfirmCallback(b *gotgbot.Bot, ct
 		return nil // Stay in the same state to allow retry
 	}
 
+	h.auditLog.Record(ctx.EffectiveUser, constants.AuditActionCreate, constants.AuditEntityRandomCoffeePairs, "", nil, nil)
+
 	h.RemovePreviousMessage(b, &userId)
 
 	// Send success message
@@ -74,9 +74,9 @@ func NewTrySummarizeHandler(
 func (h *trySummarizeHandler) startSummarizeConversation(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
 
-	// Check if user has admin permissions and is in a private chat
-	if !h.permissionsService.CheckAdminAndPrivateChat(msg, constants.ShowTopicsCommand) {
-		log.Printf("%s: User %d (%s) tried to use /%s without admin permissions.",
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageBot, constants.ShowTopicsCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
 			utils.GetCurrentTypeName(),
 			ctx.EffectiveUser.Id,
 			ctx.EffectiveUser.Username,
@@ -87,12 +87,6 @@ func (h *trySummarizeHandler) startSummarizeConversation(b *gotgbot.Bot, ctx *ex
 
 	log.Printf("%s: User %d initiated summarization", utils.GetCurrentTypeName(), msg.From.Id)
 
-	// Check if the user is an admin
-	if !utils.IsUserAdminOrCreator(b, msg.From.Id, h.config) {
-		msg.Reply(b, "Эта команда доступна только администраторам.", nil)
-		return handlers.EndConversation()
-	}
-
 	// Ask user to confirm with inline keyboard
 	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
 		msg,
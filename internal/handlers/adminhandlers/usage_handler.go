@@ -0,0 +1,77 @@
+package adminhandlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type usageHandler struct {
+	config               *config.Config
+	llmUsageService      *services.LLMUsageService
+	messageSenderService *services.MessageSenderService
+	permissionsService   *services.PermissionsService
+}
+
+func NewUsageHandler(
+	config *config.Config,
+	llmUsageService *services.LLMUsageService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &usageHandler{
+		config:               config,
+		llmUsageService:      llmUsageService,
+		messageSenderService: messageSenderService,
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewCommand(constants.UsageCommand, h.handleCommand)
+}
+
+// handleCommand shows the LLM usage by feature and user since the start of the month or for the last N days
+func (h *usageHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityViewUsage, constants.UsageCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.UsageCommand,
+		)
+		return nil
+	}
+
+	args := strings.Fields(msg.Text)[1:]
+	days := 0
+	if len(args) > 0 {
+		parsedDays, err := strconv.Atoi(args[0])
+		if err != nil || len(args) > 1 || parsedDays < 1 || parsedDays > constants.LLMUsageMaxDays {
+			h.messageSenderService.ReplyHtml(msg, formatters.FormatLLMUsageUsage(), nil)
+			return nil
+		}
+		days = parsedDays
+	}
+
+	report, err := h.llmUsageService.GetReport(days)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Ошибка при получении статистики использования ИИ.", nil)
+		return fmt.Errorf("%s: failed to get LLM usage report: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.messageSenderService.ReplyHtml(msg, formatters.FormatLLMUsageReport(report), nil)
+	return nil
+}
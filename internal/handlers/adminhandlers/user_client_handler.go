@@ -0,0 +1,478 @@
+package adminhandlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	userClientStateChooseMethod  = "user_client_state_choose_method"
+	userClientStateWaitForCode   = "user_client_state_wait_for_code"
+	userClientStateWaitForLogin  = "user_client_state_wait_for_login"
+	userClientStateConfirmLogout = "user_client_state_confirm_logout"
+
+	// Context data keys
+	userClientCtxDataKeyPreviousMessageID = "user_client_ctx_data_previous_message_id"
+	userClientCtxDataKeyPreviousChatID    = "user_client_ctx_data_previous_chat_id"
+)
+
+type userClientHandler struct {
+	config               *config.Config
+	tgUserClient         clients.TgUserClientLogin
+	messageSenderService *services.MessageSenderService
+	userStore            *utils.UserDataStore
+	permissionsService   *services.PermissionsService
+}
+
+func NewUserClientHandler(
+	config *config.Config,
+	tgUserClient clients.TgUserClientLogin,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &userClientHandler{
+		config:               config,
+		tgUserClient:         tgUserClient,
+		messageSenderService: messageSenderService,
+		userStore:            utils.NewUserDataStore(),
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.UserClientCommand, h.handleCommand),
+		},
+		map[string][]ext.Handler{
+			userClientStateChooseMethod: {
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientLoginCodeCallback), h.handleLoginWithCode),
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientLoginQRCallback), h.handleLoginWithQR),
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientCancelCallback), h.handleCallbackCancel),
+			},
+			userClientStateWaitForCode: {
+				handlers.NewMessage(message.Text, h.processCode),
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientResendCodeCallback), h.handleResendCode),
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientCancelCallback), h.handleCallbackCancel),
+			},
+			userClientStateWaitForLogin: {
+				handlers.NewMessage(message.Text, h.processPassword),
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientCancelCallback), h.handleCallbackCancel),
+			},
+			userClientStateConfirmLogout: {
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientConfirmLogoutCallback), h.handleConfirmLogout),
+				handlers.NewCallback(callbackquery.Equal(constants.UserClientCancelCallback), h.handleCallbackCancel),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// handleCommand shows the session status or starts the login or logout
+func (h *userClientHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Check if user has the capability and is in a private chat
+	if !h.permissionsService.CheckCapabilityAndPrivateChat(msg, constants.CapabilityManageBot, constants.UserClientCommand) {
+		log.Printf("%s: User %d (%s) tried to use /%s without the capability.",
+			utils.GetCurrentTypeName(),
+			ctx.EffectiveUser.Id,
+			ctx.EffectiveUser.Username,
+			constants.UserClientCommand,
+		)
+		return handlers.EndConversation()
+	}
+
+	args := strings.Fields(msg.Text)[1:]
+	subcommand := constants.UserClientStatusSubcommand
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	switch subcommand {
+	case constants.UserClientStatusSubcommand:
+		status := h.tgUserClient.GetStatus(context.Background())
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatUserClientStatus(status, h.config.ClubTimezone), nil)
+		return handlers.EndConversation()
+
+	case constants.UserClientLoginSubcommand:
+		sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+			msg,
+			"Как войти в аккаунт TG-клиента?\n\n"+
+				"📷 QR-код — отсканируй его в приложении Telegram этого аккаунта.\n"+
+				"🔢 Код из Telegram — придёт в приложение или по SMS на номер из настроек.",
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.UserClientLoginMethodButtons(),
+			},
+		)
+		h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+		return handlers.NextConversationState(userClientStateChooseMethod)
+
+	case constants.UserClientLogoutSubcommand:
+		sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+			msg,
+			"Завершить сессию TG-клиента? Саммаризация и поиск по чатам не будут работать, пока ты не войдёшь снова.",
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ConfirmAndCancelButton(constants.UserClientConfirmLogoutCallback, constants.UserClientCancelCallback),
+			},
+		)
+		h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+		return handlers.NextConversationState(userClientStateConfirmLogout)
+
+	default:
+		h.messageSenderService.ReplyHtml(msg, formatters.FormatUserClientUsage(), nil)
+		return handlers.EndConversation()
+	}
+}
+
+// handleLoginWithCode sends the login code to the configured phone number
+func (h *userClientHandler) handleLoginWithCode(b *gotgbot.Bot, ctx *ext.Context) error {
+	_, _ = ctx.Update.CallbackQuery.Answer(b, nil)
+	msg := ctx.EffectiveMessage
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	code, err := h.tgUserClient.SendLoginCode(context.Background())
+	if err != nil {
+		h.replyLoginError(msg, err)
+		return handlers.EndConversation()
+	}
+
+	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+		msg,
+		formatLoginCodeSent(code, h.config.ClubTimezone),
+		&gotgbot.SendMessageOpts{
+			ParseMode:   "HTML",
+			ReplyMarkup: buttons.UserClientResendCodeButtons(),
+		},
+	)
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(userClientStateWaitForCode)
+}
+
+// handleResendCode requests a new login code
+func (h *userClientHandler) handleResendCode(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	msg := ctx.EffectiveMessage
+
+	code, err := h.tgUserClient.ResendLoginCode(context.Background())
+	if errors.Is(err, clients.ErrLoginResendTooEarly) {
+		_, _ = cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text:      "Новый код пока нельзя запросить, подожди немного.",
+			ShowAlert: true,
+		})
+		return nil
+	}
+	_, _ = cb.Answer(b, nil)
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	if err != nil {
+		h.replyLoginError(msg, err)
+		return handlers.EndConversation()
+	}
+
+	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+		msg,
+		formatLoginCodeSent(code, h.config.ClubTimezone),
+		&gotgbot.SendMessageOpts{
+			ParseMode:   "HTML",
+			ReplyMarkup: buttons.UserClientResendCodeButtons(),
+		},
+	)
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return nil
+}
+
+// processCode signs in with the code, it is entered reversed because Telegram
+// invalidates login codes that are sent in messages as is
+func (h *userClientHandler) processCode(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	revertedCode := strings.TrimSpace(msg.Text)
+	if revertedCode == "" {
+		h.messageSenderService.Reply(msg, "Код не может быть пустым. Введи код или используй кнопку для отмены.", nil)
+		return nil // Stay in the same state
+	}
+
+	err := h.tgUserClient.SignInWithCode(context.Background(), reverseString(revertedCode))
+	switch {
+	case errors.Is(err, clients.ErrLoginCodeInvalid):
+		h.messageSenderService.Reply(msg, "Неверный код. Проверь, что он введён задом наперёд, и попробуй ещё раз.", nil)
+		return nil // Stay in the same state
+	case errors.Is(err, clients.ErrLoginPasswordNeeded):
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.askPassword(msg.Chat.Id, ctx.EffectiveUser.Id)
+		return handlers.NextConversationState(userClientStateWaitForLogin)
+	case err != nil:
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.replyLoginError(msg, err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, "✅ Вход выполнен, TG-клиент снова работает.", nil)
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+	return handlers.EndConversation()
+}
+
+// handleLoginWithQR starts the QR login in the background, the QR codes are sent until one is scanned
+func (h *userClientHandler) handleLoginWithQR(b *gotgbot.Bot, ctx *ext.Context) error {
+	_, _ = ctx.Update.CallbackQuery.Answer(b, nil)
+	msg := ctx.EffectiveMessage
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	sentMsg, _ := h.messageSenderService.ReplyWithReturnMessage(
+		msg,
+		"Открой Telegram аккаунта TG-клиента: Настройки → Устройства → Подключить устройство, и отсканируй QR-код. "+
+			"Если включена двухэтапная аутентификация, я попрошу пароль.",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(constants.UserClientCancelCallback),
+		},
+	)
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+
+	go h.runQRLogin(b, msg.Chat.Id, ctx.EffectiveUser.Id)
+	return handlers.NextConversationState(userClientStateWaitForLogin)
+}
+
+// runQRLogin shows the QR codes and reports the result of the login
+func (h *userClientHandler) runQRLogin(b *gotgbot.Bot, chatID int64, userID int64) {
+	var qrMessage *gotgbot.Message
+	deleteQRMessage := func() {
+		if qrMessage != nil {
+			_, _ = b.DeleteMessage(chatID, qrMessage.MessageId, nil)
+			qrMessage = nil
+		}
+	}
+
+	err := h.tgUserClient.LoginWithQR(context.Background(), func(ctx context.Context, qrCode *clients.LoginQRCode) error {
+		deleteQRMessage()
+
+		sentMsg, err := h.messageSenderService.SendPhotoWithReturnMessage(
+			chatID,
+			gotgbot.InputFileByReader("login_qr.png", bytes.NewReader(qrCode.PNG)),
+			&gotgbot.SendPhotoOpts{
+				Caption: fmt.Sprintf("QR-код для входа действует до %s, потом я пришлю новый.",
+					qrCode.ExpiresAt.In(h.config.ClubTimezone).Format("15:04:05")),
+			},
+		)
+		qrMessage = sentMsg
+		return err
+	})
+	deleteQRMessage()
+
+	switch {
+	case err == nil:
+		h.MessageRemoveInlineKeyboard(b, &userID)
+		h.userStore.Clear(userID)
+		h.messageSenderService.Send(chatID, "✅ Вход выполнен, TG-клиент снова работает.", nil)
+	case errors.Is(err, clients.ErrLoginPasswordNeeded):
+		h.askPassword(chatID, userID)
+	case errors.Is(err, context.Canceled):
+		// The login was cancelled by the admin
+	case errors.Is(err, context.DeadlineExceeded):
+		h.MessageRemoveInlineKeyboard(b, &userID)
+		h.messageSenderService.Send(chatID, fmt.Sprintf(
+			"⌛ QR-код так и не отсканировали. Чтобы попробовать снова, используй /%s %s",
+			constants.UserClientCommand, constants.UserClientLoginSubcommand), nil)
+	default:
+		h.MessageRemoveInlineKeyboard(b, &userID)
+		h.messageSenderService.Send(chatID, "❌ Не удалось войти по QR-коду. Подробности в логах.", nil)
+		log.Printf("%s: QR login failed: %v", utils.GetCurrentTypeName(), err)
+	}
+}
+
+// processPassword signs in with the 2FA password. While the QR code is not scanned yet, it only reminds about it.
+func (h *userClientHandler) processPassword(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	switch h.tgUserClient.GetLoginStep() {
+	case clients.LoginStepQR:
+		h.messageSenderService.Reply(msg, "Сначала отсканируй QR-код в приложении Telegram.", nil)
+		return nil // Stay in the same state
+	case clients.LoginStepPassword:
+	default:
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.messageSenderService.Reply(msg, fmt.Sprintf(
+			"Вход уже завершён или отменён. Состояние сессии: /%s", constants.UserClientCommand), nil)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	// The password should not stay in the chat history
+	if _, err := msg.Delete(b, nil); err != nil {
+		log.Printf("%s: Failed to delete the password message: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	err := h.tgUserClient.SignInWithPassword(context.Background(), strings.TrimSpace(msg.Text))
+	switch {
+	case errors.Is(err, clients.ErrLoginPasswordInvalid):
+		h.messageSenderService.Send(msg.Chat.Id, "Неверный пароль, попробуй ещё раз.", nil)
+		return nil // Stay in the same state
+	case err != nil:
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.replyLoginError(msg, err)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Send(msg.Chat.Id, "✅ Вход выполнен, TG-клиент снова работает.", nil)
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+	return handlers.EndConversation()
+}
+
+// handleConfirmLogout terminates the session
+func (h *userClientHandler) handleConfirmLogout(b *gotgbot.Bot, ctx *ext.Context) error {
+	_, _ = ctx.Update.CallbackQuery.Answer(b, nil)
+	msg := ctx.EffectiveMessage
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	if err := h.tgUserClient.Logout(context.Background()); err != nil {
+		h.messageSenderService.Reply(msg, "❌ Не удалось завершить сессию. Подробности в логах.", nil)
+		return fmt.Errorf("%s: failed to log out: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	log.Printf("%s: User %d logged the user client out", utils.GetCurrentTypeName(), ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, fmt.Sprintf(
+		"✅ Сессия завершена. Чтобы войти снова, используй /%s %s",
+		constants.UserClientCommand, constants.UserClientLoginSubcommand), nil)
+	return handlers.EndConversation()
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *userClientHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// handleCancel handles the /cancel command, a login in progress is cancelled too
+func (h *userClientHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.tgUserClient.CancelLogin()
+	h.messageSenderService.Reply(msg, "Операция отменена.", nil)
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+// askPassword asks for the 2FA password of the account
+func (h *userClientHandler) askPassword(chatID int64, userID int64) {
+	sentMsg, _ := h.messageSenderService.SendWithReturnMessage(
+		chatID,
+		"🔐 У аккаунта включена двухэтапная аутентификация. Отправь пароль, я сразу удалю сообщение с ним.",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(constants.UserClientCancelCallback),
+		},
+	)
+	if sentMsg != nil {
+		h.SavePreviousMessageInfo(userID, sentMsg)
+	}
+}
+
+// replyLoginError explains why the login or logout failed
+func (h *userClientHandler) replyLoginError(msg *gotgbot.Message, err error) {
+	var text string
+	switch {
+	case errors.Is(err, clients.ErrLoginAlreadyAuthorized):
+		text = fmt.Sprintf("TG-клиент уже авторизован. Чтобы войти заново, сначала используй /%s %s",
+			constants.UserClientCommand, constants.UserClientLogoutSubcommand)
+	case errors.Is(err, clients.ErrLoginInProgress):
+		text = "Вход уже выполняется. Заверши его или отмени через /" + constants.CancelCommand
+	case errors.Is(err, clients.ErrLoginPhoneNotConfigured):
+		text = "Номер телефона TG-клиента не настроен, войди по QR-коду."
+	case errors.Is(err, clients.ErrLoginExpired), errors.Is(err, clients.ErrLoginNotStarted):
+		text = fmt.Sprintf("⌛ Время на вход истекло. Чтобы попробовать снова, используй /%s %s",
+			constants.UserClientCommand, constants.UserClientLoginSubcommand)
+	default:
+		text = "❌ Не удалось войти. Подробности в логах."
+		log.Printf("%s: Login failed: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	h.messageSenderService.Reply(msg, text, nil)
+}
+
+func (h *userClientHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			userClientCtxDataKeyPreviousMessageID,
+			userClientCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *userClientHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	if sentMsg == nil {
+		return
+	}
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		userClientCtxDataKeyPreviousMessageID, userClientCtxDataKeyPreviousChatID)
+}
+
+// formatLoginCodeSent tells where the login code was sent and how to enter it
+func formatLoginCodeSent(code *clients.LoginCode, loc *time.Location) string {
+	destination := "в Telegram"
+	switch code.Type {
+	case "app":
+		destination = "в приложение Telegram"
+	case "sms":
+		destination = "по SMS"
+	case "call":
+		destination = "звонком"
+	}
+
+	return fmt.Sprintf(
+		"Код отправлен %s. Введи его <b>задом наперёд</b> (например, 12345 → 54321), иначе Telegram его аннулирует. "+
+			"Код действует до %s.",
+		destination,
+		code.ExpiresAt.In(loc).Format("15:04"),
+	)
+}
+
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
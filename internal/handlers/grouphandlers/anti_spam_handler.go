@@ -0,0 +1,52 @@
+package grouphandlers
+
+import (
+	"context"
+	"fmt"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type AntiSpamHandler struct {
+	config          *config.Config
+	antiSpamService *services.AntiSpamService
+}
+
+func NewAntiSpamHandler(
+	config *config.Config,
+	antiSpamService *services.AntiSpamService,
+) ext.Handler {
+	h := &AntiSpamHandler{
+		config:          config,
+		antiSpamService: antiSpamService,
+	}
+	return handlers.NewMessage(h.check, h.handle)
+}
+
+func (h *AntiSpamHandler) check(msg *gotgbot.Message) bool {
+	if !h.config.AntiSpamEnabled || msg == nil || msg.From == nil {
+		return false
+	}
+
+	return msg.Chat.Id == utils.ChatIdToFullChatId(h.config.SuperGroupChatID)
+}
+
+func (h *AntiSpamHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	removed, err := h.antiSpamService.CheckFirstMessage(context.Background(), ctx.EffectiveMessage)
+	if err != nil {
+		return fmt.Errorf("%s: failed to check message for spam: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// Other handlers must not process the removed message
+	if removed {
+		return ext.EndGroups
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package grouphandlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+)
+
+type CaptchaAnswerHandler struct {
+	antiSpamService *services.AntiSpamService
+}
+
+func NewCaptchaAnswerHandler(antiSpamService *services.AntiSpamService) ext.Handler {
+	h := &CaptchaAnswerHandler{antiSpamService: antiSpamService}
+	return handlers.NewCallback(callbackquery.Prefix(constants.CaptchaAnswerCallbackPrefix), h.handleCallback)
+}
+
+func (h *CaptchaAnswerHandler) handleCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+
+	// Callback data format: captcha_answer_<userID>_<option>
+	userIDStr, optionStr, _ := strings.Cut(strings.TrimPrefix(cb.Data, constants.CaptchaAnswerCallbackPrefix), "_")
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		observability.Logger(ctx).Warn("Invalid callback data", "data", cb.Data, "error", err)
+		return h.answer(b, ctx, "Не удалось обработать нажатие.")
+	}
+	option, err := strconv.Atoi(optionStr)
+	if err != nil {
+		observability.Logger(ctx).Warn("Invalid callback data", "data", cb.Data, "error", err)
+		return h.answer(b, ctx, "Не удалось обработать нажатие.")
+	}
+
+	if cb.From.Id != userID {
+		return h.answer(b, ctx, "Эта проверка не для тебя 🙂")
+	}
+
+	if !h.antiSpamService.HasPendingCaptcha(userID) {
+		// The captcha may have been lost, the user who is still restricted by it gets a new one
+		if cb.Message == nil {
+			return h.answer(b, ctx, "Проверка уже завершена.")
+		}
+		rechallenged, err := h.antiSpamService.RechallengeRestrictedMember(cb.Message.GetChat().Id, cb.From, cb.Message.GetMessageId())
+		if err != nil {
+			_ = h.answer(b, ctx, "Произошла ошибка, попробуй позже.")
+			return fmt.Errorf("%s: failed to rechallenge restricted member: %w", utils.GetCurrentTypeName(), err)
+		}
+		if !rechallenged {
+			return h.answer(b, ctx, "Проверка уже завершена.")
+		}
+		return h.answer(b, ctx, "Эта проверка устарела, я отправил новую — ответь на неё в чате.")
+	}
+
+	passed, err := h.antiSpamService.AnswerCaptcha(userID, option)
+	if err != nil {
+		_ = h.answer(b, ctx, "Произошла ошибка, попробуй позже.")
+		return fmt.Errorf("%s: failed to answer captcha: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	if !passed {
+		return h.answer(b, ctx, "Неверный ответ. Ты можешь вступить в чат ещё раз.")
+	}
+
+	return h.answer(b, ctx, "Спасибо! Добро пожаловать в клуб 🎉")
+}
+
+func (h *CaptchaAnswerHandler) answer(b *gotgbot.Bot, ctx *ext.Context, text string) error {
+	_, err := ctx.CallbackQuery.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+		Text:      text,
+		ShowAlert: true,
+	})
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to answer callback query", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package grouphandlers
+
+import (
+	"fmt"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type CaptchaJoinHandler struct {
+	config                 *config.Config
+	antiSpamService        *services.AntiSpamService
+	membershipCacheService *services.MembershipCacheService
+}
+
+func NewCaptchaJoinHandler(
+	config *config.Config,
+	antiSpamService *services.AntiSpamService,
+	membershipCacheService *services.MembershipCacheService,
+) ext.Handler {
+	h := &CaptchaJoinHandler{
+		config:                 config,
+		antiSpamService:        antiSpamService,
+		membershipCacheService: membershipCacheService,
+	}
+	return handlers.NewChatMember(h.check, h.handle)
+}
+
+func (h *CaptchaJoinHandler) check(chatMember *gotgbot.ChatMemberUpdated) bool {
+	if !h.config.CaptchaEnabled && !h.config.AntiSpamEnabled {
+		return false
+	}
+
+	if chatMember.Chat.Id != utils.ChatIdToFullChatId(h.config.SuperGroupChatID) {
+		return false
+	}
+
+	// Only users who have just joined the chat
+	oldStatus := chatMember.OldChatMember.GetStatus()
+	wasOutside := oldStatus == "left" || oldStatus == "kicked"
+	isNowMember := chatMember.NewChatMember.GetStatus() == "member"
+
+	return wasOutside && isNowMember && !chatMember.NewChatMember.GetUser().IsBot
+}
+
+func (h *CaptchaJoinHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	chatMember := ctx.ChatMember
+
+	// Members added by admins don't need to pass the captcha
+	if chatMember.From.Id != chatMember.NewChatMember.GetUser().Id &&
+		utils.IsUserAdminOrCreator(h.membershipCacheService, chatMember.From.Id, h.config) {
+		return nil
+	}
+
+	if err := h.antiSpamService.HandleNewMember(chatMember.Chat, chatMember.NewChatMember.GetUser()); err != nil {
+		return fmt.Errorf("%s: failed to handle new member: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package grouphandlers
+
+import (
+	"fmt"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type forumTopicHandler struct {
+	config            *config.Config
+	forumTopicService *services.ForumTopicService
+}
+
+func NewForumTopicHandler(
+	config *config.Config,
+	forumTopicService *services.ForumTopicService,
+) ext.Handler {
+	h := &forumTopicHandler{
+		config:            config,
+		forumTopicService: forumTopicService,
+	}
+
+	return handlers.NewMessage(h.check, h.handle)
+}
+
+func (h *forumTopicHandler) check(msg *gotgbot.Message) bool {
+	if msg == nil {
+		return false
+	}
+
+	return msg.Chat.Id == utils.ChatIdToFullChatId(h.config.SuperGroupChatID) &&
+		services.IsForumTopicServiceMessage(msg)
+}
+
+func (h *forumTopicHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	if err := h.forumTopicService.HandleServiceMessage(ctx.EffectiveMessage); err != nil {
+		return fmt.Errorf("%s: failed to update forum topic: %w", utils.GetCurrentTypeName(), err)
+	}
+	return nil
+}
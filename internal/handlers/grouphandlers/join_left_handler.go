@@ -13,10 +13,10 @@ import (
 )
 
 type JoinLeftHandler struct {
-	userRepo *repositories.UserRepository
+	userRepo repositories.UserRepository
 }
 
-func NewJoinLeftHandler(userRepo *repositories.UserRepository) ext.Handler {
+func NewJoinLeftHandler(userRepo repositories.UserRepository) ext.Handler {
 	h := &JoinLeftHandler{userRepo: userRepo}
 	return handlers.NewChatMember(chatmember.All, h.handle)
 }
@@ -0,0 +1,36 @@
+package grouphandlers
+
+import (
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type MembershipCacheHandler struct {
+	config                 *config.Config
+	membershipCacheService *services.MembershipCacheService
+}
+
+func NewMembershipCacheHandler(
+	config *config.Config,
+	membershipCacheService *services.MembershipCacheService,
+) ext.Handler {
+	h := &MembershipCacheHandler{
+		config:                 config,
+		membershipCacheService: membershipCacheService,
+	}
+	return handlers.NewChatMember(h.check, h.handle)
+}
+
+func (h *MembershipCacheHandler) check(chatMember *gotgbot.ChatMemberUpdated) bool {
+	return chatMember.Chat.Id == utils.ChatIdToFullChatId(h.config.SuperGroupChatID)
+}
+
+func (h *MembershipCacheHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	h.membershipCacheService.Update(ctx.ChatMember)
+	return nil
+}
@@ -0,0 +1,101 @@
+package grouphandlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type ModerationHandler struct {
+	config                 *config.Config
+	moderationRulesService *services.ModerationRulesService
+	botUsername            string
+}
+
+func NewModerationHandler(
+	config *config.Config,
+	moderationRulesService *services.ModerationRulesService,
+) ext.Handler {
+	h := &ModerationHandler{
+		config:                 config,
+		moderationRulesService: moderationRulesService,
+	}
+
+	return handlers.NewMessage(h.check, h.handle)
+}
+
+func (h *ModerationHandler) check(msg *gotgbot.Message) bool {
+	if msg == nil || msg.From == nil {
+		return false
+	}
+
+	// Service messages are not moderated
+	if msg.NewChatMembers != nil || msg.LeftChatMember != nil {
+		return false
+	}
+
+	// Check if the topic has a moderation rule
+	if _, ok := h.moderationRulesService.GetRule(int(msg.MessageThreadId)); !ok {
+		return false
+	}
+
+	// Don't trigger if message is reply to another message in closed thread (this already handled by RepliesFromClosedThreadsHandler)
+	if slices.Contains(h.config.ClosedTopicsIDs, int(msg.MessageThreadId)) &&
+		msg.ReplyToMessage != nil &&
+		msg.ReplyToMessage.MessageId != msg.MessageThreadId {
+		return false
+	}
+
+	// Don't trigger if message handled by SaveHandler or ForwardHandler
+	if strings.HasPrefix(msg.Text, "/save") ||
+		strings.HasPrefix(msg.Text, "/forward") {
+		return false
+	}
+
+	return true
+}
+
+func (h *ModerationHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Set bot username if not set
+	if h.botUsername == "" {
+		h.botUsername = b.User.Username
+	}
+
+	// Don't trigger if message handled by SaveHandler with exact bot username
+	if msg.Text == "@"+h.botUsername {
+		return nil
+	}
+
+	rule, ok := h.moderationRulesService.GetRule(int(msg.MessageThreadId))
+	if !ok {
+		return nil
+	}
+
+	reason, violated := h.moderationRulesService.FindViolation(msg, rule)
+	if !violated {
+		return nil
+	}
+
+	if err := h.moderationRulesService.Enforce(msg, rule, reason); err != nil {
+		return fmt.Errorf("%s: error >> failed to enforce moderation rule: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// A warned message stays in the topic for a while, other actions remove it,
+	// so other handlers must not process it
+	if constants.ModerationAction(rule.Action) != constants.ModerationActionWarn {
+		return ext.EndGroups
+	}
+
+	return nil
+}
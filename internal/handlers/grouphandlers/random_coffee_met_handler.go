@@ -0,0 +1,92 @@
+package grouphandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+)
+
+type RandomCoffeeMetHandler struct {
+	userRepo     repositories.UserRepository
+	pairRepo     repositories.RandomCoffeePairRepository
+	scoreService *services.ScoreService
+}
+
+func NewRandomCoffeeMetHandler(
+	userRepo repositories.UserRepository,
+	pairRepo repositories.RandomCoffeePairRepository,
+	scoreService *services.ScoreService,
+) ext.Handler {
+	h := &RandomCoffeeMetHandler{
+		userRepo:     userRepo,
+		pairRepo:     pairRepo,
+		scoreService: scoreService,
+	}
+	return handlers.NewCallback(callbackquery.Prefix(constants.RandomCoffeeMetCallbackPrefix), h.handleCallback)
+}
+
+func (h *RandomCoffeeMetHandler) handleCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+
+	pollID, err := strconv.Atoi(strings.TrimPrefix(cb.Data, constants.RandomCoffeeMetCallbackPrefix))
+	if err != nil {
+		observability.Logger(ctx).Warn("Invalid callback data", "data", cb.Data, "error", err)
+		return h.answer(b, ctx, "Не удалось обработать нажатие.")
+	}
+
+	// 1. Find the pair of the user who pressed the button
+	user, err := h.userRepo.GetByTelegramID(cb.From.Id)
+	if err != nil && err != sql.ErrNoRows {
+		observability.Logger(ctx).Error("Failed to get user", "error", err)
+		return h.answer(b, ctx, "Произошла ошибка, попробуй позже.")
+	}
+	if err == sql.ErrNoRows {
+		return h.answer(b, ctx, "Эта кнопка только для участников пар этой недели.")
+	}
+
+	pair, err := h.pairRepo.GetPairByPollAndUser(pollID, user.ID)
+	if err == sql.ErrNoRows {
+		return h.answer(b, ctx, "Эта кнопка только для участников пар этой недели.")
+	}
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to get random coffee pair", "poll_id", pollID, "error", err)
+		return h.answer(b, ctx, "Произошла ошибка, попробуй позже.")
+	}
+
+	// 2. Award only the member who confirmed the meeting, each member of the pair confirms it for themselves
+	reference := fmt.Sprintf("random_coffee_pair:%d", pair.ID)
+	awarded, err := h.scoreService.Award(user.ID, constants.ScoreReasonRandomCoffeeCompleted, reference)
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to award random coffee karma", "pair_id", pair.ID, "error", err)
+		return h.answer(b, ctx, "Произошла ошибка, попробуй позже.")
+	}
+
+	if !awarded {
+		return h.answer(b, ctx, "Ты уже отметил(а) эту встречу. Спасибо!")
+	}
+
+	return h.answer(b, ctx, fmt.Sprintf("☕️ Отлично! Тебе начислено +%d к карме. Собеседник получит карму, когда тоже нажмёт кнопку.",
+		constants.ScoreRules[constants.ScoreReasonRandomCoffeeCompleted]))
+}
+
+func (h *RandomCoffeeMetHandler) answer(b *gotgbot.Bot, ctx *ext.Context, text string) error {
+	_, err := ctx.CallbackQuery.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+		Text:      text,
+		ShowAlert: true,
+	})
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to answer callback query", "error", err)
+	}
+	return nil
+}
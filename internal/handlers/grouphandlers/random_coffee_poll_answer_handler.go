@@ -2,6 +2,7 @@ package grouphandlers
 
 import (
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/repositories"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/utils"
@@ -15,17 +16,17 @@ import (
 
 type RandomCoffeePollAnswerHandler struct {
 	config               *config.Config
-	userRepo             *repositories.UserRepository
-	pollRepo             *repositories.RandomCoffeePollRepository
-	participantRepo      *repositories.RandomCoffeeParticipantRepository
+	userRepo             repositories.UserRepository
+	pollRepo             repositories.RandomCoffeePollRepository
+	participantRepo      repositories.RandomCoffeeParticipantRepository
 	messageSenderService *services.MessageSenderService
 }
 
 func NewRandomCoffeePollAnswerHandler(
 	config *config.Config,
-	userRepo *repositories.UserRepository,
-	pollRepo *repositories.RandomCoffeePollRepository,
-	participantRepo *repositories.RandomCoffeeParticipantRepository,
+	userRepo repositories.UserRepository,
+	pollRepo repositories.RandomCoffeePollRepository,
+	participantRepo repositories.RandomCoffeeParticipantRepository,
 	messageSenderService *services.MessageSenderService,
 ) ext.Handler {
 	h := &RandomCoffeePollAnswerHandler{
@@ -50,9 +51,10 @@ func (h *RandomCoffeePollAnswerHandler) handleUpdate(b *gotgbot.Bot, ctx *ext.Co
 	if pollAnswer.User.IsBot {
 		log.Printf("%s: Bot tried to vote. Ignoring.", utils.GetCurrentTypeName())
 		if len(pollAnswer.OptionIds) > 0 && pollAnswer.OptionIds[0] == 0 {
-			err := h.messageSenderService.SendHtml(
+			err := h.messageSenderService.EnqueueHtmlOrSend(
 				h.config.AdminUserID,
 				"🚫 К сожалению, участие в опросе Random Coffee для ботов недоступно. Пожалуйста, отзови свой голос.",
+				constants.MessagePriorityNotification,
 				nil,
 			)
 			if err != nil {
@@ -73,10 +75,11 @@ func (h *RandomCoffeePollAnswerHandler) handleUpdate(b *gotgbot.Bot, ctx *ext.Co
 	if internalUser.HasCoffeeBan {
 		log.Printf("%s: User %d is banned. Ignoring.", utils.GetCurrentTypeName(), pollAnswer.User.Id)
 		if len(pollAnswer.OptionIds) > 0 && pollAnswer.OptionIds[0] == 0 {
-			err := h.messageSenderService.SendHtml(
+			err := h.messageSenderService.EnqueueHtmlOrSend(
 				internalUser.TgID,
 				"🚫 К сожалению, участие в опросе Random Coffee для тебя недоступно, так как ты находишься в бане. "+
 					"Пожалуйста, отзови свой голос, и обратись к администратору для разблокировки.",
+				constants.MessagePriorityNotification,
 				nil,
 			)
 			if err != nil {
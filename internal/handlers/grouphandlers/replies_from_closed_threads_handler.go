@@ -6,7 +6,6 @@ import (
 	"strconv"
 	"unicode/utf8"
 
-	"evo-bot-go/internal/clients"
 	"evo-bot-go/internal/config"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/utils"
@@ -19,11 +18,13 @@ import (
 type RepliesFromClosedThreadsHandler struct {
 	config               *config.Config
 	closedTopics         map[int]bool
+	forumTopicService    *services.ForumTopicService
 	messageSenderService *services.MessageSenderService
 }
 
 func NewRepliesFromClosedThreadsHandler(
 	config *config.Config,
+	forumTopicService *services.ForumTopicService,
 	messageSenderService *services.MessageSenderService,
 ) ext.Handler {
 	// Create map of closed topics
@@ -34,6 +35,7 @@ func NewRepliesFromClosedThreadsHandler(
 
 	h := &RepliesFromClosedThreadsHandler{
 		closedTopics:         closedTopics,
+		forumTopicService:    forumTopicService,
 		messageSenderService: messageSenderService,
 		config:               config,
 	}
@@ -87,14 +89,13 @@ func (h *RepliesFromClosedThreadsHandler) forwardReplyMessage(ctx *ext.Context)
 		msg.ReplyToMessage.MessageId)
 
 	// Get the topic name
-	topicName, topicErr := clients.TgGetTopicName(int(msg.MessageThreadId))
+	topicName, topicErr := h.forumTopicService.GetTopicName(int(msg.MessageThreadId))
 	if topicErr != nil {
+		// Continue with the default topic name
 		log.Printf(
 			"%s: warning >> failed to get topic name: %v",
 			utils.GetCurrentTypeName(),
 			topicErr)
-		// Continue with a default topic name
-		topicName = "Topic"
 	}
 
 	// Prepare the text with the topic name and user mention
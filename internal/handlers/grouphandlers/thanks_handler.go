@@ -0,0 +1,71 @@
+package grouphandlers
+
+import (
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type ThanksHandler struct {
+	config        *config.Config
+	thanksService *services.ThanksService
+}
+
+func NewThanksHandler(
+	config *config.Config,
+	thanksService *services.ThanksService,
+) ext.Handler {
+	h := &ThanksHandler{
+		config:        config,
+		thanksService: thanksService,
+	}
+
+	return handlers.NewMessage(h.check, h.handle)
+}
+
+func (h *ThanksHandler) check(msg *gotgbot.Message) bool {
+	if msg == nil || msg.From == nil {
+		return false
+	}
+
+	// Only messages of the club supergroup
+	return msg.Chat.Id == utils.ChatIdToFullChatId(h.config.SuperGroupChatID)
+}
+
+func (h *ThanksHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Remember the author, so reactions to this message can be credited later
+	if err := h.thanksService.RememberMessageAuthor(msg.Chat.Id, msg.MessageId, msg.From, time.Unix(msg.Date, 0)); err != nil {
+		observability.Logger(ctx).Error("Failed to remember message author", "message_id", msg.MessageId, "error", err)
+	}
+
+	reply := msg.ReplyToMessage
+	if reply == nil || reply.From == nil {
+		return nil
+	}
+
+	// Replies to the topic itself are not directed at a member
+	if reply.MessageId == msg.MessageThreadId {
+		return nil
+	}
+
+	if !utils.IsThanksMessage(msg.GetText()) {
+		return nil
+	}
+
+	_, err := h.thanksService.GiveThanks(msg.From, reply.From, msg.Chat.Id, reply.MessageId, constants.ThanksSourceReply)
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to give thanks", "receiver_id", reply.From.Id, "error", err)
+	}
+
+	return nil
+}
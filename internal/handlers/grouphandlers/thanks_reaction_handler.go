@@ -0,0 +1,86 @@
+package grouphandlers
+
+import (
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type ThanksReactionHandler struct {
+	config        *config.Config
+	thanksService *services.ThanksService
+}
+
+func NewThanksReactionHandler(
+	config *config.Config,
+	thanksService *services.ThanksService,
+) ext.Handler {
+	h := &ThanksReactionHandler{
+		config:        config,
+		thanksService: thanksService,
+	}
+
+	return handlers.NewReaction(h.check, h.handle)
+}
+
+func (h *ThanksReactionHandler) check(reaction *gotgbot.MessageReactionUpdated) bool {
+	// Anonymous reactions can't be credited
+	if reaction == nil || reaction.User == nil {
+		return false
+	}
+
+	return reaction.Chat.Id == utils.ChatIdToFullChatId(h.config.SuperGroupChatID)
+}
+
+func (h *ThanksReactionHandler) handle(b *gotgbot.Bot, ctx *ext.Context) error {
+	reaction := ctx.MessageReaction
+
+	// Only a newly added thanks reaction counts
+	if !h.hasNewThanksReaction(reaction) {
+		return nil
+	}
+
+	author, err := h.thanksService.GetMessageAuthor(reaction.Chat.Id, reaction.MessageId)
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to get message author", "message_id", reaction.MessageId, "error", err)
+		return nil
+	}
+	if author == nil {
+		observability.Logger(ctx).Info("Author of the message is unknown, skipping reaction", "message_id", reaction.MessageId)
+		return nil
+	}
+
+	_, err = h.thanksService.GiveThanks(reaction.User, author, reaction.Chat.Id, reaction.MessageId, constants.ThanksSourceReaction)
+	if err != nil {
+		observability.Logger(ctx).Error("Failed to give thanks", "receiver_id", author.Id, "error", err)
+	}
+
+	return nil
+}
+
+func (h *ThanksReactionHandler) hasNewThanksReaction(reaction *gotgbot.MessageReactionUpdated) bool {
+	for _, newReaction := range reaction.NewReaction {
+		if !h.thanksService.IsThanksReaction(newReaction) {
+			continue
+		}
+
+		isNew := true
+		for _, oldReaction := range reaction.OldReaction {
+			if oldReaction.MergeReactionType() == newReaction.MergeReactionType() {
+				isNew = false
+				break
+			}
+		}
+		if isNew {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,360 @@
+package privatehandlers
+
+import (
+	"context"
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/prompts"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states
+	directoryStateAwaitQuery = "directory_state_await_query"
+	directoryStateResults    = "directory_state_results"
+
+	// UserStore keys
+	directoryCtxDataKeyQuery             = "directory_ctx_data_query"
+	directoryCtxDataKeyStatus            = "directory_ctx_data_status"
+	directoryCtxDataKeyPreviousMessageID = "directory_ctx_data_previous_message_id"
+	directoryCtxDataKeyPreviousChatID    = "directory_ctx_data_previous_chat_id"
+
+	// directoryRephrasedQueryLengthLimit cuts the rephrased query, so a verbose answer of the LLM stays a query
+	directoryRephrasedQueryLengthLimit = 200
+)
+
+type directoryHandler struct {
+	config                      *config.Config
+	profileRepository           repositories.ProfileRepository
+	promptingTemplateRepository *repositories.PromptingTemplateRepository
+	openaiClient                *clients.OpenAiClient
+	llmUsageService             *services.LLMUsageService
+	messageSenderService        *services.MessageSenderService
+	permissionsService          *services.PermissionsService
+	userStore                   *utils.UserDataStore
+}
+
+func NewDirectoryHandler(
+	config *config.Config,
+	profileRepository repositories.ProfileRepository,
+	promptingTemplateRepository *repositories.PromptingTemplateRepository,
+	openaiClient *clients.OpenAiClient,
+	llmUsageService *services.LLMUsageService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &directoryHandler{
+		config:                      config,
+		profileRepository:           profileRepository,
+		promptingTemplateRepository: promptingTemplateRepository,
+		openaiClient:                openaiClient,
+		llmUsageService:             llmUsageService,
+		messageSenderService:        messageSenderService,
+		permissionsService:          permissionsService,
+		userStore:                   utils.NewUserDataStore(),
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.DirectoryCommand, h.handleCommand),
+		},
+		map[string][]ext.Handler{
+			directoryStateAwaitQuery: {
+				handlers.NewMessage(message.Text, h.handleQueryInput),
+				handlers.NewCallback(callbackquery.Equal(constants.DirectoryCancelCallback), h.handleCallbackCancel),
+			},
+			directoryStateResults: {
+				handlers.NewMessage(message.Text, h.handleQueryInput),
+				handlers.NewCallback(callbackquery.Equal(constants.DirectoryCancelCallback), h.handleCallbackCancel),
+				handlers.NewCallback(callbackquery.Prefix(constants.DirectoryPrefix), h.handleCallback),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+			// The command with a query starts a new search during the conversation too
+			AllowReEntry: true,
+		},
+	)
+}
+
+// handleCommand is the entry point of /directory, the query may follow the command
+func (h *directoryHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	if !h.permissionsService.CheckPrivateChatType(msg) {
+		return handlers.EndConversation()
+	}
+
+	if !h.permissionsService.CheckClubMemberPermissions(msg, constants.DirectoryCommand) {
+		return handlers.EndConversation()
+	}
+
+	h.MessageRemoveInlineKeyboard(&ctx.EffectiveUser.Id)
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+	h.userStore.Set(ctx.EffectiveUser.Id, directoryCtxDataKeyStatus, constants.DirectoryStatusMembers)
+
+	if args := strings.Fields(msg.Text)[1:]; len(args) > 0 {
+		return h.search(b, ctx.EffectiveUser.Id, msg.Chat.Id, utils.ParseDirectoryQuery(strings.Join(args, " ")))
+	}
+
+	return h.askQuery(ctx.EffectiveUser.Id, msg.Chat.Id)
+}
+
+// handleQueryInput starts a new search by the text of the user
+func (h *directoryHandler) handleQueryInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	query := utils.ParseDirectoryQuery(msg.Text)
+	if query == (utils.DirectoryQuery{}) {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Поисковый запрос не может быть пустым. Пожалуйста, введи запрос или используй /%s для отмены.", constants.CancelCommand),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	h.MessageRemoveInlineKeyboard(&ctx.EffectiveUser.Id)
+	return h.search(b, ctx.EffectiveUser.Id, msg.Chat.Id, query)
+}
+
+// handleCallback handles the buttons under the results
+func (h *directoryHandler) handleCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	callback := ctx.Update.CallbackQuery
+	_, _ = callback.Answer(b, nil)
+
+	userID := callback.From.Id
+	query, _ := h.userStore.Get(userID, directoryCtxDataKeyQuery)
+	directoryQuery, _ := query.(utils.DirectoryQuery)
+
+	if pageText, ok := strings.CutPrefix(callback.Data, constants.DirectoryPagePrefix); ok {
+		page, err := strconv.Atoi(pageText)
+		if err != nil || page < 0 {
+			return fmt.Errorf("%s: invalid page in callback %q", utils.GetCurrentTypeName(), callback.Data)
+		}
+		return h.showResults(b, userID, ctx.EffectiveMessage, directoryQuery, page)
+	}
+
+	switch callback.Data {
+	case constants.DirectoryStatusCallback:
+		h.userStore.Set(userID, directoryCtxDataKeyStatus, nextDirectoryStatus(h.status(userID)))
+		return h.showResults(b, userID, ctx.EffectiveMessage, directoryQuery, 0)
+	case constants.DirectoryRephraseCallback:
+		return h.handleRephrase(b, ctx, directoryQuery)
+	case constants.DirectoryNewSearchCallback:
+		h.MessageRemoveInlineKeyboard(&userID)
+		return h.askQuery(userID, ctx.EffectiveMessage.Chat.Id)
+	}
+
+	return nil
+}
+
+// handleRephrase asks the LLM for keywords and synonyms of the query and searches by them,
+// the filters of the query are kept
+func (h *directoryHandler) handleRephrase(b *gotgbot.Bot, ctx *ext.Context, query utils.DirectoryQuery) error {
+	userID := ctx.EffectiveUser.Id
+	msg := ctx.EffectiveMessage
+
+	if h.openaiClient == nil || query.Text == "" {
+		return nil
+	}
+
+	if llmQuotaExceeded(h.llmUsageService, h.messageSenderService, msg, userID) {
+		return nil // Stay with the current results
+	}
+
+	templateText, err := h.promptingTemplateRepository.Get(prompts.DirectoryQueryPromptTemplateDbKey)
+	if err != nil {
+		h.messageSenderService.Send(msg.Chat.Id, "Произошла ошибка при получении шаблона для расширения запроса.", nil)
+		log.Printf("%s: Error during template retrieval: %v", utils.GetCurrentTypeName(), err)
+		return nil
+	}
+
+	h.messageSenderService.SendTypingAction(msg.Chat.Id)
+
+	llmCtx := services.WithLLMCaller(context.Background(), userID, constants.LLMFeatureDirectory)
+	response, err := h.openaiClient.GetCompletion(llmCtx, fmt.Sprintf(templateText, query.Text))
+	if err != nil {
+		h.messageSenderService.Send(msg.Chat.Id, "Произошла ошибка при получении ответа от OpenAI.", nil)
+		log.Printf("%s: Error during OpenAI response retrieval: %v", utils.GetCurrentTypeName(), err)
+		return nil
+	}
+
+	rephrased := cleanRephrasedDirectoryQuery(response)
+	if rephrased == "" {
+		h.messageSenderService.Send(msg.Chat.Id, "Не получилось расширить запрос, попробуй сформулировать его иначе.", nil)
+		return nil
+	}
+
+	query.Text = rephrased
+	return h.showResults(b, userID, msg, query, 0)
+}
+
+// cleanRephrasedDirectoryQuery keeps the first line of the answer without quotes and filters,
+// so the answer cannot change the tag and the city of the query
+func cleanRephrasedDirectoryQuery(response string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(response), "\n")
+	line = strings.Trim(strings.TrimSpace(line), "\"'`«»")
+	text := utils.ParseDirectoryQuery(line).Text
+	if utf8.RuneCountInString(text) > directoryRephrasedQueryLengthLimit {
+		text = string([]rune(text)[:directoryRephrasedQueryLengthLimit])
+	}
+	return strings.TrimSpace(text)
+}
+
+func (h *directoryHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	_, _ = ctx.Update.CallbackQuery.Answer(b, nil)
+	return h.handleCancel(b, ctx)
+}
+
+func (h *directoryHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	h.MessageRemoveInlineKeyboard(&ctx.EffectiveUser.Id)
+	h.messageSenderService.Send(ctx.EffectiveChat.Id, "Поиск по каталогу участников завершён.", nil)
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+	return handlers.EndConversation()
+}
+
+func (h *directoryHandler) askQuery(userID int64, chatID int64) error {
+	sentMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+		chatID,
+		"<b>📇 Каталог участников</b>\n\n"+formatters.FormatDirectoryHelp(),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(constants.DirectoryCancelCallback),
+		})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in askQuery: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(userID, sentMsg)
+	return handlers.NextConversationState(directoryStateAwaitQuery)
+}
+
+// search sends the first page of the results as a new message
+func (h *directoryHandler) search(b *gotgbot.Bot, userID int64, chatID int64, query utils.DirectoryQuery) error {
+	return h.showResults(b, userID, &gotgbot.Message{Chat: gotgbot.Chat{Id: chatID}}, query, 0)
+}
+
+// showResults shows the page of the results in the message of the bot or, without the message ID, in a new message
+func (h *directoryHandler) showResults(b *gotgbot.Bot, userID int64, msg *gotgbot.Message, query utils.DirectoryQuery, page int) error {
+	status := h.status(userID)
+	filter := repositories.ProfileSearchFilter{
+		Query:        query.Text,
+		Tag:          query.Tag,
+		City:         query.City,
+		IsClubMember: directoryStatusFilter(status),
+	}
+
+	profiles, total, err := h.profileRepository.Search(filter, constants.DirectoryPageSize, page*constants.DirectoryPageSize)
+	if err != nil {
+		h.messageSenderService.Send(msg.Chat.Id, "Произошла ошибка при поиске участников.", nil)
+		log.Printf("%s: Error during profiles search: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	// The profiles could change since the previous page
+	pages := (total + constants.DirectoryPageSize - 1) / constants.DirectoryPageSize
+	if page > 0 && page >= pages {
+		return h.showResults(b, userID, msg, query, max(pages-1, 0))
+	}
+
+	h.userStore.Set(userID, directoryCtxDataKeyQuery, query)
+
+	text := formatters.FormatDirectoryResults(query, status, profiles, total, page, h.config)
+	markup := buttons.DirectoryResultsButtons(page, pages, status, h.openaiClient != nil && query.Text != "")
+	linkPreview := &gotgbot.LinkPreviewOptions{IsDisabled: true}
+
+	if msg.MessageId != 0 {
+		_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+			ChatId:             msg.Chat.Id,
+			MessageId:          msg.MessageId,
+			ParseMode:          "HTML",
+			ReplyMarkup:        markup,
+			LinkPreviewOptions: linkPreview,
+		})
+		if err != nil && !strings.Contains(err.Error(), "are exactly the same") {
+			return fmt.Errorf("%s: failed to edit message in showResults: %w", utils.GetCurrentTypeName(), err)
+		}
+		return handlers.NextConversationState(directoryStateResults)
+	}
+
+	sentMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(msg.Chat.Id, text, &gotgbot.SendMessageOpts{
+		ReplyMarkup:        markup,
+		LinkPreviewOptions: linkPreview,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in showResults: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(userID, sentMsg)
+	return handlers.NextConversationState(directoryStateResults)
+}
+
+func (h *directoryHandler) status(userID int64) constants.DirectoryStatus {
+	if status, ok := h.userStore.Get(userID, directoryCtxDataKeyStatus); ok {
+		return status.(constants.DirectoryStatus)
+	}
+	return constants.DirectoryStatusMembers
+}
+
+func nextDirectoryStatus(status constants.DirectoryStatus) constants.DirectoryStatus {
+	switch status {
+	case constants.DirectoryStatusMembers:
+		return constants.DirectoryStatusAll
+	case constants.DirectoryStatusAll:
+		return constants.DirectoryStatusFormer
+	}
+	return constants.DirectoryStatusMembers
+}
+
+func directoryStatusFilter(status constants.DirectoryStatus) *bool {
+	var isClubMember bool
+	switch status {
+	case constants.DirectoryStatusMembers:
+		isClubMember = true
+	case constants.DirectoryStatusFormer:
+		isClubMember = false
+	default:
+		return nil
+	}
+	return &isClubMember
+}
+
+func (h *directoryHandler) MessageRemoveInlineKeyboard(userID *int64) {
+	var chatID, messageID int64
+
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			directoryCtxDataKeyPreviousMessageID,
+			directoryCtxDataKeyPreviousChatID,
+		)
+	}
+
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *directoryHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		directoryCtxDataKeyPreviousMessageID, directoryCtxDataKeyPreviousChatID)
+}
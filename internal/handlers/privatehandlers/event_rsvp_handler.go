@@ -0,0 +1,350 @@
+package privatehandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"evo-bot-go/internal/buttons"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+const (
+	// Conversation states names
+	eventRsvpStateSelectEvent = "event_rsvp_state_select_event"
+	eventRsvpStateConfirm     = "event_rsvp_state_confirm"
+
+	// Context data keys
+	eventRsvpCtxDataKeySelectedEventID   = "event_rsvp_ctx_data_selected_event_id"
+	eventRsvpCtxDataKeyIsRegistered      = "event_rsvp_ctx_data_is_registered"
+	eventRsvpCtxDataKeyPreviousMessageID = "event_rsvp_ctx_data_previous_message_id"
+	eventRsvpCtxDataKeyPreviousChatID    = "event_rsvp_ctx_data_previous_chat_id"
+
+	// Callback data
+	eventRsvpCallbackConfirmYes    = "event_rsvp_callback_confirm_yes"
+	eventRsvpCallbackConfirmCancel = "event_rsvp_callback_confirm_cancel"
+)
+
+type eventRsvpHandler struct {
+	config                   *config.Config
+	eventRepository          repositories.EventRepository
+	userRepository           repositories.UserRepository
+	eventRegistrationService *services.EventRegistrationService
+	messageSenderService     *services.MessageSenderService
+	userStore                *utils.UserDataStore
+	permissionsService       *services.PermissionsService
+}
+
+func NewEventRsvpHandler(
+	config *config.Config,
+	eventRepository repositories.EventRepository,
+	userRepository repositories.UserRepository,
+	eventRegistrationService *services.EventRegistrationService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &eventRsvpHandler{
+		config:                   config,
+		eventRepository:          eventRepository,
+		userRepository:           userRepository,
+		eventRegistrationService: eventRegistrationService,
+		messageSenderService:     messageSenderService,
+		userStore:                utils.NewUserDataStore(),
+		permissionsService:       permissionsService,
+	}
+
+	return handlers.NewConversation(
+		[]ext.Handler{
+			handlers.NewCommand(constants.EventRsvpCommand, h.startRsvp),
+		},
+		map[string][]ext.Handler{
+			eventRsvpStateSelectEvent: {
+				handlers.NewMessage(message.Text, h.handleEventSelection),
+				handlers.NewCallback(callbackquery.Equal(eventRsvpCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+			eventRsvpStateConfirm: {
+				handlers.NewCallback(callbackquery.Equal(eventRsvpCallbackConfirmYes), h.handleConfirmCallback),
+				handlers.NewCallback(callbackquery.Equal(eventRsvpCallbackConfirmCancel), h.handleCallbackCancel),
+			},
+		},
+		&handlers.ConversationOpts{
+			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
+		},
+	)
+}
+
+// 1. startRsvp is the entry point handler for registration to offline events
+func (h *eventRsvpHandler) startRsvp(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Only proceed if this is a private chat
+	if !h.permissionsService.CheckPrivateChatType(msg) {
+		return handlers.EndConversation()
+	}
+
+	// Check if user is a club member
+	if !h.permissionsService.CheckClubMemberPermissions(msg, constants.EventRsvpCommand) {
+		return handlers.EndConversation()
+	}
+
+	// Get last actual events and keep only those with registration
+	events, err := h.eventRepository.GetLastActualEvents(10)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Ошибка при получении списка мероприятий.", nil)
+		log.Printf("%s: Error during events retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	var registrableEvents []repositories.Event
+	for i := range events {
+		if h.eventRegistrationService.SupportsRegistration(&events[i]) {
+			registrableEvents = append(registrableEvents, events[i])
+		}
+	}
+
+	if len(registrableEvents) == 0 {
+		h.messageSenderService.Reply(msg, "Сейчас нет офлайн-мероприятий, на которые можно записаться.", nil)
+		return handlers.EndConversation()
+	}
+
+	// Show event times in the member's own timezone
+	loc := h.config.ClubTimezone
+	if user, err := h.userRepository.GetByTelegramID(ctx.EffectiveUser.Id); err == nil {
+		loc = utils.ResolveLocation(user.Timezone, h.config.ClubTimezone)
+	}
+
+	formattedEvents := formatters.FormatEventListForTopicsView(
+		registrableEvents,
+		"Выбери ID мероприятия, на которое ты хочешь записаться или запись на которое хочешь отменить",
+		loc,
+	)
+
+	sentMsg, _ := h.messageSenderService.ReplyMarkdownWithReturnMessage(
+		msg,
+		formattedEvents,
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.CancelButton(eventRsvpCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(eventRsvpStateSelectEvent)
+}
+
+// 2. handleEventSelection shows the registration status for the selected event
+func (h *eventRsvpHandler) handleEventSelection(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	userInput := strings.TrimSpace(strings.Replace(msg.Text, "/", "", 1))
+
+	eventID, err := strconv.Atoi(userInput)
+	if err != nil {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Пожалуйста, отправь корректный ID мероприятия или используй /%s для отмены.", constants.CancelCommand),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil || !h.eventRegistrationService.SupportsRegistration(event) {
+		h.messageSenderService.Reply(
+			msg,
+			fmt.Sprintf("Не удалось найти офлайн-мероприятие с ID %d. Пожалуйста, проверь ID.", eventID),
+			nil,
+		)
+		return nil // Stay in the same state
+	}
+
+	dbUser, err := h.userRepository.GetOrCreate(ctx.EffectiveUser)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при получении твоих данных.", nil)
+		log.Printf("%s: Error during user retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	registration, err := h.eventRepository.GetEventRegistration(event.ID, dbUser.ID)
+	if err != nil && err != sql.ErrNoRows {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при получении твоей записи.", nil)
+		log.Printf("%s: Error during registration retrieval: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	going, waitlist, err := h.eventRepository.CountEventRegistrations(event.ID)
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Произошла ошибка при получении списка участников.", nil)
+		log.Printf("%s: Error during registrations count: %v", utils.GetCurrentTypeName(), err)
+		return handlers.EndConversation()
+	}
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+
+	placesStr := fmt.Sprintf("%d (без ограничений)", going)
+	if event.Capacity != nil {
+		placesStr = fmt.Sprintf("%d из %d", going, *event.Capacity)
+	}
+
+	text := fmt.Sprintf(
+		"%s <b>%s</b>\n\nЗаписано: %s\nВ листе ожидания: %d\n\n",
+		formatters.GetTypeEmoji(constants.EventType(event.Type)), event.Name, placesStr, waitlist,
+	)
+
+	isRegistered := registration != nil
+	switch {
+	case !isRegistered:
+		text += "Ты ещё не записан(а). Подтверди запись на мероприятие:"
+		if event.Capacity != nil && going >= *event.Capacity {
+			text += "\n<i>Свободных мест нет, поэтому ты попадёшь в лист ожидания.</i>"
+		}
+	case registration.Status == string(constants.EventRegistrationStatusWaitlist):
+		text += "Ты в листе ожидания. Подтверди, если хочешь отменить запись:"
+	default:
+		text += "Ты записан(а) на мероприятие. Подтверди, если хочешь отменить запись:"
+	}
+
+	h.userStore.Set(ctx.EffectiveUser.Id, eventRsvpCtxDataKeySelectedEventID, event.ID)
+	h.userStore.Set(ctx.EffectiveUser.Id, eventRsvpCtxDataKeyIsRegistered, isRegistered)
+
+	sentMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+		msg.Chat.Id,
+		text,
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ConfirmAndCancelButton(eventRsvpCallbackConfirmYes, eventRsvpCallbackConfirmCancel),
+		},
+	)
+
+	h.SavePreviousMessageInfo(ctx.EffectiveUser.Id, sentMsg)
+	return handlers.NextConversationState(eventRsvpStateConfirm)
+}
+
+// 3. handleConfirmCallback registers the user or cancels the registration
+func (h *eventRsvpHandler) handleConfirmCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	msg := ctx.EffectiveMessage
+	userID := ctx.EffectiveUser.Id
+
+	h.MessageRemoveInlineKeyboard(b, &userID)
+
+	eventIDVal, ok := h.userStore.Get(userID, eventRsvpCtxDataKeySelectedEventID)
+	eventID, okType := eventIDVal.(int)
+	if !ok || !okType {
+		h.messageSenderService.Send(msg.Chat.Id, fmt.Sprintf(
+			"Произошла ошибка при получении выбранного мероприятия. Пожалуйста, начни заново с /%s",
+			constants.EventRsvpCommand,
+		), nil)
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	isRegisteredVal, _ := h.userStore.Get(userID, eventRsvpCtxDataKeyIsRegistered)
+	isRegistered, _ := isRegisteredVal.(bool)
+
+	event, err := h.eventRepository.GetEventByID(eventID)
+	if err != nil {
+		h.messageSenderService.Send(msg.Chat.Id, "Не удалось найти выбранное мероприятие.", nil)
+		log.Printf("%s: Error during event retrieval: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	if isRegistered {
+		err = h.eventRegistrationService.Cancel(event, ctx.EffectiveUser)
+		if err != nil {
+			h.messageSenderService.Send(msg.Chat.Id, "Произошла ошибка при отмене записи.", nil)
+			log.Printf("%s: Error during registration cancel: %v", utils.GetCurrentTypeName(), err)
+		} else {
+			h.messageSenderService.SendHtml(msg.Chat.Id,
+				fmt.Sprintf("Запись на мероприятие <b>%s</b> отменена. Спасибо, что освободил(а) место!", event.Name), nil)
+		}
+
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	registration, err := h.eventRegistrationService.Register(event, ctx.EffectiveUser)
+	if err != nil {
+		h.messageSenderService.Send(msg.Chat.Id, "Произошла ошибка при записи на мероприятие.", nil)
+		log.Printf("%s: Error during registration: %v", utils.GetCurrentTypeName(), err)
+		h.userStore.Clear(userID)
+		return handlers.EndConversation()
+	}
+
+	if registration.Status == string(constants.EventRegistrationStatusWaitlist) {
+		h.messageSenderService.SendHtml(msg.Chat.Id, fmt.Sprintf(
+			"Свободных мест на мероприятие <b>%s</b> нет, ты добавлен(а) в лист ожидания. "+
+				"Как только место освободится, я напишу тебе в личку.",
+			event.Name,
+		), nil)
+	} else {
+		h.messageSenderService.SendHtml(msg.Chat.Id, fmt.Sprintf(
+			"✅ Ты записан(а) на мероприятие <b>%s</b>!\n\nЕсли планы поменяются, отмени запись через /%s, чтобы место досталось другим.",
+			event.Name, constants.EventRsvpCommand,
+		), nil)
+	}
+
+	h.userStore.Clear(userID)
+	return handlers.EndConversation()
+}
+
+// handleCallbackCancel processes the cancel button click
+func (h *eventRsvpHandler) handleCallbackCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	// Answer the callback query to remove the loading state on the button
+	cb := ctx.Update.CallbackQuery
+	_, _ = cb.Answer(b, nil)
+
+	return h.handleCancel(b, ctx)
+}
+
+// 4. handleCancel handles the /cancel command
+func (h *eventRsvpHandler) handleCancel(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+	h.messageSenderService.Reply(msg, "Операция записи на мероприятие отменена.", nil)
+
+	// Clean up user data
+	h.userStore.Clear(ctx.EffectiveUser.Id)
+
+	return handlers.EndConversation()
+}
+
+func (h *eventRsvpHandler) MessageRemoveInlineKeyboard(b *gotgbot.Bot, userID *int64) {
+	var chatID, messageID int64
+
+	// If userID provided, get stored message info using the utility method
+	if userID != nil {
+		messageID, chatID = h.userStore.GetPreviousMessageInfo(
+			*userID,
+			eventRsvpCtxDataKeyPreviousMessageID,
+			eventRsvpCtxDataKeyPreviousChatID,
+		)
+	}
+
+	// Skip if we don't have valid chat and message IDs
+	if chatID == 0 || messageID == 0 {
+		return
+	}
+
+	// Use message sender service to remove the inline keyboard
+	_ = h.messageSenderService.RemoveInlineKeyboard(chatID, messageID)
+}
+
+func (h *eventRsvpHandler) SavePreviousMessageInfo(userID int64, sentMsg *gotgbot.Message) {
+	h.userStore.SetPreviousMessageInfo(userID, sentMsg.MessageId, sentMsg.Chat.Id,
+		eventRsvpCtxDataKeyPreviousMessageID, eventRsvpCtxDataKeyPreviousChatID)
+}
@@ -17,20 +17,23 @@ import (
 
 type eventsHandler struct {
 	config               *config.Config
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	userRepository       repositories.UserRepository
 	messageSenderService *services.MessageSenderService
 	permissionsService   *services.PermissionsService
 }
 
 func NewEventsHandler(
 	config *config.Config,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	userRepository repositories.UserRepository,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
 	h := &eventsHandler{
 		config:               config,
 		eventRepository:      eventRepository,
+		userRepository:       userRepository,
 		messageSenderService: messageSenderService,
 		permissionsService:   permissionsService,
 	}
@@ -64,13 +67,21 @@ func (h *eventsHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 		return nil
 	}
 
+	// Show event times in the member's own timezone
+	loc := h.config.ClubTimezone
+	if user, err := h.userRepository.GetByTelegramID(ctx.EffectiveUser.Id); err == nil {
+		loc = utils.ResolveLocation(user.Timezone, h.config.ClubTimezone)
+	}
+
 	// Format and display event list
 	formattedEvents := formatters.FormatEventListForEventsView(
 		events,
 		"📋 Список ближайших мероприятий",
+		loc,
 	)
 	formattedEvents += fmt.Sprintf("\nДобавить темы и вопросы /%s. ", constants.TopicAddCommand)
 	formattedEvents += fmt.Sprintf("Просмотреть темы и вопросы /%s. ", constants.TopicsCommand)
+	formattedEvents += fmt.Sprintf("Записаться на офлайн-мероприятие /%s. ", constants.EventRsvpCommand)
 	formattedEvents += "Больше информации о мероприятиях смотри в [клубном календаре](https://itbeard.com/s/evo-calendar)."
 	h.messageSenderService.ReplyMarkdown(msg, formattedEvents, nil)
 
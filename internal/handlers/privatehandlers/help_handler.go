@@ -5,7 +5,6 @@ import (
 	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/formatters"
 	"evo-bot-go/internal/services"
-	"evo-bot-go/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -46,7 +45,7 @@ func (h *helpHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	}
 
 	user := ctx.EffectiveUser
-	isAdmin := utils.IsUserAdminOrCreator(b, user.Id, h.config)
+	isAdmin := h.permissionsService.IsStaff(user.Id)
 	helpText := formatters.FormatHelpMessage(isAdmin, h.config)
 
 	h.messageSenderService.ReplyHtml(msg, helpText, nil)
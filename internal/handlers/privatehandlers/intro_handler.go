@@ -43,6 +43,8 @@ const (
 type introHandler struct {
 	config                      *config.Config
 	openaiClient                *clients.OpenAiClient
+	llmUsageService             *services.LLMUsageService
+	tgUserClient                clients.TgUserClient
 	promptingTemplateRepository *repositories.PromptingTemplateRepository
 	messageSenderService        *services.MessageSenderService
 	userStore                   *utils.UserDataStore
@@ -52,6 +54,8 @@ type introHandler struct {
 func NewIntroHandler(
 	config *config.Config,
 	openaiClient *clients.OpenAiClient,
+	llmUsageService *services.LLMUsageService,
+	tgUserClient clients.TgUserClient,
 	messageSenderService *services.MessageSenderService,
 	promptingTemplateRepository *repositories.PromptingTemplateRepository,
 	permissionsService *services.PermissionsService,
@@ -59,6 +63,8 @@ func NewIntroHandler(
 	h := &introHandler{
 		config:                      config,
 		openaiClient:                openaiClient,
+		llmUsageService:             llmUsageService,
+		tgUserClient:                tgUserClient,
 		promptingTemplateRepository: promptingTemplateRepository,
 		messageSenderService:        messageSenderService,
 		userStore:                   utils.NewUserDataStore(),
@@ -128,11 +134,18 @@ func (h *introHandler) processIntroSearch(b *gotgbot.Bot, ctx *ext.Context) erro
 	// Get query from user message
 	query := strings.TrimSpace(msg.Text)
 
+	if llmQuotaExceeded(h.llmUsageService, h.messageSenderService, msg, ctx.EffectiveUser.Id) {
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
 	// Mark as processing
 	h.userStore.Set(ctx.EffectiveUser.Id, introCtxDataKeyProcessing, true)
 
 	// Create a cancellable context for this operation
-	typingCtx, cancelTyping := context.WithCancel(context.Background())
+	llmCtx := services.WithLLMCaller(context.Background(), ctx.EffectiveUser.Id, constants.LLMFeatureIntro)
+	typingCtx, cancelTyping := context.WithCancel(llmCtx)
 
 	// Store cancel function in user store so it can be called from handleCancel
 	h.userStore.Set(ctx.EffectiveUser.Id, introCtxDataKeyCancelFunc, cancelTyping)
@@ -162,7 +175,7 @@ func (h *introHandler) processIntroSearch(b *gotgbot.Bot, ctx *ext.Context) erro
 	h.messageSenderService.SendTypingAction(msg.Chat.Id)
 
 	// Get messages from Intro topic
-	messages, err := clients.GetChatMessages(h.config.SuperGroupChatID, h.config.IntroTopicID)
+	messages, err := h.tgUserClient.GetChatMessages(typingCtx, h.config.SuperGroupChatID, h.config.IntroTopicID)
 	if err != nil {
 		h.messageSenderService.Reply(msg, "Произошла ошибка при получении сообщений из чата.", nil)
 		log.Printf("%s: Error during messages retrieval: %v", utils.GetCurrentTypeName(), err)
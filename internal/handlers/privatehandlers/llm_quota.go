@@ -0,0 +1,24 @@
+package privatehandlers
+
+import (
+	"evo-bot-go/internal/services"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// llmQuotaExceeded checks the LLM quota of the user and the monthly budget.
+// When the request is not allowed it replies to the message with the reason and returns true.
+func llmQuotaExceeded(
+	llmUsageService *services.LLMUsageService,
+	messageSenderService *services.MessageSenderService,
+	msg *gotgbot.Message,
+	userID int64,
+) bool {
+	allowed, quotaMessage := llmUsageService.CheckQuota(userID)
+	if allowed {
+		return false
+	}
+
+	messageSenderService.Reply(msg, quotaMessage, nil)
+	return true
+}
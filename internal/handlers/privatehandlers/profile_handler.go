@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"evo-bot-go/internal/buttons"
 	"evo-bot-go/internal/clients"
 	"evo-bot-go/internal/config"
@@ -16,6 +17,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -35,9 +37,13 @@ const (
 	profileStateAwaitBio                  = "profile_state_await_bio"
 	profileStateAwaitFirstname            = "profile_state_await_firstname"
 	profileStateAwaitLastname             = "profile_state_await_lastname"
+	profileStateAwaitTimezone             = "profile_state_await_timezone"
+	profileStateAwaitOptionalField        = "profile_state_await_optional_field"
+	profileStateAwaitPhoto                = "profile_state_await_photo"
 
 	// UserStore keys
 	profileCtxDataKeyField                   = "profile_ctx_data_field"
+	profileCtxDataKeyOptionalField           = "profile_ctx_data_optional_field"
 	profileCtxDataKeyPreviousMessageID       = "profile_ctx_data_previous_message_id"
 	profileCtxDataKeyPreviousChatID          = "profile_ctx_data_previous_chat_id"
 	profileCtxDataKeyLastMessageTimeFromUser = "profile_ctx_data_last_message_time_from_user"
@@ -51,6 +57,8 @@ const (
 	profileMenuEditFirstnameHeader  = "Профиль → Редактирование → Имя"
 	profileMenuEditLastnameHeader   = "Профиль → Редактирование → Фамилия"
 	profileMenuEditBioHeader        = "Профиль → Редактирование → О себе"
+	profileMenuEditTimezoneHeader   = "Профиль → Редактирование → Часовой пояс"
+	profileMenuEditPhotoHeader      = "Профиль → Редактирование → Фото"
 	profileMenuPublishHeader        = "Профиль → Публикация"
 	profileMenuSearchHeader         = "Профиль → Поиск"
 	profileMenuBioSearchHeader      = "Профиль → Поиск по биографиям"
@@ -64,10 +72,13 @@ type profileHandler struct {
 	messageSenderService        *services.MessageSenderService
 	permissionsService          *services.PermissionsService
 	profileService              *services.ProfileService
-	userRepository              *repositories.UserRepository
-	profileRepository           *repositories.ProfileRepository
+	scoreService                *services.ScoreService
+	thanksService               *services.ThanksService
+	userRepository              repositories.UserRepository
+	profileRepository           repositories.ProfileRepository
 	promptingTemplateRepository *repositories.PromptingTemplateRepository
 	openaiClient                *clients.OpenAiClient
+	llmUsageService             *services.LLMUsageService
 	userStore                   *utils.UserDataStore
 }
 
@@ -76,20 +87,26 @@ func NewProfileHandler(
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 	profileService *services.ProfileService,
-	userRepository *repositories.UserRepository,
-	profileRepository *repositories.ProfileRepository,
+	scoreService *services.ScoreService,
+	thanksService *services.ThanksService,
+	userRepository repositories.UserRepository,
+	profileRepository repositories.ProfileRepository,
 	promptingTemplateRepository *repositories.PromptingTemplateRepository,
 	openaiClient *clients.OpenAiClient,
+	llmUsageService *services.LLMUsageService,
 ) ext.Handler {
 	h := &profileHandler{
 		config:                      config,
 		messageSenderService:        messageSenderService,
 		permissionsService:          permissionsService,
 		profileService:              profileService,
+		scoreService:                scoreService,
+		thanksService:               thanksService,
 		userRepository:              userRepository,
 		profileRepository:           profileRepository,
 		promptingTemplateRepository: promptingTemplateRepository,
 		openaiClient:                openaiClient,
+		llmUsageService:             llmUsageService,
 		userStore:                   utils.NewUserDataStore(),
 	}
 
@@ -131,6 +148,24 @@ func NewProfileHandler(
 				handlers.NewCallback(callbackquery.Equal(constants.ProfileEditMyProfileCallback), h.handleCallback),
 				handlers.NewCallback(callbackquery.Equal(constants.ProfileFullCancel), h.handleCallbackCancel),
 			},
+			profileStateAwaitTimezone: {
+				handlers.NewMessage(message.Text, h.handleTimezoneInput),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfileEditMyProfileCallback), h.handleCallback),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfileFullCancel), h.handleCallbackCancel),
+			},
+			profileStateAwaitOptionalField: {
+				handlers.NewMessage(message.Text, h.handleOptionalFieldInput),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfileEditMyProfileCallback), h.handleCallback),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfileFullCancel), h.handleCallbackCancel),
+			},
+			profileStateAwaitPhoto: {
+				handlers.NewMessage(message.Photo, h.handlePhotoInput),
+				handlers.NewMessage(message.All, h.handleNotPhotoInput),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfilePhotoFromTelegramCallback), h.handleCallback),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfilePhotoDeleteCallback), h.handleCallback),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfileEditMyProfileCallback), h.handleCallback),
+				handlers.NewCallback(callbackquery.Equal(constants.ProfileFullCancel), h.handleCallbackCancel),
+			},
 		},
 		&handlers.ConversationOpts{
 			Exits: []ext.Handler{handlers.NewCommand(constants.CancelCommand, h.handleCancel)},
@@ -204,6 +239,10 @@ func (h *profileHandler) handleCallback(b *gotgbot.Bot, ctx *ext.Context) error
 	effectiveMsg := ctx.EffectiveMessage
 	userId := callback.From.Id
 
+	if field, ok := strings.CutPrefix(data, constants.ProfileEditFieldPrefix); ok {
+		return h.handleEditOptionalField(b, ctx, effectiveMsg, constants.ProfileField(field))
+	}
+
 	switch data {
 	case constants.ProfileViewMyProfileCallback:
 		return h.handleViewMyProfile(b, ctx, effectiveMsg)
@@ -219,6 +258,17 @@ func (h *profileHandler) handleCallback(b *gotgbot.Bot, ctx *ext.Context) error
 		return h.handleEditField(b, ctx, effectiveMsg, "новое имя", profileStateAwaitFirstname)
 	case constants.ProfileEditLastnameCallback:
 		return h.handleEditField(b, ctx, effectiveMsg, "новую фамилию", profileStateAwaitLastname)
+	case constants.ProfileEditTimezoneCallback:
+		return h.handleEditField(b, ctx, effectiveMsg,
+			"часовой пояс в формате IANA, например <code>Europe/Moscow</code> или <code>Asia/Tbilisi</code> "+
+				"(или <code>-</code>, чтобы использовать часовой пояс клуба)",
+			profileStateAwaitTimezone)
+	case constants.ProfileEditPhotoCallback:
+		return h.handleEditPhoto(b, ctx, effectiveMsg)
+	case constants.ProfilePhotoFromTelegramCallback:
+		return h.handlePhotoFromTelegram(b, ctx, effectiveMsg)
+	case constants.ProfilePhotoDeleteCallback:
+		return h.handlePhotoDelete(b, ctx, effectiveMsg)
 	case constants.ProfilePublishCallback:
 		return h.handlePublishProfile(b, ctx, effectiveMsg, false)
 	case constants.ProfilePublishWithoutPreviewCallback:
@@ -249,6 +299,11 @@ func (h *profileHandler) handleViewMyProfile(b *gotgbot.Bot, ctx *ext.Context, m
 	}
 
 	profileText := fmt.Sprintf("<b>%s</b>\n\n%s", profileMenuMyProfileHeader, formatters.FormatProfileView(dbUser, profile, true))
+	if thanksStats, err := h.thanksService.GetStats(dbUser.ID); err == nil {
+		profileText += formatters.FormatThanksStats(thanksStats)
+	} else {
+		log.Printf("%s: Error during thanks stats retrieval: %v", utils.GetCurrentTypeName(), err)
+	}
 	editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(msg.Chat.Id, profileText,
 		&gotgbot.SendMessageOpts{
 			ReplyMarkup: buttons.ProfileEditBackCancelButtons(constants.ProfileStartCallback),
@@ -400,6 +455,13 @@ func (h *profileHandler) handleEditField(b *gotgbot.Bot, ctx *ext.Context, msg *
 	case profileStateAwaitLastname:
 		oldFieldValue = "Текущее значение: <code>" + dbUser.Lastname + "</code>"
 		menuHeader = profileMenuEditLastnameHeader
+	case profileStateAwaitTimezone:
+		if dbUser.Timezone != "" {
+			oldFieldValue = "Текущее значение: <code>" + dbUser.Timezone + "</code>"
+		} else {
+			oldFieldValue = "Текущее значение: часовой пояс клуба (<code>" + h.config.ClubTimezone.String() + "</code>)"
+		}
+		menuHeader = profileMenuEditTimezoneHeader
 	}
 
 	if oldFieldValue == "" || oldFieldValue == " " {
@@ -424,6 +486,104 @@ func (h *profileHandler) handleEditField(b *gotgbot.Bot, ctx *ext.Context, msg *
 	return handlers.NextConversationState(nextState)
 }
 
+// Starts the process of editing an optional profile field, like the location or the skills
+func (h *profileHandler) handleEditOptionalField(b *gotgbot.Bot, ctx *ext.Context, msg *gotgbot.Message, field constants.ProfileField) error {
+	user := ctx.Update.CallbackQuery.From
+	if !slices.Contains(constants.AllProfileFields, field) {
+		return fmt.Errorf("%s: unknown profile field %q in handleEditOptionalField", utils.GetCurrentTypeName(), field)
+	}
+
+	h.userStore.Set(user.Id, profileCtxDataKeyOptionalField, field)
+
+	dbUser, err := h.userRepository.GetOrCreate(&user)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user in handleEditOptionalField: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	dbProfile, err := h.profileRepository.GetOrCreate(dbUser.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get/create profile in handleEditOptionalField: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	oldFieldValue := "отсутствует"
+	if value := formatters.FormatProfileFieldValue(dbProfile, field); value != "" {
+		oldFieldValue = "<pre>" + value + "</pre>"
+	}
+
+	h.RemovePreviousMessage(b, &user.Id)
+	editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+		msg.Chat.Id,
+		fmt.Sprintf("<b>%s → %s</b>", profileMenuEditHeader, formatters.ProfileFieldTitles[field])+
+			fmt.Sprintf("\n\nТекущее значение: %s", oldFieldValue)+
+			fmt.Sprintf("\n\n%s", formatters.FormatProfileFieldPrompt(field)),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ProfileBackCancelButtons(constants.ProfileEditMyProfileCallback),
+		})
+
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in handleEditOptionalField: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(user.Id, editedMsg)
+	return handlers.NextConversationState(profileStateAwaitOptionalField)
+}
+
+// Optional profile field handler
+func (h *profileHandler) handleOptionalFieldInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	storedField, _ := h.userStore.Get(msg.From.Id, profileCtxDataKeyOptionalField)
+	field, _ := storedField.(constants.ProfileField)
+	menuHeader := fmt.Sprintf("%s → %s", profileMenuEditHeader, formatters.ProfileFieldTitles[field])
+
+	dbUser, err := h.userRepository.GetOrCreate(ctx.EffectiveUser)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user in handleOptionalFieldInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	profile, err := h.profileRepository.GetOrCreate(dbUser.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get/create profile in handleOptionalFieldInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	err = h.profileService.UpdateProfileField(profile.ID, field, msg.Text)
+	if errors.Is(err, services.ErrInvalidProfileFieldValue) {
+		h.RemovePreviousMessage(b, &msg.From.Id)
+		b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+		errMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s</b>", menuHeader)+
+				"\n\n⚠️ Не получилось разобрать ответ, проверь формат и пришли снова."+
+				fmt.Sprintf("\n\n%s", formatters.FormatProfileFieldPrompt(field)),
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ProfileBackCancelButtons(constants.ProfileEditMyProfileCallback),
+			})
+
+		h.SavePreviousMessageInfo(msg.From.Id, errMsg)
+		return nil
+	}
+	if err != nil {
+		_ = h.messageSenderService.ReplyHtml(msg,
+			fmt.Sprintf("<b>%s</b>", menuHeader)+
+				"\n\nПроизошла ошибка при сохранении.", nil)
+		return fmt.Errorf("%s: failed to save %s in handleOptionalFieldInput: %w", utils.GetCurrentTypeName(), field, err)
+	}
+
+	h.RemovePreviousMessage(b, &msg.From.Id)
+	b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+	sendMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(msg.Chat.Id,
+		fmt.Sprintf("<b>%s</b>", menuHeader)+
+			"\n\n✅ Сохранено!",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ProfileBackPublishCancelButtons(constants.ProfileEditMyProfileCallback),
+		})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in handleOptionalFieldInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(msg.From.Id, sendMsg)
+	return handlers.NextConversationState(profileStateEditMyProfile)
+}
+
 // Bio handler
 func (h *profileHandler) handleBioInput(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
@@ -569,6 +729,220 @@ func (h *profileHandler) handleLastnameInput(b *gotgbot.Bot, ctx *ext.Context) e
 	return handlers.NextConversationState(profileStateEditMyProfile)
 }
 
+// Timezone handler
+func (h *profileHandler) handleTimezoneInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	timezone := strings.TrimSpace(msg.Text)
+
+	// "-" resets the timezone to the club one
+	if timezone == "-" {
+		timezone = ""
+	} else {
+		loc, err := utils.ParseTimezone(timezone)
+		if err != nil {
+			h.RemovePreviousMessage(b, &msg.From.Id)
+			b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+			errMsg, _ := h.messageSenderService.SendHtmlWithReturnMessage(
+				msg.Chat.Id,
+				fmt.Sprintf("<b>%s</b>", profileMenuEditTimezoneHeader)+
+					"\n\nНе удалось распознать часовой пояс. Введи его в формате IANA, например <code>Europe/Moscow</code>:",
+				&gotgbot.SendMessageOpts{
+					ReplyMarkup: buttons.ProfileBackCancelButtons(constants.ProfileEditMyProfileCallback),
+				})
+
+			h.SavePreviousMessageInfo(msg.From.Id, errMsg)
+			return nil
+		}
+		timezone = loc.String()
+	}
+
+	dbUser, err := h.userRepository.GetOrCreate(ctx.EffectiveUser)
+	if err == nil {
+		err = h.userRepository.UpdateTimezone(dbUser.ID, timezone)
+	}
+	if err != nil {
+		_ = h.messageSenderService.ReplyHtml(msg,
+			fmt.Sprintf("<b>%s</b>", profileMenuEditTimezoneHeader)+
+				"\n\nПроизошла ошибка при сохранении часового пояса.", nil)
+		return fmt.Errorf("%s: failed to save timezone in handleTimezoneInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	displayTimezone := timezone
+	if displayTimezone == "" {
+		displayTimezone = h.config.ClubTimezone.String()
+	}
+
+	h.RemovePreviousMessage(b, &msg.From.Id)
+	b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+	sendMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(msg.Chat.Id,
+		fmt.Sprintf("<b>%s</b>", profileMenuEditTimezoneHeader)+
+			fmt.Sprintf("\n\n✅ Часовой пояс сохранён: <code>%s</code>. Время мероприятий теперь будет показано в нём.", displayTimezone),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ProfileBackPublishCancelButtons(constants.ProfileEditMyProfileCallback),
+		})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in handleTimezoneInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(msg.From.Id, sendMsg)
+	return handlers.NextConversationState(profileStateEditMyProfile)
+}
+
+// handleEditPhoto shows the current profile photo and asks for a new one
+func (h *profileHandler) handleEditPhoto(b *gotgbot.Bot, ctx *ext.Context, msg *gotgbot.Message) error {
+	user := ctx.Update.CallbackQuery.From
+
+	dbUser, err := h.userRepository.GetOrCreate(&user)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user in handleEditPhoto: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	dbProfile, err := h.profileRepository.GetOrCreate(dbUser.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get/create profile in handleEditPhoto: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	hasPhoto := dbProfile.PhotoFileID != ""
+	currentPhoto := "Текущее фото: отсутствует"
+	if hasPhoto {
+		currentPhoto = "Текущее фото — выше."
+	}
+	text := fmt.Sprintf("<b>%s</b>", profileMenuEditPhotoHeader) +
+		fmt.Sprintf("\n\n%s", currentPhoto) +
+		fmt.Sprintf("\n\nПришли фото, и оно будет опубликовано вместе с твоим профилем в канале \"<a href='%s'>Интро</a>\". ", utils.GetIntroTopicLink(h.config)) +
+		"Фото нужно отправить именно как фото, а не файлом." +
+		"\n\nТакже можно взять текущую аватарку из Telegram: по нажатию на кнопку бот скопирует её, " +
+		"и после публикации профиля её увидят все участники клуба."
+
+	h.RemovePreviousMessage(b, &user.Id)
+	var editedMsg *gotgbot.Message
+	if hasPhoto {
+		editedMsg, err = h.messageSenderService.SendPhotoWithReturnMessage(
+			msg.Chat.Id,
+			gotgbot.InputFileByID(dbProfile.PhotoFileID),
+			&gotgbot.SendPhotoOpts{
+				Caption:     text,
+				ParseMode:   "HTML",
+				ReplyMarkup: buttons.ProfilePhotoButtons(true),
+			})
+	} else {
+		editedMsg, err = h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			text,
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ProfilePhotoButtons(false),
+			})
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in handleEditPhoto: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(user.Id, editedMsg)
+	return handlers.NextConversationState(profileStateAwaitPhoto)
+}
+
+// handlePhotoInput saves the photo uploaded by the user as the profile photo
+func (h *profileHandler) handlePhotoInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// The last size is the largest one
+	fileID := msg.Photo[len(msg.Photo)-1].FileId
+	if err := h.saveProfileField(msg.From, "photo_file_id", fileID); err != nil {
+		_ = h.messageSenderService.ReplyHtml(msg,
+			fmt.Sprintf("<b>%s</b>", profileMenuEditPhotoHeader)+
+				"\n\nПроизошла ошибка при сохранении фото.", nil)
+		return fmt.Errorf("%s: failed to save photo in handlePhotoInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+	return h.showPhotoSaved(b, msg.Chat.Id, msg.From.Id, "✅ Фото сохранено!")
+}
+
+// handleNotPhotoInput reminds that the profile photo is expected
+func (h *profileHandler) handleNotPhotoInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	h.RemovePreviousMessage(b, &msg.From.Id)
+	b.DeleteMessage(msg.Chat.Id, msg.MessageId, nil)
+	errMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+		msg.Chat.Id,
+		fmt.Sprintf("<b>%s</b>", profileMenuEditPhotoHeader)+
+			"\n\n⚠️ Это не фото. Пришли изображение как фото (не файлом) или возьми аватарку из Telegram.",
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ProfilePhotoButtons(false),
+		})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in handleNotPhotoInput: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(msg.From.Id, errMsg)
+	return nil
+}
+
+// handlePhotoFromTelegram copies the current Telegram avatar of the user to the profile photo
+func (h *profileHandler) handlePhotoFromTelegram(b *gotgbot.Bot, ctx *ext.Context, msg *gotgbot.Message) error {
+	user := ctx.Update.CallbackQuery.From
+
+	photos, err := b.GetUserProfilePhotos(user.Id, &gotgbot.GetUserProfilePhotosOpts{Limit: 1})
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user profile photos in handlePhotoFromTelegram: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	if len(photos.Photos) == 0 || len(photos.Photos[0]) == 0 {
+		h.RemovePreviousMessage(b, &user.Id)
+		errMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s</b>", profileMenuEditPhotoHeader)+
+				"\n\n⚠️ Не нашёл аватарку в твоём Telegram, возможно, она скрыта настройками приватности. Пришли фото сюда.",
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ProfilePhotoButtons(false),
+			})
+		if err != nil {
+			return fmt.Errorf("%s: failed to send message in handlePhotoFromTelegram: %w", utils.GetCurrentTypeName(), err)
+		}
+
+		h.SavePreviousMessageInfo(user.Id, errMsg)
+		return nil
+	}
+
+	sizes := photos.Photos[0]
+	if err := h.saveProfileField(&user, "photo_file_id", sizes[len(sizes)-1].FileId); err != nil {
+		return fmt.Errorf("%s: failed to save photo in handlePhotoFromTelegram: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return h.showPhotoSaved(b, msg.Chat.Id, user.Id, "✅ Аватарка из Telegram сохранена как фото профиля!")
+}
+
+// handlePhotoDelete removes the profile photo, the profile is published as text again
+func (h *profileHandler) handlePhotoDelete(b *gotgbot.Bot, ctx *ext.Context, msg *gotgbot.Message) error {
+	user := ctx.Update.CallbackQuery.From
+
+	if err := h.saveProfileField(&user, "photo_file_id", ""); err != nil {
+		return fmt.Errorf("%s: failed to delete photo in handlePhotoDelete: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return h.showPhotoSaved(b, msg.Chat.Id, user.Id, "🗑 Фото удалено.")
+}
+
+// showPhotoSaved confirms the change of the profile photo and offers to publish the profile
+func (h *profileHandler) showPhotoSaved(b *gotgbot.Bot, chatID int64, userID int64, result string) error {
+	h.RemovePreviousMessage(b, &userID)
+	sendMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(chatID,
+		fmt.Sprintf("<b>%s</b>", profileMenuEditPhotoHeader)+
+			fmt.Sprintf("\n\n%s", result)+
+			fmt.Sprintf(" Изменения появятся в канале \"<a href='%s'>Интро</a>\" после публикации профиля.", utils.GetIntroTopicLink(h.config)),
+		&gotgbot.SendMessageOpts{
+			ReplyMarkup: buttons.ProfileBackPublishCancelButtons(constants.ProfileEditMyProfileCallback),
+		})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send message in showPhotoSaved: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	h.SavePreviousMessageInfo(userID, sendMsg)
+	return handlers.NextConversationState(profileStateEditMyProfile)
+}
+
 // handlePublishProfile publishes the user's profile to the intro topic
 func (h *profileHandler) handlePublishProfile(b *gotgbot.Bot, ctx *ext.Context, msg *gotgbot.Message, withoutPreview bool) error {
 	user := ctx.Update.CallbackQuery.From
@@ -622,64 +996,53 @@ func (h *profileHandler) handlePublishProfile(b *gotgbot.Bot, ctx *ext.Context,
 		return handlers.NextConversationState(profileStateViewOptions)
 	}
 
-	// Format profile text for publishing
-	publicMessageText := formatters.FormatPublicProfileForMessage(dbUser, profile, false)
-
-	var publishedMsg *gotgbot.Message
-	// Check if we need to update existing message or create a new one
-	if profile.PublishedMessageID.Valid {
-		// Try to edit existing message
-		_, _, err := b.EditMessageText(
-			publicMessageText,
-			&gotgbot.EditMessageTextOpts{
-				ChatId:    utils.ChatIdToFullChatId(h.config.SuperGroupChatID),
-				MessageId: profile.PublishedMessageID.Int64,
-				ParseMode: "HTML",
-				LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
-					IsDisabled: withoutPreview,
-				},
+	if excess := formatters.PublicProfileExcessLength(dbUser, profile); excess > 0 {
+		h.RemovePreviousMessage(b, &user.Id)
+		editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s</b>", profileMenuPublishHeader)+
+				"\n\n⚠️ Твой профиль не помещается в одно сообщение Telegram. "+
+				fmt.Sprintf("\n\nСократи биографию, навыки или ссылки на %d символов и опубликуй снова.", excess),
+			&gotgbot.SendMessageOpts{
+				ReplyMarkup: buttons.ProfileEditBackCancelButtons(constants.ProfileStartCallback),
 			})
-		// If editing fails, create a new message if the error is not about the message being exactly the same
-		if err != nil && !strings.Contains(err.Error(), "are exactly the same") {
-			publishedMsg, err = h.messageSenderService.SendHtmlWithReturnMessage(
-				utils.ChatIdToFullChatId(h.config.SuperGroupChatID),
-				publicMessageText,
-				&gotgbot.SendMessageOpts{
-					MessageThreadId: int64(h.config.IntroTopicID),
-					LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
-						IsDisabled: withoutPreview,
-					},
-				})
-			if err != nil {
-				return fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
-			}
-		} else {
-			// Message updated successfully, store the message ID for database update
-			messageID := profile.PublishedMessageID.Int64
-			publishedMsg = &gotgbot.Message{
-				MessageId: messageID,
-			}
+
+		if err != nil {
+			return fmt.Errorf("%s: failed to send message in handlePublishProfile: %w", utils.GetCurrentTypeName(), err)
 		}
-	} else {
-		// Create a new message
-		publishedMsg, err = h.messageSenderService.SendHtmlWithReturnMessage(
-			utils.ChatIdToFullChatId(h.config.SuperGroupChatID),
-			publicMessageText,
+
+		h.SavePreviousMessageInfo(user.Id, editedMsg)
+		return handlers.NextConversationState(profileStateViewOptions)
+	}
+
+	publishedMessageID, err := h.profileService.PublishProfile(dbUser, profile, withoutPreview)
+	if errors.Is(err, services.ErrProfileFollowUpNotPublished) {
+		log.Printf("%s: %v", utils.GetCurrentTypeName(), err)
+		h.RemovePreviousMessage(b, &user.Id)
+		editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
+			msg.Chat.Id,
+			fmt.Sprintf("<b>%s</b>", profileMenuPublishHeader)+
+				fmt.Sprintf("\n\n⚠️ Фото с началом профиля опубликовано в канале \"<a href='%s'>Интро</a>\", но продолжение отправить не удалось. ",
+					utils.GetIntroMessageLink(h.config, publishedMessageID))+
+				"\n\nПопробуй опубликовать профиль ещё раз.",
 			&gotgbot.SendMessageOpts{
-				MessageThreadId: int64(h.config.IntroTopicID),
-				LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
-					IsDisabled: withoutPreview,
-				},
+				ReplyMarkup: buttons.ProfileEditBackCancelButtons(constants.ProfileStartCallback),
 			})
+
 		if err != nil {
-			return fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
+			return fmt.Errorf("%s: failed to send message in handlePublishProfile: %w", utils.GetCurrentTypeName(), err)
 		}
-	}
 
-	// Update profile with the published message ID
-	err = h.profileRepository.UpdatePublishedMessageID(profile.ID, publishedMsg.MessageId)
+		h.SavePreviousMessageInfo(user.Id, editedMsg)
+		return handlers.NextConversationState(profileStateViewOptions)
+	}
 	if err != nil {
-		return fmt.Errorf("%s: failed to update published message ID: %w", utils.GetCurrentTypeName(), err)
+		return fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// Award karma for the first profile publication
+	if _, err := h.scoreService.Award(dbUser.ID, constants.ScoreReasonProfilePublished, "profile"); err != nil {
+		log.Printf("%s: Error during karma award for profile: %v", utils.GetCurrentTypeName(), err)
 	}
 
 	// Show success message
@@ -687,7 +1050,7 @@ func (h *profileHandler) handlePublishProfile(b *gotgbot.Bot, ctx *ext.Context,
 	editedMsg, err := h.messageSenderService.SendHtmlWithReturnMessage(
 		msg.Chat.Id,
 		fmt.Sprintf("<b>%s</b>", profileMenuPublishHeader)+
-			fmt.Sprintf("\n\n✅ Твой профиль успешно опубликован в канале \"<a href='%s'>Интро</a>\"!", utils.GetIntroMessageLink(h.config, profile.PublishedMessageID.Int64)),
+			fmt.Sprintf("\n\n✅ Твой профиль успешно опубликован в канале \"<a href='%s'>Интро</a>\"!", utils.GetIntroMessageLink(h.config, publishedMessageID)),
 		&gotgbot.SendMessageOpts{
 			ReplyMarkup: buttons.ProfileBackCancelButtons(constants.ProfileStartCallback),
 		})
@@ -856,11 +1219,18 @@ func (h *profileHandler) handleBioSearchInput(b *gotgbot.Bot, ctx *ext.Context)
 		return nil // Stay in the same state
 	}
 
+	if llmQuotaExceeded(h.llmUsageService, h.messageSenderService, msg, ctx.EffectiveUser.Id) {
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
 	// Mark as processing
 	h.userStore.Set(ctx.EffectiveUser.Id, profileCtxDataKeyProcessing, true)
 
 	// Create a cancellable context for this operation
-	typingCtx, cancelTyping := context.WithCancel(context.Background())
+	llmCtx := services.WithLLMCaller(context.Background(), ctx.EffectiveUser.Id, constants.LLMFeatureProfileSearch)
+	typingCtx, cancelTyping := context.WithCancel(llmCtx)
 
 	// Store cancel function in user store so it can be called from handleCancel
 	h.userStore.Set(ctx.EffectiveUser.Id, profileCtxDataKeyCancelFunc, cancelTyping)
@@ -962,21 +1332,37 @@ func (h *profileHandler) handleBioSearchInput(b *gotgbot.Bot, ctx *ext.Context)
 
 func (h *profileHandler) prepareProfilesData(profiles []repositories.ProfileWithUser) ([]byte, error) {
 	type ProfileData struct {
-		ID          int    `json:"id"`
-		FirstName   string `json:"first_name"`
-		LastName    string `json:"last_name"`
-		Username    string `json:"username,omitempty"`
-		Bio         string `json:"bio"`
+		ID          int      `json:"id"`
+		FirstName   string   `json:"first_name"`
+		LastName    string   `json:"last_name"`
+		Username    string   `json:"username,omitempty"`
+		Bio         string   `json:"bio"`
+		City        string   `json:"city,omitempty"`
+		Country     string   `json:"country,omitempty"`
+		JobTitle    string   `json:"job_title,omitempty"`
+		Company     string   `json:"company,omitempty"`
+		Skills      []string `json:"skills,omitempty"`
+		CanHelpWith []string `json:"can_help_with,omitempty"`
+		LookingFor  []string `json:"looking_for,omitempty"`
+		Languages   []string `json:"languages,omitempty"`
 	}
 
 	profileObjects := make([]ProfileData, 0, len(profiles))
 	for _, profileWithUser := range profiles {
 		profileObjects = append(profileObjects, ProfileData{
-			ID:        profileWithUser.Profile.ID,
-			FirstName: profileWithUser.User.Firstname,
-			LastName:  profileWithUser.User.Lastname,
-			Username:  profileWithUser.User.TgUsername,
-			Bio:       profileWithUser.Profile.Bio,
+			ID:          profileWithUser.Profile.ID,
+			FirstName:   profileWithUser.User.Firstname,
+			LastName:    profileWithUser.User.Lastname,
+			Username:    profileWithUser.User.TgUsername,
+			Bio:         profileWithUser.Profile.Bio,
+			City:        profileWithUser.Profile.City,
+			Country:     profileWithUser.Profile.Country,
+			JobTitle:    profileWithUser.Profile.JobTitle,
+			Company:     profileWithUser.Profile.Company,
+			Skills:      profileWithUser.Profile.Skills,
+			CanHelpWith: profileWithUser.Profile.CanHelpWith,
+			LookingFor:  profileWithUser.Profile.LookingFor,
+			Languages:   profileWithUser.Profile.Languages,
 		})
 	}
 
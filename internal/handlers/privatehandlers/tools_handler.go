@@ -43,6 +43,8 @@ const (
 type toolsHandler struct {
 	config                      *config.Config
 	openaiClient                *clients.OpenAiClient
+	llmUsageService             *services.LLMUsageService
+	tgUserClient                clients.TgUserClient
 	promptingTemplateRepository *repositories.PromptingTemplateRepository
 	messageSenderService        *services.MessageSenderService
 	userStore                   *utils.UserDataStore
@@ -52,6 +54,8 @@ type toolsHandler struct {
 func NewToolsHandler(
 	config *config.Config,
 	openaiClient *clients.OpenAiClient,
+	llmUsageService *services.LLMUsageService,
+	tgUserClient clients.TgUserClient,
 	messageSenderService *services.MessageSenderService,
 	promptingTemplateRepository *repositories.PromptingTemplateRepository,
 	permissionsService *services.PermissionsService,
@@ -59,6 +63,8 @@ func NewToolsHandler(
 	h := &toolsHandler{
 		config:                      config,
 		openaiClient:                openaiClient,
+		llmUsageService:             llmUsageService,
+		tgUserClient:                tgUserClient,
 		promptingTemplateRepository: promptingTemplateRepository,
 		messageSenderService:        messageSenderService,
 		userStore:                   utils.NewUserDataStore(),
@@ -133,11 +139,18 @@ func (h *toolsHandler) processToolSearch(b *gotgbot.Bot, ctx *ext.Context) error
 		return nil // Stay in the same state
 	}
 
+	if llmQuotaExceeded(h.llmUsageService, h.messageSenderService, msg, ctx.EffectiveUser.Id) {
+		h.MessageRemoveInlineKeyboard(b, &ctx.EffectiveUser.Id)
+		h.userStore.Clear(ctx.EffectiveUser.Id)
+		return handlers.EndConversation()
+	}
+
 	// Mark as processing
 	h.userStore.Set(ctx.EffectiveUser.Id, toolsUserCtxDataKeyProcessing, true)
 
 	// Create a cancellable context for this operation
-	typingCtx, cancelTyping := context.WithCancel(context.Background())
+	llmCtx := services.WithLLMCaller(context.Background(), ctx.EffectiveUser.Id, constants.LLMFeatureTools)
+	typingCtx, cancelTyping := context.WithCancel(llmCtx)
 
 	// Store cancel function in user store so it can be called from handleCancel
 	h.userStore.Set(ctx.EffectiveUser.Id, toolsUserCtxDataKeyCancelFunc, cancelTyping)
@@ -159,7 +172,7 @@ func (h *toolsHandler) processToolSearch(b *gotgbot.Bot, ctx *ext.Context) error
 	h.messageSenderService.SendTypingAction(msg.Chat.Id)
 
 	// Get messages from chat
-	messages, err := clients.GetChatMessages(h.config.SuperGroupChatID, h.config.ToolTopicID)
+	messages, err := h.tgUserClient.GetChatMessages(typingCtx, h.config.SuperGroupChatID, h.config.ToolTopicID)
 	if err != nil {
 		h.messageSenderService.Reply(msg, "Произошла ошибка при получении сообщений из чата.", nil)
 		log.Printf("%s: Error during messages retrieval: %v", utils.GetCurrentTypeName(), err)
@@ -0,0 +1,83 @@
+package privatehandlers
+
+import (
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+	"log"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+type topHandler struct {
+	config               *config.Config
+	scoreService         *services.ScoreService
+	messageSenderService *services.MessageSenderService
+	permissionsService   *services.PermissionsService
+}
+
+func NewTopHandler(
+	config *config.Config,
+	scoreService *services.ScoreService,
+	messageSenderService *services.MessageSenderService,
+	permissionsService *services.PermissionsService,
+) ext.Handler {
+	h := &topHandler{
+		config:               config,
+		scoreService:         scoreService,
+		messageSenderService: messageSenderService,
+		permissionsService:   permissionsService,
+	}
+
+	return handlers.NewCommand(constants.TopCommand, h.handleCommand)
+}
+
+func (h *topHandler) handleCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+
+	// Only proceed if this is a private chat
+	if !h.permissionsService.CheckPrivateChatType(msg) {
+		return nil
+	}
+
+	// Check if user is a club member
+	if !h.permissionsService.CheckClubMemberPermissions(msg, constants.TopCommand) {
+		return nil
+	}
+
+	leaders, err := h.scoreService.GetLeaderboard()
+	if err != nil {
+		h.messageSenderService.Reply(msg, "Ошибка при получении рейтинга участников.", nil)
+		log.Printf("%s: Error during leaderboard retrieval: %v", utils.GetCurrentTypeName(), err)
+		return nil
+	}
+
+	text := formatters.FormatScoreLeaderboard(leaders)
+
+	// Add the member's own karma and its latest changes
+	user, err := h.scoreService.GetUserByTelegramID(ctx.EffectiveUser.Id)
+	if err == nil {
+		rank, err := h.scoreService.GetRank(user.ID)
+		if err != nil {
+			log.Printf("%s: Error during rank retrieval: %v", utils.GetCurrentTypeName(), err)
+		}
+
+		history, err := h.scoreService.GetHistory(user.ID)
+		if err != nil {
+			log.Printf("%s: Error during score history retrieval: %v", utils.GetCurrentTypeName(), err)
+		}
+
+		text += "\n" + formatters.FormatScoreHistory(user, rank, history)
+	}
+
+	text += "\n<i>Карма начисляется за темы к мероприятиям, участие во встречах и Random Coffee, " +
+		"публикацию профиля и благодарности от других участников. Раз в месяц карма немного снижается.</i>"
+
+	h.messageSenderService.ReplyHtml(msg, text, nil)
+
+	return nil
+}
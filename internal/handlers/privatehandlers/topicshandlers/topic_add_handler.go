@@ -41,7 +41,9 @@ const (
 type topicAddHandler struct {
 	config               *config.Config
 	topicRepository      *repositories.TopicRepository
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	userRepository       repositories.UserRepository
+	scoreService         *services.ScoreService
 	messageSenderService *services.MessageSenderService
 	userStore            *utils.UserDataStore
 	permissionsService   *services.PermissionsService
@@ -50,7 +52,9 @@ type topicAddHandler struct {
 func NewTopicAddHandler(
 	config *config.Config,
 	topicRepository *repositories.TopicRepository,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	userRepository repositories.UserRepository,
+	scoreService *services.ScoreService,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
@@ -58,6 +62,8 @@ func NewTopicAddHandler(
 		config:               config,
 		topicRepository:      topicRepository,
 		eventRepository:      eventRepository,
+		userRepository:       userRepository,
+		scoreService:         scoreService,
 		messageSenderService: messageSenderService,
 		userStore:            utils.NewUserDataStore(),
 		permissionsService:   permissionsService,
@@ -110,10 +116,17 @@ func (h *topicAddHandler) startTopicAdd(b *gotgbot.Bot, ctx *ext.Context) error
 		return handlers.EndConversation()
 	}
 
+	// Show event times in the member's own timezone
+	loc := h.config.ClubTimezone
+	if user, err := h.userRepository.GetByTelegramID(ctx.EffectiveUser.Id); err == nil {
+		loc = utils.ResolveLocation(user.Timezone, h.config.ClubTimezone)
+	}
+
 	// Format and display event list for selection
 	formattedEvents := formatters.FormatEventListForTopicsView(
 		events,
 		fmt.Sprintf("Выбери ID мероприятия, к которому ты хочешь закинуть темы или вопросы"),
+		loc,
 	)
 
 	sentMsg, _ := h.messageSenderService.ReplyMarkdownWithReturnMessage(
@@ -209,13 +222,22 @@ func (h *topicAddHandler) handleTopicEntry(b *gotgbot.Bot, ctx *ext.Context) err
 	}
 
 	// Create the new topic
-	_, err := h.topicRepository.CreateTopic(topicText, userNickname, eventID)
+	topicID, err := h.topicRepository.CreateTopic(topicText, userNickname, eventID)
 	if err != nil {
 		h.messageSenderService.Reply(msg, "Ой! Что-то пошло не так...", nil)
 		log.Printf("%s: Error during topic creation in database: %v", utils.GetCurrentTypeName(), err)
 		return handlers.EndConversation()
 	}
 
+	// Award karma for the proposed topic
+	if _, err := h.scoreService.AwardTelegramUser(
+		ctx.EffectiveUser,
+		constants.ScoreReasonTopicProposed,
+		fmt.Sprintf("topic:%d", topicID),
+	); err != nil {
+		log.Printf("%s: Error during karma award for topic: %v", utils.GetCurrentTypeName(), err)
+	}
+
 	// Send notification to admin about new topic
 	eventName, _ := h.userStore.Get(ctx.EffectiveUser.Id, topicAddCtxDataKeySelectedEventName)
 	adminChatID := h.config.AdminUserID
@@ -38,7 +38,8 @@ const (
 type topicsHandler struct {
 	config               *config.Config
 	topicRepository      *repositories.TopicRepository
-	eventRepository      *repositories.EventRepository
+	eventRepository      repositories.EventRepository
+	userRepository       repositories.UserRepository
 	messageSenderService *services.MessageSenderService
 	userStore            *utils.UserDataStore
 	permissionsService   *services.PermissionsService
@@ -47,7 +48,8 @@ type topicsHandler struct {
 func NewTopicsHandler(
 	config *config.Config,
 	topicRepository *repositories.TopicRepository,
-	eventRepository *repositories.EventRepository,
+	eventRepository repositories.EventRepository,
+	userRepository repositories.UserRepository,
 	messageSenderService *services.MessageSenderService,
 	permissionsService *services.PermissionsService,
 ) ext.Handler {
@@ -55,6 +57,7 @@ func NewTopicsHandler(
 		config:               config,
 		topicRepository:      topicRepository,
 		eventRepository:      eventRepository,
+		userRepository:       userRepository,
 		messageSenderService: messageSenderService,
 		userStore:            utils.NewUserDataStore(),
 		permissionsService:   permissionsService,
@@ -103,10 +106,17 @@ func (h *topicsHandler) startTopics(b *gotgbot.Bot, ctx *ext.Context) error {
 		return handlers.EndConversation()
 	}
 
+	// Show event times in the member's own timezone
+	loc := h.config.ClubTimezone
+	if user, err := h.userRepository.GetByTelegramID(ctx.EffectiveUser.Id); err == nil {
+		loc = utils.ResolveLocation(user.Timezone, h.config.ClubTimezone)
+	}
+
 	// Format and display event list for selection
 	formattedEvents := formatters.FormatEventListForTopicsView(
 		events,
 		fmt.Sprintf("Выбери ID мероприятия, для которого ты хочешь увидеть темы и вопросы"),
+		loc,
 	)
 
 	sentMsg, _ := h.messageSenderService.ReplyMarkdownWithReturnMessage(
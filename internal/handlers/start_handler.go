@@ -5,7 +5,6 @@ import (
 	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/formatters"
 	"evo-bot-go/internal/services"
-	"evo-bot-go/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -69,7 +68,7 @@ func (h *startHandler) handleStart(b *gotgbot.Bot, ctx *ext.Context) error {
 	greeting += "! 🎩"
 
 	// Check if user is a member of the club
-	isClubMember := utils.IsUserClubMember(b, user.Id, h.config)
+	isClubMember := h.permissionsService.IsClubMember(user.Id)
 
 	var message string
 	var inlineKeyboard gotgbot.InlineKeyboardMarkup
@@ -124,7 +123,7 @@ func (h *startHandler) handleCallbackHelp(b *gotgbot.Bot, ctx *ext.Context) erro
 	_, _ = cb.Answer(b, nil)
 
 	user := ctx.EffectiveUser
-	isAdmin := utils.IsUserAdminOrCreator(b, user.Id, h.config)
+	isAdmin := h.permissionsService.IsStaff(user.Id)
 	helpText := formatters.FormatHelpMessage(isAdmin, h.config)
 
 	h.messageSenderService.ReplyHtml(ctx.EffectiveMessage, helpText, nil)
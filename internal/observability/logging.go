@@ -0,0 +1,138 @@
+package observability
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+// loggerCtxDataKey is the key of the update-scoped logger in ext.Context.Data
+const loggerCtxDataKey = "observability_logger"
+
+// SetupLogger makes slog the default logger with the given format ("text" or "json") and level.
+// Output of the standard log package goes through the same handler.
+func SetupLogger(format string, level string) error {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Logger returns the logger with the fields of the update being handled: update ID, handler, user and chat
+func Logger(ctx *ext.Context) *slog.Logger {
+	if ctx != nil && ctx.Data != nil {
+		if logger, ok := ctx.Data[loggerCtxDataKey].(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// instrumentedHandler logs and measures every update handled by the wrapped handler
+type instrumentedHandler struct {
+	ext.Handler
+	name string
+}
+
+// InstrumentHandler wraps the handler to count handled updates, measure their duration
+// and give the handler an update-scoped logger
+func InstrumentHandler(handler ext.Handler) ext.Handler {
+	return &instrumentedHandler{Handler: handler, name: HandlerName(handler)}
+}
+
+func (h *instrumentedHandler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
+	logger := slog.Default().With("update_id", ctx.UpdateId, "handler", h.name)
+	if ctx.EffectiveUser != nil {
+		logger = logger.With("user_id", ctx.EffectiveUser.Id)
+	}
+	if ctx.EffectiveChat != nil {
+		logger = logger.With("chat_id", ctx.EffectiveChat.Id)
+	}
+	if ctx.Data == nil {
+		ctx.Data = make(map[string]interface{})
+	}
+	ctx.Data[loggerCtxDataKey] = logger
+
+	start := time.Now()
+	err := h.Handler.HandleUpdate(b, ctx)
+	duration := time.Since(start)
+
+	status := "ok"
+	if err != nil && !isConversationStateChange(err) && err != ext.EndGroups && err != ext.ContinueGroups {
+		status = "error"
+	}
+	logger.Debug("update handled", "duration", duration, "status", status)
+
+	UpdatesTotal.WithLabelValues(h.name, status).Inc()
+	UpdateDuration.WithLabelValues(h.name).Observe(duration.Seconds())
+	return err
+}
+
+// isConversationStateChange reports whether the error is how a conversation step switches its state
+func isConversationStateChange(err error) bool {
+	_, ok := err.(*handlers.ConversationStateChange)
+	return ok
+}
+
+// HandlerName returns a readable name of the handler: the package, type and method of its response function.
+// Conversations are named after their first entry point.
+func HandlerName(handler ext.Handler) string {
+	// handlers.NewConversation returns a value, but a pointer is a handler too
+	var entryPoints []ext.Handler
+	switch conversation := handler.(type) {
+	case handlers.Conversation:
+		entryPoints = conversation.EntryPoints
+	case *handlers.Conversation:
+		entryPoints = conversation.EntryPoints
+	}
+	if len(entryPoints) > 0 {
+		return "conversation:" + HandlerName(entryPoints[0])
+	}
+
+	value := reflect.ValueOf(handler)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		response := value.FieldByName("Response")
+		if response.IsValid() && response.Kind() == reflect.Func && !response.IsNil() {
+			if fn := runtime.FuncForPC(response.Pointer()); fn != nil {
+				return shortFuncName(fn.Name())
+			}
+		}
+	}
+
+	return handler.Name()
+}
+
+// shortFuncName turns "evo-bot-go/internal/handlers/adminhandlers.(*broadcastHandler).startBroadcast-fm"
+// into "adminhandlers.broadcastHandler.startBroadcast"
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
@@ -0,0 +1,85 @@
+package observability
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testHandler struct{}
+
+func (h *testHandler) startCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	return nil
+}
+
+func TestHandlerName(t *testing.T) {
+	h := &testHandler{}
+	command := handlers.NewCommand("start", h.startCommand)
+	assert.Equal(t, "observability.testHandler.startCommand", HandlerName(command))
+
+	// Conversations are named after the first entry point, both as values and as pointers
+	conversation := handlers.NewConversation([]ext.Handler{command}, nil, nil)
+	assert.Equal(t, "conversation:observability.testHandler.startCommand", HandlerName(conversation))
+	assert.Equal(t, "conversation:observability.testHandler.startCommand", HandlerName(&conversation))
+
+	// Handlers without a response function keep their own name
+	empty := handlers.NewConversation(nil, nil, nil)
+	assert.Equal(t, empty.Name(), HandlerName(empty))
+}
+
+func TestShortFuncName(t *testing.T) {
+	assert.Equal(t, "adminhandlers.broadcastHandler.startBroadcast",
+		shortFuncName("evo-bot-go/internal/handlers/adminhandlers.(*broadcastHandler).startBroadcast-fm"))
+	assert.Equal(t, "tasks.run", shortFuncName("evo-bot-go/internal/tasks.run"))
+}
+
+func TestSetupLogger(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	assert.NoError(t, SetupLogger("json", "debug"))
+	assert.NoError(t, SetupLogger("", "info"))
+	assert.Error(t, SetupLogger("xml", "info"))
+	assert.Error(t, SetupLogger("text", "verbose"))
+}
+
+func TestInstrumentHandler(t *testing.T) {
+	var output bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	bot := &gotgbot.Bot{User: gotgbot.User{Id: 1, IsBot: true}}
+	handlerErr := errors.New("boom")
+	handler := InstrumentHandler(handlers.NewMessage(nil, func(b *gotgbot.Bot, ctx *ext.Context) error {
+		Logger(ctx).Info("inside handler")
+		return handlerErr
+	}))
+
+	ctx := ext.NewContext(bot, &gotgbot.Update{
+		UpdateId: 77,
+		Message: &gotgbot.Message{
+			From: &gotgbot.User{Id: 2001},
+			Chat: gotgbot.Chat{Id: -100123, Type: "supergroup"},
+		},
+	}, nil)
+	require.ErrorIs(t, handler.HandleUpdate(bot, ctx), handlerErr)
+
+	// The handler logs with the fields of the update
+	assert.Contains(t, output.String(), `"msg":"inside handler"`)
+	assert.Contains(t, output.String(), `"update_id":77`)
+	assert.Contains(t, output.String(), `"user_id":2001`)
+	assert.Contains(t, output.String(), `"chat_id":-100123`)
+	assert.Contains(t, output.String(), `"status":"error"`)
+
+	// Outside of a handled update the default logger is used
+	assert.Equal(t, slog.Default(), Logger(nil))
+	assert.Equal(t, slog.Default(), Logger(ext.NewContext(bot, &gotgbot.Update{}, nil)))
+}
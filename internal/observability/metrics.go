@@ -0,0 +1,114 @@
+package observability
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultDurationBuckets are the histogram buckets in seconds used for request and query durations
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// registry holds the metrics of the bot together with the Go runtime and process metrics
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Metrics of the bot, exposed on /metrics in the Prometheus text format
+var (
+	UpdatesTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_updates_total",
+		Help: "Handled Telegram updates by handler and status.",
+	}, []string{"handler", "status"})
+	UpdateDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "evo_bot_update_duration_seconds",
+		Help:    "Time spent handling a Telegram update.",
+		Buckets: DefaultDurationBuckets,
+	}, []string{"handler"})
+
+	LLMRequestsTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_llm_requests_total",
+		Help: "Requests to the LLM API by operation and status.",
+	}, []string{"operation", "status"})
+	LLMRequestDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "evo_bot_llm_request_duration_seconds",
+		Help:    "Latency of LLM API requests.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"operation"})
+	LLMTokensTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_llm_tokens_total",
+		Help: "Tokens used by LLM API requests.",
+	}, []string{"operation", "type"})
+
+	MTProtoCallsTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_mtproto_calls_total",
+		Help: "Calls of the Telegram user client by method and status.",
+	}, []string{"method", "status"})
+	MTProtoCallDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "evo_bot_mtproto_call_duration_seconds",
+		Help:    "Latency of Telegram user client calls.",
+		Buckets: DefaultDurationBuckets,
+	}, []string{"method"})
+	MTProtoFloodWaitsTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_mtproto_flood_waits_total",
+		Help: "FLOOD_WAIT errors returned to the Telegram user client.",
+	}, []string{"method"})
+
+	TaskRunsTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_task_runs_total",
+		Help: "Runs of scheduled tasks by task and status.",
+	}, []string{"task", "status"})
+	TaskRunDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "evo_bot_task_run_duration_seconds",
+		Help:    "Duration of scheduled task runs.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"task"})
+
+	DBQueryDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "evo_bot_db_query_duration_seconds",
+		Help:    "Duration of database queries by operation.",
+		Buckets: DefaultDurationBuckets,
+	}, []string{"operation"})
+	DBQueryErrorsTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "evo_bot_db_query_errors_total",
+		Help: "Failed database queries by operation.",
+	}, []string{"operation"})
+)
+
+// metricsHandler serves the registered metrics in the Prometheus exposition format
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// NewGaugeFunc registers a new gauge whose value is read from the function when the metrics are scraped
+func NewGaugeFunc(name string, help string, value func() float64) {
+	promauto.With(registry).NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, value)
+}
+
+// StatusLabel returns the status label value for the error
+func StatusLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// ObserveDuration adds the time elapsed since start to the histogram
+func ObserveDuration(observer prometheus.Observer, start time.Time) {
+	observer.Observe(time.Since(start).Seconds())
+}
+
+// ObserveTaskRun records the outcome and duration of a scheduled task run
+func ObserveTaskRun(task string, start time.Time, err error) {
+	ObserveDuration(TaskRunDuration.WithLabelValues(task), start)
+	TaskRunsTotal.WithLabelValues(task, StatusLabel(err)).Inc()
+}
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	metricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	return recorder.Body.String()
+}
+
+func TestMetrics_LabelValuesAreEscaped(t *testing.T) {
+	MTProtoFloodWaitsTotal.WithLabelValues("get \"x\" C:\\dir\nпривет").Inc()
+
+	// Only the backslash, the double quote and the line feed are escaped, UTF-8 is kept as is
+	assert.Contains(t, scrapeMetrics(t),
+		`evo_bot_mtproto_flood_waits_total{method="get \"x\" C:\\dir\nпривет"} 1`)
+}
+
+func TestObserveTaskRun(t *testing.T) {
+	ObserveTaskRun("test_task", time.Now(), nil)
+	ObserveTaskRun("test_task", time.Now(), nil)
+	ObserveTaskRun("test_task", time.Now(), errors.New("failed"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(TaskRunsTotal.WithLabelValues("test_task", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(TaskRunsTotal.WithLabelValues("test_task", "error")))
+
+	output := scrapeMetrics(t)
+	assert.Contains(t, output, `evo_bot_task_run_duration_seconds_bucket{task="test_task",le="0.1"} 3`)
+	assert.Contains(t, output, `evo_bot_task_run_duration_seconds_count{task="test_task"} 3`)
+}
+
+func TestNewGaugeFunc(t *testing.T) {
+	NewGaugeFunc("evo_bot_test_size", "Test gauge.", func() float64 { return 42 })
+
+	output := scrapeMetrics(t)
+	assert.Contains(t, output, "# TYPE evo_bot_test_size gauge\nevo_bot_test_size 42\n")
+	assert.Contains(t, output, "go_goroutines", "the Go runtime metrics are exposed too")
+}
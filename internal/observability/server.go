@@ -0,0 +1,105 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// readinessCheckTimeout limits every readiness check, so a hanging dependency cannot hang /readyz
+const readinessCheckTimeout = 5 * time.Second
+
+// ReadinessCheck checks a dependency the bot needs to serve updates
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Server exposes /metrics, /healthz and /readyz over HTTP
+type Server struct {
+	server *http.Server
+	checks []ReadinessCheck
+}
+
+// NewServer creates a new observability server listening on the address
+func NewServer(addr string, checks ...ReadinessCheck) *Server {
+	s := &Server{checks: checks}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler())
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Start starts serving in the background
+func (s *Server) Start() {
+	slog.Info("Starting observability server", "addr", s.server.Addr)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Observability server failed", "error", err)
+		}
+	}()
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// handleHealthz reports that the process is alive
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// handleReadyz runs all readiness checks in parallel and answers 503 if any of them fails
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	results := make(map[string]string, len(s.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ready := true
+
+	for _, check := range s.checks {
+		wg.Add(1)
+		go func(check ReadinessCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+			defer cancel()
+			err := check.Check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				ready = false
+				results[check.Name] = err.Error()
+				slog.Warn("Readiness check failed", "check", check.Name, "error", err)
+			} else {
+				results[check.Name] = "ok"
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ready":  ready,
+		"checks": results,
+	})
+}
@@ -0,0 +1,47 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Readyz(t *testing.T) {
+	failing := errors.New("database is down")
+	server := NewServer("",
+		ReadinessCheck{Name: "bot_api", Check: func(ctx context.Context) error { return nil }},
+		ReadinessCheck{Name: "database", Check: func(ctx context.Context) error { return failing }},
+	)
+
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	var body struct {
+		Ready  bool              `json:"ready"`
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&body))
+	assert.False(t, body.Ready)
+	assert.Equal(t, map[string]string{"bot_api": "ok", "database": "database is down"}, body.Checks)
+
+	recorder = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "ok\n", recorder.Body.String())
+}
+
+func TestServer_Metrics(t *testing.T) {
+	UpdatesTotal.WithLabelValues("test.handler", "ok").Inc()
+
+	recorder := httptest.NewRecorder()
+	NewServer("").server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `evo_bot_updates_total{handler="test.handler",status="ok"} 1`)
+}
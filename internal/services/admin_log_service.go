@@ -0,0 +1,35 @@
+package services
+
+import (
+	"log"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/utils"
+)
+
+// AdminLogService reports automatic and moderation actions to the admin log chat
+type AdminLogService struct {
+	config               *config.Config
+	messageSenderService *MessageSenderService
+}
+
+// NewAdminLogService creates a new admin log service
+func NewAdminLogService(config *config.Config, messageSenderService *MessageSenderService) *AdminLogService {
+	return &AdminLogService{
+		config:               config,
+		messageSenderService: messageSenderService,
+	}
+}
+
+// Log sends the HTML formatted text to the admin log chat
+func (s *AdminLogService) Log(text string) {
+	log.Printf("%s: %s", utils.GetCurrentTypeName(), text)
+
+	if s.config.AdminLogChatID == 0 {
+		return
+	}
+	if err := s.messageSenderService.EnqueueHtmlOrSend(s.config.AdminLogChatID, "🛡 "+text, constants.MessagePriorityNotification, nil); err != nil {
+		log.Printf("%s: Failed to send admin log message: %v", utils.GetCurrentTypeName(), err)
+	}
+}
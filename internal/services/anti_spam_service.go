@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/prompts"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// pendingCaptcha is a captcha challenge that a new member has not answered yet
+type pendingCaptcha struct {
+	chatID       int64
+	user         gotgbot.User
+	messageID    int64
+	answerOption int
+	timer        *time.Timer
+}
+
+// AntiSpamService handles the captcha for new members, spam checks of their first messages and the admin log
+type AntiSpamService struct {
+	config                      *config.Config
+	bot                         *gotgbot.Bot
+	openaiClient                *clients.OpenAiClient
+	messageSenderService        *MessageSenderService
+	adminLogService             *AdminLogService
+	promptingTemplateRepository *repositories.PromptingTemplateRepository
+	pendingCaptchaRepository    *repositories.PendingCaptchaRepository
+	moderationActionRepository  *repositories.ModerationActionRepository
+	userRepository              repositories.UserRepository
+	llmUsageService             *LLMUsageService
+
+	mu        sync.Mutex
+	captchas  map[int64]*pendingCaptcha
+	newcomers map[int64]int
+}
+
+// NewAntiSpamService creates a new anti-spam service
+func NewAntiSpamService(
+	config *config.Config,
+	bot *gotgbot.Bot,
+	openaiClient *clients.OpenAiClient,
+	messageSenderService *MessageSenderService,
+	adminLogService *AdminLogService,
+	promptingTemplateRepository *repositories.PromptingTemplateRepository,
+	pendingCaptchaRepository *repositories.PendingCaptchaRepository,
+	moderationActionRepository *repositories.ModerationActionRepository,
+	userRepository repositories.UserRepository,
+	llmUsageService *LLMUsageService,
+) *AntiSpamService {
+	return &AntiSpamService{
+		config:                      config,
+		bot:                         bot,
+		openaiClient:                openaiClient,
+		messageSenderService:        messageSenderService,
+		adminLogService:             adminLogService,
+		promptingTemplateRepository: promptingTemplateRepository,
+		pendingCaptchaRepository:    pendingCaptchaRepository,
+		moderationActionRepository:  moderationActionRepository,
+		userRepository:              userRepository,
+		llmUsageService:             llmUsageService,
+		captchas:                    make(map[int64]*pendingCaptcha),
+		newcomers:                   make(map[int64]int),
+	}
+}
+
+// HandleNewMember restricts the new member until the captcha is passed, or just starts watching
+// the first messages if the captcha is disabled
+func (s *AntiSpamService) HandleNewMember(chat gotgbot.Chat, user gotgbot.User) error {
+	if !s.config.CaptchaEnabled {
+		s.markNewcomer(user.Id)
+		return nil
+	}
+
+	if _, err := s.bot.RestrictChatMember(chat.Id, user.Id, gotgbot.ChatPermissions{}, nil); err != nil {
+		return fmt.Errorf("%s: failed to restrict new member %d: %w", utils.GetCurrentTypeName(), user.Id, err)
+	}
+
+	if err := s.sendCaptcha(chat.Id, user); err != nil {
+		return err
+	}
+
+	s.adminLogService.Log(fmt.Sprintf("🧩 Новый участник %s: отправлена капча", s.formatUserLink(user)))
+	return nil
+}
+
+// sendCaptcha sends the captcha to the restricted user and removes them when the timeout passes without an answer
+func (s *AntiSpamService) sendCaptcha(chatID int64, user gotgbot.User) error {
+	// Pick random options, one of them is the answer
+	emojis := make([]string, 0, len(constants.CaptchaOptions))
+	for emoji := range constants.CaptchaOptions {
+		emojis = append(emojis, emoji)
+	}
+	rand.Shuffle(len(emojis), func(i, j int) { emojis[i], emojis[j] = emojis[j], emojis[i] })
+	emojis = emojis[:constants.CaptchaOptionsCount]
+	answerOption := rand.Intn(len(emojis))
+
+	row := make([]gotgbot.InlineKeyboardButton, 0, len(emojis))
+	for i, emoji := range emojis {
+		row = append(row, gotgbot.InlineKeyboardButton{
+			Text:         emoji,
+			CallbackData: fmt.Sprintf("%s%d_%d", constants.CaptchaAnswerCallbackPrefix, user.Id, i),
+		})
+	}
+
+	text := fmt.Sprintf(
+		"👋 Привет, %s!\n\nЧтобы писать в чат, нажми на <b>%s</b> в течение %d мин. Иначе мне придётся тебя удалить 🤖",
+		s.formatUserLink(user),
+		constants.CaptchaOptions[emojis[answerOption]],
+		int(s.config.CaptchaTimeout.Minutes()),
+	)
+
+	sentMsg, err := s.messageSenderService.SendHtmlWithReturnMessage(chatID, text, &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{row}},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to send captcha: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// Keep the captcha in the database, so the deadline survives a restart
+	err = s.pendingCaptchaRepository.Save(&repositories.PendingCaptcha{
+		User:         user,
+		ChatID:       chatID,
+		MessageID:    sentMsg.MessageId,
+		AnswerOption: answerOption,
+		Deadline:     time.Now().Add(s.config.CaptchaTimeout),
+	})
+	if err != nil {
+		log.Printf("%s: Failed to save pending captcha: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	s.armCaptcha(&pendingCaptcha{
+		chatID:       chatID,
+		user:         user,
+		messageID:    sentMsg.MessageId,
+		answerOption: answerOption,
+	}, s.config.CaptchaTimeout)
+	return nil
+}
+
+// RestorePendingCaptchas re-arms the captchas saved before a restart, the expired ones fail right away
+func (s *AntiSpamService) RestorePendingCaptchas() error {
+	saved, err := s.pendingCaptchaRepository.GetAll()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get pending captchas: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	for _, captcha := range saved {
+		s.armCaptcha(&pendingCaptcha{
+			chatID:       captcha.ChatID,
+			user:         captcha.User,
+			messageID:    captcha.MessageID,
+			answerOption: captcha.AnswerOption,
+		}, max(time.Until(captcha.Deadline), 0))
+	}
+
+	if len(saved) > 0 {
+		log.Printf("%s: Restored %d pending captchas", utils.GetCurrentTypeName(), len(saved))
+	}
+	return nil
+}
+
+// armCaptcha registers the captcha and removes the user when the timeout passes without an answer
+func (s *AntiSpamService) armCaptcha(captcha *pendingCaptcha, timeout time.Duration) {
+	userID := captcha.user.Id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.captchas[userID]; ok {
+		previous.timer.Stop()
+	}
+	s.captchas[userID] = captcha
+	captcha.timer = time.AfterFunc(timeout, func() {
+		s.failCaptcha(userID, "нет ответа на капчу")
+	})
+}
+
+// HasPendingCaptcha reports whether the user has a captcha to answer
+func (s *AntiSpamService) HasPendingCaptcha(userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.captchas[userID]
+	return ok
+}
+
+// AnswerCaptcha checks the chosen option. The user gets full access on the right answer and is removed from the chat otherwise.
+// A member who was muted while answering stays restricted until the mute is over.
+func (s *AntiSpamService) AnswerCaptcha(userID int64, option int) (bool, error) {
+	s.mu.Lock()
+	captcha, ok := s.captchas[userID]
+	s.mu.Unlock()
+	if !ok {
+		return false, fmt.Errorf("%s: no pending captcha for user %d", utils.GetCurrentTypeName(), userID)
+	}
+
+	if option != captcha.answerOption {
+		s.failCaptcha(userID, "неверный ответ на капчу")
+		return false, nil
+	}
+
+	// The captcha stays pending if the mute can not be checked, so the user can answer again
+	muted, err := s.hasActiveMute(userID)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	delete(s.captchas, userID)
+	s.mu.Unlock()
+	captcha.timer.Stop()
+	s.deletePendingCaptcha(userID)
+
+	if _, err := s.bot.DeleteMessage(captcha.chatID, captcha.messageID, nil); err != nil {
+		log.Printf("%s: Failed to delete captcha message: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	if muted {
+		s.adminLogService.Log(fmt.Sprintf("✅ %s: капча пройдена, ограничения оставлены до конца мьюта", s.formatUserLink(captcha.user)))
+		return true, nil
+	}
+
+	// Restore the default permissions of the chat
+	permissions := utils.GetDefaultChatPermissions(s.bot, captcha.chatID)
+	if _, err := s.bot.RestrictChatMember(captcha.chatID, userID, permissions, nil); err != nil {
+		return true, fmt.Errorf("%s: failed to lift restrictions from user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	s.markNewcomer(userID)
+	s.adminLogService.Log(fmt.Sprintf("✅ %s: капча пройдена", s.formatUserLink(captcha.user)))
+	return true, nil
+}
+
+// CheckFirstMessage checks the first messages of new members and bans the author if the message is spam.
+// Returns true if the message was removed.
+func (s *AntiSpamService) CheckFirstMessage(ctx context.Context, msg *gotgbot.Message) (bool, error) {
+	s.mu.Lock()
+	remaining, ok := s.newcomers[msg.From.Id]
+	if ok {
+		if remaining <= 1 {
+			delete(s.newcomers, msg.From.Id)
+		} else {
+			s.newcomers[msg.From.Id] = remaining - 1
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	reason, isSpam := s.detectSpam(ctx, msg)
+	if !isSpam {
+		return false, nil
+	}
+
+	if _, err := msg.Delete(s.bot, nil); err != nil {
+		log.Printf("%s: Failed to delete spam message: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	if _, err := s.bot.BanChatMember(msg.Chat.Id, msg.From.Id, &gotgbot.BanChatMemberOpts{RevokeMessages: true}); err != nil {
+		return true, fmt.Errorf("%s: failed to ban user %d: %w", utils.GetCurrentTypeName(), msg.From.Id, err)
+	}
+
+	s.mu.Lock()
+	delete(s.newcomers, msg.From.Id)
+	s.mu.Unlock()
+
+	text := msg.GetText()
+	if len([]rune(text)) > constants.AntiSpamLogTextMaxLength {
+		text = string([]rune(text)[:constants.AntiSpamLogTextMaxLength]) + "..."
+	}
+	s.adminLogService.Log(fmt.Sprintf("🚫 %s: бан за спам, %s\n\n<blockquote>%s</blockquote>",
+		s.formatUserLink(*msg.From), reason, html.EscapeString(text)))
+
+	return true, nil
+}
+
+// detectSpam applies the heuristics and the optional LLM classification to the message
+func (s *AntiSpamService) detectSpam(ctx context.Context, msg *gotgbot.Message) (string, bool) {
+	if utils.HasTelegramInviteLink(msg) {
+		return "ссылка-приглашение в Telegram", true
+	}
+	if utils.IsForwardedFromChannel(msg) {
+		return "пересланный пост из канала", true
+	}
+	if phrase, ok := utils.FindSpamPhrase(msg.GetText(), constants.AntiSpamPhrases); ok {
+		return fmt.Sprintf("спам-фраза «%s»", html.EscapeString(phrase)), true
+	}
+	if utils.MessageHasLinks(msg) {
+		return "ссылка в первом сообщении", true
+	}
+
+	if !s.config.AntiSpamLlmEnabled || strings.TrimSpace(msg.GetText()) == "" {
+		return "", false
+	}
+	if allowed, _ := s.llmUsageService.CheckQuota(0); !allowed {
+		log.Printf("%s: Skipping LLM classification: %v", utils.GetCurrentTypeName(), ErrLLMBudgetExhausted)
+		return "", false
+	}
+
+	template, err := s.promptingTemplateRepository.Get(prompts.AntiSpamPromptTemplateDbKey)
+	if err != nil {
+		log.Printf("%s: Failed to get anti-spam prompt template: %v", utils.GetCurrentTypeName(), err)
+	}
+	if template == "" {
+		template = prompts.AntiSpamPromptDefaultTemplate
+	}
+
+	llmCtx := WithLLMCaller(ctx, 0, constants.LLMFeatureAntiSpam)
+	answer, err := s.openaiClient.GetCompletion(llmCtx, fmt.Sprintf(template, msg.GetText()))
+	if err != nil {
+		log.Printf("%s: Failed to classify message with LLM: %v", utils.GetCurrentTypeName(), err)
+		return "", false
+	}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(answer)), "SPAM") {
+		return "классификация LLM", true
+	}
+
+	return "", false
+}
+
+// failCaptcha removes the user from the chat without a permanent ban, so they can join again
+func (s *AntiSpamService) failCaptcha(userID int64, reason string) {
+	s.mu.Lock()
+	captcha, ok := s.captchas[userID]
+	delete(s.captchas, userID)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	captcha.timer.Stop()
+	s.deletePendingCaptcha(userID)
+
+	if _, err := s.bot.DeleteMessage(captcha.chatID, captcha.messageID, nil); err != nil {
+		log.Printf("%s: Failed to delete captcha message: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	if _, err := s.bot.BanChatMember(captcha.chatID, userID, nil); err != nil {
+		log.Printf("%s: Failed to remove user %d: %v", utils.GetCurrentTypeName(), userID, err)
+		return
+	}
+	if _, err := s.bot.UnbanChatMember(captcha.chatID, userID, &gotgbot.UnbanChatMemberOpts{OnlyIfBanned: true}); err != nil {
+		log.Printf("%s: Failed to unban user %d: %v", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	s.adminLogService.Log(fmt.Sprintf("👢 %s: удаление из чата, %s", s.formatUserLink(captcha.user), reason))
+}
+
+// RechallengeRestrictedMember sends a fresh captcha to the user who answered a captcha that is no longer pending,
+// e.g. because it was lost. The answer to the lost captcha is not trusted, the restrictions stay until the new one is passed.
+// Returns false if the user is not restricted by the captcha: not restricted anymore or muted until a date.
+func (s *AntiSpamService) RechallengeRestrictedMember(chatID int64, user gotgbot.User, staleMessageID int64) (bool, error) {
+	chatMember, err := s.bot.GetChatMember(chatID, user.Id, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get chat member %d: %w", utils.GetCurrentTypeName(), user.Id, err)
+	}
+	// The captcha restricts forever, a mute has the end date
+	if chatMember.GetStatus() != "restricted" || chatMember.MergeChatMember().UntilDate != 0 {
+		return false, nil
+	}
+
+	if staleMessageID != 0 {
+		if _, err := s.bot.DeleteMessage(chatID, staleMessageID, nil); err != nil {
+			log.Printf("%s: Failed to delete stale captcha message: %v", utils.GetCurrentTypeName(), err)
+		}
+	}
+
+	if err := s.sendCaptcha(chatID, user); err != nil {
+		return false, err
+	}
+
+	s.adminLogService.Log(fmt.Sprintf("🧩 %s: проверка не найдена, отправлена новая капча", s.formatUserLink(user)))
+	return true, nil
+}
+
+// hasActiveMute reports whether the user is muted by the admins and must stay restricted after the captcha
+func (s *AntiSpamService) hasActiveMute(userTgID int64) (bool, error) {
+	user, err := s.userRepository.GetByTelegramID(userTgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get user %d: %w", utils.GetCurrentTypeName(), userTgID, err)
+	}
+
+	return s.moderationActionRepository.HasActiveMute(user.ID)
+}
+
+// deletePendingCaptcha forgets the saved captcha of the user
+func (s *AntiSpamService) deletePendingCaptcha(userID int64) {
+	if err := s.pendingCaptchaRepository.Delete(userID); err != nil {
+		log.Printf("%s: Failed to delete pending captcha: %v", utils.GetCurrentTypeName(), err)
+	}
+}
+
+// markNewcomer starts the spam checks of the first messages of the user
+func (s *AntiSpamService) markNewcomer(userID int64) {
+	if !s.config.AntiSpamEnabled {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newcomers[userID] = constants.AntiSpamFirstMessagesCount
+}
+
+func (s *AntiSpamService) formatUserLink(user gotgbot.User) string {
+	name := html.EscapeString(strings.TrimSpace(user.FirstName + " " + user.LastName))
+	if user.Username != "" {
+		name += " (@" + user.Username + ")"
+	}
+	return fmt.Sprintf("<a href=\"tg://user?id=%d\">%s</a>", user.Id, name)
+}
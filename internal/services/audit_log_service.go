@@ -0,0 +1,80 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// AuditLogService records changes made by admins through the bot
+type AuditLogService struct {
+	auditLogRepository *repositories.AuditLogRepository
+}
+
+// NewAuditLogService creates a new audit log service
+func NewAuditLogService(auditLogRepository *repositories.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{auditLogRepository: auditLogRepository}
+}
+
+// Record stores the admin action. Before and after values are stored as JSON, nil values are stored as NULL.
+// Failures are only logged, so the audit never breaks the admin action itself.
+func (s *AuditLogService) Record(
+	actor *gotgbot.User,
+	action constants.AuditAction,
+	entityType constants.AuditEntity,
+	entityID interface{},
+	before interface{},
+	after interface{},
+) {
+	actorName := strings.TrimSpace(actor.FirstName + " " + actor.LastName)
+	if actor.Username != "" {
+		actorName += " (@" + actor.Username + ")"
+	}
+
+	_, err := s.auditLogRepository.Create(
+		actor.Id,
+		actorName,
+		string(action),
+		string(entityType),
+		fmt.Sprint(entityID),
+		s.toJson(before),
+		s.toJson(after),
+	)
+	if err != nil {
+		log.Printf("%s: Failed to record %s of %s %v by %d: %v",
+			utils.GetCurrentTypeName(), action, entityType, entityID, actor.Id, err)
+	}
+}
+
+// Find returns the latest audit log entries matching the filter
+func (s *AuditLogService) Find(filter repositories.AuditLogFilter) ([]repositories.AuditLogEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = constants.AuditLogLimit
+	}
+	return s.auditLogRepository.Find(filter)
+}
+
+func (s *AuditLogService) toJson(value interface{}) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("%s: Failed to marshal audit value: %v", utils.GetCurrentTypeName(), err)
+		return sql.NullString{}
+	}
+	// Typed nil pointers, maps and slices are stored as NULL too, not as JSON null
+	if string(data) == "null" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogService_ToJson(t *testing.T) {
+	service := NewAuditLogService(nil)
+
+	assert.Equal(t, sql.NullString{}, service.toJson(nil))
+	assert.Equal(t, sql.NullString{}, service.toJson((*repositories.Event)(nil)), "typed nil pointer")
+	assert.Equal(t, sql.NullString{}, service.toJson([]string(nil)), "nil slice")
+
+	capacity := 30
+	assert.Equal(t, sql.NullString{String: "30", Valid: true}, service.toJson(&capacity))
+	assert.Equal(t, sql.NullString{String: `{"topic":"intro"}`, Valid: true}, service.toJson(map[string]string{"topic": "intro"}))
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/utils"
+)
+
+// BroadcastService queues broadcasts to club members and reports their delivery
+type BroadcastService struct {
+	messageSenderService      *MessageSenderService
+	broadcastRepository       *repositories.BroadcastRepository
+	outgoingMessageRepository *repositories.OutgoingMessageRepository
+}
+
+// NewBroadcastService creates a new broadcast service
+func NewBroadcastService(
+	messageSenderService *MessageSenderService,
+	broadcastRepository *repositories.BroadcastRepository,
+	outgoingMessageRepository *repositories.OutgoingMessageRepository,
+) *BroadcastService {
+	return &BroadcastService{
+		messageSenderService:      messageSenderService,
+		broadcastRepository:       broadcastRepository,
+		outgoingMessageRepository: outgoingMessageRepository,
+	}
+}
+
+// GetRecipients returns the Telegram IDs of the users in the audience
+func (s *BroadcastService) GetRecipients(audience constants.BroadcastAudience, param int) ([]int64, error) {
+	return s.broadcastRepository.GetAudienceTelegramIDs(audience, param)
+}
+
+// Schedule saves the broadcast and queues a copy of the message for every recipient at once.
+// The copies are sent by the message queue task after scheduledAt.
+func (s *BroadcastService) Schedule(
+	createdByTgID int64,
+	audience constants.BroadcastAudience,
+	param int,
+	fromChatID int64,
+	fromMessageID int64,
+	scheduledAt time.Time,
+) (*repositories.Broadcast, error) {
+	recipients, err := s.GetRecipients(audience, param)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%s: audience %s is empty", utils.GetCurrentTypeName(), audience)
+	}
+
+	broadcast := &repositories.Broadcast{
+		CreatedByTgID: createdByTgID,
+		Audience:      audience,
+		AudienceParam: param,
+		FromChatID:    fromChatID,
+		FromMessageID: fromMessageID,
+		ScheduledAt:   scheduledAt,
+	}
+	broadcast.ID, err = s.broadcastRepository.CreateQueued(broadcast, recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("%s: Broadcast %d to %s queued for %d recipients, scheduled at %v",
+		utils.GetCurrentTypeName(), broadcast.ID, audience, len(recipients), scheduledAt)
+
+	return broadcast, nil
+}
+
+// SendReports sends the delivery report to the author of every broadcast that has no pending messages left
+func (s *BroadcastService) SendReports() error {
+	broadcasts, err := s.broadcastRepository.GetUnreported()
+	if err != nil {
+		return err
+	}
+
+	for i := range broadcasts {
+		broadcast := &broadcasts[i]
+
+		stats, err := s.outgoingMessageRepository.GetBatchStats(repositories.BroadcastBatch(broadcast.ID))
+		if err != nil {
+			log.Printf("%s: Failed to get delivery stats of broadcast %d: %v", utils.GetCurrentTypeName(), broadcast.ID, err)
+			continue
+		}
+		if stats[constants.OutgoingMessageStatusPending] > 0 {
+			continue
+		}
+
+		if err := s.messageSenderService.EnqueueHtmlOrSend(broadcast.CreatedByTgID, formatters.FormatBroadcastReport(broadcast, stats), constants.MessagePriorityNotification, nil); err != nil {
+			log.Printf("%s: Failed to send report of broadcast %d: %v", utils.GetCurrentTypeName(), broadcast.ID, err)
+			continue
+		}
+
+		if err := s.broadcastRepository.MarkReported(broadcast.ID); err != nil {
+			log.Printf("%s: %v", utils.GetCurrentTypeName(), err)
+		}
+	}
+
+	return nil
+}
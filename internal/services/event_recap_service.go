@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/prompts"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// EventRecapService generates recaps of finished events from recording transcripts
+type EventRecapService struct {
+	bot                         *gotgbot.Bot
+	config                      *config.Config
+	openaiClient                *clients.OpenAiClient
+	messageSenderService        *MessageSenderService
+	promptingTemplateRepository *repositories.PromptingTemplateRepository
+	topicRepository             *repositories.TopicRepository
+	eventRecapRepository        *repositories.EventRecapRepository
+	llmUsageService             *LLMUsageService
+}
+
+// NewEventRecapService creates a new event recap service
+func NewEventRecapService(
+	bot *gotgbot.Bot,
+	config *config.Config,
+	openaiClient *clients.OpenAiClient,
+	messageSenderService *MessageSenderService,
+	promptingTemplateRepository *repositories.PromptingTemplateRepository,
+	topicRepository *repositories.TopicRepository,
+	eventRecapRepository *repositories.EventRecapRepository,
+	llmUsageService *LLMUsageService,
+) *EventRecapService {
+	return &EventRecapService{
+		bot:                         bot,
+		config:                      config,
+		openaiClient:                openaiClient,
+		messageSenderService:        messageSenderService,
+		promptingTemplateRepository: promptingTemplateRepository,
+		topicRepository:             topicRepository,
+		eventRecapRepository:        eventRecapRepository,
+		llmUsageService:             llmUsageService,
+	}
+}
+
+// DownloadTranscript downloads a transcript document sent to the bot and returns its plain text
+func (s *EventRecapService) DownloadTranscript(document *gotgbot.Document) (string, error) {
+	if !utils.IsSupportedTranscriptFile(document.FileName) {
+		return "", fmt.Errorf("%s: unsupported transcript file: %s", utils.GetCurrentTypeName(), document.FileName)
+	}
+
+	if document.FileSize > constants.EventRecapTranscriptMaxFileSize {
+		return "", fmt.Errorf("%s: transcript file is too large: %d bytes", utils.GetCurrentTypeName(), document.FileSize)
+	}
+
+	file, err := s.bot.GetFile(document.FileId, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get file info: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	resp, err := http.Get(file.URL(s.bot, nil))
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to download file: %w", utils.GetCurrentTypeName(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: failed to download file, status: %s", utils.GetCurrentTypeName(), resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, constants.EventRecapTranscriptMaxFileSize))
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to read file: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return utils.ParseTranscript(string(content)), nil
+}
+
+// GenerateRecap chunks the transcript through the LLM, builds the final recap and stores it with the event
+func (s *EventRecapService) GenerateRecap(ctx context.Context, event *repositories.Event, transcript string) (*repositories.EventRecap, error) {
+	chunks := utils.SplitTextIntoChunks(transcript, constants.EventRecapTranscriptChunkSize)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%s: transcript is empty", utils.GetCurrentTypeName())
+	}
+
+	if allowed, _ := s.llmUsageService.CheckQuota(0); !allowed {
+		return nil, fmt.Errorf("%s: %w", utils.GetCurrentTypeName(), ErrLLMBudgetExhausted)
+	}
+
+	chunkTemplate, err := s.promptingTemplateRepository.Get(prompts.EventRecapChunkPromptTemplateDbKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get chunk prompt template: %w", utils.GetCurrentTypeName(), err)
+	}
+	if chunkTemplate == "" {
+		chunkTemplate = prompts.EventRecapChunkPromptDefaultTemplate
+	}
+
+	recapTemplate, err := s.promptingTemplateRepository.Get(prompts.EventRecapPromptTemplateDbKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get recap prompt template: %w", utils.GetCurrentTypeName(), err)
+	}
+	if recapTemplate == "" {
+		recapTemplate = prompts.EventRecapPromptDefaultTemplate
+	}
+
+	log.Printf("%s: Generating recap for event %d from %d transcript chunks", utils.GetCurrentTypeName(), event.ID, len(chunks))
+
+	ctx = WithLLMCaller(ctx, 0, constants.LLMFeatureEventRecap)
+
+	// Summarize each chunk separately to fit the model context
+	notes := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		prompt := fmt.Sprintf(chunkTemplate, event.Name, i+1, len(chunks), chunk)
+		chunkNotes, err := s.openaiClient.GetCompletion(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to summarize transcript chunk %d: %w", utils.GetCurrentTypeName(), i+1, err)
+		}
+		notes = append(notes, fmt.Sprintf("Фрагмент %d:\n%s", i+1, chunkNotes))
+	}
+
+	topics, err := s.topicRepository.GetTopicsByEventID(event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get topics for event: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	topicsList := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		topicsList = append(topicsList, "- "+topic.Topic)
+	}
+
+	prompt := fmt.Sprintf(recapTemplate, event.Name, strings.Join(topicsList, "\n"), strings.Join(notes, "\n\n"))
+	answer, err := s.openaiClient.GetCompletion(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to generate recap: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	content, err := ParseEventRecapContent(answer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	recap, err := s.eventRecapRepository.Upsert(event.ID, content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to save recap: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return recap, nil
+}
+
+// ParseEventRecapContent reads the recap the model answered with as JSON. The JSON may be wrapped
+// into a Markdown code block or text, empty points are dropped.
+func ParseEventRecapContent(answer string) (repositories.EventRecapContent, error) {
+	var content repositories.EventRecapContent
+
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start == -1 || end < start {
+		return content, fmt.Errorf("recap is not JSON: %q", utils.TruncateText(answer, 100))
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &content); err != nil {
+		return content, fmt.Errorf("failed to parse recap: %w", err)
+	}
+
+	content.KeyPoints = compactRecapItems(content.KeyPoints)
+	content.Decisions = compactRecapItems(content.Decisions)
+	content.Links = compactRecapItems(content.Links)
+	topics := content.Topics[:0]
+	for _, topic := range content.Topics {
+		topic.Topic = strings.TrimSpace(topic.Topic)
+		if topic.Topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	content.Topics = topics
+
+	if content.IsEmpty() {
+		return content, fmt.Errorf("recap is empty")
+	}
+	return content, nil
+}
+
+func compactRecapItems(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// FormatRecapMessage builds the message text for a recap publication
+func (s *EventRecapService) FormatRecapMessage(event *repositories.Event, recap *repositories.EventRecap) string {
+	return formatters.FormatEventRecap(event, recap)
+}
+
+// PublishRecap publishes the recap to the recap topic, editing the previously published message if there is one
+func (s *EventRecapService) PublishRecap(event *repositories.Event, recap *repositories.EventRecap) (*gotgbot.Message, error) {
+	chatID := utils.ChatIdToFullChatId(s.config.SuperGroupChatID)
+	messageText := s.FormatRecapMessage(event, recap)
+
+	if recap.PublishedMessageID.Valid {
+		editedMsg, _, err := s.bot.EditMessageText(
+			messageText,
+			&gotgbot.EditMessageTextOpts{
+				ChatId:    chatID,
+				MessageId: recap.PublishedMessageID.Int64,
+				ParseMode: "HTML",
+			})
+		if err == nil {
+			return editedMsg, nil
+		}
+		if strings.Contains(err.Error(), "are exactly the same") {
+			return nil, nil
+		}
+		log.Printf("%s: Failed to edit published recap, sending a new message: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	opts := &gotgbot.SendMessageOpts{
+		MessageThreadId: int64(s.config.RecapTopicID),
+	}
+	publishedMsg, err := s.messageSenderService.SendHtmlWithReturnMessage(chatID, messageText, opts)
+	if err != nil {
+		// The text of the model is escaped, but the recap is published anyway if Telegram does not accept the HTML
+		log.Printf("%s: Failed to publish recap as HTML, sending plain text: %v", utils.GetCurrentTypeName(), err)
+		publishedMsg, err = s.messageSenderService.SendWithReturnMessage(chatID, utils.HtmlToPlainText(messageText), opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to publish recap: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	if err := s.eventRecapRepository.UpdatePublishedMessageID(recap.ID, publishedMsg.MessageId); err != nil {
+		log.Printf("%s: Failed to save published message ID for recap: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	return publishedMsg, nil
+}
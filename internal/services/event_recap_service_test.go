@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventRecapContent(t *testing.T) {
+	answer := "Вот конспект:\n```json\n" + `{
+		"key_points": ["Generics <T> & interfaces", " "],
+		"decisions": [],
+		"links": ["https://go.dev"],
+		"topics": [{"topic": "Errors", "status": "covered"}, {"topic": "", "status": "partial"}]
+	}` + "\n```"
+
+	content, err := ParseEventRecapContent(answer)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.EventRecapContent{
+		KeyPoints: []string{"Generics <T> & interfaces"},
+		Decisions: []string{},
+		Links:     []string{"https://go.dev"},
+		Topics:    []repositories.EventRecapTopic{{Topic: "Errors", Status: constants.EventRecapTopicCovered}},
+	}, content)
+
+	_, err = ParseEventRecapContent("<b>🔑 Ключевые мысли</b>\n- HTML instead of JSON")
+	assert.Error(t, err)
+
+	_, err = ParseEventRecapContent(`{"key_points": [], "topics": []}`)
+	assert.Error(t, err, "empty recap")
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"log"
+	"slices"
+	"strings"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// EventRegistrationService manages registrations for offline events with limited capacity
+type EventRegistrationService struct {
+	config               *config.Config
+	messageSenderService *MessageSenderService
+	eventRepository      repositories.EventRepository
+	userRepository       repositories.UserRepository
+}
+
+// NewEventRegistrationService creates a new event registration service
+func NewEventRegistrationService(
+	config *config.Config,
+	messageSenderService *MessageSenderService,
+	eventRepository repositories.EventRepository,
+	userRepository repositories.UserRepository,
+) *EventRegistrationService {
+	return &EventRegistrationService{
+		config:               config,
+		messageSenderService: messageSenderService,
+		eventRepository:      eventRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// SupportsRegistration reports whether members can register for the event
+func (s *EventRegistrationService) SupportsRegistration(event *repositories.Event) bool {
+	return event.Status == string(constants.EventStatusActual) &&
+		slices.Contains(constants.EventTypesWithCapacity, constants.EventType(event.Type))
+}
+
+// Register registers the Telegram user for the event, putting them on the waitlist when the event is full
+func (s *EventRegistrationService) Register(event *repositories.Event, tgUser *gotgbot.User) (*repositories.EventRegistration, error) {
+	dbUser, err := s.userRepository.GetOrCreate(tgUser)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	registration, err := s.eventRepository.RegisterForEvent(event.ID, dbUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to register user for event: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return registration, nil
+}
+
+// Cancel removes the Telegram user's registration and notifies members promoted from the waitlist
+func (s *EventRegistrationService) Cancel(event *repositories.Event, tgUser *gotgbot.User) error {
+	dbUser, err := s.userRepository.GetOrCreate(tgUser)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	promoted, err := s.eventRepository.CancelEventRegistration(event.ID, dbUser.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to cancel registration: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	s.notifyPromoted(event, promoted)
+	return nil
+}
+
+// UpdateCapacity changes the event capacity and notifies members promoted from the waitlist
+func (s *EventRegistrationService) UpdateCapacity(event *repositories.Event, capacity *int) error {
+	promoted, err := s.eventRepository.UpdateEventCapacity(event.ID, capacity)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update capacity: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	s.notifyPromoted(event, promoted)
+	return nil
+}
+
+// BuildAttendeesCsv builds a CSV file with the members who are going to the event
+func (s *EventRegistrationService) BuildAttendeesCsv(event *repositories.Event) ([]byte, int, error) {
+	registrations, err := s.eventRepository.GetEventRegistrationsWithUsers(event.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: failed to get registrations: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	var buf bytes.Buffer
+	// BOM makes spreadsheet apps detect UTF-8 for Cyrillic names
+	buf.WriteString("\ufeff")
+
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"#", "Имя", "Username", "Профиль"}); err != nil {
+		return nil, 0, fmt.Errorf("%s: failed to write CSV header: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	count := 0
+	for _, registration := range registrations {
+		if registration.Registration.Status != string(constants.EventRegistrationStatusGoing) {
+			continue
+		}
+		count++
+
+		user := registration.User
+		fullName := user.Firstname
+		if user.Lastname != "" {
+			fullName += " " + user.Lastname
+		}
+
+		username := ""
+		if user.TgUsername != "" {
+			username = "@" + user.TgUsername
+		}
+
+		// Prefer the published intro, then the public username, then the Telegram ID link
+		profileLink := fmt.Sprintf("tg://user?id=%d", user.TgID)
+		if registration.ProfilePublishedMessageID.Valid {
+			profileLink = utils.GetIntroMessageLink(s.config, registration.ProfilePublishedMessageID.Int64)
+		} else if user.TgUsername != "" {
+			profileLink = "https://t.me/" + user.TgUsername
+		}
+
+		row := []string{fmt.Sprintf("%d", count), escapeCsvCell(fullName), escapeCsvCell(username), profileLink}
+		if err := writer.Write(row); err != nil {
+			return nil, 0, fmt.Errorf("%s: failed to write CSV row: %w", utils.GetCurrentTypeName(), err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, 0, fmt.Errorf("%s: failed to flush CSV: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return buf.Bytes(), count, nil
+}
+
+// escapeCsvCell keeps spreadsheet apps from running a member's name as a formula
+func escapeCsvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// notifyPromoted queues a DM to every member who got a place from the waitlist
+func (s *EventRegistrationService) notifyPromoted(event *repositories.Event, promoted []repositories.EventRegistration) {
+	for _, registration := range promoted {
+		user, err := s.userRepository.GetByID(registration.UserID)
+		if err != nil {
+			log.Printf("%s: Failed to get promoted user %d: %v", utils.GetCurrentTypeName(), registration.UserID, err)
+			continue
+		}
+
+		text := fmt.Sprintf(
+			"🎉 Освободилось место! Ты переведён(а) из листа ожидания в участники мероприятия %s <b>%s</b>.\n\n"+
+				"Если планы поменялись, отмени запись через /%s, чтобы место досталось следующему в очереди.",
+			formatters.GetTypeEmoji(constants.EventType(event.Type)),
+			html.EscapeString(event.Name),
+			constants.EventRsvpCommand,
+		)
+
+		if err := s.messageSenderService.EnqueueHtml(user.TgID, text, constants.MessagePriorityNotification, nil); err != nil {
+			log.Printf("%s: Failed to queue notification for user %d about promotion from waitlist: %v", utils.GetCurrentTypeName(), user.TgID, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/dbtest"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/memory"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEventRegistrationService returns the service over the in-memory repositories,
+// the notifications about promotions fail to be queued and are only logged
+func newTestEventRegistrationService(t *testing.T, capacity *int) (*EventRegistrationService, *memory.Store, *repositories.Event) {
+	t.Helper()
+
+	store := memory.NewStore()
+	messageSenderService := NewMessageSenderService(nil, store.Users(),
+		repositories.NewOutgoingMessageRepository(dbtest.Unavailable()), NewTelegramRateLimiter())
+	service := NewEventRegistrationService(&config.Config{SuperGroupChatID: 1234567890, IntroTopicID: 10},
+		messageSenderService, store.Events(), store.Users())
+
+	eventID, err := store.Events().CreateEvent("Meetup", constants.EventTypeMeetup)
+	require.NoError(t, err)
+	_, err = store.Events().UpdateEventCapacity(eventID, capacity)
+	require.NoError(t, err)
+	event, err := store.Events().GetEventByID(eventID)
+	require.NoError(t, err)
+
+	return service, store, event
+}
+
+func testMembers(names ...string) []*gotgbot.User {
+	users := make([]*gotgbot.User, 0, len(names))
+	for i, name := range names {
+		users = append(users, &gotgbot.User{Id: int64(2000 + i), FirstName: name, Username: strings.ToLower(name)})
+	}
+	return users
+}
+
+func registrationStatuses(t *testing.T, store *memory.Store, event *repositories.Event, users []*gotgbot.User) []string {
+	t.Helper()
+
+	statuses := make([]string, 0, len(users))
+	for _, user := range users {
+		dbUser, err := store.Users().GetByTelegramID(user.Id)
+		require.NoError(t, err)
+		registration, err := store.Events().GetEventRegistration(event.ID, dbUser.ID)
+		if err != nil {
+			statuses = append(statuses, "")
+			continue
+		}
+		statuses = append(statuses, registration.Status)
+	}
+	return statuses
+}
+
+const (
+	statusGoing    = string(constants.EventRegistrationStatusGoing)
+	statusWaitlist = string(constants.EventRegistrationStatusWaitlist)
+)
+
+func TestEventRegistrationService_WaitlistPromotion(t *testing.T) {
+	capacity := 2
+	service, store, event := newTestEventRegistrationService(t, &capacity)
+	members := testMembers("Ivan", "Petr", "Anna", "Olga", "Oleg")
+	ivan, petr, olga, oleg := members[0], members[1], members[3], members[4]
+
+	for _, member := range members[:4] {
+		_, err := service.Register(event, member)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{statusGoing, statusGoing, statusWaitlist, statusWaitlist}, registrationStatuses(t, store, event, members[:4]))
+
+	// Registering again keeps the place
+	registration, err := service.Register(event, ivan)
+	require.NoError(t, err)
+	assert.Equal(t, statusGoing, registration.Status)
+
+	// Leaving the statusWaitlist frees no place
+	require.NoError(t, service.Cancel(event, olga))
+	assert.Equal(t, []string{statusGoing, statusGoing, statusWaitlist, ""}, registrationStatuses(t, store, event, members[:4]))
+
+	// A cancelled place goes to the earliest on the statusWaitlist, the next one waits for the next place
+	registration, err = service.Register(event, oleg)
+	require.NoError(t, err)
+	assert.Equal(t, statusWaitlist, registration.Status)
+	require.NoError(t, service.Cancel(event, ivan))
+	assert.Equal(t, []string{"", statusGoing, statusGoing, "", statusWaitlist}, registrationStatuses(t, store, event, members))
+
+	require.NoError(t, service.Cancel(event, petr))
+	assert.Equal(t, []string{"", "", statusGoing, "", statusGoing}, registrationStatuses(t, store, event, members))
+
+	goingCount, waitlistCount, err := store.Events().CountEventRegistrations(event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, goingCount)
+	assert.Zero(t, waitlistCount)
+}
+
+func TestEventRegistrationService_CapacityChanges(t *testing.T) {
+	capacity := 0
+	service, store, event := newTestEventRegistrationService(t, &capacity)
+	members := testMembers("Ivan", "Petr", "Anna", "Olga")
+
+	// Nobody gets a place at an event without places
+	for _, member := range members {
+		_, err := service.Register(event, member)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{statusWaitlist, statusWaitlist, statusWaitlist, statusWaitlist}, registrationStatuses(t, store, event, members))
+
+	// Raising the capacity promotes in registration order
+	capacity = 1
+	require.NoError(t, service.UpdateCapacity(event, &capacity))
+	assert.Equal(t, []string{statusGoing, statusWaitlist, statusWaitlist, statusWaitlist}, registrationStatuses(t, store, event, members))
+
+	capacity = 3
+	require.NoError(t, service.UpdateCapacity(event, &capacity))
+	assert.Equal(t, []string{statusGoing, statusGoing, statusGoing, statusWaitlist}, registrationStatuses(t, store, event, members))
+
+	// Lowering the capacity keeps the places of those who are statusGoing
+	capacity = 1
+	require.NoError(t, service.UpdateCapacity(event, &capacity))
+	assert.Equal(t, []string{statusGoing, statusGoing, statusGoing, statusWaitlist}, registrationStatuses(t, store, event, members))
+	require.NoError(t, service.Cancel(event, members[0]))
+	assert.Equal(t, []string{"", statusGoing, statusGoing, statusWaitlist}, registrationStatuses(t, store, event, members))
+
+	// Without the capacity everybody is statusGoing
+	require.NoError(t, service.UpdateCapacity(event, nil))
+	assert.Equal(t, []string{"", statusGoing, statusGoing, statusGoing}, registrationStatuses(t, store, event, members))
+}
+
+func TestEventRegistrationService_BuildAttendeesCsv(t *testing.T) {
+	capacity := 2
+	service, store, event := newTestEventRegistrationService(t, &capacity)
+	members := testMembers("Ivan", "Petr", "Anna")
+	members[1].Username = ""
+	members[1].LastName = "Petrov"
+
+	for _, member := range members {
+		_, err := service.Register(event, member)
+		require.NoError(t, err)
+	}
+
+	// The published intro is preferred to the username
+	dbUser, err := store.Users().GetByTelegramID(members[0].Id)
+	require.NoError(t, err)
+	profile, err := store.Profiles().GetOrCreate(dbUser.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.Profiles().UpdatePublishedMessageID(profile.ID, 42))
+
+	data, count, err := service.BuildAttendeesCsv(event)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "only those who are going")
+	require.True(t, strings.HasPrefix(string(data), "\ufeff"))
+
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff"))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"#", "Имя", "Username", "Профиль"},
+		{"1", "Ivan", "'@ivan", "https://t.me/c/1234567890/10/42"},
+		{"2", "Petr Petrov", "", "tg://user?id=2001"},
+	}, rows)
+}
+
+func TestEventRegistrationService_BuildAttendeesCsvEscapesFormulas(t *testing.T) {
+	service, _, event := newTestEventRegistrationService(t, nil)
+	members := testMembers(`=HYPERLINK("https://example.com","Ivan")`, "+7 999", "-1", "@admin", "\tTab", "Anna")
+	members[5].LastName = "=1+1"
+
+	for _, member := range members {
+		member.Username = ""
+		_, err := service.Register(event, member)
+		require.NoError(t, err)
+	}
+
+	data, count, err := service.BuildAttendeesCsv(event)
+	require.NoError(t, err)
+	require.Equal(t, len(members), count)
+
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff"))).ReadAll()
+	require.NoError(t, err)
+	names := make([]string, 0, count)
+	for _, row := range rows[1:] {
+		names = append(names, row[1])
+	}
+	assert.Equal(t, []string{
+		`'=HYPERLINK("https://example.com","Ivan")`,
+		"'+7 999",
+		"'-1",
+		"'@admin",
+		"'\tTab",
+		"Anna =1+1",
+	}, names)
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// ForumTopicService is the registry of the club chat topics. Lookups are served from memory,
+// which is loaded from the database, synced with the user client and updated from service messages.
+type ForumTopicService struct {
+	config               *config.Config
+	tgUserClient         clients.TgUserClient
+	forumTopicRepository repositories.ForumTopicRepository
+
+	mu     sync.RWMutex
+	topics map[int]repositories.ForumTopic
+}
+
+// NewForumTopicService creates a new forum topic service
+func NewForumTopicService(
+	config *config.Config,
+	tgUserClient clients.TgUserClient,
+	forumTopicRepository repositories.ForumTopicRepository,
+) *ForumTopicService {
+	return &ForumTopicService{
+		config:               config,
+		tgUserClient:         tgUserClient,
+		forumTopicRepository: forumTopicRepository,
+		topics:               make(map[int]repositories.ForumTopic),
+	}
+}
+
+// Load replaces the registry with the topics stored in the database
+func (s *ForumTopicService) Load() error {
+	stored, err := s.forumTopicRepository.GetAll()
+	if err != nil {
+		return fmt.Errorf("%s: failed to load forum topics: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	topics := make(map[int]repositories.ForumTopic, len(stored))
+	for _, topic := range stored {
+		topics[topic.TopicID] = topic
+	}
+
+	s.mu.Lock()
+	s.topics = topics
+	s.mu.Unlock()
+	return nil
+}
+
+// Sync fetches all topics of the club chat, stores them, drops the deleted ones and reloads the registry.
+// It returns the number of synced topics.
+func (s *ForumTopicService) Sync(ctx context.Context) (int, error) {
+	tgTopics, err := s.tgUserClient.GetForumTopics(ctx, s.config.SuperGroupChatID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get forum topics: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// Keep the registry as it is rather than deleting every topic on an empty response
+	if len(tgTopics) == 0 {
+		return 0, nil
+	}
+
+	topicIDs := make([]int, 0, len(tgTopics))
+	for _, tgTopic := range tgTopics {
+		topic := &repositories.ForumTopic{
+			TopicID:        tgTopic.ID,
+			Title:          tgTopic.Title,
+			IconColor:      tgTopic.IconColor,
+			IsClosed:       tgTopic.Closed,
+			IsHidden:       tgTopic.Hidden,
+			TopicCreatedAt: sql.NullTime{Time: time.Unix(int64(tgTopic.Date), 0), Valid: tgTopic.Date != 0},
+		}
+		if tgTopic.IconEmojiID != 0 {
+			topic.IconEmojiID = strconv.FormatInt(tgTopic.IconEmojiID, 10)
+		}
+
+		if err := s.forumTopicRepository.Upsert(topic); err != nil {
+			return 0, fmt.Errorf("%s: failed to store forum topic: %w", utils.GetCurrentTypeName(), err)
+		}
+		topicIDs = append(topicIDs, tgTopic.ID)
+	}
+
+	deleted, err := s.forumTopicRepository.DeleteAllExcept(topicIDs)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to delete removed forum topics: %w", utils.GetCurrentTypeName(), err)
+	}
+	if deleted > 0 {
+		log.Printf("%s: Removed %d deleted forum topics", utils.GetCurrentTypeName(), deleted)
+	}
+
+	if err := s.Load(); err != nil {
+		return 0, err
+	}
+
+	return len(topicIDs), nil
+}
+
+// GetTopic returns the topic by its ID, topic 0 is the General topic
+func (s *ForumTopicService) GetTopic(topicID int) (repositories.ForumTopic, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	topic, ok := s.topics[normalizeForumTopicID(topicID)]
+	return topic, ok
+}
+
+// GetTopics returns all known topics ordered by ID
+func (s *ForumTopicService) GetTopics() []repositories.ForumTopic {
+	s.mu.RLock()
+	topics := make([]repositories.ForumTopic, 0, len(s.topics))
+	for _, topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(topics, func(i, j int) bool { return topics[i].TopicID < topics[j].TopicID })
+	return topics
+}
+
+// GetTopicName returns the name of the topic without network calls.
+// For an unknown topic it returns a default name along with an error.
+func (s *ForumTopicService) GetTopicName(topicID int) (string, error) {
+	topic, ok := s.GetTopic(topicID)
+	if ok && topic.Title != "" {
+		return topic.Title, nil
+	}
+
+	if normalizeForumTopicID(topicID) == constants.ForumGeneralTopicID {
+		return constants.ForumGeneralTopicDefaultName, nil
+	}
+
+	return constants.ForumTopicDefaultName, fmt.Errorf("%s: topic %d is not in the registry", utils.GetCurrentTypeName(), topicID)
+}
+
+// IsForumTopicServiceMessage checks if the message is a service message about a topic change
+func IsForumTopicServiceMessage(msg *gotgbot.Message) bool {
+	return msg.ForumTopicCreated != nil ||
+		msg.ForumTopicEdited != nil ||
+		msg.ForumTopicClosed != nil ||
+		msg.ForumTopicReopened != nil ||
+		msg.GeneralForumTopicHidden != nil ||
+		msg.GeneralForumTopicUnhidden != nil
+}
+
+// HandleServiceMessage applies a forum topic service message to the registry.
+// Changes of topics that are not known yet are left for the next sync, except for created topics.
+func (s *ForumTopicService) HandleServiceMessage(msg *gotgbot.Message) error {
+	topicID := normalizeForumTopicID(int(msg.MessageThreadId))
+	if msg.GeneralForumTopicHidden != nil || msg.GeneralForumTopicUnhidden != nil {
+		topicID = constants.ForumGeneralTopicID
+	}
+
+	topic, known := s.GetTopic(topicID)
+
+	switch {
+	case msg.ForumTopicCreated != nil:
+		topic = repositories.ForumTopic{
+			TopicID:        topicID,
+			Title:          msg.ForumTopicCreated.Name,
+			IconColor:      int(msg.ForumTopicCreated.IconColor),
+			IconEmojiID:    msg.ForumTopicCreated.IconCustomEmojiId,
+			TopicCreatedAt: sql.NullTime{Time: time.Unix(msg.Date, 0), Valid: true},
+		}
+	case !known:
+		log.Printf("%s: Skipping service message of unknown topic %d until the next sync", utils.GetCurrentTypeName(), topicID)
+		return nil
+	case msg.ForumTopicEdited != nil:
+		edited := msg.ForumTopicEdited
+		if edited.Name != "" {
+			topic.Title = edited.Name
+		}
+		// The icon is either changed along with the name or it is the only change, an empty ID means it was removed
+		if edited.IconCustomEmojiId != "" || edited.Name == "" {
+			topic.IconEmojiID = edited.IconCustomEmojiId
+		}
+	case msg.ForumTopicClosed != nil:
+		topic.IsClosed = true
+	case msg.ForumTopicReopened != nil:
+		topic.IsClosed = false
+	case msg.GeneralForumTopicHidden != nil:
+		topic.IsHidden = true
+	case msg.GeneralForumTopicUnhidden != nil:
+		topic.IsHidden = false
+	default:
+		return nil
+	}
+
+	if err := s.forumTopicRepository.Upsert(&topic); err != nil {
+		return fmt.Errorf("%s: failed to store forum topic: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	topic.UpdatedAt = time.Now()
+	s.mu.Lock()
+	s.topics[topicID] = topic
+	s.mu.Unlock()
+	return nil
+}
+
+// normalizeForumTopicID maps the Bot API thread ID 0 of the General topic to its MTProto ID
+func normalizeForumTopicID(topicID int) int {
+	if topicID == 0 {
+		return constants.ForumGeneralTopicID
+	}
+	return topicID
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/memory"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/gotd/td/tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestForumTopicService(t *testing.T, tgUserClient *fakeTgUserClient, stored ...repositories.ForumTopic) (*ForumTopicService, repositories.ForumTopicRepository) {
+	t.Helper()
+
+	repository := memory.NewStore().ForumTopics()
+	for _, topic := range stored {
+		require.NoError(t, repository.Upsert(&topic))
+	}
+
+	service := NewForumTopicService(&config.Config{SuperGroupChatID: 1234567890}, tgUserClient, repository)
+	require.NoError(t, service.Load())
+	return service, repository
+}
+
+func topicIDs(topics []repositories.ForumTopic) []int {
+	ids := make([]int, 0, len(topics))
+	for _, topic := range topics {
+		ids = append(ids, topic.TopicID)
+	}
+	return ids
+}
+
+func TestForumTopicService_Sync(t *testing.T) {
+	tgUserClient := &fakeTgUserClient{topics: []tg.ForumTopic{
+		{ID: constants.ForumGeneralTopicID, Title: "Общение"},
+		{ID: 42, Title: "Go", IconEmojiID: 5368324170671202286, Closed: true, Date: 1754000000},
+	}}
+	service, repository := newTestForumTopicService(t, tgUserClient,
+		repositories.ForumTopic{TopicID: 42, Title: "Old name"},
+		repositories.ForumTopic{TopicID: 99, Title: "Deleted"},
+	)
+
+	synced, err := service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, synced)
+	assert.Equal(t, []fakeTgUserClientCall{{Method: "GetForumTopics", ChatID: 1234567890}}, tgUserClient.calls)
+
+	// The deleted topic is dropped from the database and the registry
+	stored, err := repository.GetAll()
+	require.NoError(t, err)
+	assert.Equal(t, []int{constants.ForumGeneralTopicID, 42}, topicIDs(stored))
+	assert.Equal(t, []int{constants.ForumGeneralTopicID, 42}, topicIDs(service.GetTopics()))
+
+	topic, ok := service.GetTopic(42)
+	require.True(t, ok)
+	assert.Equal(t, "Go", topic.Title)
+	assert.Equal(t, "5368324170671202286", topic.IconEmojiID)
+	assert.True(t, topic.IsClosed)
+	assert.Equal(t, int64(1754000000), topic.TopicCreatedAt.Time.Unix())
+
+	// The Bot API thread ID 0 is the General topic
+	name, err := service.GetTopicName(0)
+	require.NoError(t, err)
+	assert.Equal(t, "Общение", name)
+}
+
+func TestForumTopicService_SyncKeepsRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *fakeTgUserClient
+		wantErr bool
+	}{
+		{name: "empty response", client: &fakeTgUserClient{}},
+		{name: "user client error", client: &fakeTgUserClient{err: errors.New("TG User Client: not connected")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository := newTestForumTopicService(t, tt.client,
+				repositories.ForumTopic{TopicID: 42, Title: "Go"},
+				repositories.ForumTopic{TopicID: 43, Title: "Rust"},
+			)
+
+			synced, err := service.Sync(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Zero(t, synced)
+
+			stored, err := repository.GetAll()
+			require.NoError(t, err)
+			assert.Equal(t, []int{42, 43}, topicIDs(stored))
+			assert.Equal(t, []int{42, 43}, topicIDs(service.GetTopics()))
+		})
+	}
+}
+
+func TestForumTopicService_GetTopicName(t *testing.T) {
+	service, _ := newTestForumTopicService(t, &fakeTgUserClient{},
+		repositories.ForumTopic{TopicID: 42, Title: "Go"},
+		repositories.ForumTopic{TopicID: 43},
+	)
+
+	name, err := service.GetTopicName(42)
+	assert.NoError(t, err)
+	assert.Equal(t, "Go", name)
+
+	name, err = service.GetTopicName(0)
+	assert.NoError(t, err, "the General topic has a name before the first sync")
+	assert.Equal(t, constants.ForumGeneralTopicDefaultName, name)
+
+	name, err = service.GetTopicName(43)
+	assert.Error(t, err, "a topic without a title")
+	assert.Equal(t, constants.ForumTopicDefaultName, name)
+
+	name, err = service.GetTopicName(44)
+	assert.Error(t, err)
+	assert.Equal(t, constants.ForumTopicDefaultName, name)
+}
+
+func TestForumTopicService_HandleServiceMessage(t *testing.T) {
+	stored := []repositories.ForumTopic{
+		{TopicID: constants.ForumGeneralTopicID, Title: "Общение"},
+		{TopicID: 42, Title: "Go", IconEmojiID: "100"},
+	}
+
+	tests := []struct {
+		name    string
+		msg     gotgbot.Message
+		topicID int
+		want    *repositories.ForumTopic // nil if the topic is not registered
+	}{
+		{
+			name:    "created topic",
+			msg:     gotgbot.Message{MessageThreadId: 50, ForumTopicCreated: &gotgbot.ForumTopicCreated{Name: "Rust", IconColor: 7322096, IconCustomEmojiId: "200"}},
+			topicID: 50,
+			want:    &repositories.ForumTopic{TopicID: 50, Title: "Rust", IconColor: 7322096, IconEmojiID: "200"},
+		},
+		{
+			name:    "edited name keeps the icon",
+			msg:     gotgbot.Message{MessageThreadId: 42, ForumTopicEdited: &gotgbot.ForumTopicEdited{Name: "Golang"}},
+			topicID: 42,
+			want:    &repositories.ForumTopic{TopicID: 42, Title: "Golang", IconEmojiID: "100"},
+		},
+		{
+			name:    "edited icon keeps the name",
+			msg:     gotgbot.Message{MessageThreadId: 42, ForumTopicEdited: &gotgbot.ForumTopicEdited{IconCustomEmojiId: "300"}},
+			topicID: 42,
+			want:    &repositories.ForumTopic{TopicID: 42, Title: "Go", IconEmojiID: "300"},
+		},
+		{
+			name:    "edited name and icon",
+			msg:     gotgbot.Message{MessageThreadId: 42, ForumTopicEdited: &gotgbot.ForumTopicEdited{Name: "Golang", IconCustomEmojiId: "300"}},
+			topicID: 42,
+			want:    &repositories.ForumTopic{TopicID: 42, Title: "Golang", IconEmojiID: "300"},
+		},
+		{
+			name:    "removed icon",
+			msg:     gotgbot.Message{MessageThreadId: 42, ForumTopicEdited: &gotgbot.ForumTopicEdited{}},
+			topicID: 42,
+			want:    &repositories.ForumTopic{TopicID: 42, Title: "Go"},
+		},
+		{
+			name:    "closed topic",
+			msg:     gotgbot.Message{MessageThreadId: 42, ForumTopicClosed: &gotgbot.ForumTopicClosed{}},
+			topicID: 42,
+			want:    &repositories.ForumTopic{TopicID: 42, Title: "Go", IconEmojiID: "100", IsClosed: true},
+		},
+		{
+			name:    "closed General topic",
+			msg:     gotgbot.Message{ForumTopicClosed: &gotgbot.ForumTopicClosed{}},
+			topicID: constants.ForumGeneralTopicID,
+			want:    &repositories.ForumTopic{TopicID: constants.ForumGeneralTopicID, Title: "Общение", IsClosed: true},
+		},
+		{
+			name:    "hidden General topic",
+			msg:     gotgbot.Message{MessageThreadId: 0, GeneralForumTopicHidden: &gotgbot.GeneralForumTopicHidden{}},
+			topicID: constants.ForumGeneralTopicID,
+			want:    &repositories.ForumTopic{TopicID: constants.ForumGeneralTopicID, Title: "Общение", IsHidden: true},
+		},
+		{
+			name:    "edited unknown topic is left for the next sync",
+			msg:     gotgbot.Message{MessageThreadId: 77, ForumTopicEdited: &gotgbot.ForumTopicEdited{Name: "Unknown"}},
+			topicID: 77,
+		},
+		{
+			name:    "closed unknown topic is left for the next sync",
+			msg:     gotgbot.Message{MessageThreadId: 77, ForumTopicClosed: &gotgbot.ForumTopicClosed{}},
+			topicID: 77,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository := newTestForumTopicService(t, &fakeTgUserClient{}, stored...)
+
+			require.NoError(t, service.HandleServiceMessage(&tt.msg))
+
+			topic, ok := service.GetTopic(tt.topicID)
+			storedTopics, err := repository.GetAll()
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.False(t, ok)
+				assert.NotContains(t, topicIDs(storedTopics), tt.topicID)
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tt.want.Title, topic.Title)
+			assert.Equal(t, tt.want.IconColor, topic.IconColor)
+			assert.Equal(t, tt.want.IconEmojiID, topic.IconEmojiID)
+			assert.Equal(t, tt.want.IsClosed, topic.IsClosed)
+			assert.Equal(t, tt.want.IsHidden, topic.IsHidden)
+
+			// The database has the same topic as the registry
+			require.Contains(t, topicIDs(storedTopics), tt.topicID)
+			for _, storedTopic := range storedTopics {
+				if storedTopic.TopicID == tt.topicID {
+					assert.Equal(t, topic.Title, storedTopic.Title)
+					assert.Equal(t, topic.IconEmojiID, storedTopic.IconEmojiID)
+					assert.Equal(t, topic.IsClosed, storedTopic.IsClosed)
+					assert.Equal(t, topic.IsHidden, storedTopic.IsHidden)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+)
+
+// ErrLLMBudgetExhausted is returned by the features that call the LLM on their own when the monthly budget is spent
+var ErrLLMBudgetExhausted = errors.New("monthly LLM budget is exhausted")
+
+// llmCallerCtxKey is the context key of the user and feature an LLM call is made for
+type llmCallerCtxKey struct{}
+
+type llmCaller struct {
+	userTgID int64
+	feature  constants.LLMFeature
+}
+
+// WithLLMCaller returns a context that attributes the LLM calls made with it to the user and feature.
+// Use userTgID 0 for calls made by scheduled tasks.
+func WithLLMCaller(ctx context.Context, userTgID int64, feature constants.LLMFeature) context.Context {
+	return context.WithValue(ctx, llmCallerCtxKey{}, llmCaller{userTgID: userTgID, feature: feature})
+}
+
+// LLMUsageService records LLM calls and enforces the per-user daily quota and the monthly budget
+type LLMUsageService struct {
+	config             *config.Config
+	llmUsageRepository *repositories.LLMUsageRepository
+}
+
+// NewLLMUsageService creates a new LLM usage service
+func NewLLMUsageService(config *config.Config, llmUsageRepository *repositories.LLMUsageRepository) *LLMUsageService {
+	return &LLMUsageService{
+		config:             config,
+		llmUsageRepository: llmUsageRepository,
+	}
+}
+
+// RecordCall stores the LLM call with its estimated cost, it is used as the OpenAI client call recorder.
+// Failures are only logged, so accounting never breaks the feature itself.
+func (s *LLMUsageService) RecordCall(ctx context.Context, call clients.LLMCall) {
+	caller, ok := ctx.Value(llmCallerCtxKey{}).(llmCaller)
+	if !ok {
+		caller = llmCaller{feature: constants.LLMFeatureUnknown}
+	}
+
+	usage := &repositories.LLMUsage{
+		Feature:          caller.feature,
+		Model:            call.Model,
+		PromptTokens:     call.PromptTokens,
+		CompletionTokens: call.CompletionTokens,
+		LatencyMs:        call.Latency.Milliseconds(),
+		CostUSD:          estimateLLMCost(call.Model, call.PromptTokens, call.CompletionTokens),
+		Success:          call.Err == nil,
+	}
+	if caller.userTgID != 0 {
+		usage.UserTgID = sql.NullInt64{Int64: caller.userTgID, Valid: true}
+	}
+
+	if err := s.llmUsageRepository.Create(usage); err != nil {
+		log.Printf("%s: Failed to record LLM call of %s: %v", utils.GetCurrentTypeName(), caller.feature, err)
+	}
+}
+
+// CheckQuota checks the user's daily quota and the monthly budget before an LLM call. Use userTgID 0
+// for calls made by the bot itself, only the monthly budget applies to them.
+// When the call is not allowed it returns a message for the user. If the usage cannot be read the call is allowed.
+func (s *LLMUsageService) CheckQuota(userTgID int64) (bool, string) {
+	now := time.Now().In(s.config.ClubTimezone)
+
+	if s.config.LLMMonthlyBudgetUSD > 0 {
+		cost, err := s.llmUsageRepository.GetTotalCostSince(startOfMonth(now))
+		if err != nil {
+			log.Printf("%s: Failed to check the monthly LLM budget: %v", utils.GetCurrentTypeName(), err)
+		} else if cost >= s.config.LLMMonthlyBudgetUSD {
+			return false, "🙏 Лимит запросов к ИИ на этот месяц исчерпан. Возможности с ИИ снова заработают в начале следующего месяца."
+		}
+	}
+
+	if s.config.LLMUserDailyQuota > 0 && userTgID != 0 {
+		count, err := s.llmUsageRepository.CountUserRequestsSince(userTgID, startOfDay(now))
+		if err != nil {
+			log.Printf("%s: Failed to check the daily LLM quota of user %d: %v", utils.GetCurrentTypeName(), userTgID, err)
+		} else if count >= s.config.LLMUserDailyQuota {
+			return false, fmt.Sprintf(
+				"🙏 Дневной лимит запросов к ИИ (%d) исчерпан. Возвращайся завтра!",
+				s.config.LLMUserDailyQuota,
+			)
+		}
+	}
+
+	return true, ""
+}
+
+// GetReport returns the LLM usage for the last days, or since the start of the month if days is 0
+func (s *LLMUsageService) GetReport(days int) (*repositories.LLMUsageReport, error) {
+	now := time.Now().In(s.config.ClubTimezone)
+	since := startOfMonth(now)
+	if days > 0 {
+		since = startOfDay(now).AddDate(0, 0, -(days - 1))
+	}
+
+	byFeature, err := s.llmUsageRepository.GetStatsByFeatureSince(since)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get usage by feature: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	byUser, err := s.llmUsageRepository.GetStatsByUserSince(since, constants.LLMUsageTopUsersLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get usage by user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	monthCost, err := s.llmUsageRepository.GetTotalCostSince(startOfMonth(now))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get monthly cost: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	report := &repositories.LLMUsageReport{
+		Since:            since,
+		ByFeature:        byFeature,
+		ByUser:           byUser,
+		MonthCostUSD:     monthCost,
+		MonthlyBudgetUSD: s.config.LLMMonthlyBudgetUSD,
+		UserDailyQuota:   s.config.LLMUserDailyQuota,
+	}
+	for _, stats := range byFeature {
+		report.Total.Requests += stats.Requests
+		report.Total.PromptTokens += stats.PromptTokens
+		report.Total.CompletionTokens += stats.CompletionTokens
+		report.Total.CostUSD += stats.CostUSD
+	}
+
+	return report, nil
+}
+
+// estimateLLMCost estimates the cost of a call in US dollars. Versioned model names like
+// "o3-mini-2025-01-31" use the price of the longest matching model name.
+func estimateLLMCost(model string, promptTokens int64, completionTokens int64) float64 {
+	var price constants.LLMModelPrice
+	matched := ""
+	for name, p := range constants.LLMModelPrices {
+		if strings.HasPrefix(model, name) && len(name) > len(matched) {
+			price = p
+			matched = name
+		}
+	}
+
+	return (float64(promptTokens)*price.PromptPerMillion + float64(completionTokens)*price.CompletionPerMillion) / 1_000_000
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// MembershipCacheStats holds the hit and miss counters of the membership cache
+type MembershipCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Size   int
+}
+
+type membershipCacheKey struct {
+	chatID int64
+	userID int64
+}
+
+type membershipCacheEntry struct {
+	member    gotgbot.ChatMember
+	expiresAt time.Time
+}
+
+// MembershipCacheService caches GetChatMember lookups. It implements utils.ChatMemberGetter,
+// so it can be used by utils.IsUserClubMember and utils.IsUserAdminOrCreator instead of the bot.
+type MembershipCacheService struct {
+	config *config.Config
+	bot    *gotgbot.Bot
+
+	mu      sync.RWMutex
+	members map[membershipCacheKey]membershipCacheEntry
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewMembershipCacheService creates a new membership cache service
+func NewMembershipCacheService(config *config.Config, bot *gotgbot.Bot) *MembershipCacheService {
+	return &MembershipCacheService{
+		config:  config,
+		bot:     bot,
+		members: make(map[membershipCacheKey]membershipCacheEntry),
+	}
+}
+
+// GetChatMember returns the cached chat member or requests it from the Bot API
+func (s *MembershipCacheService) GetChatMember(chatId, userId int64, opts *gotgbot.GetChatMemberOpts) (gotgbot.ChatMember, error) {
+	key := membershipCacheKey{chatID: chatId, userID: userId}
+
+	s.mu.RLock()
+	entry, ok := s.members[key]
+	s.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		s.hits.Add(1)
+		return entry.member, nil
+	}
+
+	s.misses.Add(1)
+	member, err := s.bot.GetChatMember(chatId, userId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(key, member)
+	return member, nil
+}
+
+// Update stores the new membership from a chat_member update
+func (s *MembershipCacheService) Update(chatMember *gotgbot.ChatMemberUpdated) {
+	s.set(membershipCacheKey{
+		chatID: chatMember.Chat.Id,
+		userID: chatMember.NewChatMember.GetUser().Id,
+	}, chatMember.NewChatMember)
+}
+
+// PreloadAdmins loads the administrators of the club chat into the cache and evicts
+// the cached administrators who have lost their rights
+func (s *MembershipCacheService) PreloadAdmins() error {
+	chatID := utils.ChatIdToFullChatId(s.config.SuperGroupChatID)
+
+	admins, err := s.bot.GetChatAdministrators(chatID, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get chat administrators: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	adminIDs := make(map[int64]bool, len(admins))
+	for _, admin := range admins {
+		adminIDs[admin.GetUser().Id] = true
+		s.set(membershipCacheKey{chatID: chatID, userID: admin.GetUser().Id}, admin)
+	}
+
+	s.removeFormerAdmins(chatID, adminIDs)
+	s.removeExpired()
+	return nil
+}
+
+// GetStats returns the hit and miss counters and the number of cached members
+func (s *MembershipCacheService) GetStats() MembershipCacheStats {
+	s.mu.RLock()
+	size := len(s.members)
+	s.mu.RUnlock()
+
+	return MembershipCacheStats{
+		Hits:   s.hits.Load(),
+		Misses: s.misses.Load(),
+		Size:   size,
+	}
+}
+
+func (s *MembershipCacheService) set(key membershipCacheKey, member gotgbot.ChatMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.members[key] = membershipCacheEntry{
+		member:    member,
+		expiresAt: time.Now().Add(s.config.MembershipCacheTTL),
+	}
+}
+
+func (s *MembershipCacheService) removeFormerAdmins(chatID int64, adminIDs map[int64]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range s.members {
+		status := entry.member.GetStatus()
+		if key.chatID == chatID && (status == "administrator" || status == "creator") && !adminIDs[key.userID] {
+			delete(s.members, key)
+		}
+	}
+}
+
+func (s *MembershipCacheService) removeExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.members {
+		if now.After(entry.expiresAt) {
+			delete(s.members, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/telegramtest"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembershipCacheService_PreloadAdminsEvictsFormerAdmins(t *testing.T) {
+	server := telegramtest.NewServer(t)
+	appConfig := &config.Config{SuperGroupChatID: 1234567890, MembershipCacheTTL: time.Hour}
+	service := NewMembershipCacheService(appConfig, server.Bot)
+
+	admins := []int64{1001, 1002}
+	server.Handle("getChatAdministrators", func(r telegramtest.Request) (interface{}, error) {
+		members := make([]map[string]interface{}, 0, len(admins))
+		for _, id := range admins {
+			members = append(members, map[string]interface{}{
+				"status": "administrator",
+				"user":   gotgbot.User{Id: id, FirstName: "Admin"},
+			})
+		}
+		return members, nil
+	})
+
+	require.NoError(t, service.PreloadAdmins())
+	assert.True(t, utils.IsUserAdminOrCreator(service, 1002, appConfig))
+	assert.False(t, utils.IsUserAdminOrCreator(service, 2001, appConfig), "members are cached too")
+	assert.Equal(t, 3, service.GetStats().Size)
+
+	// The demoted admin is requested again, the cached members are kept
+	admins = []int64{1001}
+	require.NoError(t, service.PreloadAdmins())
+	assert.Equal(t, 2, service.GetStats().Size)
+	server.ClearRequests()
+	assert.False(t, utils.IsUserAdminOrCreator(service, 1002, appConfig))
+	assert.Len(t, server.Requests("getChatMember"), 1)
+}
@@ -1,23 +1,60 @@
 package services
 
 import (
+	"database/sql"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
 	"evo-bot-go/internal/utils"
 	"fmt"
 	"log"
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 )
 
 type MessageSenderService struct {
-	bot *gotgbot.Bot
+	bot                       *gotgbot.Bot
+	userRepository            repositories.UserRepository
+	outgoingMessageRepository *repositories.OutgoingMessageRepository
+	rateLimiter               utils.MessageRateLimiter
+	blockedUsersMutex         sync.RWMutex
+	blockedUsers              map[int64]struct{}
 }
 
-func NewMessageSenderService(bot *gotgbot.Bot) *MessageSenderService {
-	return &MessageSenderService{bot: bot}
+// QueueOpts are the optional parameters of a message put into the outgoing queue
+type QueueOpts struct {
+	MessageThreadId int64
+	Batch           string    // groups the messages of one mailing
+	SendAfter       time.Time // the message is not sent before this time
+}
+
+func NewMessageSenderService(
+	bot *gotgbot.Bot,
+	userRepository repositories.UserRepository,
+	outgoingMessageRepository *repositories.OutgoingMessageRepository,
+	rateLimiter utils.MessageRateLimiter,
+) *MessageSenderService {
+	return &MessageSenderService{
+		bot:                       bot,
+		userRepository:            userRepository,
+		outgoingMessageRepository: outgoingMessageRepository,
+		rateLimiter:               rateLimiter,
+		blockedUsers:              make(map[int64]struct{}),
+	}
+}
+
+// NewTelegramRateLimiter creates the rate limiter with the limits of the Bot API
+func NewTelegramRateLimiter() *utils.RateLimiter {
+	return utils.NewRateLimiter(utils.RateLimits{
+		GlobalPerSecond:      constants.RateLimitGlobalPerSecond,
+		PrivateChatPerSecond: constants.RateLimitPrivateChatPerSecond,
+		PrivateChatBurst:     constants.RateLimitPrivateChatBurst,
+		GroupChatPerMinute:   constants.RateLimitGroupChatPerMinute,
+	})
 }
 
 // Send message to chat
@@ -35,7 +72,7 @@ func (s *MessageSenderService) SendWithReturnMessage(chatId int64, text string,
 		}
 	}
 
-	sentMsg, err := s.bot.SendMessage(chatId, text, opts)
+	sentMsg, err := s.sendMessage(chatId, text, opts, constants.MessagePriorityInteractive)
 
 	if err != nil {
 		// If topic is closed, try to reopen it and send again
@@ -77,7 +114,7 @@ func (s *MessageSenderService) SendMarkdownWithReturnMessage(chatId int64, text
 		}
 	}
 
-	sentMsg, err := s.bot.SendMessage(chatId, text, opts)
+	sentMsg, err := s.sendMessage(chatId, text, opts, constants.MessagePriorityInteractive)
 
 	if err != nil {
 		// If topic is closed, try to reopen it and send again
@@ -101,6 +138,12 @@ func (s *MessageSenderService) SendMarkdown(chatId int64, text string, opts *got
 
 // Send html message to chat
 func (s *MessageSenderService) SendHtmlWithReturnMessage(chatId int64, text string, opts *gotgbot.SendMessageOpts) (*gotgbot.Message, error) {
+	return s.SendHtmlWithPriority(chatId, text, opts, constants.MessagePriorityInteractive)
+}
+
+// SendHtmlWithPriority sends the html message right away, waiting for the rate limiter with the priority.
+// It is meant for the scheduled posts that need the sent message, the rest of the bulk messages are queued.
+func (s *MessageSenderService) SendHtmlWithPriority(chatId int64, text string, opts *gotgbot.SendMessageOpts, priority constants.MessagePriority) (*gotgbot.Message, error) {
 	// default options for html messages
 	if opts == nil {
 		opts = &gotgbot.SendMessageOpts{
@@ -119,7 +162,7 @@ func (s *MessageSenderService) SendHtmlWithReturnMessage(chatId int64, text stri
 		}
 	}
 
-	sentMsg, err := s.bot.SendMessage(chatId, text, opts)
+	sentMsg, err := s.sendMessage(chatId, text, opts, priority)
 
 	if err != nil {
 		// If topic is closed, try to reopen it and send again
@@ -161,7 +204,7 @@ func (s *MessageSenderService) ReplyWithReturnMessage(msg *gotgbot.Message, repl
 		}
 	}
 
-	sentMsg, err := msg.Reply(s.bot, replyText, opts)
+	sentMsg, err := s.reply(msg, replyText, opts)
 
 	if err != nil {
 		log.Printf("%s: Reply: Failed to send message: %v", utils.GetCurrentTypeName(), err)
@@ -196,7 +239,7 @@ func (s *MessageSenderService) ReplyMarkdownWithReturnMessage(msg *gotgbot.Messa
 		}
 	}
 
-	sentMsg, err := msg.Reply(s.bot, replyText, opts)
+	sentMsg, err := s.reply(msg, replyText, opts)
 
 	if err != nil {
 		log.Printf("%s: ReplyMarkdown: Failed to send message: %v", utils.GetCurrentTypeName(), err)
@@ -225,7 +268,7 @@ func (s *MessageSenderService) ReplyHtml(msg *gotgbot.Message, replyText string,
 		}
 	}
 
-	_, err := msg.Reply(s.bot, replyText, opts)
+	_, err := s.reply(msg, replyText, opts)
 
 	if err != nil {
 		log.Printf("%s: ReplyHtml: Failed to send message: %v", utils.GetCurrentTypeName(), err)
@@ -242,7 +285,7 @@ func (s *MessageSenderService) ReplyWithCleanupAfterDelayWithPing(
 	delaySeconds int,
 	opts *gotgbot.SendMessageOpts,
 ) error {
-	sentMsg, err := msg.Reply(s.bot, text, opts)
+	sentMsg, err := s.reply(msg, text, opts)
 	if err != nil {
 		log.Printf("Failed to send reply: %v", err)
 		return err
@@ -258,7 +301,7 @@ func (s *MessageSenderService) ReplyWithCleanupAfterDelayWithPing(
 		"Пинг!",
 	}
 	randomGreeting := greetings[rand.Intn(len(greetings))]
-	_, err = s.bot.SendMessage(msg.From.Id, randomGreeting, nil)
+	_, err = s.sendMessage(msg.From.Id, randomGreeting, nil, constants.MessagePriorityInteractive)
 	if err != nil {
 		log.Printf("Failed to send greeting message: %v", err)
 	}
@@ -378,7 +421,9 @@ func (s *MessageSenderService) SendCopy(
 		}
 	}
 
-	sentMessage, err := method(chatId, text, opts)
+	sentMessage, err := s.send(chatId, constants.MessagePriorityInteractive, func() (*gotgbot.Message, error) {
+		return method(chatId, text, opts)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -388,7 +433,7 @@ func (s *MessageSenderService) SendCopy(
 		(originalMessage.Animation != nil ||
 			originalMessage.Photo != nil ||
 			originalMessage.Video != nil) {
-		_, err := s.bot.SendMessage(chatId, trimmedPartOfCaption, &gotgbot.SendMessageOpts{Entities: trimmedPartOfCaptionEntities})
+		_, err := s.sendMessage(chatId, trimmedPartOfCaption, &gotgbot.SendMessageOpts{Entities: trimmedPartOfCaptionEntities}, constants.MessagePriorityInteractive)
 		if err != nil {
 			return sentMessage, err
 		}
@@ -397,12 +442,27 @@ func (s *MessageSenderService) SendCopy(
 	return sentMessage, nil
 }
 
+// SendPhotoWithReturnMessage sends a photo to chat
+func (s *MessageSenderService) SendPhotoWithReturnMessage(chatId int64, photo gotgbot.InputFileOrString, opts *gotgbot.SendPhotoOpts) (*gotgbot.Message, error) {
+	sentMsg, err := s.send(chatId, constants.MessagePriorityInteractive, func() (*gotgbot.Message, error) {
+		return s.bot.SendPhoto(chatId, photo, opts)
+	})
+	if err != nil {
+		log.Printf("%s: SendPhoto: Failed to send photo: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	return sentMsg, err
+}
+
 // SendTypingAction sends a typing action to the specified chat.
 func (s *MessageSenderService) SendTypingAction(chatId int64) error {
-	_, err := s.bot.Request("sendChatAction", map[string]string{
-		"chat_id": strconv.FormatInt(chatId, 10),
-		"action":  "typing",
-	}, nil, nil)
+	err := s.call(chatId, constants.MessagePriorityInteractive, func() error {
+		_, err := s.bot.Request("sendChatAction", map[string]string{
+			"chat_id": strconv.FormatInt(chatId, 10),
+			"action":  "typing",
+		}, nil, nil)
+		return err
+	})
 	if err != nil {
 		log.Printf("%s: SendTypingAction: Failed to send typing action: %v", utils.GetCurrentTypeName(), err)
 	}
@@ -415,10 +475,13 @@ func (s *MessageSenderService) RemoveInlineKeyboard(chatID int64, messageID int6
 		return nil
 	}
 
-	_, _, err := s.bot.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
-		ChatId:      chatID,
-		MessageId:   messageID,
-		ReplyMarkup: gotgbot.InlineKeyboardMarkup{},
+	err := s.call(chatID, constants.MessagePriorityInteractive, func() error {
+		_, _, err := s.bot.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:      chatID,
+			MessageId:   messageID,
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{},
+		})
+		return err
 	})
 
 	if err != nil {
@@ -430,8 +493,11 @@ func (s *MessageSenderService) RemoveInlineKeyboard(chatID int64, messageID int6
 
 // PinMessageWithNotification pins a message with optional notification to all users
 func (s *MessageSenderService) PinMessage(chatID int64, messageID int64, withNotification bool) error {
-	_, err := s.bot.PinChatMessage(chatID, messageID, &gotgbot.PinChatMessageOpts{
-		DisableNotification: !withNotification,
+	err := s.call(chatID, constants.MessagePriorityInteractive, func() error {
+		_, err := s.bot.PinChatMessage(chatID, messageID, &gotgbot.PinChatMessageOpts{
+			DisableNotification: !withNotification,
+		})
+		return err
 	})
 
 	if err != nil {
@@ -441,6 +507,241 @@ func (s *MessageSenderService) PinMessage(chatID int64, messageID int64, withNot
 	return err
 }
 
+// EnqueueHtml puts an html message into the persistent outgoing queue.
+// Queued messages are sent in the background by the message queue task and survive restarts.
+func (s *MessageSenderService) EnqueueHtml(chatId int64, text string, priority constants.MessagePriority, opts *QueueOpts) error {
+	message := &repositories.OutgoingMessage{
+		ChatID:    chatId,
+		Text:      text,
+		ParseMode: "HTML",
+		Priority:  priority,
+	}
+	if opts != nil {
+		message.MessageThreadID = opts.MessageThreadId
+		message.Batch = opts.Batch
+		message.SendAfter = opts.SendAfter
+	}
+
+	if _, err := s.outgoingMessageRepository.Create(message); err != nil {
+		log.Printf("%s: EnqueueHtml: Failed to queue message: %v", utils.GetCurrentTypeName(), err)
+		return err
+	}
+
+	return nil
+}
+
+// EnqueueHtmlOrSend puts an html message into the persistent outgoing queue,
+// or sends it right away if the queue is not available, so the message is not lost with the database
+func (s *MessageSenderService) EnqueueHtmlOrSend(chatId int64, text string, priority constants.MessagePriority, opts *QueueOpts) error {
+	if err := s.EnqueueHtml(chatId, text, priority, opts); err == nil {
+		return nil
+	}
+
+	sendOpts := &gotgbot.SendMessageOpts{
+		ParseMode: "HTML",
+		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}
+	if opts != nil {
+		sendOpts.MessageThreadId = opts.MessageThreadId
+	}
+	if _, err := s.sendMessage(chatId, text, sendOpts, priority); err != nil {
+		log.Printf("%s: EnqueueHtmlOrSend: Failed to send message: %v", utils.GetCurrentTypeName(), err)
+		return err
+	}
+
+	return nil
+}
+
+// EnqueueCopy puts a copy of the message into the persistent outgoing queue, media and formatting included
+func (s *MessageSenderService) EnqueueCopy(chatId int64, fromChatId int64, messageId int64, priority constants.MessagePriority, opts *QueueOpts) error {
+	message := &repositories.OutgoingMessage{
+		ChatID:            chatId,
+		CopyFromChatID:    sql.NullInt64{Int64: fromChatId, Valid: true},
+		CopyFromMessageID: sql.NullInt64{Int64: messageId, Valid: true},
+		Priority:          priority,
+	}
+	if opts != nil {
+		message.MessageThreadID = opts.MessageThreadId
+		message.Batch = opts.Batch
+		message.SendAfter = opts.SendAfter
+	}
+
+	if _, err := s.outgoingMessageRepository.Create(message); err != nil {
+		log.Printf("%s: EnqueueCopy: Failed to queue message copy: %v", utils.GetCurrentTypeName(), err)
+		return err
+	}
+
+	return nil
+}
+
+// ProcessQueue sends the queued messages that are due and returns how many of them were processed
+func (s *MessageSenderService) ProcessQueue(limit int) (int, error) {
+	messages, err := s.outgoingMessageRepository.ClaimDue(limit, constants.OutgoingQueueClaimTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range messages {
+		s.deliverQueued(&messages[i])
+	}
+
+	return len(messages), nil
+}
+
+// CleanupQueue removes delivered and undeliverable messages older than the retention period
+func (s *MessageSenderService) CleanupQueue(retention time.Duration) (int64, error) {
+	return s.outgoingMessageRepository.DeleteFinishedBefore(time.Now().Add(-retention))
+}
+
+// LoadBlockedUsers loads the users who blocked the bot from the database
+func (s *MessageSenderService) LoadBlockedUsers() error {
+	tgIDs, err := s.userRepository.GetBotBlockedTelegramIDs()
+	if err != nil {
+		return err
+	}
+
+	s.blockedUsersMutex.Lock()
+	defer s.blockedUsersMutex.Unlock()
+
+	s.blockedUsers = make(map[int64]struct{}, len(tgIDs))
+	for _, tgID := range tgIDs {
+		s.blockedUsers[tgID] = struct{}{}
+	}
+
+	return nil
+}
+
+// IsBotBlocked reports whether the user blocked the bot the last time we tried to message them
+func (s *MessageSenderService) IsBotBlocked(userId int64) bool {
+	s.blockedUsersMutex.RLock()
+	defer s.blockedUsersMutex.RUnlock()
+
+	_, blocked := s.blockedUsers[userId]
+	return blocked
+}
+
+func (s *MessageSenderService) deliverQueued(message *repositories.OutgoingMessage) {
+	if message.ChatID > 0 && s.IsBotBlocked(message.ChatID) {
+		if err := s.outgoingMessageRepository.MarkUndelivered(message.ID, constants.OutgoingMessageStatusBlocked, "bot is blocked by the user"); err != nil {
+			log.Printf("%s: %v", utils.GetCurrentTypeName(), err)
+		}
+		return
+	}
+
+	var sentMsg *gotgbot.Message
+	var err error
+	if message.CopyFromChatID.Valid {
+		sentMsg, err = s.send(message.ChatID, message.Priority, func() (*gotgbot.Message, error) {
+			messageId, err := s.bot.CopyMessage(message.ChatID, message.CopyFromChatID.Int64, message.CopyFromMessageID.Int64, &gotgbot.CopyMessageOpts{
+				MessageThreadId: message.MessageThreadID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &gotgbot.Message{MessageId: messageId.MessageId, Chat: gotgbot.Chat{Id: message.ChatID}}, nil
+		})
+	} else {
+		opts := &gotgbot.SendMessageOpts{
+			ParseMode:       message.ParseMode,
+			MessageThreadId: message.MessageThreadID,
+			LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
+				IsDisabled: true,
+			},
+		}
+		sentMsg, err = s.sendMessage(message.ChatID, message.Text, opts, message.Priority)
+		if err != nil && s.isTopicClosedError(err) && opts.MessageThreadId != 0 {
+			sentMsg, err = s.handleClosedTopicReturnMessage(message.ChatID, message.Text, opts, "deliverQueued", err)
+		}
+	}
+
+	switch {
+	case err == nil:
+		err = s.outgoingMessageRepository.MarkSent(message.ID, sentMsg.MessageId)
+	case utils.IsBotBlockedError(err):
+		err = s.outgoingMessageRepository.MarkUndelivered(message.ID, constants.OutgoingMessageStatusBlocked, err.Error())
+	case message.Attempts+1 >= constants.OutgoingQueueMaxAttempts:
+		log.Printf("%s: Giving up on queued message %d to chat %d: %v", utils.GetCurrentTypeName(), message.ID, message.ChatID, err)
+		err = s.outgoingMessageRepository.MarkUndelivered(message.ID, constants.OutgoingMessageStatusFailed, err.Error())
+	default:
+		retryDelay := time.Duration(message.Attempts+1) * constants.OutgoingQueueRetryDelay
+		err = s.outgoingMessageRepository.Reschedule(message.ID, time.Now().Add(retryDelay), err.Error())
+	}
+
+	if err != nil {
+		log.Printf("%s: Failed to update queued message %d: %v", utils.GetCurrentTypeName(), message.ID, err)
+	}
+}
+
+// send waits for the rate limiter, retries the request while Telegram answers 429 and tracks users who blocked the bot
+func (s *MessageSenderService) send(chatId int64, priority constants.MessagePriority, method func() (*gotgbot.Message, error)) (*gotgbot.Message, error) {
+	for attempt := 0; ; attempt++ {
+		s.rateLimiter.Wait(chatId, int(priority))
+
+		sentMsg, err := method()
+		if chatId > 0 {
+			s.updateBotBlocked(chatId, err)
+		}
+
+		retryAfter, tooManyRequests := utils.GetRetryAfter(err)
+		if !tooManyRequests || attempt >= constants.RateLimitMaxRetries {
+			return sentMsg, err
+		}
+
+		log.Printf("%s: Too many requests to chat %d, retrying after %v", utils.GetCurrentTypeName(), chatId, retryAfter)
+		time.Sleep(retryAfter)
+	}
+}
+
+// call waits for the rate limiter and retries like send, for the Bot API methods that do not return a message
+func (s *MessageSenderService) call(chatId int64, priority constants.MessagePriority, method func() error) error {
+	_, err := s.send(chatId, priority, func() (*gotgbot.Message, error) {
+		return nil, method()
+	})
+	return err
+}
+
+func (s *MessageSenderService) sendMessage(chatId int64, text string, opts *gotgbot.SendMessageOpts, priority constants.MessagePriority) (*gotgbot.Message, error) {
+	return s.send(chatId, priority, func() (*gotgbot.Message, error) {
+		return s.bot.SendMessage(chatId, text, opts)
+	})
+}
+
+func (s *MessageSenderService) reply(msg *gotgbot.Message, text string, opts *gotgbot.SendMessageOpts) (*gotgbot.Message, error) {
+	return s.send(msg.Chat.Id, constants.MessagePriorityInteractive, func() (*gotgbot.Message, error) {
+		return msg.Reply(s.bot, text, opts)
+	})
+}
+
+// updateBotBlocked remembers that the user blocked the bot, or forgets it once a message gets through again
+func (s *MessageSenderService) updateBotBlocked(userId int64, err error) {
+	blocked := utils.IsBotBlockedError(err)
+	if !blocked && err != nil {
+		return
+	}
+
+	s.blockedUsersMutex.Lock()
+	_, wasBlocked := s.blockedUsers[userId]
+	if blocked {
+		s.blockedUsers[userId] = struct{}{}
+	} else {
+		delete(s.blockedUsers, userId)
+	}
+	s.blockedUsersMutex.Unlock()
+
+	if blocked == wasBlocked {
+		return
+	}
+
+	if blocked {
+		log.Printf("%s: User %d blocked the bot", utils.GetCurrentTypeName(), userId)
+	}
+	if dbErr := s.userRepository.SetBotBlocked(userId, blocked); dbErr != nil {
+		log.Printf("%s: %v", utils.GetCurrentTypeName(), dbErr)
+	}
+}
+
 func (s *MessageSenderService) isTopicClosedError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "TOPIC_CLOSED")
 }
@@ -454,17 +755,23 @@ func (s *MessageSenderService) handleClosedTopicReturnMessage(chatId int64, text
 	log.Printf("%s: %s: Topic is closed, attempting to reopen it", utils.GetCurrentTypeName(), methodName)
 
 	// Try to reopen the topic
-	_, reopenErr := s.bot.ReopenForumTopic(chatId, opts.MessageThreadId, nil)
+	reopenErr := s.call(chatId, constants.MessagePriorityInteractive, func() error {
+		_, err := s.bot.ReopenForumTopic(chatId, opts.MessageThreadId, nil)
+		return err
+	})
 	if reopenErr != nil {
 		log.Printf("%s: %s: Failed to reopen topic: %v", utils.GetCurrentTypeName(), methodName, reopenErr)
 		return nil, fmt.Errorf("failed to reopen topic and send message: %w", originalErr)
 	}
 
 	// Try sending the message again
-	sentMsg, err := s.bot.SendMessage(chatId, text, opts)
+	sentMsg, err := s.sendMessage(chatId, text, opts, constants.MessagePriorityInteractive)
 
 	// Close the topic again to maintain its original state
-	_, closeErr := s.bot.CloseForumTopic(chatId, opts.MessageThreadId, nil)
+	closeErr := s.call(chatId, constants.MessagePriorityInteractive, func() error {
+		_, err := s.bot.CloseForumTopic(chatId, opts.MessageThreadId, nil)
+		return err
+	})
 	if closeErr != nil {
 		log.Printf("%s: %s: Warning: Failed to close topic after sending message: %v", utils.GetCurrentTypeName(), methodName, closeErr)
 		// We don't return an error here as the message was sent successfully
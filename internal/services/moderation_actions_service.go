@@ -0,0 +1,202 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// WarnResult describes the outcome of a warning
+type WarnResult struct {
+	Warnings int  // warnings since the last mute or ban, including this one
+	Muted    bool // true if the warning triggered the automatic mute
+}
+
+// ModerationActionsService applies warnings, mutes and bans to members and stores them in the moderation log
+type ModerationActionsService struct {
+	config                     *config.Config
+	bot                        *gotgbot.Bot
+	messageSenderService       *MessageSenderService
+	adminLogService            *AdminLogService
+	moderationActionRepository *repositories.ModerationActionRepository
+	userRepository             repositories.UserRepository
+}
+
+// NewModerationActionsService creates a new moderation actions service
+func NewModerationActionsService(
+	config *config.Config,
+	bot *gotgbot.Bot,
+	messageSenderService *MessageSenderService,
+	adminLogService *AdminLogService,
+	moderationActionRepository *repositories.ModerationActionRepository,
+	userRepository repositories.UserRepository,
+) *ModerationActionsService {
+	return &ModerationActionsService{
+		config:                     config,
+		bot:                        bot,
+		messageSenderService:       messageSenderService,
+		adminLogService:            adminLogService,
+		moderationActionRepository: moderationActionRepository,
+		userRepository:             userRepository,
+	}
+}
+
+// ResolveUser finds a user by @username or Telegram ID
+func (s *ModerationActionsService) ResolveUser(reference string) (*repositories.User, error) {
+	reference = strings.TrimSpace(reference)
+	if tgID, err := strconv.ParseInt(reference, 10, 64); err == nil {
+		return s.userRepository.GetByTelegramID(tgID)
+	}
+	return s.userRepository.GetByTelegramUsername(strings.TrimPrefix(reference, "@"))
+}
+
+// GetOrCreateUser returns the database user of the Telegram user
+func (s *ModerationActionsService) GetOrCreateUser(tgUser *gotgbot.User) (*repositories.User, error) {
+	user, _, err := s.userRepository.GetOrFullCreate(tgUser)
+	return user, err
+}
+
+// Warn warns the member and mutes them automatically after too many warnings
+func (s *ModerationActionsService) Warn(target *repositories.User, admin *repositories.User, reason string) (*WarnResult, error) {
+	if err := s.record(target, admin, constants.MemberSanctionWarn, reason, sql.NullTime{}); err != nil {
+		return nil, err
+	}
+
+	warnings, err := s.moderationActionRepository.CountWarningsSinceLastPenalty(target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(target, fmt.Sprintf("⚠️ Ты получаешь предупреждение от администраторов клуба (%d/%d).%s",
+		warnings, constants.ModerationWarningsBeforeMute, formatReason(reason)))
+
+	result := &WarnResult{Warnings: warnings}
+	if warnings >= constants.ModerationWarningsBeforeMute {
+		autoReason := fmt.Sprintf("%d предупреждения", warnings)
+		if err := s.Mute(target, nil, constants.ModerationWarningsMuteDuration, autoReason); err != nil {
+			return result, err
+		}
+		result.Muted = true
+	}
+
+	return result, nil
+}
+
+// Mute forbids the member to send messages for the duration. Admin is nil for automatic mutes.
+func (s *ModerationActionsService) Mute(target *repositories.User, admin *repositories.User, duration time.Duration, reason string) error {
+	until := time.Now().Add(duration)
+	if _, err := s.bot.RestrictChatMember(s.chatID(), target.TgID, gotgbot.ChatPermissions{}, &gotgbot.RestrictChatMemberOpts{
+		UntilDate: until.Unix(),
+	}); err != nil {
+		return fmt.Errorf("%s: failed to mute user %d: %w", utils.GetCurrentTypeName(), target.TgID, err)
+	}
+
+	if err := s.record(target, admin, constants.MemberSanctionMute, reason, sql.NullTime{Time: until, Valid: true}); err != nil {
+		return err
+	}
+
+	s.notify(target, fmt.Sprintf("🔇 Ты сможешь снова писать в чат клуба %s.%s",
+		utils.FormatTimeUntil(duration), formatReason(reason)))
+	return nil
+}
+
+// Unmute restores the default permissions of the member
+func (s *ModerationActionsService) Unmute(target *repositories.User, admin *repositories.User, reason string) error {
+	permissions := utils.GetDefaultChatPermissions(s.bot, s.chatID())
+	if _, err := s.bot.RestrictChatMember(s.chatID(), target.TgID, permissions, nil); err != nil {
+		return fmt.Errorf("%s: failed to unmute user %d: %w", utils.GetCurrentTypeName(), target.TgID, err)
+	}
+
+	if err := s.record(target, admin, constants.MemberSanctionUnmute, reason, sql.NullTime{}); err != nil {
+		return err
+	}
+
+	s.notify(target, "🔊 Ограничение снято, ты снова можешь писать в чат клуба.")
+	return nil
+}
+
+// Ban removes the member from the chat permanently
+func (s *ModerationActionsService) Ban(target *repositories.User, admin *repositories.User, reason string) error {
+	if _, err := s.bot.BanChatMember(s.chatID(), target.TgID, nil); err != nil {
+		return fmt.Errorf("%s: failed to ban user %d: %w", utils.GetCurrentTypeName(), target.TgID, err)
+	}
+
+	return s.record(target, admin, constants.MemberSanctionBan, reason, sql.NullTime{})
+}
+
+// Unban allows the member to join the chat again
+func (s *ModerationActionsService) Unban(target *repositories.User, admin *repositories.User, reason string) error {
+	if _, err := s.bot.UnbanChatMember(s.chatID(), target.TgID, &gotgbot.UnbanChatMemberOpts{OnlyIfBanned: true}); err != nil {
+		return fmt.Errorf("%s: failed to unban user %d: %w", utils.GetCurrentTypeName(), target.TgID, err)
+	}
+
+	return s.record(target, admin, constants.MemberSanctionUnban, reason, sql.NullTime{})
+}
+
+// GetRecent returns the latest moderation actions
+func (s *ModerationActionsService) GetRecent() ([]repositories.ModerationActionWithUsers, error) {
+	return s.moderationActionRepository.GetRecent(constants.ModerationLogLimit)
+}
+
+// GetHistory returns the latest moderation actions of the user
+func (s *ModerationActionsService) GetHistory(userID int, limit int) ([]repositories.ModerationActionWithUsers, error) {
+	return s.moderationActionRepository.GetByUserID(userID, limit)
+}
+
+// record stores the action and reports it to the admin log
+func (s *ModerationActionsService) record(
+	target *repositories.User,
+	admin *repositories.User,
+	action constants.MemberSanction,
+	reason string,
+	untilAt sql.NullTime,
+) error {
+	adminUserID := sql.NullInt64{}
+	adminName := "автоматически"
+	if admin != nil {
+		adminUserID = sql.NullInt64{Int64: int64(admin.ID), Valid: true}
+		adminName = html.EscapeString(strings.TrimSpace(admin.Firstname + " " + admin.Lastname))
+	}
+
+	if _, err := s.moderationActionRepository.Create(target.ID, adminUserID, action, reason, untilAt); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("<code>/%s</code> → <a href=\"tg://user?id=%d\">%s</a> (%s)",
+		action, target.TgID, html.EscapeString(strings.TrimSpace(target.Firstname+" "+target.Lastname)), adminName)
+	if reason != "" {
+		text += "\nПричина: " + html.EscapeString(reason)
+	}
+	s.adminLogService.Log(text)
+
+	return nil
+}
+
+// notify sends a message about the action to the member, the member may have never started the bot
+func (s *ModerationActionsService) notify(target *repositories.User, text string) {
+	if err := s.messageSenderService.EnqueueHtmlOrSend(target.TgID, html.EscapeString(text), constants.MessagePriorityNotification, nil); err != nil {
+		log.Printf("%s: Failed to notify user %d: %v", utils.GetCurrentTypeName(), target.TgID, err)
+	}
+}
+
+func (s *ModerationActionsService) chatID() int64 {
+	return utils.ChatIdToFullChatId(s.config.SuperGroupChatID)
+}
+
+func formatReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return "\nПричина: " + reason
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// ModerationRulesService keeps the per-topic moderation rules and enforces them on group messages
+type ModerationRulesService struct {
+	config                   *config.Config
+	bot                      *gotgbot.Bot
+	messageSenderService     *MessageSenderService
+	membershipCacheService   *MembershipCacheService
+	moderationRuleRepository *repositories.ModerationRuleRepository
+	userRepository           repositories.UserRepository
+
+	rulesMu sync.RWMutex
+	rules   map[int]repositories.ModerationRule
+}
+
+// NewModerationRulesService creates a new moderation rules service and loads the rules
+func NewModerationRulesService(
+	config *config.Config,
+	bot *gotgbot.Bot,
+	messageSenderService *MessageSenderService,
+	membershipCacheService *MembershipCacheService,
+	moderationRuleRepository *repositories.ModerationRuleRepository,
+	userRepository repositories.UserRepository,
+) *ModerationRulesService {
+	s := &ModerationRulesService{
+		config:                   config,
+		bot:                      bot,
+		messageSenderService:     messageSenderService,
+		membershipCacheService:   membershipCacheService,
+		moderationRuleRepository: moderationRuleRepository,
+		userRepository:           userRepository,
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Printf("%s: Failed to load moderation rules: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	return s
+}
+
+// Reload reads the rules from the database. Read-only topics from the config get
+// a built-in rule unless the database has a rule for them.
+func (s *ModerationRulesService) Reload() error {
+	rules := make(map[int]repositories.ModerationRule)
+	for _, topicID := range s.config.ClosedTopicsIDs {
+		rules[topicID] = s.closedTopicRule(topicID)
+	}
+
+	dbRules, err := s.moderationRuleRepository.GetAll()
+	if err != nil {
+		s.rulesMu.Lock()
+		s.rules = rules
+		s.rulesMu.Unlock()
+		return fmt.Errorf("%s: failed to get rules: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	for _, rule := range dbRules {
+		rules[rule.TopicID] = rule
+	}
+
+	s.rulesMu.Lock()
+	s.rules = rules
+	s.rulesMu.Unlock()
+
+	log.Printf("%s: Loaded %d moderation rules", utils.GetCurrentTypeName(), len(rules))
+	return nil
+}
+
+// GetRule returns the rule of the topic
+func (s *ModerationRulesService) GetRule(topicID int) (*repositories.ModerationRule, bool) {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	rule, ok := s.rules[topicID]
+	if !ok {
+		return nil, false
+	}
+	return &rule, true
+}
+
+// GetRules returns all effective rules ordered by topic
+func (s *ModerationRulesService) GetRules() []repositories.ModerationRule {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	rules := make([]repositories.ModerationRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].TopicID < rules[j].TopicID })
+	return rules
+}
+
+// SaveRule stores the rule and applies it immediately
+func (s *ModerationRulesService) SaveRule(rule *repositories.ModerationRule) error {
+	if err := s.moderationRuleRepository.Upsert(rule); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+// DeleteRule removes the rule of the topic. Returns sql.ErrNoRows if there is no stored rule.
+func (s *ModerationRulesService) DeleteRule(topicID int) error {
+	if err := s.moderationRuleRepository.DeleteByTopicID(topicID); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+// ParseRule parses a rule from "key=value" pairs on the first line, the rest of the text is the DM template.
+// Supported keys: topic, posters, min_score, users, links, media, forwards, action, mute.
+func (s *ModerationRulesService) ParseRule(text string) (*repositories.ModerationRule, error) {
+	firstLine, template, _ := strings.Cut(strings.TrimSpace(text), "\n")
+
+	rule := &repositories.ModerationRule{
+		TopicID:        -1,
+		AllowedPosters: string(constants.ModerationPostersEveryone),
+		AllowLinks:     true,
+		AllowMedia:     true,
+		AllowForwards:  true,
+		Action:         string(constants.ModerationActionDelete),
+		MuteMinutes:    constants.ModerationDefaultMuteMinutes,
+		DmTemplate:     strings.TrimSpace(template),
+	}
+
+	for _, pair := range strings.Fields(firstLine) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("ожидается ключ=значение, получено «%s»", pair)
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "topic":
+			rule.TopicID, err = strconv.Atoi(value)
+			if err != nil || rule.TopicID < 0 {
+				return nil, fmt.Errorf("некорректный ID топика: %s", value)
+			}
+		case "posters":
+			posters := constants.ModerationPosters(strings.ToLower(value))
+			if !slices.Contains([]constants.ModerationPosters{
+				constants.ModerationPostersAdmins,
+				constants.ModerationPostersMembers,
+				constants.ModerationPostersEveryone,
+			}, posters) {
+				return nil, fmt.Errorf("posters может быть admins, members или everyone")
+			}
+			rule.AllowedPosters = string(posters)
+		case "min_score":
+			rule.MinScore, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("некорректный min_score: %s", value)
+			}
+		case "users":
+			rule.AllowedUserIDs = utils.ParseInt64List(value)
+		case "links":
+			rule.AllowLinks, err = parseYesNo(value)
+		case "media":
+			rule.AllowMedia, err = parseYesNo(value)
+		case "forwards":
+			rule.AllowForwards, err = parseYesNo(value)
+		case "action":
+			action := constants.ModerationAction(strings.ToLower(value))
+			if !slices.Contains([]constants.ModerationAction{
+				constants.ModerationActionDelete,
+				constants.ModerationActionWarn,
+				constants.ModerationActionMute,
+				constants.ModerationActionForward,
+			}, action) {
+				return nil, fmt.Errorf("action может быть delete, warn, mute или forward")
+			}
+			rule.Action = string(action)
+		case "mute":
+			rule.MuteMinutes, err = strconv.Atoi(value)
+			if err != nil || rule.MuteMinutes <= 0 || rule.MuteMinutes > int(utils.MaxShortDuration/time.Minute) {
+				return nil, fmt.Errorf("некорректная длительность mute: %s", value)
+			}
+		default:
+			return nil, fmt.Errorf("неизвестный ключ: %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if rule.TopicID < 0 {
+		return nil, fmt.Errorf("не указан topic")
+	}
+
+	return rule, nil
+}
+
+// FindViolation checks the message against the rule and returns a human readable reason of the violation
+func (s *ModerationRulesService) FindViolation(msg *gotgbot.Message, rule *repositories.ModerationRule) (string, bool) {
+	// Anonymous admins post on behalf of GroupAnonymousBot
+	if msg.From.IsBot && msg.From.Username == "GroupAnonymousBot" {
+		return "", false
+	}
+
+	// Users from the rule's list may always post
+	if slices.Contains(rule.AllowedUserIDs, msg.From.Id) {
+		return "", false
+	}
+
+	// Admins may always post
+	chatMember, err := s.membershipCacheService.GetChatMember(msg.Chat.Id, msg.From.Id, nil)
+	if err != nil {
+		log.Printf("%s: Failed to get chat member %d: %v", utils.GetCurrentTypeName(), msg.From.Id, err)
+	} else if status := chatMember.GetStatus(); status == "administrator" || status == "creator" {
+		return "", false
+	}
+
+	switch constants.ModerationPosters(rule.AllowedPosters) {
+	case constants.ModerationPostersAdmins:
+		return "канал предназначен только для чтения", true
+	case constants.ModerationPostersMembers:
+		if rule.MinScore > 0 {
+			score := 0
+			user, err := s.userRepository.GetByTelegramID(msg.From.Id)
+			if err != nil && err != sql.ErrNoRows {
+				log.Printf("%s: Failed to get user %d: %v", utils.GetCurrentTypeName(), msg.From.Id, err)
+				return "", false
+			}
+			if user != nil {
+				score = user.Score
+			}
+			if score < rule.MinScore {
+				return fmt.Sprintf("писать в канал можно с кармой от %d", rule.MinScore), true
+			}
+		}
+	}
+
+	if !rule.AllowLinks && utils.MessageHasLinks(msg) {
+		return "ссылки в этом канале запрещены", true
+	}
+	if !rule.AllowMedia && utils.MessageHasMedia(msg) {
+		return "медиафайлы в этом канале запрещены", true
+	}
+	if !rule.AllowForwards && utils.IsForwardedMessage(msg) {
+		return "пересланные сообщения в этом канале запрещены", true
+	}
+
+	return "", false
+}
+
+// Enforce applies the rule's action to the message that broke it
+func (s *ModerationRulesService) Enforce(msg *gotgbot.Message, rule *repositories.ModerationRule, reason string) error {
+	topicUrl := fmt.Sprintf("https://t.me/c/%s/%d", strconv.FormatInt(msg.Chat.Id, 10)[4:], msg.MessageThreadId)
+	dmText := strings.NewReplacer(
+		constants.ModerationTemplateTopicUrl, topicUrl,
+		constants.ModerationTemplateReason, reason,
+	).Replace(rule.DmTemplate)
+
+	switch constants.ModerationAction(rule.Action) {
+	case constants.ModerationActionWarn:
+		warningText := dmText
+		if warningText == "" {
+			warningText = strings.ReplaceAll(constants.ModerationWarnDefaultText, constants.ModerationTemplateReason, reason)
+		}
+		log.Printf("%s: Warned user %d in topic %s: %s", utils.GetCurrentTypeName(), msg.From.Id, topicUrl, reason)
+		// The warning and the message are removed after a delay to keep the topic clean
+		return s.messageSenderService.ReplyWithCleanupAfterDelayWithPing(msg, warningText, constants.ModerationWarnCleanupSeconds, &gotgbot.SendMessageOpts{
+			ParseMode: "Markdown",
+		})
+
+	case constants.ModerationActionForward:
+		if err := s.moveToForwardingTopic(msg, topicUrl); err != nil {
+			log.Printf("%s: Failed to move message to forwarding topic: %v", utils.GetCurrentTypeName(), err)
+		}
+
+	case constants.ModerationActionMute:
+		until := time.Now().Add(time.Duration(rule.MuteMinutes) * time.Minute)
+		if _, err := s.bot.RestrictChatMember(msg.Chat.Id, msg.From.Id, gotgbot.ChatPermissions{}, &gotgbot.RestrictChatMemberOpts{
+			UntilDate: until.Unix(),
+		}); err != nil {
+			log.Printf("%s: Failed to mute user %d: %v", utils.GetCurrentTypeName(), msg.From.Id, err)
+		}
+	}
+
+	if _, err := msg.Delete(s.bot, nil); err != nil {
+		return fmt.Errorf("%s: failed to delete message: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	log.Printf("%s: Applied %s to message of user %d in topic %s: %s",
+		utils.GetCurrentTypeName(), rule.Action, msg.From.Id, topicUrl, reason)
+
+	if dmText == "" {
+		return nil
+	}
+
+	// Send the DM and a copy of the removed message to the author
+	if err := s.messageSenderService.SendMarkdown(msg.From.Id, dmText, nil); err != nil {
+		return fmt.Errorf("%s: failed to send message about deletion: %w", utils.GetCurrentTypeName(), err)
+	}
+	if _, err := s.messageSenderService.SendCopy(msg.From.Id, nil, msg.GetText(), msg.GetEntities(), msg); err != nil {
+		return fmt.Errorf("%s: failed to send copy message: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return nil
+}
+
+// moveToForwardingTopic copies the message to the forwarding topic with a link to the original topic
+func (s *ModerationRulesService) moveToForwardingTopic(msg *gotgbot.Message, topicUrl string) error {
+	author := msg.From.FirstName
+	if msg.From.Username != "" {
+		author = "@" + msg.From.Username
+	}
+
+	prefix := fmt.Sprintf("↪️ Сообщение %s перенесено из ", author)
+	linkText := "канала"
+	firstLine := prefix + linkText
+	firstLineLength := int64(utils.Utf16CodeUnitCount(firstLine))
+
+	entities := []gotgbot.MessageEntity{
+		{Type: "italic", Offset: 0, Length: firstLineLength},
+		{Type: "text_link", Offset: int64(utils.Utf16CodeUnitCount(prefix)), Length: int64(utils.Utf16CodeUnitCount(linkText)), Url: topicUrl},
+	}
+	for _, entity := range msg.GetEntities() {
+		entity.Offset += firstLineLength + 1 // +1 for the newline character
+		entities = append(entities, entity)
+	}
+
+	_, err := s.messageSenderService.SendCopy(msg.Chat.Id, &s.config.ForwardingTopicID, firstLine+"\n"+msg.GetText(), entities, msg)
+	return err
+}
+
+// closedTopicRule builds the built-in rule of a read-only topic
+func (s *ModerationRulesService) closedTopicRule(topicID int) repositories.ModerationRule {
+	return repositories.ModerationRule{
+		TopicID:        topicID,
+		AllowedPosters: string(constants.ModerationPostersAdmins),
+		AllowLinks:     true,
+		AllowMedia:     true,
+		AllowForwards:  true,
+		Action:         string(constants.ModerationActionDelete),
+		MuteMinutes:    constants.ModerationDefaultMuteMinutes,
+		DmTemplate:     constants.ModerationClosedTopicDmTemplate,
+	}
+}
+
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true", "да":
+		return true, nil
+	case "no", "false", "нет":
+		return false, nil
+	default:
+		return false, fmt.Errorf("ожидается yes или no, получено «%s»", value)
+	}
+}
@@ -3,42 +3,119 @@ package services
 import (
 	"evo-bot-go/internal/config"
 	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
 	"evo-bot-go/internal/utils"
 	"log"
+	"slices"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 )
 
 type PermissionsService struct {
-	config               *config.Config
-	bot                  *gotgbot.Bot
-	messageSenderService *MessageSenderService
+	config                 *config.Config
+	bot                    *gotgbot.Bot
+	messageSenderService   *MessageSenderService
+	membershipCacheService *MembershipCacheService
+	userRoleRepository     repositories.UserRoleRepository
 }
 
 func NewPermissionsService(
 	config *config.Config,
 	bot *gotgbot.Bot,
 	messageSenderService *MessageSenderService,
+	membershipCacheService *MembershipCacheService,
+	userRoleRepository repositories.UserRoleRepository,
 ) *PermissionsService {
 	return &PermissionsService{
-		config:               config,
-		bot:                  bot,
-		messageSenderService: messageSenderService,
+		config:                 config,
+		bot:                    bot,
+		messageSenderService:   messageSenderService,
+		membershipCacheService: membershipCacheService,
+		userRoleRepository:     userRoleRepository,
 	}
 }
 
-// CheckAdminPermissions checks if the user has admin permissions and returns an appropriate error response
+// GetRoles returns the effective roles of the user ordered from the most powerful.
+// The configured admin is the owner, admins of the club chat are admins, members of the chat are members,
+// all other roles are granted through the bot and stored in the database.
+// The stored roles work only while the user is in the club chat: they are kept when the user leaves,
+// so they are back if the user returns, but a former member has no capabilities.
+// If the membership can not be checked, the stored roles are not trusted either.
+func (s *PermissionsService) GetRoles(userID int64) []constants.Role {
+	var roles []constants.Role
+	if userID == s.config.AdminUserID {
+		roles = append(roles, constants.RoleOwner)
+	}
+
+	chatMember, err := s.membershipCacheService.GetChatMember(utils.ChatIdToFullChatId(s.config.SuperGroupChatID), userID, nil)
+	if err != nil {
+		log.Printf("%s: Failed to get chat member %d: %v", utils.GetCurrentTypeName(), userID, err)
+	} else {
+		switch chatMember.GetStatus() {
+		case "administrator", "creator":
+			roles = append(roles, constants.RoleAdmin, constants.RoleMember)
+			roles = append(roles, s.getStoredRoles(userID)...)
+		case "left", "kicked":
+		default:
+			roles = append(roles, constants.RoleMember)
+			roles = append(roles, s.getStoredRoles(userID)...)
+		}
+	}
+
+	effectiveRoles := make([]constants.Role, 0, len(roles))
+	for _, role := range constants.AllRoles {
+		if slices.Contains(roles, role) {
+			effectiveRoles = append(effectiveRoles, role)
+		}
+	}
+	return effectiveRoles
+}
+
+// HasRole checks if the user has the role
+func (s *PermissionsService) HasRole(userID int64, role constants.Role) bool {
+	return slices.Contains(s.GetRoles(userID), role)
+}
+
+// HasCapability checks if any role of the user allows the capability
+func (s *PermissionsService) HasCapability(userID int64, capability constants.Capability) bool {
+	if userID == s.config.AdminUserID {
+		return true
+	}
+
+	for _, role := range s.GetRoles(userID) {
+		if slices.Contains(constants.RoleCapabilities[role], capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff checks if the user has any role with management capabilities
+func (s *PermissionsService) IsStaff(userID int64) bool {
+	if userID == s.config.AdminUserID {
+		return true
+	}
+
+	for _, role := range s.GetRoles(userID) {
+		if len(constants.RoleCapabilities[role]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCapability checks if the user has the capability and returns an appropriate error response
 // Returns true if user has permission, false otherwise
-func (s *PermissionsService) CheckAdminPermissions(msg *gotgbot.Message, commandName string) bool {
-	if !utils.IsUserAdminOrCreator(s.bot, msg.From.Id, s.config) {
+func (s *PermissionsService) CheckCapability(msg *gotgbot.Message, capability constants.Capability, commandName string) bool {
+	if !s.HasCapability(msg.From.Id, capability) {
 		if err := s.messageSenderService.Reply(
 			msg,
-			"Эта команда доступна только администраторам.",
+			"Эта команда недоступна для твоей роли.",
 			nil,
 		); err != nil {
-			log.Printf("%s: Failed to send admin-only message: %v", utils.GetCurrentTypeName(), err)
+			log.Printf("%s: Failed to send no-capability message: %v", utils.GetCurrentTypeName(), err)
 		}
-		log.Printf("%s: User %d tried to use %s without admin rights", utils.GetCurrentTypeName(), msg.From.Id, commandName)
+		log.Printf("%s: User %d tried to use %s without %s capability", utils.GetCurrentTypeName(), msg.From.Id, commandName, capability)
 		return false
 	}
 
@@ -65,8 +142,13 @@ func (s *PermissionsService) CheckPrivateChatType(msg *gotgbot.Message) bool {
 	return true
 }
 
+// IsClubMember checks if the user is a member of the club chat
+func (s *PermissionsService) IsClubMember(userID int64) bool {
+	return utils.IsUserClubMember(s.membershipCacheService, userID, s.config)
+}
+
 func (s *PermissionsService) CheckClubMemberPermissions(msg *gotgbot.Message, commandName string) bool {
-	if !utils.IsUserClubMember(s.bot, msg.From.Id, s.config) {
+	if !s.IsClubMember(msg.From.Id) {
 		if err := s.messageSenderService.Reply(
 			msg,
 			"Эта команда доступна только участникам клуба.",
@@ -81,10 +163,10 @@ func (s *PermissionsService) CheckClubMemberPermissions(msg *gotgbot.Message, co
 	return true
 }
 
-// CheckAdminAndPrivateChat combines permission and chat type checking for admin-only private commands
+// CheckCapabilityAndPrivateChat combines capability and chat type checking for management commands
 // Returns true if all checks pass, false otherwise
-func (s *PermissionsService) CheckAdminAndPrivateChat(msg *gotgbot.Message, commandName string) bool {
-	if !s.CheckAdminPermissions(msg, commandName) {
+func (s *PermissionsService) CheckCapabilityAndPrivateChat(msg *gotgbot.Message, capability constants.Capability, commandName string) bool {
+	if !s.CheckCapability(msg, capability, commandName) {
 		return false
 	}
 
@@ -94,3 +176,12 @@ func (s *PermissionsService) CheckAdminAndPrivateChat(msg *gotgbot.Message, comm
 
 	return true
 }
+
+func (s *PermissionsService) getStoredRoles(userID int64) []constants.Role {
+	roles, err := s.userRoleRepository.GetRolesByTelegramID(userID)
+	if err != nil {
+		log.Printf("%s: Failed to get roles of user %d: %v", utils.GetCurrentTypeName(), userID, err)
+		return nil
+	}
+	return roles
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/memory"
+	"evo-bot-go/internal/telegramtest"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingUserRoleRepository fails every request like an unavailable database
+type failingUserRoleRepository struct {
+	repositories.UserRoleRepository
+}
+
+func (failingUserRoleRepository) GetRolesByTelegramID(tgID int64) ([]constants.Role, error) {
+	return nil, errors.New("database is unavailable")
+}
+
+func TestPermissionsService_Roles(t *testing.T) {
+	const (
+		ownerID     = 1001
+		moderatorID = 2001
+	)
+
+	tests := []struct {
+		name        string
+		userID      int64
+		status      string // status in the club chat, empty if the lookup fails
+		storedRole  constants.Role
+		failingRepo bool
+
+		wantRoles    []constants.Role
+		wantModerate bool
+		wantStaff    bool
+	}{
+		{
+			name:   "configured owner who is not in the chat",
+			userID: ownerID, status: "left",
+			wantRoles:    []constants.Role{constants.RoleOwner},
+			wantModerate: true, wantStaff: true,
+		},
+		{
+			name:   "chat admin",
+			userID: moderatorID, status: "administrator",
+			wantRoles:    []constants.Role{constants.RoleAdmin, constants.RoleMember},
+			wantModerate: true, wantStaff: true,
+		},
+		{
+			name:   "chat creator with a stored role",
+			userID: moderatorID, status: "creator", storedRole: constants.RoleCoffeeManager,
+			wantRoles:    []constants.Role{constants.RoleAdmin, constants.RoleCoffeeManager, constants.RoleMember},
+			wantModerate: true, wantStaff: true,
+		},
+		{
+			name:   "member with a stored role",
+			userID: moderatorID, status: "member", storedRole: constants.RoleModerator,
+			wantRoles:    []constants.Role{constants.RoleModerator, constants.RoleMember},
+			wantModerate: true, wantStaff: true,
+		},
+		{
+			name:   "member with a stored role of another capability",
+			userID: moderatorID, status: "member", storedRole: constants.RoleEventOrganizer,
+			wantRoles:    []constants.Role{constants.RoleEventOrganizer, constants.RoleMember},
+			wantModerate: false, wantStaff: true,
+		},
+		{
+			name:   "member without roles",
+			userID: moderatorID, status: "member",
+			wantRoles: []constants.Role{constants.RoleMember},
+		},
+		{
+			name:   "stored role of a user who left the chat",
+			userID: moderatorID, status: "left", storedRole: constants.RoleModerator,
+			wantRoles: []constants.Role{},
+		},
+		{
+			name:   "stored role of a banned user",
+			userID: moderatorID, status: "kicked", storedRole: constants.RoleModerator,
+			wantRoles: []constants.Role{},
+		},
+		{
+			name:   "stored role when the membership can not be checked",
+			userID: moderatorID, storedRole: constants.RoleModerator,
+			wantRoles: []constants.Role{},
+		},
+		{
+			name:   "repository error",
+			userID: moderatorID, status: "member", failingRepo: true,
+			wantRoles: []constants.Role{constants.RoleMember},
+		},
+		{
+			name:   "repository error for the owner",
+			userID: ownerID, status: "member", failingRepo: true,
+			wantRoles:    []constants.Role{constants.RoleOwner, constants.RoleMember},
+			wantModerate: true, wantStaff: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := telegramtest.NewServer(t)
+			server.Handle("getChatMember", func(r telegramtest.Request) (interface{}, error) {
+				if tt.status == "" {
+					return nil, &telegramtest.APIError{Code: 400, Description: "Bad Request: chat not found"}
+				}
+				return map[string]interface{}{
+					"status": tt.status,
+					"user":   gotgbot.User{Id: r.Int64("user_id"), FirstName: "User"},
+				}, nil
+			})
+
+			appConfig := &config.Config{SuperGroupChatID: 1234567890, AdminUserID: ownerID, MembershipCacheTTL: time.Hour}
+			store := memory.NewStore()
+			var userRoleRepository repositories.UserRoleRepository = store.UserRoles()
+			if tt.failingRepo {
+				userRoleRepository = failingUserRoleRepository{}
+			}
+			if tt.storedRole != "" {
+				userID, err := store.Users().Create(tt.userID, "Ivan", "Petrov", "ivan")
+				require.NoError(t, err)
+				_, err = store.UserRoles().Grant(userID, tt.storedRole, ownerID)
+				require.NoError(t, err)
+			}
+
+			service := NewPermissionsService(appConfig, server.Bot, nil,
+				NewMembershipCacheService(appConfig, server.Bot), userRoleRepository)
+
+			assert.Equal(t, tt.wantRoles, service.GetRoles(tt.userID))
+			assert.Equal(t, tt.wantModerate, service.HasCapability(tt.userID, constants.CapabilityModerate))
+			assert.Equal(t, tt.wantStaff, service.IsStaff(tt.userID))
+		})
+	}
+}
@@ -1,18 +1,47 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/formatters"
+	"evo-bot-go/internal/utils"
+	"fmt"
+	"log"
+	"strings"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 )
 
+// ErrInvalidProfileFieldValue is returned when the answer does not match the format of the profile field
+var ErrInvalidProfileFieldValue = errors.New("invalid profile field value")
+
+// ErrProfileTooLong is returned when the published profile does not fit into the Telegram limits
+var ErrProfileTooLong = errors.New("profile is too long to publish")
+
+// ErrProfileFollowUpNotPublished is returned when the profile photo is published but its follow-up message is not
+var ErrProfileFollowUpNotPublished = errors.New("profile follow-up is not published")
+
 type ProfileService struct {
-	bot *gotgbot.Bot
+	bot                  *gotgbot.Bot
+	config               *config.Config
+	messageSenderService *MessageSenderService
+	profileRepository    repositories.ProfileRepository
 }
 
-func NewProfileService(bot *gotgbot.Bot) *ProfileService {
+func NewProfileService(
+	bot *gotgbot.Bot,
+	config *config.Config,
+	messageSenderService *MessageSenderService,
+	profileRepository repositories.ProfileRepository,
+) *ProfileService {
 	return &ProfileService{
-		bot: bot,
+		bot:                  bot,
+		config:               config,
+		messageSenderService: messageSenderService,
+		profileRepository:    profileRepository,
 	}
 }
 
@@ -36,3 +65,206 @@ func (s *ProfileService) IsProfileComplete(user *repositories.User, profile *rep
 
 	return true
 }
+
+// PublishProfile publishes the profile to the intro topic and returns the ID of the published message.
+// The profile with a photo is published as the photo with the caption, the rest of a long profile
+// goes to the follow-up message. The previously published message is edited if it is of the same kind,
+// otherwise it is replaced with a new one. If the follow-up message fails, the published photo is still saved
+// and ErrProfileFollowUpNotPublished is returned with its ID.
+func (s *ProfileService) PublishProfile(user *repositories.User, profile *repositories.Profile, withoutPreview bool) (int64, error) {
+	if excess := formatters.PublicProfileExcessLength(user, profile); excess > 0 {
+		return 0, fmt.Errorf("%s: %w by %d characters", utils.GetCurrentTypeName(), ErrProfileTooLong, excess)
+	}
+
+	chatID := utils.ChatIdToFullChatId(s.config.SuperGroupChatID)
+	withPhoto := profile.PhotoFileID != ""
+	linkPreviewOptions := &gotgbot.LinkPreviewOptions{IsDisabled: withoutPreview}
+
+	var text, followUp string
+	if withPhoto {
+		text, followUp = formatters.FormatPublicProfileForPhoto(user, profile)
+	} else {
+		text = formatters.FormatPublicProfileForMessage(user, profile, false)
+	}
+
+	messageID := int64(0)
+	if profile.PublishedMessageID.Valid && profile.PublishedWithPhoto == withPhoto {
+		var err error
+		if withPhoto {
+			_, _, err = s.bot.EditMessageMedia(
+				gotgbot.InputMediaPhoto{
+					Media:     gotgbot.InputFileByID(profile.PhotoFileID),
+					Caption:   text,
+					ParseMode: "HTML",
+				},
+				&gotgbot.EditMessageMediaOpts{
+					ChatId:    chatID,
+					MessageId: profile.PublishedMessageID.Int64,
+				})
+		} else {
+			_, _, err = s.bot.EditMessageText(
+				text,
+				&gotgbot.EditMessageTextOpts{
+					ChatId:             chatID,
+					MessageId:          profile.PublishedMessageID.Int64,
+					ParseMode:          "HTML",
+					LinkPreviewOptions: linkPreviewOptions,
+				})
+		}
+		if err == nil || isMessageNotModifiedError(err) {
+			messageID = profile.PublishedMessageID.Int64
+		} else {
+			log.Printf("%s: Failed to edit published profile, sending a new message: %v", utils.GetCurrentTypeName(), err)
+		}
+	}
+
+	edited := messageID != 0
+	if !edited {
+		var publishedMsg *gotgbot.Message
+		var err error
+		if withPhoto {
+			publishedMsg, err = s.messageSenderService.SendPhotoWithReturnMessage(
+				chatID,
+				gotgbot.InputFileByID(profile.PhotoFileID),
+				&gotgbot.SendPhotoOpts{
+					Caption:         text,
+					ParseMode:       "HTML",
+					MessageThreadId: int64(s.config.IntroTopicID),
+				})
+		} else {
+			publishedMsg, err = s.messageSenderService.SendHtmlWithReturnMessage(
+				chatID,
+				text,
+				&gotgbot.SendMessageOpts{
+					MessageThreadId:    int64(s.config.IntroTopicID),
+					LinkPreviewOptions: linkPreviewOptions,
+				})
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%s: failed to publish profile: %w", utils.GetCurrentTypeName(), err)
+		}
+		messageID = publishedMsg.MessageId
+
+		// The previous message of the other kind can not be edited into the new one
+		if profile.PublishedMessageID.Valid {
+			s.deletePublishedMessage(chatID, profile.PublishedMessageID.Int64)
+		}
+	}
+
+	var followUpErr error
+	extraMessageID := sql.NullInt64{}
+	if followUp != "" {
+		if edited && profile.PublishedExtraMessageID.Valid {
+			_, _, err := s.bot.EditMessageText(
+				followUp,
+				&gotgbot.EditMessageTextOpts{
+					ChatId:             chatID,
+					MessageId:          profile.PublishedExtraMessageID.Int64,
+					ParseMode:          "HTML",
+					LinkPreviewOptions: linkPreviewOptions,
+				})
+			if err == nil || isMessageNotModifiedError(err) {
+				extraMessageID = profile.PublishedExtraMessageID
+			} else {
+				log.Printf("%s: Failed to edit published profile follow-up, sending a new message: %v", utils.GetCurrentTypeName(), err)
+			}
+		}
+
+		if !extraMessageID.Valid {
+			if profile.PublishedExtraMessageID.Valid {
+				s.deletePublishedMessage(chatID, profile.PublishedExtraMessageID.Int64)
+			}
+			extraMsg, err := s.messageSenderService.SendHtmlWithReturnMessage(
+				chatID,
+				followUp,
+				&gotgbot.SendMessageOpts{
+					MessageThreadId:    int64(s.config.IntroTopicID),
+					LinkPreviewOptions: linkPreviewOptions,
+					ReplyParameters: &gotgbot.ReplyParameters{
+						MessageId:                messageID,
+						AllowSendingWithoutReply: true,
+					},
+				})
+			if err != nil {
+				followUpErr = fmt.Errorf("%s: %w: %v", utils.GetCurrentTypeName(), ErrProfileFollowUpNotPublished, err)
+			} else {
+				extraMessageID = sql.NullInt64{Int64: extraMsg.MessageId, Valid: true}
+			}
+		}
+	} else if profile.PublishedExtraMessageID.Valid {
+		s.deletePublishedMessage(chatID, profile.PublishedExtraMessageID.Int64)
+	}
+
+	var extraMessageIDValue interface{}
+	if extraMessageID.Valid {
+		extraMessageIDValue = extraMessageID.Int64
+	}
+	if err := s.profileRepository.Update(profile.ID, map[string]interface{}{
+		"published_message_id":       messageID,
+		"published_with_photo":       withPhoto,
+		"published_extra_message_id": extraMessageIDValue,
+	}); err != nil {
+		return 0, fmt.Errorf("%s: failed to update published message ID: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	profile.PublishedMessageID = sql.NullInt64{Int64: messageID, Valid: true}
+	profile.PublishedWithPhoto = withPhoto
+	profile.PublishedExtraMessageID = extraMessageID
+	return messageID, followUpErr
+}
+
+// deletePublishedMessage removes the outdated message of the published profile from the intro topic
+func (s *ProfileService) deletePublishedMessage(chatID int64, messageID int64) {
+	if _, err := s.bot.DeleteMessage(chatID, messageID, nil); err != nil {
+		log.Printf("%s: Failed to delete outdated profile message %d: %v", utils.GetCurrentTypeName(), messageID, err)
+	}
+}
+
+// isMessageNotModifiedError reports whether Telegram refused the edit because nothing changed
+func isMessageNotModifiedError(err error) bool {
+	return strings.Contains(err.Error(), "are exactly the same")
+}
+
+// UpdateProfileField parses the answer of the user and saves it to the optional profile field,
+// utils.ProfileClearValue clears the field
+func (s *ProfileService) UpdateProfileField(profileID int, field constants.ProfileField, input string) error {
+	fields, err := parseProfileField(field, input)
+	if err != nil {
+		return err
+	}
+
+	if err := s.profileRepository.Update(profileID, fields); err != nil {
+		return fmt.Errorf("%s: failed to update %s of profile %d: %w", utils.GetCurrentTypeName(), field, profileID, err)
+	}
+	return nil
+}
+
+// parseProfileField returns the columns to update for the answer
+func parseProfileField(field constants.ProfileField, input string) (map[string]interface{}, error) {
+	switch field {
+	case constants.ProfileFieldLocation, constants.ProfileFieldJob:
+		first, second, err := utils.ParseProfileTextPair(input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProfileFieldValue, err)
+		}
+		if field == constants.ProfileFieldLocation {
+			return map[string]interface{}{"city": first, "country": second}, nil
+		}
+		return map[string]interface{}{"job_title": first, "company": second}, nil
+	case constants.ProfileFieldSkills, constants.ProfileFieldCanHelpWith,
+		constants.ProfileFieldLookingFor, constants.ProfileFieldLanguages:
+		tags, err := utils.ParseProfileTags(input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProfileFieldValue, err)
+		}
+		// The fields of the tags are named like their columns
+		return map[string]interface{}{string(field): repositories.ProfileTags(tags)}, nil
+	case constants.ProfileFieldLinks:
+		links, invalid := utils.ParseProfileLinks(input)
+		if len(invalid) > 0 {
+			return nil, fmt.Errorf("%w: invalid links %s", ErrInvalidProfileFieldValue, strings.Join(invalid, ", "))
+		}
+		return map[string]interface{}{"links": repositories.ProfileLinks(links)}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidProfileFieldValue, field)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/repositories"
 	"evo-bot-go/internal/utils"
 
@@ -20,11 +21,11 @@ type RandomCoffeeService struct {
 	config          *config.Config
 	pollSender      *PollSenderService
 	messageSender   *MessageSenderService
-	pollRepo        *repositories.RandomCoffeePollRepository
-	participantRepo *repositories.RandomCoffeeParticipantRepository
-	profileRepo     *repositories.ProfileRepository
-	pairRepo        *repositories.RandomCoffeePairRepository
-	userRepo        *repositories.UserRepository
+	pollRepo        repositories.RandomCoffeePollRepository
+	participantRepo repositories.RandomCoffeeParticipantRepository
+	profileRepo     repositories.ProfileRepository
+	pairRepo        repositories.RandomCoffeePairRepository
+	userRepo        repositories.UserRepository
 }
 
 // NewRandomCoffeeService creates a new random coffee poll service
@@ -33,11 +34,11 @@ func NewRandomCoffeeService(
 	config *config.Config,
 	pollSender *PollSenderService,
 	messageSender *MessageSenderService,
-	pollRepo *repositories.RandomCoffeePollRepository,
-	participantRepo *repositories.RandomCoffeeParticipantRepository,
-	profileRepo *repositories.ProfileRepository,
-	pairRepo *repositories.RandomCoffeePairRepository,
-	userRepo *repositories.UserRepository,
+	pollRepo repositories.RandomCoffeePollRepository,
+	participantRepo repositories.RandomCoffeeParticipantRepository,
+	profileRepo repositories.ProfileRepository,
+	pairRepo repositories.RandomCoffeePairRepository,
+	userRepo repositories.UserRepository,
 ) *RandomCoffeeService {
 	return &RandomCoffeeService{
 		bot:             bot,
@@ -74,7 +75,8 @@ func (s *RandomCoffeeService) SendPoll(ctx context.Context) error {
 	opts := &gotgbot.SendMessageOpts{
 		MessageThreadId: int64(s.config.RandomCoffeeTopicID),
 	}
-	err := s.messageSender.SendHtml(chatID, message, opts)
+	// The announcement is sent right away, it has to come before the poll
+	_, err := s.messageSender.SendHtmlWithPriority(chatID, message, opts, constants.MessagePriorityBulk)
 	if err != nil {
 		return fmt.Errorf("%s: Failed to send regular message: %v", utils.GetCurrentTypeName(), err)
 	}
@@ -280,11 +282,24 @@ func (s *RandomCoffeeService) GenerateAndSendPairs() error {
 	messageBuilder.WriteString("\n🗓 День, время и формат встречи вы выбираете сами. Просто напиши своей паре в личку, когда и в каком формате тебе удобно встретиться.")
 
 	// Send the pairing message
+	messageBuilder.WriteString("\n\n✅ После встречи нажми кнопку ниже — карма начисляется каждому, кто подтвердит встречу.")
+
 	opts := &gotgbot.SendMessageOpts{
 		MessageThreadId: int64(s.config.RandomCoffeeTopicID),
-	}
-
-	message, err := s.messageSender.SendHtmlWithReturnMessage(chatID, messageBuilder.String(), opts)
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{
+					{
+						Text:         "✅ Мы встретились",
+						CallbackData: fmt.Sprintf("%s%d", constants.RandomCoffeeMetCallbackPrefix, latestPoll.ID),
+					},
+				},
+			},
+		},
+	}
+
+	// The pairs are sent right away to pin the message
+	message, err := s.messageSender.SendHtmlWithPriority(chatID, messageBuilder.String(), opts, constants.MessagePriorityBulk)
 	if err != nil {
 		return fmt.Errorf("%s: error sending pairing message to chat %d: %w", utils.GetCurrentTypeName(), chatID, err)
 	}
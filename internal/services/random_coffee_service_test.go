@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomCoffeeService_generateSmartPairs(t *testing.T) {
+	store := memory.NewStore()
+	service := &RandomCoffeeService{pairRepo: store.RandomCoffeePairs()}
+
+	participants := make([]repositories.User, 0, 5)
+	for i, name := range []string{"Ivan", "Petr", "Anna", "Olga", "Oleg"} {
+		id, err := store.Users().Create(int64(1000+i), name, "", "")
+		require.NoError(t, err)
+		user, err := store.Users().GetByID(id)
+		require.NoError(t, err)
+		participants = append(participants, *user)
+	}
+	ivan, petr, anna, olga := participants[0], participants[1], participants[2], participants[3]
+
+	weekStart := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	lastPollID, err := store.RandomCoffeePolls().CreatePoll(repositories.RandomCoffeePoll{
+		MessageID: 1, WeekStartDate: weekStart, TelegramPollID: "last",
+	})
+	require.NoError(t, err)
+	pollID, err := store.RandomCoffeePolls().CreatePoll(repositories.RandomCoffeePoll{
+		MessageID: 2, WeekStartDate: weekStart.AddDate(0, 0, 7), TelegramPollID: "current",
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.RandomCoffeePairs().CreatePair(int(lastPollID), ivan.ID, petr.ID))
+	require.NoError(t, store.RandomCoffeePairs().CreatePair(int(lastPollID), anna.ID, olga.ID))
+
+	// Only the first four can pair up, so the last week pairs must not repeat
+	pairs, unpaired, err := service.generateSmartPairs(participants[:4], int(pollID))
+	require.NoError(t, err)
+	assert.Nil(t, unpaired)
+	require.Len(t, pairs, 2)
+
+	for _, pair := range pairs {
+		lastWeek, err := store.RandomCoffeePairs().GetPairByPollAndUser(int(lastPollID), pair.User2.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, []int64{lastWeek.User1ID, lastWeek.User2ID}, int64(pair.User1.ID),
+			"%s x %s were paired last week", pair.User1.Firstname, pair.User2.Firstname)
+
+		saved, err := store.RandomCoffeePairs().GetPairByPollAndUser(int(pollID), pair.User1.ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{int64(pair.User1.ID), int64(pair.User2.ID)}, []int64{saved.User1ID, saved.User2ID})
+	}
+
+	t.Run("odd number of participants", func(t *testing.T) {
+		nextPollID, err := store.RandomCoffeePolls().CreatePoll(repositories.RandomCoffeePoll{
+			MessageID: 3, WeekStartDate: weekStart.AddDate(0, 0, 14), TelegramPollID: "next",
+		})
+		require.NoError(t, err)
+
+		pairs, unpaired, err := service.generateSmartPairs(participants, int(nextPollID))
+		require.NoError(t, err)
+		assert.Len(t, pairs, 2)
+		assert.NotNil(t, unpaired)
+	})
+
+	t.Run("not enough participants", func(t *testing.T) {
+		_, _, err := service.generateSmartPairs(participants[:1], int(pollID))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// ScoreService awards reputation points for member activity and keeps the score ledger
+type ScoreService struct {
+	config          *config.Config
+	scoreRepository repositories.ScoreRepository
+	userRepository  repositories.UserRepository
+}
+
+// NewScoreService creates a new score service
+func NewScoreService(
+	config *config.Config,
+	scoreRepository repositories.ScoreRepository,
+	userRepository repositories.UserRepository,
+) *ScoreService {
+	return &ScoreService{
+		config:          config,
+		scoreRepository: scoreRepository,
+		userRepository:  userRepository,
+	}
+}
+
+// Award adds the points defined by ScoreRules for the given reason.
+// The reference identifies the activity, so the same activity is never awarded twice.
+func (s *ScoreService) Award(userID int, reason constants.ScoreReason, reference string) (bool, error) {
+	points, ok := constants.ScoreRules[reason]
+	if !ok {
+		return false, fmt.Errorf("%s: no score rule for reason %s", utils.GetCurrentTypeName(), reason)
+	}
+
+	awarded, err := s.scoreRepository.AddEntry(userID, points, reason, reference, "")
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to award %s to user %d: %w", utils.GetCurrentTypeName(), reason, userID, err)
+	}
+
+	if awarded {
+		log.Printf("%s: Awarded %d points to user %d for %s (%s)", utils.GetCurrentTypeName(), points, userID, reason, reference)
+	}
+
+	return awarded, nil
+}
+
+// AwardTelegramUser awards points to a Telegram user, creating the user record when needed
+func (s *ScoreService) AwardTelegramUser(tgUser *gotgbot.User, reason constants.ScoreReason, reference string) (bool, error) {
+	user, err := s.userRepository.GetOrCreate(tgUser)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get user: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return s.Award(user.ID, reason, reference)
+}
+
+// AwardEventRegistrations awards karma to everyone with a confirmed registration for the event,
+// the bot does not know who actually came, so the karma is paid for the registration
+func (s *ScoreService) AwardEventRegistrations(eventID int, registrations []repositories.EventRegistrationWithUser) int {
+	awardedCount := 0
+	for _, registration := range registrations {
+		if registration.Registration.Status != string(constants.EventRegistrationStatusGoing) {
+			continue
+		}
+
+		awarded, err := s.Award(registration.User.ID, constants.ScoreReasonEventRegistered, fmt.Sprintf("event:%d", eventID))
+		if err != nil {
+			log.Printf("%s: Failed to award event registration to user %d: %v", utils.GetCurrentTypeName(), registration.User.ID, err)
+			continue
+		}
+		if awarded {
+			awardedCount++
+		}
+	}
+
+	return awardedCount
+}
+
+// Adjust adds a manual admin adjustment with a comment
+func (s *ScoreService) Adjust(userID int, points int, comment string) error {
+	if points == 0 {
+		return fmt.Errorf("%s: adjustment must not be zero", utils.GetCurrentTypeName())
+	}
+
+	if _, err := s.scoreRepository.AddEntry(userID, points, constants.ScoreReasonAdminAdjustment, "", comment); err != nil {
+		return fmt.Errorf("%s: failed to adjust score of user %d: %w", utils.GetCurrentTypeName(), userID, err)
+	}
+
+	return nil
+}
+
+// GetLeaderboard returns the members with the highest score
+func (s *ScoreService) GetLeaderboard() ([]repositories.User, error) {
+	return s.scoreRepository.GetLeaderboard(constants.ScoreLeaderboardLimit)
+}
+
+// GetRank returns the leaderboard position of a user
+func (s *ScoreService) GetRank(userID int) (int, error) {
+	return s.scoreRepository.GetRank(userID)
+}
+
+// GetHistory returns the latest score ledger entries of a user
+func (s *ScoreService) GetHistory(userID int) ([]repositories.ScoreLedgerEntry, error) {
+	return s.scoreRepository.GetHistory(userID, constants.ScoreHistoryLimit)
+}
+
+// ApplyMonthlyDecay applies the configured score decay once per calendar month
+func (s *ScoreService) ApplyMonthlyDecay(now time.Time) (int, error) {
+	if s.config.ScoreDecayPercent == 0 {
+		return 0, nil
+	}
+
+	affected, err := s.scoreRepository.ApplyDecay(s.config.ScoreDecayPercent, decayReference(now))
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to apply monthly decay: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	return affected, nil
+}
+
+// IsMonthlyDecayMissed reports whether the decay of the current month is not applied yet although
+// the decay of an earlier month is, like after the bot was down at the start of the month.
+// Without any previous decay the first one waits for the start of the next month.
+func (s *ScoreService) IsMonthlyDecayMissed(now time.Time) (bool, error) {
+	if s.config.ScoreDecayPercent == 0 {
+		return false, nil
+	}
+
+	lastReference, err := s.scoreRepository.GetLastDecayReference()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get last decay: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// References are ordered by month, see decayReference
+	return lastReference != "" && lastReference < decayReference(now), nil
+}
+
+// decayReference identifies the decay of the month in the score ledger
+func decayReference(now time.Time) string {
+	return fmt.Sprintf("decay:%s", now.Format("2006-01"))
+}
+
+// GetUserByTelegramID returns the user record for a Telegram ID
+func (s *ScoreService) GetUserByTelegramID(tgID int64) (*repositories.User, error) {
+	user, err := s.userRepository.GetByTelegramID(tgID)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get user: %w", utils.GetCurrentTypeName(), err)
+	}
+	return user, nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/database/repositories/memory"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScoreService(t *testing.T, decayPercent int) (*ScoreService, *memory.Store) {
+	t.Helper()
+
+	store := memory.NewStore()
+	return NewScoreService(&config.Config{ScoreDecayPercent: decayPercent}, store.Scores(), store.Users()), store
+}
+
+func userScore(t *testing.T, store *memory.Store, userID int) int {
+	t.Helper()
+
+	user, err := store.Users().GetByID(userID)
+	require.NoError(t, err)
+	return user.Score
+}
+
+func TestScoreService_AwardOncePerActivity(t *testing.T) {
+	service, store := newTestScoreService(t, 0)
+	ivan, err := store.Users().Create(2001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+
+	awarded, err := service.Award(ivan, constants.ScoreReasonTopicProposed, "topic:1")
+	require.NoError(t, err)
+	assert.True(t, awarded)
+
+	// The same activity is not paid again, another one is
+	awarded, err = service.Award(ivan, constants.ScoreReasonTopicProposed, "topic:1")
+	require.NoError(t, err)
+	assert.False(t, awarded)
+	awarded, err = service.Award(ivan, constants.ScoreReasonTopicProposed, "topic:2")
+	require.NoError(t, err)
+	assert.True(t, awarded)
+	assert.Equal(t, 2*constants.ScoreRules[constants.ScoreReasonTopicProposed], userScore(t, store, ivan))
+
+	_, err = service.Award(ivan, constants.ScoreReasonDecay, "decay:2025-08")
+	assert.Error(t, err, "the decay has no award rule")
+
+	// A member without a record gets one
+	awarded, err = service.AwardTelegramUser(&gotgbot.User{Id: 2002, FirstName: "Petr"}, constants.ScoreReasonProfilePublished, "profile:1")
+	require.NoError(t, err)
+	assert.True(t, awarded)
+	petr, err := store.Users().GetByTelegramID(2002)
+	require.NoError(t, err)
+	assert.Equal(t, constants.ScoreRules[constants.ScoreReasonProfilePublished], petr.Score)
+}
+
+func TestScoreService_RandomCoffeeCreditsEachConfirmingMember(t *testing.T) {
+	service, store := newTestScoreService(t, 0)
+	ivan, err := store.Users().Create(2001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+	petr, err := store.Users().Create(2002, "Petr", "Sidorov", "petr")
+	require.NoError(t, err)
+
+	// Both members of the pair share the reference, the points go only to the member who confirmed
+	reference := "random_coffee_pair:7"
+	awarded, err := service.Award(ivan, constants.ScoreReasonRandomCoffeeCompleted, reference)
+	require.NoError(t, err)
+	assert.True(t, awarded)
+	assert.Equal(t, constants.ScoreRules[constants.ScoreReasonRandomCoffeeCompleted], userScore(t, store, ivan))
+	assert.Zero(t, userScore(t, store, petr))
+
+	awarded, err = service.Award(ivan, constants.ScoreReasonRandomCoffeeCompleted, reference)
+	require.NoError(t, err)
+	assert.False(t, awarded, "one meeting is paid once")
+
+	awarded, err = service.Award(petr, constants.ScoreReasonRandomCoffeeCompleted, reference)
+	require.NoError(t, err)
+	assert.True(t, awarded)
+	assert.Equal(t, constants.ScoreRules[constants.ScoreReasonRandomCoffeeCompleted], userScore(t, store, petr))
+}
+
+func TestScoreService_AwardEventRegistrations(t *testing.T) {
+	service, store := newTestScoreService(t, 0)
+	ivan, err := store.Users().Create(2001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+	petr, err := store.Users().Create(2002, "Petr", "Sidorov", "petr")
+	require.NoError(t, err)
+
+	registrations := []repositories.EventRegistrationWithUser{
+		{
+			Registration: &repositories.EventRegistration{Status: string(constants.EventRegistrationStatusGoing)},
+			User:         &repositories.User{ID: ivan},
+		},
+		{
+			Registration: &repositories.EventRegistration{Status: string(constants.EventRegistrationStatusWaitlist)},
+			User:         &repositories.User{ID: petr},
+		},
+	}
+
+	assert.Equal(t, 1, service.AwardEventRegistrations(42, registrations), "only those who are going")
+	assert.Zero(t, service.AwardEventRegistrations(42, registrations), "the event is paid once")
+	assert.Equal(t, constants.ScoreRules[constants.ScoreReasonEventRegistered], userScore(t, store, ivan))
+	assert.Zero(t, userScore(t, store, petr))
+}
+
+func TestScoreService_Adjust(t *testing.T) {
+	service, store := newTestScoreService(t, 0)
+	ivan, err := store.Users().Create(2001, "Ivan", "Petrov", "ivan")
+	require.NoError(t, err)
+
+	assert.Error(t, service.Adjust(ivan, 0, "nothing"))
+
+	// Manual adjustments have no reference, so every one of them counts
+	require.NoError(t, service.Adjust(ivan, 10, "organized the meetup"))
+	require.NoError(t, service.Adjust(ivan, -3, "spam"))
+	require.NoError(t, service.Adjust(ivan, -3, "spam"))
+	assert.Equal(t, 4, userScore(t, store, ivan))
+
+	history, err := service.GetHistory(ivan)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, -3, history[0].Points)
+	assert.Equal(t, "spam", history[0].Comment)
+}
+
+func TestScoreService_MonthlyDecay(t *testing.T) {
+	august := time.Date(2025, time.August, 1, 0, 0, 30, 0, time.UTC)
+	september := time.Date(2025, time.September, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		decayPercent int
+		appliedAt    []time.Time // months the decay was applied before
+		now          time.Time
+		wantMissed   bool
+		wantAffected int
+		wantScore    int
+	}{
+		{
+			name:         "decay is turned off",
+			decayPercent: 0,
+			now:          september,
+			wantScore:    100,
+		},
+		{
+			name:         "first decay waits for the start of the month",
+			decayPercent: 10,
+			now:          september,
+			wantAffected: 1,
+			wantScore:    90,
+		},
+		{
+			name:         "decay of this month is applied already",
+			decayPercent: 10,
+			appliedAt:    []time.Time{september},
+			now:          september,
+			wantScore:    90,
+		},
+		{
+			name:         "decay of this month was missed",
+			decayPercent: 10,
+			appliedAt:    []time.Time{august},
+			now:          september,
+			wantMissed:   true,
+			wantAffected: 1,
+			wantScore:    81,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store := newTestScoreService(t, tt.decayPercent)
+			ivan, err := store.Users().Create(2001, "Ivan", "Petrov", "ivan")
+			require.NoError(t, err)
+			require.NoError(t, service.Adjust(ivan, 100, "opening balance"))
+			for _, appliedAt := range tt.appliedAt {
+				_, err := service.ApplyMonthlyDecay(appliedAt)
+				require.NoError(t, err)
+			}
+
+			missed, err := service.IsMonthlyDecayMissed(tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMissed, missed)
+
+			affected, err := service.ApplyMonthlyDecay(tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAffected, affected)
+			assert.Equal(t, tt.wantScore, userScore(t, store, ivan))
+
+			// Once applied, the decay of the month is neither missed nor applied again
+			missed, err = service.IsMonthlyDecayMissed(tt.now)
+			require.NoError(t, err)
+			assert.False(t, missed)
+			affected, err = service.ApplyMonthlyDecay(tt.now.AddDate(0, 0, 1))
+			require.NoError(t, err)
+			assert.Zero(t, affected)
+		})
+	}
+}
@@ -10,11 +10,11 @@ import (
 
 	"evo-bot-go/internal/clients"
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
 	"evo-bot-go/internal/database/prompts"
 	"evo-bot-go/internal/database/repositories"
 	"evo-bot-go/internal/utils"
 
-	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/gotd/td/tg"
 )
 
@@ -22,22 +22,31 @@ import (
 type SummarizationService struct {
 	config                      *config.Config
 	openaiClient                *clients.OpenAiClient
+	tgUserClient                clients.TgUserClient
+	forumTopicService           *ForumTopicService
 	messageSenderService        *MessageSenderService
 	promptingTemplateRepository *repositories.PromptingTemplateRepository
+	llmUsageService             *LLMUsageService
 }
 
 // NewSummarizationService creates a new summarization service
 func NewSummarizationService(
 	config *config.Config,
 	openaiClient *clients.OpenAiClient,
+	tgUserClient clients.TgUserClient,
+	forumTopicService *ForumTopicService,
 	messageSenderService *MessageSenderService,
 	promptingTemplateRepository *repositories.PromptingTemplateRepository,
+	llmUsageService *LLMUsageService,
 ) *SummarizationService {
 	return &SummarizationService{
 		config:                      config,
 		openaiClient:                openaiClient,
+		tgUserClient:                tgUserClient,
+		forumTopicService:           forumTopicService,
 		messageSenderService:        messageSenderService,
 		promptingTemplateRepository: promptingTemplateRepository,
+		llmUsageService:             llmUsageService,
 	}
 }
 
@@ -45,6 +54,10 @@ func NewSummarizationService(
 func (s *SummarizationService) RunDailySummarization(ctx context.Context, sendToDM bool) error {
 	log.Printf("%s: Starting daily summarization process", utils.GetCurrentTypeName())
 
+	if allowed, _ := s.llmUsageService.CheckQuota(0); !allowed {
+		return fmt.Errorf("%s: %w", utils.GetCurrentTypeName(), ErrLLMBudgetExhausted)
+	}
+
 	// Get the time 24 hours ago
 	since := time.Now().Add(-24 * time.Hour)
 
@@ -74,52 +87,18 @@ func (s *SummarizationService) RunDailySummarization(ctx context.Context, sendTo
 
 // summarizeTopicMessages summarizes a single topic
 func (s *SummarizationService) summarizeTopicMessages(ctx context.Context, topicID int, since time.Time, sendToDM bool) error {
-	// Get topic name
-	topicName, err := clients.TgGetTopicName(topicID)
+	// A topic that is not synced yet is summarized under the default name
+	topicName, err := s.forumTopicService.GetTopicName(topicID)
 	if err != nil {
-		return fmt.Errorf("%s: failed to get topic name: %w", utils.GetCurrentTypeName(), err)
+		log.Printf("%s: Using the default topic name: %v", utils.GetCurrentTypeName(), err)
 	}
 
 	// Calculate hours since the given time
 	hoursSince := int(time.Since(since).Hours()) + 1 // Add 1 to ensure we get all messages since 'since' time
 
-	// Get messages directly from Telegram with retry logic for rate limiting
-	var tgMessages []tg.Message
-	maxRetries := 3
-	for retry := 0; retry < maxRetries; retry++ {
-		tgMessages, err = clients.GetLastTopicMessagesByTime(s.config.SuperGroupChatID, topicID, hoursSince)
-		if err == nil {
-			break
-		}
-
-		// Check if it's a FLOOD_WAIT error
-		if retry < maxRetries-1 && err.Error() != "" {
-			errStr := err.Error()
-			if floodWaitIndex := utils.IndexAny(errStr, "FLOOD_WAIT"); floodWaitIndex != -1 {
-				// Try to extract wait time from error message (format: "FLOOD_WAIT (seconds)")
-				waitTimeStr := utils.ExtractNumber(errStr[floodWaitIndex:])
-				waitTime := 10 // Default wait time in seconds
-				if extractedTime, err := strconv.Atoi(waitTimeStr); err == nil && extractedTime > 0 {
-					waitTime = extractedTime
-				}
-
-				// Add buffer to required wait time
-				waitTime += 10
-
-				log.Printf("%s: Hit rate limit for topic %d, waiting %d seconds before retry %d/%d",
-					utils.GetCurrentTypeName(), topicID, waitTime, retry+1, maxRetries)
-
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-time.After(time.Duration(waitTime) * time.Second):
-					// Continue after waiting
-					continue
-				}
-			}
-		}
-
-		// If we got here, it's not a rate limit error or we've exceeded retries
+	// Get messages directly from Telegram, the user client waits out FLOOD_WAITs itself
+	tgMessages, err := s.tgUserClient.GetLastTopicMessagesByTime(ctx, s.config.SuperGroupChatID, topicID, hoursSince)
+	if err != nil {
 		return fmt.Errorf("%s: failed to get messages from Telegram: %w", utils.GetCurrentTypeName(), err)
 	}
 
@@ -205,7 +184,7 @@ func (s *SummarizationService) summarizeTopicMessages(ctx context.Context, topic
 		log.Printf("%s: Error writing prompt to file: %v", utils.GetCurrentTypeName(), err)
 	}
 
-	summary, err := s.openaiClient.GetCompletion(ctx, prompt)
+	summary, err := s.openaiClient.GetCompletion(WithLLMCaller(ctx, 0, constants.LLMFeatureSummarization), prompt)
 	if err != nil {
 		return fmt.Errorf("Summarization Service: failed to generate summary: %w", err)
 	}
@@ -217,22 +196,26 @@ func (s *SummarizationService) summarizeTopicMessages(ctx context.Context, topic
 
 	// Determine the target chat ID and options with summary topic ID
 	var targetChatID int64 = utils.ChatIdToFullChatId(int64(s.config.SuperGroupChatID))
-	var opts *gotgbot.SendMessageOpts = &gotgbot.SendMessageOpts{
+	opts := &QueueOpts{
 		MessageThreadId: int64(s.config.SummaryTopicID),
 	}
+	priority := constants.MessagePriorityBulk
 	if sendToDM {
 		// If sendToDM is true, try to get the user ID from context
 		if userID, ok := ctx.Value("userID").(int64); ok {
 			targetChatID = userID
 			opts = nil
+			priority = constants.MessagePriorityNotification
 		} else {
 			log.Printf("%s: Warning: sendToDM is true but userID not found in context, using SummaryTopicID instead", utils.GetCurrentTypeName())
 		}
 	}
 
-	// Send the summary to the target chat
-	s.messageSenderService.SendHtml(targetChatID, finalSummary, opts)
+	// Queue the summary to the target chat, so it survives a restart
+	if err := s.messageSenderService.EnqueueHtmlOrSend(targetChatID, finalSummary, priority, opts); err != nil {
+		return fmt.Errorf("%s: failed to send summary: %w", utils.GetCurrentTypeName(), err)
+	}
 
-	log.Printf("%s: Summary sent successfully", utils.GetCurrentTypeName())
+	log.Printf("%s: Summary queued successfully", utils.GetCurrentTypeName())
 	return nil
 }
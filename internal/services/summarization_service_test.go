@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/telegramtest"
+
+	"github.com/gotd/td/tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTgUserClient answers like the user client with the configured topics and messages
+type fakeTgUserClient struct {
+	clients.TgUserClient
+
+	mu       sync.Mutex
+	topics   []tg.ForumTopic
+	messages []tg.Message
+	err      error
+	calls    []fakeTgUserClientCall
+}
+
+type fakeTgUserClientCall struct {
+	Method  string
+	ChatID  int64
+	TopicID int
+	Hours   int
+}
+
+func (c *fakeTgUserClient) GetForumTopics(ctx context.Context, chatID int64) ([]tg.ForumTopic, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, fakeTgUserClientCall{Method: "GetForumTopics", ChatID: chatID})
+	return c.topics, c.err
+}
+
+func (c *fakeTgUserClient) GetLastTopicMessagesByTime(ctx context.Context, chatID int64, topicID int, hours int) ([]tg.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, fakeTgUserClientCall{Method: "GetLastTopicMessagesByTime", ChatID: chatID, TopicID: topicID, Hours: hours})
+	return c.messages, c.err
+}
+
+func TestSummarizationService_ReadsTopicsThroughUserClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		topicID  int
+		messages []tg.Message
+		err      error
+	}{
+		{name: "no messages", topicID: 42},
+		{name: "General topic", topicID: 0},
+		{name: "user client error", topicID: 42, err: errors.New("TG User Client: not connected")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := telegramtest.NewServer(t)
+			appConfig := &config.Config{
+				SuperGroupChatID:   1234567890,
+				MonitoredTopicsIDs: []int{tt.topicID},
+				SummaryTopicID:     7,
+				ClubTimezone:       time.UTC,
+			}
+			tgUserClient := &fakeTgUserClient{messages: tt.messages, err: tt.err}
+
+			service := NewSummarizationService(
+				appConfig,
+				nil, // OpenAI is not called without messages
+				tgUserClient,
+				NewForumTopicService(appConfig, tgUserClient, nil),
+				NewMessageSenderService(server.Bot, nil, nil, nil),
+				nil,
+				NewLLMUsageService(appConfig, nil),
+			)
+
+			// A failed topic is skipped, so the other topics are still summarized
+			require.NoError(t, service.RunDailySummarization(context.Background(), false))
+
+			assert.Equal(t, []fakeTgUserClientCall{{
+				Method:  "GetLastTopicMessagesByTime",
+				ChatID:  appConfig.SuperGroupChatID,
+				TopicID: tt.topicID,
+				Hours:   25,
+			}}, tgUserClient.calls)
+			assert.Empty(t, server.Requests(), "nothing is summarized")
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/database/repositories"
+	"evo-bot-go/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// ThanksService credits members who receive thanks from other members, with anti-abuse limits
+type ThanksService struct {
+	config           *config.Config
+	thanksRepository *repositories.ThanksRepository
+	userRepository   repositories.UserRepository
+	scoreService     *ScoreService
+
+	// Authors of old messages are forgotten at most once an hour
+	authorsMu       sync.Mutex
+	authorsPrunedAt time.Time
+}
+
+// NewThanksService creates a new thanks service
+func NewThanksService(
+	config *config.Config,
+	thanksRepository *repositories.ThanksRepository,
+	userRepository repositories.UserRepository,
+	scoreService *ScoreService,
+) *ThanksService {
+	return &ThanksService{
+		config:           config,
+		thanksRepository: thanksRepository,
+		userRepository:   userRepository,
+		scoreService:     scoreService,
+	}
+}
+
+// RememberMessageAuthor stores the author of a group message so reactions to it can be credited,
+// also after a restart of the bot. Messages of bots are not remembered, they can't be thanked.
+func (s *ThanksService) RememberMessageAuthor(chatID int64, messageID int64, author *gotgbot.User, postedAt time.Time) error {
+	if author.IsBot {
+		return nil
+	}
+
+	if err := s.thanksRepository.SaveMessageAuthor(chatID, messageID, author, postedAt); err != nil {
+		return fmt.Errorf("%s: failed to remember message author: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	s.authorsMu.Lock()
+	defer s.authorsMu.Unlock()
+	if time.Since(s.authorsPrunedAt) < time.Hour {
+		return nil
+	}
+	s.authorsPrunedAt = time.Now()
+
+	if _, err := s.thanksRepository.DeleteMessageAuthorsBefore(time.Now().Add(-constants.ThanksMessageAuthorsTTL)); err != nil {
+		log.Printf("%s: Failed to forget old message authors: %v", utils.GetCurrentTypeName(), err)
+	}
+	return nil
+}
+
+// GetMessageAuthor returns the remembered author of a group message, or nil if the message is unknown or too old
+func (s *ThanksService) GetMessageAuthor(chatID int64, messageID int64) (*gotgbot.User, error) {
+	author, err := s.thanksRepository.GetMessageAuthor(chatID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get message author: %w", utils.GetCurrentTypeName(), err)
+	}
+	return author, nil
+}
+
+// IsThanksReaction reports whether the reaction emoji counts as thanks
+func (s *ThanksService) IsThanksReaction(reaction gotgbot.ReactionType) bool {
+	emojiReaction, ok := reaction.(gotgbot.ReactionTypeEmoji)
+	return ok && slices.Contains(constants.ThanksReactionEmojis, emojiReaction.Emoji)
+}
+
+// GiveThanks credits the receiver with karma for the message if the anti-abuse limits allow it.
+// Returns false when the thanks was not counted.
+func (s *ThanksService) GiveThanks(giver *gotgbot.User, receiver *gotgbot.User, chatID int64, messageID int64, source constants.ThanksSource) (bool, error) {
+	// No self-thanks and no thanks from or to bots
+	if giver.Id == receiver.Id || giver.IsBot || receiver.IsBot {
+		return false, nil
+	}
+
+	giverUser, err := s.userRepository.GetOrCreate(giver)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get giver: %w", utils.GetCurrentTypeName(), err)
+	}
+	receiverUser, err := s.userRepository.GetOrCreate(receiver)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get receiver: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	// Daily cap per giver
+	givenToday, err := s.thanksRepository.CountGivenSince(giverUser.ID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to count given thanks: %w", utils.GetCurrentTypeName(), err)
+	}
+	if givenToday >= s.config.ThanksDailyLimit {
+		log.Printf("%s: User %d reached the daily thanks limit", utils.GetCurrentTypeName(), giver.Id)
+		return false, nil
+	}
+
+	// Cooldown per pair
+	lastAt, err := s.thanksRepository.GetLastBetween(giverUser.ID, receiverUser.ID)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get last thanks: %w", utils.GetCurrentTypeName(), err)
+	}
+	if lastAt != nil && time.Since(*lastAt) < s.config.ThanksPairCooldown {
+		log.Printf("%s: User %d already thanked user %d recently", utils.GetCurrentTypeName(), giver.Id, receiver.Id)
+		return false, nil
+	}
+
+	thanksID, err := s.thanksRepository.Create(giverUser.ID, receiverUser.ID, chatID, messageID, source)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to save thanks: %w", utils.GetCurrentTypeName(), err)
+	}
+	if thanksID == 0 {
+		// Already thanked for this message
+		return false, nil
+	}
+
+	if _, err := s.scoreService.Award(receiverUser.ID, constants.ScoreReasonThanksReceived, fmt.Sprintf("thanks:%d", thanksID)); err != nil {
+		return false, fmt.Errorf("%s: failed to award thanks: %w", utils.GetCurrentTypeName(), err)
+	}
+
+	log.Printf("%s: User %d thanked user %d by %s", utils.GetCurrentTypeName(), giver.Id, receiver.Id, source)
+	return true, nil
+}
+
+// GetStats returns the thanks counters of the user
+func (s *ThanksService) GetStats(userID int) (*repositories.ThanksStats, error) {
+	return s.thanksRepository.GetStats(userID)
+}
@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"log"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+)
+
+// BroadcastReportTask sends delivery reports of broadcasts once all their messages are processed
+type BroadcastReportTask struct {
+	broadcastService *services.BroadcastService
+	stop             chan struct{}
+}
+
+// NewBroadcastReportTask creates a new broadcast report task
+func NewBroadcastReportTask(broadcastService *services.BroadcastService) *BroadcastReportTask {
+	return &BroadcastReportTask{
+		broadcastService: broadcastService,
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start starts the broadcast report task
+func (t *BroadcastReportTask) Start() {
+	log.Printf("%s: Starting broadcast report task with checks every %v",
+		utils.GetCurrentTypeName(),
+		constants.BroadcastReportCheckInterval)
+	go t.run()
+}
+
+// Stop stops the broadcast report task
+func (t *BroadcastReportTask) Stop() {
+	log.Printf("%s: Stopping broadcast report task", utils.GetCurrentTypeName())
+	close(t.stop)
+}
+
+func (t *BroadcastReportTask) run() {
+	ticker := time.NewTicker(constants.BroadcastReportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			start := time.Now()
+			err := t.broadcastService.SendReports()
+			observability.ObserveTaskRun("broadcast_report", start, err)
+			if err != nil {
+				log.Printf("%s: Error sending broadcast reports: %v", utils.GetCurrentTypeName(), err)
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/observability"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/utils"
 )
@@ -66,7 +67,10 @@ func (s *DailySummarizationTask) run() {
 					defer cancel()
 
 					// For scheduled tasks, always send to the chat (not to DM)
-					if err := s.summarizationService.RunDailySummarization(ctx, false); err != nil {
+					start := time.Now()
+					err := s.summarizationService.RunDailySummarization(ctx, false)
+					observability.ObserveTaskRun("daily_summarization", start, err)
+					if err != nil {
 						log.Printf("%s: Error running daily summarization: %v", utils.GetCurrentTypeName(), err)
 					}
 				}()
@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+)
+
+// ForumTopicsSyncTask loads the forum topic registry and keeps it in sync with the club chat
+type ForumTopicsSyncTask struct {
+	forumTopicService *services.ForumTopicService
+	interval          time.Duration
+	stop              chan struct{}
+}
+
+// NewForumTopicsSyncTask creates a new forum topics sync task
+func NewForumTopicsSyncTask(forumTopicService *services.ForumTopicService) *ForumTopicsSyncTask {
+	return &ForumTopicsSyncTask{
+		forumTopicService: forumTopicService,
+		interval:          constants.ForumTopicsSyncInterval,
+		stop:              make(chan struct{}),
+	}
+}
+
+// Start loads the stored topics, so names are available right away, and starts syncing in the background
+func (t *ForumTopicsSyncTask) Start() {
+	log.Printf("%s: Starting forum topics sync task with interval %v", utils.GetCurrentTypeName(), t.interval)
+	if err := t.forumTopicService.Load(); err != nil {
+		log.Printf("%s: Error loading forum topics: %v", utils.GetCurrentTypeName(), err)
+	}
+	go t.run()
+}
+
+// Stop stops the forum topics sync task
+func (t *ForumTopicsSyncTask) Stop() {
+	log.Printf("%s: Stopping forum topics sync task", utils.GetCurrentTypeName())
+	close(t.stop)
+}
+
+// run syncs the topics right away and then once per interval
+func (t *ForumTopicsSyncTask) run() {
+	t.sync()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.sync()
+		}
+	}
+}
+
+func (t *ForumTopicsSyncTask) sync() {
+	start := time.Now()
+	count, err := t.forumTopicService.Sync(context.Background())
+	observability.ObserveTaskRun("forum_topics_sync", start, err)
+	if err != nil {
+		log.Printf("%s: Error syncing forum topics: %v", utils.GetCurrentTypeName(), err)
+		return
+	}
+
+	log.Printf("%s: Synced %d forum topics", utils.GetCurrentTypeName(), count)
+}
@@ -0,0 +1,71 @@
+package tasks
+
+import (
+	"log"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+)
+
+// MembershipCacheTask reloads the admin list of the club chat into the membership cache
+type MembershipCacheTask struct {
+	config                 *config.Config
+	membershipCacheService *services.MembershipCacheService
+	stop                   chan struct{}
+}
+
+// NewMembershipCacheTask creates a new membership cache task
+func NewMembershipCacheTask(config *config.Config, membershipCacheService *services.MembershipCacheService) *MembershipCacheTask {
+	return &MembershipCacheTask{
+		config:                 config,
+		membershipCacheService: membershipCacheService,
+		stop:                   make(chan struct{}),
+	}
+}
+
+// Start starts the membership cache task
+func (t *MembershipCacheTask) Start() {
+	log.Printf("%s: Starting membership cache task with admin reload every %v",
+		utils.GetCurrentTypeName(),
+		t.config.MembershipCacheTTL)
+	go t.run()
+}
+
+// Stop stops the membership cache task
+func (t *MembershipCacheTask) Stop() {
+	log.Printf("%s: Stopping membership cache task", utils.GetCurrentTypeName())
+	close(t.stop)
+}
+
+// run preloads the admins right away and then once per cache TTL
+func (t *MembershipCacheTask) run() {
+	t.preloadAdmins()
+
+	ticker := time.NewTicker(t.config.MembershipCacheTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.preloadAdmins()
+		}
+	}
+}
+
+func (t *MembershipCacheTask) preloadAdmins() {
+	start := time.Now()
+	err := t.membershipCacheService.PreloadAdmins()
+	observability.ObserveTaskRun("membership_cache", start, err)
+	if err != nil {
+		log.Printf("%s: Error preloading admins: %v", utils.GetCurrentTypeName(), err)
+	}
+
+	stats := t.membershipCacheService.GetStats()
+	log.Printf("%s: Membership cache: %d hits, %d misses, %d cached members",
+		utils.GetCurrentTypeName(), stats.Hits, stats.Misses, stats.Size)
+}
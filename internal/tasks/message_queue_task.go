@@ -0,0 +1,96 @@
+package tasks
+
+import (
+	"log"
+	"time"
+
+	"evo-bot-go/internal/constants"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+)
+
+// MessageQueueTask sends the messages queued in the database respecting Telegram rate limits
+type MessageQueueTask struct {
+	messageSenderService *services.MessageSenderService
+	stop                 chan struct{}
+}
+
+// NewMessageQueueTask creates a new message queue task
+func NewMessageQueueTask(messageSenderService *services.MessageSenderService) *MessageQueueTask {
+	return &MessageQueueTask{
+		messageSenderService: messageSenderService,
+		stop:                 make(chan struct{}),
+	}
+}
+
+// Start starts the message queue task
+func (t *MessageQueueTask) Start() {
+	log.Printf("%s: Starting message queue task with polling every %v",
+		utils.GetCurrentTypeName(),
+		constants.OutgoingQueuePollInterval)
+	go t.run()
+}
+
+// Stop stops the message queue task
+func (t *MessageQueueTask) Stop() {
+	log.Printf("%s: Stopping message queue task", utils.GetCurrentTypeName())
+	close(t.stop)
+}
+
+// run restores the blocked users, then sends due messages on every tick and cleans up old ones once a day
+func (t *MessageQueueTask) run() {
+	if err := t.messageSenderService.LoadBlockedUsers(); err != nil {
+		log.Printf("%s: Error loading users who blocked the bot: %v", utils.GetCurrentTypeName(), err)
+	}
+	t.cleanup()
+
+	pollTicker := time.NewTicker(constants.OutgoingQueuePollInterval)
+	defer pollTicker.Stop()
+	cleanupTicker := time.NewTicker(24 * time.Hour)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-pollTicker.C:
+			t.processQueue()
+		case <-cleanupTicker.C:
+			t.cleanup()
+		}
+	}
+}
+
+// processQueue sends due messages batch by batch until the queue has none left or the task is stopped
+func (t *MessageQueueTask) processQueue() {
+	for {
+		start := time.Now()
+		processed, err := t.messageSenderService.ProcessQueue(constants.OutgoingQueueBatchSize)
+		observability.ObserveTaskRun("message_queue", start, err)
+		if err != nil {
+			log.Printf("%s: Error processing message queue: %v", utils.GetCurrentTypeName(), err)
+			return
+		}
+		if processed < constants.OutgoingQueueBatchSize {
+			return
+		}
+
+		select {
+		case <-t.stop:
+			return
+		default:
+		}
+	}
+}
+
+func (t *MessageQueueTask) cleanup() {
+	deleted, err := t.messageSenderService.CleanupQueue(constants.OutgoingQueueRetention)
+	if err != nil {
+		log.Printf("%s: Error cleaning up message queue: %v", utils.GetCurrentTypeName(), err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("%s: Removed %d old messages from the queue", utils.GetCurrentTypeName(), deleted)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/observability"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/utils"
 )
@@ -62,7 +63,10 @@ func (t *RandomCoffeePairsTask) run() {
 				log.Printf("%s: Running scheduled random coffee pairs generation", utils.GetCurrentTypeName())
 
 				go func() {
-					if err := t.randomCoffeeService.GenerateAndSendPairs(); err != nil {
+					start := time.Now()
+					err := t.randomCoffeeService.GenerateAndSendPairs()
+					observability.ObserveTaskRun("random_coffee_pairs", start, err)
+					if err != nil {
 						log.Printf("%s: Error generating random coffee pairs: %v", utils.GetCurrentTypeName(), err)
 					}
 				}()
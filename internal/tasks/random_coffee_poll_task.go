@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/observability"
 	"evo-bot-go/internal/services"
 	"evo-bot-go/internal/utils"
 )
@@ -66,7 +67,10 @@ func (t *RandomCoffeePollTask) run() {
 					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 					defer cancel()
 
-					if err := t.randomCoffeeService.SendPoll(ctx); err != nil {
+					start := time.Now()
+					err := t.randomCoffeeService.SendPoll(ctx)
+					observability.ObserveTaskRun("random_coffee_poll", start, err)
+					if err != nil {
 						log.Printf("%s: Error sending random coffee poll: %v", utils.GetCurrentTypeName(), err)
 					}
 				}()
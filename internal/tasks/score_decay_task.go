@@ -0,0 +1,96 @@
+package tasks
+
+import (
+	"log"
+	"time"
+
+	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/observability"
+	"evo-bot-go/internal/services"
+	"evo-bot-go/internal/utils"
+)
+
+// ScoreDecayTask handles the monthly karma decay
+type ScoreDecayTask struct {
+	config       *config.Config
+	scoreService *services.ScoreService
+	stop         chan struct{}
+}
+
+// NewScoreDecayTask creates a new score decay task
+func NewScoreDecayTask(config *config.Config, scoreService *services.ScoreService) *ScoreDecayTask {
+	return &ScoreDecayTask{
+		config:       config,
+		scoreService: scoreService,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start starts the score decay task
+func (t *ScoreDecayTask) Start() {
+	if !t.config.ScoreDecayTaskEnabled {
+		log.Printf("%s: Score decay task is disabled", utils.GetCurrentTypeName())
+		return
+	}
+	log.Printf("%s: Starting score decay task with %d%% on the first day of each month",
+		utils.GetCurrentTypeName(),
+		t.config.ScoreDecayPercent)
+	go t.run()
+}
+
+// Stop stops the score decay task
+func (t *ScoreDecayTask) Stop() {
+	log.Printf("%s: Stopping score decay task", utils.GetCurrentTypeName())
+	close(t.stop)
+}
+
+// run runs the score decay task
+func (t *ScoreDecayTask) run() {
+	// The start of the month could pass while the bot was down
+	missed, err := t.scoreService.IsMonthlyDecayMissed(time.Now().UTC())
+	if err != nil {
+		log.Printf("%s: Error checking for missed score decay: %v", utils.GetCurrentTypeName(), err)
+	} else if missed {
+		log.Printf("%s: Score decay of this month was missed, applying it now", utils.GetCurrentTypeName())
+		t.applyDecay(time.Now().UTC())
+	}
+
+	nextRun := t.calculateNextRun()
+	log.Printf("%s: Next score decay scheduled for: %v", utils.GetCurrentTypeName(), nextRun)
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case now := <-ticker.C:
+			if now.After(nextRun) {
+				log.Printf("%s: Running scheduled score decay", utils.GetCurrentTypeName())
+				t.applyDecay(now.UTC())
+
+				nextRun = t.calculateNextRun()
+				log.Printf("%s: Next score decay scheduled for: %v", utils.GetCurrentTypeName(), nextRun)
+			}
+		}
+	}
+}
+
+// applyDecay applies the decay of the month, it is recorded once per month, so a repeated run is a no-op
+func (t *ScoreDecayTask) applyDecay(now time.Time) {
+	start := time.Now()
+	affected, err := t.scoreService.ApplyMonthlyDecay(now)
+	observability.ObserveTaskRun("score_decay", start, err)
+	if err != nil {
+		log.Printf("%s: Error applying score decay: %v", utils.GetCurrentTypeName(), err)
+	} else {
+		log.Printf("%s: Score decay applied to %d users", utils.GetCurrentTypeName(), affected)
+	}
+}
+
+// calculateNextRun returns the start of the next month in UTC
+func (t *ScoreDecayTask) calculateNextRun() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -1,24 +1,28 @@
 package tasks
 
 import (
+	"context"
 	"log"
 	"time"
 
 	"evo-bot-go/internal/clients"
+	"evo-bot-go/internal/observability"
 	"evo-bot-go/internal/utils"
 )
 
 // SessionKeepAliveTask handles scheduling of session keep-alive tasks for tg_user client session.
 type SessionKeepAliveTask struct {
-	interval time.Duration
-	stop     chan struct{}
+	tgUserClient clients.TgUserClient
+	interval     time.Duration
+	stop         chan struct{}
 }
 
 // NewSessionKeepAliveTask creates a new session keep-alive task
-func NewSessionKeepAliveTask(interval time.Duration) *SessionKeepAliveTask {
+func NewSessionKeepAliveTask(tgUserClient clients.TgUserClient, interval time.Duration) *SessionKeepAliveTask {
 	return &SessionKeepAliveTask{
-		interval: interval,
-		stop:     make(chan struct{}),
+		tgUserClient: tgUserClient,
+		interval:     interval,
+		stop:         make(chan struct{}),
 	}
 }
 
@@ -27,7 +31,7 @@ func (s *SessionKeepAliveTask) Start() {
 	log.Printf("%s: Starting session keep-alive task with interval %s", utils.GetCurrentTypeName(), s.interval)
 
 	// First time refresh
-	if err := clients.TgKeepSessionAlive(); err != nil {
+	if err := s.tgUserClient.KeepSessionAlive(context.Background()); err != nil {
 		log.Printf("%s: Failed to keep session alive: %v", utils.GetCurrentTypeName(), err)
 	} else {
 		log.Printf("%s: Session refresh successful", utils.GetCurrentTypeName())
@@ -56,7 +60,10 @@ func (s *SessionKeepAliveTask) run() {
 
 			// Run keep-alive in a separate goroutine
 			go func() {
-				if err := clients.TgKeepSessionAlive(); err != nil {
+				start := time.Now()
+				err := s.tgUserClient.KeepSessionAlive(context.Background())
+				observability.ObserveTaskRun("session_keepalive", start, err)
+				if err != nil {
 					log.Printf("%s: Failed to keep session alive: %v", utils.GetCurrentTypeName(), err)
 				} else {
 					log.Printf("%s: Session refresh successful", utils.GetCurrentTypeName())
@@ -0,0 +1,203 @@
+package telegramtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// BotUser is the user of the bot created by the server
+var BotUser = gotgbot.User{
+	Id:        123456,
+	IsBot:     true,
+	FirstName: "Evo Test Bot",
+	Username:  "evo_test_bot",
+}
+
+// defaultResponder answers like Telegram for the methods the bot uses, all other methods succeed with true
+func (s *Server) defaultResponder(r Request) (interface{}, error) {
+	switch r.Method {
+	case "getMe":
+		return BotUser, nil
+	case "sendMessage", "sendPhoto", "sendDocument", "sendAnimation", "sendVideo", "sendPoll":
+		return s.sendMessage(r), nil
+	case "copyMessage", "forwardMessage":
+		msg := s.sendMessage(r)
+		if r.Method == "copyMessage" {
+			return gotgbot.MessageId{MessageId: msg.MessageId}, nil
+		}
+		return msg, nil
+	case "editMessageText", "editMessageCaption", "editMessageReplyMarkup", "editMessageMedia":
+		return s.editMessage(r)
+	case "deleteMessage":
+		return s.deleteMessage(r)
+	case "getChatMember":
+		// Everybody is a member of every chat
+		return map[string]interface{}{
+			"status": "member",
+			"user":   gotgbot.User{Id: r.Int64("user_id"), FirstName: "User"},
+		}, nil
+	case "getUserProfilePhotos":
+		// Users have no avatars unless the test says otherwise
+		return gotgbot.UserProfilePhotos{Photos: [][]gotgbot.PhotoSize{}}, nil
+	}
+	return true, nil
+}
+
+func (s *Server) sendMessage(r Request) *gotgbot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chatID := r.Int64("chat_id")
+	s.lastMessageID++
+	msg := &gotgbot.Message{
+		MessageId:       s.lastMessageID,
+		MessageThreadId: r.Int64("message_thread_id"),
+		IsTopicMessage:  r.Int64("message_thread_id") != 0,
+		From:            &BotUser,
+		Chat:            chatOf(chatID),
+		Date:            time.Now().Unix(),
+		Text:            r.Params["text"],
+		Caption:         r.Params["caption"],
+		ReplyMarkup:     inlineKeyboard(r.Params["reply_markup"]),
+	}
+
+	switch r.Method {
+	case "sendPhoto":
+		fileID := "photo-" + r.Params["chat_id"]
+		if r.Params["photo"] != "" {
+			// The photo is resent by its file_id
+			fileID = r.Params["photo"]
+		}
+		msg.Photo = []gotgbot.PhotoSize{{FileId: fileID, FileUniqueId: fileID}}
+	case "sendDocument":
+		msg.Document = &gotgbot.Document{FileId: "document-" + r.Params["chat_id"], FileUniqueId: "document"}
+	case "sendPoll":
+		msg.Poll = poll(msg.MessageId, r)
+	}
+
+	s.chats[chatID] = append(s.chats[chatID], msg)
+	return msg
+}
+
+func (s *Server) editMessage(r Request) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.findMessage(r.Int64("chat_id"), r.Int64("message_id"))
+	if msg == nil {
+		return nil, &APIError{Code: http.StatusBadRequest, Description: "Bad Request: message to edit not found"}
+	}
+
+	edited := *msg
+	switch r.Method {
+	case "editMessageText":
+		if len(msg.Photo) > 0 {
+			return nil, &APIError{Code: http.StatusBadRequest, Description: "Bad Request: there is no text in the message to edit"}
+		}
+		edited.Text = r.Params["text"]
+	case "editMessageCaption":
+		edited.Caption = r.Params["caption"]
+	case "editMessageMedia":
+		var media struct {
+			Type    string `json:"type"`
+			Media   string `json:"media"`
+			Caption string `json:"caption"`
+		}
+		_ = json.Unmarshal([]byte(r.Params["media"]), &media)
+		if len(msg.Photo) == 0 || media.Type != "photo" {
+			return nil, &APIError{Code: http.StatusBadRequest, Description: "Bad Request: there is no media in the message to edit"}
+		}
+		edited.Caption = media.Caption
+		edited.Photo = []gotgbot.PhotoSize{{FileId: media.Media, FileUniqueId: media.Media}}
+	}
+	edited.ReplyMarkup = inlineKeyboard(r.Params["reply_markup"])
+
+	if edited.Text == msg.Text && edited.Caption == msg.Caption && samePhoto(edited.Photo, msg.Photo) &&
+		sameKeyboard(edited.ReplyMarkup, msg.ReplyMarkup) {
+		return nil, &APIError{
+			Code: http.StatusBadRequest,
+			Description: "Bad Request: message is not modified: specified new message content and reply markup " +
+				"are exactly the same as a current content and reply markup of the message",
+		}
+	}
+
+	edited.EditDate = time.Now().Unix()
+	*msg = edited
+	return edited, nil
+}
+
+func (s *Server) deleteMessage(r Request) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chatID, messageID := r.Int64("chat_id"), r.Int64("message_id")
+	for i, msg := range s.chats[chatID] {
+		if msg.MessageId == messageID {
+			s.chats[chatID] = append(s.chats[chatID][:i], s.chats[chatID][i+1:]...)
+			return true, nil
+		}
+	}
+	return nil, &APIError{Code: http.StatusBadRequest, Description: "Bad Request: message to delete not found"}
+}
+
+func (s *Server) findMessage(chatID int64, messageID int64) *gotgbot.Message {
+	for _, msg := range s.chats[chatID] {
+		if msg.MessageId == messageID {
+			return msg
+		}
+	}
+	return nil
+}
+
+// chatOf returns the chat by its ID, positive IDs are private chats with users
+func chatOf(chatID int64) gotgbot.Chat {
+	if chatID > 0 {
+		return gotgbot.Chat{Id: chatID, Type: "private"}
+	}
+	return gotgbot.Chat{Id: chatID, Type: "supergroup", IsForum: true}
+}
+
+// inlineKeyboard parses the reply markup, other keyboards than the inline one are ignored
+func inlineKeyboard(replyMarkup string) *gotgbot.InlineKeyboardMarkup {
+	if replyMarkup == "" {
+		return nil
+	}
+
+	var keyboard gotgbot.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(replyMarkup), &keyboard); err != nil || len(keyboard.InlineKeyboard) == 0 {
+		return nil
+	}
+	return &keyboard
+}
+
+func sameKeyboard(a, b *gotgbot.InlineKeyboardMarkup) bool {
+	aJson, _ := json.Marshal(a)
+	bJson, _ := json.Marshal(b)
+	return string(aJson) == string(bJson)
+}
+
+func samePhoto(a, b []gotgbot.PhotoSize) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return a[len(a)-1].FileUniqueId == b[len(b)-1].FileUniqueId
+}
+
+func poll(messageID int64, r Request) *gotgbot.Poll {
+	var options []gotgbot.InputPollOption
+	_ = json.Unmarshal([]byte(r.Params["options"]), &options)
+
+	p := &gotgbot.Poll{
+		Id:          PollID(messageID),
+		Question:    r.Params["question"],
+		IsAnonymous: r.Params["is_anonymous"] != "false",
+		Type:        "regular",
+	}
+	for _, option := range options {
+		p.Options = append(p.Options, gotgbot.PollOption{Text: option.Text})
+	}
+	return p
+}
@@ -0,0 +1,244 @@
+// Package telegramtest is a fake Telegram Bot API server for handler tests. The bot created by the server sends
+// all requests to it, the server records them and keeps the messages of every chat, so tests can drive
+// a conversation with updates and check what the bot answered.
+package telegramtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+const botToken = "123456:test-token"
+
+// Request is a Bot API request made by the bot
+type Request struct {
+	Method string
+	Params map[string]string
+	Files  map[string][]byte
+}
+
+// Int64 returns the numeric parameter, or 0 if it is missing
+func (r Request) Int64(name string) int64 {
+	value, _ := strconv.ParseInt(r.Params[name], 10, 64)
+	return value
+}
+
+// Responder answers a request with the result, or with the error if it is not nil.
+// An *APIError is answered with its code, any other error with 400 Bad Request.
+type Responder func(r Request) (interface{}, error)
+
+// APIError is a failed Bot API response
+type APIError struct {
+	Code        int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Description)
+}
+
+// Server is a fake Telegram Bot API server
+type Server struct {
+	// Bot is the bot talking to the server
+	Bot *gotgbot.Bot
+
+	httpServer *httptest.Server
+
+	mu            sync.Mutex
+	requests      []Request
+	responders    map[string]Responder
+	chats         map[int64][]*gotgbot.Message
+	lastMessageID int64
+	lastUpdateID  int64
+}
+
+// NewServer starts a fake server and creates a bot talking to it, both are closed with the test
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		responders: make(map[string]Responder),
+		chats:      make(map[int64][]*gotgbot.Message),
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.httpServer.Close)
+
+	bot, err := gotgbot.NewBot(botToken, &gotgbot.BotOpts{
+		BotClient: &gotgbot.BaseBotClient{
+			Client: http.Client{},
+			DefaultRequestOpts: &gotgbot.RequestOpts{
+				Timeout: 5 * time.Second,
+				APIURL:  s.httpServer.URL,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("telegramtest: failed to create bot: %v", err)
+	}
+	s.Bot = bot
+
+	// The token check is not a request of the bot under test
+	s.ClearRequests()
+	return s
+}
+
+// Handle replaces the default answer to the method
+func (s *Server) Handle(method string, responder Responder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responders[method] = responder
+}
+
+// Default answers the request like the server does by default,
+// so a replaced answer can fail only the requests the test is about
+func (s *Server) Default(r Request) (interface{}, error) {
+	return s.defaultResponder(r)
+}
+
+// Requests returns the recorded requests of the methods, or all of them if no method is given
+func (s *Server) Requests(methods ...string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []Request
+	for _, r := range s.requests {
+		if len(methods) == 0 || slices.Contains(methods, r.Method) {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// ClearRequests forgets the recorded requests, the chat messages are kept
+func (s *Server) ClearRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+// Messages returns the messages of the chat that are not deleted, in the order they were sent
+func (s *Server) Messages(chatID int64) []gotgbot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]gotgbot.Message, 0, len(s.chats[chatID]))
+	for _, msg := range s.chats[chatID] {
+		messages = append(messages, *msg)
+	}
+	return messages
+}
+
+// LastBotMessage returns the latest message of the bot in the chat, or nil if there is none
+func (s *Server) LastBotMessage(chatID int64) *gotgbot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.chats[chatID]
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].From != nil && messages[i].From.Id == BotUser.Id {
+			msg := *messages[i]
+			return &msg
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	// The path is /bot<token>/<method>
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] != "bot"+botToken {
+		writeResponse(w, nil, &APIError{Code: http.StatusNotFound, Description: "Not Found"})
+		return
+	}
+
+	r, err := parseRequest(parts[1], req)
+	if err != nil {
+		writeResponse(w, nil, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	responder, ok := s.responders[r.Method]
+	s.mu.Unlock()
+
+	if !ok {
+		responder = s.defaultResponder
+	}
+	result, err := responder(r)
+	writeResponse(w, result, err)
+}
+
+func parseRequest(method string, req *http.Request) (Request, error) {
+	r := Request{
+		Method: method,
+		Params: make(map[string]string),
+		Files:  make(map[string][]byte),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return r, fmt.Errorf("failed to parse multipart request: %w", err)
+		}
+		for name, values := range req.MultipartForm.Value {
+			r.Params[name] = values[0]
+		}
+		for name, headers := range req.MultipartForm.File {
+			file, err := headers[0].Open()
+			if err != nil {
+				return r, fmt.Errorf("failed to open file %s: %w", name, err)
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return r, fmt.Errorf("failed to read file %s: %w", name, err)
+			}
+			r.Files[name] = data
+		}
+		return r, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return r, fmt.Errorf("failed to read request: %w", err)
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &r.Params); err != nil {
+			return r, fmt.Errorf("failed to parse request: %w", err)
+		}
+	}
+	return r, nil
+}
+
+func writeResponse(w http.ResponseWriter, result interface{}, err error) {
+	response := map[string]interface{}{"ok": err == nil}
+	if err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			apiErr = &APIError{Code: http.StatusBadRequest, Description: "Bad Request: " + err.Error()}
+		}
+		response["error_code"] = apiErr.Code
+		response["description"] = apiErr.Description
+	} else {
+		response["result"] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
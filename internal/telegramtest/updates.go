@@ -0,0 +1,107 @@
+package telegramtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// PollID returns the Telegram ID of the poll sent by the bot in the message
+func PollID(messageID int64) string {
+	return fmt.Sprintf("poll-%d", messageID)
+}
+
+// Message returns the update with a message of the user in the chat, it is stored as a message of the chat.
+// A text starting with "/" is a command, like in Telegram clients.
+func (s *Server) Message(chat gotgbot.Chat, from gotgbot.User, text string) *gotgbot.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastMessageID++
+	msg := &gotgbot.Message{
+		MessageId: s.lastMessageID,
+		From:      &from,
+		Chat:      chat,
+		Date:      time.Now().Unix(),
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command := strings.Fields(text)[0]
+		msg.Entities = []gotgbot.MessageEntity{{Type: "bot_command", Offset: 0, Length: int64(utf8.RuneCountInString(command))}}
+	}
+	s.chats[chat.Id] = append(s.chats[chat.Id], msg)
+
+	return &gotgbot.Update{UpdateId: s.nextUpdateID(), Message: msg}
+}
+
+// PrivateMessage returns the update with a message of the user in the private chat with the bot
+func (s *Server) PrivateMessage(from gotgbot.User, text string) *gotgbot.Update {
+	return s.Message(chatOf(from.Id), from, text)
+}
+
+// PrivatePhoto returns the update with a photo of the user in the private chat with the bot
+func (s *Server) PrivatePhoto(from gotgbot.User, fileID string) *gotgbot.Update {
+	update := s.PrivateMessage(from, "")
+	update.Message.Photo = []gotgbot.PhotoSize{
+		{FileId: fileID + "-small", FileUniqueId: fileID + "-small", Width: 90, Height: 90},
+		{FileId: fileID, FileUniqueId: fileID, Width: 1280, Height: 1280},
+	}
+	return update
+}
+
+// CallbackQuery returns the update with a click of the user on the inline button with the data under the message
+func (s *Server) CallbackQuery(from gotgbot.User, msg *gotgbot.Message, data string) *gotgbot.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updateID := s.nextUpdateID()
+	return &gotgbot.Update{
+		UpdateId: updateID,
+		CallbackQuery: &gotgbot.CallbackQuery{
+			Id:           fmt.Sprintf("callback-%d", updateID),
+			From:         from,
+			Message:      *msg,
+			ChatInstance: fmt.Sprintf("chat-%d", msg.Chat.Id),
+			Data:         data,
+		},
+	}
+}
+
+// PollAnswer returns the update with the answer of the user to the poll, no options retract the vote
+func (s *Server) PollAnswer(from gotgbot.User, pollID string, optionIDs ...int64) *gotgbot.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &gotgbot.Update{
+		UpdateId: s.nextUpdateID(),
+		PollAnswer: &gotgbot.PollAnswer{
+			PollId:    pollID,
+			User:      &from,
+			OptionIds: optionIDs,
+		},
+	}
+}
+
+// Button returns the callback data of the inline button of the message whose text contains the text
+func Button(msg *gotgbot.Message, text string) (string, bool) {
+	if msg == nil || msg.ReplyMarkup == nil {
+		return "", false
+	}
+
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		for _, button := range row {
+			if strings.Contains(button.Text, text) {
+				return button.CallbackData, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (s *Server) nextUpdateID() int64 {
+	s.lastUpdateID++
+	return s.lastUpdateID
+}
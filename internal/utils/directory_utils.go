@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+)
+
+// DirectoryQuery is a member directory query split into the free text and the filters
+type DirectoryQuery struct {
+	Text string
+	Tag  string
+	City string
+}
+
+// ParseDirectoryQuery extracts the "#tag" and "город:Name" (or "city:Name") filters from the query,
+// the rest is the free text. Underscores of the filters are spaces, so "#system_design" and
+// "город:Нижний_Новгород" work. Only the first tag and the first city are used.
+func ParseDirectoryQuery(input string) DirectoryQuery {
+	var query DirectoryQuery
+	var words []string
+	for _, word := range strings.Fields(input) {
+		lower := strings.ToLower(word)
+		switch {
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			if query.Tag == "" {
+				query.Tag = directoryFilterValue(word[1:])
+			}
+		case strings.HasPrefix(lower, "город:") || strings.HasPrefix(lower, "city:"):
+			_, city, _ := strings.Cut(word, ":")
+			if query.City == "" {
+				query.City = directoryFilterValue(city)
+			}
+		default:
+			words = append(words, word)
+		}
+	}
+	query.Text = strings.Join(words, " ")
+	return query
+}
+
+// String formats the query back, so ParseDirectoryQuery returns the same query
+func (q DirectoryQuery) String() string {
+	var parts []string
+	if q.Text != "" {
+		parts = append(parts, q.Text)
+	}
+	if q.Tag != "" {
+		parts = append(parts, "#"+strings.ReplaceAll(q.Tag, " ", "_"))
+	}
+	if q.City != "" {
+		parts = append(parts, "город:"+strings.ReplaceAll(q.City, " ", "_"))
+	}
+	return strings.Join(parts, " ")
+}
+
+func directoryFilterValue(value string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(value, "_", " ")), " ")
+}
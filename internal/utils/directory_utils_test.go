@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDirectoryQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  DirectoryQuery
+	}{
+		{"", DirectoryQuery{}},
+		{"  backend   golang ", DirectoryQuery{Text: "backend golang"}},
+		{"#system_design", DirectoryQuery{Tag: "system design"}},
+		{"дизайнер город:Нижний_Новгород #Figma", DirectoryQuery{Text: "дизайнер", Tag: "Figma", City: "Нижний Новгород"}},
+		{"City:Berlin go #go #rust city:Paris", DirectoryQuery{Text: "go", Tag: "go", City: "Berlin"}},
+		{"C# #", DirectoryQuery{Text: "C# #"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseDirectoryQuery(tt.input))
+		})
+	}
+
+	query := DirectoryQuery{Text: "react or vue", Tag: "system design", City: "Нижний Новгород"}
+	assert.Equal(t, query, ParseDirectoryQuery(query.String()))
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// MessageHasLinks reports whether the message text or caption contains links
+func MessageHasLinks(msg *gotgbot.Message) bool {
+	for _, entity := range append(msg.Entities, msg.CaptionEntities...) {
+		if entity.Type == "url" || entity.Type == "text_link" {
+			return true
+		}
+	}
+
+	text := strings.ToLower(msg.GetText())
+	return strings.Contains(text, "http://") ||
+		strings.Contains(text, "https://") ||
+		strings.Contains(text, "t.me/")
+}
+
+// MessageHasMedia reports whether the message contains any media attachment
+func MessageHasMedia(msg *gotgbot.Message) bool {
+	return len(msg.Photo) > 0 ||
+		msg.Video != nil ||
+		msg.Animation != nil ||
+		msg.Document != nil ||
+		msg.Audio != nil ||
+		msg.Voice != nil ||
+		msg.VideoNote != nil ||
+		msg.Sticker != nil
+}
+
+// IsForwardedMessage reports whether the message is forwarded from another chat or user
+func IsForwardedMessage(msg *gotgbot.Message) bool {
+	return msg.ForwardOrigin != nil
+}
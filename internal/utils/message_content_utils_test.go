@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageHasLinks(t *testing.T) {
+	assert.True(t, MessageHasLinks(&gotgbot.Message{
+		Text:     "look at example.com",
+		Entities: []gotgbot.MessageEntity{{Type: "url", Offset: 8, Length: 11}},
+	}))
+	assert.True(t, MessageHasLinks(&gotgbot.Message{
+		Caption:         "here",
+		CaptionEntities: []gotgbot.MessageEntity{{Type: "text_link", Offset: 0, Length: 4, Url: "https://example.com"}},
+	}))
+	assert.True(t, MessageHasLinks(&gotgbot.Message{Text: "join t.me/+abcdef"}))
+	assert.False(t, MessageHasLinks(&gotgbot.Message{
+		Text:     "hello @someone",
+		Entities: []gotgbot.MessageEntity{{Type: "mention", Offset: 6, Length: 8}},
+	}))
+}
+
+func TestMessageHasMedia(t *testing.T) {
+	assert.True(t, MessageHasMedia(&gotgbot.Message{Photo: []gotgbot.PhotoSize{{FileId: "1"}}}))
+	assert.True(t, MessageHasMedia(&gotgbot.Message{Document: &gotgbot.Document{FileId: "1"}}))
+	assert.True(t, MessageHasMedia(&gotgbot.Message{Sticker: &gotgbot.Sticker{FileId: "1"}}))
+	assert.False(t, MessageHasMedia(&gotgbot.Message{Text: "just text"}))
+}
+
+func TestIsForwardedMessage(t *testing.T) {
+	assert.True(t, IsForwardedMessage(&gotgbot.Message{
+		ForwardOrigin: gotgbot.MessageOriginChannel{Chat: gotgbot.Chat{Id: -100123}},
+	}))
+	assert.False(t, IsForwardedMessage(&gotgbot.Message{Text: "original"}))
+}
@@ -47,3 +47,28 @@ func IsUserAdminOrCreator(b ChatMemberGetter, userId int64, config *config.Confi
 	}
 	return false
 }
+
+// GetDefaultChatPermissions returns the default member permissions of the chat,
+// or permissions to send any messages if the chat can't be fetched
+func GetDefaultChatPermissions(b *gotgbot.Bot, chatId int64) gotgbot.ChatPermissions {
+	chat, err := b.GetChat(chatId, nil)
+	if err == nil && chat.Permissions != nil {
+		return *chat.Permissions
+	}
+	if err != nil {
+		log.Printf("Failed to get chat permissions, using defaults: %v", err)
+	}
+
+	return gotgbot.ChatPermissions{
+		CanSendMessages:       true,
+		CanSendAudios:         true,
+		CanSendDocuments:      true,
+		CanSendPhotos:         true,
+		CanSendVideos:         true,
+		CanSendVideoNotes:     true,
+		CanSendVoiceNotes:     true,
+		CanSendPolls:          true,
+		CanSendOtherMessages:  true,
+		CanAddWebPagePreviews: true,
+	}
+}
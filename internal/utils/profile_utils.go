@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"evo-bot-go/internal/constants"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProfileClearValue is the answer that clears an optional profile field
+const ProfileClearValue = "-"
+
+var (
+	telegramUsernameRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{4,31}$`)
+	githubUsernameRegexp   = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+	linkedInSlugRegexp     = regexp.MustCompile(`^[\p{L}\p{N}_%-]+$`)
+	websiteHostRegexp      = regexp.MustCompile(`^(?:[\p{L}\p{N}-]+\.)+\p{L}{2,}$`)
+)
+
+// ParseProfileTextPair splits "first, second" into two trimmed values, the second one is optional.
+// It is used for "city, country" and "job title, company".
+func ParseProfileTextPair(input string) (string, string, error) {
+	input = strings.TrimSpace(input)
+	if input == ProfileClearValue {
+		return "", "", nil
+	}
+
+	first, second, _ := strings.Cut(input, ",")
+	first = strings.Join(strings.Fields(first), " ")
+	second = strings.Join(strings.Fields(second), " ")
+	if first == "" {
+		return "", "", fmt.Errorf("empty value: %q", input)
+	}
+	if utf8.RuneCountInString(first) > constants.ProfileTextLengthLimit ||
+		utf8.RuneCountInString(second) > constants.ProfileTextLengthLimit {
+		return "", "", fmt.Errorf("value is longer than %d characters: %q", constants.ProfileTextLengthLimit, input)
+	}
+	return first, second, nil
+}
+
+// ParseProfileTags parses tags separated by commas, semicolons or new lines.
+// A leading "#" is dropped, repeated tags are skipped regardless of the case.
+func ParseProfileTags(input string) ([]string, error) {
+	input = strings.TrimSpace(input)
+	tags := []string{}
+	if input == ProfileClearValue {
+		return tags, nil
+	}
+
+	seen := make(map[string]bool)
+	items := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n'
+	})
+	for _, item := range items {
+		tag := strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(item), "#")), " ")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > constants.ProfileTagLengthLimit {
+			return nil, fmt.Errorf("tag is longer than %d characters: %q", constants.ProfileTagLengthLimit, tag)
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags in %q", input)
+	}
+	if len(tags) > constants.ProfileTagsLimit {
+		return nil, fmt.Errorf("more than %d tags", constants.ProfileTagsLimit)
+	}
+	return tags, nil
+}
+
+// ParseProfileLinks parses links separated by spaces or new lines into a map of the link type to the normalized link.
+// It also returns the values that are not valid links, including the second link of the same type.
+func ParseProfileLinks(input string) (map[string]string, []string) {
+	links := make(map[string]string)
+	input = strings.TrimSpace(input)
+	if input == ProfileClearValue {
+		return links, nil
+	}
+
+	var invalid []string
+	for _, value := range strings.Fields(input) {
+		linkType, link, ok := NormalizeProfileLink(value)
+		if !ok || links[string(linkType)] != "" {
+			invalid = append(invalid, value)
+			continue
+		}
+		links[string(linkType)] = link
+	}
+	return links, invalid
+}
+
+// NormalizeProfileLink detects the type of the link and validates it by the rules of the type.
+// Telegram accepts "@username" and t.me links, GitHub a profile, LinkedIn a personal or a company page,
+// any other http(s) link with a domain name is a website.
+func NormalizeProfileLink(value string) (constants.ProfileLinkType, string, bool) {
+	value = strings.TrimSpace(value)
+	if username, ok := strings.CutPrefix(value, "@"); ok {
+		if !telegramUsernameRegexp.MatchString(username) {
+			return "", "", false
+		}
+		return constants.ProfileLinkTelegram, "https://t.me/" + username, true
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return "", "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	var segments []string
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		segments = strings.Split(path, "/")
+	}
+
+	switch {
+	case host == "t.me" || host == "telegram.me":
+		if len(segments) != 1 || !telegramUsernameRegexp.MatchString(segments[0]) {
+			return "", "", false
+		}
+		return constants.ProfileLinkTelegram, "https://t.me/" + segments[0], true
+	case host == "github.com":
+		if len(segments) != 1 || !githubUsernameRegexp.MatchString(segments[0]) {
+			return "", "", false
+		}
+		return constants.ProfileLinkGitHub, "https://github.com/" + segments[0], true
+	case host == "linkedin.com" || strings.HasSuffix(host, ".linkedin.com"):
+		if len(segments) != 2 || (segments[0] != "in" && segments[0] != "company") ||
+			!linkedInSlugRegexp.MatchString(segments[1]) {
+			return "", "", false
+		}
+		return constants.ProfileLinkLinkedIn, "https://www.linkedin.com/" + segments[0] + "/" + segments[1], true
+	case websiteHostRegexp.MatchString(host):
+		// The link is kept as typed, so international domain names stay readable
+		link, _, _ := strings.Cut(value, "#")
+		return constants.ProfileLinkWebsite, link, true
+	}
+	return "", "", false
+}
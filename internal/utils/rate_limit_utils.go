@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// rateLimiterPriorityPollInterval is how often a send waiting behind more urgent sends checks again
+const rateLimiterPriorityPollInterval = 50 * time.Millisecond
+
+// rateLimiterMaxChatBuckets is the number of per-chat buckets after which full (idle) buckets are dropped
+const rateLimiterMaxChatBuckets = 1000
+
+// TokenBucket holds up to capacity tokens and refills one token every refillEvery
+type TokenBucket struct {
+	capacity    float64
+	refillEvery time.Duration
+	tokens      float64
+	last        time.Time
+}
+
+// NewTokenBucket creates a full token bucket
+func NewTokenBucket(capacity int, refillEvery time.Duration) *TokenBucket {
+	return &TokenBucket{
+		capacity:    float64(capacity),
+		refillEvery: refillEvery,
+		tokens:      float64(capacity),
+	}
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += float64(now.Sub(b.last)) / float64(b.refillEvery)
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	if b.last.IsZero() || now.After(b.last) {
+		b.last = now
+	}
+}
+
+// Delay returns how long to wait until a token is available, zero if it is available now
+func (b *TokenBucket) Delay(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.refillEvery))
+}
+
+// Take consumes a token; it should be called only after Delay returned zero
+func (b *TokenBucket) Take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
+
+// isFull reports whether the bucket has not been used for long enough to refill completely
+func (b *TokenBucket) isFull(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// RateLimits configures the buckets of a RateLimiter
+type RateLimits struct {
+	GlobalPerSecond      int // messages per second to all chats together
+	PrivateChatPerSecond int // messages per second to a single private chat
+	PrivateChatBurst     int // messages that can be sent to a private chat at once
+	GroupChatPerMinute   int // messages per minute to a single group
+}
+
+// MessageRateLimiter blocks until a message with the priority can be sent to the chat, RateLimiter implements it
+type MessageRateLimiter interface {
+	Wait(chatID int64, priority int)
+}
+
+// RateLimiter throttles outgoing messages with a global and a per-chat token bucket.
+// Sends with a lower priority value take the global tokens first: while one of them is waiting
+// for the global bucket, less urgent sends wait too. A send waiting for its own chat holds back nobody.
+type RateLimiter struct {
+	mu               sync.Mutex
+	limits           RateLimits
+	global           *TokenBucket
+	chats            map[int64]*TokenBucket
+	waitingForGlobal map[int]int
+}
+
+// rateLimitWaiter is a send blocked in RateLimiter.Wait
+type rateLimitWaiter struct {
+	priority         int
+	waitingForGlobal bool
+}
+
+// NewRateLimiter creates a new rate limiter
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		limits:           limits,
+		global:           NewTokenBucket(limits.GlobalPerSecond, time.Second/time.Duration(limits.GlobalPerSecond)),
+		chats:            make(map[int64]*TokenBucket),
+		waitingForGlobal: make(map[int]int),
+	}
+}
+
+// Wait blocks until a message with the given priority can be sent to the chat
+func (l *RateLimiter) Wait(chatID int64, priority int) {
+	waiter := &rateLimitWaiter{priority: priority}
+	for {
+		delay := l.reserve(chatID, waiter, time.Now())
+		if delay == 0 {
+			return
+		}
+		time.Sleep(delay)
+	}
+}
+
+// reserve takes a token from both buckets and returns zero, or returns how long to wait before trying again
+func (l *RateLimiter) reserve(chatID int64, waiter *rateLimitWaiter, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	chat := l.chatBucket(chatID, now)
+	if delay := chat.Delay(now); delay > 0 {
+		l.setWaitingForGlobal(waiter, false)
+		return delay
+	}
+
+	// Only the sends that may go to their chats compete for the global bucket, the most urgent first
+	l.setWaitingForGlobal(waiter, true)
+	for p, count := range l.waitingForGlobal {
+		if p < waiter.priority && count > 0 {
+			return rateLimiterPriorityPollInterval
+		}
+	}
+	if delay := l.global.Delay(now); delay > 0 {
+		return delay
+	}
+
+	chat.Take(now)
+	l.global.Take(now)
+	l.setWaitingForGlobal(waiter, false)
+	return 0
+}
+
+func (l *RateLimiter) setWaitingForGlobal(waiter *rateLimitWaiter, waiting bool) {
+	if waiter.waitingForGlobal == waiting {
+		return
+	}
+
+	waiter.waitingForGlobal = waiting
+	if waiting {
+		l.waitingForGlobal[waiter.priority]++
+	} else {
+		l.waitingForGlobal[waiter.priority]--
+	}
+}
+
+func (l *RateLimiter) chatBucket(chatID int64, now time.Time) *TokenBucket {
+	if bucket, ok := l.chats[chatID]; ok {
+		return bucket
+	}
+
+	if len(l.chats) >= rateLimiterMaxChatBuckets {
+		for id, bucket := range l.chats {
+			if bucket.isFull(now) {
+				delete(l.chats, id)
+			}
+		}
+	}
+
+	var bucket *TokenBucket
+	if chatID > 0 {
+		bucket = NewTokenBucket(l.limits.PrivateChatBurst, time.Second/time.Duration(l.limits.PrivateChatPerSecond))
+	} else {
+		bucket = NewTokenBucket(l.limits.GroupChatPerMinute, time.Minute/time.Duration(l.limits.GroupChatPerMinute))
+	}
+	l.chats[chatID] = bucket
+	return bucket
+}
+
+// GetRetryAfter returns how long Telegram asked to wait if the error is a 429 Too Many Requests
+func GetRetryAfter(err error) (time.Duration, bool) {
+	var telegramErr *gotgbot.TelegramError
+	if !errors.As(err, &telegramErr) || telegramErr.Code != 429 {
+		return 0, false
+	}
+
+	if telegramErr.ResponseParams == nil || telegramErr.ResponseParams.RetryAfter <= 0 {
+		return time.Second, true
+	}
+	return time.Duration(telegramErr.ResponseParams.RetryAfter) * time.Second, true
+}
+
+// IsBotBlockedError reports whether the message could not be delivered because the user blocked the bot or deleted the account
+func IsBotBlockedError(err error) bool {
+	var telegramErr *gotgbot.TelegramError
+	if !errors.As(err, &telegramErr) || telegramErr.Code != 403 {
+		return false
+	}
+
+	return strings.Contains(telegramErr.Description, "bot was blocked by the user") ||
+		strings.Contains(telegramErr.Description, "user is deactivated")
+}
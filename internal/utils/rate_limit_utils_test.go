@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRateLimits = RateLimits{
+	GlobalPerSecond:      30,
+	PrivateChatPerSecond: 1,
+	PrivateChatBurst:     3,
+	GroupChatPerMinute:   20,
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2025, 8, 19, 12, 0, 0, 0, time.UTC)
+	bucket := NewTokenBucket(2, time.Second)
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, time.Duration(0), bucket.Delay(now))
+		bucket.Take(now)
+	}
+	assert.Equal(t, time.Second, bucket.Delay(now))
+	assert.Equal(t, 500*time.Millisecond, bucket.Delay(now.Add(500*time.Millisecond)))
+
+	// Refill never exceeds the capacity
+	later := now.Add(time.Hour)
+	assert.True(t, bucket.isFull(later))
+	bucket.Take(later)
+	bucket.Take(later)
+	assert.Equal(t, time.Second, bucket.Delay(later))
+}
+
+func TestRateLimiterPerChat(t *testing.T) {
+	now := time.Date(2025, 8, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(testRateLimits)
+
+	// Private chat allows a short burst, then one message per second
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Duration(0), limiter.reserve(42, &rateLimitWaiter{priority: 1}, now))
+	}
+	assert.Equal(t, time.Second, limiter.reserve(42, &rateLimitWaiter{priority: 1}, now))
+
+	// Other chats are not affected
+	assert.Equal(t, time.Duration(0), limiter.reserve(43, &rateLimitWaiter{priority: 1}, now))
+
+	// Group chat allows 20 messages per minute
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, time.Duration(0), limiter.reserve(-100, &rateLimitWaiter{priority: 1}, now.Add(2*time.Second)))
+	}
+	assert.Equal(t, 3*time.Second, limiter.reserve(-100, &rateLimitWaiter{priority: 1}, now.Add(2*time.Second)))
+}
+
+func TestRateLimiterGlobal(t *testing.T) {
+	now := time.Date(2025, 8, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(testRateLimits)
+
+	for i := int64(1); i <= 30; i++ {
+		assert.Equal(t, time.Duration(0), limiter.reserve(i, &rateLimitWaiter{priority: 1}, now))
+	}
+	assert.Equal(t, time.Second/30, limiter.reserve(31, &rateLimitWaiter{priority: 1}, now))
+}
+
+func TestRateLimiterPriority(t *testing.T) {
+	now := time.Date(2025, 8, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(testRateLimits)
+
+	// A bulk send waits while an interactive send is waiting for the global bucket
+	interactive := &rateLimitWaiter{priority: 0}
+	limiter.setWaitingForGlobal(interactive, true)
+	assert.Equal(t, rateLimiterPriorityPollInterval, limiter.reserve(42, &rateLimitWaiter{priority: 2}, now))
+	assert.Equal(t, time.Duration(0), limiter.reserve(43, interactive, now))
+	assert.Zero(t, limiter.waitingForGlobal[0])
+
+	assert.Equal(t, time.Duration(0), limiter.reserve(42, &rateLimitWaiter{priority: 2}, now))
+}
+
+func TestRateLimiterPriorityAcrossChats(t *testing.T) {
+	now := time.Date(2025, 8, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(testRateLimits)
+
+	// An interactive send waiting for its own chat does not hold back bulk sends to other chats
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Duration(0), limiter.reserve(42, &rateLimitWaiter{priority: 0}, now))
+	}
+	interactive := &rateLimitWaiter{priority: 0}
+	assert.Equal(t, time.Second, limiter.reserve(42, interactive, now))
+	assert.Equal(t, time.Duration(0), limiter.reserve(43, &rateLimitWaiter{priority: 2}, now))
+
+	// Once the global bucket is empty, the interactive send to a fresh chat goes before the bulk ones
+	for i := int64(100); i < 126; i++ {
+		assert.Equal(t, time.Duration(0), limiter.reserve(i, &rateLimitWaiter{priority: 2}, now))
+	}
+	urgent := &rateLimitWaiter{priority: 0}
+	bulk := &rateLimitWaiter{priority: 2}
+	assert.Equal(t, time.Second/30, limiter.reserve(44, urgent, now))
+	later := now.Add(time.Second / 30)
+	assert.Equal(t, rateLimiterPriorityPollInterval, limiter.reserve(45, bulk, later))
+	assert.Equal(t, time.Duration(0), limiter.reserve(44, urgent, later))
+	assert.Equal(t, time.Duration(0), limiter.reserve(45, bulk, later.Add(time.Second/30)))
+
+	// The interactive send that is still waiting for chat 42 has never blocked the global bucket
+	assert.Equal(t, time.Duration(0), limiter.reserve(42, interactive, now.Add(time.Second)))
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	retryAfter, ok := GetRetryAfter(&gotgbot.TelegramError{
+		Code:           429,
+		Description:    "Too Many Requests: retry after 7",
+		ResponseParams: &gotgbot.ResponseParameters{RetryAfter: 7},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, retryAfter)
+
+	retryAfter, ok = GetRetryAfter(fmt.Errorf("wrapped: %w", &gotgbot.TelegramError{Code: 429}))
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	_, ok = GetRetryAfter(&gotgbot.TelegramError{Code: 400, Description: "Bad Request"})
+	assert.False(t, ok)
+
+	_, ok = GetRetryAfter(errors.New("network error"))
+	assert.False(t, ok)
+
+	_, ok = GetRetryAfter(nil)
+	assert.False(t, ok)
+}
+
+func TestIsBotBlockedError(t *testing.T) {
+	assert.True(t, IsBotBlockedError(&gotgbot.TelegramError{Code: 403, Description: "Forbidden: bot was blocked by the user"}))
+	assert.True(t, IsBotBlockedError(&gotgbot.TelegramError{Code: 403, Description: "Forbidden: user is deactivated"}))
+	assert.False(t, IsBotBlockedError(&gotgbot.TelegramError{Code: 403, Description: "Forbidden: bot is not a member of the supergroup chat"}))
+	assert.False(t, IsBotBlockedError(&gotgbot.TelegramError{Code: 400, Description: "Bad Request: chat not found"}))
+	assert.False(t, IsBotBlockedError(errors.New("bot was blocked by the user")))
+	assert.False(t, IsBotBlockedError(nil))
+}
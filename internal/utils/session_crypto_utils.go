@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Encrypted sessions are stored as an envelope: the session is encrypted with a random data key,
+// and the data key is encrypted with the configured master key.
+//
+//	magic (4) | master key ID (8) | data key nonce (12) | encrypted data key (48) | data nonce (12) | encrypted session
+const (
+	sessionEnvelopeMagic       = "EVS1"
+	sessionKeySize             = 32 // AES-256
+	sessionKeyIDSize           = 8
+	sessionNonceSize           = 12
+	sessionEncryptedDataKeyLen = sessionKeySize + 16 // with the GCM tag
+	sessionEnvelopeHeaderLen   = len(sessionEnvelopeMagic) + sessionKeyIDSize + sessionNonceSize + sessionEncryptedDataKeyLen + sessionNonceSize
+)
+
+var (
+	ErrSessionNotEncrypted = errors.New("session is not encrypted")
+	ErrSessionUnknownKey   = errors.New("session is encrypted with an unknown key")
+)
+
+type sessionMasterKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// SessionCipher encrypts Telegram sessions with AES-GCM. New sessions are encrypted with the current key,
+// the old keys are only used to decrypt sessions stored before a key rotation.
+type SessionCipher struct {
+	current sessionMasterKey
+	keys    map[string]sessionMasterKey
+}
+
+// ParseSessionKey decodes a 32 byte key from base64 or hex
+func ParseSessionKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == sessionKeySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == sessionKeySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("session key must be %d bytes encoded in base64 or hex", sessionKeySize)
+}
+
+// NewSessionCipher creates a cipher from the current key and the old keys encoded in base64 or hex
+func NewSessionCipher(currentKey string, oldKeys []string) (*SessionCipher, error) {
+	current, err := newSessionMasterKey(currentKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid current session key: %w", err)
+	}
+
+	c := &SessionCipher{
+		current: current,
+		keys:    map[string]sessionMasterKey{string(current.id): current},
+	}
+	for i, oldKey := range oldKeys {
+		key, err := newSessionMasterKey(oldKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid old session key #%d: %w", i+1, err)
+		}
+		if _, exists := c.keys[string(key.id)]; !exists {
+			c.keys[string(key.id)] = key
+		}
+	}
+
+	return c, nil
+}
+
+func newSessionMasterKey(encoded string) (sessionMasterKey, error) {
+	key, err := ParseSessionKey(encoded)
+	if err != nil {
+		return sessionMasterKey{}, err
+	}
+
+	aead, err := newSessionAEAD(key)
+	if err != nil {
+		return sessionMasterKey{}, err
+	}
+
+	hash := sha256.Sum256(key)
+	return sessionMasterKey{id: hash[:sessionKeyIDSize], aead: aead}, nil
+}
+
+func newSessionAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsEncryptedSession checks if the data is a session envelope
+func IsEncryptedSession(data []byte) bool {
+	return len(data) >= sessionEnvelopeHeaderLen && bytes.HasPrefix(data, []byte(sessionEnvelopeMagic))
+}
+
+// IsCurrentKey checks if the session envelope is encrypted with the current key
+func (c *SessionCipher) IsCurrentKey(data []byte) bool {
+	if !IsEncryptedSession(data) {
+		return false
+	}
+	return bytes.Equal(sessionEnvelopeKeyID(data), c.current.id)
+}
+
+// Encrypt encrypts the session with a new data key
+func (c *SessionCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, sessionKeySize)
+	dataKeyNonce := make([]byte, sessionNonceSize)
+	dataNonce := make([]byte, sessionNonceSize)
+	for _, buf := range [][]byte{dataKey, dataKeyNonce, dataNonce} {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+	}
+
+	dataAEAD, err := newSessionAEAD(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data cipher: %w", err)
+	}
+
+	envelope := make([]byte, 0, sessionEnvelopeHeaderLen+len(plaintext)+dataAEAD.Overhead())
+	envelope = append(envelope, sessionEnvelopeMagic...)
+	envelope = append(envelope, c.current.id...)
+	envelope = append(envelope, dataKeyNonce...)
+	// The header authenticates the data key, so it cannot be moved to an envelope with another key ID
+	envelope = c.current.aead.Seal(envelope, dataKeyNonce, dataKey, envelope[:len(sessionEnvelopeMagic)+sessionKeyIDSize])
+	envelope = append(envelope, dataNonce...)
+	envelope = dataAEAD.Seal(envelope, dataNonce, plaintext, []byte(sessionEnvelopeMagic))
+
+	return envelope, nil
+}
+
+// Decrypt decrypts the session envelope with the key it was encrypted with
+func (c *SessionCipher) Decrypt(envelope []byte) ([]byte, error) {
+	if !IsEncryptedSession(envelope) {
+		return nil, ErrSessionNotEncrypted
+	}
+
+	key, ok := c.keys[string(sessionEnvelopeKeyID(envelope))]
+	if !ok {
+		return nil, ErrSessionUnknownKey
+	}
+
+	offset := len(sessionEnvelopeMagic) + sessionKeyIDSize
+	dataKeyNonce := envelope[offset : offset+sessionNonceSize]
+	offset += sessionNonceSize
+	encryptedDataKey := envelope[offset : offset+sessionEncryptedDataKeyLen]
+	offset += sessionEncryptedDataKeyLen
+	dataNonce := envelope[offset : offset+sessionNonceSize]
+	offset += sessionNonceSize
+
+	dataKey, err := key.aead.Open(nil, dataKeyNonce, encryptedDataKey, envelope[:len(sessionEnvelopeMagic)+sessionKeyIDSize])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+
+	dataAEAD, err := newSessionAEAD(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data cipher: %w", err)
+	}
+
+	plaintext, err := dataAEAD.Open(nil, dataNonce, envelope[offset:], []byte(sessionEnvelopeMagic))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt session: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func sessionEnvelopeKeyID(envelope []byte) []byte {
+	return envelope[len(sessionEnvelopeMagic) : len(sessionEnvelopeMagic)+sessionKeyIDSize]
+}
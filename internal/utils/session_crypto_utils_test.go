@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSessionKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestParseSessionKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+
+	parsed, err := ParseSessionKey(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	parsed, err = ParseSessionKey(" " + hex.EncodeToString(key) + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	_, err = ParseSessionKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+
+	_, err = ParseSessionKey("")
+	assert.Error(t, err)
+}
+
+func TestSessionCipher_EncryptDecrypt(t *testing.T) {
+	c, err := NewSessionCipher(testSessionKey(1), nil)
+	require.NoError(t, err)
+
+	session := []byte(`{"Version":1,"Data":{"AuthKey":"secret"}}`)
+	envelope, err := c.Encrypt(session)
+	require.NoError(t, err)
+
+	assert.True(t, IsEncryptedSession(envelope))
+	assert.True(t, c.IsCurrentKey(envelope))
+	assert.False(t, bytes.Contains(envelope, []byte("secret")))
+
+	decrypted, err := c.Decrypt(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, session, decrypted)
+
+	// Every envelope gets its own data key and nonces
+	other, err := c.Encrypt(session)
+	require.NoError(t, err)
+	assert.NotEqual(t, envelope, other)
+}
+
+func TestSessionCipher_Rotation(t *testing.T) {
+	oldCipher, err := NewSessionCipher(testSessionKey(1), nil)
+	require.NoError(t, err)
+	envelope, err := oldCipher.Encrypt([]byte("session"))
+	require.NoError(t, err)
+
+	rotated, err := NewSessionCipher(testSessionKey(2), []string{testSessionKey(1)})
+	require.NoError(t, err)
+	assert.False(t, rotated.IsCurrentKey(envelope))
+
+	decrypted, err := rotated.Decrypt(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("session"), decrypted)
+
+	withoutOldKey, err := NewSessionCipher(testSessionKey(2), nil)
+	require.NoError(t, err)
+	_, err = withoutOldKey.Decrypt(envelope)
+	assert.ErrorIs(t, err, ErrSessionUnknownKey)
+}
+
+func TestSessionCipher_Errors(t *testing.T) {
+	c, err := NewSessionCipher(testSessionKey(1), nil)
+	require.NoError(t, err)
+
+	_, err = c.Decrypt([]byte(`{"Version":1}`))
+	assert.ErrorIs(t, err, ErrSessionNotEncrypted)
+	assert.False(t, IsEncryptedSession([]byte(`{"Version":1}`)))
+
+	envelope, err := c.Encrypt([]byte("session"))
+	require.NoError(t, err)
+	envelope[len(envelope)-1] ^= 0xff
+	_, err = c.Decrypt(envelope)
+	assert.Error(t, err)
+
+	_, err = NewSessionCipher("not a key", nil)
+	assert.Error(t, err)
+	_, err = NewSessionCipher(testSessionKey(1), []string{"not a key"})
+	assert.Error(t, err)
+}
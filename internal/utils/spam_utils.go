@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// inviteLinkRegex matches Telegram invite links like t.me/+abc or t.me/joinchat/abc
+var inviteLinkRegex = regexp.MustCompile(`(?i)(t\.me|telegram\.me|telegram\.dog)/(\+|joinchat/)[\w-]+`)
+
+// HasTelegramInviteLink reports whether the message text, caption or its text links contain a Telegram invite link
+func HasTelegramInviteLink(msg *gotgbot.Message) bool {
+	if inviteLinkRegex.MatchString(msg.GetText()) {
+		return true
+	}
+	for _, entity := range append(msg.Entities, msg.CaptionEntities...) {
+		if entity.Type == "text_link" && inviteLinkRegex.MatchString(entity.Url) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsForwardedFromChannel reports whether the message is a forwarded channel post
+func IsForwardedFromChannel(msg *gotgbot.Message) bool {
+	return msg.ForwardOrigin != nil && msg.ForwardOrigin.GetType() == "channel"
+}
+
+// FindSpamPhrase returns the first spam phrase found in the text, case-insensitive
+func FindSpamPhrase(text string, phrases []string) (string, bool) {
+	text = strings.ToLower(text)
+	for _, phrase := range phrases {
+		if strings.Contains(text, strings.ToLower(phrase)) {
+			return phrase, true
+		}
+	}
+	return "", false
+}
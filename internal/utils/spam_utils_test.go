@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasTelegramInviteLink(t *testing.T) {
+	assert.True(t, HasTelegramInviteLink(&gotgbot.Message{Text: "join us https://t.me/+AbCdEf123"}))
+	assert.True(t, HasTelegramInviteLink(&gotgbot.Message{Caption: "telegram.me/joinchat/AAAA-bbb"}))
+	assert.True(t, HasTelegramInviteLink(&gotgbot.Message{
+		Text:     "click here",
+		Entities: []gotgbot.MessageEntity{{Type: "text_link", Offset: 0, Length: 10, Url: "https://t.me/+xyz"}},
+	}))
+	assert.False(t, HasTelegramInviteLink(&gotgbot.Message{Text: "see https://t.me/evocoders"}))
+	assert.False(t, HasTelegramInviteLink(&gotgbot.Message{Text: "a+b = c"}))
+}
+
+func TestIsForwardedFromChannel(t *testing.T) {
+	assert.True(t, IsForwardedFromChannel(&gotgbot.Message{
+		ForwardOrigin: gotgbot.MessageOriginChannel{Chat: gotgbot.Chat{Id: -100123}},
+	}))
+	assert.False(t, IsForwardedFromChannel(&gotgbot.Message{
+		ForwardOrigin: gotgbot.MessageOriginUser{SenderUser: gotgbot.User{Id: 1}},
+	}))
+	assert.False(t, IsForwardedFromChannel(&gotgbot.Message{Text: "original"}))
+}
+
+func TestFindSpamPhrase(t *testing.T) {
+	phrases := []string{"заработок от", "пишите в лс"}
+
+	phrase, ok := FindSpamPhrase("Лёгкий ЗАРАБОТОК от 500$ в день", phrases)
+	assert.True(t, ok)
+	assert.Equal(t, "заработок от", phrase)
+
+	_, ok = FindSpamPhrase("Всем привет, я Go-разработчик", phrases)
+	assert.False(t, ok)
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"html"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -33,3 +35,46 @@ func EscapeMarkdown(s string) string {
 
 	return result
 }
+
+var htmlTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// HtmlTextLength returns the length of the HTML text as Telegram counts it for the limits:
+// in UTF-16 code units, without the tags and with the entities unescaped
+func HtmlTextLength(s string) int {
+	return Utf16CodeUnitCount(HtmlToPlainText(s))
+}
+
+// HtmlToPlainText removes the tags of the HTML text and unescapes the entities
+func HtmlToPlainText(s string) string {
+	return html.UnescapeString(htmlTagRegexp.ReplaceAllString(s, ""))
+}
+
+// TruncateText cuts the text to the limit of characters, marking the cut with an ellipsis
+func TruncateText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
+
+// ParseInt64List parses a comma-separated list of numbers, skipping invalid items
+func ParseInt64List(s string) []int64 {
+	var result []int64
+	for _, item := range strings.Split(s, ",") {
+		value, err := strconv.ParseInt(strings.TrimSpace(item), 10, 64)
+		if err == nil {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// FormatInt64List formats numbers as a comma-separated list
+func FormatInt64List(values []int64) string {
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		items = append(items, strconv.FormatInt(value, 10))
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "Berlin", TruncateText("Berlin", 6))
+	assert.Equal(t, "Berl…", TruncateText("Berlin", 5))
+	assert.Equal(t, "Новый…", TruncateText("Новый Берлин", 7))
+}
+
+func TestParseInt64List(t *testing.T) {
+	assert.Equal(t, []int64{1, 22, 333}, ParseInt64List("1, 22,333"))
+	assert.Equal(t, []int64{5}, ParseInt64List("abc,5,"))
+	assert.Nil(t, ParseInt64List(""))
+}
+
+func TestFormatInt64List(t *testing.T) {
+	assert.Equal(t, "1,22,333", FormatInt64List([]int64{1, 22, 333}))
+	assert.Equal(t, "", FormatInt64List(nil))
+}
+
+func TestHtmlTextLength(t *testing.T) {
+	assert.Equal(t, 0, HtmlTextLength(""))
+	assert.Equal(t, 10, HtmlTextLength(`<b><a href="tg://user?id=1">Ivan</a></b> &lt;3 Go`))
+	// The emoji takes two UTF-16 code units
+	assert.Equal(t, 9, HtmlTextLength("📍 <i>Berlin</i>"))
+}
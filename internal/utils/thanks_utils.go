@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// thanksWords contains words that express gratitude to another member
+var thanksWords = []string{
+	"спасибо",
+	"thanks",
+	"thank you",
+}
+
+// IsThanksMessage reports whether the text expresses gratitude
+func IsThanksMessage(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if strings.HasPrefix(text, "+1") && (len(text) == 2 || !isWordRune([]rune(text[2:])[0])) {
+		return true
+	}
+	for _, word := range thanksWords {
+		if containsWord(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether the word occurs in the text not as a part of another word
+func containsWord(text string, word string) bool {
+	for start := 0; start < len(text); {
+		idx := strings.Index(text[start:], word)
+		if idx < 0 {
+			return false
+		}
+		idx += start
+		end := idx + len(word)
+
+		before, after := ' ', ' '
+		if idx > 0 {
+			before = lastRune(text[:idx])
+		}
+		if end < len(text) {
+			after = []rune(text[end:])[0]
+		}
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		start = end
+	}
+	return false
+}
+
+func lastRune(text string) rune {
+	runes := []rune(text)
+	return runes[len(runes)-1]
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
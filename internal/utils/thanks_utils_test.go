@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsThanksMessage(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{"Спасибо!", true},
+		{"огромное спасибо за помощь", true},
+		{"Thanks a lot", true},
+		{"thank you, it works", true},
+		{"спасибочки", false},
+		{"thanksgiving is coming", false},
+		{"+1", true},
+		{"+1 полностью согласен", true},
+		{"+100500", false},
+		{"у меня 2+1 варианта", false},
+		{"привет всем", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsThanksMessage(tt.text))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimezone parses an IANA timezone name such as "Europe/Moscow"
+func ParseTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	// Empty and "Local" names are accepted by time.LoadLocation but depend on the server
+	if name == "" || strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("unknown timezone: %q", name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone: %q", name)
+	}
+
+	return loc, nil
+}
+
+// ResolveLocation returns the location for the timezone name or the fallback if it is empty or unknown
+func ResolveLocation(name string, fallback *time.Location) *time.Location {
+	loc, err := ParseTimezone(name)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// PluralizeRu picks the Russian plural form for n, e.g. "час", "часа", "часов"
+func PluralizeRu(n int, one, few, many string) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+		return many
+	case n%10 == 1:
+		return one
+	case n%10 >= 2 && n%10 <= 4:
+		return few
+	default:
+		return many
+	}
+}
+
+// FormatTimeUntil formats a positive duration as a relative Russian phrase, e.g. "через 3 часа"
+func FormatTimeUntil(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "меньше чем через минуту"
+	case d < time.Hour:
+		minutes := int(d.Minutes())
+		return fmt.Sprintf("через %d %s", minutes, PluralizeRu(minutes, "минуту", "минуты", "минут"))
+	case d < 24*time.Hour:
+		hours := int(d.Hours())
+		return fmt.Sprintf("через %d %s", hours, PluralizeRu(hours, "час", "часа", "часов"))
+	default:
+		days := int(d.Hours() / 24)
+		return fmt.Sprintf("через %d %s", days, PluralizeRu(days, "день", "дня", "дней"))
+	}
+}
+
+// MaxShortDuration is the longest duration accepted by ParseShortDuration,
+// Telegram treats restrictions longer than 366 days as permanent
+const MaxShortDuration = 366 * 24 * time.Hour
+
+// ParseShortDuration parses durations like "30m", "2h", "1d" or "1w" up to MaxShortDuration
+func ParseShortDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+
+	// Compare the amount before multiplying, so huge values don't overflow
+	if amount > int(MaxShortDuration/unit) {
+		return 0, fmt.Errorf("duration %q is longer than %s", value, MaxShortDuration)
+	}
+
+	return time.Duration(amount) * unit, nil
+}
+
+// FormatDurationRu formats a duration as a Russian phrase in the largest whole unit, e.g. "2 дня" or "90 минут"
+func FormatDurationRu(d time.Duration) string {
+	minutes := int(d.Minutes())
+	switch {
+	case minutes > 0 && minutes%(24*60) == 0:
+		days := minutes / (24 * 60)
+		return fmt.Sprintf("%d %s", days, PluralizeRu(days, "день", "дня", "дней"))
+	case minutes > 0 && minutes%60 == 0:
+		hours := minutes / 60
+		return fmt.Sprintf("%d %s", hours, PluralizeRu(hours, "час", "часа", "часов"))
+	default:
+		return fmt.Sprintf("%d %s", minutes, PluralizeRu(minutes, "минута", "минуты", "минут"))
+	}
+}
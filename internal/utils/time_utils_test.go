@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimezone(t *testing.T) {
+	loc, err := ParseTimezone(" Europe/Moscow ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Moscow", loc.String())
+
+	loc, err = ParseTimezone("UTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", loc.String())
+
+	_, err = ParseTimezone("Mars/Olympus")
+	assert.Error(t, err)
+
+	_, err = ParseTimezone("")
+	assert.Error(t, err)
+
+	_, err = ParseTimezone("Local")
+	assert.Error(t, err)
+}
+
+func TestResolveLocation(t *testing.T) {
+	fallback := time.FixedZone("Club", 3*60*60)
+
+	assert.Equal(t, "Asia/Tbilisi", ResolveLocation("Asia/Tbilisi", fallback).String())
+	assert.Equal(t, fallback, ResolveLocation("", fallback))
+	assert.Equal(t, fallback, ResolveLocation("Invalid/Zone", fallback))
+}
+
+func TestPluralizeRu(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{1, "час"},
+		{2, "часа"},
+		{4, "часа"},
+		{5, "часов"},
+		{11, "часов"},
+		{12, "часов"},
+		{14, "часов"},
+		{21, "час"},
+		{22, "часа"},
+		{25, "часов"},
+		{111, "часов"},
+		{0, "часов"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, PluralizeRu(tt.n, "час", "часа", "часов"), "n=%d", tt.n)
+	}
+}
+
+func TestFormatTimeUntil(t *testing.T) {
+	assert.Equal(t, "меньше чем через минуту", FormatTimeUntil(30*time.Second))
+	assert.Equal(t, "через 1 минуту", FormatTimeUntil(time.Minute+10*time.Second))
+	assert.Equal(t, "через 45 минут", FormatTimeUntil(45*time.Minute))
+	assert.Equal(t, "через 3 часа", FormatTimeUntil(3*time.Hour+20*time.Minute))
+	assert.Equal(t, "через 21 час", FormatTimeUntil(21*time.Hour))
+	assert.Equal(t, "через 1 день", FormatTimeUntil(30*time.Hour))
+	assert.Equal(t, "через 5 дней", FormatTimeUntil(5*24*time.Hour))
+}
+
+func TestParseShortDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30m":  30 * time.Minute,
+		"2h":   2 * time.Hour,
+		"1D":   24 * time.Hour,
+		"1w":   7 * 24 * time.Hour,
+		"366d": MaxShortDuration,
+		"52w":  52 * 7 * 24 * time.Hour,
+	}
+	for input, expected := range tests {
+		d, err := ParseShortDuration(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, d, input)
+	}
+
+	for _, input := range []string{"", "h", "0h", "-1h", "10", "5y", "abc", "367d", "53w", "527041m", "99999999999999h"} {
+		_, err := ParseShortDuration(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestFormatDurationRu(t *testing.T) {
+	assert.Equal(t, "1 день", FormatDurationRu(24*time.Hour))
+	assert.Equal(t, "2 дня", FormatDurationRu(48*time.Hour))
+	assert.Equal(t, "1 час", FormatDurationRu(time.Hour))
+	assert.Equal(t, "90 минут", FormatDurationRu(90*time.Minute))
+	assert.Equal(t, "1 минута", FormatDurationRu(time.Minute))
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	transcriptVoiceTagRegexp = regexp.MustCompile(`^<v(?:\.[^\s>]+)?\s+([^>]+)>`)
+	transcriptAnyTagRegexp   = regexp.MustCompile(`</?[^>]+>`)
+	transcriptCueIndexRegexp = regexp.MustCompile(`^\d+$`)
+	transcriptTimingRegexp   = regexp.MustCompile(`(?m)^\s*(?:\d+:)?\d{2}:\d{2}[.,]\d{3}\s+-->\s+`)
+)
+
+// supportedTranscriptExtensions lists file extensions that can be parsed as transcripts
+var supportedTranscriptExtensions = []string{".vtt", ".srt", ".txt"}
+
+// IsSupportedTranscriptFile checks if the file name has a supported transcript extension
+func IsSupportedTranscriptFile(fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for _, supported := range supportedTranscriptExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTranscript converts VTT/SRT/plain text transcript into plain text lines.
+// For VTT and SRT headers, cue indexes, timestamps and markup are removed,
+// VTT voice tags (<v Speaker>) are converted to "Speaker: text" format.
+// Plain text is kept as is, only empty lines are removed. Consecutive duplicate lines are collapsed.
+func ParseTranscript(content string) string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	subtitles := isSubtitles(content)
+
+	var result []string
+	lastLine := ""
+	skipBlock := false
+
+	for _, rawLine := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(rawLine)
+
+		if line == "" {
+			skipBlock = false
+			continue
+		}
+
+		if !subtitles {
+			if line != lastLine {
+				result = append(result, line)
+				lastLine = line
+			}
+			continue
+		}
+
+		if skipBlock {
+			continue
+		}
+
+		// Skip VTT header and metadata blocks
+		if strings.HasPrefix(line, "WEBVTT") ||
+			strings.HasPrefix(line, "NOTE") ||
+			strings.HasPrefix(line, "STYLE") ||
+			strings.HasPrefix(line, "REGION") {
+			skipBlock = true
+			continue
+		}
+
+		// Skip timestamps and cue indexes
+		if strings.Contains(line, "-->") || transcriptCueIndexRegexp.MatchString(line) {
+			continue
+		}
+
+		if matches := transcriptVoiceTagRegexp.FindStringSubmatch(line); len(matches) > 1 {
+			line = strings.TrimSpace(matches[1]) + ": " + line[len(matches[0]):]
+		}
+		line = strings.TrimSpace(transcriptAnyTagRegexp.ReplaceAllString(line, ""))
+
+		if line == "" || line == lastLine {
+			continue
+		}
+
+		result = append(result, line)
+		lastLine = line
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// isSubtitles detects VTT by its header and SRT by its cue timings
+func isSubtitles(content string) bool {
+	return strings.HasPrefix(strings.TrimSpace(content), "WEBVTT") || transcriptTimingRegexp.MatchString(content)
+}
+
+// SplitTextIntoChunks splits text by lines into chunks not longer than maxRunes runes.
+// Lines longer than maxRunes are split into several chunks.
+func SplitTextIntoChunks(text string, maxRunes int) []string {
+	if maxRunes <= 0 || strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if currentLen > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		for len(runes) > maxRunes {
+			flush()
+			chunks = append(chunks, string(runes[:maxRunes]))
+			runes = runes[maxRunes:]
+		}
+
+		lineLen := utf8.RuneCountInString(string(runes))
+		separatorLen := 0
+		if currentLen > 0 {
+			separatorLen = 1
+		}
+
+		if currentLen+separatorLen+lineLen > maxRunes {
+			flush()
+			separatorLen = 0
+		}
+
+		if separatorLen > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(string(runes))
+		currentLen += separatorLen + lineLen
+	}
+
+	flush()
+	return chunks
+}
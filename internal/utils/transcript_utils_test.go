@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupportedTranscriptFile(t *testing.T) {
+	assert.True(t, IsSupportedTranscriptFile("meeting.vtt"))
+	assert.True(t, IsSupportedTranscriptFile("meeting.SRT"))
+	assert.True(t, IsSupportedTranscriptFile("notes.txt"))
+	assert.False(t, IsSupportedTranscriptFile("recording.mp4"))
+	assert.False(t, IsSupportedTranscriptFile("transcript"))
+}
+
+func TestParseTranscript_Vtt(t *testing.T) {
+	content := "WEBVTT\nKind: captions\n\nNOTE this is a note\nspanning two lines\n\n" +
+		"1\n00:00:01.000 --> 00:00:03.000\n<v Иван>Привет всем</v>\n\n" +
+		"2\n00:00:03.000 --> 00:00:05.000\n<v Иван>Привет всем</v>\n\n" +
+		"00:00:05.000 --> 00:00:07.000 align:start\n<c>Начинаем</c> созвон\n"
+
+	result := ParseTranscript(content)
+	assert.Equal(t, "Иван: Привет всем\nНачинаем созвон", result)
+}
+
+func TestParseTranscript_Srt(t *testing.T) {
+	content := "1\r\n00:00:01,000 --> 00:00:03,000\r\nFirst line\r\n\r\n" +
+		"2\r\n00:00:03,000 --> 00:00:05,000\r\n<i>Second</i> line\r\n"
+
+	result := ParseTranscript(content)
+	assert.Equal(t, "First line\nSecond line", result)
+}
+
+func TestParseTranscript_PlainText(t *testing.T) {
+	content := "\ufeffSpeaker A: hello\n\n  Speaker B: hi  \n"
+
+	result := ParseTranscript(content)
+	assert.Equal(t, "Speaker A: hello\nSpeaker B: hi", result)
+}
+
+func TestParseTranscript_PlainTextKeepsSubtitleLikeLines(t *testing.T) {
+	content := "NOTE: бюджет утвердили\n2024\nрост <5% -> ок\nрост <5% -> ок\n"
+
+	result := ParseTranscript(content)
+	assert.Equal(t, "NOTE: бюджет утвердили\n2024\nрост <5% -> ок", result)
+}
+
+func TestSplitTextIntoChunks(t *testing.T) {
+	text := "aaaa\nbbbb\ncccc"
+
+	chunks := SplitTextIntoChunks(text, 9)
+	assert.Equal(t, []string{"aaaa\nbbbb", "cccc"}, chunks)
+}
+
+func TestSplitTextIntoChunks_LongLine(t *testing.T) {
+	text := strings.Repeat("я", 25)
+
+	chunks := SplitTextIntoChunks(text, 10)
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, strings.Repeat("я", 10), chunks[0])
+	assert.Equal(t, strings.Repeat("я", 5), chunks[2])
+}
+
+func TestSplitTextIntoChunks_Empty(t *testing.T) {
+	assert.Nil(t, SplitTextIntoChunks("   ", 10))
+	assert.Nil(t, SplitTextIntoChunks("text", 0))
+}
@@ -1,23 +1,43 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // embedded zone database for hosts without one
 
 	"evo-bot-go/internal/bot"
 	"evo-bot-go/internal/clients"
 	"evo-bot-go/internal/config"
+	"evo-bot-go/internal/database"
+	"evo-bot-go/internal/database/migrations"
+	"evo-bot-go/internal/observability"
 )
 
 func main() {
+	// The migrate subcommand only needs the database connection
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Load configuration
 	appConfig, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Set up structured logging
+	if err := observability.SetupLogger(appConfig.LogFormat, appConfig.LogLevel); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+
 	// Initialize OpenAI client
 	openaiClient, err := clients.NewOpenAiClient()
 	if err != nil {
@@ -37,6 +57,34 @@ func main() {
 	botClient.Start()
 }
 
+// runMigrateCommand applies or rolls back database migrations, see migrations.CommandUsage
+func runMigrateCommand(args []string) error {
+	dbConnection, err := config.LoadDBConnection()
+	if err != nil {
+		return err
+	}
+
+	sessionKey, oldSessionKeys, err := config.LoadTGUserClientSessionKeys()
+	if err != nil {
+		return err
+	}
+	sessionCipher, err := database.NewSessionCipher(sessionKey, oldSessionKeys)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDB(dbConnection)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return migrations.RunCommand(ctx, db.DB, sessionCipher, args, os.Stdout)
+}
+
 // setupGracefulShutdown configures signal handling for graceful application termination
 func setupGracefulShutdown(botClient *bot.TgBotClient) {
 	sigChan := make(chan os.Signal, 1)